@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -11,13 +12,18 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	gowrapper "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/gateway"
 )
 
 var (
 	client *gowrapper.EipClient
+	hub    *gowrapper.Hub // Polls the tags watched over /ws
 	mu     sync.Mutex
 )
 
+// wsInterval is how often tags watched over the WebSocket are polled
+const wsInterval = 500 * time.Millisecond
+
 func main() {
 	r := mux.NewRouter()
 
@@ -57,7 +63,9 @@ func handleConnect(w http.ResponseWriter, r *http.Request) {
 	defer mu.Unlock()
 
 	if client != nil {
+		hub.Close()
 		client.Close()
+		client, hub = nil, nil
 	}
 
 	var err error
@@ -66,6 +74,7 @@ func handleConnect(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	hub = client.NewHub(wsInterval)
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -75,8 +84,9 @@ func handleDisconnect(w http.ResponseWriter, r *http.Request) {
 	defer mu.Unlock()
 
 	if client != nil {
+		hub.Close()
 		client.Close()
-		client = nil
+		client, hub = nil, nil
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -129,51 +139,13 @@ func handleTag(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		var value interface{} = req.Value
-		switch req.Type {
-		case "Dint":
-			if f, ok := req.Value.(float64); ok {
-				value = int32(f)
-			} else if i, ok := req.Value.(int); ok {
-				value = int32(i)
-			} else if s, ok := req.Value.(string); ok {
-				var v int32
-				_, err := fmt.Sscanf(s, "%d", &v)
-				if err != nil {
-					http.Error(w, "invalid DINT value", http.StatusBadRequest)
-					return
-				}
-				value = v
-			}
-		case "Int":
-			if f, ok := req.Value.(float64); ok {
-				value = int16(f)
-			} else if i, ok := req.Value.(int); ok {
-				value = int16(i)
-			} else if s, ok := req.Value.(string); ok {
-				var v int16
-				_, err := fmt.Sscanf(s, "%d", &v)
-				if err != nil {
-					http.Error(w, "invalid INT value", http.StatusBadRequest)
-					return
-				}
-				value = v
-			}
-		case "Real":
-			if f, ok := req.Value.(float64); ok {
-				value = f
-			} else if s, ok := req.Value.(string); ok {
-				var v float64
-				_, err := fmt.Sscanf(s, "%f", &v)
-				if err != nil {
-					http.Error(w, "invalid REAL value", http.StatusBadRequest)
-					return
-				}
-				value = v
-			}
+		plcVal, err := gowrapper.ConvertValue(typeVal, req.Value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-		plcVal := &gowrapper.PlcValue{Type: typeVal, Value: value}
-		err = client.WriteValue(req.Tag, plcVal)
+		ctx := gowrapper.WithCallerIdentity(r.Context(), gateway.RequestIdentity(r))
+		err = client.WriteValueContext(ctx, req.Tag, plcVal)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -211,55 +183,17 @@ func handleBatch(w http.ResponseWriter, r *http.Request) {
 		// Batch write
 		writeMap := make(map[string]interface{})
 		for _, writeReq := range req.Writes {
-			_, err := parsePlcDataType(writeReq.Type)
+			typeVal, err := parsePlcDataType(writeReq.Type)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusBadRequest)
 				return
 			}
-			var value interface{} = writeReq.Value
-			switch writeReq.Type {
-			case "Dint":
-				if f, ok := writeReq.Value.(float64); ok {
-					value = int32(f)
-				} else if i, ok := writeReq.Value.(int); ok {
-					value = int32(i)
-				} else if s, ok := writeReq.Value.(string); ok {
-					var v int32
-					_, err := fmt.Sscanf(s, "%d", &v)
-					if err != nil {
-						http.Error(w, "invalid DINT value", http.StatusBadRequest)
-						return
-					}
-					value = v
-				}
-			case "Int":
-				if f, ok := writeReq.Value.(float64); ok {
-					value = int16(f)
-				} else if i, ok := writeReq.Value.(int); ok {
-					value = int16(i)
-				} else if s, ok := writeReq.Value.(string); ok {
-					var v int16
-					_, err := fmt.Sscanf(s, "%d", &v)
-					if err != nil {
-						http.Error(w, "invalid INT value", http.StatusBadRequest)
-						return
-					}
-					value = v
-				}
-			case "Real":
-				if f, ok := writeReq.Value.(float64); ok {
-					value = f
-				} else if s, ok := writeReq.Value.(string); ok {
-					var v float64
-					_, err := fmt.Sscanf(s, "%f", &v)
-					if err != nil {
-						http.Error(w, "invalid REAL value", http.StatusBadRequest)
-						return
-					}
-					value = v
-				}
+			plcVal, err := gowrapper.ConvertValue(typeVal, writeReq.Value)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
 			}
-			writeMap[writeReq.Tag] = value
+			writeMap[writeReq.Tag] = plcVal.Value
 		}
 		err := client.BatchWrite(writeMap)
 		if err != nil {
@@ -300,7 +234,51 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// wsTag names a tag and its type in a WebSocket message
+type wsTag struct {
+	Tag  string `json:"tag"`
+	Type string `json:"type"`
+}
+
+// wsRequest is a message from a WebSocket client:
+//
+//	{"action": "subscribe", "tags": [{"tag": "Speed", "type": "REAL"}]}
+//	{"action": "write", "id": "7", "tag": "Speed", "type": "REAL", "value": 12.5}
+type wsRequest struct {
+	Action string      `json:"action"`
+	ID     string      `json:"id,omitempty"`
+	Tags   []wsTag     `json:"tags,omitempty"`
+	Tag    string      `json:"tag,omitempty"`
+	Type   string      `json:"type,omitempty"`
+	Value  interface{} `json:"value,omitempty"`
+}
+
+// wsSession is one WebSocket connection and the tags it watches
+type wsSession struct {
+	conn *websocket.Conn
+	hub  *gowrapper.Hub
+	// identity is the caller identity of the upgrade request, attached to
+	// every write of the connection
+	identity gowrapper.CallerIdentity
+
+	writeMu sync.Mutex // gorilla/websocket allows one writer at a time
+
+	mu       sync.Mutex
+	consumer *gowrapper.Consumer
+}
+
+// handleWebSocket streams changes of the watched tags and accepts writes.
+// Each write is confirmed with a write_result message, and the writer does
+// not get its own change back as an update; other connections do.
 func handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	h := hub
+	mu.Unlock()
+	if h == nil {
+		http.Error(w, "Not connected", http.StatusBadRequest)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
@@ -308,64 +286,159 @@ func handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
-	// Simulate real-time updates
+	s := &wsSession{conn: conn, hub: h, identity: gateway.RequestIdentity(r)}
+	defer s.unsubscribe()
+	// Watch the demo input until the client subscribes to its own tags
+	if err := s.subscribe([]wsTag{{Tag: "_IO_EM_DI00", Type: "BOOL"}}); err != nil {
+		s.send(map[string]interface{}{"action": "error", "error": err.Error()})
+	}
+
 	for {
-		time.Sleep(1 * time.Second)
-		mu.Lock()
-		if client == nil {
-			mu.Unlock()
+		_, reader, err := conn.NextReader()
+		if err != nil {
 			return
 		}
-		mu.Unlock()
+		var req wsRequest
+		dec := json.NewDecoder(reader)
+		dec.UseNumber()
+		if err := dec.Decode(&req); err != nil {
+			s.send(map[string]interface{}{"action": "error", "error": "invalid message: " + err.Error()})
+			continue
+		}
+		switch req.Action {
+		case "subscribe":
+			if err := s.subscribe(req.Tags); err != nil {
+				s.send(map[string]interface{}{"action": "error", "error": err.Error()})
+			}
+		case "write":
+			s.write(req)
+		default:
+			s.send(map[string]interface{}{"action": "error", "error": fmt.Sprintf("unknown action %q", req.Action)})
+		}
+	}
+}
 
-		// Example: Read a tag and send update (Bool type for demo)
-		val, err := client.ReadValue("_IO_EM_DI00", gowrapper.Bool)
+// send writes a message to the connection
+func (s *wsSession) send(msg interface{}) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := s.conn.WriteJSON(msg); err != nil {
+		log.Println(err)
+	}
+}
+
+// subscribe replaces the watched tags
+func (s *wsSession) subscribe(tags []wsTag) error {
+	members := make([]gowrapper.GroupMember, 0, len(tags))
+	for _, t := range tags {
+		dataType, err := parsePlcDataType(t.Type)
 		if err != nil {
-			log.Println(err)
-			continue
+			return err
 		}
-		conn.WriteJSON(map[string]interface{}{
-			"tag":   "_IO_EM_DI00",
-			"value": val.Value,
-			"type":  "Bool",
-		})
+		members = append(members, gowrapper.GroupMember{TagName: t.Tag, DataType: dataType})
 	}
+	consumer, err := s.hub.Subscribe(gowrapper.ConsumerOptions{Tags: members})
+	if err != nil {
+		return err
+	}
+	s.unsubscribe()
+	s.mu.Lock()
+	s.consumer = consumer
+	s.mu.Unlock()
+	go s.forward(consumer)
+	return nil
 }
 
-// parsePlcDataType converts a string to gowrapper.PlcDataType
-func parsePlcDataType(s string) (gowrapper.PlcDataType, error) {
-	switch s {
-	case "Bool":
-		return gowrapper.Bool, nil
-	case "Sint":
-		return gowrapper.Sint, nil
-	case "Int":
-		return gowrapper.Int, nil
-	case "Dint":
-		return gowrapper.Dint, nil
-	case "Lint":
-		return gowrapper.Lint, nil
-	case "Usint":
-		return gowrapper.Usint, nil
-	case "Uint":
-		return gowrapper.Uint, nil
-	case "Udint":
-		return gowrapper.Udint, nil
-	case "Ulint":
-		return gowrapper.Ulint, nil
-	case "Real":
-		return gowrapper.Real, nil
-	case "Lreal":
-		return gowrapper.Lreal, nil
-	case "String":
-		return gowrapper.String, nil
-	case "Udt":
-		return gowrapper.Udt, nil
-	default:
-		return 0, fmt.Errorf("unsupported PLC data type: %s", s)
+// unsubscribe stops watching the current tags
+func (s *wsSession) unsubscribe() {
+	s.mu.Lock()
+	consumer := s.consumer
+	s.consumer = nil
+	s.mu.Unlock()
+	if consumer != nil {
+		consumer.Close()
+	}
+}
+
+// forward sends the samples of consumer until it is closed. If the hub
+// closed it because the PLC was disconnected, the connection is closed too.
+func (s *wsSession) forward(consumer *gowrapper.Consumer) {
+	for sample := range consumer.C {
+		msg := map[string]interface{}{
+			"tag":       sample.TagName,
+			"type":      sample.Type.String(),
+			"value":     sample.Value,
+			"quality":   sample.Quality.String(),
+			"timestamp": sample.Timestamp,
+		}
+		if sample.Err != nil {
+			msg["error"] = sample.Err.Error()
+		}
+		s.send(msg)
+	}
+	s.mu.Lock()
+	current := s.consumer == consumer
+	s.mu.Unlock()
+	if current {
+		s.conn.Close()
 	}
 }
 
+// write writes a tag and confirms it with a write_result message. Once the
+// write succeeded, the update it causes is suppressed for this connection.
+// The write carries the connection's caller identity, with the message ID as
+// the correlation ID if the upgrade request had none.
+func (s *wsSession) write(req wsRequest) {
+	result := map[string]interface{}{"action": "write_result", "id": req.ID, "tag": req.Tag}
+	fail := func(err error) {
+		result["success"] = false
+		result["error"] = err.Error()
+		s.send(result)
+	}
+
+	dataType, err := parsePlcDataType(req.Type)
+	if err != nil {
+		fail(err)
+		return
+	}
+	value, err := gowrapper.NewPlcValue(dataType, req.Value)
+	if err != nil {
+		fail(err)
+		return
+	}
+	mu.Lock()
+	if client == nil {
+		mu.Unlock()
+		fail(fmt.Errorf("not connected"))
+		return
+	}
+	id := s.identity
+	if id.CorrelationID == "" {
+		id.CorrelationID = req.ID
+	}
+	err = client.WriteValueContext(gowrapper.WithCallerIdentity(context.Background(), id), req.Tag, value)
+	mu.Unlock()
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	s.mu.Lock()
+	if s.consumer != nil {
+		s.consumer.SuppressEcho(req.Tag, dataType, value.Value)
+	}
+	s.mu.Unlock()
+	result["success"] = true
+	result["value"] = value.Value
+	s.send(result)
+}
+
+// parsePlcDataType converts a string to gowrapper.PlcDataType, accepting any
+// casing or synonym understood by the wrapper ("DINT", "int32", "float", ...)
+func parsePlcDataType(s string) (gowrapper.PlcDataType, error) {
+	return gowrapper.ParsePlcDataType(s)
+}
+
 // Add handler for tag info discovery
 func handleTagInfo(w http.ResponseWriter, r *http.Request) {
 	mu.Lock()
@@ -495,6 +568,7 @@ func handleBenchmark(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	ctx := gowrapper.WithCallerIdentity(r.Context(), gateway.RequestIdentity(r))
 	readCount := 0
 	writeCount := 0
 	start := time.Now()
@@ -513,20 +587,20 @@ func handleBenchmark(w http.ResponseWriter, r *http.Request) {
 		}
 		if req.Write {
 			var writeVal interface{}
-			switch req.Type {
-			case "Bool":
+			switch typeVal {
+			case gowrapper.Bool:
 				lastBool = !lastBool
 				writeVal = lastBool
-			case "Int":
+			case gowrapper.Int:
 				lastInt++
 				writeVal = int16(lastInt)
-			case "Dint":
+			case gowrapper.Dint:
 				lastInt++
 				writeVal = int32(lastInt)
-			case "Real":
+			case gowrapper.Real:
 				lastFloat += 1.1
 				writeVal = lastFloat
-			case "String":
+			case gowrapper.String:
 				if lastString == "A" {
 					lastString = "B"
 				} else {
@@ -538,7 +612,7 @@ func handleBenchmark(w http.ResponseWriter, r *http.Request) {
 				writeVal = lastInt
 			}
 			plcVal := &gowrapper.PlcValue{Type: typeVal, Value: writeVal}
-			err := client.WriteValue(req.Tag, plcVal)
+			err := client.WriteValueContext(ctx, req.Tag, plcVal)
 			if err == nil {
 				writeCount++
 			} else {
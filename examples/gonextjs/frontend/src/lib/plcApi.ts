@@ -37,14 +37,66 @@ export async function writeTag(tag: string, value: any, type: string): Promise<b
 }
 
 export function subscribeToTagUpdates(onUpdate: (data: PlcTagValue) => void): () => void {
+  const socket = openTagSocket(onUpdate);
+  return () => socket.close();
+}
+
+export interface TagSocket {
+  /** Replaces the tags whose changes are sent to onUpdate */
+  subscribe(tags: { tag: string; type: string }[]): void;
+  /** Writes a tag; resolves with the written value once the backend confirms it */
+  write(tag: string, value: any, type: string): Promise<any>;
+  close(): void;
+}
+
+/**
+ * Opens the backend WebSocket for tag updates and writes. A write made on the
+ * socket is not echoed back to onUpdate, so controls do not jump when their
+ * own change arrives; writes from elsewhere still are.
+ */
+export function openTagSocket(onUpdate: (data: PlcTagValue) => void): TagSocket {
   const ws = new WebSocket(`ws://${window.location.hostname}:8080/ws`);
+  const pending = new Map<string, { resolve: (value: any) => void; reject: (err: Error) => void }>();
+  const queued: string[] = [];
+  let nextId = 0;
+
+  const send = (msg: object) => {
+    const text = JSON.stringify(msg);
+    if (ws.readyState === WebSocket.OPEN) ws.send(text);
+    else queued.push(text);
+  };
+  ws.onopen = () => queued.splice(0).forEach((text) => ws.send(text));
   ws.onmessage = (event) => {
+    let data: any;
     try {
-      const data = JSON.parse(event.data);
+      data = JSON.parse(event.data);
+    } catch {
+      return;
+    }
+    if (data.action === 'write_result') {
+      const request = pending.get(data.id);
+      pending.delete(data.id);
+      if (data.success) request?.resolve(data.value);
+      else request?.reject(new Error(data.error));
+    } else if (!data.action) {
       onUpdate(data);
-    } catch {}
+    }
+  };
+  ws.onclose = () => {
+    pending.forEach(({ reject }) => reject(new Error('WebSocket closed')));
+    pending.clear();
+  };
+
+  return {
+    subscribe: (tags) => send({ action: 'subscribe', tags }),
+    write: (tag, value, type) =>
+      new Promise((resolve, reject) => {
+        const id = String(++nextId);
+        pending.set(id, { resolve, reject });
+        send({ action: 'write', id, tag, type, value });
+      }),
+    close: () => ws.close(),
   };
-  return () => ws.close();
 }
 
 export async function batchReadTags(tags: { tag: string; type: string }[]): Promise<Record<string, any>> {
@@ -0,0 +1,288 @@
+// Package admin is a management API for the EtherNet/IP clients of a
+// long-running process such as a gateway. It reports each client's session,
+// queue, subscription and cache state and lets an operator trigger a
+// reconnect or flush a client's caches at runtime.
+//
+// The API has no authentication of its own. Serve it on a listener from
+// Listen, which only accepts loopback addresses and Unix sockets unless it
+// terminates TLS and verifies client certificates.
+package admin
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// Client is the client functionality the management API exposes.
+// *ethernetip.EipClient implements it.
+type Client interface {
+	GetIPAddress() string
+	SessionDiagnostics() ethernetip.SessionDiagnostics
+	QueueStats() ethernetip.QueueStats
+	SubscriptionHealth() []ethernetip.SubscriptionHealth
+	CacheContents() ethernetip.CacheContents
+	FlushCaches()
+	FailoverFor(reason ethernetip.ReconnectReason, cause error) error
+}
+
+// ClientStatus is the state of one registered client
+type ClientStatus struct {
+	Name          string                          `json:"name"`
+	Address       string                          `json:"address"`
+	Session       ethernetip.SessionDiagnostics   `json:"session"`
+	Queue         ethernetip.QueueStats           `json:"queue"`
+	Subscriptions []ethernetip.SubscriptionHealth `json:"subscriptions"`
+}
+
+// errRequested is the cause recorded for reconnects requested through the API
+var errRequested = errors.New("requested through the management API")
+
+// Server is the management API, an http.Handler over the registered clients
+type Server struct {
+	mu      sync.RWMutex
+	clients map[string]Client
+	mux     *http.ServeMux
+}
+
+// NewServer creates a management API with no clients
+func NewServer() *Server {
+	s := &Server{clients: make(map[string]Client), mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /clients", s.handleList)
+	s.mux.HandleFunc("GET /clients/{name}", s.handleStatus)
+	s.mux.HandleFunc("GET /clients/{name}/subscriptions", s.handleSubscriptions)
+	s.mux.HandleFunc("GET /clients/{name}/cache", s.handleCache)
+	s.mux.HandleFunc("POST /clients/{name}/cache/flush", s.handleFlush)
+	s.mux.HandleFunc("POST /clients/{name}/reconnect", s.handleReconnect)
+	return s
+}
+
+// Register adds a client under name, replacing any client of that name
+func (s *Server) Register(name string, client Client) {
+	s.mu.Lock()
+	s.clients[name] = client
+	s.mu.Unlock()
+}
+
+// Unregister removes the client registered under name
+func (s *Server) Unregister(name string) {
+	s.mu.Lock()
+	delete(s.clients, name)
+	s.mu.Unlock()
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Status returns the state of every registered client, sorted by name
+func (s *Server) Status() []ClientStatus {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.clients))
+	for name := range s.clients {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+	sort.Strings(names)
+
+	statuses := make([]ClientStatus, 0, len(names))
+	for _, name := range names {
+		if client, ok := s.client(name); ok {
+			statuses = append(statuses, status(name, client))
+		}
+	}
+	return statuses
+}
+
+// client returns the client registered under name
+func (s *Server) client(name string) (Client, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	client, ok := s.clients[name]
+	return client, ok
+}
+
+// status collects the state of a client
+func status(name string, client Client) ClientStatus {
+	return ClientStatus{
+		Name:          name,
+		Address:       client.GetIPAddress(),
+		Session:       client.SessionDiagnostics(),
+		Queue:         client.QueueStats(),
+		Subscriptions: client.SubscriptionHealth(),
+	}
+}
+
+// lookup returns the client named in the request path, answering 404 if
+// there is none
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (Client, bool) {
+	name := r.PathValue("name")
+	client, ok := s.client(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no client named '%s'", name))
+	}
+	return client, ok
+}
+
+// handleList handles GET /clients
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Status())
+}
+
+// handleStatus handles GET /clients/{name}
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if client, ok := s.lookup(w, r); ok {
+		writeJSON(w, http.StatusOK, status(r.PathValue("name"), client))
+	}
+}
+
+// handleSubscriptions handles GET /clients/{name}/subscriptions
+func (s *Server) handleSubscriptions(w http.ResponseWriter, r *http.Request) {
+	if client, ok := s.lookup(w, r); ok {
+		writeJSON(w, http.StatusOK, client.SubscriptionHealth())
+	}
+}
+
+// handleCache handles GET /clients/{name}/cache
+func (s *Server) handleCache(w http.ResponseWriter, r *http.Request) {
+	if client, ok := s.lookup(w, r); ok {
+		writeJSON(w, http.StatusOK, client.CacheContents())
+	}
+}
+
+// handleFlush handles POST /clients/{name}/cache/flush
+func (s *Server) handleFlush(w http.ResponseWriter, r *http.Request) {
+	if client, ok := s.lookup(w, r); ok {
+		client.FlushCaches()
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleReconnect handles POST /clients/{name}/reconnect. The reconnect is
+// recorded in the client's history as an explicit close.
+func (s *Server) handleReconnect(w http.ResponseWriter, r *http.Request) {
+	client, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	if err := client.FailoverFor(ethernetip.ReconnectExplicitClose, errRequested); err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, client.SessionDiagnostics())
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"error": message})
+}
+
+// Listen opens a listener for the management API. An address of the form
+// "unix:/path/to/socket" opens a Unix socket that only its owner may use,
+// replacing a stale socket file. Other addresses are TCP and must be
+// loopback ("localhost:9090", "127.0.0.1:9090") unless tlsConfig requires
+// and verifies client certificates (tls.RequireAndVerifyClientCert), since
+// the API has no other authentication. With a tlsConfig, connections are
+// TLS-terminated by the listener.
+func Listen(address string, tlsConfig *tls.Config) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		l, err := listenUnix(path)
+		if err != nil {
+			return nil, err
+		}
+		return wrapTLS(l, tlsConfig), nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if !loopback(host) && (tlsConfig == nil || tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert) {
+		return nil, fmt.Errorf("management API address %s is not loopback; use a Unix socket, a loopback address or TLS with verified client certificates", address)
+	}
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	return wrapTLS(l, tlsConfig), nil
+}
+
+// listenUnix opens an owner-only Unix socket at path. The socket is bound in
+// a private (0700) directory next to path and moved into place once its mode
+// is 0600, so it is never reachable with the default permissions.
+func listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".admin-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	private := filepath.Join(dir, "sock")
+	l, err := net.Listen("unix", private)
+	if err != nil {
+		return nil, err
+	}
+	ul := l.(*net.UnixListener)
+	// The bound path is moved away; unixListener removes the final one
+	ul.SetUnlinkOnClose(false)
+	if err := os.Chmod(private, 0o600); err != nil {
+		ul.Close()
+		return nil, err
+	}
+	if err := os.Rename(private, path); err != nil {
+		ul.Close()
+		return nil, err
+	}
+	return &unixListener{UnixListener: ul, path: path}, nil
+}
+
+// unixListener removes its socket file when closed
+type unixListener struct {
+	*net.UnixListener
+	path string
+}
+
+// Close closes the listener and removes the socket file
+func (l *unixListener) Close() error {
+	err := l.UnixListener.Close()
+	os.Remove(l.path)
+	return err
+}
+
+// wrapTLS terminates TLS on l if tlsConfig is not nil
+func wrapTLS(l net.Listener, tlsConfig *tls.Config) net.Listener {
+	if tlsConfig == nil {
+		return l
+	}
+	return tls.NewListener(l, tlsConfig)
+}
+
+// loopback reports whether host only accepts local connections
+func loopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,157 @@
+package admin
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// *EipClient is managed directly
+var _ Client = (*ethernetip.EipClient)(nil)
+
+// fakeClient records the management actions taken on it
+type fakeClient struct {
+	flushes    int
+	reconnects []ethernetip.ReconnectReason
+	causes     []error
+	failover   error
+}
+
+func (f *fakeClient) GetIPAddress() string { return "192.168.1.10" }
+
+func (f *fakeClient) SessionDiagnostics() ethernetip.SessionDiagnostics {
+	return ethernetip.SessionDiagnostics{Failovers: int64(len(f.reconnects))}
+}
+
+func (f *fakeClient) QueueStats() ethernetip.QueueStats { return ethernetip.QueueStats{Submitted: 42} }
+
+func (f *fakeClient) SubscriptionHealth() []ethernetip.SubscriptionHealth {
+	return []ethernetip.SubscriptionHealth{{TagName: "Speed", Type: ethernetip.Dint}}
+}
+
+func (f *fakeClient) CacheContents() ethernetip.CacheContents {
+	return ethernetip.CacheContents{TagMetadata: map[string]ethernetip.TagMetadata{"speed": {DataType: 0xC4}}}
+}
+
+func (f *fakeClient) FlushCaches() { f.flushes++ }
+
+func (f *fakeClient) FailoverFor(reason ethernetip.ReconnectReason, cause error) error {
+	f.reconnects = append(f.reconnects, reason)
+	f.causes = append(f.causes, cause)
+	return f.failover
+}
+
+// do sends a request to s and returns the response
+func do(s *Server, method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+// TestServer tests the status and action endpoints
+func TestServer(t *testing.T) {
+	s := NewServer()
+	line1, line2 := &fakeClient{}, &fakeClient{failover: errors.New("connection refused")}
+	s.Register("line2", line2)
+	s.Register("line1", line1)
+
+	// Health states are encoded by name, so decode only what is checked
+	var statuses []struct {
+		Name          string
+		Address       string
+		Queue         ethernetip.QueueStats
+		Subscriptions []struct {
+			TagName string `json:"tag_name"`
+		}
+	}
+	rec := do(s, "GET", "/clients")
+	if err := json.Unmarshal(rec.Body.Bytes(), &statuses); err != nil || len(statuses) != 2 || statuses[0].Name != "line1" {
+		t.Fatalf("Expected both clients sorted by name, got %s (%v)", rec.Body, err)
+	}
+	if statuses[0].Queue.Submitted != 42 || statuses[0].Subscriptions[0].TagName != "Speed" || statuses[0].Address != "192.168.1.10" {
+		t.Errorf("Unexpected status %+v", statuses[0])
+	}
+
+	var cache ethernetip.CacheContents
+	if rec := do(s, "GET", "/clients/line1/cache"); json.Unmarshal(rec.Body.Bytes(), &cache) != nil || cache.TagMetadata["speed"].DataType != 0xC4 {
+		t.Errorf("Unexpected cache contents %s", rec.Body)
+	}
+	if rec := do(s, "POST", "/clients/line1/cache/flush"); rec.Code != http.StatusNoContent || line1.flushes != 1 {
+		t.Errorf("Expected the cache flushed, got %d and %d flushes", rec.Code, line1.flushes)
+	}
+
+	if rec := do(s, "POST", "/clients/line1/reconnect"); rec.Code != http.StatusOK || len(line1.reconnects) != 1 ||
+		line1.reconnects[0] != ethernetip.ReconnectExplicitClose || line1.causes[0] != errRequested {
+		t.Errorf("Expected an explicit reconnect, got %d and %v", rec.Code, line1.reconnects)
+	}
+	if rec := do(s, "POST", "/clients/line2/reconnect"); rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected a failed reconnect to answer 502, got %d", rec.Code)
+	}
+
+	s.Unregister("line2")
+	if rec := do(s, "GET", "/clients/line2"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unregistered client, got %d", rec.Code)
+	}
+	if rec := do(s, "GET", "/clients/line1/reconnect"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected actions to require POST, got %d", rec.Code)
+	}
+}
+
+// TestListen tests that only local listeners are opened without TLS
+func TestListen(t *testing.T) {
+	if _, err := Listen("0.0.0.0:0", nil); err == nil {
+		t.Error("Expected a non-loopback address to be refused without TLS")
+	}
+	if _, err := Listen("0.0.0.0:0", &tls.Config{}); err == nil {
+		t.Error("Expected a non-loopback address to be refused without client certificates")
+	}
+	l, err := Listen("0.0.0.0:0", &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert})
+	if err != nil {
+		t.Fatalf("Expected a TLS listener, got %v", err)
+	}
+	l.Close()
+
+	for _, address := range []string{"127.0.0.1:0", "localhost:0", "unix:" + filepath.Join(t.TempDir(), "admin.sock")} {
+		l, err := Listen(address, nil)
+		if err != nil {
+			t.Errorf("Failed to listen on %s: %v", address, err)
+			continue
+		}
+		l.Close()
+	}
+}
+
+// TestListenUnix tests that the socket is owner-only, reachable at its path
+// and removed when closed
+func TestListenUnix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "admin.sock")
+	l, err := Listen("unix:"+path, nil)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("Expected an owner-only socket, got %v, %v", info, err)
+	}
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	conn.Close()
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only the socket in %s, got %d entries", dir, len(entries))
+	}
+	l.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket removed, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package ethernetip
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/batch"
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/tagpath"
+)
+
+// tagAliases is the client's dictionary of logical tag names, keyed by
+// TagNameOptions.Key
+type tagAliases struct {
+	mu    sync.RWMutex
+	names map[string]string // Key of the alias -> PLC tag
+	// aliases maps the aliases, as written, to their tags
+	aliases map[string]string
+
+	// The file watched by WatchTagAliases, if any
+	watchMu sync.Mutex
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// SetTagAliases replaces the client's alias dictionary. Afterwards reads and
+// writes by an alias, such as "line1.speed", go to its tag, such as
+// "Program:Line1.VFD.SpeedFbk"; names that are not aliases are used as they
+// are. Aliases are matched with the client's TagNameOptions. Every tag must
+// be a valid tag name; nothing changes if one is not. nil removes all
+// aliases.
+func (c *EipClient) SetTagAliases(aliases map[string]string) error {
+	opts := c.TagNameOptions()
+	names := make(map[string]string, len(aliases))
+	for alias, tag := range aliases {
+		alias, tag = opts.Clean(alias), opts.Clean(tag)
+		if alias == "" {
+			return NewEipError(ErrInvalidTagName, "tag alias cannot be empty")
+		}
+		if _, err := tagpath.Parse(tag); err != nil {
+			return NewEipErrorWithDetails(ErrInvalidTagName, fmt.Sprintf("alias '%s': %v", alias, err),
+				map[string]interface{}{"alias": alias, "tag_name": tag})
+		}
+		if other, dup := names[opts.Key(alias)]; dup && other != tag {
+			return NewEipErrorWithDetails(ErrInvalidTagName, fmt.Sprintf("alias '%s' is defined twice", alias),
+				map[string]interface{}{"alias": alias})
+		}
+		names[opts.Key(alias)] = tag
+	}
+
+	c.aliases.mu.Lock()
+	c.aliases.names = names
+	c.aliases.aliases = make(map[string]string, len(aliases))
+	for alias, tag := range aliases {
+		c.aliases.aliases[opts.Clean(alias)] = opts.Clean(tag)
+	}
+	c.aliases.mu.Unlock()
+	return nil
+}
+
+// TagAliases returns a copy of the alias dictionary
+func (c *EipClient) TagAliases() map[string]string {
+	c.aliases.mu.RLock()
+	defer c.aliases.mu.RUnlock()
+	aliases := make(map[string]string, len(c.aliases.aliases))
+	for alias, tag := range c.aliases.aliases {
+		aliases[alias] = tag
+	}
+	return aliases
+}
+
+// ResolveAlias returns the tag an alias stands for, or name itself when it
+// is not an alias
+func (c *EipClient) ResolveAlias(name string) string {
+	c.aliases.mu.RLock()
+	empty := len(c.aliases.names) == 0
+	c.aliases.mu.RUnlock()
+	if empty {
+		return name
+	}
+	key := c.TagNameOptions().Key(name)
+	c.aliases.mu.RLock()
+	defer c.aliases.mu.RUnlock()
+	if tag, ok := c.aliases.names[key]; ok {
+		return tag
+	}
+	return name
+}
+
+// resolveAliases resolves names as aliases for an operation on several
+// tags (see batch.ResolveNames)
+func (c *EipClient) resolveAliases(names []string) (tags []string, requested map[string][]string) {
+	return batch.ResolveNames(names, c.ResolveAlias)
+}
+
+// LoadTagAliases replaces the alias dictionary with one read from r, as JSON
+// or YAML. Nested mappings are joined with dots, so both files below define
+// "line1.speed":
+//
+//	{"line1": {"speed": "Program:Line1.VFD.SpeedFbk"}}
+//
+//	line1:
+//	  speed: Program:Line1.VFD.SpeedFbk
+//
+// YAML is limited to nested mappings of scalars and comments, as for
+// recipes.
+func (c *EipClient) LoadTagAliases(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read tag aliases: %w", err)
+	}
+	aliases, err := parseTagAliases(data)
+	if err != nil {
+		return err
+	}
+	return c.SetTagAliases(aliases)
+}
+
+// LoadTagAliasesFile replaces the alias dictionary with the one in a file
+// (see LoadTagAliases)
+func (c *EipClient) LoadTagAliasesFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read tag aliases: %w", err)
+	}
+	defer f.Close()
+	return c.LoadTagAliases(f)
+}
+
+// parseTagAliases decodes an alias file into a flat dictionary
+func parseTagAliases(data []byte) (map[string]string, error) {
+	var doc map[string]interface{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		parsed, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse tag aliases: %w", err)
+		}
+		doc = parsed
+	} else if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse tag aliases: %w", err)
+	}
+	aliases := make(map[string]string)
+	if err := flattenAliases(aliases, "", doc); err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// flattenAliases adds the aliases of a mapping, prefixed by prefix
+func flattenAliases(aliases map[string]string, prefix string, doc map[string]interface{}) error {
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		alias := key
+		if prefix != "" {
+			alias = prefix + "." + key
+		}
+		switch v := doc[key].(type) {
+		case string:
+			if _, dup := aliases[alias]; dup {
+				return fmt.Errorf("failed to parse tag aliases: '%s' is defined twice", alias)
+			}
+			aliases[alias] = v
+		case map[string]interface{}:
+			if err := flattenAliases(aliases, alias, v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("failed to parse tag aliases: '%s' must be a tag name or a mapping", alias)
+		}
+	}
+	return nil
+}
+
+// WatchTagAliases loads the alias dictionary from a file and reloads it
+// whenever the file's modification time or size changes, checking every
+// interval. A file that fails to load leaves the previous dictionary in
+// place. onReload, if not nil, is called after every reload with its
+// result. Watching another file stops the previous watch; stop ends it.
+func (c *EipClient) WatchTagAliases(path string, interval time.Duration, onReload func(error)) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, NewEipError(ErrInvalidOperation, "alias reload interval must be positive")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag aliases: %w", err)
+	}
+	if err := c.LoadTagAliasesFile(path); err != nil {
+		return nil, err
+	}
+
+	c.stopAliasWatch()
+	a := &c.aliases
+	a.watchMu.Lock()
+	defer a.watchMu.Unlock()
+	done := make(chan struct{})
+	a.stop = done
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := c.Clock().NewTicker(interval)
+		defer ticker.Stop()
+		modTime, size := info.ModTime(), info.Size()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C():
+			}
+			info, err := os.Stat(path)
+			if err == nil && info.ModTime().Equal(modTime) && info.Size() == size {
+				continue
+			}
+			if err == nil {
+				modTime, size = info.ModTime(), info.Size()
+				err = c.LoadTagAliasesFile(path)
+			}
+			if onReload != nil {
+				onReload(err)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			a.watchMu.Lock()
+			defer a.watchMu.Unlock()
+			if a.stop == done {
+				close(done)
+				a.stop = nil
+				a.wg.Wait()
+			}
+		})
+	}, nil
+}
+
+// stopAliasWatch stops the WatchTagAliases watch, if any
+func (c *EipClient) stopAliasWatch() {
+	a := &c.aliases
+	a.watchMu.Lock()
+	defer a.watchMu.Unlock()
+	if a.stop != nil {
+		close(a.stop)
+		a.stop = nil
+		a.wg.Wait()
+	}
+}
@@ -0,0 +1,135 @@
+package ethernetip
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTagAliases tests defining aliases and reading through them
+func TestTagAliases(t *testing.T) {
+	client := &EipClient{tagNames: LogixTagNames}
+	if err := client.DefineVirtualTag("Answer", "6 * 7"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetTagAliases(map[string]string{
+		"line1.speed":  "Program:Line1.VFD.SpeedFbk",
+		"line1.answer": "Answer",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := client.ResolveAlias("Line1.Speed"); got != "Program:Line1.VFD.SpeedFbk" {
+		t.Errorf("Expected aliases to follow the name options, got %s", got)
+	}
+	if got := client.ResolveAlias("Motor1"); got != "Motor1" {
+		t.Errorf("Expected other names unchanged, got %s", got)
+	}
+	value, err := client.ReadValue("line1.answer", Dint)
+	if err != nil || value.Value != int32(42) {
+		t.Errorf("Expected 42 through the alias, got %v, %v", value, err)
+	}
+	var eipErr *EipError
+	if err := client.WriteValue("line1.answer", &PlcValue{Type: Dint, Value: int32(1)}); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagAccess {
+		t.Errorf("Expected the write to reach the read-only virtual tag, got %v", err)
+	}
+
+	if err := client.SetTagAliases(map[string]string{"bad": "Tag[1"}); err == nil {
+		t.Error("Expected an error for an invalid tag")
+	}
+	if len(client.TagAliases()) != 2 {
+		t.Error("Expected a failed update to keep the previous aliases")
+	}
+	if err := client.SetTagAliases(map[string]string{"a": "X", "A": "Y"}); err == nil {
+		t.Error("Expected an error for an alias defined twice")
+	}
+}
+
+// TestLoadTagAliases tests JSON and YAML alias files
+func TestLoadTagAliases(t *testing.T) {
+	client := &EipClient{}
+	yaml := `# Line 1
+line1:
+  speed: Program:Line1.VFD.SpeedFbk
+  vfd:
+    fault: "Program:Line1.VFD.Fault"
+line2.speed: Program:Line2.VFD.SpeedFbk
+`
+	if err := client.LoadTagAliases(strings.NewReader(yaml)); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"line1.speed":     "Program:Line1.VFD.SpeedFbk",
+		"line1.vfd.fault": "Program:Line1.VFD.Fault",
+		"line2.speed":     "Program:Line2.VFD.SpeedFbk",
+	}
+	got := client.TagAliases()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for alias, tag := range want {
+		if got[alias] != tag {
+			t.Errorf("%s: got %q, want %q", alias, got[alias], tag)
+		}
+	}
+
+	if err := client.LoadTagAliases(strings.NewReader(`{"line1": {"speed": "Speed"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if client.ResolveAlias("line1.speed") != "Speed" || client.ResolveAlias("line2.speed") != "line2.speed" {
+		t.Error("Expected loading to replace the dictionary")
+	}
+	for _, bad := range []string{`{"a": 1}`, "a:\n  - b\n", `{"a": {"b": "X"}, "a.b": "Y"}`} {
+		if err := client.LoadTagAliases(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+// TestWatchTagAliases tests reloading the alias file when it changes
+func TestWatchTagAliases(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	client := &EipClient{}
+	client.SetClock(clock)
+	path := filepath.Join(t.TempDir(), "aliases.yaml")
+	if err := os.WriteFile(path, []byte("speed: Speed1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reloads := make(chan error, 1)
+	stop, err := client.WatchTagAliases(path, time.Second, func(err error) { reloads <- err })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	if client.ResolveAlias("speed") != "Speed1" {
+		t.Fatal("Expected the file to be loaded")
+	}
+
+	rewrite := func(content string, at time.Time) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, at, at); err != nil {
+			t.Fatal(err)
+		}
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+	}
+	rewrite("speed: Speed2\n", time.Now().Add(time.Minute))
+	if err := <-reloads; err != nil || client.ResolveAlias("speed") != "Speed2" {
+		t.Errorf("Expected the change to be loaded, got %v", err)
+	}
+	rewrite("speed: [\n", time.Now().Add(2*time.Minute))
+	if err := <-reloads; err == nil || client.ResolveAlias("speed") != "Speed2" {
+		t.Errorf("Expected a bad file to keep the aliases, got %v", err)
+	}
+
+	stop()
+	if clock.Waiters() != 0 {
+		t.Error("Expected stop to end the watch")
+	}
+}
@@ -0,0 +1,419 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// ArraySlice is a run of consecutive array elements
+type ArraySlice struct {
+	TagName string        `json:"tag_name"`
+	Start   int           `json:"start"`
+	Type    PlcDataType   `json:"data_type"`
+	Values  []interface{} `json:"values"`
+}
+
+// arrayElementPath encodes the request path of element start of an array tag
+func arrayElementPath(tagName string, start int) ([]byte, error) {
+	if start < 0 {
+		return nil, NewEipError(ErrInvalidTagDimension, fmt.Sprintf("negative array index %d", start))
+	}
+	return tagRequestPath(fmt.Sprintf("%s[%d]", tagName, start))
+}
+
+// CheckArrayBounds checks that count elements starting at index start lie
+// within the array tag, using the tag's cached metadata, and fails with
+// ErrIndexOutOfRange reporting the array's size instead of sending a request
+// the controller would reject with a generic path error. Only
+// one-dimensional arrays of known size are checked; tags whose metadata cannot
+// be read are left to the controller.
+func (c *EipClient) CheckArrayBounds(tagName string, start, count int) error {
+	return c.checkBounds(c.ResolveAlias(tagName), start, count)
+}
+
+// checkBounds is CheckArrayBounds for a tag name that is not an alias
+func (c *EipClient) checkBounds(tagName string, start, count int) error {
+	meta, err := c.GetTagMetadataCached(tagName)
+	if err != nil {
+		return nil
+	}
+	return checkArrayBounds(tagName, meta, start, count)
+}
+
+// checkArrayBounds checks elements start to start+count-1 against meta
+func checkArrayBounds(tagName string, meta *TagMetadata, start, count int) error {
+	switch {
+	case meta.ArrayDimension == 0:
+		return NewEipErrorWithDetails(ErrIndexOutOfRange, fmt.Sprintf("'%s' is not an array", tagName),
+			map[string]interface{}{"tag_name": tagName, "start": start, "count": count})
+	case meta.ArrayDimension > 1, meta.ArraySize == 0:
+		// Only one-dimensional arrays of known size are checked
+		return nil
+	case start >= 0 && count >= 0 && start+count <= meta.ArraySize:
+		return nil
+	}
+	details := map[string]interface{}{"tag_name": tagName, "start": start, "count": count, "array_size": meta.ArraySize}
+	if count == 1 {
+		return NewEipErrorWithDetails(ErrIndexOutOfRange,
+			fmt.Sprintf("index %d of '%s' out of range: the array has %d elements", start, tagName, meta.ArraySize), details)
+	}
+	return NewEipErrorWithDetails(ErrIndexOutOfRange,
+		fmt.Sprintf("elements %d to %d of '%s' out of range: the array has %d elements", start, start+count-1, tagName, meta.ArraySize), details)
+}
+
+// arraySize returns the number of elements of a one-dimensional array tag,
+// from its cached metadata
+func (c *EipClient) arraySize(tagName string) (int, error) {
+	meta, err := c.GetTagMetadataCached(tagName)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case meta.ArrayDimension == 0:
+		return 0, NewEipErrorWithDetails(ErrIndexOutOfRange, fmt.Sprintf("'%s' is not an array", tagName),
+			map[string]interface{}{"tag_name": tagName})
+	case meta.ArrayDimension > 1 || meta.ArraySize == 0:
+		return 0, NewEipErrorWithDetails(ErrInvalidTagLength,
+			fmt.Sprintf("the size of '%s' is not known; pass an element count", tagName),
+			map[string]interface{}{"tag_name": tagName, "array_dimension": meta.ArrayDimension})
+	}
+	return meta.ArraySize, nil
+}
+
+// ReadArraySlice reads count elements of an array tag starting at index start,
+// e.g. ReadArraySlice("Recipe.Steps", 10, 20) reads elements 10 to 29. The
+// elements are fetched with Read Tag Fragmented, so slices larger than one
+// packet are transferred in several round trips. The element type is taken
+// from the controller's reply; arrays of atomic types are supported. Slices
+// past the end of the array fail with ErrIndexOutOfRange (see
+// CheckArrayBounds). A count of 0 reads the rest of the array from start,
+// sized from the tag's metadata, so ReadArraySlice("Temps", 0, 0) reads the
+// whole array.
+func (c *EipClient) ReadArraySlice(tagName string, start, count int) (*ArraySlice, error) {
+	var slice *ArraySlice
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		slice, err = c.readArraySlice(tagName, start, count)
+		return err
+	})
+	return slice, err
+}
+
+// readArraySlice is ReadArraySlice without queuing
+func (c *EipClient) readArraySlice(tagName string, start, count int) (*ArraySlice, error) {
+	if count == 0 {
+		size, err := c.arraySize(tagName)
+		if err != nil {
+			return nil, err
+		}
+		if count = size - start; count <= 0 {
+			return nil, c.checkBounds(tagName, start, 1)
+		}
+	}
+	if count < 0 {
+		return nil, NewEipError(ErrInvalidTagLength, fmt.Sprintf("element count must be positive, got %d", count))
+	}
+	if count > 0xFFFF {
+		return nil, NewEipError(ErrInvalidTagLength, fmt.Sprintf("element count %d exceeds 65535", count))
+	}
+	path, err := arrayElementPath(tagName, start)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkBounds(tagName, start, count); err != nil {
+		return nil, err
+	}
+
+	code, data, err := c.readFragmented(path, count)
+	if err != nil {
+		return nil, err
+	}
+	dataType, values, err := decodeArrayElements(code, data, count)
+	if err != nil {
+		return nil, err
+	}
+	return &ArraySlice{TagName: tagName, Start: start, Type: dataType, Values: values}, nil
+}
+
+// readFragmented reads count elements at path with as many Read Tag Fragmented
+// requests as needed, returning the CIP type code and the element data
+func (c *EipClient) readFragmented(path []byte, count int) (uint16, []byte, error) {
+	var code uint16
+	var data []byte
+	for {
+		resp, err := c.SendCIPMessage(CIPServiceReadTagFragmented, path, readFragmentedRequest(count, len(data)))
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(resp.Data) < 2 {
+			return 0, nil, NewEipError(ErrInvalidValue, "Read Tag Fragmented reply too short")
+		}
+		code = binary.LittleEndian.Uint16(resp.Data)
+		data = append(data, resp.Data[2:]...)
+		if resp.GeneralStatus != CIPStatusPartialTransfer {
+			return code, data, nil
+		}
+		if len(resp.Data) == 2 {
+			return 0, nil, NewEipError(ErrInvalidValue, "Read Tag Fragmented made no progress")
+		}
+	}
+}
+
+// WriteArraySlice writes values to consecutive elements of an array tag
+// starting at index start, leaving the other elements untouched. Values may
+// be any Go numbers that fit dataType. Large slices are sent in several
+// Write Tag Fragmented requests. Slices past the end of the array fail with
+// ErrIndexOutOfRange before anything is written.
+func (c *EipClient) WriteArraySlice(tagName string, start int, dataType PlcDataType, values []interface{}) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		label := fmt.Sprintf("%s[%d..%d]", tagName, start, start+len(values)-1)
+		return c.auditedAs(label, dataType.String(), values, func() error {
+			return c.writeArraySlice(tagName, start, dataType, values)
+		})
+	})
+}
+
+// writeArraySlice is WriteArraySlice without auditing
+func (c *EipClient) writeArraySlice(tagName string, start int, dataType PlcDataType, values []interface{}) error {
+	if len(values) == 0 {
+		return NewEipError(ErrInvalidTagLength, "no values to write")
+	}
+	if len(values) > 0xFFFF {
+		return NewEipError(ErrInvalidTagLength, fmt.Sprintf("element count %d exceeds 65535", len(values)))
+	}
+	path, err := arrayElementPath(tagName, start)
+	if err != nil {
+		return err
+	}
+	if err := c.checkBounds(tagName, start, len(values)); err != nil {
+		return err
+	}
+	requests, err := writeFragmentedRequests(dataType, values, len(path))
+	if err != nil {
+		return err
+	}
+	for _, req := range requests {
+		if _, err := c.SendCIPMessage(CIPServiceWriteTagFragmented, path, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteArrayElements writes selected elements of an array tag, keyed by
+// index, leaving the others untouched, e.g. {12: 1.5, 13: 2.0, 40: 0} writes
+// elements 12, 13 and 40. Consecutive indexes are written as one slice and
+// the slices are packed into Multiple Service Packets. Elements of BOOL
+// arrays are changed with one Read-Modify-Write Tag request per 32-bit word,
+// so other bits of the word, written by the controller or other clients, are
+// kept. Slices that fail are reported together in an ErrBatchOperationFailed
+// error while the others take effect. An index past the end of the array
+// fails with ErrIndexOutOfRange before anything is written.
+func (c *EipClient) WriteArrayElements(tagName string, dataType PlcDataType, values map[int]interface{}) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.auditedAs(tagName, dataType.String(), values, func() error {
+			return c.writeArrayElements(tagName, dataType, values)
+		})
+	})
+}
+
+// writeArrayElements is WriteArrayElements without auditing
+func (c *EipClient) writeArrayElements(tagName string, dataType PlcDataType, values map[int]interface{}) error {
+	if len(values) == 0 {
+		return NewEipError(ErrInvalidTagLength, "no values to write")
+	}
+	highest := 0
+	for index := range values {
+		if index < 0 {
+			return NewEipError(ErrInvalidTagDimension, fmt.Sprintf("negative array index %d", index))
+		}
+		highest = max(highest, index)
+	}
+	if err := c.checkBounds(tagName, highest, 1); err != nil {
+		return err
+	}
+
+	var labels []string
+	var requests [][]byte
+	var failed []string
+	if dataType == Bool {
+		words, err := boolArrayMasks(values)
+		if err != nil {
+			return err
+		}
+		for _, word := range words {
+			path, err := arrayElementPath(tagName, word.index)
+			if err != nil {
+				return err
+			}
+			req := append([]byte{CIPServiceReadModifyWriteTag, byte(len(path) / 2)}, path...)
+			labels = append(labels, fmt.Sprintf("%s[%d..%d]", tagName, word.index*32, word.index*32+31))
+			requests = append(requests, append(req, readModifyWriteRequest(4, uint64(word.or), uint64(word.and))...))
+		}
+	} else {
+		for _, run := range arrayRuns(values) {
+			label := fmt.Sprintf("%s[%d..%d]", tagName, run.start, run.start+len(run.values)-1)
+			req, err := writeArrayRunRequest(tagName, run, dataType)
+			if err != nil {
+				return err
+			}
+			// Service count, offset and Message Router header of a packet
+			if 10+len(req) > maxUnconnectedMessageSize {
+				if err := c.writeArraySlice(tagName, run.start, dataType, run.values); err != nil {
+					failed = append(failed, fmt.Sprintf("%s (%v)", label, err))
+				}
+				continue
+			}
+			labels = append(labels, label)
+			requests = append(requests, req)
+		}
+	}
+	failed = append(failed, c.sendWriteRequests(labels, requests)...)
+	return bindingError("array write", failed)
+}
+
+// arrayRun is a run of consecutive array elements to write
+type arrayRun struct {
+	start  int
+	values []interface{}
+}
+
+// arrayRuns groups values by index into runs of consecutive elements, in
+// index order
+func arrayRuns(values map[int]interface{}) []arrayRun {
+	indexes := make([]int, 0, len(values))
+	for index := range values {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	var runs []arrayRun
+	for _, index := range indexes {
+		if n := len(runs); n > 0 && runs[n-1].start+len(runs[n-1].values) == index {
+			runs[n-1].values = append(runs[n-1].values, values[index])
+			continue
+		}
+		runs = append(runs, arrayRun{start: index, values: []interface{}{values[index]}})
+	}
+	return runs
+}
+
+// writeArrayRunRequest encodes a Write Tag request for a run of elements
+func writeArrayRunRequest(tagName string, run arrayRun, dataType PlcDataType) ([]byte, error) {
+	code, _, ok := cipTypeInfo(dataType)
+	if !ok || dataType == String {
+		return nil, NewEipError(ErrInvalidDataType, fmt.Sprintf("array writes do not support %s", dataType))
+	}
+	path, err := arrayElementPath(tagName, run.start)
+	if err != nil {
+		return nil, err
+	}
+	req := append([]byte{CIPServiceWriteTag, byte(len(path) / 2)}, path...)
+	req = binary.LittleEndian.AppendUint16(req, code)
+	req = binary.LittleEndian.AppendUint16(req, uint16(len(run.values)))
+	for i, v := range run.values {
+		data, err := encodeElement(dataType, v)
+		if err != nil {
+			return nil, NewEipErrorWithDetails(ErrInvalidTagValue, fmt.Sprintf("element %d: %v", run.start+i, err),
+				map[string]interface{}{"index": run.start + i})
+		}
+		req = append(req, data...)
+	}
+	return req, nil
+}
+
+// boolWordMask is a Read-Modify-Write of one 32-bit word of a BOOL array
+type boolWordMask struct {
+	index   int // Word index: elements 32*index to 32*index+31
+	or, and uint32
+}
+
+// boolArrayMasks turns BOOL element values into word masks, in word order
+func boolArrayMasks(values map[int]interface{}) ([]boolWordMask, error) {
+	words := make(map[int]*boolWordMask)
+	for index, v := range values {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, NewEipErrorWithDetails(ErrInvalidTagValue, fmt.Sprintf("element %d: expected bool, got %T", index, v),
+				map[string]interface{}{"index": index})
+		}
+		word := words[index/32]
+		if word == nil {
+			word = &boolWordMask{index: index / 32, and: ^uint32(0)}
+			words[index/32] = word
+		}
+		if bit := uint32(1) << (index % 32); b {
+			word.or |= bit
+		} else {
+			word.and &^= bit
+		}
+	}
+	masks := make([]boolWordMask, 0, len(words))
+	for _, word := range words {
+		masks = append(masks, *word)
+	}
+	sort.Slice(masks, func(i, j int) bool { return masks[i].index < masks[j].index })
+	return masks, nil
+}
+
+// readFragmentedRequest encodes the request data of Read Tag Fragmented
+func readFragmentedRequest(count, offset int) []byte {
+	data := make([]byte, 6)
+	binary.LittleEndian.PutUint16(data, uint16(count))
+	binary.LittleEndian.PutUint32(data[2:], uint32(offset))
+	return data
+}
+
+// decodeArrayElements decodes count consecutive atomic values
+func decodeArrayElements(code uint16, data []byte, count int) (PlcDataType, []interface{}, error) {
+	dataType, ok := atomicDataType(code)
+	if !ok || dataType == Bool {
+		// BOOL arrays are packed into 32-bit words and need bit addressing
+		return 0, nil, NewEipErrorWithDetails(ErrInvalidDataType, "array slices support atomic numeric types only",
+			map[string]interface{}{"cip_type": code})
+	}
+	_, size, _ := cipTypeInfo(dataType)
+	if len(data) < count*size {
+		return 0, nil, NewEipErrorWithDetails(ErrInvalidValue, "array reply truncated",
+			map[string]interface{}{"length": len(data), "expected": count * size})
+	}
+	values := make([]interface{}, count)
+	for i := range values {
+		values[i] = decodeElement(dataType, data[i*size:])
+	}
+	return dataType, values, nil
+}
+
+// writeFragmentedRequests encodes values as the request data of one or more
+// Write Tag Fragmented requests, each small enough for an unconnected message
+func writeFragmentedRequests(dataType PlcDataType, values []interface{}, pathLen int) ([][]byte, error) {
+	code, size, ok := cipTypeInfo(dataType)
+	if !ok || dataType == Bool || dataType == String {
+		return nil, NewEipError(ErrInvalidDataType, fmt.Sprintf("array slices do not support %s", dataType))
+	}
+	data := make([]byte, 0, len(values)*size)
+	for i, v := range values {
+		b, err := encodeElement(dataType, v)
+		if err != nil {
+			return nil, NewEipErrorWithDetails(ErrInvalidTagValue, fmt.Sprintf("element %d: %v", i, err),
+				map[string]interface{}{"index": i})
+		}
+		data = append(data, b...)
+	}
+
+	// Service, path size, path, type, element count, byte offset
+	overhead := 2 + pathLen + 2 + 2 + 4
+	chunk := (maxUnconnectedMessageSize - overhead) / size * size
+	var requests [][]byte
+	for offset := 0; offset < len(data); offset += chunk {
+		end := offset + chunk
+		if end > len(data) {
+			end = len(data)
+		}
+		req := make([]byte, 8, 8+end-offset)
+		binary.LittleEndian.PutUint16(req, code)
+		binary.LittleEndian.PutUint16(req[2:], uint16(len(values)))
+		binary.LittleEndian.PutUint32(req[4:], uint32(offset))
+		requests = append(requests, append(req, data[offset:end]...))
+	}
+	return requests, nil
+}
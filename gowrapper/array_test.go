@@ -0,0 +1,247 @@
+package ethernetip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDecodeArrayElements tests decoding a run of array elements
+func TestDecodeArrayElements(t *testing.T) {
+	data := []byte{0x01, 0x00, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0x2A, 0x00, 0x00, 0x00}
+	dataType, values, err := decodeArrayElements(CIPTypeDint, data, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataType != Dint || values[0] != int32(1) || values[1] != int32(-1) || values[2] != int32(42) {
+		t.Errorf("Unexpected elements %v %v", dataType, values)
+	}
+
+	if _, _, err := decodeArrayElements(CIPTypeDint, data, 4); err == nil {
+		t.Error("Expected error for truncated reply")
+	}
+	if _, _, err := decodeArrayElements(CIPTypeBool, data, 1); err == nil {
+		t.Error("Expected error for BOOL array")
+	}
+}
+
+// TestWriteFragmentedRequests tests splitting a large slice write into packets
+func TestWriteFragmentedRequests(t *testing.T) {
+	values := make([]interface{}, 300)
+	for i := range values {
+		values[i] = i
+	}
+	requests, err := writeFragmentedRequests(Dint, values, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) < 2 {
+		t.Fatalf("Expected 1200 bytes to be split, got %d request(s)", len(requests))
+	}
+
+	offset := 0
+	for _, req := range requests {
+		if 2+10+len(req) > maxUnconnectedMessageSize {
+			t.Errorf("Request of %d bytes exceeds the packet limit", len(req))
+		}
+		if binary.LittleEndian.Uint16(req) != CIPTypeDint || binary.LittleEndian.Uint16(req[2:]) != 300 {
+			t.Errorf("Unexpected header % X", req[:4])
+		}
+		if got := int(binary.LittleEndian.Uint32(req[4:])); got != offset {
+			t.Errorf("Expected offset %d, got %d", offset, got)
+		}
+		chunk := req[8:]
+		if len(chunk)%4 != 0 {
+			t.Errorf("Chunk of %d bytes splits an element", len(chunk))
+		}
+		if first := int32(binary.LittleEndian.Uint32(chunk)); int(first) != offset/4 {
+			t.Errorf("Chunk at offset %d starts with element %d", offset, first)
+		}
+		offset += len(chunk)
+	}
+	if offset != 1200 {
+		t.Errorf("Expected 1200 bytes in total, got %d", offset)
+	}
+
+	if _, err := writeFragmentedRequests(Sint, []interface{}{200}, 10); err == nil {
+		t.Error("Expected range error for SINT")
+	}
+	if _, err := writeFragmentedRequests(Bool, []interface{}{true}, 10); err == nil {
+		t.Error("Expected error for BOOL array")
+	}
+}
+
+// TestEncodeElement tests encoding values of various Go types
+func TestEncodeElement(t *testing.T) {
+	cases := []struct {
+		dataType PlcDataType
+		value    interface{}
+		want     []byte
+	}{
+		{Bool, true, []byte{1}},
+		{Sint, -2, []byte{0xFE}},
+		{Int, int16(-32768), []byte{0x00, 0x80}},
+		{Dint, float64(42), []byte{0x2A, 0, 0, 0}},
+		{Udint, uint32(0xFFFFFFFF), []byte{0xFF, 0xFF, 0xFF, 0xFF}},
+		{Real, 1.5, []byte{0x00, 0x00, 0xC0, 0x3F}},
+		{Lint, int64(-1), []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+	}
+	for _, c := range cases {
+		got, err := encodeElement(c.dataType, c.value)
+		if err != nil {
+			t.Errorf("%s: %v", c.dataType, err)
+			continue
+		}
+		if string(got) != string(c.want) {
+			t.Errorf("%s: got % X, want % X", c.dataType, got, c.want)
+		}
+	}
+	for _, bad := range []struct {
+		dataType PlcDataType
+		value    interface{}
+	}{{Usint, -1}, {Dint, 1.5}, {Int, "7"}, {Bool, 1}} {
+		if _, err := encodeElement(bad.dataType, bad.value); err == nil {
+			t.Errorf("%s: expected error for %v", bad.dataType, bad.value)
+		}
+	}
+}
+
+// TestReadArraySlice tests slice reads against a real PLC
+func TestReadArraySlice(t *testing.T) {
+	skipIfNoPlc(t)
+	client, err := NewClient(getTestPlcIP())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.ReadArraySlice("TestArray", 0, -1); err == nil {
+		t.Error("Expected error for a negative count")
+	}
+	slice, err := client.ReadArraySlice("TestArray", 0, 4)
+	if err != nil {
+		t.Skipf("TestArray not available: %v", err)
+	}
+	if len(slice.Values) != 4 {
+		t.Errorf("Expected 4 values, got %d", len(slice.Values))
+	}
+}
+
+// TestArrayRuns tests grouping sparse element writes into consecutive runs
+func TestArrayRuns(t *testing.T) {
+	runs := arrayRuns(map[int]interface{}{15: 4, 12: 1, 13: 2, 14: 3, 40: 9, 2: 0})
+	want := []arrayRun{
+		{start: 2, values: []interface{}{0}},
+		{start: 12, values: []interface{}{1, 2, 3, 4}},
+		{start: 40, values: []interface{}{9}},
+	}
+	if !reflect.DeepEqual(runs, want) {
+		t.Errorf("Got %+v, want %+v", runs, want)
+	}
+
+	req, err := writeArrayRunRequest("Recipe", runs[1], Int)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, _ := arrayElementPath("Recipe", 12)
+	header := 2 + len(path)
+	if req[0] != CIPServiceWriteTag || !bytes.Equal(req[2:header], path) {
+		t.Errorf("Unexpected request header % X", req[:header])
+	}
+	if binary.LittleEndian.Uint16(req[header:]) != CIPTypeInt || binary.LittleEndian.Uint16(req[header+2:]) != 4 {
+		t.Errorf("Unexpected type and count % X", req[header:header+4])
+	}
+	if want := []byte{1, 0, 2, 0, 3, 0, 4, 0}; !bytes.Equal(req[header+4:], want) {
+		t.Errorf("Got data % X, want % X", req[header+4:], want)
+	}
+
+	if _, err := writeArrayRunRequest("Recipe", arrayRun{start: 0, values: []interface{}{70000}}, Int); err == nil {
+		t.Error("Expected range error for INT")
+	}
+}
+
+// TestBoolArrayMasks tests combining BOOL element writes into word masks
+func TestBoolArrayMasks(t *testing.T) {
+	masks, err := boolArrayMasks(map[int]interface{}{0: true, 5: false, 31: true, 33: false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []boolWordMask{
+		{index: 0, or: 1 | 1<<31, and: ^uint32(1 << 5)},
+		{index: 1, or: 0, and: ^uint32(1 << 1)},
+	}
+	if !reflect.DeepEqual(masks, want) {
+		t.Errorf("Got %+v, want %+v", masks, want)
+	}
+	if _, err := boolArrayMasks(map[int]interface{}{0: 1}); err == nil {
+		t.Error("Expected error for a non-bool value")
+	}
+}
+
+// TestCheckArrayBounds tests validating indexes and slices against tag metadata
+func TestCheckArrayBounds(t *testing.T) {
+	c := &EipClient{tagCache: map[string]*TagMetadata{
+		"Temps":  {DataType: 0xCA, ArrayDimension: 1, ArraySize: 10},
+		"Grid":   {DataType: 0xC4, ArrayDimension: 2, ArraySize: 12},
+		"Speed":  {DataType: 0xCA},
+		"Alarms": {DataType: 0xC1, ArrayDimension: 1, ArraySize: 64},
+	}}
+
+	outOfRange := func(err error) bool {
+		var eipErr *EipError
+		return errors.As(err, &eipErr) && eipErr.Code == ErrIndexOutOfRange
+	}
+	for _, tc := range []struct {
+		tag          string
+		start, count int
+		ok           bool
+	}{
+		{"Temps", 0, 10, true},
+		{"Temps", 9, 1, true},
+		{"Temps", 8, 3, false},
+		{"Temps", 10, 1, false},
+		{"Grid", 20, 1, true}, // Only one-dimensional arrays are checked
+		{"Speed", 0, 1, false},
+		{"Unknown", 1000, 1, true}, // Left to the controller
+	} {
+		err := c.CheckArrayBounds(tc.tag, tc.start, tc.count)
+		if tc.ok && err != nil {
+			t.Errorf("%s[%d] x%d: unexpected error %v", tc.tag, tc.start, tc.count, err)
+		}
+		if !tc.ok && !outOfRange(err) {
+			t.Errorf("%s[%d] x%d: expected ErrIndexOutOfRange, got %v", tc.tag, tc.start, tc.count, err)
+		}
+	}
+
+	err := c.CheckArrayBounds("Temps", 8, 3)
+	if eipErr := err.(*EipError); eipErr.Details["array_size"] != 10 || !strings.Contains(eipErr.Message, "elements 8 to 10") {
+		t.Errorf("Expected the array's bounds in the error, got %v", err)
+	}
+
+	if _, err := c.ReadArraySlice("Temps", 5, 6); !outOfRange(err) {
+		t.Errorf("Expected ReadArraySlice to be checked, got %v", err)
+	}
+	// A count of 0 reads to the end of the array
+	if _, err := c.ReadArraySlice("Speed", 0, 0); !outOfRange(err) {
+		t.Errorf("Expected ErrIndexOutOfRange for a scalar, got %v", err)
+	}
+	if _, err := c.ReadArraySlice("Temps", 10, 0); !outOfRange(err) {
+		t.Errorf("Expected ErrIndexOutOfRange past the end, got %v", err)
+	}
+	var eipErr *EipError
+	if _, err := c.ReadArraySlice("Grid", 0, 0); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagLength {
+		t.Errorf("Expected ErrInvalidTagLength for a multi-dimensional array, got %v", err)
+	}
+	if _, err := c.ReadArraySlice("Temps", 4, 0); err == nil || outOfRange(err) {
+		t.Errorf("Expected the rest of the array to be requested, got %v", err)
+	}
+	if err := c.WriteArraySlice("Temps", 9, Real, []interface{}{1.0, 2.0}); !outOfRange(err) {
+		t.Errorf("Expected WriteArraySlice to be checked, got %v", err)
+	}
+	if err := c.WriteArrayElements("Alarms", Bool, map[int]interface{}{3: true, 64: true}); !outOfRange(err) {
+		t.Errorf("Expected WriteArrayElements to be checked, got %v", err)
+	}
+}
@@ -0,0 +1,378 @@
+package ethernetip
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AuditRecord records one tag write. The records of a client form a hash
+// chain: Hash is the SHA-256 of the record's JSON encoding without Hash,
+// which includes PrevHash, the Hash of the record before it. Editing,
+// removing or reordering records of a log breaks the chain, which
+// VerifyAuditLog detects.
+type AuditRecord struct {
+	Sequence uint64    `json:"sequence"`
+	Time     time.Time `json:"time"`
+	TagName  string    `json:"tag_name"`
+	DataType string    `json:"data_type"`
+	// OldValue is the value before the write if known: the last good sample
+	// of a subscription to the tag or, with AuditOptions.ReadOldValue, the
+	// value read before writing. It is nil otherwise.
+	OldValue interface{}    `json:"old_value,omitempty"`
+	NewValue interface{}    `json:"new_value"`
+	Caller   CallerIdentity `json:"caller"`
+	Success  bool           `json:"success"`
+	Error    string         `json:"error,omitempty"`
+	PrevHash string         `json:"prev_hash"`
+	Hash     string         `json:"hash"`
+}
+
+// auditEntry is the JSON encoding of an AuditRecord, with its values
+// already encoded so the hash of a record read back from a log is that of
+// the record written
+type auditEntry struct {
+	Sequence uint64          `json:"sequence"`
+	Time     time.Time       `json:"time"`
+	TagName  string          `json:"tag_name"`
+	DataType string          `json:"data_type"`
+	OldValue json.RawMessage `json:"old_value,omitempty"`
+	NewValue json.RawMessage `json:"new_value"`
+	Caller   CallerIdentity  `json:"caller"`
+	Success  bool            `json:"success"`
+	Error    string          `json:"error,omitempty"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"`
+}
+
+// entry returns the JSON encoding of r
+func (r AuditRecord) entry() (auditEntry, error) {
+	e := auditEntry{Sequence: r.Sequence, Time: r.Time, TagName: r.TagName, DataType: r.DataType,
+		Caller: r.Caller, Success: r.Success, Error: r.Error, PrevHash: r.PrevHash, Hash: r.Hash}
+	var err error
+	if r.OldValue != nil {
+		if e.OldValue, err = json.Marshal(jsonValue(r.OldValue)); err != nil {
+			return e, err
+		}
+	}
+	e.NewValue, err = json.Marshal(jsonValue(r.NewValue))
+	return e, err
+}
+
+// MarshalJSON encodes the record as one line of an audit log
+func (r AuditRecord) MarshalJSON() ([]byte, error) {
+	e, err := r.entry()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(e)
+}
+
+// hash returns the hash of the entry, ignoring its Hash
+func (e auditEntry) hash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AuditSink stores audit records. Audit is called for one record at a time,
+// in sequence order, while the writing goroutine waits.
+type AuditSink interface {
+	Audit(record AuditRecord) error
+}
+
+// AuditFunc is an AuditSink calling a function
+type AuditFunc func(record AuditRecord) error
+
+// Audit calls f
+func (f AuditFunc) Audit(record AuditRecord) error {
+	return f(record)
+}
+
+// NewAuditWriter returns an AuditSink writing records to w as JSON lines,
+// the format VerifyAuditLog reads. If w has a Sync method, like *os.File, it
+// is called after every record so records survive a crash.
+func NewAuditWriter(w io.Writer) AuditSink {
+	return &auditWriter{w: w}
+}
+
+type auditWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (a *auditWriter) Audit(record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if s, ok := a.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// NewAuditChannel returns an AuditSink sending records to ch. The send
+// blocks, so a slow receiver delays writes rather than losing records.
+func NewAuditChannel(ch chan<- AuditRecord) AuditSink {
+	return AuditFunc(func(record AuditRecord) error {
+		ch <- record
+		return nil
+	})
+}
+
+// AuditChain identifies the last record of an audit log
+type AuditChain struct {
+	Sequence uint64 `json:"sequence"`
+	Hash     string `json:"hash"`
+}
+
+// AuditOptions configures the audit log of a client
+type AuditOptions struct {
+	// ReadOldValue reads a tag before writing it when no subscription knows
+	// its value, at the cost of one more request per write
+	ReadOldValue bool
+	// Continue chains the first record to the end of an existing log, as
+	// returned by VerifyAuditLog; the zero value starts a new chain
+	Continue AuditChain
+}
+
+// auditor is the audit log of a client. mu keeps the chain in the order
+// records reach the sink.
+type auditor struct {
+	sink     AuditSink
+	opts     AuditOptions
+	mu       sync.Mutex
+	chain    AuditChain
+	failures int64
+}
+
+// SetAuditSink records every tag write of the client, with the value before
+// the write if known, the caller identity of context-aware writes and the
+// result, to sink; nil stops auditing. Writes through WriteValue and the
+// APIs built on it, the typed Write methods, bit, array, raw, UDT, batch and
+// packed writes are all recorded, each attempt of a retried write
+// separately. A record the sink fails to store is logged and counted by
+// AuditFailures, and the chain continues from the last stored record.
+func (c *EipClient) SetAuditSink(sink AuditSink, opts AuditOptions) {
+	if sink == nil {
+		c.audit.Store(nil)
+		return
+	}
+	c.audit.Store(&auditor{sink: sink, opts: opts, chain: opts.Continue})
+}
+
+// AuditChain returns the last record stored by the audit sink
+func (c *EipClient) AuditChain() AuditChain {
+	a := c.audit.Load()
+	if a == nil {
+		return AuditChain{}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.chain
+}
+
+// AuditFailures returns how many records the audit sink failed to store
+func (c *EipClient) AuditFailures() int64 {
+	a := c.audit.Load()
+	if a == nil {
+		return 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.failures
+}
+
+// audited runs write, a write of value to a tag of dataType, if the write
+// policy allows it and records it in the audit log
+func (c *EipClient) audited(caller CallerIdentity, tagName string, dataType PlcDataType, value interface{}, write func() error) error {
+	write = c.permitted(tagName, write)
+	a := c.audit.Load()
+	if a == nil {
+		return write()
+	}
+	old := c.auditOldValue(a, tagName, dataType)
+	err := write()
+	c.recordAudit(a, AuditRecord{TagName: tagName, DataType: dataType.String(), OldValue: old, NewValue: value, Caller: caller}, err)
+	return err
+}
+
+// auditedAs runs write if the write policy allows it and records it in the
+// audit log, for writes whose
+// value is not that of a PlcDataType and whose old value is not known
+func (c *EipClient) auditedAs(tagName, typeName string, value interface{}, write func() error) error {
+	write = c.permitted(tagName, write)
+	a := c.audit.Load()
+	if a == nil {
+		return write()
+	}
+	err := write()
+	c.recordAudit(a, AuditRecord{TagName: tagName, DataType: typeName, NewValue: value}, err)
+	return err
+}
+
+// auditOldValue returns the value of a tag before a write, if known
+func (c *EipClient) auditOldValue(a *auditor, tagName string, dataType PlcDataType) interface{} {
+	if c.poller != nil {
+		if sample, ok := c.poller.Sample(tagName, dataType); ok && sample.Quality == QualityGood {
+			return sample.Value
+		}
+	}
+	if a.opts.ReadOldValue && dataType != Udt {
+		if value, err := c.readValue(tagName, dataType); err == nil {
+			return value.Value
+		}
+	}
+	return nil
+}
+
+// recordAudit completes a record with its sequence, time, result and hash
+// and passes it to the sink
+func (c *EipClient) recordAudit(a *auditor, record AuditRecord, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	record.Sequence = a.chain.Sequence + 1
+	record.Time = c.Clock().Now().UTC()
+	record.Success = err == nil
+	if err != nil {
+		record.Error = err.Error()
+	}
+	record.PrevHash = a.chain.Hash
+
+	e, encErr := record.entry()
+	if encErr == nil {
+		record.Hash, encErr = e.hash()
+	}
+	if encErr != nil {
+		// Keep the write in the log even if its value cannot be encoded
+		record.NewValue, record.OldValue = fmt.Sprint(record.NewValue), nil
+		if e, encErr = record.entry(); encErr == nil {
+			record.Hash, encErr = e.hash()
+		}
+	}
+	if encErr == nil {
+		encErr = a.sink.Audit(record)
+	}
+	if encErr != nil {
+		a.failures++
+		c.logf(slog.LevelError, "❌", "Failed to audit write #%d of tag '%s': %v", record.Sequence, record.TagName, encErr)
+		return
+	}
+	a.chain = AuditChain{Sequence: record.Sequence, Hash: record.Hash}
+}
+
+// VerifyAuditLog checks the hash chain of an audit log written by
+// NewAuditWriter and returns its last record, so a client can continue the
+// log with AuditOptions.Continue. It fails with ErrInvalidValue naming the
+// first record that was edited, removed or moved.
+func VerifyAuditLog(r io.Reader) (AuditChain, error) {
+	dec := json.NewDecoder(r)
+	var chain AuditChain
+	for n := 1; dec.More(); n++ {
+		var e auditEntry
+		if err := dec.Decode(&e); err != nil {
+			return chain, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("audit record %d is not valid JSON: %v", n, err),
+				map[string]interface{}{"record": n})
+		}
+		hash, err := e.hash()
+		if err != nil {
+			return chain, err
+		}
+		switch {
+		case hash != e.Hash:
+			return chain, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("audit record %d (sequence %d) was modified", n, e.Sequence),
+				map[string]interface{}{"record": n, "sequence": e.Sequence})
+		case n > 1 && (e.PrevHash != chain.Hash || e.Sequence != chain.Sequence+1):
+			return chain, NewEipErrorWithDetails(ErrInvalidValue,
+				fmt.Sprintf("audit record %d (sequence %d) does not follow sequence %d", n, e.Sequence, chain.Sequence),
+				map[string]interface{}{"record": n, "sequence": e.Sequence, "previous": chain.Sequence})
+		}
+		chain = AuditChain{Sequence: e.Sequence, Hash: e.Hash}
+	}
+	return chain, nil
+}
+
+// auditOldValues returns the values of tags before packed writes, if known,
+// reading those no subscription knows in one batch with ReadOldValue
+func (c *EipClient) auditOldValues(a *auditor, writes []packedWrite) []interface{} {
+	old := make([]interface{}, len(writes))
+	group := c.NewTagGroup()
+	for i, w := range writes {
+		if c.poller != nil {
+			if sample, ok := c.poller.Sample(w.tagName, w.dataType); ok && sample.Quality == QualityGood {
+				old[i] = sample.Value
+				continue
+			}
+		}
+		if a.opts.ReadOldValue {
+			group.Add(w.tagName, w.dataType)
+		}
+	}
+	if group.Len() == 0 {
+		return old
+	}
+	values, _ := group.ReadAll()
+	for i, w := range writes {
+		if value, ok := values[w.tagName]; ok && old[i] == nil {
+			old[i] = value.Value
+		}
+	}
+	return old
+}
+
+// auditBatchWrite records the tags of a BatchWrite, which succeed or fail
+// together
+func (c *EipClient) auditBatchWrite(tagValues map[string]interface{}, err error) {
+	a := c.audit.Load()
+	if a == nil {
+		return
+	}
+	tagNames := make([]string, 0, len(tagValues))
+	for tagName := range tagValues {
+		tagNames = append(tagNames, tagName)
+	}
+	sort.Strings(tagNames)
+	for _, tagName := range tagNames {
+		c.recordAudit(a, AuditRecord{TagName: tagName, NewValue: tagValues[tagName]}, err)
+	}
+}
+
+// auditBatch records the writes of an ExecuteBatch with their results, or
+// with err if the batch failed as a whole
+func (c *EipClient) auditBatch(operations []BatchOperation, results []BatchOperationResult, err error) {
+	a := c.audit.Load()
+	if a == nil {
+		return
+	}
+	for i, op := range operations {
+		if !op.IsWrite {
+			continue
+		}
+		opErr := err
+		if err == nil {
+			if i >= len(results) || results[i].TagName != op.TagName {
+				opErr = NewEipError(ErrInvalidOperation, "no result for the write")
+			} else if !results[i].Success {
+				opErr = NewEipErrorWithDetails(results[i].ErrorCode, results[i].ErrorMessage,
+					map[string]interface{}{"tag_name": op.TagName})
+			}
+		}
+		c.recordAudit(a, AuditRecord{TagName: op.TagName, DataType: op.DataType.String(), NewValue: op.Value}, opErr)
+	}
+}
@@ -0,0 +1,94 @@
+package ethernetip
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestAuditLog tests that writes are recorded in a verifiable hash chain
+func TestAuditLog(t *testing.T) {
+	var log bytes.Buffer
+	client := &EipClient{}
+	client.SetAuditSink(NewAuditWriter(&log), AuditOptions{})
+
+	// Without a PLC the writes fail, which is recorded too
+	client.WriteDint("Speed", 1200)
+	ctx := WithCallerIdentity(context.Background(), CallerIdentity{User: "operator1", CorrelationID: "req-7"})
+	client.WriteValueContext(ctx, "Setpoint", &PlcValue{Type: Real, Value: math.NaN()})
+	client.SetBits("Flags", 0x05)
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 records, got %d:\n%s", len(lines), log.String())
+	}
+	var records []AuditRecord
+	for _, line := range lines {
+		var record AuditRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode %s: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if r := records[0]; r.Sequence != 1 || r.TagName != "Speed" || r.DataType != "DINT" || r.NewValue != 1200.0 || r.Success || r.Error == "" {
+		t.Errorf("Unexpected first record %+v", r)
+	}
+	if r := records[1]; r.Caller.User != "operator1" || r.Caller.CorrelationID != "req-7" || r.NewValue != "NaN" || r.PrevHash != records[0].Hash {
+		t.Errorf("Unexpected second record %+v", r)
+	}
+	if r := records[2]; r.DataType != "BITS" || r.TagName != "Flags" {
+		t.Errorf("Expected SetBits recorded once as BITS, got %+v", r)
+	}
+
+	chain, err := VerifyAuditLog(strings.NewReader(log.String()))
+	if err != nil || chain != client.AuditChain() || chain.Sequence != 3 {
+		t.Fatalf("Expected the log to verify up to %+v, got %+v (%v)", client.AuditChain(), chain, err)
+	}
+
+	// A client continuing the log extends the same chain
+	next := &EipClient{}
+	next.SetAuditSink(NewAuditWriter(&log), AuditOptions{Continue: chain})
+	next.WriteBool("Start", true)
+	if chain, err := VerifyAuditLog(strings.NewReader(log.String())); err != nil || chain.Sequence != 4 {
+		t.Errorf("Expected the continued log to verify up to 4, got %+v (%v)", chain, err)
+	}
+
+	var eipErr *EipError
+	edited := strings.Replace(log.String(), `"new_value":1200`, `"new_value":1300`, 1)
+	if _, err := VerifyAuditLog(strings.NewReader(edited)); !errors.As(err, &eipErr) || eipErr.Details["record"] != 1 {
+		t.Errorf("Expected the edited record 1 to be detected, got %v", err)
+	}
+	removed := strings.Join(append(lines[:1:1], lines[2:]...), "\n")
+	if _, err := VerifyAuditLog(strings.NewReader(removed)); !errors.As(err, &eipErr) || eipErr.Details["record"] != 2 {
+		t.Errorf("Expected the removed record to be detected at record 2, got %v", err)
+	}
+}
+
+// TestAuditSinkFailure tests that records the sink rejects are counted and
+// do not advance the chain
+func TestAuditSinkFailure(t *testing.T) {
+	client := &EipClient{}
+	records := make(chan AuditRecord, 1)
+	client.SetAuditSink(NewAuditChannel(records), AuditOptions{})
+	client.WriteInt("Count", 3)
+	record := <-records
+	if record.Sequence != 1 || record.NewValue != int16(3) {
+		t.Errorf("Expected the Go value in the channel, got %+v", record)
+	}
+
+	client.SetAuditSink(AuditFunc(func(AuditRecord) error { return errors.New("disk full") }), AuditOptions{Continue: client.AuditChain()})
+	client.WriteInt("Count", 4)
+	if client.AuditFailures() != 1 || client.AuditChain().Sequence != 1 {
+		t.Errorf("Expected 1 failure and the chain at 1, got %d and %+v", client.AuditFailures(), client.AuditChain())
+	}
+
+	client.SetAuditSink(nil, AuditOptions{})
+	client.WriteInt("Count", 5)
+	if client.AuditChain() != (AuditChain{}) {
+		t.Error("Expected no audit log after removing the sink")
+	}
+}
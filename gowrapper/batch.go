@@ -0,0 +1,297 @@
+package ethernetip
+
+/*
+#include <stdlib.h>
+
+// Batch operations
+extern int eip_read_tags_batch(int client_id, char** tag_names, int tag_count, char* results, int results_capacity);
+extern int eip_write_tags_batch(int client_id, const char* tag_values, int tag_count, char* results, int results_capacity);
+extern int eip_execute_batch(int client_id, const char* operations, int operation_count, char* results, int results_capacity);
+extern int eip_configure_batch_operations(int client_id, void* config);
+extern int eip_get_batch_config(int client_id, void* config);
+*/
+import "C"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/batch"
+)
+
+// BatchConfig represents configuration for batch operations
+type BatchConfig = batch.Config
+
+// DefaultBatchConfig returns a default batch configuration
+func DefaultBatchConfig() *BatchConfig {
+	return batch.DefaultConfig()
+}
+
+// HighPerformanceBatchConfig returns a batch configuration optimized for performance
+func HighPerformanceBatchConfig() *BatchConfig {
+	return batch.HighPerformanceConfig()
+}
+
+// ConservativeBatchConfig returns a batch configuration optimized for reliability
+func ConservativeBatchConfig() *BatchConfig {
+	return batch.ConservativeConfig()
+}
+
+// ConfigureBatchOperations configures batch operations
+func (c *EipClient) ConfigureBatchOperations(config *BatchConfig) error {
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch config: %v", err)
+	}
+
+	cConfig := C.CString(string(jsonData))
+	defer C.free(unsafe.Pointer(cConfig))
+
+	retCode := int(C.eip_configure_batch_operations(C.int(c.id()), unsafe.Pointer(cConfig)))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: "Failed to configure batch operations",
+		}
+	}
+
+	return nil
+}
+
+// GetBatchConfig gets the current batch configuration
+func (c *EipClient) GetBatchConfig() (*BatchConfig, error) {
+	const maxConfigSize = 1024
+	cConfig := C.malloc(C.size_t(maxConfigSize))
+	defer C.free(cConfig)
+
+	retCode := int(C.eip_get_batch_config(C.int(c.id()), cConfig))
+	if retCode != 0 {
+		return nil, &EipError{
+			Code:    retCode,
+			Message: "Failed to get batch configuration",
+		}
+	}
+
+	var config BatchConfig
+	err := json.Unmarshal([]byte(C.GoString((*C.char)(cConfig))), &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse batch config: %v", err)
+	}
+
+	return &config, nil
+}
+
+// BatchRead reads multiple tags in a single operation. The results are
+// keyed by the names requested, aliases included.
+func (c *EipClient) BatchRead(tagNames []string) (map[string]interface{}, error) {
+	var results map[string]interface{}
+	err := c.submit(OperationRead, fmt.Sprintf("%d tags", len(tagNames)), func() (err error) {
+		results, err = c.batchRead(tagNames)
+		return err
+	})
+	return results, err
+}
+
+// batchRead is BatchRead without queuing
+func (c *EipClient) batchRead(tagNames []string) (map[string]interface{}, error) {
+	if len(tagNames) == 0 {
+		return nil, errors.New("no tags specified for batch read")
+	}
+	tagNames, requested := c.resolveAliases(tagNames)
+
+	// Convert tag names to C strings
+	cTagNames := make([]*C.char, len(tagNames))
+	for i, name := range tagNames {
+		cTagNames[i] = C.CString(name)
+		defer C.free(unsafe.Pointer(cTagNames[i]))
+	}
+
+	// Allocate memory for results
+	const maxResultsSize = 4096
+	cResults := C.malloc(C.size_t(maxResultsSize))
+	defer C.free(cResults)
+
+	// Call the batch read function
+	retCode := int(C.eip_read_tags_batch(
+		C.int(c.id()),
+		(**C.char)(unsafe.Pointer(&cTagNames[0])),
+		C.int(len(tagNames)),
+		(*C.char)(cResults),
+		C.int(maxResultsSize),
+	))
+
+	if retCode != 0 {
+		return nil, &EipError{
+			Code:    retCode,
+			Message: "Failed to execute batch read",
+		}
+	}
+
+	// Parse the JSON results
+	var results map[string]interface{}
+	err := json.Unmarshal([]byte(C.GoString((*C.char)(cResults))), &results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse batch read results: %v", err)
+	}
+
+	return batch.ByRequestedName(results, requested), nil
+}
+
+// BatchWrite writes multiple tags in a single operation. If the write
+// policy rejects any of the tags, none are written.
+func (c *EipClient) BatchWrite(tagValues map[string]interface{}) error {
+	return c.submit(OperationWrite, fmt.Sprintf("%d tags", len(tagValues)), func() error {
+		return c.batchWrite(tagValues)
+	})
+}
+
+// batchWrite is BatchWrite without queuing
+func (c *EipClient) batchWrite(tagValues map[string]interface{}) error {
+	if len(tagValues) == 0 {
+		return errors.New("no tags specified for batch write")
+	}
+	tagValues, err := batch.ResolveWrites(tagValues, c.ResolveAlias)
+	if err != nil {
+		return err
+	}
+	for tagName := range tagValues {
+		if err := c.CheckWrite(tagName); err != nil {
+			c.auditBatchWrite(tagValues, err)
+			return err
+		}
+	}
+
+	// Convert tag values to JSON
+	jsonData, err := json.Marshal(tagValues)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tag values: %v", err)
+	}
+
+	cTagValues := C.CString(string(jsonData))
+	defer C.free(unsafe.Pointer(cTagValues))
+
+	// Allocate memory for results
+	const maxResultsSize = 1024
+	cResults := C.malloc(C.size_t(maxResultsSize))
+	defer C.free(cResults)
+
+	// Call the batch write function
+	retCode := int(C.eip_write_tags_batch(
+		C.int(c.id()),
+		cTagValues,
+		C.int(len(tagValues)),
+		(*C.char)(cResults),
+		C.int(maxResultsSize),
+	))
+
+	if retCode != 0 {
+		err = &EipError{
+			Code:    retCode,
+			Message: "Failed to execute batch write",
+		}
+	}
+	c.auditBatchWrite(tagValues, err)
+	return err
+}
+
+// ExecuteBatch executes a batch of operations (mix of reads and writes). If
+// the write policy rejects any of the writes, nothing is executed.
+func (c *EipClient) ExecuteBatch(operations []BatchOperation) ([]BatchOperationResult, error) {
+	// A batch with writes is queued as a write, so it is never shed
+	kind := OperationRead
+	for _, op := range operations {
+		if op.IsWrite {
+			kind = OperationWrite
+		}
+	}
+	var results []BatchOperationResult
+	err := c.submit(kind, fmt.Sprintf("%d operations", len(operations)), func() (err error) {
+		results, err = c.executeBatch(operations)
+		return err
+	})
+	return results, err
+}
+
+// executeBatch is ExecuteBatch without queuing. Operations on an alias are
+// sent for its tag, and their results reported under the alias.
+func (c *EipClient) executeBatch(operations []BatchOperation) ([]BatchOperationResult, error) {
+	if len(operations) == 0 {
+		return nil, errors.New("no operations specified for batch execution")
+	}
+	requested := operations
+	operations = batch.ResolveOperations(requested, c.ResolveAlias)
+	for _, op := range operations {
+		if !op.IsWrite {
+			continue
+		}
+		if err := c.CheckWrite(op.TagName); err != nil {
+			c.auditBatch(operations, nil, err)
+			return nil, err
+		}
+	}
+
+	// Convert operations to JSON
+	jsonData, err := json.Marshal(operations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch operations: %v", err)
+	}
+
+	cOperations := C.CString(string(jsonData))
+	defer C.free(unsafe.Pointer(cOperations))
+
+	// Allocate memory for results
+	const maxResultsSize = 4096
+	cResults := C.malloc(C.size_t(maxResultsSize))
+	defer C.free(cResults)
+
+	// Call the batch execute function
+	retCode := int(C.eip_execute_batch(
+		C.int(c.id()),
+		cOperations,
+		C.int(len(operations)),
+		(*C.char)(cResults),
+		C.int(maxResultsSize),
+	))
+
+	if retCode != 0 {
+		err := &EipError{
+			Code:    retCode,
+			Message: "Failed to execute batch operations",
+		}
+		c.auditBatch(operations, nil, err)
+		return nil, err
+	}
+
+	// Parse the JSON results
+	var results []BatchOperationResult
+	err = json.Unmarshal([]byte(C.GoString((*C.char)(cResults))), &results)
+	if err != nil {
+		err = fmt.Errorf("failed to parse batch execution results: %v", err)
+		c.auditBatch(operations, nil, err)
+		return nil, err
+	}
+
+	c.auditBatch(operations, results, nil)
+	batch.RestoreNames(results, requested, operations)
+	return results, nil
+}
+
+// ReadMultipleTags reads multiple tags in parallel, submitted to the queue
+// as one queued operation. The results are keyed by the names requested,
+// aliases included.
+func (c *EipClient) ReadMultipleTags(tags map[string]PlcDataType) (map[string]*PlcValue, error) {
+	var results map[string]*PlcValue
+	err := c.submit(OperationRead, fmt.Sprintf("%d tags", len(tags)), func() (err error) {
+		results, err = c.readMultipleTags(tags)
+		return err
+	})
+	return results, err
+}
+
+// readMultipleTags reads multiple tags in parallel without queuing them
+func (c *EipClient) readMultipleTags(tags map[string]PlcDataType) (map[string]*PlcValue, error) {
+	return batch.ReadParallel(tags, func(name string, dataType PlcDataType) (*PlcValue, error) {
+		return c.readValue(c.ResolveAlias(name), dataType)
+	})
+}
@@ -0,0 +1,200 @@
+// Package batch holds the parts of batch reads and writes that do not talk
+// to a controller: the batch configuration and its presets, the mapping
+// between the names a batch was requested under and the tags sent for them,
+// and the parallel read behind ReadMultipleTags. It has no dependency on the
+// native library. The ethernetip package sends batches through it and
+// re-exports Config as BatchConfig, so existing code is unaffected.
+package batch
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// Config represents configuration for batch operations
+type Config struct {
+	MaxOperationsPerPacket int           `json:"max_operations_per_packet"`
+	MaxPacketSize          int           `json:"max_packet_size"`
+	PacketTimeoutMs        int64         `json:"packet_timeout_ms"`
+	ContinueOnError        bool          `json:"continue_on_error"`
+	OptimizePacketPacking  bool          `json:"optimize_packet_packing"`
+	RetryCount             int           `json:"retry_count"`
+	RetryDelay             time.Duration `json:"retry_delay"`
+	MaxConcurrentOps       int           `json:"max_concurrent_ops"`
+	OperationTimeout       time.Duration `json:"operation_timeout"`
+}
+
+// DefaultConfig returns a default batch configuration
+func DefaultConfig() *Config {
+	return &Config{
+		MaxOperationsPerPacket: 20,
+		MaxPacketSize:          504,
+		PacketTimeoutMs:        3000,
+		ContinueOnError:        true,
+		OptimizePacketPacking:  true,
+		RetryCount:             3,
+		RetryDelay:             time.Second,
+		MaxConcurrentOps:       10,
+		OperationTimeout:       5 * time.Second,
+	}
+}
+
+// HighPerformanceConfig returns a batch configuration optimized for performance
+func HighPerformanceConfig() *Config {
+	return &Config{
+		MaxOperationsPerPacket: 50,
+		MaxPacketSize:          1000,
+		PacketTimeoutMs:        1000,
+		ContinueOnError:        true,
+		OptimizePacketPacking:  true,
+		RetryCount:             2,
+		RetryDelay:             500 * time.Millisecond,
+		MaxConcurrentOps:       20,
+		OperationTimeout:       2 * time.Second,
+	}
+}
+
+// ConservativeConfig returns a batch configuration optimized for reliability
+func ConservativeConfig() *Config {
+	return &Config{
+		MaxOperationsPerPacket: 10,
+		MaxPacketSize:          252,
+		PacketTimeoutMs:        5000,
+		ContinueOnError:        false,
+		OptimizePacketPacking:  false,
+		RetryCount:             5,
+		RetryDelay:             2 * time.Second,
+		MaxConcurrentOps:       5,
+		OperationTimeout:       10 * time.Second,
+	}
+}
+
+// ResolveNames resolves names with resolve, which maps an alias to its tag
+// and any other name to itself. It returns the tags, without duplicates,
+// and the names each tag was requested under, or nil requested if no name
+// was an alias.
+func ResolveNames(names []string, resolve func(name string) string) (tags []string, requested map[string][]string) {
+	tags = make([]string, 0, len(names))
+	requested = make(map[string][]string, len(names))
+	aliased := false
+	for _, name := range names {
+		tag := resolve(name)
+		aliased = aliased || tag != name
+		if _, ok := requested[tag]; !ok {
+			tags = append(tags, tag)
+		}
+		requested[tag] = append(requested[tag], name)
+	}
+	if !aliased {
+		return tags, nil
+	}
+	return tags, requested
+}
+
+// ByRequestedName re-keys m, keyed by tag, by the names the tags were
+// requested under (see ResolveNames). m is returned as it is when
+// requested is nil.
+func ByRequestedName[V any](m map[string]V, requested map[string][]string) map[string]V {
+	if requested == nil {
+		return m
+	}
+	byName := make(map[string]V, len(m))
+	for tag, v := range m {
+		names, ok := requested[tag]
+		if !ok {
+			byName[tag] = v
+			continue
+		}
+		for _, name := range names {
+			byName[name] = v
+		}
+	}
+	return byName
+}
+
+// ResolveWrites re-keys values by the tags their names stand for. Two names
+// of the same tag fail with ErrInvalidTagName, since only one of the values
+// could be written.
+func ResolveWrites(values map[string]interface{}, resolve func(name string) string) (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(values))
+	names := make(map[string]string, len(values))
+	for name, value := range values {
+		tag := resolve(name)
+		if other, dup := names[tag]; dup {
+			return nil, types.NewEipErrorWithDetails(types.ErrInvalidTagName,
+				fmt.Sprintf("'%s' and '%s' both write '%s'", other, name, tag),
+				map[string]interface{}{"tag_name": tag})
+		}
+		names[tag] = name
+		resolved[tag] = value
+	}
+	return resolved, nil
+}
+
+// ResolveOperations returns a copy of operations with each tag name
+// resolved. After execution, RestoreNames reports the results under the
+// names of operations.
+func ResolveOperations(operations []types.BatchOperation, resolve func(name string) string) []types.BatchOperation {
+	resolved := make([]types.BatchOperation, len(operations))
+	for i, op := range operations {
+		op.TagName = resolve(op.TagName)
+		resolved[i] = op
+	}
+	return resolved
+}
+
+// RestoreNames renames the results of the resolved operations sent back to
+// the names of the operations requested
+func RestoreNames(results []types.BatchOperationResult, requested, sent []types.BatchOperation) {
+	for i := range results {
+		if i < len(requested) && i < len(sent) && results[i].TagName == sent[i].TagName {
+			results[i].TagName = requested[i].TagName
+		}
+	}
+}
+
+// ReadFunc reads one tag
+type ReadFunc func(tagName string, dataType types.PlcDataType) (*types.PlcValue, error)
+
+// ReadParallel reads every tag of tags with read, each in its own
+// goroutine. It returns the values keyed by tag name, or one of the errors
+// if any read failed.
+func ReadParallel(tags map[string]types.PlcDataType, read ReadFunc) (map[string]*types.PlcValue, error) {
+	type result struct {
+		tagName string
+		value   *types.PlcValue
+		err     error
+	}
+
+	resultChan := make(chan result, len(tags))
+
+	for tagName, dataType := range tags {
+		go func(name string, dt types.PlcDataType) {
+			value, err := read(name, dt)
+			resultChan <- result{
+				tagName: name,
+				value:   value,
+				err:     err,
+			}
+		}(tagName, dataType)
+	}
+
+	results := make(map[string]*types.PlcValue)
+	var lastErr error
+
+	for i := 0; i < len(tags); i++ {
+		r := <-resultChan
+		if r.err != nil {
+			lastErr = r.err
+		} else {
+			results[r.tagName] = r.value
+		}
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return results, nil
+}
@@ -0,0 +1,94 @@
+package batch
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// aliases resolves "speed" and "rpm" to "Motor.Speed"
+func aliases(name string) string {
+	if name == "speed" || name == "rpm" {
+		return "Motor.Speed"
+	}
+	return name
+}
+
+// TestConfigPresets tests that the presets trade speed for reliability
+func TestConfigPresets(t *testing.T) {
+	fast, normal, safe := HighPerformanceConfig(), DefaultConfig(), ConservativeConfig()
+	if !(fast.MaxOperationsPerPacket > normal.MaxOperationsPerPacket && normal.MaxOperationsPerPacket > safe.MaxOperationsPerPacket) {
+		t.Errorf("Expected decreasing operations per packet, got %d, %d, %d",
+			fast.MaxOperationsPerPacket, normal.MaxOperationsPerPacket, safe.MaxOperationsPerPacket)
+	}
+	if safe.ContinueOnError {
+		t.Error("Expected the conservative preset to stop on error")
+	}
+}
+
+// TestResolveNames tests resolving and re-keying the names of a batch
+func TestResolveNames(t *testing.T) {
+	tags, requested := ResolveNames([]string{"speed", "Level", "rpm"}, aliases)
+	if !reflect.DeepEqual(tags, []string{"Motor.Speed", "Level"}) {
+		t.Errorf("Expected [Motor.Speed Level], got %v", tags)
+	}
+	values := ByRequestedName(map[string]int{"Motor.Speed": 1, "Level": 2}, requested)
+	if !reflect.DeepEqual(values, map[string]int{"speed": 1, "rpm": 1, "Level": 2}) {
+		t.Errorf("Expected values under the names requested, got %v", values)
+	}
+
+	if _, requested := ResolveNames([]string{"Level"}, aliases); requested != nil {
+		t.Errorf("Expected nil requested without aliases, got %v", requested)
+	}
+}
+
+// TestResolveWrites tests that two names of one tag cannot both be written
+func TestResolveWrites(t *testing.T) {
+	values, err := ResolveWrites(map[string]interface{}{"speed": 5, "Level": 2}, aliases)
+	if err != nil || values["Motor.Speed"] != 5 || values["Level"] != 2 {
+		t.Errorf("Expected values keyed by tag, got %v, %v", values, err)
+	}
+
+	_, err = ResolveWrites(map[string]interface{}{"speed": 5, "rpm": 6}, aliases)
+	var eipErr *types.EipError
+	if !errors.As(err, &eipErr) || eipErr.Code != types.ErrInvalidTagName {
+		t.Errorf("Expected ErrInvalidTagName, got %v", err)
+	}
+}
+
+// TestResolveOperations tests that results are reported under the names requested
+func TestResolveOperations(t *testing.T) {
+	requested := []types.BatchOperation{{TagName: "speed"}, {TagName: "Level", IsWrite: true}}
+	sent := ResolveOperations(requested, aliases)
+	if sent[0].TagName != "Motor.Speed" || requested[0].TagName != "speed" {
+		t.Errorf("Expected a resolved copy, got %+v from %+v", sent, requested)
+	}
+
+	results := []types.BatchOperationResult{{TagName: "Motor.Speed"}, {TagName: "Level"}}
+	RestoreNames(results, requested, sent)
+	if results[0].TagName != "speed" || results[1].TagName != "Level" {
+		t.Errorf("Expected results under speed and Level, got %+v", results)
+	}
+}
+
+// TestReadParallel tests reading every tag and reporting a failure
+func TestReadParallel(t *testing.T) {
+	read := func(tagName string, dataType types.PlcDataType) (*types.PlcValue, error) {
+		if strings.HasPrefix(tagName, "Bad") {
+			return nil, errors.New("not found")
+		}
+		return &types.PlcValue{Type: dataType, Value: tagName}, nil
+	}
+
+	values, err := ReadParallel(map[string]types.PlcDataType{"A": types.Dint, "B": types.Real}, read)
+	if err != nil || len(values) != 2 || values["B"].Type != types.Real || values["A"].Value != "A" {
+		t.Errorf("Expected A and B, got %v, %v", values, err)
+	}
+
+	if _, err := ReadParallel(map[string]types.PlcDataType{"A": types.Dint, "Bad": types.Dint}, read); err == nil {
+		t.Error("Expected the failed read to be reported")
+	}
+}
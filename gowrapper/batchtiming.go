@@ -0,0 +1,84 @@
+package ethernetip
+
+/*
+#include <stdlib.h>
+
+// Batch timing
+extern int eip_get_last_batch_timing(int client_id, void* timing);
+*/
+import "C"
+import (
+	"time"
+	"unsafe"
+)
+
+// BatchTiming is the packet-level timing of a batch execution, summed over
+// every Multiple Service Packet the batch was split into. It complements the
+// per-operation BatchOperationResult.ExecutionTimeUs when tuning batch sizes.
+type BatchTiming struct {
+	Packets       int   `json:"packets"`        // Multiple Service Packets sent
+	Operations    int   `json:"operations"`     // Operations in the batch
+	RequestBytes  int64 `json:"request_bytes"`  // CIP request bytes sent
+	ResponseBytes int64 `json:"response_bytes"` // Reply bytes received
+
+	Serialization time.Duration `json:"serialization"` // Building requests
+	RoundTrip     time.Duration `json:"round_trip"`    // Request sent until reply read
+	// ControllerEstimate is the part of RoundTrip attributed to the controller.
+	// The network share of each round trip is taken to be the fastest round
+	// trip seen on the session, so the estimate improves as the session ages.
+	ControllerEstimate time.Duration `json:"controller_estimate"`
+	Decode             time.Duration `json:"decode"` // Parsing replies
+	Total              time.Duration `json:"total"`  // Wall-clock time of the batch
+}
+
+// OperationsPerSecond returns the batch throughput, or 0 if no time was recorded
+func (t *BatchTiming) OperationsPerSecond() float64 {
+	if t.Total <= 0 {
+		return 0
+	}
+	return float64(t.Operations) / t.Total.Seconds()
+}
+
+// cBatchTiming mirrors the native CBatchTiming layout
+type cBatchTiming struct {
+	packets              uint32
+	operations           uint32
+	requestBytes         uint64
+	responseBytes        uint64
+	serializationUs      uint64
+	roundTripUs          uint64
+	controllerEstimateUs uint64
+	decodeUs             uint64
+	totalUs              uint64
+}
+
+// LastBatchTiming returns the packet-level timing of the most recent batch
+// read or batch execution on this client
+func (c *EipClient) LastBatchTiming() (*BatchTiming, error) {
+	var raw cBatchTiming
+	retCode := int(C.eip_get_last_batch_timing(C.int(c.id()), unsafe.Pointer(&raw)))
+	if retCode != 0 {
+		return nil, NewEipErrorWithDetails(ErrInvalidOperation, "Failed to get batch timing",
+			map[string]interface{}{
+				"error_code": retCode,
+				"client_id":  c.id(),
+			})
+	}
+	return raw.toBatchTiming(), nil
+}
+
+// toBatchTiming converts the native microsecond counters
+func (raw *cBatchTiming) toBatchTiming() *BatchTiming {
+	us := func(v uint64) time.Duration { return time.Duration(v) * time.Microsecond }
+	return &BatchTiming{
+		Packets:            int(raw.packets),
+		Operations:         int(raw.operations),
+		RequestBytes:       int64(raw.requestBytes),
+		ResponseBytes:      int64(raw.responseBytes),
+		Serialization:      us(raw.serializationUs),
+		RoundTrip:          us(raw.roundTripUs),
+		ControllerEstimate: us(raw.controllerEstimateUs),
+		Decode:             us(raw.decodeUs),
+		Total:              us(raw.totalUs),
+	}
+}
@@ -0,0 +1,39 @@
+package ethernetip
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// TestBatchTimingLayout tests that cBatchTiming matches the native struct size
+func TestBatchTimingLayout(t *testing.T) {
+	if size := unsafe.Sizeof(cBatchTiming{}); size != 64 {
+		t.Errorf("expected 64-byte layout, got %d", size)
+	}
+}
+
+// TestBatchTimingConversion tests conversion of native counters
+func TestBatchTimingConversion(t *testing.T) {
+	raw := cBatchTiming{
+		packets:              2,
+		operations:           40,
+		requestBytes:         800,
+		responseBytes:        600,
+		serializationUs:      50,
+		roundTripUs:          4000,
+		controllerEstimateUs: 1500,
+		decodeUs:             30,
+		totalUs:              5000,
+	}
+	timing := raw.toBatchTiming()
+	if timing.RoundTrip != 4*time.Millisecond || timing.ControllerEstimate != 1500*time.Microsecond {
+		t.Errorf("unexpected durations: %+v", timing)
+	}
+	if ops := timing.OperationsPerSecond(); ops != 8000 {
+		t.Errorf("expected 8000 ops/s, got %v", ops)
+	}
+	if ops := (&BatchTiming{Operations: 5}).OperationsPerSecond(); ops != 0 {
+		t.Errorf("expected 0 ops/s without timing, got %v", ops)
+	}
+}
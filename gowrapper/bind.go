@@ -0,0 +1,539 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// fieldBinding is a struct field bound to a tag with an `eip` struct tag
+type fieldBinding struct {
+	tagName  string
+	dataType PlcDataType
+	index    []int
+}
+
+// batched reports whether the field can be read and written in a Multiple
+// Service Packet by c (see packable)
+func (b fieldBinding) batched(c *EipClient) bool {
+	_, ok := c.packable(b.tagName, b.dataType)
+	return ok
+}
+
+// batchable reports whether a tag can be read with a ReadPlan and written in
+// a Multiple Service Packet. Strings may use custom types and bit members
+// need a read-modify-write, so they go through ReadValue and WriteValue.
+func batchable(tagName string, dataType PlcDataType) bool {
+	if dataType == String {
+		return false
+	}
+	_, _, bit := splitBitMember(tagName)
+	return !bit
+}
+
+// packable resolves tagName as an alias and reports whether the tag is
+// batchable. Virtual tags are computed by the client, not read from the PLC,
+// so they are never packed and go through readValue.
+func (c *EipClient) packable(tagName string, dataType PlcDataType) (string, bool) {
+	tagName = c.ResolveAlias(tagName)
+	if _, virtual := c.virtualTag(tagName); virtual {
+		return tagName, false
+	}
+	return tagName, batchable(tagName, dataType)
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// ReadInto reads the tags bound to the fields of the struct v points to and
+// stores their values. Fields are bound with an `eip` struct tag naming the
+// tag and, optionally, its data type:
+//
+//	type Line struct {
+//		Speed   float32   `eip:"Line1.Speed"`
+//		Running bool      `eip:"Line1.Run"`
+//		Started time.Time `eip:"Line1.Started,LDT"`
+//	}
+//
+// Without a type, it follows from the field's Go type: float32 is REAL,
+// int32 DINT, time.Time DT, time.Duration TIME and so on. Values are
+// converted to the field type, failing if they do not fit. The reads are
+// packed into as few requests as possible with a ReadPlan; fields whose tags
+// fail are left unchanged and reported together in an ErrBatchOperationFailed
+// error.
+func (c *EipClient) ReadInto(v interface{}) error {
+	target, bindings, err := bindStruct(v)
+	if err != nil {
+		return err
+	}
+
+	var items, single []ReadItem
+	for _, b := range bindings {
+		item := ReadItem{TagName: b.tagName, DataType: b.dataType}
+		if b.batched(c) {
+			items = append(items, item)
+		} else {
+			single = append(single, item)
+		}
+	}
+	var plan *ReadPlan
+	if len(items) > 0 {
+		if plan, err = c.CompileReadPlan(items); err != nil {
+			return err
+		}
+	}
+	var values map[string]*PlcValue
+	var failed []string
+	if err := c.submit(OperationRead, fmt.Sprintf("%d tags", len(bindings)), func() (err error) {
+		values, failed, err = c.readItems(plan, single)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	for _, b := range bindings {
+		value, ok := values[b.tagName]
+		if !ok {
+			continue
+		}
+		if err := assignField(target.FieldByIndex(b.index), value.Value); err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", b.tagName, err))
+		}
+	}
+	return bindingError("struct read", failed)
+}
+
+// readItems executes plan, if any, and reads the single items one by one,
+// without queuing. Items that fail are described in the returned list.
+func (c *EipClient) readItems(plan *ReadPlan, single []ReadItem) (map[string]*PlcValue, []string, error) {
+	values := make(map[string]*PlcValue, len(single))
+	var failed []string
+	for _, item := range single {
+		value, err := c.readValue(c.ResolveAlias(item.TagName), item.DataType)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", item.TagName, err))
+			continue
+		}
+		values[item.TagName] = value
+	}
+	if plan == nil {
+		return values, failed, nil
+	}
+	read, err := plan.readAll()
+	for name, value := range read {
+		values[name] = value
+	}
+	var batchErr *EipError
+	if errors.As(err, &batchErr) && batchErr.Code == ErrBatchOperationFailed {
+		items, _ := batchErr.Details["failed_items"].([]string)
+		failed = append(failed, items...)
+	} else if err != nil {
+		return nil, nil, err
+	}
+	return values, failed, nil
+}
+
+// WriteFrom writes the fields of the struct v points to to their bound tags;
+// see ReadInto for the `eip` struct tag. The writes are packed into Multiple
+// Service Packets. They are not atomic: writes that fail are reported
+// together in an ErrBatchOperationFailed error while the others take effect.
+func (c *EipClient) WriteFrom(v interface{}) error {
+	source, bindings, err := bindStruct(v)
+	if err != nil {
+		return err
+	}
+
+	var writes []packedWrite
+	var failed []string
+	for _, b := range bindings {
+		value := source.FieldByIndex(b.index).Interface()
+		if !b.batched(c) {
+			if err := c.WriteValue(b.tagName, &PlcValue{Type: b.dataType, Value: value}); err != nil {
+				failed = append(failed, fmt.Sprintf("%s (%v)", b.tagName, err))
+			}
+			continue
+		}
+		// WriteValue resolves aliases itself; packed writes are resolved here
+		tagName := c.ResolveAlias(b.tagName)
+		req, err := writeTagRequest(tagName, b.dataType, value)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", b.tagName, err))
+			continue
+		}
+		writes = append(writes, packedWrite{tagName: tagName, dataType: b.dataType, value: value, request: req})
+	}
+	failed = append(failed, failedWrites(writes, c.writePacked(writes))...)
+	return bindingError("struct write", failed)
+}
+
+// packedWrite is a Write Tag request of a tag write packed into a Multiple
+// Service Packet
+type packedWrite struct {
+	tagName  string
+	dataType PlcDataType
+	value    interface{}
+	request  []byte
+}
+
+// writePacked sends tag writes packed into Multiple Service Packets,
+// recording them in the audit log, and returns the error of each. Writes the
+// write policy rejects are not sent.
+func (c *EipClient) writePacked(writes []packedWrite) []error {
+	a := c.audit.Load()
+	var old []interface{}
+	if a != nil {
+		old = c.auditOldValues(a, writes)
+	}
+	errs := make([]error, len(writes))
+	var sent []int
+	var tagNames []string
+	var requests [][]byte
+	for i, w := range writes {
+		if errs[i] = c.CheckWrite(w.tagName); errs[i] == nil {
+			sent = append(sent, i)
+			tagNames, requests = append(tagNames, w.tagName), append(requests, w.request)
+		}
+	}
+	if len(sent) > 0 {
+		var sentErrs []error
+		err := c.submit(OperationWrite, fmt.Sprintf("%d tags", len(sent)), func() error {
+			sentErrs = c.writeRequests(tagNames, requests)
+			return nil
+		})
+		for j, i := range sent {
+			if err != nil {
+				errs[i] = err
+			} else {
+				errs[i] = sentErrs[j]
+			}
+		}
+	}
+	if a != nil {
+		for i, w := range writes {
+			c.recordAudit(a, AuditRecord{TagName: w.tagName, DataType: w.dataType.String(), OldValue: old[i], NewValue: w.value}, errs[i])
+		}
+	}
+	return errs
+}
+
+// failedWrites describes the packed writes that failed
+func failedWrites(writes []packedWrite, errs []error) []string {
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", writes[i].tagName, err))
+		}
+	}
+	return failed
+}
+
+// sendWriteRequests sends Write Tag requests packed into Multiple Service
+// Packets and describes the ones that failed
+func (c *EipClient) sendWriteRequests(tagNames []string, requests [][]byte) []string {
+	var failed []string
+	for i, err := range c.writeRequests(tagNames, requests) {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", tagNames[i], err))
+		}
+	}
+	return failed
+}
+
+// writeRequests sends Write Tag requests packed into Multiple Service
+// Packets and returns the error of each request, nil for those that succeeded
+func (c *EipClient) writeRequests(tagNames []string, requests [][]byte) []error {
+	errs := make([]error, 0, len(requests))
+	for _, packet := range packRequests(requests) {
+		errs = append(errs, c.sendWritePacket(tagNames[:len(packet)], packet)...)
+		tagNames = tagNames[len(packet):]
+	}
+	return errs
+}
+
+// sendWritePacket sends Write Tag requests in one Multiple Service Packet and
+// returns the error of each
+func (c *EipClient) sendWritePacket(tagNames []string, requests [][]byte) []error {
+	describe := func(err error) []error {
+		errs := make([]error, len(tagNames))
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	resp, err := c.SendCIPMessage(CIPServiceMultipleServicePacket,
+		classInstancePath(CIPClassMessageRouter, 1), buildMultipleServicePacket(requests))
+	if err != nil && (resp == nil || resp.GeneralStatus != CIPStatusEmbeddedService) {
+		return describe(err)
+	}
+	replies, err := parseMultipleServiceReply(resp.Data)
+	if err == nil && len(replies) != len(tagNames) {
+		err = NewEipErrorWithDetails(ErrInvalidOperation, "write reply count mismatch",
+			map[string]interface{}{"expected": len(tagNames), "actual": len(replies)})
+	}
+	if err != nil {
+		return describe(err)
+	}
+	errs := make([]error, len(replies))
+	for i, reply := range replies {
+		if reply.GeneralStatus != CIPStatusSuccess {
+			errs[i] = fmt.Errorf("status 0x%02X", reply.GeneralStatus)
+		}
+	}
+	return errs
+}
+
+// bindingError reports the tags of a struct or group operation that failed
+func bindingError(op string, failed []string) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	return NewEipErrorWithDetails(ErrBatchOperationFailed,
+		fmt.Sprintf("%s failed: %s", op, strings.Join(failed, ", ")),
+		map[string]interface{}{"failed_items": failed})
+}
+
+// bindStruct returns the struct v points to and its tag bindings
+func bindStruct(v interface{}) (reflect.Value, []fieldBinding, error) {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, nil, NewEipError(ErrInvalidOperation, fmt.Sprintf("expected a pointer to a struct, got %T", v))
+	}
+	target := ptr.Elem()
+	bindings, err := structBindings(target.Type(), nil)
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+	if len(bindings) == 0 {
+		return reflect.Value{}, nil, NewEipError(ErrInvalidOperation,
+			fmt.Sprintf("%s has no fields with an eip struct tag", target.Type()))
+	}
+	seen := make(map[string]bool, len(bindings))
+	for _, b := range bindings {
+		if seen[b.tagName] {
+			return reflect.Value{}, nil, NewEipError(ErrInvalidTagName, fmt.Sprintf("tag '%s' is bound to more than one field", b.tagName))
+		}
+		seen[b.tagName] = true
+	}
+	return target, bindings, nil
+}
+
+// structBindings collects the bound fields of t, including those of embedded
+// structs
+func structBindings(t reflect.Type, index []int) ([]fieldBinding, error) {
+	var bindings []fieldBinding
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+		tag, ok := f.Tag.Lookup("eip")
+		if !ok {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				embedded, err := structBindings(f.Type, fieldIndex)
+				if err != nil {
+					return nil, err
+				}
+				bindings = append(bindings, embedded...)
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		if !f.IsExported() {
+			return nil, NewEipError(ErrInvalidOperation, fmt.Sprintf("field %s with an eip struct tag is not exported", f.Name))
+		}
+		tagName, typeName, _ := strings.Cut(tag, ",")
+		tagName = strings.TrimSpace(tagName)
+		if tagName == "" {
+			return nil, NewEipError(ErrInvalidTagName, fmt.Sprintf("field %s has an empty eip tag name", f.Name))
+		}
+		var dataType PlcDataType
+		var err error
+		if typeName = strings.TrimSpace(typeName); typeName != "" {
+			dataType, err = ParsePlcDataType(typeName)
+		} else {
+			dataType, err = fieldDataType(f.Type)
+		}
+		if err != nil {
+			return nil, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("field %s: %v", f.Name, err),
+				map[string]interface{}{"field": f.Name, "tag_name": tagName})
+		}
+		bindings = append(bindings, fieldBinding{tagName: tagName, dataType: dataType, index: fieldIndex})
+	}
+	return bindings, nil
+}
+
+// fieldDataType infers the PLC type of a field from its Go type
+func fieldDataType(t reflect.Type) (PlcDataType, error) {
+	switch t {
+	case timeType:
+		return Dt, nil
+	case durationType:
+		return Time, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return Bool, nil
+	case reflect.Int8:
+		return Sint, nil
+	case reflect.Int16:
+		return Int, nil
+	case reflect.Int32, reflect.Int:
+		return Dint, nil
+	case reflect.Int64:
+		return Lint, nil
+	case reflect.Uint8:
+		return Usint, nil
+	case reflect.Uint16:
+		return Uint, nil
+	case reflect.Uint32, reflect.Uint:
+		return Udint, nil
+	case reflect.Uint64:
+		return Ulint, nil
+	case reflect.Float32:
+		return Real, nil
+	case reflect.Float64:
+		return Lreal, nil
+	case reflect.String:
+		return String, nil
+	}
+	return 0, fmt.Errorf("no PLC type for %s; name one in the eip tag", t)
+}
+
+// assignField stores a value read from the PLC in a field, converting it to
+// the field's type
+func assignField(field reflect.Value, value interface{}) error {
+	src := reflect.ValueOf(value)
+	if !src.IsValid() {
+		return fmt.Errorf("no value")
+	}
+	if src.Type().AssignableTo(field.Type()) {
+		field.Set(src)
+		return nil
+	}
+
+	outOfRange := fmt.Errorf("value %v does not fit %s", value, field.Type())
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i = src.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if src.Uint() > 1<<63-1 {
+				return outOfRange
+			}
+			i = int64(src.Uint())
+		case reflect.Float32, reflect.Float64:
+			f := src.Float()
+			if f != float64(int64(f)) {
+				return outOfRange
+			}
+			i = int64(f)
+		default:
+			return fmt.Errorf("cannot store %T in %s", value, field.Type())
+		}
+		if field.OverflowInt(i) {
+			return outOfRange
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if src.Int() < 0 {
+				return outOfRange
+			}
+			u = uint64(src.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			u = src.Uint()
+		case reflect.Float32, reflect.Float64:
+			f := src.Float()
+			if f < 0 || f >= 1<<64 || f != float64(uint64(f)) {
+				return outOfRange
+			}
+			u = uint64(f)
+		default:
+			return fmt.Errorf("cannot store %T in %s", value, field.Type())
+		}
+		if field.OverflowUint(u) {
+			return outOfRange
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, ok := numericValue(value)
+		if !ok {
+			return fmt.Errorf("cannot store %T in %s", value, field.Type())
+		}
+		if field.OverflowFloat(f) {
+			return outOfRange
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("cannot store %T in %s", value, field.Type())
+		}
+		field.SetBool(b)
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("cannot store %T in %s", value, field.Type())
+		}
+		field.SetString(s)
+	default:
+		return fmt.Errorf("cannot store %T in %s", value, field.Type())
+	}
+	return nil
+}
+
+// writeTagRequest encodes a Write Tag request for one atomic value
+func writeTagRequest(tagName string, dataType PlcDataType, value interface{}) ([]byte, error) {
+	req, err := writeTagHeader(tagName, dataType)
+	if err != nil {
+		return nil, err
+	}
+	data, err := encodeElement(dataType, value)
+	if err != nil {
+		return nil, err
+	}
+	return append(req, data...), nil
+}
+
+// writeTagHeader encodes the part of a Write Tag request before the value:
+// service, path size, path, type and element count
+func writeTagHeader(tagName string, dataType PlcDataType) ([]byte, error) {
+	code, _, ok := cipTypeInfo(dataType)
+	if !ok {
+		return nil, NewEipError(ErrInvalidDataType, fmt.Sprintf("unsupported data type for '%s'", tagName))
+	}
+	path, err := tagRequestPath(tagName)
+	if err != nil {
+		return nil, err
+	}
+	req := append([]byte{CIPServiceWriteTag, byte(len(path) / 2)}, path...)
+	req = binary.LittleEndian.AppendUint16(req, code)
+	return binary.LittleEndian.AppendUint16(req, 1), nil
+}
+
+// packRequests splits embedded requests into Multiple Service Packets that
+// fit the unconnected message size, keeping their order
+func packRequests(requests [][]byte) [][][]byte {
+	// Service, path size, Message Router path and service count
+	empty := 2 + len(classInstancePath(CIPClassMessageRouter, 1)) + 2
+	var packets [][][]byte
+	size := 0
+	for _, req := range requests {
+		if len(packets) == 0 || size+2+len(req) > maxUnconnectedMessageSize {
+			packets = append(packets, nil)
+			size = empty
+		}
+		packets[len(packets)-1] = append(packets[len(packets)-1], req)
+		size += 2 + len(req)
+	}
+	return packets
+}
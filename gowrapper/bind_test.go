@@ -0,0 +1,137 @@
+package ethernetip
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type lineBase struct {
+	Running bool `eip:"Line1.Run"`
+}
+
+type line struct {
+	lineBase
+	Speed   float32       `eip:"Line1.Speed"`
+	Count   int           `eip:"Line1.Count"`
+	Started time.Time     `eip:"Line1.Started, LDT"`
+	Cycle   time.Duration `eip:"Line1.Cycle"`
+	Name    string        `eip:"Line1.Name"`
+	Fault   bool          `eip:"Line1.Status.3"`
+	Note    string        `eip:"-"`
+	Local   int
+}
+
+// TestBindStruct tests deriving tag bindings from struct tags
+func TestBindStruct(t *testing.T) {
+	_, bindings, err := bindStruct(&line{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[string]PlcDataType{
+		"Line1.Run": Bool, "Line1.Speed": Real, "Line1.Count": Dint, "Line1.Started": Ldt,
+		"Line1.Cycle": Time, "Line1.Name": String, "Line1.Status.3": Bool,
+	}
+	if len(bindings) != len(want) {
+		t.Fatalf("Expected %d bindings, got %+v", len(want), bindings)
+	}
+	for _, b := range bindings {
+		if want[b.tagName] != b.dataType {
+			t.Errorf("%s: got %s, want %s", b.tagName, b.dataType, want[b.tagName])
+		}
+		batched := b.tagName != "Line1.Name" && b.tagName != "Line1.Status.3"
+		if b.batched(&EipClient{}) != batched {
+			t.Errorf("%s: expected batched %v", b.tagName, batched)
+		}
+	}
+
+	invalid := []interface{}{
+		line{},
+		(*line)(nil),
+		&struct{ A int }{},
+		&struct {
+			A int `eip:"X"`
+			B int `eip:"X"`
+		}{},
+		&struct {
+			A []int `eip:"X"`
+		}{},
+		&struct {
+			A int `eip:"X,NOPE"`
+		}{},
+		&struct {
+			a int `eip:"X"`
+		}{},
+	}
+	for _, v := range invalid {
+		if _, _, err := bindStruct(v); err == nil {
+			t.Errorf("Expected error binding %T", v)
+		}
+	}
+}
+
+// TestAssignField tests converting PLC values to field types
+func TestAssignField(t *testing.T) {
+	var target struct {
+		I8  int8
+		U16 uint16
+		F32 float32
+		I   int
+		B   bool
+		S   string
+		T   time.Time
+	}
+	v := reflect.ValueOf(&target).Elem()
+	when := time.Unix(100, 0).UTC()
+	for i, value := range []interface{}{int16(-5), uint32(60000), 1.5, float64(7), true, "abc", when} {
+		if err := assignField(v.Field(i), value); err != nil {
+			t.Errorf("Field %d: %v", i, err)
+		}
+	}
+	if target.I8 != -5 || target.U16 != 60000 || target.F32 != 1.5 || target.I != 7 || !target.B || target.S != "abc" || !target.T.Equal(when) {
+		t.Errorf("Unexpected values: %+v", target)
+	}
+
+	for i, value := range []interface{}{int32(200), int32(-1), 1e40, 1.5, "x", 1, 2} {
+		if err := assignField(v.Field(i), value); err == nil {
+			t.Errorf("Field %d: expected error storing %v", i, value)
+		}
+	}
+}
+
+// TestWriteRequests tests encoding and packing of struct writes
+func TestWriteRequests(t *testing.T) {
+	req, err := writeTagRequest("Speed", Real, float32(1.5))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Service, path (symbol segment for "Speed" with pad byte), REAL, 1 element, value
+	want := []byte{0x4D, 0x04, 0x91, 0x05, 'S', 'p', 'e', 'e', 'd', 0x00, 0xCA, 0x00, 0x01, 0x00, 0x00, 0x00, 0xC0, 0x3F}
+	if !reflect.DeepEqual(req, want) {
+		t.Errorf("Got % X, want % X", req, want)
+	}
+	if _, err := writeTagRequest("Count", Sint, 300); err == nil {
+		t.Error("Expected error for a value that does not fit")
+	}
+
+	var requests [][]byte
+	for i := 0; i < 40; i++ {
+		req, _ := writeTagRequest(strings.Repeat("T", 20), Dint, i)
+		requests = append(requests, req)
+	}
+	packets := packRequests(requests)
+	if len(packets) < 2 {
+		t.Fatalf("Expected the writes to span packets, got %d", len(packets))
+	}
+	total := 0
+	for _, packet := range packets {
+		total += len(packet)
+		if size := len(buildMultipleServicePacket(packet)) + 6; size > maxUnconnectedMessageSize {
+			t.Errorf("Packet of %d bytes exceeds the limit", size)
+		}
+	}
+	if total != len(requests) {
+		t.Errorf("Expected %d requests in the packets, got %d", len(requests), total)
+	}
+}
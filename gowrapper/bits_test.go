@@ -0,0 +1,109 @@
+package ethernetip
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestSplitBitMember tests recognising bit addresses
+func TestSplitBitMember(t *testing.T) {
+	cases := []struct {
+		tag  string
+		base string
+		bit  int
+		ok   bool
+	}{
+		{"Status.5", "Status", 5, true},
+		{"Motors[2].Flags.31", "Motors[2].Flags", 31, true},
+		{"Program:Main.Word.0", "Program:Main.Word", 0, true},
+		{"Motor.Running", "", 0, false},
+		{"Status", "", 0, false},
+		{"Status.", "", 0, false},
+		{"Status.300", "", 0, false},
+	}
+	for _, c := range cases {
+		base, bit, ok := splitBitMember(c.tag)
+		if ok != c.ok || base != c.base || bit != c.bit {
+			t.Errorf("%s: got (%q, %d, %v)", c.tag, base, bit, ok)
+		}
+	}
+}
+
+// TestCheckBit tests bit range validation against the tag type
+func TestCheckBit(t *testing.T) {
+	if width, err := checkBit("A", CIPTypeDint, 31); err != nil || width != 4 {
+		t.Errorf("Expected DINT bit 31 to be valid, got %d, %v", width, err)
+	}
+	if _, err := checkBit("A", CIPTypeInt, 16); err == nil {
+		t.Error("Expected error for INT bit 16")
+	}
+	if _, err := checkBit("A", CIPTypeReal, 0); err == nil {
+		t.Error("Expected error for REAL tag")
+	}
+}
+
+// TestReadModifyWriteRequest tests the mask encoding
+func TestReadModifyWriteRequest(t *testing.T) {
+	set := readModifyWriteRequest(2, 1<<9, ^uint64(0))
+	if want := []byte{0x02, 0x00, 0x00, 0x02, 0xFF, 0xFF}; !bytes.Equal(set, want) {
+		t.Errorf("set: got % X, want % X", set, want)
+	}
+	clear := readModifyWriteRequest(4, 0, ^uint64(1))
+	if want := []byte{0x04, 0x00, 0, 0, 0, 0, 0xFE, 0xFF, 0xFF, 0xFF}; !bytes.Equal(clear, want) {
+		t.Errorf("clear: got % X, want % X", clear, want)
+	}
+}
+
+// TestCheckMasks tests mask validation against the tag width
+func TestCheckMasks(t *testing.T) {
+	if width, err := checkMasks("A", CIPTypeInt, 0x8001, ^uint64(0)); err != nil || width != 2 {
+		t.Errorf("Expected INT set mask to be valid, got %d, %v", width, err)
+	}
+	if _, err := checkMasks("A", CIPTypeInt, 0, ^uint64(0x8001)); err != nil {
+		t.Errorf("Expected INT clear mask to be valid, got %v", err)
+	}
+	if _, err := checkMasks("A", CIPTypeLint, 1<<63, 0); err != nil {
+		t.Errorf("Expected any LINT masks to be valid, got %v", err)
+	}
+	if _, err := checkMasks("A", CIPTypeSint, 1<<8, ^uint64(0)); err == nil {
+		t.Error("Expected error for a SINT set mask beyond bit 7")
+	}
+	if _, err := checkMasks("A", CIPTypeDint, 0, ^uint64(1<<40)); err == nil {
+		t.Error("Expected error for a DINT clear mask beyond bit 31")
+	}
+	if _, err := checkMasks("A", CIPTypeReal, 1, ^uint64(0)); err == nil {
+		t.Error("Expected error for REAL tag")
+	}
+}
+
+// TestBitAccess tests bit reads and writes against a real PLC
+func TestBitAccess(t *testing.T) {
+	skipIfNoPlc(t)
+	client, err := NewClient(getTestPlcIP())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.WriteDint("TestDint", 0); err != nil {
+		t.Skipf("TestDint not available: %v", err)
+	}
+	if err := client.WriteBit("TestDint", 5, true); err != nil {
+		t.Fatalf("WriteBit failed: %v", err)
+	}
+	if v, err := client.ReadDint("TestDint"); err != nil || v != 32 {
+		t.Errorf("Expected 32, got %d (%v)", v, err)
+	}
+	if on, err := client.ReadBool("TestDint.5"); err != nil || !on {
+		t.Errorf("Expected bit 5 set, got %v (%v)", on, err)
+	}
+	if err := client.SetBits("TestDint", 0x0F); err != nil {
+		t.Fatalf("SetBits failed: %v", err)
+	}
+	if err := client.ClearBits("TestDint", 0x21); err != nil {
+		t.Fatalf("ClearBits failed: %v", err)
+	}
+	if v, err := client.ReadDint("TestDint"); err != nil || v != 0x0E {
+		t.Errorf("Expected 0x0E, got %#x (%v)", v, err)
+	}
+}
@@ -0,0 +1,127 @@
+// Package boltstore keeps the writes of an ethernetip.WriteQueue in a bbolt
+// database, for edge devices that already keep their state in one or want
+// transactional storage instead of the JSON lines log of FileWriteStore.
+// Each queued write is one key of a bucket, so queuing a write is a single
+// committed transaction however long the queue is.
+package boltstore
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultBucket is the bucket Open keeps the queue in
+const DefaultBucket = "eip_write_queue"
+
+// Store is an ethernetip.AppendWriteStore backed by a bbolt bucket. Writes
+// are keyed by their sequence number, so they load in the order queued, and
+// encoded as by QueuedWrite.MarshalJSON.
+type Store struct {
+	db     *bolt.DB
+	bucket []byte
+	owned  bool // Close closes db
+}
+
+// Open opens or creates the database file at path and keeps the queue in
+// DefaultBucket. It waits at most a second for another process holding the
+// file to release it.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open write queue database: %v", err)
+	}
+	s, err := New(db, DefaultBucket)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	s.owned = true
+	return s, nil
+}
+
+// New keeps the queue in bucket of an open database, which may hold other
+// buckets of the application. The database stays open when the store is
+// closed.
+func New(db *bolt.DB, bucket string) (*Store, error) {
+	s := &Store{db: db, bucket: []byte(bucket)}
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(s.bucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create write queue bucket: %v", err)
+	}
+	return s, nil
+}
+
+// Close closes the database if the store opened it
+func (s *Store) Close() error {
+	if !s.owned {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Append implements ethernetip.AppendWriteStore
+func (s *Store) Append(write ethernetip.QueuedWrite) error {
+	value, err := json.Marshal(write)
+	if err != nil {
+		return fmt.Errorf("failed to marshal queued write to %s: %v", write.TagName, err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Put(key(write.Seq), value)
+	})
+}
+
+// Save implements ethernetip.WriteStore
+func (s *Store) Save(writes []ethernetip.QueuedWrite) error {
+	values := make([][]byte, len(writes))
+	for i, w := range writes {
+		value, err := json.Marshal(w)
+		if err != nil {
+			return fmt.Errorf("failed to marshal queued write to %s: %v", w.TagName, err)
+		}
+		values[i] = value
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(s.bucket); err != nil {
+			return err
+		}
+		b, err := tx.CreateBucket(s.bucket)
+		if err != nil {
+			return err
+		}
+		for i, w := range writes {
+			if err := b.Put(key(w.Seq), values[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Load implements ethernetip.WriteStore
+func (s *Store) Load() ([]ethernetip.QueuedWrite, error) {
+	var writes []ethernetip.QueuedWrite
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).ForEach(func(k, v []byte) error {
+			var w ethernetip.QueuedWrite
+			if err := json.Unmarshal(v, &w); err != nil {
+				return fmt.Errorf("failed to parse queued write %x: %v", k, err)
+			}
+			writes = append(writes, w)
+			return nil
+		})
+	})
+	return writes, err
+}
+
+// key encodes a sequence number so keys sort in queue order
+func key(seq uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, seq)
+}
@@ -0,0 +1,99 @@
+package boltstore
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/eiptest"
+	bolt "go.etcd.io/bbolt"
+)
+
+// TestStoreRoundTrip tests that queued writes survive reopening the
+// database with their Go types, and that flushed writes leave it
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.db")
+	fake := eiptest.NewFakeClient()
+	fake.SetErr(ethernetip.NewEipError(ethernetip.ErrConnectionFailed, "connection lost"))
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue, err := ethernetip.NewWriteQueue(fake, ethernetip.WriteQueueOptions{Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []*ethernetip.PlcValue{
+		{Type: ethernetip.Dint, Value: int32(42)},
+		{Type: ethernetip.Real, Value: math.NaN()},
+		{Type: ethernetip.Lint, Value: int64(9007199254740993)},
+	} {
+		if queued, err := queue.Write("Tag", value); !queued || err != nil {
+			t.Fatalf("Expected %v to be queued, got %v, %v", value.Value, queued, err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	restored, err := ethernetip.NewWriteQueue(fake, ethernetip.WriteQueueOptions{Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := restored.Pending()
+	if len(pending) != 3 {
+		t.Fatalf("Expected 3 restored writes, got %d", len(pending))
+	}
+	if f, ok := pending[1].Value.(float64); pending[0].Value != int32(42) || !ok || !math.IsNaN(f) || pending[2].Value != int64(9007199254740993) {
+		t.Errorf("Expected the values with their types, got %#v", pending)
+	}
+
+	fake.SetErr(nil)
+	if result, err := restored.Flush(); err != nil || result.Written != 3 {
+		t.Fatalf("Expected 3 writes flushed, got %+v, %v", result, err)
+	}
+	if writes, err := store.Load(); err != nil || len(writes) != 0 {
+		t.Errorf("Expected an empty store after the flush, got %v, %v", writes, err)
+	}
+}
+
+// TestNewSharedDatabase tests that a store in a shared database keeps to its
+// bucket and leaves the database open
+func TestNewSharedDatabase(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "app.db"), 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	store, err := New(db, "writes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := ethernetip.QueuedWrite{Seq: 7, TagName: "Count", DataType: ethernetip.Dint, Value: int32(1)}
+	if err := store.Append(write); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte("writes")).Get(key(7)) == nil {
+			t.Error("Expected the write in the writes bucket")
+		}
+		if tx.Bucket([]byte(DefaultBucket)) != nil {
+			t.Errorf("Expected no %s bucket", DefaultBucket)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected the database to stay open, got %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package ethernetip
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TagNodeKind is the kind of a node in the tag browse tree
+type TagNodeKind int
+
+const (
+	// NodeController is the root of the tree, holding the controller-scope
+	// tags and the programs
+	NodeController TagNodeKind = iota
+	// NodeProgram is a program scope, holding the program's tags
+	NodeProgram
+	// NodeTag is a controller- or program-scope tag
+	NodeTag
+	// NodeMember is a member of a structure
+	NodeMember
+	// NodeElement is an element of an array
+	NodeElement
+)
+
+// String returns the name of the kind
+func (k TagNodeKind) String() string {
+	switch k {
+	case NodeController:
+		return "controller"
+	case NodeProgram:
+		return "program"
+	case NodeTag:
+		return "tag"
+	case NodeMember:
+		return "member"
+	case NodeElement:
+		return "element"
+	default:
+		return fmt.Sprintf("TagNodeKind(%d)", int(k))
+	}
+}
+
+// MarshalJSON encodes the kind as its name
+func (k TagNodeKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+// TagNode is a node of the tag browse tree returned by BrowseTags. Programs
+// and their tags are listed up front; the members of structures and the
+// elements of arrays are read from the controller only when the node is
+// expanded, so browsing stays fast on controllers with tens of thousands of
+// tags.
+type TagNode struct {
+	// Name is the name shown for the node: the tag, program or member name,
+	// or the index of an array element ("[3]")
+	Name string `json:"name"`
+	// Path is the full tag path to read the node with, e.g.
+	// "Program:Main.Recipe.Steps[3]"; empty for the root and programs
+	Path string      `json:"path,omitempty"`
+	Kind TagNodeKind `json:"kind"`
+	// Type is the atomic type name ("DINT") or the template name of a
+	// structure. It is empty for structures whose template has not been read
+	// yet; Expand fills it in.
+	Type string `json:"type,omitempty"`
+	// Dimensions is the number of array dimensions, 0 for scalars
+	Dimensions int `json:"dimensions,omitempty"`
+	// Expandable reports whether the node has children, loaded or not
+	Expandable bool `json:"expandable,omitempty"`
+	// Children are the loaded children; nil until Expand for structures and
+	// arrays
+	Children []*TagNode `json:"children,omitempty"`
+
+	client    *EipClient
+	structure bool
+	template  uint16 // Template instance of a structure
+	size      int    // Elements of an array, 0 if not known yet
+	mu        sync.Mutex
+	expanded  bool
+}
+
+// BrowseTags returns the tags of the last DiscoverTagDatabase as a tree like
+// the controller organizer of the programming software: the root holds the
+// controller-scope tags followed by one node per program. System tags are
+// left out. Structures and arrays are expanded on demand with
+// (*TagNode).Expand.
+func (c *EipClient) BrowseTags() (*TagNode, error) {
+	db := c.TagDatabase()
+	if db == nil {
+		return nil, NewEipError(ErrInvalidOperation, "no tag database; run DiscoverTagDatabase first")
+	}
+
+	root := &TagNode{Kind: NodeController, Expandable: true, expanded: true, client: c}
+	programs := map[string]*TagNode{}
+	var programOrder []*TagNode
+	program := func(name string) *TagNode {
+		if node, ok := programs[name]; ok {
+			return node
+		}
+		node := &TagNode{Name: name, Kind: NodeProgram, Expandable: true, expanded: true, client: c}
+		programs[name] = node
+		programOrder = append(programOrder, node)
+		return node
+	}
+
+	for _, tag := range db.Tags {
+		if tag.IsSystem() {
+			continue
+		}
+		if tag.Program == "" && strings.HasPrefix(tag.Name, "Program:") {
+			program(strings.TrimPrefix(tag.Name, "Program:"))
+			continue
+		}
+		node := c.tagNode(tag)
+		if tag.Program == "" {
+			root.Children = append(root.Children, node)
+		} else {
+			parent := program(tag.Program)
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	root.Children = append(root.Children, programOrder...)
+	return root, nil
+}
+
+// tagNode returns the browse node of a tag in the tag database
+func (c *EipClient) tagNode(tag TagInfo) *TagNode {
+	name := tag.Name
+	if tag.Program != "" {
+		name = strings.TrimPrefix(name, "Program:"+tag.Program+".")
+	}
+	node := &TagNode{
+		Name:       name,
+		Path:       tag.Name,
+		Kind:       NodeTag,
+		Dimensions: tag.Dimensions(),
+		client:     c,
+		structure:  tag.IsStructure(),
+	}
+	c.setNodeType(node, tag.TypeCode())
+	return node
+}
+
+// setNodeType sets the type of node from a type code: an atomic type, or
+// the template instance if node is a structure
+func (c *EipClient) setNodeType(node *TagNode, code uint16) {
+	node.Expandable = node.structure || node.Dimensions > 0
+	if !node.structure {
+		if dataType, ok := atomicDataType(code); ok {
+			node.Type = dataType.String()
+		}
+		return
+	}
+	node.template = code
+	if cached, ok := c.templates.Load(code); ok {
+		node.Type = cached.(*StructTemplate).Name
+	}
+}
+
+// Expand loads and returns the children of the node: the members of a
+// structure, or the elements of an array. Templates are read through
+// GetTemplate and array sizes through GetTagMetadataCached, so expanding a
+// node a second time, or another tag of the same type, does not go to the
+// controller again. Multi-dimensional arrays cannot be expanded.
+func (n *TagNode) Expand() ([]*TagNode, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.expanded || !n.Expandable {
+		return n.Children, nil
+	}
+
+	var children []*TagNode
+	var err error
+	if n.Dimensions > 0 {
+		children, err = n.elements()
+	} else {
+		children, err = n.members()
+	}
+	if err != nil {
+		return nil, err
+	}
+	n.Children = children
+	n.expanded = true
+	return children, nil
+}
+
+// elements returns the element nodes of an array
+func (n *TagNode) elements() ([]*TagNode, error) {
+	if n.Dimensions > 1 {
+		return nil, NewEipErrorWithDetails(ErrInvalidTagDimension,
+			fmt.Sprintf("cannot expand '%s': it has %d dimensions", n.Path, n.Dimensions),
+			map[string]interface{}{"tag_name": n.Path, "dimensions": n.Dimensions})
+	}
+	if n.size == 0 {
+		meta, err := n.client.GetTagMetadataCached(n.Path)
+		if err != nil {
+			return nil, err
+		}
+		n.size = meta.ArraySize
+	}
+	if n.structure && n.Type == "" {
+		if template, err := n.client.GetTemplate(n.template); err == nil {
+			n.Type = template.Name
+		}
+	}
+
+	children := make([]*TagNode, n.size)
+	for i := range children {
+		index := fmt.Sprintf("[%d]", i)
+		children[i] = &TagNode{
+			Name:       index,
+			Path:       n.Path + index,
+			Kind:       NodeElement,
+			Type:       n.Type,
+			Expandable: n.structure,
+			client:     n.client,
+			structure:  n.structure,
+			template:   n.template,
+		}
+	}
+	return children, nil
+}
+
+// members returns the member nodes of a structure, leaving out the hidden
+// members that hold packed BOOLs
+func (n *TagNode) members() ([]*TagNode, error) {
+	template, err := n.client.GetTemplate(n.template)
+	if err != nil {
+		return nil, err
+	}
+	n.Type = template.Name
+
+	var children []*TagNode
+	for _, m := range template.Members {
+		if hiddenMember(m) {
+			continue
+		}
+		child := &TagNode{
+			Name:      m.Name,
+			Path:      n.Path + "." + m.Name,
+			Kind:      NodeMember,
+			client:    n.client,
+			structure: m.IsStructure(),
+		}
+		if m.IsArray() {
+			child.Dimensions = 1
+			child.size = int(m.Info)
+		}
+		n.client.setNodeType(child, m.TypeCode())
+		children = append(children, child)
+	}
+	return children, nil
+}
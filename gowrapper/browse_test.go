@@ -0,0 +1,105 @@
+package ethernetip
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// browseClient returns a client with a tag database, templates and array
+// metadata for browsing
+func browseClient() *EipClient {
+	client := &EipClient{tagCache: map[string]*TagMetadata{"Values": {DataType: 0xC4, ArrayDimension: 1, ArraySize: 4}}}
+	client.tagDB.Store(NewTagDatabase([]TagInfo{
+		{Name: "Speed", SymbolType: CIPTypeDint},
+		{Name: "Recipe", SymbolType: symbolTypeStructBit | 0x100},
+		{Name: "Values", SymbolType: 1<<symbolTypeDimsShift | CIPTypeDint},
+		{Name: "Grid", SymbolType: 2<<symbolTypeDimsShift | CIPTypeDint},
+		{Name: "Program:Main", SymbolType: 0x68},
+		{Name: "Program:Main.Count", SymbolType: CIPTypeDint, Program: "Main"},
+		{Name: "__Internal", SymbolType: symbolTypeSystemBit | CIPTypeDint},
+	}))
+	client.templates.Store(uint16(0x100), &StructTemplate{Instance: 0x100, Name: "RECIPE", Members: []TemplateMember{
+		{Name: "ZZZZZZZZZZRECIPE0", Type: CIPTypeSint},
+		{Name: "Running", Type: CIPTypeBool, Info: 0},
+		{Name: "Steps", Type: symbolTypeStructBit | 1<<symbolTypeDimsShift | 0x101, Info: 3, Offset: 4},
+		{Name: "Setpoint", Type: CIPTypeReal, Offset: 28},
+	}})
+	client.templates.Store(uint16(0x101), &StructTemplate{Instance: 0x101, Name: "STEP", Members: []TemplateMember{
+		{Name: "Duration", Type: CIPTypeDint},
+	}})
+	return client
+}
+
+// TestBrowseTags tests the top of the tree: controller tags then programs,
+// without system tags
+func TestBrowseTags(t *testing.T) {
+	if _, err := (&EipClient{}).BrowseTags(); err == nil {
+		t.Error("Expected an error without a tag database")
+	}
+
+	root, err := browseClient().BrowseTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, node := range root.Children {
+		names = append(names, node.Kind.String()+":"+node.Name)
+	}
+	want := []string{"tag:Grid", "tag:Recipe", "tag:Speed", "tag:Values", "program:Main"}
+	if len(names) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, names)
+		}
+	}
+
+	main := root.Children[4]
+	if len(main.Children) != 1 || main.Children[0].Name != "Count" || main.Children[0].Path != "Program:Main.Count" {
+		t.Errorf("Unexpected program tags %+v", main.Children)
+	}
+	speed := root.Children[2]
+	if speed.Type != "DINT" || speed.Expandable {
+		t.Errorf("Unexpected scalar node %+v", speed)
+	}
+	if data, err := json.Marshal(speed); err != nil || string(data) != `{"name":"Speed","path":"Speed","kind":"tag","type":"DINT"}` {
+		t.Errorf("Unexpected JSON %s (%v)", data, err)
+	}
+}
+
+// TestTagNodeExpand tests expanding structures and arrays on demand
+func TestTagNodeExpand(t *testing.T) {
+	root, err := browseClient().BrowseTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipe, values, grid := root.Children[1], root.Children[3], root.Children[0]
+	if recipe.Type != "RECIPE" || !recipe.Expandable || recipe.Children != nil {
+		t.Fatalf("Expected an unexpanded structure, got %+v", recipe)
+	}
+
+	members, err := recipe.Expand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 3 || members[0].Name != "Running" || members[0].Type != "BOOL" || members[2].Path != "Recipe.Setpoint" {
+		t.Fatalf("Unexpected members %+v", members)
+	}
+	steps, err := members[1].Expand()
+	if err != nil || len(steps) != 3 || steps[2].Path != "Recipe.Steps[2]" || steps[2].Type != "STEP" || !steps[2].Expandable {
+		t.Fatalf("Unexpected elements %+v (%v)", steps, err)
+	}
+	step, err := steps[2].Expand()
+	if err != nil || len(step) != 1 || step[0].Path != "Recipe.Steps[2].Duration" || step[0].Kind != NodeMember {
+		t.Errorf("Unexpected step members %+v (%v)", step, err)
+	}
+
+	elements, err := values.Expand()
+	if err != nil || len(elements) != 4 || elements[3].Path != "Values[3]" || elements[3].Kind != NodeElement || elements[3].Expandable {
+		t.Errorf("Unexpected array elements %+v (%v)", elements, err)
+	}
+	if _, err := grid.Expand(); err == nil {
+		t.Error("Expected an error expanding a multi-dimensional array")
+	}
+}
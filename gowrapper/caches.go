@@ -0,0 +1,51 @@
+package ethernetip
+
+import "sort"
+
+// CacheContents lists what the client has cached from the controller
+type CacheContents struct {
+	// TagMetadata is the metadata cache of GetTagMetadataCached, keyed by
+	// TagNameOptions key
+	TagMetadata map[string]TagMetadata `json:"tag_metadata"`
+	// Templates are the structure templates read by GetTemplate, by instance
+	Templates []StructTemplate `json:"templates"`
+	// StringTypes maps string tags to the DATA capacity of the string type
+	// learned for them
+	StringTypes map[string]int `json:"string_types"`
+	// TagDatabaseTags is the number of tags in the tag database, which
+	// FlushCaches keeps
+	TagDatabaseTags int `json:"tag_database_tags"`
+}
+
+// CacheContents returns a copy of the client's caches
+func (c *EipClient) CacheContents() CacheContents {
+	contents := CacheContents{
+		TagMetadata: make(map[string]TagMetadata),
+		StringTypes: make(map[string]int),
+	}
+	c.tagCacheMu.RLock()
+	for key, meta := range c.tagCache {
+		contents.TagMetadata[key] = *meta
+	}
+	c.tagCacheMu.RUnlock()
+	c.templates.Range(func(_, v interface{}) bool {
+		contents.Templates = append(contents.Templates, *v.(*StructTemplate))
+		return true
+	})
+	sort.Slice(contents.Templates, func(i, j int) bool { return contents.Templates[i].Instance < contents.Templates[j].Instance })
+	c.stringTypes.Range(func(k, v interface{}) bool {
+		contents.StringTypes[k.(string)] = v.(stringType).capacity
+		return true
+	})
+	contents.TagDatabaseTags = c.TagDatabase().Len()
+	return contents
+}
+
+// FlushCaches clears the tag metadata, template and string type caches, so
+// they are read again from the controller, e.g. after a program download.
+// The tag database is kept; run DiscoverTagDatabase to refresh it.
+func (c *EipClient) FlushCaches() {
+	c.ClearTagCache()
+	c.templates.Clear()
+	c.stringTypes.Clear()
+}
@@ -0,0 +1,27 @@
+package ethernetip
+
+import "testing"
+
+// TestFlushCaches tests that the caches are listed and flushed, keeping the
+// tag database
+func TestFlushCaches(t *testing.T) {
+	client := &EipClient{tagCache: map[string]*TagMetadata{"speed": {DataType: 0xC4}}}
+	client.tagDB.Store(NewTagDatabase([]TagInfo{{Name: "Speed", SymbolType: CIPTypeDint}}))
+	client.templates.Store(uint16(0x0F10), &StructTemplate{Instance: 0x0F10, Name: "STRING20"})
+	client.stringTypes.Store("message", stringType{capacity: 20})
+
+	contents := client.CacheContents()
+	if contents.TagMetadata["speed"].DataType != 0xC4 || len(contents.Templates) != 1 ||
+		contents.StringTypes["message"] != 20 || contents.TagDatabaseTags != 1 {
+		t.Fatalf("Unexpected cache contents %+v", contents)
+	}
+
+	client.FlushCaches()
+	contents = client.CacheContents()
+	if len(contents.TagMetadata) != 0 || len(contents.Templates) != 0 || len(contents.StringTypes) != 0 {
+		t.Errorf("Expected empty caches, got %+v", contents)
+	}
+	if contents.TagDatabaseTags != 1 {
+		t.Error("Expected the tag database to be kept")
+	}
+}
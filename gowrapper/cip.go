@@ -0,0 +1,312 @@
+package ethernetip
+
+/*
+#include <stdlib.h>
+
+// Generic CIP messaging
+extern int eip_send_cip_request(int client_id, const unsigned char* request, int request_len, unsigned char* response, int response_capacity, int* response_len);
+*/
+import "C"
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/tagpath"
+)
+
+// CIP service codes used by the wrapper
+const (
+	CIPServiceGetAttributesAll         byte = 0x01
+	CIPServiceGetAttributeList         byte = 0x03
+	CIPServiceSetAttributeList         byte = 0x04
+	CIPServiceMultipleServicePacket    byte = 0x0A
+	CIPServiceGetAttributeSingle       byte = 0x0E
+	CIPServiceSetAttributeSingle       byte = 0x10
+	CIPServiceReadTag                  byte = 0x4C
+	CIPServiceWriteTag                 byte = 0x4D
+	CIPServiceReadModifyWriteTag       byte = 0x4E
+	CIPServiceReadTagFragmented        byte = 0x52
+	CIPServiceWriteTagFragmented       byte = 0x53
+	CIPServiceGetInstanceAttributeList byte = 0x55
+)
+
+// CIP general status codes
+const (
+	CIPStatusSuccess         byte = 0x00
+	CIPStatusPathUnknown     byte = 0x05
+	CIPStatusPartialTransfer byte = 0x06
+	CIPStatusEmbeddedService byte = 0x1E // One or more embedded services failed
+)
+
+// CIP object classes used by the wrapper
+const (
+	CIPClassIdentity      uint16 = 0x01
+	CIPClassMessageRouter uint16 = 0x02
+	CIPClassSymbol        uint16 = 0x6B
+	CIPClassTemplate      uint16 = 0x6C
+)
+
+// defaultCIPResponseSize is the initial reply buffer size for SendCIPRequest
+const defaultCIPResponseSize = 4096
+
+// maxUnconnectedMessageSize is the largest CIP message an unconnected
+// (UCMM) request or reply may carry
+const maxUnconnectedMessageSize = 504
+
+// CIPResponse is a parsed CIP Message Router reply
+type CIPResponse struct {
+	Service        byte     // Reply service code (request service | 0x80)
+	GeneralStatus  byte     // CIP general status
+	ExtendedStatus []uint16 // Additional status words, if any
+	Data           []byte   // Response data following the status
+}
+
+// ParseCIPResponse parses a raw CIP Message Router reply
+func ParseCIPResponse(reply []byte) (*CIPResponse, error) {
+	if len(reply) < 4 {
+		return nil, NewEipErrorWithDetails(ErrInvalidOperation, "CIP reply too short",
+			map[string]interface{}{"length": len(reply)})
+	}
+	extWords := int(reply[3])
+	if len(reply) < 4+extWords*2 {
+		return nil, NewEipErrorWithDetails(ErrInvalidOperation, "CIP reply truncated in extended status",
+			map[string]interface{}{"length": len(reply), "extended_status_words": extWords})
+	}
+	resp := &CIPResponse{
+		Service:       reply[0],
+		GeneralStatus: reply[2],
+		Data:          reply[4+extWords*2:],
+	}
+	for i := 0; i < extWords; i++ {
+		resp.ExtendedStatus = append(resp.ExtendedStatus, binary.LittleEndian.Uint16(reply[4+i*2:]))
+	}
+	return resp, nil
+}
+
+// cipStatusError converts a failed CIP reply into an EipError
+func cipStatusError(service byte, resp *CIPResponse) error {
+	return NewEipErrorWithDetails(ErrInvalidOperation,
+		fmt.Sprintf("CIP service 0x%02X failed with status 0x%02X", service, resp.GeneralStatus),
+		map[string]interface{}{
+			"service":         service,
+			"cip_status":      resp.GeneralStatus,
+			"extended_status": resp.ExtendedStatus,
+		})
+}
+
+// SendCIPRequest sends a raw CIP Message Router request through the client's
+// session and returns the raw reply. This is the generic messaging primitive
+// used for services the typed API does not cover. While the write policy is
+// read-only, only read services are sent.
+func (c *EipClient) SendCIPRequest(request []byte) ([]byte, error) {
+	if len(request) == 0 {
+		return nil, NewEipError(ErrInvalidOperation, "CIP request cannot be empty")
+	}
+	if err := c.checkCIPRequest(request); err != nil {
+		return nil, err
+	}
+
+	c.beginUse()
+	defer c.endUse()
+	var reply []byte
+	err := c.traced(nil, func() error {
+		var err error
+		reply, err = c.sendCIPRequest(request)
+		return err
+	})
+	return reply, err
+}
+
+// sendCIPRequest issues request, growing the reply buffer as needed
+func (c *EipClient) sendCIPRequest(request []byte) ([]byte, error) {
+	capacity := defaultCIPResponseSize
+	for {
+		response := make([]byte, capacity)
+		var responseLen C.int
+		retCode := int(C.eip_send_cip_request(
+			C.int(c.id()),
+			(*C.uchar)(unsafe.Pointer(&request[0])),
+			C.int(len(request)),
+			(*C.uchar)(unsafe.Pointer(&response[0])),
+			C.int(capacity),
+			&responseLen,
+		))
+		switch {
+		case retCode == -2 && int(responseLen) > capacity:
+			capacity = int(responseLen)
+			continue
+		case retCode != 0:
+			return nil, NewEipErrorWithDetails(ErrConnectionFailed, "Failed to send CIP request",
+				map[string]interface{}{
+					"error_code": retCode,
+					"client_id":  c.id(),
+				})
+		}
+		return response[:int(responseLen)], nil
+	}
+}
+
+// SendCIPMessage builds a CIP request from a service code, an encoded request
+// path and request data, sends it and parses the reply. Replies with a general
+// status other than success or partial transfer are returned as errors.
+func (c *EipClient) SendCIPMessage(service byte, path []byte, data []byte) (*CIPResponse, error) {
+	if len(path)%2 != 0 {
+		return nil, NewEipError(ErrInvalidTagAddress, "CIP path must be an even number of bytes")
+	}
+	request := make([]byte, 0, 2+len(path)+len(data))
+	request = append(request, service, byte(len(path)/2))
+	request = append(request, path...)
+	request = append(request, data...)
+
+	reply, err := c.SendCIPRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ParseCIPResponse(reply)
+	if err != nil {
+		return nil, err
+	}
+	if resp.GeneralStatus != CIPStatusSuccess && resp.GeneralStatus != CIPStatusPartialTransfer {
+		return resp, cipStatusError(service, resp)
+	}
+	return resp, nil
+}
+
+// logicalSegment encodes a logical segment of the given type (0x20 class,
+// 0x24 instance, 0x30 attribute, 0x28 member) using the smallest format.
+func logicalSegment(segType byte, value uint32) []byte {
+	switch {
+	case value <= 0xFF:
+		return []byte{segType, byte(value)}
+	case value <= 0xFFFF:
+		return []byte{segType | 0x01, 0x00, byte(value), byte(value >> 8)}
+	default:
+		b := []byte{segType | 0x02, 0x00, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(b[2:], value)
+		return b
+	}
+}
+
+// classInstancePath encodes a class/instance request path
+func classInstancePath(class uint16, instance uint32) []byte {
+	return append(logicalSegment(0x20, uint32(class)), logicalSegment(0x24, instance)...)
+}
+
+// classInstanceAttributePath encodes a class/instance/attribute request path
+func classInstanceAttributePath(class uint16, instance uint32, attribute uint16) []byte {
+	return append(classInstancePath(class, instance), logicalSegment(0x30, uint32(attribute))...)
+}
+
+// symbolicSegment encodes an ANSI extended symbolic segment, padded to an even length
+func symbolicSegment(name string) []byte {
+	b := make([]byte, 0, 3+len(name))
+	b = append(b, 0x91, byte(len(name)))
+	b = append(b, name...)
+	if len(name)%2 != 0 {
+		b = append(b, 0x00)
+	}
+	return b
+}
+
+// tagRequestPath encodes a Logix tag name such as "Program:Main.Recipe[2].Speed"
+// as a request path of symbolic and element segments
+func tagRequestPath(tagName string) ([]byte, error) {
+	parsed, err := parseTagPath(tagName)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.HasBit {
+		return nil, NewEipError(ErrInvalidTagAddress, fmt.Sprintf("bit member '%d' cannot be addressed symbolically in '%s'; use ReadBit/WriteBit", parsed.Bit, tagName))
+	}
+	var path []byte
+	if parsed.Program != "" {
+		path = append(path, symbolicSegment("Program:"+parsed.Program)...)
+	}
+	for _, member := range parsed.Members {
+		path = append(path, symbolicSegment(member.Name)...)
+		for _, index := range member.Indices {
+			path = append(path, logicalSegment(0x28, uint32(index))...)
+		}
+	}
+	return path, nil
+}
+
+// parseTagPath parses a tag name (see package tagpath). Empty names fail with
+// ErrInvalidTagName, malformed ones with ErrInvalidTagAddress.
+func parseTagPath(tagName string) (tagpath.Path, error) {
+	if tagName == "" {
+		return tagpath.Path{}, NewEipError(ErrInvalidTagName, "Tag name cannot be empty")
+	}
+	path, err := tagpath.Parse(tagName)
+	if err != nil {
+		return path, NewEipErrorWithDetails(ErrInvalidTagAddress, fmt.Sprintf("invalid tag name '%s': %v", tagName, err),
+			map[string]interface{}{"tag_name": tagName})
+	}
+	return path, nil
+}
+
+// splitBitMember splits a bit address such as "Status.5" or "Motors[2].Flags.31"
+// into the integer tag and the bit number. ok is false when the last member is
+// not a bit number.
+func splitBitMember(tagName string) (base string, bit int, ok bool) {
+	i := strings.LastIndex(tagName, ".")
+	if i <= 0 || i == len(tagName)-1 {
+		return "", 0, false
+	}
+	n, err := strconv.ParseUint(tagName[i+1:], 10, 8)
+	if err != nil {
+		return "", 0, false
+	}
+	return tagName[:i], int(n), true
+}
+
+// buildMultipleServicePacket encodes the request data of a Multiple Service
+// Packet: the service count, the offset of each service and the services
+func buildMultipleServicePacket(requests [][]byte) []byte {
+	header := 2 + 2*len(requests)
+	data := make([]byte, header)
+	binary.LittleEndian.PutUint16(data, uint16(len(requests)))
+	offset := header
+	for i, req := range requests {
+		binary.LittleEndian.PutUint16(data[2+2*i:], uint16(offset))
+		offset += len(req)
+	}
+	for _, req := range requests {
+		data = append(data, req...)
+	}
+	return data
+}
+
+// parseMultipleServiceReply splits a Multiple Service Packet reply into its
+// embedded replies
+func parseMultipleServiceReply(data []byte) ([]*CIPResponse, error) {
+	if len(data) < 2 {
+		return nil, NewEipError(ErrInvalidOperation, "Multiple Service Packet reply too short")
+	}
+	count := int(binary.LittleEndian.Uint16(data))
+	if len(data) < 2+2*count {
+		return nil, NewEipErrorWithDetails(ErrInvalidOperation, "Multiple Service Packet reply truncated",
+			map[string]interface{}{"length": len(data), "count": count})
+	}
+	replies := make([]*CIPResponse, count)
+	for i := 0; i < count; i++ {
+		start := int(binary.LittleEndian.Uint16(data[2+2*i:]))
+		end := len(data)
+		if i+1 < count {
+			end = int(binary.LittleEndian.Uint16(data[2+2*(i+1):]))
+		}
+		if start > end || end > len(data) {
+			return nil, NewEipErrorWithDetails(ErrInvalidOperation, "invalid Multiple Service Packet offset",
+				map[string]interface{}{"index": i, "start": start, "end": end})
+		}
+		resp, err := ParseCIPResponse(data[start:end])
+		if err != nil {
+			return nil, err
+		}
+		replies[i] = resp
+	}
+	return replies, nil
+}
@@ -0,0 +1,106 @@
+package ethernetip
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestParseCIPResponse tests parsing of CIP Message Router replies
+func TestParseCIPResponse(t *testing.T) {
+	resp, err := ParseCIPResponse([]byte{0xCC, 0x00, 0x00, 0x00, 0xC4, 0x00, 0x2A, 0x00, 0x00, 0x00})
+	if err != nil {
+		t.Fatalf("Failed to parse reply: %v", err)
+	}
+	if resp.Service != 0xCC || resp.GeneralStatus != CIPStatusSuccess {
+		t.Errorf("Unexpected header: %+v", resp)
+	}
+	if !bytes.Equal(resp.Data, []byte{0xC4, 0x00, 0x2A, 0x00, 0x00, 0x00}) {
+		t.Errorf("Unexpected data: % X", resp.Data)
+	}
+
+	resp, err = ParseCIPResponse([]byte{0xCC, 0x00, 0xFF, 0x01, 0x05, 0x21})
+	if err != nil {
+		t.Fatalf("Failed to parse error reply: %v", err)
+	}
+	if resp.GeneralStatus != 0xFF || len(resp.ExtendedStatus) != 1 || resp.ExtendedStatus[0] != 0x2105 {
+		t.Errorf("Unexpected extended status: %+v", resp)
+	}
+
+	if _, err := ParseCIPResponse([]byte{0xCC, 0x00}); err == nil {
+		t.Error("Expected error for short reply")
+	}
+	if _, err := ParseCIPResponse([]byte{0xCC, 0x00, 0xFF, 0x02, 0x05, 0x21}); err == nil {
+		t.Error("Expected error for truncated extended status")
+	}
+}
+
+// TestCIPPathSegments tests logical and symbolic segment encoding
+func TestCIPPathSegments(t *testing.T) {
+	cases := []struct {
+		got  []byte
+		want []byte
+	}{
+		{classInstancePath(CIPClassSymbol, 0), []byte{0x20, 0x6B, 0x24, 0x00}},
+		{classInstancePath(CIPClassTemplate, 0x1234), []byte{0x20, 0x6C, 0x25, 0x00, 0x34, 0x12}},
+		{classInstanceAttributePath(CIPClassIdentity, 1, 7), []byte{0x20, 0x01, 0x24, 0x01, 0x30, 0x07}},
+		{logicalSegment(0x24, 0x12345678), []byte{0x26, 0x00, 0x78, 0x56, 0x34, 0x12}},
+		{symbolicSegment("Tag"), []byte{0x91, 0x03, 'T', 'a', 'g', 0x00}},
+		{symbolicSegment("Tags"), []byte{0x91, 0x04, 'T', 'a', 'g', 's'}},
+	}
+	for i, c := range cases {
+		if !bytes.Equal(c.got, c.want) {
+			t.Errorf("Case %d: got % X, want % X", i, c.got, c.want)
+		}
+	}
+}
+
+// TestTagRequestPath tests encoding of tag names into request paths
+func TestTagRequestPath(t *testing.T) {
+	path, err := tagRequestPath("Program:Main.Recipe[2,300].Speed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []byte
+	want = append(want, symbolicSegment("Program:Main")...)
+	want = append(want, symbolicSegment("Recipe")...)
+	want = append(want, 0x28, 0x02, 0x29, 0x00, 0x2C, 0x01)
+	want = append(want, symbolicSegment("Speed")...)
+	if !bytes.Equal(path, want) {
+		t.Errorf("got % X, want % X", path, want)
+	}
+
+	for _, bad := range []string{"", "Tag.", "Tag[1", "Tag[x]", "Word.3"} {
+		if _, err := tagRequestPath(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+// TestMultipleServicePacket tests encoding requests and splitting replies
+func TestMultipleServicePacket(t *testing.T) {
+	data := buildMultipleServicePacket([][]byte{{0xAA, 0xBB}, {0xCC}})
+	want := []byte{0x02, 0x00, 0x06, 0x00, 0x08, 0x00, 0xAA, 0xBB, 0xCC}
+	if !bytes.Equal(data, want) {
+		t.Errorf("got % X, want % X", data, want)
+	}
+
+	reply := []byte{
+		0x02, 0x00, 0x06, 0x00, 0x0C, 0x00,
+		0xCC, 0x00, 0x00, 0x00, 0xC4, 0x00,
+		0xCC, 0x00, 0x05, 0x00,
+	}
+	replies, err := parseMultipleServiceReply(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replies) != 2 || replies[0].GeneralStatus != CIPStatusSuccess || replies[1].GeneralStatus != CIPStatusPathUnknown {
+		t.Errorf("unexpected replies: %+v", replies)
+	}
+	if !bytes.Equal(replies[0].Data, []byte{0xC4, 0x00}) {
+		t.Errorf("unexpected data: % X", replies[0].Data)
+	}
+
+	if _, err := parseMultipleServiceReply([]byte{0x02, 0x00, 0x06, 0x00}); err == nil {
+		t.Error("expected error for truncated offsets")
+	}
+}
@@ -0,0 +1,241 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/codec"
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// CIP elementary data type codes, as returned in Read Tag replies
+const (
+	CIPTypeBool   = codec.TypeBool
+	CIPTypeSint   = codec.TypeSint
+	CIPTypeInt    = codec.TypeInt
+	CIPTypeDint   = codec.TypeDint
+	CIPTypeLint   = codec.TypeLint
+	CIPTypeUsint  = codec.TypeUsint
+	CIPTypeUint   = codec.TypeUint
+	CIPTypeUdint  = codec.TypeUdint
+	CIPTypeUlint  = codec.TypeUlint
+	CIPTypeReal   = codec.TypeReal
+	CIPTypeLreal  = codec.TypeLreal
+	CIPTypeStruct = codec.TypeStruct // Followed by a 2-byte structure handle
+)
+
+// Logix STRING layout: structure handle, DINT length and 82 data bytes
+const (
+	logixStringHandle  uint16 = 0x0FCE
+	logixStringMaxData        = codec.StringDataSize
+)
+
+// cipTypeInfo maps a PlcDataType to its CIP type code and the size of its
+// value in a Read Tag reply (excluding the type word)
+func cipTypeInfo(dataType PlcDataType) (code uint16, size int, ok bool) {
+	switch dataType {
+	case Bool:
+		return CIPTypeBool, 1, true
+	case Sint:
+		return CIPTypeSint, 1, true
+	case Int:
+		return CIPTypeInt, 2, true
+	case Dint:
+		return CIPTypeDint, 4, true
+	case Lint:
+		return CIPTypeLint, 8, true
+	case Usint:
+		return CIPTypeUsint, 1, true
+	case Uint:
+		return CIPTypeUint, 2, true
+	case Udint:
+		return CIPTypeUdint, 4, true
+	case Ulint:
+		return CIPTypeUlint, 8, true
+	case Real:
+		return CIPTypeReal, 4, true
+	case Lreal:
+		return CIPTypeLreal, 8, true
+	case Dt, Ldt, Time:
+		// Logix time types are LINTs on the wire
+		return CIPTypeLint, 8, true
+	case String:
+		return CIPTypeStruct, 2 + 4 + logixStringMaxData, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// decodeTagValue decodes the data of a Read Tag reply ([type UINT][value])
+// into the Go type ReadValue uses for dataType
+func decodeTagValue(dataType PlcDataType, data []byte) (interface{}, error) {
+	code, size, ok := cipTypeInfo(dataType)
+	if !ok {
+		return nil, NewEipError(ErrInvalidDataType, fmt.Sprintf("unsupported data type %d", dataType))
+	}
+	if len(data) < 2 {
+		return nil, NewEipError(ErrInvalidValue, "Read Tag reply too short")
+	}
+	if got := binary.LittleEndian.Uint16(data); got != code {
+		return nil, NewEipErrorWithDetails(ErrInvalidDataType, "tag type does not match requested type",
+			map[string]interface{}{"expected_type": code, "actual_type": got})
+	}
+	value := data[2:]
+	if dataType == String {
+		// STRING replies may omit unused trailing data bytes
+		size = 2 + 4
+	}
+	if len(value) < size {
+		return nil, NewEipErrorWithDetails(ErrInvalidValue, "Read Tag reply truncated",
+			map[string]interface{}{"length": len(value), "expected": size})
+	}
+
+	if dataType != String {
+		return decodeElement(dataType, value), nil
+	}
+	if handle := binary.LittleEndian.Uint16(value); handle != logixStringHandle {
+		return nil, NewEipErrorWithDetails(ErrInvalidDataType, "structure is not a STRING",
+			map[string]interface{}{"structure_handle": handle})
+	}
+	text, err := codec.DecodeString(value[2:])
+	if err != nil {
+		return nil, NewEipError(ErrInvalidValue, fmt.Sprintf("invalid STRING: %v", err))
+	}
+	return text, nil
+}
+
+// atomicDataType maps a CIP elementary type code to its PlcDataType
+func atomicDataType(code uint16) (PlcDataType, bool) {
+	for _, dataType := range []PlcDataType{Bool, Sint, Int, Dint, Lint, Usint, Uint, Udint, Ulint, Real, Lreal} {
+		if c, _, _ := cipTypeInfo(dataType); c == code {
+			return dataType, true
+		}
+	}
+	return 0, false
+}
+
+// decodeElement decodes one atomic value. value must hold at least the size
+// reported by cipTypeInfo.
+func decodeElement(dataType PlcDataType, value []byte) interface{} {
+	r := codec.NewReader(value)
+	switch dataType {
+	case Bool:
+		return r.Bool()
+	case Sint:
+		return r.Int8()
+	case Int:
+		return r.Int16()
+	case Dint:
+		return r.Int32()
+	case Lint:
+		return r.Int64()
+	case Usint:
+		return r.Uint8()
+	case Uint:
+		return r.Uint16()
+	case Udint:
+		return r.Uint32()
+	case Ulint:
+		return r.Uint64()
+	case Real:
+		return float64(r.Float32())
+	case Dt, Ldt, Time:
+		return temporalValue(dataType, r.Int64())
+	default: // Lreal
+		return r.Float64()
+	}
+}
+
+// encodeElement encodes one atomic value for a Write Tag request. Any Go
+// integer or float is accepted as long as it fits the PLC type.
+func encodeElement(dataType PlcDataType, v interface{}) ([]byte, error) {
+	_, size, ok := cipTypeInfo(dataType)
+	if !ok || dataType == String {
+		return nil, NewEipError(ErrInvalidDataType, fmt.Sprintf("unsupported element type %s", dataType))
+	}
+	buf := make([]byte, size)
+
+	switch dataType {
+	case Dt, Ldt, Time:
+		lint, err := temporalLint(dataType, v)
+		if err != nil {
+			return nil, err
+		}
+		binary.LittleEndian.PutUint64(buf, uint64(lint))
+		return buf, nil
+	}
+
+	if dataType == Bool {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, NewEipError(ErrInvalidTagValue, fmt.Sprintf("expected bool, got %T", v))
+		}
+		if b {
+			buf[0] = 1
+		}
+		return buf, nil
+	}
+
+	f, ok := numericValue(v)
+	if !ok {
+		return nil, NewEipError(ErrInvalidTagValue, fmt.Sprintf("expected number for %s, got %T", dataType, v))
+	}
+	outOfRange := func(min, max float64) error {
+		if f != math.Trunc(f) || f < min || f > max {
+			return NewEipErrorWithDetails(ErrInvalidTagValue, fmt.Sprintf("value %v out of range for %s", v, dataType),
+				map[string]interface{}{"value": v, "type": dataType.String()})
+		}
+		return nil
+	}
+
+	var err error
+	switch dataType {
+	case Sint:
+		err = outOfRange(math.MinInt8, math.MaxInt8)
+		buf[0] = byte(int8(f))
+	case Int:
+		err = outOfRange(math.MinInt16, math.MaxInt16)
+		binary.LittleEndian.PutUint16(buf, uint16(int16(f)))
+	case Dint:
+		err = outOfRange(math.MinInt32, math.MaxInt32)
+		binary.LittleEndian.PutUint32(buf, uint32(int32(f)))
+	case Lint:
+		if i, isInt := v.(int64); isInt {
+			binary.LittleEndian.PutUint64(buf, uint64(i))
+			break
+		}
+		err = outOfRange(math.MinInt64, math.MaxInt64)
+		binary.LittleEndian.PutUint64(buf, uint64(int64(f)))
+	case Usint:
+		err = outOfRange(0, math.MaxUint8)
+		buf[0] = uint8(f)
+	case Uint:
+		err = outOfRange(0, math.MaxUint16)
+		binary.LittleEndian.PutUint16(buf, uint16(f))
+	case Udint:
+		err = outOfRange(0, math.MaxUint32)
+		binary.LittleEndian.PutUint32(buf, uint32(f))
+	case Ulint:
+		if u, isUint := v.(uint64); isUint {
+			binary.LittleEndian.PutUint64(buf, u)
+			break
+		}
+		err = outOfRange(0, math.MaxUint64)
+		binary.LittleEndian.PutUint64(buf, uint64(f))
+	case Real:
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(float32(f)))
+	case Lreal:
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(f))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// numericValue converts any Go integer or float to float64 (see
+// types.NumericValue)
+func numericValue(v interface{}) (float64, bool) {
+	return types.NumericValue(v)
+}
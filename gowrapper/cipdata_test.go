@@ -0,0 +1,41 @@
+package ethernetip
+
+import (
+	"testing"
+)
+
+// TestDecodeTagValue tests decoding of Read Tag reply data
+func TestDecodeTagValue(t *testing.T) {
+	str := []byte{0xA0, 0x02, 0xCE, 0x0F, 0x02, 0x00, 0x00, 0x00, 'o', 'k', 0x00, 0x00}
+	cases := []struct {
+		dataType PlcDataType
+		data     []byte
+		want     interface{}
+	}{
+		{Bool, []byte{0xC1, 0x00, 0xFF}, true},
+		{Sint, []byte{0xC2, 0x00, 0xFE}, int8(-2)},
+		{Int, []byte{0xC3, 0x00, 0x00, 0x80}, int16(-32768)},
+		{Dint, []byte{0xC4, 0x00, 0x2A, 0x00, 0x00, 0x00}, int32(42)},
+		{Udint, []byte{0xC8, 0x00, 0xFF, 0xFF, 0xFF, 0xFF}, uint32(0xFFFFFFFF)},
+		{Real, []byte{0xCA, 0x00, 0x00, 0x00, 0xC0, 0x3F}, 1.5},
+		{Lreal, []byte{0xCB, 0x00, 0, 0, 0, 0, 0, 0, 0x04, 0x40}, 2.5},
+		{String, str, "ok"},
+	}
+	for _, c := range cases {
+		got, err := decodeTagValue(c.dataType, c.data)
+		if err != nil {
+			t.Errorf("type %d: %v", c.dataType, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("type %d: got %v (%T), want %v (%T)", c.dataType, got, got, c.want, c.want)
+		}
+	}
+
+	if _, err := decodeTagValue(Dint, []byte{0xC3, 0x00, 0x01, 0x00}); err == nil {
+		t.Error("expected error for type mismatch")
+	}
+	if _, err := decodeTagValue(Dint, []byte{0xC4, 0x00, 0x01}); err == nil {
+		t.Error("expected error for truncated value")
+	}
+}
@@ -0,0 +1,80 @@
+package ethernetip
+
+import (
+	"context"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/subscribe"
+)
+
+// Clock is the source of time for keep-alive, retries, subscriptions and the
+// wait helpers. SystemClock is used unless one is set with SetClock; tests
+// set a FakeClock to drive timers without sleeping.
+type Clock = subscribe.Clock
+
+// ClockTimer is a single event created by a Clock, like time.Timer
+type ClockTimer = subscribe.ClockTimer
+
+// ClockTicker is a repeating event created by a Clock, like time.Ticker
+type ClockTicker = subscribe.ClockTicker
+
+// SystemClock is the real time of the time package
+var SystemClock = subscribe.SystemClock
+
+// FakeClock is a Clock that only moves when advanced, for deterministic tests
+// of time-driven behaviour (see subscribe.FakeClock)
+type FakeClock = subscribe.FakeClock
+
+// NewFakeClock returns a FakeClock set to start
+func NewFakeClock(start time.Time) *FakeClock {
+	return subscribe.NewFakeClock(start)
+}
+
+// clockOf returns the clock of v if it has one and SystemClock otherwise
+func clockOf(v interface{}) Clock {
+	return subscribe.ClockOf(v)
+}
+
+// sleep waits for d on clock
+func sleep(clock Clock, d time.Duration) {
+	subscribe.Sleep(clock, d)
+}
+
+// withTimeout is context.WithTimeout measured on clock
+func withTimeout(ctx context.Context, clock Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if clock == subscribe.SystemClock {
+		return context.WithTimeout(ctx, d)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	timer := clock.NewTimer(d)
+	go func() {
+		select {
+		case <-timer.C():
+			cancel()
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}()
+	return ctx, cancel
+}
+
+// Clock returns the client's clock
+func (c *EipClient) Clock() Clock {
+	if clock := c.clock.Load(); clock != nil {
+		return *clock
+	}
+	return SystemClock
+}
+
+// SetClock sets the clock used by the client's keep-alive, idle timeout,
+// retries, wait helpers and subscriptions; nil restores SystemClock. A
+// running keep-alive is restarted on the new clock.
+func (c *EipClient) SetClock(clock Clock) {
+	if clock == nil {
+		clock = SystemClock
+	}
+	c.clock.Store(&clock)
+	if c.keepAliveInterval > 0 {
+		c.SetKeepAliveInterval(c.keepAliveInterval)
+	}
+}
@@ -0,0 +1,63 @@
+package ethernetip
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestRetryBudgetFakeClock tests the budget's backoff schedule without waiting
+func TestRetryBudgetFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	start := clock.Now()
+	budget := RetryBudget{Budget: 10 * time.Second, InitialDelay: time.Second, MaxDelay: 4 * time.Second, Clock: clock}
+
+	var attempts []time.Duration
+	done := make(chan error)
+	go func() {
+		done <- budget.Do(context.Background(), func(context.Context) error {
+			attempts = append(attempts, clock.Now().Sub(start))
+			return NewEipError(ErrTimeout, "timeout")
+		})
+	}()
+	// The budget timer and a backoff timer are pending while waiting
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		clock.BlockUntil(2)
+		clock.Advance(delay)
+	}
+	if err := <-done; err == nil {
+		t.Fatal("Expected the last error")
+	}
+	// A fifth attempt would start at 11s, past the budget
+	want := []time.Duration{0, time.Second, 3 * time.Second, 7 * time.Second}
+	if len(attempts) != len(want) {
+		t.Fatalf("Expected attempts at %v, got %v", want, attempts)
+	}
+	for i := range want {
+		if attempts[i] != want[i] {
+			t.Errorf("Expected attempts at %v, got %v", want, attempts)
+		}
+	}
+}
+
+// TestKeepAliveFakeClock tests that the keep-alive runs on the client's clock
+func TestKeepAliveFakeClock(t *testing.T) {
+	client := &EipClient{}
+	client.session.Store(-3)
+	clock := NewFakeClock(time.Now())
+	client.SetClock(clock)
+	client.SetIdleTimeout(10 * time.Second)
+	client.SetKeepAliveInterval(30 * time.Second)
+	defer client.stopKeepAlive()
+
+	clock.BlockUntil(1)
+	clock.Advance(29 * time.Second)
+	if client.IsIdle() {
+		t.Fatal("Expected no check before the keep-alive interval")
+	}
+	clock.Advance(time.Second)
+	waitFor(t, client.IsIdle)
+	if client.IdleCloses() != 1 {
+		t.Errorf("Expected 1 idle close, got %d", client.IdleCloses())
+	}
+}
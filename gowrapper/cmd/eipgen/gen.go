@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/codec"
+)
+
+// atomic describes how an elementary type is declared and transferred in
+// generated code. decode and encode are format strings taking the byte slice
+// expression, and for encode the value expression.
+type atomic struct {
+	name   string // Logix type name used in the eip struct tag
+	goType string
+	size   int
+	decode string
+	encode string
+}
+
+var atomics = map[uint16]atomic{
+	codec.TypeSint:  {"SINT", "int8", 1, "int8(%s[0])", "%s[0] = byte(%s)"},
+	codec.TypeInt:   {"INT", "int16", 2, "int16(binary.LittleEndian.Uint16(%s))", "binary.LittleEndian.PutUint16(%s, uint16(%s))"},
+	codec.TypeDint:  {"DINT", "int32", 4, "int32(binary.LittleEndian.Uint32(%s))", "binary.LittleEndian.PutUint32(%s, uint32(%s))"},
+	codec.TypeLint:  {"LINT", "int64", 8, "int64(binary.LittleEndian.Uint64(%s))", "binary.LittleEndian.PutUint64(%s, uint64(%s))"},
+	codec.TypeUsint: {"USINT", "uint8", 1, "%s[0]", "%s[0] = %s"},
+	codec.TypeUint:  {"UINT", "uint16", 2, "binary.LittleEndian.Uint16(%s)", "binary.LittleEndian.PutUint16(%s, %s)"},
+	codec.TypeUdint: {"UDINT", "uint32", 4, "binary.LittleEndian.Uint32(%s)", "binary.LittleEndian.PutUint32(%s, %s)"},
+	codec.TypeUlint: {"ULINT", "uint64", 8, "binary.LittleEndian.Uint64(%s)", "binary.LittleEndian.PutUint64(%s, %s)"},
+	codec.TypeReal:  {"REAL", "float32", 4, "math.Float32frombits(binary.LittleEndian.Uint32(%s))", "binary.LittleEndian.PutUint32(%s, math.Float32bits(%s))"},
+	codec.TypeLreal: {"LREAL", "float64", 8, "math.Float64frombits(binary.LittleEndian.Uint64(%s))", "binary.LittleEndian.PutUint64(%s, math.Float64bits(%s))"},
+	// BOOL arrays in structures are stored as arrays of 32-bit words
+	0xD3: {"DWORD", "uint32", 4, "binary.LittleEndian.Uint32(%s)", "binary.LittleEndian.PutUint32(%s, %s)"},
+}
+
+// generator emits Go types for structure templates
+type generator struct {
+	pkg        string
+	templates  map[uint16]*ethernetip.StructTemplate
+	typeNames  map[uint16]string
+	pending    []uint16
+	emitted    map[uint16]bool
+	imports    map[string]bool
+	body       bytes.Buffer
+	decodeBody bytes.Buffer
+	encodeBody bytes.Buffer
+}
+
+// generate returns Go source declaring a struct type with decode and encode
+// functions for each named template and the structures nested in it. With no
+// names, every template except string types is generated.
+func generate(pkg string, templates []*ethernetip.StructTemplate, names []string) ([]byte, error) {
+	g := &generator{
+		pkg:       pkg,
+		templates: make(map[uint16]*ethernetip.StructTemplate),
+		typeNames: make(map[uint16]string),
+		emitted:   make(map[uint16]bool),
+		imports:   map[string]bool{"fmt": true},
+	}
+	used := map[string]bool{}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Instance < templates[j].Instance })
+	for _, t := range templates {
+		g.templates[t.Instance] = t
+		name := goName(t.Name)
+		for used[name] {
+			name += "_"
+		}
+		used[name] = true
+		g.typeNames[t.Instance] = name
+	}
+
+	if len(names) == 0 {
+		for _, t := range templates {
+			if _, isString := t.StringCapacity(); !isString {
+				g.pending = append(g.pending, t.Instance)
+			}
+		}
+	}
+	for _, name := range names {
+		t := g.lookup(name)
+		if t == nil {
+			return nil, fmt.Errorf("no structure type named %s", name)
+		}
+		if _, isString := t.StringCapacity(); isString {
+			return nil, fmt.Errorf("%s is a string type; its members are generated as Go strings", t.Name)
+		}
+		g.pending = append(g.pending, t.Instance)
+	}
+	if len(g.pending) == 0 {
+		return nil, fmt.Errorf("no structure types to generate")
+	}
+
+	for len(g.pending) > 0 {
+		instance := g.pending[0]
+		g.pending = g.pending[1:]
+		if g.emitted[instance] {
+			continue
+		}
+		g.emitted[instance] = true
+		if err := g.emit(g.templates[instance]); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by eipgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.pkg)
+	// Standard library imports first, then the module's own packages
+	var std, module []string
+	for path := range g.imports {
+		if strings.Contains(path, ".") {
+			module = append(module, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(module)
+	for i, group := range [][]string{std, module} {
+		if i > 0 && len(group) > 0 {
+			out.WriteString("\n")
+		}
+		for _, path := range group {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		}
+	}
+	out.WriteString(")\n")
+	out.Write(g.body.Bytes())
+	return format.Source(out.Bytes())
+}
+
+// lookup returns the template named name, matched ignoring case
+func (g *generator) lookup(name string) *ethernetip.StructTemplate {
+	for _, t := range g.templates {
+		if strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// emit writes the type, layout constants and functions of a template
+func (g *generator) emit(t *ethernetip.StructTemplate) error {
+	name := g.typeNames[t.Instance]
+	g.decodeBody.Reset()
+	g.encodeBody.Reset()
+	fields := &bytes.Buffer{}
+	needErr := false
+	fieldNames := map[string]bool{}
+
+	for _, m := range t.Members {
+		if strings.HasPrefix(m.Name, "ZZZZZZZZZZ") || strings.HasPrefix(m.Name, "__") {
+			continue
+		}
+		field := goName(m.Name)
+		for fieldNames[field] {
+			field += "_"
+		}
+		fieldNames[field] = true
+		count := 1
+		if m.IsArray() {
+			count = int(m.Info)
+		}
+		array := ""
+		if m.IsArray() {
+			array = fmt.Sprintf("[%d]", count)
+		}
+		offset := int(m.Offset)
+
+		if m.IsStructure() {
+			nested, ok := g.templates[m.TypeCode()]
+			if !ok {
+				return fmt.Errorf("%s.%s: template %d of the member is missing", t.Name, m.Name, m.TypeCode())
+			}
+			needErr = true
+			if capacity, isString := nested.StringCapacity(); isString {
+				fmt.Fprintf(fields, "\t%s %sstring `eip:\"%s,STRING\"`\n", field, array, m.Name)
+				g.imports["github.com/sergiogallegos/rust-ethernet-ip/gowrapper/codec"] = true
+				g.each(m.IsArray(), field, offset, nested.Size,
+					func(v, at string) string {
+						return fmt.Sprintf("if %s, err = codec.DecodeString(data[%s:%s+%d]); err != nil {\nreturn v, fmt.Errorf(\"%s: %%w\", err)\n}\n", v, at, at, nested.Size, m.Name)
+					},
+					func(v, at string) string {
+						return fmt.Sprintf("b, err := codec.EncodeString(%s, %d)\nif err != nil {\nreturn nil, fmt.Errorf(\"%s: %%w\", err)\n}\ncopy(data[%s:], b)\n", v, capacity, m.Name, at)
+					})
+				continue
+			}
+			nestedName := g.typeNames[nested.Instance]
+			g.pending = append(g.pending, nested.Instance)
+			fmt.Fprintf(fields, "\t%s %s%s `eip:\"%s\"`\n", field, array, nestedName, m.Name)
+			g.each(m.IsArray(), field, offset, nested.Size,
+				func(v, at string) string {
+					return fmt.Sprintf("if %s, err = Decode%s(data[%s:]); err != nil {\nreturn v, fmt.Errorf(\"%s: %%w\", err)\n}\n", v, nestedName, at, m.Name)
+				},
+				func(v, at string) string {
+					return fmt.Sprintf("b, err := Encode%s(%s)\nif err != nil {\nreturn nil, fmt.Errorf(\"%s: %%w\", err)\n}\ncopy(data[%s:], b)\n", nestedName, v, m.Name, at)
+				})
+			continue
+		}
+
+		if m.TypeCode() == codec.TypeBool {
+			g.imports["github.com/sergiogallegos/rust-ethernet-ip/gowrapper/codec"] = true
+			bit := int(m.Info)
+			if m.IsArray() {
+				bit = 0
+			}
+			fmt.Fprintf(fields, "\t%s %sbool `eip:\"%s,BOOL\"`\n", field, array, m.Name)
+			g.each(m.IsArray(), field, offset, 1,
+				func(v, at string) string { return fmt.Sprintf("%s = codec.Bit(data[%s:], %d)\n", v, at, bit) },
+				func(v, at string) string { return fmt.Sprintf("codec.SetBit(data[%s:], %d, %s)\n", at, bit, v) })
+			continue
+		}
+
+		a, ok := atomics[m.TypeCode()]
+		if !ok {
+			fmt.Fprintf(fields, "\t// %s has unsupported type 0x%02X and is left out\n", m.Name, m.TypeCode())
+			continue
+		}
+		g.imports["encoding/binary"] = true
+		if strings.Contains(a.decode, "math.") {
+			g.imports["math"] = true
+		}
+		fmt.Fprintf(fields, "\t%s %s%s `eip:\"%s,%s\"`\n", field, array, a.goType, m.Name, a.name)
+		g.each(m.IsArray(), field, offset, a.size,
+			func(v, at string) string {
+				return fmt.Sprintf("%s = %s\n", v, fmt.Sprintf(a.decode, "data["+at+":]"))
+			},
+			func(v, at string) string {
+				return fmt.Sprintf(a.encode, "data["+at+":]", v) + "\n"
+			})
+	}
+
+	fmt.Fprintf(&g.body, "\n// %s is the %s structure. Members that hold packed BOOLs are left out.\n", name, t.Name)
+	fmt.Fprintf(&g.body, "type %s struct {\n%s}\n", name, fields)
+	fmt.Fprintf(&g.body, "\n// %s layout\nconst (\n", t.Name)
+	fmt.Fprintf(&g.body, "\t// %sSize is the size of %s data in bytes\n\t%sSize = %d\n", name, t.Name, name, t.Size)
+	fmt.Fprintf(&g.body, "\t// %sHandle is the structure handle of %s in raw tag reads and writes\n\t%sHandle = 0x%04X\n)\n", name, t.Name, name, t.Handle)
+
+	fmt.Fprintf(&g.body, "\n// Decode%s decodes %s data, such as the bytes after the structure handle\n// returned by ReadRaw\n", name, t.Name)
+	fmt.Fprintf(&g.body, "func Decode%s(data []byte) (%s, error) {\nvar v %s\n", name, name, name)
+	fmt.Fprintf(&g.body, "if len(data) < %sSize {\nreturn v, fmt.Errorf(\"%s needs %%d bytes, got %%d\", %sSize, len(data))\n}\n", name, t.Name, name)
+	if needErr {
+		g.body.WriteString("var err error\n")
+	}
+	g.body.Write(g.decodeBody.Bytes())
+	g.body.WriteString("return v, nil\n}\n")
+
+	fmt.Fprintf(&g.body, "\n// Encode%s encodes v as %s data. Members that are left out are zero.\n", name, t.Name)
+	fmt.Fprintf(&g.body, "func Encode%s(v %s) ([]byte, error) {\ndata := make([]byte, %sSize)\n", name, name, name)
+	g.body.Write(g.encodeBody.Bytes())
+	g.body.WriteString("return data, nil\n}\n")
+	return nil
+}
+
+// each adds the decode and encode statements of a member, looping over the
+// elements of an array. decode and encode take the value expression and the
+// offset expression of one element.
+func (g *generator) each(array bool, field string, offset, size int, decode, encode func(v, at string) string) {
+	if !array {
+		at := fmt.Sprint(offset)
+		g.decodeBody.WriteString(decode("v."+field, at))
+		stmt := encode("v."+field, at)
+		if strings.Count(stmt, "\n") > 1 {
+			// Scope the temporaries of multi-statement encodings
+			stmt = "{\n" + stmt + "}\n"
+		}
+		g.encodeBody.WriteString(stmt)
+		return
+	}
+	at := fmt.Sprintf("%d+i*%d", offset, size)
+	fmt.Fprintf(&g.decodeBody, "for i := range v.%s {\n%s}\n", field, decode("v."+field+"[i]", at))
+	fmt.Fprintf(&g.encodeBody, "for i := range v.%s {\n%s}\n", field, encode("v."+field+"[i]", at))
+}
+
+// goName converts a Logix name to an exported Go identifier: words separated
+// by underscores are capitalized and joined, and all-caps words are lowered
+// after their first letter, so "MOTOR_DATA" becomes MotorData and "rpmSet"
+// becomes RpmSet
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if word == "" {
+			continue
+		}
+		runes := []rune(word)
+		if strings.ToUpper(word) == word {
+			runes = []rune(strings.ToLower(word))
+		}
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	if b.Len() == 0 || !unicode.IsLetter([]rune(b.String())[0]) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// testTemplates are a structure with packed BOOLs, atomic members, an array
+// of a nested structure and a custom string type
+func testTemplates() []*ethernetip.StructTemplate {
+	return []*ethernetip.StructTemplate{
+		{Instance: 0x100, Name: "RECIPE", Handle: 0x1234, Size: 52, Members: []ethernetip.TemplateMember{
+			{Name: "ZZZZZZZZZZRECIPE0", Type: 0xC2},
+			{Name: "Running", Type: 0xC1, Info: 0},
+			{Name: "Done", Type: 0xC1, Info: 1},
+			{Name: "Count", Type: 0xC4, Offset: 4},
+			{Name: "Setpoint", Type: 0xCA, Offset: 8},
+			{Name: "Steps", Type: 0x8000 | 1<<13 | 0x101, Info: 2, Offset: 12},
+			{Name: "BATCH_NAME", Type: 0x8000 | 0x102, Offset: 28},
+		}},
+		{Instance: 0x101, Name: "STEP", Handle: 0x5678, Size: 8, Members: []ethernetip.TemplateMember{
+			{Name: "Duration", Type: 0xC4},
+			{Name: "Speed", Type: 0xC3, Offset: 4},
+		}},
+		{Instance: 0x102, Name: "STRING20", Handle: 0x9ABC, Size: 24, Members: []ethernetip.TemplateMember{
+			{Name: "LEN", Type: 0xC4},
+			{Name: "DATA", Type: 1<<13 | 0xC2, Info: 20, Offset: 4},
+		}},
+	}
+}
+
+// TestGenerate compares the generated source with the golden file
+func TestGenerate(t *testing.T) {
+	source, err := generate("plc", testTemplates(), []string{"recipe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "recipe.golden")
+	if *update {
+		if err := os.WriteFile(golden, source, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(source, want) {
+		t.Errorf("Generated source differs from %s; run go test -update to accept it:\n%s", golden, source)
+	}
+
+	// Without names, every structure type except strings is generated
+	all, err := generate("plc", testTemplates(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(all), "type Step struct") || strings.Contains(string(all), "type String20 struct") {
+		t.Errorf("Unexpected types generated:\n%s", all)
+	}
+}
+
+// TestGenerateErrors tests unknown and string types
+func TestGenerateErrors(t *testing.T) {
+	if _, err := generate("plc", testTemplates(), []string{"MISSING"}); err == nil {
+		t.Error("Expected an error for an unknown type")
+	}
+	if _, err := generate("plc", testTemplates(), []string{"STRING20"}); err == nil {
+		t.Error("Expected an error for a string type")
+	}
+	if _, err := generate("plc", testTemplates()[:1], []string{"RECIPE"}); err == nil {
+		t.Error("Expected an error for a missing nested template")
+	}
+}
+
+// TestGoName tests converting Logix names to Go identifiers
+func TestGoName(t *testing.T) {
+	for name, want := range map[string]string{"MOTOR_DATA": "MotorData", "rpmSet": "RpmSet", "Speed": "Speed", "_1": "X1"} {
+		if got := goName(name); got != want {
+			t.Errorf("goName(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -16,14 +17,27 @@ func main() {
 	plcIP := "192.168.0.1:44818"
 	fmt.Printf("Connecting to PLC at %s...\n", plcIP)
 
-	// Try to connect with retry logic
-	client, err := ethernetip.ConnectWithRetry(plcIP, 3, time.Second)
+	// Try to connect, retrying for up to 5 seconds
+	client, err := ethernetip.ConnectWithBudget(context.Background(), plcIP, ethernetip.RetryBudget{Budget: 5 * time.Second})
 	if err != nil {
 		log.Fatalf("Failed to connect to PLC: %v", err)
 	}
 	defer client.Close()
 
 	fmt.Println("Connected successfully!")
+
+	if info, err := client.GetDeviceInfo(); err == nil {
+		fmt.Printf("Controller: %s\n", info)
+	}
+
+	// Run the startup self-test
+	report, err := client.SelfTest(context.Background(), ethernetip.SelfTestOptions{MetadataTag: "_IO_EM_DI00"})
+	for _, check := range report.Checks {
+		fmt.Printf("  %-12s %-8s %v %s%s\n", check.Name, check.Status, check.Duration, check.Detail, check.Error)
+	}
+	if err != nil {
+		log.Printf("Self-test failed: %v", err)
+	}
 	fmt.Println("\nTesting basic read/write operations...")
 
 	// Test writing a boolean value
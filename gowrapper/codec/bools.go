@@ -0,0 +1,51 @@
+package codec
+
+import "fmt"
+
+// Bit reports whether bit n of data is set. Bits are numbered from the least
+// significant bit of the first byte, which matches Logix bit addressing of
+// little-endian integers ("MyDint.5") and BOOL arrays.
+func Bit(data []byte, n int) bool {
+	if n < 0 || n/8 >= len(data) {
+		return false
+	}
+	return data[n/8]&(1<<(n%8)) != 0
+}
+
+// SetBit sets or clears bit n of data. It panics if n is out of range.
+func SetBit(data []byte, n int, v bool) {
+	if v {
+		data[n/8] |= 1 << (n % 8)
+	} else {
+		data[n/8] &^= 1 << (n % 8)
+	}
+}
+
+// BoolArraySize returns the encoded size of a Logix BOOL array of n elements.
+// BOOL arrays are stored as 32-bit words, so the size is a multiple of 4.
+func BoolArraySize(n int) int {
+	return (n + 31) / 32 * 4
+}
+
+// PackBools encodes values as a Logix BOOL array
+func PackBools(values []bool) []byte {
+	data := make([]byte, BoolArraySize(len(values)))
+	for i, v := range values {
+		if v {
+			SetBit(data, i, true)
+		}
+	}
+	return data
+}
+
+// UnpackBools decodes the first n elements of a Logix BOOL array
+func UnpackBools(data []byte, n int) ([]bool, error) {
+	if n < 0 || len(data)*8 < n {
+		return nil, fmt.Errorf("%d BOOLs need %d bytes, have %d: %w", n, (n+7)/8, len(data), ErrShortBuffer)
+	}
+	values := make([]bool, n)
+	for i := range values {
+		values[i] = Bit(data, i)
+	}
+	return values, nil
+}
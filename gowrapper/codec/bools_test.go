@@ -0,0 +1,32 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestPackBools tests Logix BOOL array packing
+func TestPackBools(t *testing.T) {
+	values := make([]bool, 33)
+	values[0], values[9], values[32] = true, true, true
+	data := PackBools(values)
+	if !bytes.Equal(data, []byte{0x01, 0x02, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}) {
+		t.Errorf("Unexpected packing % X", data)
+	}
+
+	unpacked, err := UnpackBools(data, 33)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range values {
+		if unpacked[i] != values[i] {
+			t.Errorf("Element %d: got %v", i, unpacked[i])
+		}
+	}
+	if _, err := UnpackBools(data, 65); err == nil {
+		t.Error("Expected error for short data")
+	}
+	if Bit(data, 100) {
+		t.Error("Expected out of range bit to be clear")
+	}
+}
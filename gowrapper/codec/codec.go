@@ -0,0 +1,51 @@
+// Package codec provides the byte-level building blocks used to encode and
+// decode CIP data: little-endian field readers and writers, Logix structure
+// layout and padding rules, BOOL packing and the Logix STRING body. It has no
+// dependency on the native library, so it can be used on its own to write
+// custom structure codecs or to parse Class 1 I/O assemblies.
+package codec
+
+import "errors"
+
+// CIP elementary data type codes
+const (
+	TypeBool   uint16 = 0xC1
+	TypeSint   uint16 = 0xC2
+	TypeInt    uint16 = 0xC3
+	TypeDint   uint16 = 0xC4
+	TypeLint   uint16 = 0xC5
+	TypeUsint  uint16 = 0xC6
+	TypeUint   uint16 = 0xC7
+	TypeUdint  uint16 = 0xC8
+	TypeUlint  uint16 = 0xC9
+	TypeReal   uint16 = 0xCA
+	TypeLreal  uint16 = 0xCB
+	TypeStruct uint16 = 0x02A0 // Followed by a 2-byte structure handle on the wire
+)
+
+// ErrShortBuffer is returned when data ends before a field
+var ErrShortBuffer = errors.New("codec: short buffer")
+
+// TypeSize returns the encoded size in bytes of an elementary type
+func TypeSize(code uint16) (int, bool) {
+	switch code {
+	case TypeBool, TypeSint, TypeUsint:
+		return 1, true
+	case TypeInt, TypeUint:
+		return 2, true
+	case TypeDint, TypeUdint, TypeReal:
+		return 4, true
+	case TypeLint, TypeUlint, TypeLreal:
+		return 8, true
+	default:
+		return 0, false
+	}
+}
+
+// Align rounds offset up to a multiple of alignment
+func Align(offset, alignment int) int {
+	if alignment <= 1 {
+		return offset
+	}
+	return (offset + alignment - 1) / alignment * alignment
+}
@@ -0,0 +1,120 @@
+package codec
+
+import "fmt"
+
+// Member describes a member of a Logix structure (UDT) in declaration order
+type Member struct {
+	Name string
+	// Type is an elementary type code, or TypeStruct for a nested structure
+	Type uint16
+	// Count is the number of array elements; 0 or 1 is a scalar
+	Count int
+	// Struct is the layout of a nested structure when Type is TypeStruct
+	Struct *Layout
+}
+
+// Field is a member placed in a structure
+type Field struct {
+	Member
+	// Offset is the byte offset of the member (of its host byte for BOOLs)
+	Offset int
+	// Bit is the bit within the host byte of a scalar BOOL, -1 otherwise
+	Bit int
+	// Size is the number of bytes the member occupies
+	Size int
+}
+
+// Layout is the memory layout of a Logix structure
+type Layout struct {
+	Fields []Field
+	// Size is the structure size including trailing padding
+	Size int
+	// Alignment is the alignment of the structure when nested or in an array
+	Alignment int
+
+	byName map[string]int
+}
+
+// NewLayout lays out members the way Logix controllers do:
+//   - each member is aligned to its natural size (INT 2, DINT/REAL 4,
+//     LINT/LREAL 8); nested structures and BOOL arrays to 4, or 8 when they
+//     contain 64-bit members
+//   - consecutive scalar BOOLs are packed into a hidden SINT, 8 per byte
+//   - BOOL arrays are stored as 32-bit words
+//   - the structure size is rounded up to its alignment, at least 4
+func NewLayout(members []Member) (*Layout, error) {
+	l := &Layout{Alignment: 4, byName: make(map[string]int, len(members))}
+	offset := 0
+	boolHost, boolBit := -1, 0
+	for _, m := range members {
+		if _, dup := l.byName[m.Name]; dup {
+			return nil, fmt.Errorf("codec: duplicate member %q", m.Name)
+		}
+		count := m.Count
+		if count < 1 {
+			count = 1
+		}
+
+		field := Field{Member: m, Bit: -1}
+		if m.Type == TypeBool && m.Count <= 1 {
+			// Scalar BOOLs share a host byte until it is full
+			if boolHost < 0 || boolBit == 8 {
+				boolHost, boolBit = offset, 0
+				offset++
+			}
+			field.Offset, field.Bit, field.Size = boolHost, boolBit, 1
+			boolBit++
+			l.addField(field)
+			continue
+		}
+		boolHost = -1
+
+		size, align, err := memberSize(m, count)
+		if err != nil {
+			return nil, err
+		}
+		offset = Align(offset, align)
+		field.Offset, field.Size = offset, size
+		offset += size
+		if align > l.Alignment {
+			l.Alignment = align
+		}
+		l.addField(field)
+	}
+	l.Size = Align(offset, l.Alignment)
+	return l, nil
+}
+
+// memberSize returns the size and alignment of a non-BOOL-scalar member
+func memberSize(m Member, count int) (size, align int, err error) {
+	switch m.Type {
+	case TypeBool:
+		return BoolArraySize(count), 4, nil
+	case TypeStruct:
+		if m.Struct == nil {
+			return 0, 0, fmt.Errorf("codec: structure member %q has no layout", m.Name)
+		}
+		return m.Struct.Size * count, m.Struct.Alignment, nil
+	default:
+		elem, ok := TypeSize(m.Type)
+		if !ok {
+			return 0, 0, fmt.Errorf("codec: member %q has unknown type 0x%04X", m.Name, m.Type)
+		}
+		return elem * count, elem, nil
+	}
+}
+
+// addField appends a field and indexes it by name
+func (l *Layout) addField(field Field) {
+	l.byName[field.Name] = len(l.Fields)
+	l.Fields = append(l.Fields, field)
+}
+
+// Field returns the member with the given name
+func (l *Layout) Field(name string) (Field, bool) {
+	i, ok := l.byName[name]
+	if !ok {
+		return Field{}, false
+	}
+	return l.Fields[i], true
+}
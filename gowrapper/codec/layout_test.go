@@ -0,0 +1,79 @@
+package codec
+
+import "testing"
+
+// TestLayoutPadding tests Logix alignment and BOOL packing rules
+func TestLayoutPadding(t *testing.T) {
+	layout, err := NewLayout([]Member{
+		{Name: "Enable", Type: TypeBool},
+		{Name: "Done", Type: TypeBool},
+		{Name: "Mode", Type: TypeSint},
+		{Name: "Speed", Type: TypeReal},
+		{Name: "Fault", Type: TypeBool},
+		{Name: "Total", Type: TypeLint},
+		{Name: "Flags", Type: TypeBool, Count: 40},
+		{Name: "Counts", Type: TypeInt, Count: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][2]int{
+		"Enable": {0, 0},
+		"Done":   {0, 1},
+		"Mode":   {1, -1},
+		"Speed":  {4, -1},
+		"Fault":  {8, 0},
+		"Total":  {16, -1},
+		"Flags":  {24, -1},
+		"Counts": {32, -1},
+	}
+	for name, w := range want {
+		f, ok := layout.Field(name)
+		if !ok || f.Offset != w[0] || f.Bit != w[1] {
+			t.Errorf("%s: got offset %d bit %d, want %d %d", name, f.Offset, f.Bit, w[0], w[1])
+		}
+	}
+	if f, _ := layout.Field("Flags"); f.Size != 8 {
+		t.Errorf("Expected 40 BOOLs in 8 bytes, got %d", f.Size)
+	}
+	if layout.Size != 40 || layout.Alignment != 8 {
+		t.Errorf("Expected size 40 aligned to 8, got %d/%d", layout.Size, layout.Alignment)
+	}
+}
+
+// TestLayoutNested tests nested structures and BOOL host overflow
+func TestLayoutNested(t *testing.T) {
+	var bits []Member
+	for _, name := range []string{"B0", "B1", "B2", "B3", "B4", "B5", "B6", "B7", "B8"} {
+		bits = append(bits, Member{Name: name, Type: TypeBool})
+	}
+	inner, err := NewLayout(bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := inner.Field("B8"); f.Offset != 1 || f.Bit != 0 {
+		t.Errorf("Expected the ninth BOOL in a second host byte, got %d/%d", f.Offset, f.Bit)
+	}
+	if inner.Size != 4 {
+		t.Errorf("Expected size rounded to 4, got %d", inner.Size)
+	}
+
+	outer, err := NewLayout([]Member{
+		{Name: "Id", Type: TypeSint},
+		{Name: "Bits", Type: TypeStruct, Count: 2, Struct: inner},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := outer.Field("Bits"); f.Offset != 4 || f.Size != 8 {
+		t.Errorf("Unexpected nested field %+v", f)
+	}
+
+	if _, err := NewLayout([]Member{{Name: "X", Type: TypeStruct}}); err == nil {
+		t.Error("Expected error for structure without layout")
+	}
+	if _, err := NewLayout([]Member{{Name: "X", Type: TypeDint}, {Name: "X", Type: TypeDint}}); err == nil {
+		t.Error("Expected error for duplicate member")
+	}
+}
@@ -0,0 +1,144 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Reader decodes little-endian fields from a byte slice. The first read past
+// the end of the data records an error wrapping ErrShortBuffer; later reads
+// return zero values, so a sequence of reads can be checked once with Err.
+type Reader struct {
+	data []byte
+	off  int
+	err  error
+}
+
+// NewReader creates a reader positioned at the start of data
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+// Err returns the first error encountered
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Offset returns the current position
+func (r *Reader) Offset() int {
+	return r.off
+}
+
+// Len returns the number of unread bytes
+func (r *Reader) Len() int {
+	return len(r.data) - r.off
+}
+
+// next returns the next n bytes, or nil after recording an error
+func (r *Reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.Len() < n {
+		r.err = fmt.Errorf("need %d bytes at offset %d, have %d: %w", n, r.off, r.Len(), ErrShortBuffer)
+		return nil
+	}
+	b := r.data[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+// Seek moves to an absolute offset, e.g. the offset of a structure member
+func (r *Reader) Seek(offset int) {
+	if r.err != nil {
+		return
+	}
+	if offset < 0 || offset > len(r.data) {
+		r.err = fmt.Errorf("seek to %d beyond %d bytes: %w", offset, len(r.data), ErrShortBuffer)
+		return
+	}
+	r.off = offset
+}
+
+// Skip skips n bytes
+func (r *Reader) Skip(n int) {
+	r.next(n)
+}
+
+// Align skips padding up to the next multiple of alignment
+func (r *Reader) Align(alignment int) {
+	r.next(Align(r.off, alignment) - r.off)
+}
+
+// Bytes returns the next n bytes. The slice aliases the reader's data.
+func (r *Reader) Bytes(n int) []byte {
+	return r.next(n)
+}
+
+// Bool reads a one-byte BOOL
+func (r *Reader) Bool() bool {
+	return r.Uint8() != 0
+}
+
+// Uint8 reads a USINT
+func (r *Reader) Uint8() uint8 {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// Int8 reads a SINT
+func (r *Reader) Int8() int8 {
+	return int8(r.Uint8())
+}
+
+// Uint16 reads a UINT
+func (r *Reader) Uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+// Int16 reads an INT
+func (r *Reader) Int16() int16 {
+	return int16(r.Uint16())
+}
+
+// Uint32 reads a UDINT
+func (r *Reader) Uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+// Int32 reads a DINT
+func (r *Reader) Int32() int32 {
+	return int32(r.Uint32())
+}
+
+// Uint64 reads a ULINT
+func (r *Reader) Uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+// Int64 reads a LINT
+func (r *Reader) Int64() int64 {
+	return int64(r.Uint64())
+}
+
+// Float32 reads a REAL
+func (r *Reader) Float32() float32 {
+	return math.Float32frombits(r.Uint32())
+}
+
+// Float64 reads an LREAL
+func (r *Reader) Float64() float64 {
+	return math.Float64frombits(r.Uint64())
+}
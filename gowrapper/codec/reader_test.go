@@ -0,0 +1,68 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestWriterReaderRoundTrip tests that every field type survives a round trip
+func TestWriterReaderRoundTrip(t *testing.T) {
+	var w Writer
+	w.PutBool(true)
+	w.PutInt8(-2)
+	w.Align(4)
+	w.PutInt16(-300)
+	w.PutInt32(-70000)
+	w.PutUint32(0xDEADBEEF)
+	w.PutFloat32(1.5)
+	w.PutInt64(-1 << 40)
+	w.PutFloat64(2.25)
+	w.PutBytes([]byte{7, 8})
+
+	if w.Len() != 36 {
+		t.Fatalf("Expected 36 bytes, got %d", w.Len())
+	}
+	if !bytes.Equal(w.Bytes()[:4], []byte{0x01, 0xFE, 0x00, 0x00}) {
+		t.Errorf("Unexpected encoding % X", w.Bytes()[:4])
+	}
+
+	r := NewReader(w.Bytes())
+	if !r.Bool() || r.Int8() != -2 {
+		t.Error("Unexpected BOOL/SINT")
+	}
+	r.Align(4)
+	if r.Int16() != -300 || r.Int32() != -70000 || r.Uint32() != 0xDEADBEEF || r.Float32() != 1.5 {
+		t.Error("Unexpected INT/DINT/UDINT/REAL")
+	}
+	if r.Int64() != -1<<40 || r.Float64() != 2.25 {
+		t.Error("Unexpected LINT/LREAL")
+	}
+	if !bytes.Equal(r.Bytes(2), []byte{7, 8}) || r.Len() != 0 || r.Err() != nil {
+		t.Errorf("Unexpected tail, err %v", r.Err())
+	}
+}
+
+// TestReaderShortBuffer tests that errors are sticky
+func TestReaderShortBuffer(t *testing.T) {
+	r := NewReader([]byte{1, 2, 3})
+	if r.Uint32() != 0 {
+		t.Error("Expected zero value past the end")
+	}
+	if r.Uint8() != 0 {
+		t.Error("Expected reads after an error to return zero")
+	}
+	if !errors.Is(r.Err(), ErrShortBuffer) {
+		t.Errorf("Expected ErrShortBuffer, got %v", r.Err())
+	}
+
+	r = NewReader([]byte{1, 2, 3, 4})
+	r.Seek(2)
+	if r.Uint16() != 0x0403 || r.Err() != nil {
+		t.Errorf("Unexpected read after seek, err %v", r.Err())
+	}
+	r.Seek(5)
+	if r.Err() == nil {
+		t.Error("Expected error seeking past the end")
+	}
+}
@@ -0,0 +1,39 @@
+package codec
+
+import "fmt"
+
+// StringDataSize is the DATA capacity of the predefined Logix STRING type
+const StringDataSize = 82
+
+// StringSize returns the size of a Logix string structure with the given data
+// capacity: a DINT length followed by the data array, padded to 4 bytes
+func StringSize(capacity int) int {
+	return Align(4+capacity, 4)
+}
+
+// EncodeString encodes s as the body of a Logix string structure (LEN and
+// DATA) with the given data capacity, zero-filling unused bytes
+func EncodeString(s string, capacity int) ([]byte, error) {
+	if len(s) > capacity {
+		return nil, fmt.Errorf("codec: string of %d bytes exceeds capacity %d", len(s), capacity)
+	}
+	w := NewWriter(StringSize(capacity))
+	w.PutInt32(int32(len(s)))
+	w.PutBytes([]byte(s))
+	w.Pad(StringSize(capacity) - w.Len())
+	return w.Bytes(), nil
+}
+
+// DecodeString decodes the body of a Logix string structure. Trailing DATA
+// bytes beyond LEN may be omitted from data.
+func DecodeString(data []byte) (string, error) {
+	r := NewReader(data)
+	n := r.Int32()
+	if err := r.Err(); err != nil {
+		return "", err
+	}
+	if n < 0 || int(n) > r.Len() {
+		return "", fmt.Errorf("codec: string length %d out of range (%d bytes of data)", n, r.Len())
+	}
+	return string(r.Bytes(int(n))), nil
+}
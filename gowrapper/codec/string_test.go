@@ -0,0 +1,27 @@
+package codec
+
+import "testing"
+
+// TestStringRoundTrip tests Logix string bodies
+func TestStringRoundTrip(t *testing.T) {
+	data, err := EncodeString("Hello", StringDataSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 88 {
+		t.Errorf("Expected 88 bytes, got %d", len(data))
+	}
+	s, err := DecodeString(data)
+	if err != nil || s != "Hello" {
+		t.Errorf("Got %q, %v", s, err)
+	}
+	if s, err := DecodeString(data[:9]); err != nil || s != "Hello" {
+		t.Errorf("Expected trailing data to be optional, got %q, %v", s, err)
+	}
+	if _, err := EncodeString("too long", 4); err == nil {
+		t.Error("Expected capacity error")
+	}
+	if _, err := DecodeString([]byte{10, 0, 0, 0, 'a'}); err == nil {
+		t.Error("Expected length error")
+	}
+}
@@ -0,0 +1,80 @@
+package codec
+
+import "time"
+
+// Logix time types are stored as LINTs:
+//   - DT (date and time): microseconds since 1970-01-01 00:00:00 UTC
+//   - LDT (long date and time): nanoseconds since 1970-01-01 00:00:00 UTC
+//   - TIME (duration): microseconds
+//
+// DT values span far more than time.Duration, so DT is converted with
+// time.UnixMicro rather than by scaling a duration.
+
+// DateTime converts a DT value to a UTC time
+func DateTime(us int64) time.Time {
+	return time.UnixMicro(us).UTC()
+}
+
+// DateTimeValue converts t to a DT value, truncating to the microsecond
+func DateTimeValue(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+// LongDateTime converts an LDT value to a UTC time
+func LongDateTime(ns int64) time.Time {
+	return time.Unix(0, ns).UTC()
+}
+
+// LongDateTimeValue converts t to an LDT value. Times outside the years
+// 1678 to 2262 do not fit and saturate like time.Time.UnixNano.
+func LongDateTimeValue(t time.Time) int64 {
+	return t.UnixNano()
+}
+
+// Duration converts a TIME value to a duration. Values beyond about 292
+// years saturate at the duration limits.
+func Duration(us int64) time.Duration {
+	const limit = int64(1<<63-1) / int64(time.Microsecond)
+	switch {
+	case us > limit:
+		return time.Duration(1<<63 - 1)
+	case us < -limit:
+		return time.Duration(-1 << 63)
+	}
+	return time.Duration(us) * time.Microsecond
+}
+
+// DurationValue converts d to a TIME value, truncating to the microsecond
+func DurationValue(d time.Duration) int64 {
+	return d.Microseconds()
+}
+
+// DateTime reads a DT
+func (r *Reader) DateTime() time.Time {
+	return DateTime(r.Int64())
+}
+
+// LongDateTime reads an LDT
+func (r *Reader) LongDateTime() time.Time {
+	return LongDateTime(r.Int64())
+}
+
+// Duration reads a TIME
+func (r *Reader) Duration() time.Duration {
+	return Duration(r.Int64())
+}
+
+// PutDateTime writes a DT
+func (w *Writer) PutDateTime(t time.Time) {
+	w.PutInt64(DateTimeValue(t))
+}
+
+// PutLongDateTime writes an LDT
+func (w *Writer) PutLongDateTime(t time.Time) {
+	w.PutInt64(LongDateTimeValue(t))
+}
+
+// PutDuration writes a TIME
+func (w *Writer) PutDuration(d time.Duration) {
+	w.PutInt64(DurationValue(d))
+}
@@ -0,0 +1,57 @@
+package codec
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestTimeConversions tests DT, LDT and TIME conversions
+func TestTimeConversions(t *testing.T) {
+	when := time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.UTC)
+	if got := DateTime(DateTimeValue(when)); !got.Equal(when.Truncate(time.Microsecond)) {
+		t.Errorf("DT round trip: got %v", got)
+	}
+	if got := LongDateTime(LongDateTimeValue(when)); !got.Equal(when) {
+		t.Errorf("LDT round trip: got %v", got)
+	}
+	if DateTime(0) != time.Unix(0, 0).UTC() {
+		t.Error("Expected DT 0 to be the Unix epoch")
+	}
+	// DT covers dates LDT cannot
+	far := time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !DateTime(DateTimeValue(far)).Equal(far) {
+		t.Error("Expected DT to represent the year 3000")
+	}
+
+	if Duration(1500000) != 1500*time.Millisecond || DurationValue(90*time.Second) != 90000000 {
+		t.Error("Unexpected TIME conversion")
+	}
+	if Duration(math.MaxInt64) != time.Duration(math.MaxInt64) || Duration(math.MinInt64) != time.Duration(math.MinInt64) {
+		t.Error("Expected TIME values beyond time.Duration to saturate")
+	}
+}
+
+// TestTimeFields tests reading and writing time fields in a structure
+func TestTimeFields(t *testing.T) {
+	when := time.Date(2024, 3, 1, 12, 30, 0, 5000, time.UTC)
+	w := NewWriter(24)
+	w.PutDateTime(when)
+	w.PutLongDateTime(when)
+	w.PutDuration(time.Minute)
+
+	r := NewReader(w.Bytes())
+	if got := r.DateTime(); !got.Equal(when) {
+		t.Errorf("DT: got %v", got)
+	}
+	if got := r.LongDateTime(); !got.Equal(when) {
+		t.Errorf("LDT: got %v", got)
+	}
+	if got := r.Duration(); got != time.Minute {
+		t.Errorf("TIME: got %v", got)
+	}
+	r.Duration()
+	if r.Err() == nil {
+		t.Error("Expected error reading past the end")
+	}
+}
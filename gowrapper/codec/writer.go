@@ -0,0 +1,103 @@
+package codec
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Writer encodes little-endian fields into a growing byte slice. The zero
+// value is an empty writer ready for use.
+type Writer struct {
+	buf []byte
+}
+
+// NewWriter creates a writer with room for size bytes
+func NewWriter(size int) *Writer {
+	return &Writer{buf: make([]byte, 0, size)}
+}
+
+// Bytes returns the encoded data
+func (w *Writer) Bytes() []byte {
+	return w.buf
+}
+
+// Len returns the number of bytes written
+func (w *Writer) Len() int {
+	return len(w.buf)
+}
+
+// Pad writes n zero bytes
+func (w *Writer) Pad(n int) {
+	for i := 0; i < n; i++ {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+// Align pads with zeros up to the next multiple of alignment
+func (w *Writer) Align(alignment int) {
+	w.Pad(Align(len(w.buf), alignment) - len(w.buf))
+}
+
+// PutBytes writes b unchanged
+func (w *Writer) PutBytes(b []byte) {
+	w.buf = append(w.buf, b...)
+}
+
+// PutBool writes a one-byte BOOL
+func (w *Writer) PutBool(v bool) {
+	if v {
+		w.PutUint8(1)
+	} else {
+		w.PutUint8(0)
+	}
+}
+
+// PutUint8 writes a USINT
+func (w *Writer) PutUint8(v uint8) {
+	w.buf = append(w.buf, v)
+}
+
+// PutInt8 writes a SINT
+func (w *Writer) PutInt8(v int8) {
+	w.PutUint8(uint8(v))
+}
+
+// PutUint16 writes a UINT
+func (w *Writer) PutUint16(v uint16) {
+	w.buf = binary.LittleEndian.AppendUint16(w.buf, v)
+}
+
+// PutInt16 writes an INT
+func (w *Writer) PutInt16(v int16) {
+	w.PutUint16(uint16(v))
+}
+
+// PutUint32 writes a UDINT
+func (w *Writer) PutUint32(v uint32) {
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, v)
+}
+
+// PutInt32 writes a DINT
+func (w *Writer) PutInt32(v int32) {
+	w.PutUint32(uint32(v))
+}
+
+// PutUint64 writes a ULINT
+func (w *Writer) PutUint64(v uint64) {
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, v)
+}
+
+// PutInt64 writes a LINT
+func (w *Writer) PutInt64(v int64) {
+	w.PutUint64(uint64(v))
+}
+
+// PutFloat32 writes a REAL
+func (w *Writer) PutFloat32(v float32) {
+	w.PutUint32(math.Float32bits(v))
+}
+
+// PutFloat64 writes an LREAL
+func (w *Writer) PutFloat64(v float64) {
+	w.PutUint64(math.Float64bits(v))
+}
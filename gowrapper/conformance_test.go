@@ -0,0 +1,218 @@
+package ethernetip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/codec"
+)
+
+// goldenCase is a value and the bytes the controller stores for it. These
+// cases codify the wrapper's type fidelity: every value must be written and
+// read back byte for byte, and decode to the same Go type and value.
+type goldenCase struct {
+	dataType PlcDataType
+	value    interface{}
+	wire     []byte // Value bytes of a Read Tag reply, after the type code
+}
+
+// wire parses space-separated hex bytes
+func wire(s string) []byte {
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// stringWire returns the bytes of a STRING holding s: the structure handle,
+// LEN and the used DATA bytes
+func stringWire(s string) []byte {
+	data := binary.LittleEndian.AppendUint16(nil, logixStringHandle)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(s)))
+	return append(data, s...)
+}
+
+// goldenCases are the boundary values of every supported type
+var goldenCases = []goldenCase{
+	{Bool, false, wire("00")},
+	{Bool, true, wire("01")},
+
+	{Sint, int8(math.MinInt8), wire("80")},
+	{Sint, int8(-1), wire("ff")},
+	{Sint, int8(math.MaxInt8), wire("7f")},
+	{Int, int16(math.MinInt16), wire("00 80")},
+	{Int, int16(math.MaxInt16), wire("ff 7f")},
+	{Dint, int32(math.MinInt32), wire("00 00 00 80")},
+	{Dint, int32(-2), wire("fe ff ff ff")},
+	{Dint, int32(math.MaxInt32), wire("ff ff ff 7f")},
+	{Lint, int64(math.MinInt64), wire("00 00 00 00 00 00 00 80")},
+	{Lint, int64(math.MaxInt64), wire("ff ff ff ff ff ff ff 7f")},
+	{Lint, int64(1<<53 + 1), wire("01 00 00 00 00 00 20 00")},
+
+	{Usint, uint8(0), wire("00")},
+	{Usint, uint8(math.MaxUint8), wire("ff")},
+	{Uint, uint16(math.MaxUint16), wire("ff ff")},
+	{Udint, uint32(math.MaxUint32), wire("ff ff ff ff")},
+	{Ulint, uint64(math.MaxUint64), wire("ff ff ff ff ff ff ff ff")},
+	{Ulint, uint64(1<<63 + 1), wire("01 00 00 00 00 00 00 80")},
+
+	// REAL values are float64 in Go, rounded to single precision
+	{Real, float64(float32(3.14)), wire("c3 f5 48 40")},
+	{Real, float64(math.MaxFloat32), wire("ff ff 7f 7f")},
+	{Real, float64(-math.MaxFloat32), wire("ff ff 7f ff")},
+	{Real, float64(math.SmallestNonzeroFloat32), wire("01 00 00 00")},
+	{Real, math.Copysign(0, -1), wire("00 00 00 80")},
+	{Real, math.Inf(1), wire("00 00 80 7f")},
+	{Real, math.Inf(-1), wire("00 00 80 ff")},
+	{Real, math.NaN(), wire("00 00 c0 7f")},
+	{Lreal, math.Pi, wire("18 2d 44 54 fb 21 09 40")},
+	{Lreal, math.MaxFloat64, wire("ff ff ff ff ff ff ef 7f")},
+	{Lreal, math.SmallestNonzeroFloat64, wire("01 00 00 00 00 00 00 00")},
+	{Lreal, math.Inf(-1), wire("00 00 00 00 00 00 f0 ff")},
+	{Lreal, math.NaN(), wire("01 00 00 00 00 00 f8 7f")},
+
+	// Logix time types are LINTs: microseconds for DT and TIME,
+	// nanoseconds for LDT
+	{Dt, time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC), wire("46 d3 4d c0 ed 0d 06 00")},
+	{Ldt, time.Date(1970, 1, 1, 0, 0, 0, 1, time.UTC), wire("01 00 00 00 00 00 00 00")},
+	{Time, 1500 * time.Millisecond, wire("60 e3 16 00 00 00 00 00")},
+	{Time, -time.Microsecond, wire("ff ff ff ff ff ff ff ff")},
+
+	{String, "", stringWire("")},
+	{String, "Hello, PLC", stringWire("Hello, PLC")},
+	{String, "\x00\x01\x7f\xff", stringWire("\x00\x01\x7f\xff")},
+	{String, strings.Repeat("x", logixStringMaxData), stringWire(strings.Repeat("x", logixStringMaxData))},
+}
+
+// sameValue reports whether a decoded value has the Go type and value of
+// want. NaNs match NaNs of the same width; -0 and +0 do not match.
+func sameValue(dataType PlcDataType, want, got interface{}) bool {
+	switch dataType {
+	case Dt, Ldt, Time:
+		return ValuesMatch(dataType, want, got, 0)
+	case Real, Lreal:
+		g, ok := got.(float64)
+		w := want.(float64)
+		if !ok || math.IsNaN(w) || math.IsNaN(g) {
+			return ok && math.IsNaN(w) && math.IsNaN(g)
+		}
+		if dataType == Real {
+			return math.Float32bits(float32(w)) == math.Float32bits(float32(g))
+		}
+		return math.Float64bits(w) == math.Float64bits(g)
+	}
+	return reflect.TypeOf(want) == reflect.TypeOf(got) && reflect.DeepEqual(want, got)
+}
+
+// TestGoldenEncoding tests that every golden value encodes to its bytes and
+// decodes back to the same Go type and value
+func TestGoldenEncoding(t *testing.T) {
+	for _, tc := range goldenCases {
+		code, _, _ := cipTypeInfo(tc.dataType)
+		reply := append(binary.LittleEndian.AppendUint16(nil, code), tc.wire...)
+
+		if tc.dataType == String {
+			body, err := codec.EncodeString(tc.value.(string), logixStringMaxData)
+			if err != nil || !bytes.HasPrefix(body, tc.wire[2:]) || bytes.Count(body[len(tc.wire)-2:], []byte{0}) != len(body)-len(tc.wire)+2 {
+				t.Errorf("%s %q: encoded %x (%v), expected %x zero-filled", tc.dataType, tc.value, body, err, tc.wire[2:])
+			}
+		} else if data, err := encodeElement(tc.dataType, tc.value); err != nil || !bytes.Equal(data, tc.wire) {
+			t.Errorf("%s %v: encoded %x (%v), expected %x", tc.dataType, tc.value, data, err, tc.wire)
+		}
+
+		got, err := decodeTagValue(tc.dataType, reply)
+		if err != nil || !sameValue(tc.dataType, tc.value, got) {
+			t.Errorf("%s %x: decoded %#v (%v), expected %#v", tc.dataType, tc.wire, got, err, tc.value)
+		}
+	}
+
+	// Values that do not fit are rejected, not truncated
+	for _, tc := range []struct {
+		dataType PlcDataType
+		value    interface{}
+	}{
+		{Sint, 128}, {Int, -32769}, {Dint, int64(math.MaxInt32 + 1)}, {Usint, -1}, {Udint, 1.5}, {Ulint, -1.0},
+	} {
+		if data, err := encodeElement(tc.dataType, tc.value); err == nil {
+			t.Errorf("%s %v: expected an error, encoded %x", tc.dataType, tc.value, data)
+		}
+	}
+	if _, err := codec.EncodeString(strings.Repeat("x", logixStringMaxData+1), logixStringMaxData); err == nil {
+		t.Error("Expected a string longer than STRING's DATA to be rejected")
+	}
+}
+
+// goldenTags names the test PLC's tag for each type. DT, LDT and TIME values
+// are stored in a LINT.
+var goldenTags = map[PlcDataType]string{
+	Bool: "TestBool", Sint: "TestSint", Int: "TestInt", Dint: "TestDint", Lint: "TestLint",
+	Usint: "TestUsint", Uint: "TestUint", Udint: "TestUdint", Ulint: "TestUlint",
+	Real: "TestReal", Lreal: "TestLreal", Dt: "TestLint", Ldt: "TestLint", Time: "TestLint",
+	String: "TestString",
+}
+
+// TestGoldenRoundTrip writes every golden value to the PLC, or a simulator,
+// at TEST_PLC_IP and checks that it is stored byte for byte and reads back
+// as the same value. Types whose test tag does not exist are skipped.
+func TestGoldenRoundTrip(t *testing.T) {
+	skipIfNoPlc(t)
+	client, err := NewClient(getTestPlcIP())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	available := make(map[string]bool)
+	for _, tagName := range goldenTags {
+		if _, _, err := client.ReadRaw(tagName); err == nil {
+			available[tagName] = true
+		}
+	}
+
+	for _, tc := range goldenCases {
+		tagName := goldenTags[tc.dataType]
+		t.Run(tc.dataType.String()+"/"+hex.EncodeToString(tc.wire), func(t *testing.T) {
+			if !available[tagName] {
+				t.Skipf("%s not available", tagName)
+			}
+			if err := client.WriteValue(tagName, &PlcValue{Type: tc.dataType, Value: tc.value}); err != nil {
+				t.Fatalf("Failed to write %v: %v", tc.value, err)
+			}
+
+			data, code, err := client.ReadRaw(tagName)
+			if err != nil {
+				t.Fatalf("Failed to read back: %v", err)
+			}
+			wantCode, _, _ := cipTypeInfo(tc.dataType)
+			if tc.dataType == String {
+				// DATA bytes past LEN are not part of the value
+				data = data[:min(len(data), len(tc.wire))]
+			}
+			if code != wantCode || !bytes.Equal(data, tc.wire) {
+				t.Errorf("Stored type 0x%04X %x, expected 0x%04X %x", code, data, wantCode, tc.wire)
+			}
+
+			read, err := client.ReadValue(tagName, tc.dataType)
+			if err != nil {
+				t.Fatalf("Failed to read value: %v", err)
+			}
+			if !sameValue(tc.dataType, tc.value, read.Value) {
+				t.Errorf("Read %#v, expected %#v", read.Value, tc.value)
+			}
+		})
+	}
+
+	if available["TestString"] {
+		tooLong := strings.Repeat("y", logixStringMaxData+1)
+		if err := client.WriteValue("TestString", &PlcValue{Type: String, Value: tooLong}); err == nil {
+			t.Error("Expected a string longer than STRING's DATA to be rejected")
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package ethernetip
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultConnectionQueueTimeout is how long opening a session waits for a
+// free connection when a controller's budget is used up
+const DefaultConnectionQueueTimeout = 30 * time.Second
+
+// ConnectionBudget counts the connections this process holds to each
+// controller and can cap them, so that many clients, standby sessions and
+// tools in one process do not exhaust a controller's connection table.
+// Sessions opened beyond the cap queue until one is closed.
+type ConnectionBudget struct {
+	mu           sync.Mutex
+	defaultLimit int
+	timeout      time.Duration
+	controllers  map[string]*controllerBudget
+}
+
+// controllerBudget is the budget of one controller. Waiters are served in
+// order: Release hands a freed connection to the first one.
+type controllerBudget struct {
+	limit   int // 0 means the default limit
+	held    int
+	waiters []chan struct{}
+}
+
+// ConnectionUsage is the budget of one controller
+type ConnectionUsage struct {
+	Address string `json:"address"`
+	Held    int    `json:"held"`
+	// Limit is 0 when the connections are not capped
+	Limit   int `json:"limit"`
+	Waiting int `json:"waiting"`
+}
+
+// DefaultConnectionBudget is the budget every client's sessions draw from
+var DefaultConnectionBudget = NewConnectionBudget()
+
+// NewConnectionBudget creates a budget without limits
+func NewConnectionBudget() *ConnectionBudget {
+	return &ConnectionBudget{
+		timeout:     DefaultConnectionQueueTimeout,
+		controllers: make(map[string]*controllerBudget),
+	}
+}
+
+// budgetKey identifies a controller by host, so "10.0.0.5" and
+// "10.0.0.5:44818" share a budget
+func budgetKey(address string) string {
+	address = strings.ToLower(strings.TrimSpace(address))
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return address
+}
+
+// controller returns the budget of address. Must be called with b.mu held.
+func (b *ConnectionBudget) controller(address string) *controllerBudget {
+	key := budgetKey(address)
+	cb := b.controllers[key]
+	if cb == nil {
+		cb = &controllerBudget{}
+		b.controllers[key] = cb
+	}
+	return cb
+}
+
+// limitOf returns the effective limit of cb. Must be called with b.mu held.
+func (b *ConnectionBudget) limitOf(cb *controllerBudget) int {
+	if cb.limit > 0 {
+		return cb.limit
+	}
+	return b.defaultLimit
+}
+
+// SetLimit caps the connections to the controller at address; 0 falls back
+// to the default limit. Raising a limit admits queued sessions.
+func (b *ConnectionBudget) SetLimit(address string, limit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cb := b.controller(address)
+	cb.limit = max(limit, 0)
+	b.admit(cb)
+}
+
+// SetDefaultLimit caps the connections to controllers without their own
+// limit; 0 means unlimited
+func (b *ConnectionBudget) SetDefaultLimit(limit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.defaultLimit = max(limit, 0)
+	for _, cb := range b.controllers {
+		b.admit(cb)
+	}
+}
+
+// SetQueueTimeout sets how long Acquire waits for a free connection; 0 fails
+// at once when the budget is used up
+func (b *ConnectionBudget) SetQueueTimeout(timeout time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.timeout = max(timeout, 0)
+}
+
+// admit hands free connections to waiters. Must be called with b.mu held.
+func (b *ConnectionBudget) admit(cb *controllerBudget) {
+	for len(cb.waiters) > 0 && (b.limitOf(cb) == 0 || cb.held < b.limitOf(cb)) {
+		cb.held++
+		close(cb.waiters[0])
+		cb.waiters = cb.waiters[1:]
+	}
+}
+
+// Acquire takes a connection to the controller at address, queuing until one
+// is released if the budget is used up. It fails with ErrTimeout after the
+// queue timeout, or with the context's error.
+func (b *ConnectionBudget) Acquire(ctx context.Context, address string) error {
+	b.mu.Lock()
+	cb := b.controller(address)
+	limit := b.limitOf(cb)
+	if limit == 0 || (cb.held < limit && len(cb.waiters) == 0) {
+		cb.held++
+		b.mu.Unlock()
+		return nil
+	}
+	timeout := b.timeout
+	ready := make(chan struct{})
+	cb.waiters = append(cb.waiters, ready)
+	b.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	var ctxErr error
+	select {
+	case <-ready:
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
+		ctxErr = ctx.Err()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-ready:
+		// Admitted while giving up
+		return nil
+	default:
+	}
+	for i, w := range cb.waiters {
+		if w == ready {
+			cb.waiters = append(cb.waiters[:i], cb.waiters[i+1:]...)
+			break
+		}
+	}
+	if ctxErr != nil {
+		return ctxErr
+	}
+	return NewEipErrorWithDetails(ErrTimeout,
+		fmt.Sprintf("connection budget for %s used up: %d of %d connections held", address, cb.held, b.limitOf(cb)),
+		map[string]interface{}{"address": address, "held": cb.held, "limit": b.limitOf(cb), "timeout": timeout.String()})
+}
+
+// Release returns a connection to the controller at address
+func (b *ConnectionBudget) Release(address string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cb := b.controller(address)
+	if cb.held > 0 {
+		cb.held--
+	}
+	b.admit(cb)
+}
+
+// Held returns the number of connections held to the controller at address
+func (b *ConnectionBudget) Held(address string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.controller(address).held
+}
+
+// Usage returns the budget of every controller, sorted by address
+func (b *ConnectionBudget) Usage() []ConnectionUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	usage := make([]ConnectionUsage, 0, len(b.controllers))
+	for address, cb := range b.controllers {
+		usage = append(usage, ConnectionUsage{Address: address, Held: cb.held, Limit: b.limitOf(cb), Waiting: len(cb.waiters)})
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Address < usage[j].Address })
+	return usage
+}
+
+// Connection Manager object (class 0x06) instance attributes
+const (
+	cipClassConnectionManager      uint16 = 0x06
+	connMgrAttrOpenRequests               = 1
+	connMgrAttrOpenResourceRejects        = 3
+	connMgrAttrOpenOtherRejects           = 4
+	connMgrAttrConnectionTimeouts         = 8
+	connMgrAttrConnectionEntryList        = 9
+)
+
+// ConnectionLimits are the connection counts the controller reports through
+// its Connection Manager object
+type ConnectionLimits struct {
+	// Max is the size of the controller's connection table and Open the
+	// number of its entries in use, by this and every other process
+	Max  int `json:"max"`
+	Open int `json:"open"`
+	// Counters since the controller started
+	OpenRequests    uint16 `json:"open_requests"`
+	ResourceRejects uint16 `json:"resource_rejects"`
+	OtherRejects    uint16 `json:"other_rejects"`
+	Timeouts        uint16 `json:"timeouts"`
+}
+
+// Free returns the number of unused connection table entries
+func (l *ConnectionLimits) Free() int {
+	return l.Max - l.Open
+}
+
+// ReadConnectionLimits reads the controller's connection table size and use.
+// Counters the controller does not support are left at zero.
+func (c *EipClient) ReadConnectionLimits() (*ConnectionLimits, error) {
+	resp, err := c.SendCIPMessage(CIPServiceGetAttributeSingle,
+		classInstanceAttributePath(cipClassConnectionManager, 1, connMgrAttrConnectionEntryList), nil)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := parseConnectionEntryList(resp.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := c.getAttributeList(cipClassConnectionManager, 1, []attributeSpec{
+		{connMgrAttrOpenRequests, 2}, {connMgrAttrOpenResourceRejects, 2},
+		{connMgrAttrOpenOtherRejects, 2}, {connMgrAttrConnectionTimeouts, 2},
+	})
+	if err != nil {
+		return limits, nil
+	}
+	counter := func(id uint16) uint16 {
+		if v, ok := values[id]; ok {
+			return binary.LittleEndian.Uint16(v)
+		}
+		return 0
+	}
+	limits.OpenRequests = counter(connMgrAttrOpenRequests)
+	limits.ResourceRejects = counter(connMgrAttrOpenResourceRejects)
+	limits.OtherRejects = counter(connMgrAttrOpenOtherRejects)
+	limits.Timeouts = counter(connMgrAttrConnectionTimeouts)
+	return limits, nil
+}
+
+// parseConnectionEntryList decodes the Connection Entry List attribute: the
+// number of entries (UINT) followed by one bit per entry, set when open
+func parseConnectionEntryList(data []byte) (*ConnectionLimits, error) {
+	if len(data) < 2 {
+		return nil, NewEipError(ErrInvalidValue, "connection entry list too short")
+	}
+	entries := int(binary.LittleEndian.Uint16(data))
+	openBits := data[2:]
+	if len(openBits) < (entries+7)/8 {
+		return nil, NewEipErrorWithDetails(ErrInvalidValue, "connection entry list truncated",
+			map[string]interface{}{"entries": entries, "length": len(data)})
+	}
+	open := 0
+	for _, b := range openBits[:(entries+7)/8] {
+		open += bits.OnesCount8(b)
+	}
+	return &ConnectionLimits{Max: entries, Open: open}, nil
+}
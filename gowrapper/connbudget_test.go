@@ -0,0 +1,94 @@
+package ethernetip
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestConnectionBudgetQueue tests that sessions beyond the limit queue in order
+func TestConnectionBudgetQueue(t *testing.T) {
+	b := NewConnectionBudget()
+	b.SetLimit("10.0.0.5", 2)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := b.Acquire(ctx, "10.0.0.5"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := b.Acquire(ctx, "10.0.0.6"); err != nil {
+		t.Fatalf("Expected other controllers to be unlimited: %v", err)
+	}
+
+	order := make(chan int, 2)
+	for i := 1; i <= 2; i++ {
+		go func(n int) {
+			if err := b.Acquire(ctx, "10.0.0.5:44818"); err == nil {
+				order <- n
+			}
+		}(i)
+		waiting := i
+		waitFor(t, func() bool { return b.Usage()[0].Waiting == waiting })
+	}
+
+	b.Release("10.0.0.5")
+	if got := <-order; got != 1 {
+		t.Errorf("Expected the first waiter to be admitted, got %d", got)
+	}
+	b.SetLimit("10.0.0.5", 3)
+	if got := <-order; got != 2 {
+		t.Errorf("Expected raising the limit to admit the second waiter, got %d", got)
+	}
+
+	usage := b.Usage()
+	if len(usage) != 2 || usage[0] != (ConnectionUsage{Address: "10.0.0.5", Held: 3, Limit: 3}) || usage[1].Held != 1 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+}
+
+// TestConnectionBudgetTimeout tests giving up on a used-up budget
+func TestConnectionBudgetTimeout(t *testing.T) {
+	b := NewConnectionBudget()
+	b.SetDefaultLimit(1)
+	b.SetQueueTimeout(20 * time.Millisecond)
+	if err := b.Acquire(context.Background(), "plc"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := b.Acquire(context.Background(), "plc")
+	var eipErr *EipError
+	if !errors.As(err, &eipErr) || eipErr.Code != ErrTimeout {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.SetQueueTimeout(time.Minute)
+	if err := b.Acquire(ctx, "plc"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if u := b.Usage()[0]; u.Held != 1 || u.Waiting != 0 {
+		t.Errorf("Expected abandoned waits to leave the queue, got %+v", u)
+	}
+
+	b.Release("plc")
+	b.Release("plc")
+	if held := b.Held("plc"); held != 0 {
+		t.Errorf("Expected extra releases to be ignored, got %d held", held)
+	}
+}
+
+// TestParseConnectionEntryList tests decoding the connection table attribute
+func TestParseConnectionEntryList(t *testing.T) {
+	limits, err := parseConnectionEntryList([]byte{10, 0, 0x0F, 0x01})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if limits.Max != 10 || limits.Open != 5 || limits.Free() != 5 {
+		t.Errorf("Unexpected limits: %+v", limits)
+	}
+	if _, err := parseConnectionEntryList([]byte{10, 0, 0x0F}); err == nil {
+		t.Error("Expected error for truncated open bits")
+	}
+}
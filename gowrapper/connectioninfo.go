@@ -0,0 +1,94 @@
+package ethernetip
+
+/*
+// Connection info
+extern int eip_get_connection_info(int client_id, void* info);
+*/
+import "C"
+import (
+	"time"
+	"unsafe"
+)
+
+// ConnectionInfo is the state of the client's messaging connection as
+// negotiated with the controller
+type ConnectionInfo struct {
+	MessagingMode MessagingMode `json:"messaging_mode"`
+	// Connected reports whether a Class 3 connection is open; in connected
+	// mode it is opened on the first request
+	Connected bool `json:"connected"`
+	// LargeForwardOpen reports whether the connection was opened with Large
+	// Forward Open; false after a fallback to a standard connection
+	LargeForwardOpen bool `json:"large_forward_open"`
+	// ConnectionSize is the negotiated connection size in bytes, 0 without a
+	// connection
+	ConnectionSize   int           `json:"connection_size"`
+	RPI              time.Duration `json:"rpi"`
+	ConnectionID     uint32        `json:"connection_id"` // O->T ID assigned by the controller
+	ConnectionSerial uint16        `json:"connection_serial"`
+	// MaxPacketSize is the cap set with SetMaxPacketSize
+	MaxPacketSize int `json:"max_packet_size"`
+}
+
+// MessageSize returns the largest CIP message a request can carry: the
+// negotiated connection size, or the unconnected message limit without a
+// connection
+func (i *ConnectionInfo) MessageSize() int {
+	if i.Connected && i.ConnectionSize > 0 {
+		return i.ConnectionSize
+	}
+	return maxUnconnectedMessageSize
+}
+
+// messageSize returns the largest CIP message a request can carry on the
+// current connection, or the unconnected message limit if the connection info
+// is unavailable
+func (c *EipClient) messageSize() int {
+	info, err := c.GetConnectionInfo()
+	if err != nil {
+		return maxUnconnectedMessageSize
+	}
+	return info.MessageSize()
+}
+
+// cConnectionInfo mirrors the native CConnectionInfo layout
+type cConnectionInfo struct {
+	messagingMode    int32
+	connected        int32
+	largeForwardOpen int32
+	connectionSize   int32
+	rpiUs            uint32
+	connectionID     uint32
+	connectionSerial uint32
+	maxPacketSize    uint32
+}
+
+// GetConnectionInfo returns the state of the messaging connection, including
+// the connection size negotiated with Large Forward Open or the standard
+// connection it fell back to
+func (c *EipClient) GetConnectionInfo() (*ConnectionInfo, error) {
+	var raw cConnectionInfo
+	retCode := int(C.eip_get_connection_info(C.int(c.id()), unsafe.Pointer(&raw)))
+	if retCode != 0 {
+		return nil, NewEipErrorWithDetails(ErrConnectionFailed, "Failed to get connection info",
+			map[string]interface{}{
+				"error_code": retCode,
+				"client_id":  c.id(),
+			})
+	}
+	return raw.info(), nil
+}
+
+// info converts the native layout
+func (raw *cConnectionInfo) info() *ConnectionInfo {
+	return &ConnectionInfo{
+		MessagingMode:    MessagingMode(raw.messagingMode),
+		Connected:        raw.connected != 0,
+		LargeForwardOpen: raw.largeForwardOpen != 0,
+		ConnectionSize:   int(raw.connectionSize),
+		RPI:              time.Duration(raw.rpiUs) * time.Microsecond,
+		ConnectionID:     raw.connectionID,
+		ConnectionSerial: uint16(raw.connectionSerial),
+		MaxPacketSize:    int(raw.maxPacketSize),
+	}
+}
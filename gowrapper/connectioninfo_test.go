@@ -0,0 +1,43 @@
+package ethernetip
+
+import (
+	"testing"
+	"time"
+)
+
+// TestConnectionInfo tests converting the native connection info and the
+// message size it allows
+func TestConnectionInfo(t *testing.T) {
+	raw := cConnectionInfo{
+		messagingMode:    int32(MessagingConnected),
+		connected:        1,
+		connectionSize:   FallbackConnectionSize,
+		rpiUs:            100000,
+		connectionID:     0x80010002,
+		connectionSerial: 7,
+		maxPacketSize:    4000,
+	}
+	info := raw.info()
+	want := ConnectionInfo{
+		MessagingMode:    MessagingConnected,
+		Connected:        true,
+		ConnectionSize:   FallbackConnectionSize,
+		RPI:              100 * time.Millisecond,
+		ConnectionID:     0x80010002,
+		ConnectionSerial: 7,
+		MaxPacketSize:    4000,
+	}
+	if *info != want {
+		t.Errorf("Expected %+v, got %+v", want, *info)
+	}
+	if info.MessageSize() != FallbackConnectionSize {
+		t.Errorf("Expected the negotiated size, got %d", info.MessageSize())
+	}
+	if (&ConnectionInfo{MaxPacketSize: 4000}).MessageSize() != maxUnconnectedMessageSize {
+		t.Error("Expected the unconnected limit without a connection")
+	}
+
+	if _, err := (&EipClient{}).GetConnectionInfo(); err == nil {
+		t.Error("Expected an error without a session")
+	}
+}
@@ -0,0 +1,144 @@
+package ethernetip
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GroupMember is a tag in a ConsistencyGroup
+type GroupMember struct {
+	TagName  string      `json:"tag_name"`
+	DataType PlcDataType `json:"data_type"`
+}
+
+// ConsistencyGroup is a set of tags that are always read together in a single
+// Multiple Service Packet. The controller services the whole packet in one
+// request, so related values (a part count and its batch ID, say) are not torn
+// across separate reads. A group that cannot fit in one packet is rejected
+// when it is created rather than silently split.
+type ConsistencyGroup struct {
+	client  *EipClient
+	members []GroupMember
+	step    ReadStep // The Multiple Service Packet read, with aliases resolved
+}
+
+// NewConsistencyGroup creates a consistency group from members. Members named
+// by an alias read its tag, and Read returns their values under the alias. It
+// fails if a member is invalid or the request or its reply would not fit in
+// one packet of the current connection (see ConnectionInfo.MessageSize).
+func (c *EipClient) NewConsistencyGroup(members ...GroupMember) (*ConsistencyGroup, error) {
+	return c.newConsistencyGroup(c.messageSize(), members)
+}
+
+// newConsistencyGroup creates a consistency group whose request and reply
+// fit in limit bytes
+func (c *EipClient) newConsistencyGroup(limit int, members []GroupMember) (*ConsistencyGroup, error) {
+	if len(members) == 0 {
+		return nil, NewEipError(ErrInvalidOperation, "consistency group must have at least one member")
+	}
+
+	step := ReadStep{
+		Kind:       ReadStepMultiple,
+		RoundTrips: 1,
+		Reason:     fmt.Sprintf("consistency group of %d tags", len(members)),
+		// Sizes of an empty Multiple Service Packet, as in compileReadPlan
+		RequestSize: 2 + len(classInstancePath(CIPClassMessageRouter, 1)) + 2,
+		ReplySize:   4 + 2,
+	}
+	requests := make([][]byte, len(members))
+	seen := make(map[string]string, len(members))
+	for i, m := range members {
+		tagName := c.ResolveAlias(m.TagName)
+		if other, ok := seen[tagName]; ok {
+			if other == m.TagName {
+				return nil, NewEipError(ErrInvalidTagName, fmt.Sprintf("duplicate consistency group member '%s'", m.TagName))
+			}
+			return nil, NewEipErrorWithDetails(ErrInvalidTagName,
+				fmt.Sprintf("'%s' and '%s' both read '%s'", other, m.TagName, tagName),
+				map[string]interface{}{"tag_name": tagName})
+		}
+		seen[tagName] = m.TagName
+
+		req, err := encodeReadItem(ReadItem{TagName: tagName, DataType: m.DataType})
+		if err != nil {
+			return nil, err
+		}
+		step.Items = append(step.Items, req.item)
+		requests[i] = req.request
+		step.RequestSize += 2 + len(req.request)
+		step.ReplySize += 2 + 4 + req.replySize
+	}
+
+	if step.RequestSize > limit || step.ReplySize > limit {
+		return nil, NewEipErrorWithDetails(ErrInvalidOperation, "consistency group does not fit in a single packet",
+			map[string]interface{}{
+				"members":      len(members),
+				"request_size": step.RequestSize,
+				"reply_size":   step.ReplySize,
+				"max_size":     limit,
+			})
+	}
+	step.request = buildMultipleServicePacket(requests)
+
+	return &ConsistencyGroup{
+		client:  c,
+		members: append([]GroupMember(nil), members...),
+		step:    step,
+	}, nil
+}
+
+// Members returns the tags in the group
+func (g *ConsistencyGroup) Members() []GroupMember {
+	return append([]GroupMember(nil), g.members...)
+}
+
+// Read reads every member in one Multiple Service Packet, queued as one
+// operation (see QueueStats). If any member fails the whole read fails, so
+// callers never see a partially updated set.
+func (g *ConsistencyGroup) Read() (map[string]*PlcValue, error) {
+	var values map[string]*PlcValue
+	err := g.client.submit(OperationRead, fmt.Sprintf("%d tags", len(g.members)), func() error {
+		resp, err := g.client.SendCIPMessage(CIPServiceMultipleServicePacket,
+			classInstancePath(CIPClassMessageRouter, 1), g.step.request)
+		if err != nil && (resp == nil || resp.GeneralStatus != CIPStatusEmbeddedService) {
+			return err
+		}
+		values, err = g.decode(resp.Data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// decode converts the Multiple Service Packet reply data into member values,
+// keyed by the names of the members
+func (g *ConsistencyGroup) decode(data []byte) (map[string]*PlcValue, error) {
+	read := make(map[string]*PlcValue, len(g.members))
+	errs := make(map[string]error)
+	g.step.decodeMultiple(data, read, errs)
+
+	values := make(map[string]*PlcValue, len(g.members))
+	var failed []string
+	for i, m := range g.members {
+		tagName := g.step.Items[i].TagName
+		if err, ok := errs[tagName]; ok {
+			reason := err.Error()
+			var eipErr *EipError
+			if errors.As(err, &eipErr) {
+				reason = eipErr.Message
+			}
+			failed = append(failed, fmt.Sprintf("%s (%s)", m.TagName, reason))
+			continue
+		}
+		values[m.TagName] = read[tagName]
+	}
+	if len(failed) > 0 {
+		return nil, NewEipErrorWithDetails(ErrBatchOperationFailed,
+			"consistency group read failed: "+strings.Join(failed, ", "),
+			map[string]interface{}{"failed_members": failed})
+	}
+	return values, nil
+}
@@ -0,0 +1,118 @@
+package ethernetip
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestNewConsistencyGroupValidation tests that invalid or oversized groups are rejected
+func TestNewConsistencyGroupValidation(t *testing.T) {
+	client := &EipClient{}
+
+	if _, err := client.NewConsistencyGroup(); err == nil {
+		t.Error("expected error for empty group")
+	}
+	if _, err := client.NewConsistencyGroup(
+		GroupMember{"PartCount", Dint}, GroupMember{"PartCount", Dint},
+	); err == nil {
+		t.Error("expected error for duplicate member")
+	}
+	if _, err := client.NewConsistencyGroup(GroupMember{"Recipe", Udt}); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+
+	var tooMany []GroupMember
+	for i := 0; i < 10; i++ {
+		tooMany = append(tooMany, GroupMember{"Name" + strings.Repeat("x", i), String})
+	}
+	if _, err := client.NewConsistencyGroup(tooMany...); err == nil {
+		t.Error("expected error for group larger than one packet")
+	}
+
+	group, err := client.NewConsistencyGroup(GroupMember{"PartCount", Dint}, GroupMember{"BatchID", String})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(group.Members()) != 2 {
+		t.Errorf("expected 2 members, got %d", len(group.Members()))
+	}
+}
+
+// TestConsistencyGroupDecode tests decoding a group reply, including failure of one member
+func TestConsistencyGroupDecode(t *testing.T) {
+	client := &EipClient{}
+	group, err := client.NewConsistencyGroup(GroupMember{"PartCount", Dint}, GroupMember{"Running", Bool})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reply := []byte{
+		0x02, 0x00, 0x06, 0x00, 0x10, 0x00,
+		0xCC, 0x00, 0x00, 0x00, 0xC4, 0x00, 0x07, 0x00, 0x00, 0x00,
+		0xCC, 0x00, 0x00, 0x00, 0xC1, 0x00, 0x01,
+	}
+	values, err := group.decode(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["PartCount"].Value != int32(7) || values["Running"].Value != true {
+		t.Errorf("unexpected values: %+v %+v", values["PartCount"], values["Running"])
+	}
+
+	reply[18] = CIPStatusPathUnknown
+	if _, err := group.decode(reply[:20]); err == nil || !strings.Contains(err.Error(), "Running") {
+		t.Errorf("expected failure naming Running, got %v", err)
+	}
+}
+
+// TestConsistencyGroupMessageSize tests that a group is sized against the
+// connection's message size rather than the unconnected limit
+func TestConsistencyGroupMessageSize(t *testing.T) {
+	client := &EipClient{}
+	var members []GroupMember
+	for i := 0; i < 10; i++ {
+		members = append(members, GroupMember{"Name" + strings.Repeat("x", i), String})
+	}
+	if _, err := client.newConsistencyGroup(maxUnconnectedMessageSize, members); err == nil {
+		t.Error("expected error for group larger than an unconnected message")
+	}
+	group, err := client.newConsistencyGroup(MaxConnectionSize, members)
+	if err != nil {
+		t.Fatalf("expected group to fit a Large Forward Open connection, got %v", err)
+	}
+	if group.step.ReplySize <= maxUnconnectedMessageSize || group.step.ReplySize > MaxConnectionSize {
+		t.Errorf("expected reply size between %d and %d, got %d", maxUnconnectedMessageSize, MaxConnectionSize, group.step.ReplySize)
+	}
+}
+
+// TestConsistencyGroupAliases tests that aliased members read their tag and
+// are returned under the alias
+func TestConsistencyGroupAliases(t *testing.T) {
+	client := &EipClient{}
+	if err := client.SetTagAliases(map[string]string{"parts": "PartCount", "count": "PartCount"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewConsistencyGroup(GroupMember{"parts", Dint}, GroupMember{"count", Dint}); err == nil {
+		t.Error("expected error for two aliases of one tag")
+	}
+
+	group, err := client.NewConsistencyGroup(GroupMember{"parts", Dint}, GroupMember{"Running", Bool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.step.Items[0].TagName != "PartCount" {
+		t.Errorf("expected the alias to be resolved, got %s", group.step.Items[0].TagName)
+	}
+	reply := []byte{
+		0x02, 0x00, 0x06, 0x00, 0x10, 0x00,
+		0xCC, 0x00, 0x00, 0x00, 0xC4, 0x00, 0x07, 0x00, 0x00, 0x00,
+		0xCC, 0x00, 0x00, 0x00, 0xC1, 0x00, 0x01,
+	}
+	values, err := group.decode(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["parts"] == nil || values["parts"].Value != int32(7) {
+		t.Errorf("expected parts = 7, got %+v", values)
+	}
+}
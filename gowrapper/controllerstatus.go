@@ -0,0 +1,145 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// Identity Object attribute holding the device status word
+const identityAttrStatus = 5
+
+// ControllerMode is the operating mode of a Logix controller
+type ControllerMode int
+
+const (
+	ModeUnknown ControllerMode = iota
+	ModeRun
+	ModeProgram
+	ModeFaulted
+)
+
+// String returns the mode name
+func (m ControllerMode) String() string {
+	switch m {
+	case ModeRun:
+		return "RUN"
+	case ModeProgram:
+		return "PROGRAM"
+	case ModeFaulted:
+		return "FAULTED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MarshalJSON encodes the mode as its name
+func (m ControllerMode) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + m.String() + `"`), nil
+}
+
+// KeyswitchPosition is the position of the controller's mode switch
+type KeyswitchPosition int
+
+const (
+	KeyswitchUnknown KeyswitchPosition = iota
+	KeyswitchRun
+	KeyswitchProgram
+	KeyswitchRemote
+)
+
+// String returns the keyswitch position name
+func (k KeyswitchPosition) String() string {
+	switch k {
+	case KeyswitchRun:
+		return "RUN"
+	case KeyswitchProgram:
+		return "PROGRAM"
+	case KeyswitchRemote:
+		return "REMOTE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MarshalJSON encodes the keyswitch position as its name
+func (k KeyswitchPosition) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + k.String() + `"`), nil
+}
+
+// ControllerStatus is the decoded Identity Object status word of a controller
+type ControllerStatus struct {
+	Mode                  ControllerMode    `json:"mode"`
+	Keyswitch             KeyswitchPosition `json:"keyswitch"`
+	MinorRecoverableFault bool              `json:"minor_recoverable_fault"`
+	MinorFault            bool              `json:"minor_unrecoverable_fault"`
+	MajorRecoverableFault bool              `json:"major_recoverable_fault"`
+	MajorFault            bool              `json:"major_unrecoverable_fault"`
+	Raw                   uint16            `json:"raw"`
+}
+
+// Faulted reports whether the controller has a major fault
+func (s ControllerStatus) Faulted() bool {
+	return s.Mode == ModeFaulted || s.MajorRecoverableFault || s.MajorFault
+}
+
+// String summarises the status, e.g. "RUN (keyswitch REMOTE)"
+func (s ControllerStatus) String() string {
+	var b strings.Builder
+	b.WriteString(s.Mode.String())
+	b.WriteString(" (keyswitch ")
+	b.WriteString(s.Keyswitch.String())
+	b.WriteString(")")
+	if s.Faulted() {
+		b.WriteString(" major fault")
+	} else if s.MinorRecoverableFault || s.MinorFault {
+		b.WriteString(" minor fault")
+	}
+	return b.String()
+}
+
+// ParseControllerStatus decodes a Logix Identity status word. Bits 8-11 are
+// the standard CIP fault bits; Logix controllers report the operating mode in
+// the extended device status (bits 4-7) and the keyswitch in bits 12-13.
+func ParseControllerStatus(word uint16) ControllerStatus {
+	s := ControllerStatus{
+		MinorRecoverableFault: word&0x0100 != 0,
+		MinorFault:            word&0x0200 != 0,
+		MajorRecoverableFault: word&0x0400 != 0,
+		MajorFault:            word&0x0800 != 0,
+		Raw:                   word,
+	}
+	switch (word >> 4) & 0x0F {
+	case 0x5:
+		s.Mode = ModeFaulted
+	case 0x6:
+		s.Mode = ModeRun
+	case 0x7:
+		s.Mode = ModeProgram
+	}
+	if s.MajorRecoverableFault || s.MajorFault {
+		s.Mode = ModeFaulted
+	}
+	switch (word >> 12) & 0x03 {
+	case 0x1:
+		s.Keyswitch = KeyswitchRun
+	case 0x2:
+		s.Keyswitch = KeyswitchProgram
+	case 0x3:
+		s.Keyswitch = KeyswitchRemote
+	}
+	return s
+}
+
+// ReadControllerStatus reads and decodes the controller's Identity status word
+func (c *EipClient) ReadControllerStatus() (ControllerStatus, error) {
+	resp, err := c.SendCIPMessage(CIPServiceGetAttributeSingle,
+		classInstanceAttributePath(CIPClassIdentity, 1, identityAttrStatus), nil)
+	if err != nil {
+		return ControllerStatus{}, err
+	}
+	if len(resp.Data) < 2 {
+		return ControllerStatus{}, NewEipErrorWithDetails(ErrInvalidOperation, "status reply too short",
+			map[string]interface{}{"length": len(resp.Data)})
+	}
+	return ParseControllerStatus(binary.LittleEndian.Uint16(resp.Data)), nil
+}
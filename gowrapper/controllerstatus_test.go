@@ -0,0 +1,32 @@
+package ethernetip
+
+import (
+	"testing"
+)
+
+// TestParseControllerStatus tests decoding of Logix status words
+func TestParseControllerStatus(t *testing.T) {
+	cases := []struct {
+		word      uint16
+		mode      ControllerMode
+		keyswitch KeyswitchPosition
+		faulted   bool
+	}{
+		{0x3060, ModeRun, KeyswitchRemote, false},
+		{0x3070, ModeProgram, KeyswitchRemote, false},
+		{0x1060, ModeRun, KeyswitchRun, false},
+		{0x2070, ModeProgram, KeyswitchProgram, false},
+		{0x3450, ModeFaulted, KeyswitchRemote, true},
+		{0x3160, ModeRun, KeyswitchRemote, false},
+		{0x0000, ModeUnknown, KeyswitchUnknown, false},
+	}
+	for _, c := range cases {
+		s := ParseControllerStatus(c.word)
+		if s.Mode != c.mode || s.Keyswitch != c.keyswitch || s.Faulted() != c.faulted {
+			t.Errorf("0x%04X: got %s (faulted %v)", c.word, s, s.Faulted())
+		}
+	}
+	if s := ParseControllerStatus(0x3160); !s.MinorRecoverableFault {
+		t.Error("expected minor recoverable fault bit")
+	}
+}
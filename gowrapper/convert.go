@@ -0,0 +1,311 @@
+package ethernetip
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The To functions convert user-supplied values to the Go type of a PLC data
+// type: any Go integer or float, json.Number, or a string such as a form
+// field or query parameter ("1200", " -3 ", "0x1F", "1e3", "180.5").
+// Integers must be integral and fit the type; REAL values must fit a 32-bit
+// float. Conversions that fail return an ErrInvalidTagValue error naming the
+// value and type.
+
+// ToBool converts v to a BOOL: a bool, the numbers 0 and 1, or a string
+// accepted by strconv.ParseBool ("true", "0", "F", ...)
+func ToBool(v interface{}) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case string:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(b))
+		if err != nil {
+			return false, conversionError(Bool, v, "not a boolean")
+		}
+		return parsed, nil
+	}
+	n, err := toUnsigned(v, Bool, 64)
+	if err != nil || n > 1 {
+		return false, conversionError(Bool, v, "expected 0 or 1")
+	}
+	return n == 1, nil
+}
+
+// ToSint converts v to a SINT (-128 to 127)
+func ToSint(v interface{}) (int8, error) {
+	n, err := toSigned(v, Sint, 8)
+	return int8(n), err
+}
+
+// ToInt converts v to an INT (-32768 to 32767)
+func ToInt(v interface{}) (int16, error) {
+	n, err := toSigned(v, Int, 16)
+	return int16(n), err
+}
+
+// ToDint converts v to a DINT
+func ToDint(v interface{}) (int32, error) {
+	n, err := toSigned(v, Dint, 32)
+	return int32(n), err
+}
+
+// ToLint converts v to a LINT
+func ToLint(v interface{}) (int64, error) {
+	return toSigned(v, Lint, 64)
+}
+
+// ToUsint converts v to a USINT (0 to 255)
+func ToUsint(v interface{}) (uint8, error) {
+	n, err := toUnsigned(v, Usint, 8)
+	return uint8(n), err
+}
+
+// ToUint converts v to a UINT (0 to 65535)
+func ToUint(v interface{}) (uint16, error) {
+	n, err := toUnsigned(v, Uint, 16)
+	return uint16(n), err
+}
+
+// ToUdint converts v to a UDINT
+func ToUdint(v interface{}) (uint32, error) {
+	n, err := toUnsigned(v, Udint, 32)
+	return uint32(n), err
+}
+
+// ToUlint converts v to a ULINT
+func ToUlint(v interface{}) (uint64, error) {
+	return toUnsigned(v, Ulint, 64)
+}
+
+// ToReal converts v to a REAL, held in a float64 as WriteValue expects.
+// Finite values beyond the range of a 32-bit float are rejected; NaN and
+// infinities are kept.
+func ToReal(v interface{}) (float64, error) {
+	f, err := toFloat(v, Real)
+	if err == nil && !math.IsInf(f, 0) && math.Abs(f) > math.MaxFloat32 {
+		return 0, conversionError(Real, v, "out of range")
+	}
+	return f, err
+}
+
+// ToLreal converts v to an LREAL
+func ToLreal(v interface{}) (float64, error) {
+	return toFloat(v, Lreal)
+}
+
+// ConvertValue converts v to a PlcValue of dataType with the To function of
+// the type. Unlike NewPlcValue, which takes JSON-decoded values as they are,
+// it accepts numbers of any Go type and numeric strings. STRING takes a
+// string; DT and LDT take a time.Time, an RFC 3339 string or the integer
+// encoding; TIME takes a time.Duration, a duration string such as "1m30s"
+// or microseconds. UDT values are passed through unchanged.
+func ConvertValue(dataType PlcDataType, v interface{}) (*PlcValue, error) {
+	var value interface{}
+	var err error
+	switch dataType {
+	case Bool:
+		value, err = ToBool(v)
+	case Sint:
+		value, err = ToSint(v)
+	case Int:
+		value, err = ToInt(v)
+	case Dint:
+		value, err = ToDint(v)
+	case Lint:
+		value, err = ToLint(v)
+	case Usint:
+		value, err = ToUsint(v)
+	case Uint:
+		value, err = ToUint(v)
+	case Udint:
+		value, err = ToUdint(v)
+	case Ulint:
+		value, err = ToUlint(v)
+	case Real:
+		value, err = ToReal(v)
+	case Lreal:
+		value, err = ToLreal(v)
+	case String:
+		s, ok := v.(string)
+		if !ok {
+			return nil, conversionError(dataType, v, "expected a string")
+		}
+		value = s
+	case Dt, Ldt, Time:
+		switch t := v.(type) {
+		case time.Time:
+			if dataType == Time {
+				return nil, conversionError(dataType, v, "expected a duration")
+			}
+			value = t
+		case time.Duration:
+			if dataType != Time {
+				return nil, conversionError(dataType, v, "expected a time")
+			}
+			value = t
+		case string:
+			if value, err = coerceTemporal(dataType, strings.TrimSpace(t)); err != nil {
+				return nil, conversionError(dataType, v, err.Error())
+			}
+		default:
+			lint, lintErr := ToLint(v)
+			if lintErr != nil {
+				return nil, conversionError(dataType, v, "expected a time, duration or integer")
+			}
+			value = temporalValue(dataType, lint)
+		}
+	default:
+		value = v
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &PlcValue{Type: dataType, Value: value}, nil
+}
+
+// toSigned converts v to an integer of the given bit size
+func toSigned(v interface{}, dataType PlcDataType, bits int) (int64, error) {
+	var n int64
+	switch x := v.(type) {
+	case int:
+		n = int64(x)
+	case int8:
+		n = int64(x)
+	case int16:
+		n = int64(x)
+	case int32:
+		n = int64(x)
+	case int64:
+		n = x
+	case uint, uint8, uint16, uint32, uint64:
+		u, _ := toUnsigned(x, dataType, 64)
+		if u > math.MaxInt64 {
+			return 0, conversionError(dataType, v, "out of range")
+		}
+		n = int64(u)
+	case float32, float64, json.Number, string:
+		text, isText := numberText(x)
+		if isText {
+			if i, err := strconv.ParseInt(text, integerBase(text), 64); err == nil {
+				n = i
+				break
+			}
+		}
+		f, err := toFloat(x, dataType)
+		if err != nil {
+			return 0, err
+		}
+		// 2^63 itself is the first float64 beyond MaxInt64
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, conversionError(dataType, v, "not an integer in range")
+		}
+		n = int64(f)
+	default:
+		return 0, conversionError(dataType, v, "not a number")
+	}
+	if min, max := -int64(1)<<(bits-1), int64(1)<<(bits-1)-1; bits < 64 && (n < min || n > max) {
+		return 0, conversionError(dataType, v, fmt.Sprintf("out of range %d to %d", min, max))
+	}
+	return n, nil
+}
+
+// toUnsigned converts v to an unsigned integer of the given bit size
+func toUnsigned(v interface{}, dataType PlcDataType, bits int) (uint64, error) {
+	var n uint64
+	switch x := v.(type) {
+	case uint:
+		n = uint64(x)
+	case uint8:
+		n = uint64(x)
+	case uint16:
+		n = uint64(x)
+	case uint32:
+		n = uint64(x)
+	case uint64:
+		n = x
+	case int, int8, int16, int32, int64:
+		i, _ := toSigned(x, dataType, 64)
+		if i < 0 {
+			return 0, conversionError(dataType, v, "negative")
+		}
+		n = uint64(i)
+	case float32, float64, json.Number, string:
+		text, isText := numberText(x)
+		if isText {
+			if u, err := strconv.ParseUint(text, integerBase(text), 64); err == nil {
+				n = u
+				break
+			}
+		}
+		f, err := toFloat(x, dataType)
+		if err != nil {
+			return 0, err
+		}
+		if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+			return 0, conversionError(dataType, v, "not a non-negative integer in range")
+		}
+		n = uint64(f)
+	default:
+		return 0, conversionError(dataType, v, "not a number")
+	}
+	if max := uint64(1)<<bits - 1; bits < 64 && n > max {
+		return 0, conversionError(dataType, v, fmt.Sprintf("out of range 0 to %d", max))
+	}
+	return n, nil
+}
+
+// toFloat converts v to a float64
+func toFloat(v interface{}, dataType PlcDataType) (float64, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case float32:
+		return float64(x), nil
+	case int, int8, int16, int32, int64:
+		i, _ := toSigned(x, dataType, 64)
+		return float64(i), nil
+	case uint, uint8, uint16, uint32, uint64:
+		u, _ := toUnsigned(x, dataType, 64)
+		return float64(u), nil
+	case json.Number, string:
+		text, _ := numberText(x)
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return 0, conversionError(dataType, v, "not a number")
+		}
+		return f, nil
+	}
+	return 0, conversionError(dataType, v, "not a number")
+}
+
+// numberText returns the trimmed text of a json.Number or string
+func numberText(v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case json.Number:
+		return string(x), true
+	case string:
+		return strings.TrimSpace(x), true
+	}
+	return "", false
+}
+
+// integerBase returns the base of an integer literal: 16 or 2 with a 0x or
+// 0b prefix, 10 otherwise, so "010" is ten rather than octal
+func integerBase(text string) int {
+	unsigned := strings.TrimLeft(text, "+-")
+	if len(unsigned) > 2 && unsigned[0] == '0' && strings.ContainsRune("xXbB", rune(unsigned[1])) {
+		return 0
+	}
+	return 10
+}
+
+// conversionError reports a value that cannot be converted to dataType
+func conversionError(dataType PlcDataType, v interface{}, reason string) error {
+	return NewEipErrorWithDetails(ErrInvalidTagValue, fmt.Sprintf("cannot convert %v (%T) to %s: %s", v, v, dataType, reason),
+		map[string]interface{}{"value": fmt.Sprint(v), "type": dataType.String()})
+}
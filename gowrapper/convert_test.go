@@ -0,0 +1,81 @@
+package ethernetip
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestConvertIntegers tests integer conversion from Go numbers, JSON numbers
+// and strings, with range checks
+func TestConvertIntegers(t *testing.T) {
+	for _, v := range []interface{}{1200, int64(1200), uint16(1200), 1200.0, float32(1200), json.Number("1200"), " 1200 ", "0x4B0", "1.2e3"} {
+		if n, err := ToDint(v); err != nil || n != 1200 {
+			t.Errorf("ToDint(%#v) = %d, %v", v, n, err)
+		}
+	}
+	if n, err := ToInt("010"); err != nil || n != 10 {
+		t.Errorf("Expected \"010\" to be decimal, got %d (%v)", n, err)
+	}
+	if n, err := ToLint(json.Number("9007199254740993")); err != nil || n != 9007199254740993 {
+		t.Errorf("Expected LINT beyond 2^53 to be exact, got %d (%v)", n, err)
+	}
+	if n, err := ToUlint("18446744073709551615"); err != nil || n != math.MaxUint64 {
+		t.Errorf("Expected max ULINT, got %d (%v)", n, err)
+	}
+
+	var eipErr *EipError
+	for name, convert := range map[string]func() error{
+		"SINT overflow":     func() error { _, err := ToSint(128); return err },
+		"INT overflow":      func() error { _, err := ToInt("40000"); return err },
+		"DINT overflow":     func() error { _, err := ToDint(3e9); return err },
+		"LINT overflow":     func() error { _, err := ToLint(uint64(math.MaxUint64)); return err },
+		"USINT negative":    func() error { _, err := ToUsint(-1); return err },
+		"UDINT overflow":    func() error { _, err := ToUdint(json.Number("4294967296")); return err },
+		"fraction":          func() error { _, err := ToDint(1.5); return err },
+		"NaN":               func() error { _, err := ToDint(math.NaN()); return err },
+		"text":              func() error { _, err := ToDint("12abc"); return err },
+		"bool":              func() error { _, err := ToDint(true); return err },
+		"BOOL out of range": func() error { _, err := ToBool(2); return err },
+		"REAL overflow":     func() error { _, err := ToReal(1e39); return err },
+	} {
+		if err := convert(); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagValue {
+			t.Errorf("%s: expected ErrInvalidTagValue, got %v", name, err)
+		}
+	}
+}
+
+// TestConvertValue tests conversion to a PlcValue of each kind of type
+func TestConvertValue(t *testing.T) {
+	for _, tc := range []struct {
+		dataType PlcDataType
+		in, want interface{}
+	}{
+		{Bool, "true", true},
+		{Bool, 1.0, true},
+		{Sint, "-5", int8(-5)},
+		{Uint, 65535, uint16(65535)},
+		{Real, "180.5", 180.5},
+		{Lreal, float32(0.5), 0.5},
+		{String, "hello", "hello"},
+		{Time, "1m30s", 90 * time.Second},
+		{Time, 1500 * time.Millisecond, 1500 * time.Millisecond},
+	} {
+		value, err := ConvertValue(tc.dataType, tc.in)
+		if err != nil || value.Type != tc.dataType || value.Value != tc.want {
+			t.Errorf("ConvertValue(%s, %#v) = %+v, %v; want %#v", tc.dataType, tc.in, value, err, tc.want)
+		}
+	}
+
+	if f, err := ToReal("-Inf"); err != nil || !math.IsInf(f, -1) {
+		t.Errorf("Expected REAL infinity to be kept, got %v (%v)", f, err)
+	}
+	if _, err := ConvertValue(String, 5); err == nil {
+		t.Error("Expected a number to be rejected for STRING")
+	}
+	if _, err := ConvertValue(Dt, time.Second); err == nil {
+		t.Error("Expected a duration to be rejected for DT")
+	}
+}
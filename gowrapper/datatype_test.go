@@ -0,0 +1,57 @@
+package ethernetip
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestParsePlcDataType tests canonical names and common synonyms
+func TestParsePlcDataType(t *testing.T) {
+	cases := map[string]PlcDataType{
+		"BOOL": Bool, "bool": Bool, "Boolean": Bool,
+		"DINT": Dint, "Dint": Dint, "int32": Dint,
+		"INT16": Int, "REAL": Real, "FLOAT": Real, "float_32": Real,
+		"LReal": Lreal, "double": Lreal, " String ": String, "UDINT": Udint,
+	}
+	for name, want := range cases {
+		got, err := ParsePlcDataType(name)
+		if err != nil || got != want {
+			t.Errorf("%q: got %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParsePlcDataType("quaternion"); err == nil {
+		t.Error("expected error for unknown type")
+	}
+}
+
+// TestDataTypeAliases tests registering aliases from configuration
+func TestDataTypeAliases(t *testing.T) {
+	if err := LoadDataTypeAliases(map[string]string{"Analog": "float"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ParsePlcDataType("ANALOG"); err != nil || got != Real {
+		t.Errorf("expected alias to resolve to REAL, got %v, %v", got, err)
+	}
+	if err := LoadDataTypeAliases(map[string]string{"bad": "nothing"}); err == nil {
+		t.Error("expected error for unknown alias target")
+	}
+}
+
+// TestPlcDataTypeJSON tests decoding data types from numbers and names
+func TestPlcDataTypeJSON(t *testing.T) {
+	var members []GroupMember
+	data := `[{"tag_name":"A","data_type":3},{"tag_name":"B","data_type":"Float"}]`
+	if err := json.Unmarshal([]byte(data), &members); err != nil {
+		t.Fatal(err)
+	}
+	if members[0].DataType != Dint || members[1].DataType != Real {
+		t.Errorf("unexpected types: %v %v", members[0].DataType, members[1].DataType)
+	}
+	var dt PlcDataType
+	if err := json.Unmarshal([]byte(`99`), &dt); err == nil {
+		t.Error("expected error for unknown numeric type")
+	}
+	if Dint.String() != "DINT" {
+		t.Errorf("unexpected name %s", Dint)
+	}
+}
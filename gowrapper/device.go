@@ -0,0 +1,106 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// CIP device types reported by the Identity Object
+const (
+	DeviceTypeCommunicationsAdapter uint16 = 0x0C
+	DeviceTypePLC                   uint16 = 0x0E
+)
+
+// DeviceIdentity is the Identity Object (class 0x01, instance 1) of a device
+type DeviceIdentity struct {
+	VendorID      uint16 `json:"vendor_id"`
+	DeviceType    uint16 `json:"device_type"`
+	ProductCode   uint16 `json:"product_code"`
+	RevisionMajor uint8  `json:"revision_major"`
+	RevisionMinor uint8  `json:"revision_minor"`
+	Status        uint16 `json:"status"`
+	SerialNumber  uint32 `json:"serial_number"`
+	ProductName   string `json:"product_name"`
+}
+
+// Revision returns the firmware revision as "major.minor"
+func (id *DeviceIdentity) Revision() string {
+	return fmt.Sprintf("%d.%03d", id.RevisionMajor, id.RevisionMinor)
+}
+
+// vendorNames are the vendors most often met on Logix networks, by CIP
+// vendor ID
+var vendorNames = map[uint16]string{
+	1:   "Rockwell Automation/Allen-Bradley",
+	40:  "WAGO Corporation",
+	90:  "HMS Industrial Networks AB",
+	283: "Hilscher GmbH",
+}
+
+// VendorName returns the name of the vendor, or "Vendor N" for vendors the
+// wrapper does not know
+func (id *DeviceIdentity) VendorName() string {
+	if name, ok := vendorNames[id.VendorID]; ok {
+		return name
+	}
+	return fmt.Sprintf("Vendor %d", id.VendorID)
+}
+
+// String describes the device for logs, with the serial number in hex as
+// RSLinx and Studio 5000 show it:
+// "1756-L83E/B rev 33.011 serial 00C0FFEE (Rockwell Automation/Allen-Bradley, type 0x0E, product 166)"
+func (id *DeviceIdentity) String() string {
+	return fmt.Sprintf("%s rev %s serial %08X (%s, type 0x%02X, product %d)",
+		id.ProductName, id.Revision(), id.SerialNumber, id.VendorName(), id.DeviceType, id.ProductCode)
+}
+
+// ReadIdentity reads the Identity Object of the target processor, following
+// the client's route path if one is set
+func (c *EipClient) ReadIdentity() (*DeviceIdentity, error) {
+	resp, err := c.SendCIPMessage(CIPServiceGetAttributesAll, classInstancePath(CIPClassIdentity, 1), nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseIdentity(resp.Data)
+}
+
+// GetDeviceInfo reads the Identity Object of the target processor, as
+// ReadIdentity does, and keeps it for DeviceInfo
+func (c *EipClient) GetDeviceInfo() (*DeviceIdentity, error) {
+	id, err := c.ReadIdentity()
+	if err != nil {
+		return nil, err
+	}
+	c.deviceInfo.Store(id)
+	return id, nil
+}
+
+// DeviceInfo returns the identity GetDeviceInfo read last, without talking to
+// the controller, or nil before the first successful call
+func (c *EipClient) DeviceInfo() *DeviceIdentity {
+	return c.deviceInfo.Load()
+}
+
+// parseIdentity decodes Get Attributes All data of the Identity Object
+func parseIdentity(data []byte) (*DeviceIdentity, error) {
+	if len(data) < 15 {
+		return nil, NewEipErrorWithDetails(ErrInvalidOperation, "Identity reply too short",
+			map[string]interface{}{"length": len(data)})
+	}
+	id := &DeviceIdentity{
+		VendorID:      binary.LittleEndian.Uint16(data[0:]),
+		DeviceType:    binary.LittleEndian.Uint16(data[2:]),
+		ProductCode:   binary.LittleEndian.Uint16(data[4:]),
+		RevisionMajor: data[6],
+		RevisionMinor: data[7],
+		Status:        binary.LittleEndian.Uint16(data[8:]),
+		SerialNumber:  binary.LittleEndian.Uint32(data[10:]),
+	}
+	nameLen := int(data[14])
+	if len(data) < 15+nameLen {
+		return nil, NewEipErrorWithDetails(ErrInvalidOperation, "Identity product name truncated",
+			map[string]interface{}{"length": len(data), "name_length": nameLen})
+	}
+	id.ProductName = string(data[15 : 15+nameLen])
+	return id, nil
+}
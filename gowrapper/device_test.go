@@ -0,0 +1,57 @@
+package ethernetip
+
+import (
+	"testing"
+)
+
+// TestParseIdentity tests decoding of Identity Object attributes
+func TestParseIdentity(t *testing.T) {
+	data := []byte{
+		0x01, 0x00, // Vendor: Rockwell
+		0x0E, 0x00, // Device type: PLC
+		0x6C, 0x00, // Product code
+		0x20, 0x0B, // Revision 32.11
+		0x60, 0x30, // Status
+		0x78, 0x56, 0x34, 0x12, // Serial
+		0x0C, '1', '7', '5', '6', '-', 'L', '8', '3', 'E', ' ', 'V', '1',
+	}
+	id, err := parseIdentity(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.VendorID != 1 || id.DeviceType != DeviceTypePLC || id.ProductCode != 0x6C || id.SerialNumber != 0x12345678 {
+		t.Errorf("unexpected identity: %+v", id)
+	}
+	if id.ProductName != "1756-L83E V1" || id.Revision() != "32.011" {
+		t.Errorf("unexpected name or revision: %q %s", id.ProductName, id.Revision())
+	}
+
+	if _, err := parseIdentity(data[:14]); err == nil {
+		t.Error("expected error for short reply")
+	}
+	if _, err := parseIdentity(data[:20]); err == nil {
+		t.Error("expected error for truncated product name")
+	}
+}
+
+// TestDeviceIdentityString tests the log description and vendor names
+func TestDeviceIdentityString(t *testing.T) {
+	id := &DeviceIdentity{VendorID: 1, DeviceType: DeviceTypePLC, ProductCode: 166, RevisionMajor: 33, RevisionMinor: 11,
+		SerialNumber: 0xC0FFEE, ProductName: "1756-L83E/B"}
+	want := "1756-L83E/B rev 33.011 serial 00C0FFEE (Rockwell Automation/Allen-Bradley, type 0x0E, product 166)"
+	if id.String() != want {
+		t.Errorf("Expected %q, got %q", want, id.String())
+	}
+	if name := (&DeviceIdentity{VendorID: 9999}).VendorName(); name != "Vendor 9999" {
+		t.Errorf("Unexpected vendor name %q", name)
+	}
+
+	c := &EipClient{}
+	if c.DeviceInfo() != nil {
+		t.Error("Expected no device info before GetDeviceInfo")
+	}
+	c.deviceInfo.Store(id)
+	if c.DeviceInfo() != id {
+		t.Error("Expected the stored device info")
+	}
+}
@@ -0,0 +1,249 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// cipStatusObjectDoesNotExist is the CIP general status for an instance that
+// does not exist
+const cipStatusObjectDoesNotExist byte = 0x16
+
+// DiagnosticsLayout locates the controller attributes Diagnostics reads.
+// Attribute numbers differ between controller families and firmware
+// revisions, so they can be changed with SetDiagnosticsLayout. A zero class
+// skips that part of the diagnostics.
+type DiagnosticsLayout struct {
+	// TaskClass is the Task object; instances 1 to MaxTasks are read until
+	// one does not exist
+	TaskClass    uint16 `json:"task_class"`
+	MaxTasks     int    `json:"max_tasks"`
+	LastScanAttr uint16 `json:"last_scan_attribute"` // DINT, microseconds
+	MaxScanAttr  uint16 `json:"max_scan_attribute"`  // DINT, microseconds
+
+	// UtilizationClass and UtilizationInstance hold the controller's CPU and
+	// communications utilization
+	UtilizationClass    uint16 `json:"utilization_class"`
+	UtilizationInstance uint32 `json:"utilization_instance"`
+	CPUAttr             uint16 `json:"cpu_attribute"`  // UINT, percent
+	CommAttr            uint16 `json:"comm_attribute"` // UINT, percent
+
+	// ForceClass and ForceInstance hold the controller's I/O force status,
+	// an INT with bit 0 set while forces are installed and bit 1 while they
+	// are enabled
+	ForceClass      uint16 `json:"force_class"`
+	ForceInstance   uint32 `json:"force_instance"`
+	ForceStatusAttr uint16 `json:"force_status_attribute"`
+}
+
+// DefaultDiagnosticsLayout is the layout of Logix controllers
+var DefaultDiagnosticsLayout = DiagnosticsLayout{
+	TaskClass:           0x70,
+	MaxTasks:            32,
+	LastScanAttr:        0x0B,
+	MaxScanAttr:         0x0A,
+	UtilizationClass:    0x8C,
+	UtilizationInstance: 1,
+	CPUAttr:             0x01,
+	CommAttr:            0x02,
+	ForceClass:          0x8C,
+	ForceInstance:       1,
+	ForceStatusAttr:     0x05,
+}
+
+// ControllerDiagnostics is a snapshot of controller load. Values the
+// controller does not report are nil or, for tasks, left out.
+type ControllerDiagnostics struct {
+	Timestamp time.Time `json:"timestamp"`
+	// CPUUtilization and CommUtilization are percentages
+	CPUUtilization  *float64          `json:"cpu_utilization,omitempty"`
+	CommUtilization *float64          `json:"comm_utilization,omitempty"`
+	Tasks           []TaskDiagnostics `json:"tasks"`
+	// Forces is the I/O force status
+	Forces *ForcesStatus `json:"forces,omitempty"`
+	// Session is the client's own reconnect history
+	Session SessionDiagnostics `json:"session"`
+}
+
+// TaskDiagnostics are the scan times of one task
+type TaskDiagnostics struct {
+	Instance uint32        `json:"instance"`
+	LastScan time.Duration `json:"last_scan"`
+	MaxScan  time.Duration `json:"max_scan"`
+	// AvgScan averages the last scan times seen by this client's Diagnostics
+	// calls; it is an estimate whose accuracy grows with the call rate
+	AvgScan time.Duration `json:"avg_scan"`
+}
+
+// scanAverages keeps the running mean of each task's last scan time
+type scanAverages struct {
+	mu    sync.Mutex
+	tasks map[uint32]*scanAverage
+}
+
+type scanAverage struct {
+	count int64
+	total time.Duration
+}
+
+// add records a scan time of a task and returns the task's mean
+func (s *scanAverages) add(instance uint32, scan time.Duration) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tasks == nil {
+		s.tasks = make(map[uint32]*scanAverage)
+	}
+	avg := s.tasks[instance]
+	if avg == nil {
+		avg = &scanAverage{}
+		s.tasks[instance] = avg
+	}
+	avg.count++
+	avg.total += scan
+	return avg.total / time.Duration(avg.count)
+}
+
+// SetDiagnosticsLayout changes the attributes Diagnostics reads
+func (c *EipClient) SetDiagnosticsLayout(layout DiagnosticsLayout) {
+	c.diagLayout.Store(&layout)
+}
+
+// DiagnosticsLayout returns the attributes Diagnostics reads
+func (c *EipClient) DiagnosticsLayout() DiagnosticsLayout {
+	if layout := c.diagLayout.Load(); layout != nil {
+		return *layout
+	}
+	return DefaultDiagnosticsLayout
+}
+
+// Diagnostics reads the controller's CPU and communications utilization and
+// the scan times of its tasks, so overload of the controller can be alarmed,
+// together with the client's reconnect history. Attributes the controller
+// does not support are left out rather than failing the call.
+func (c *EipClient) Diagnostics() (*ControllerDiagnostics, error) {
+	layout := c.DiagnosticsLayout()
+	diag := &ControllerDiagnostics{Timestamp: time.Now(), Tasks: []TaskDiagnostics{}, Session: c.SessionDiagnostics()}
+
+	if layout.UtilizationClass != 0 {
+		values, err := c.getAttributeList(layout.UtilizationClass, layout.UtilizationInstance,
+			[]attributeSpec{{layout.CPUAttr, 2}, {layout.CommAttr, 2}})
+		if err != nil && !objectMissing(err) {
+			return nil, err
+		}
+		if v, ok := values[layout.CPUAttr]; ok {
+			pct := float64(binary.LittleEndian.Uint16(v))
+			diag.CPUUtilization = &pct
+		}
+		if v, ok := values[layout.CommAttr]; ok {
+			pct := float64(binary.LittleEndian.Uint16(v))
+			diag.CommUtilization = &pct
+		}
+	}
+
+	if layout.ForceClass != 0 {
+		forces, err := c.readForcesStatus(layout)
+		if err != nil && !objectMissing(err) {
+			return nil, err
+		}
+		diag.Forces = forces
+	}
+
+	for instance := uint32(1); layout.TaskClass != 0 && int(instance) <= layout.MaxTasks; instance++ {
+		values, err := c.getAttributeList(layout.TaskClass, instance,
+			[]attributeSpec{{layout.LastScanAttr, 4}, {layout.MaxScanAttr, 4}})
+		if objectMissing(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		lastScan, hasLast := values[layout.LastScanAttr]
+		maxScan, hasMax := values[layout.MaxScanAttr]
+		if !hasLast && !hasMax {
+			continue
+		}
+		task := TaskDiagnostics{Instance: instance}
+		if hasLast {
+			task.LastScan = time.Duration(int32(binary.LittleEndian.Uint32(lastScan))) * time.Microsecond
+			task.AvgScan = c.scanAverages.add(instance, task.LastScan)
+		}
+		if hasMax {
+			task.MaxScan = time.Duration(int32(binary.LittleEndian.Uint32(maxScan))) * time.Microsecond
+		}
+		diag.Tasks = append(diag.Tasks, task)
+	}
+	return diag, nil
+}
+
+// attributeSpec is an attribute to read with Get Attribute List and the size
+// of its value
+type attributeSpec struct {
+	id   uint16
+	size int
+}
+
+// getAttributeList reads attributes of an object instance. Attributes the
+// object reports as unsupported are left out of the result.
+func (c *EipClient) getAttributeList(class uint16, instance uint32, attrs []attributeSpec) (map[uint16][]byte, error) {
+	req := binary.LittleEndian.AppendUint16(nil, uint16(len(attrs)))
+	for _, attr := range attrs {
+		req = binary.LittleEndian.AppendUint16(req, attr.id)
+	}
+	resp, err := c.SendCIPMessage(CIPServiceGetAttributeList, classInstancePath(class, instance), req)
+	if err != nil {
+		return nil, err
+	}
+	return parseAttributeList(resp.Data, attrs)
+}
+
+// parseAttributeList decodes a Get Attribute List reply: [count UINT] then
+// [id UINT][status UINT][value] per attribute, with no value for attributes
+// that failed
+func parseAttributeList(data []byte, attrs []attributeSpec) (map[uint16][]byte, error) {
+	if len(data) < 2 {
+		return nil, NewEipError(ErrInvalidValue, "attribute list reply too short")
+	}
+	sizes := make(map[uint16]int, len(attrs))
+	for _, attr := range attrs {
+		sizes[attr.id] = attr.size
+	}
+	values := make(map[uint16][]byte, len(attrs))
+	count := int(binary.LittleEndian.Uint16(data))
+	offset := 2
+	for i := 0; i < count; i++ {
+		if offset+4 > len(data) {
+			return nil, NewEipError(ErrInvalidValue, "attribute list reply truncated")
+		}
+		id := binary.LittleEndian.Uint16(data[offset:])
+		status := binary.LittleEndian.Uint16(data[offset+2:])
+		offset += 4
+		if status != 0 {
+			continue
+		}
+		size, ok := sizes[id]
+		if !ok {
+			return nil, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("unexpected attribute %d in reply", id),
+				map[string]interface{}{"attribute": id})
+		}
+		if offset+size > len(data) {
+			return nil, NewEipError(ErrInvalidValue, "attribute list reply truncated")
+		}
+		values[id] = data[offset : offset+size]
+		offset += size
+	}
+	return values, nil
+}
+
+// objectMissing reports whether err is a CIP reply saying the addressed class
+// or instance does not exist
+func objectMissing(err error) bool {
+	var eipErr *EipError
+	if !errors.As(err, &eipErr) {
+		return false
+	}
+	status, _ := eipErr.Details["cip_status"].(byte)
+	return status == CIPStatusPathUnknown || status == cipStatusObjectDoesNotExist
+}
@@ -0,0 +1,75 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// TestParseAttributeList tests decoding a Get Attribute List reply with an
+// unsupported attribute
+func TestParseAttributeList(t *testing.T) {
+	attrs := []attributeSpec{{0x0B, 4}, {0x0A, 4}}
+	data := binary.LittleEndian.AppendUint16(nil, 2)
+	data = binary.LittleEndian.AppendUint16(data, 0x0B)
+	data = binary.LittleEndian.AppendUint16(data, 0)
+	data = binary.LittleEndian.AppendUint32(data, 1500)
+	data = binary.LittleEndian.AppendUint16(data, 0x0A)
+	data = binary.LittleEndian.AppendUint16(data, 0x14) // Attribute not supported
+
+	values, err := parseAttributeList(data, attrs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v, ok := values[0x0B]; !ok || binary.LittleEndian.Uint32(v) != 1500 {
+		t.Errorf("Unexpected attribute 0x0B: %v", values[0x0B])
+	}
+	if _, ok := values[0x0A]; ok {
+		t.Error("Expected the unsupported attribute to be left out")
+	}
+
+	if _, err := parseAttributeList(data[:8], attrs); err == nil {
+		t.Error("Expected error for a truncated value")
+	}
+	if _, err := parseAttributeList(data, attrs[1:]); err == nil {
+		t.Error("Expected error for an attribute that was not requested")
+	}
+}
+
+// TestScanAverages tests the running mean of task scan times
+func TestScanAverages(t *testing.T) {
+	var s scanAverages
+	s.add(1, 2*time.Millisecond)
+	if avg := s.add(1, 4*time.Millisecond); avg != 3*time.Millisecond {
+		t.Errorf("Expected 3ms, got %v", avg)
+	}
+	if avg := s.add(2, time.Millisecond); avg != time.Millisecond {
+		t.Errorf("Expected tasks to be averaged separately, got %v", avg)
+	}
+}
+
+// TestObjectMissing tests recognizing replies for objects that do not exist
+func TestObjectMissing(t *testing.T) {
+	missing := cipStatusError(CIPServiceGetAttributeList, &CIPResponse{GeneralStatus: cipStatusObjectDoesNotExist})
+	if !objectMissing(missing) {
+		t.Error("Expected status 0x16 to mean a missing object")
+	}
+	busy := cipStatusError(CIPServiceGetAttributeList, &CIPResponse{GeneralStatus: 0x02})
+	if objectMissing(busy) || objectMissing(nil) {
+		t.Error("Expected other errors not to mean a missing object")
+	}
+}
+
+// TestDiagnosticsLayout tests overriding the diagnostics attributes
+func TestDiagnosticsLayout(t *testing.T) {
+	c := &EipClient{}
+	if c.DiagnosticsLayout() != DefaultDiagnosticsLayout {
+		t.Error("Expected the default layout")
+	}
+	layout := DefaultDiagnosticsLayout
+	layout.UtilizationClass = 0
+	c.SetDiagnosticsLayout(layout)
+	if c.DiagnosticsLayout().UtilizationClass != 0 {
+		t.Error("Expected the layout to be replaced")
+	}
+}
@@ -0,0 +1,154 @@
+// Package discovery lists the tags of a Logix controller by walking its
+// Symbol Object. It decodes the replies itself and sends requests through a
+// PageFunc, so it has no dependency on the native library;
+// EipClient.DiscoverTagDatabase runs it over the client's session and
+// indexes the tags in a TagDatabase. The ethernetip package re-exports
+// TagInfo, so existing code is unaffected.
+package discovery
+
+import (
+	"context"
+	"encoding/binary"
+	"strings"
+)
+
+// Symbol type word layout (Logix Symbol Object attribute 2)
+const (
+	SymbolTypeStructBit = 0x8000
+	SymbolTypeSystemBit = 0x1000
+	SymbolTypeCodeMask  = 0x0FFF
+	SymbolTypeDimsShift = 13
+	SymbolTypeDimsMask  = 0x3
+)
+
+// SymbolListRequest is the request data of the Get Instance Attribute List
+// requests sent by Walk: attribute count 2, attribute 1 (name) and
+// attribute 2 (symbol type)
+var SymbolListRequest = []byte{0x02, 0x00, 0x01, 0x00, 0x02, 0x00}
+
+// TagInfo describes a tag found during discovery
+type TagInfo struct {
+	Name       string `json:"name"`
+	InstanceID uint32 `json:"instance_id"`
+	SymbolType uint16 `json:"symbol_type"`
+	// Program is the program name for program-scoped tags, empty for controller scope
+	Program string `json:"program,omitempty"`
+}
+
+// TypeCode returns the CIP atomic type code, or the template instance ID for structures
+func (t TagInfo) TypeCode() uint16 {
+	return t.SymbolType & SymbolTypeCodeMask
+}
+
+// IsStructure reports whether the tag is a structure (UDT or predefined type)
+func (t TagInfo) IsStructure() bool {
+	return t.SymbolType&SymbolTypeStructBit != 0
+}
+
+// IsSystem reports whether the tag is a controller-internal system tag
+func (t TagInfo) IsSystem() bool {
+	return t.SymbolType&SymbolTypeSystemBit != 0
+}
+
+// Dimensions returns the number of array dimensions (0 for scalars)
+func (t TagInfo) Dimensions() int {
+	return int(t.SymbolType>>SymbolTypeDimsShift) & SymbolTypeDimsMask
+}
+
+// PageFunc sends one Get Instance Attribute List request (SymbolListRequest)
+// for the Symbol Object instances of the controller scope (program empty)
+// or of a program scope, starting at instance. It returns the reply data
+// and whether the reply was a partial transfer, so more instances follow.
+type PageFunc func(program string, instance uint32) (data []byte, partial bool, err error)
+
+// Walk lists every controller- and program-scoped tag, calling progress
+// with the running total after each reply. Cancel ctx to abort.
+func Walk(ctx context.Context, page PageFunc, progress func(found int)) ([]TagInfo, error) {
+	found := 0
+	report := func(n int) {
+		found += n
+		if progress != nil {
+			progress(found)
+		}
+	}
+
+	tags, err := List(ctx, page, "", report)
+	if err != nil {
+		return nil, err
+	}
+
+	all := tags
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag.Name, "Program:") {
+			continue
+		}
+		program := strings.TrimPrefix(tag.Name, "Program:")
+		programTags, err := List(ctx, page, program, report)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, programTags...)
+	}
+	return all, nil
+}
+
+// List pages through the Symbol Object instances of the controller scope
+// (program empty) or of one program scope, calling report, if not nil, with
+// the number of tags in each reply
+func List(ctx context.Context, page PageFunc, program string, report func(n int)) ([]TagInfo, error) {
+	var tags []TagInfo
+	instance := uint32(0)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		data, partial, err := page(program, instance)
+		if err != nil {
+			return nil, err
+		}
+
+		found, last := ParseSymbolList(data, program)
+		tags = append(tags, found...)
+		if report != nil {
+			report(len(found))
+		}
+
+		if !partial || len(found) == 0 {
+			return tags, nil
+		}
+		instance = last + 1
+	}
+}
+
+// ParseSymbolList decodes a Get Instance Attribute List reply for attributes 1
+// and 2. Each entry is [instance UDINT][name length UINT][name][symbol type UINT].
+// It returns the decoded tags and the last instance ID seen.
+func ParseSymbolList(data []byte, program string) ([]TagInfo, uint32) {
+	var tags []TagInfo
+	var last uint32
+	for offset := 0; offset+6 <= len(data); {
+		instance := binary.LittleEndian.Uint32(data[offset:])
+		nameLen := int(binary.LittleEndian.Uint16(data[offset+4:]))
+		offset += 6
+		if offset+nameLen+2 > len(data) {
+			break
+		}
+		name := string(data[offset : offset+nameLen])
+		offset += nameLen
+		symbolType := binary.LittleEndian.Uint16(data[offset:])
+		offset += 2
+
+		last = instance
+		if program != "" {
+			name = "Program:" + program + "." + name
+		}
+		tags = append(tags, TagInfo{
+			Name:       name,
+			InstanceID: instance,
+			SymbolType: symbolType,
+			Program:    program,
+		})
+	}
+	return tags, last
+}
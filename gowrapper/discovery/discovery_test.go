@@ -0,0 +1,94 @@
+package discovery
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// symbolEntry encodes one Get Instance Attribute List entry for attributes 1 and 2
+func symbolEntry(instance uint32, name string, symbolType uint16) []byte {
+	b := make([]byte, 6, 8+len(name))
+	binary.LittleEndian.PutUint32(b, instance)
+	binary.LittleEndian.PutUint16(b[4:], uint16(len(name)))
+	b = append(b, name...)
+	return binary.LittleEndian.AppendUint16(b, symbolType)
+}
+
+// TestParseSymbolList tests decoding of symbol object listings
+func TestParseSymbolList(t *testing.T) {
+	var data []byte
+	data = append(data, symbolEntry(3, "Speed", 0x00CA)...)
+	data = append(data, symbolEntry(9, "Recipe", 0x8000|0x2000|0x0123)...)
+	data = append(data, 0x01, 0x02) // trailing garbage is ignored
+
+	tags, last := ParseSymbolList(data, "")
+	if len(tags) != 2 || last != 9 {
+		t.Fatalf("Expected 2 tags ending at instance 9, got %d tags, last %d", len(tags), last)
+	}
+	if tags[0].Name != "Speed" || tags[0].TypeCode() != 0xCA || tags[0].IsStructure() {
+		t.Errorf("Unexpected first tag: %+v", tags[0])
+	}
+	if !tags[1].IsStructure() || tags[1].Dimensions() != 1 || tags[1].TypeCode() != 0x123 {
+		t.Errorf("Unexpected second tag: %+v", tags[1])
+	}
+
+	scoped, _ := ParseSymbolList(symbolEntry(1, "Step", 0x00C4), "Main")
+	if scoped[0].Name != "Program:Main.Step" || scoped[0].Program != "Main" {
+		t.Errorf("Unexpected program-scoped tag: %+v", scoped[0])
+	}
+}
+
+// TestWalk tests paging through the controller scope and then each program
+func TestWalk(t *testing.T) {
+	type request struct {
+		program  string
+		instance uint32
+	}
+	var requests []request
+	page := func(program string, instance uint32) ([]byte, bool, error) {
+		requests = append(requests, request{program, instance})
+		switch {
+		case program == "" && instance == 0:
+			return symbolEntry(1, "Speed", 0x00C4), true, nil
+		case program == "":
+			return symbolEntry(5, "Program:Main", 0x1068), false, nil
+		default:
+			return symbolEntry(1, "Step", 0x00C4), false, nil
+		}
+	}
+
+	var progress []int
+	tags, err := Walk(context.Background(), page, func(found int) { progress = append(progress, found) })
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	want := []request{{"", 0}, {"", 2}, {"Main", 0}}
+	if len(requests) != len(want) {
+		t.Fatalf("Expected requests %v, got %v", want, requests)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("Expected request %d to be %v, got %v", i, want[i], requests[i])
+		}
+	}
+	if len(tags) != 3 || tags[2].Name != "Program:Main.Step" || tags[2].Program != "Main" {
+		t.Errorf("Expected Speed, Program:Main and Program:Main.Step, got %+v", tags)
+	}
+	if len(progress) != 3 || progress[2] != 3 {
+		t.Errorf("Expected progress 1, 2, 3, got %v", progress)
+	}
+
+	failure := errors.New("offline")
+	_, err = Walk(context.Background(), func(string, uint32) ([]byte, bool, error) { return nil, false, failure }, nil)
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected %v, got %v", failure, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Walk(ctx, page, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
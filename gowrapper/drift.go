@@ -0,0 +1,69 @@
+package ethernetip
+
+import "strings"
+
+// TagDrift is the difference between the tags of a project and the tags found
+// online
+type TagDrift struct {
+	// Added lists the tags the controller has and the project does not
+	Added []TagListEntry `json:"added"`
+	// Removed lists the tags of the project the controller does not have
+	Removed []TagListEntry `json:"removed"`
+	// Retyped lists the tags whose type or dimensions differ
+	Retyped []RetypedTag `json:"retyped"`
+}
+
+// RetypedTag is a tag with a different type online than in the project
+type RetypedTag struct {
+	Name    string       `json:"name"`
+	Project TagListEntry `json:"project"`
+	Online  TagListEntry `json:"online"`
+}
+
+// Empty reports whether the project and the controller agree
+func (d *TagDrift) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Retyped) == 0
+}
+
+// CompareTagLists compares the tags of a project with the tags found online.
+// Names and type names are matched ignoring case, as the controller does. An
+// array size of 0, which TagList reports when a size could not be read, matches
+// any size.
+func CompareTagLists(project, online []TagListEntry) *TagDrift {
+	drift := &TagDrift{}
+	onlineByName := make(map[string]TagListEntry, len(online))
+	for _, e := range online {
+		onlineByName[strings.ToUpper(e.Name)] = e
+	}
+	projectNames := make(map[string]bool, len(project))
+	for _, p := range project {
+		key := strings.ToUpper(p.Name)
+		projectNames[key] = true
+		o, ok := onlineByName[key]
+		switch {
+		case !ok:
+			drift.Removed = append(drift.Removed, p)
+		case !sameType(p, o):
+			drift.Retyped = append(drift.Retyped, RetypedTag{Name: p.Name, Project: p, Online: o})
+		}
+	}
+	for _, o := range online {
+		if !projectNames[strings.ToUpper(o.Name)] {
+			drift.Added = append(drift.Added, o)
+		}
+	}
+	return drift
+}
+
+// sameType reports whether two entries have the same type and dimensions
+func sameType(a, b TagListEntry) bool {
+	if !strings.EqualFold(a.Type, b.Type) || len(a.Dimensions) != len(b.Dimensions) {
+		return false
+	}
+	for i, size := range a.Dimensions {
+		if size != 0 && b.Dimensions[i] != 0 && size != b.Dimensions[i] {
+			return false
+		}
+	}
+	return true
+}
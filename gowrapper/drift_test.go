@@ -0,0 +1,40 @@
+package ethernetip
+
+import "testing"
+
+// TestCompareTagLists tests matching names and types ignoring case and
+// unknown array sizes
+func TestCompareTagLists(t *testing.T) {
+	project := []TagListEntry{
+		{Name: "Speed", Type: "DINT"},
+		{Name: "Recipe", Type: "RECIPE"},
+		{Name: "Values", Type: "REAL", Dimensions: []int{10}},
+		{Name: "Grid", Type: "INT", Dimensions: []int{4, 3}},
+		{Name: "Old", Type: "BOOL"},
+	}
+	online := []TagListEntry{
+		{Name: "SPEED", Type: "dint"},
+		{Name: "Recipe", Type: "RECIPE_V2"},
+		{Name: "Values", Type: "REAL", Dimensions: []int{0}},
+		{Name: "Grid", Type: "INT", Dimensions: []int{4, 4}},
+		{Name: "New", Type: "DINT"},
+	}
+
+	drift := CompareTagLists(project, online)
+	if drift.Empty() {
+		t.Fatal("Expected drift")
+	}
+	if len(drift.Added) != 1 || drift.Added[0].Name != "New" {
+		t.Errorf("Unexpected added tags %+v", drift.Added)
+	}
+	if len(drift.Removed) != 1 || drift.Removed[0].Name != "Old" {
+		t.Errorf("Unexpected removed tags %+v", drift.Removed)
+	}
+	if len(drift.Retyped) != 2 || drift.Retyped[0].Name != "Recipe" || drift.Retyped[1].Name != "Grid" {
+		t.Errorf("Unexpected retyped tags %+v", drift.Retyped)
+	}
+
+	if !CompareTagLists(project, project).Empty() {
+		t.Error("Expected no drift comparing a list with itself")
+	}
+}
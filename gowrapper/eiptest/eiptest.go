@@ -0,0 +1,149 @@
+// Package eiptest provides an in-memory controller for testing code built on
+// the EtherNet/IP client without a PLC. FakeClient implements the
+// ethernetip.Client interface, so it can drive a Poller, a Hub or a
+// WriteQueue, and it depends only on the types package, so tests that use it
+// on its own do not need the native library.
+package eiptest
+
+import (
+	"sync"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// FakeClient is an in-memory controller holding one value per tag. It is safe
+// for concurrent use.
+type FakeClient struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+	errs   map[string]error
+	err    error
+	reads  map[string]int
+	writes map[string]int
+}
+
+// NewFakeClient creates a FakeClient with no tags
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		values: make(map[string]interface{}),
+		errs:   make(map[string]error),
+		reads:  make(map[string]int),
+		writes: make(map[string]int),
+	}
+}
+
+// ReadValue returns the value of tagName with the requested type. A tag that
+// was never set fails with ErrTagNotFound.
+func (f *FakeClient) ReadValue(tagName string, dataType types.PlcDataType) (*types.PlcValue, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reads[tagName]++
+	if err := f.failure(tagName); err != nil {
+		return nil, err
+	}
+	v, ok := f.values[tagName]
+	if !ok {
+		return nil, types.NewEipErrorWithDetails(types.ErrTagNotFound, "tag not found",
+			map[string]interface{}{"tag_name": tagName})
+	}
+	return &types.PlcValue{Type: dataType, Value: v}, nil
+}
+
+// WriteValue stores the value of tagName
+func (f *FakeClient) WriteValue(tagName string, value *types.PlcValue) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writes[tagName]++
+	if err := f.failure(tagName); err != nil {
+		return err
+	}
+	f.values[tagName] = value.Value
+	return nil
+}
+
+// ReadMultipleTags reads each of tags like ReadValue, failing on the first
+// tag that cannot be read
+func (f *FakeClient) ReadMultipleTags(tags map[string]types.PlcDataType) (map[string]*types.PlcValue, error) {
+	results := make(map[string]*types.PlcValue, len(tags))
+	for tagName, dataType := range tags {
+		value, err := f.ReadValue(tagName, dataType)
+		if err != nil {
+			return nil, err
+		}
+		results[tagName] = value
+	}
+	return results, nil
+}
+
+// ReadTags reads each of tags like ReadValue, returning the values read and
+// the error of each tag that failed
+func (f *FakeClient) ReadTags(tags map[string]types.PlcDataType) (map[string]*types.PlcValue, map[string]error) {
+	results := make(map[string]*types.PlcValue, len(tags))
+	errs := make(map[string]error)
+	for tagName, dataType := range tags {
+		value, err := f.ReadValue(tagName, dataType)
+		if err != nil {
+			errs[tagName] = err
+			continue
+		}
+		results[tagName] = value
+	}
+	return results, errs
+}
+
+// failure returns the error set for tagName or for every tag. f.mu must be held.
+func (f *FakeClient) failure(tagName string) error {
+	if err, ok := f.errs[tagName]; ok {
+		return err
+	}
+	return f.err
+}
+
+// Set sets the value of tagName, as if the controller had changed it
+func (f *FakeClient) Set(tagName string, value interface{}) {
+	f.mu.Lock()
+	f.values[tagName] = value
+	f.mu.Unlock()
+}
+
+// Value returns the value of tagName and whether it has been set
+func (f *FakeClient) Value(tagName string) (interface{}, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.values[tagName]
+	return v, ok
+}
+
+// SetErr makes every read and write fail with err until it is cleared with
+// nil, as if the connection had been lost
+func (f *FakeClient) SetErr(err error) {
+	f.mu.Lock()
+	f.err = err
+	f.mu.Unlock()
+}
+
+// SetTagErr makes reads and writes of tagName fail with err until it is
+// cleared with nil
+func (f *FakeClient) SetTagErr(tagName string, err error) {
+	f.mu.Lock()
+	if err == nil {
+		delete(f.errs, tagName)
+	} else {
+		f.errs[tagName] = err
+	}
+	f.mu.Unlock()
+}
+
+// Reads returns the number of reads of tagName, including failed ones
+func (f *FakeClient) Reads(tagName string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reads[tagName]
+}
+
+// Writes returns the number of writes of tagName, including failed ones
+func (f *FakeClient) Writes(tagName string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.writes[tagName]
+}
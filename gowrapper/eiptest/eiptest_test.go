@@ -0,0 +1,53 @@
+package eiptest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// TestFakeClient tests reads, writes and injected errors
+func TestFakeClient(t *testing.T) {
+	f := NewFakeClient()
+	var eipErr *types.EipError
+	if _, err := f.ReadValue("Speed", types.Dint); !errors.As(err, &eipErr) || eipErr.Code != types.ErrTagNotFound {
+		t.Errorf("Expected ErrTagNotFound for an unset tag, got %v", err)
+	}
+
+	if err := f.WriteValue("Speed", &types.PlcValue{Type: types.Dint, Value: int32(1500)}); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := f.ReadValue("Speed", types.Dint); err != nil || v.Value != int32(1500) || v.Type != types.Dint {
+		t.Errorf("Expected the written value back, got %+v (%v)", v, err)
+	}
+	if f.Reads("Speed") != 2 || f.Writes("Speed") != 1 {
+		t.Errorf("Unexpected counts %d reads, %d writes", f.Reads("Speed"), f.Writes("Speed"))
+	}
+
+	offline := errors.New("offline")
+	f.Set("Temp", 21.5)
+	f.SetTagErr("Temp", offline)
+	if _, err := f.ReadValue("Temp", types.Real); err != offline {
+		t.Errorf("Expected the tag error, got %v", err)
+	}
+	if _, err := f.ReadValue("Speed", types.Dint); err != nil {
+		t.Errorf("Expected other tags to be readable, got %v", err)
+	}
+	f.SetTagErr("Temp", nil)
+	f.SetErr(offline)
+	if _, err := f.ReadMultipleTags(map[string]types.PlcDataType{"Speed": types.Dint}); err != offline {
+		t.Errorf("Expected the client error, got %v", err)
+	}
+	f.SetErr(nil)
+	values, err := f.ReadMultipleTags(map[string]types.PlcDataType{"Speed": types.Dint, "Temp": types.Real})
+	if err != nil || values["Temp"].Value != 21.5 {
+		t.Errorf("Unexpected values %v (%v)", values, err)
+	}
+
+	f.SetTagErr("Temp", offline)
+	values, errs := f.ReadTags(map[string]types.PlcDataType{"Speed": types.Dint, "Temp": types.Real})
+	if len(values) != 1 || values["Speed"] == nil || errs["Temp"] != offline {
+		t.Errorf("Expected Speed with the error of Temp, got %v %v", values, errs)
+	}
+}
@@ -191,6 +191,43 @@ func TestReadWriteValue(t *testing.T) {
 	if readStringValue.Value != "Hello, World!" {
 		t.Errorf("Expected 'Hello, World!', got %v", readStringValue.Value)
 	}
+
+	// Test unsigned and LREAL values
+	unsigned := map[string]*PlcValue{
+		"TestUsint": {Type: Usint, Value: uint8(200)},
+		"TestUint":  {Type: Uint, Value: uint16(60000)},
+		"TestUdint": {Type: Udint, Value: uint32(4000000000)},
+		"TestUlint": {Type: Ulint, Value: uint64(1) << 63},
+		"TestLreal": {Type: Lreal, Value: 2.718281828459045},
+	}
+	for tagName, value := range unsigned {
+		if err := client.WriteValue(tagName, value); err != nil {
+			t.Fatalf("Failed to write %s: %v", tagName, err)
+		}
+		read, err := client.ReadValue(tagName, value.Type)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", tagName, err)
+		}
+		if read.Value != value.Value {
+			t.Errorf("%s: expected %v, got %v", tagName, value.Value, read.Value)
+		}
+	}
+}
+
+// TestWriteValueTypeCheck tests that unsigned and LREAL writes require the matching Go type
+func TestWriteValueTypeCheck(t *testing.T) {
+	client := &EipClient{}
+	for _, value := range []*PlcValue{
+		{Type: Usint, Value: 200},
+		{Type: Uint, Value: int16(1)},
+		{Type: Udint, Value: int32(1)},
+		{Type: Ulint, Value: int64(1)},
+		{Type: Lreal, Value: float32(1)},
+	} {
+		if err := client.WriteValue("Tag", value); err == nil {
+			t.Errorf("Expected error for %v with %T", value.Type, value.Value)
+		}
+	}
 }
 
 // TestBatchOperations tests batch read and write operations
@@ -551,3 +588,111 @@ func TestIntegration(t *testing.T) {
 		}
 	}
 }
+
+// TestScalarBufNoAlloc verifies the scalar read fast path does not allocate
+// when converting tag names for the native call
+func TestScalarBufNoAlloc(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _ := getScalarBuf("Program:MainProgram.Motor1.Speed")
+		putScalarBuf(buf)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected 0 allocs per tag name conversion, got %v", allocs)
+	}
+}
+
+// BenchmarkScalarBuf measures the cost of preparing a tag name for a scalar read
+func BenchmarkScalarBuf(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf, _ := getScalarBuf("Program:MainProgram.Motor1.Speed")
+		putScalarBuf(buf)
+	}
+}
+
+// benchmarkScalarRead runs read against a live PLC and reports allocations
+func benchmarkScalarRead(b *testing.B, read func(c *EipClient) error) {
+	if getTestPlcIP() == "" {
+		b.Skip("Skipping benchmark: No PLC available. Set TEST_PLC_IP environment variable to run this benchmark.")
+	}
+
+	client, err := NewClient(getTestPlcIP())
+	if err != nil {
+		b.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := read(client); err != nil {
+			b.Fatalf("Read failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkReadBool benchmarks the BOOL read fast path
+func BenchmarkReadBool(b *testing.B) {
+	benchmarkScalarRead(b, func(c *EipClient) error {
+		_, err := c.ReadBool("TestBool")
+		return err
+	})
+}
+
+// BenchmarkReadInt benchmarks the INT read fast path
+func BenchmarkReadInt(b *testing.B) {
+	benchmarkScalarRead(b, func(c *EipClient) error {
+		_, err := c.ReadInt("TestInt")
+		return err
+	})
+}
+
+// BenchmarkReadDint benchmarks the DINT read fast path
+func BenchmarkReadDint(b *testing.B) {
+	benchmarkScalarRead(b, func(c *EipClient) error {
+		_, err := c.ReadDint("TestDint")
+		return err
+	})
+}
+
+// BenchmarkReadReal benchmarks the REAL read fast path
+func BenchmarkReadReal(b *testing.B) {
+	benchmarkScalarRead(b, func(c *EipClient) error {
+		_, err := c.ReadReal("TestReal")
+		return err
+	})
+}
+
+// TestUpdate tests the read-modify-write helpers
+func TestUpdate(t *testing.T) {
+	skipIfNoPlc(t)
+
+	client, err := NewClient(getTestPlcIP())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	err = client.WriteDint("TestDint", 41)
+	if err != nil {
+		t.Fatalf("Failed to write dint value: %v", err)
+	}
+
+	written, err := client.UpdateWithRetry("TestDint", Dint, func(old interface{}) interface{} {
+		return old.(int32) + 1
+	}, 3)
+	if err != nil {
+		t.Fatalf("Failed to update tag: %v", err)
+	}
+	if written.Value != int32(42) {
+		t.Errorf("Expected 42 to be written, got %v", written.Value)
+	}
+
+	value, err := client.ReadDint("TestDint")
+	if err != nil {
+		t.Fatalf("Failed to read dint value: %v", err)
+	}
+	if value != 42 {
+		t.Errorf("Expected 42, got %d", value)
+	}
+}
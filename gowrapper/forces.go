@@ -0,0 +1,80 @@
+package ethernetip
+
+import "encoding/binary"
+
+// Force status bits
+const (
+	forcesInstalledBit = 0x0001
+	forcesEnabledBit   = 0x0002
+)
+
+// ForcesStatus is the I/O force status of a controller
+type ForcesStatus struct {
+	// Installed reports whether any force values are set
+	Installed bool `json:"installed"`
+	// Enabled reports whether forces are enabled, so installed forces
+	// override the program and the I/O
+	Enabled bool   `json:"enabled"`
+	Raw     uint16 `json:"raw"`
+}
+
+// Active reports whether forces are installed and enabled, the state
+// production systems alarm on
+func (f ForcesStatus) Active() bool {
+	return f.Installed && f.Enabled
+}
+
+// String summarises the status, e.g. "installed, enabled"
+func (f ForcesStatus) String() string {
+	switch {
+	case f.Active():
+		return "installed, enabled"
+	case f.Installed:
+		return "installed, disabled"
+	case f.Enabled:
+		return "none installed, enabled"
+	default:
+		return "none"
+	}
+}
+
+// ParseForcesStatus decodes a force status word
+func ParseForcesStatus(word uint16) ForcesStatus {
+	return ForcesStatus{
+		Installed: word&forcesInstalledBit != 0,
+		Enabled:   word&forcesEnabledBit != 0,
+		Raw:       word,
+	}
+}
+
+// GetForcesStatus reads whether I/O forces are installed and enabled on the
+// controller, from the attribute set with SetDiagnosticsLayout
+func (c *EipClient) GetForcesStatus() (ForcesStatus, error) {
+	layout := c.DiagnosticsLayout()
+	if layout.ForceClass == 0 {
+		return ForcesStatus{}, NewEipError(ErrInvalidOperation, "no force status attribute in the diagnostics layout")
+	}
+	forces, err := c.readForcesStatus(layout)
+	if err != nil {
+		return ForcesStatus{}, err
+	}
+	if forces == nil {
+		return ForcesStatus{}, NewEipError(ErrInvalidOperation, "the controller did not report its force status")
+	}
+	return *forces, nil
+}
+
+// readForcesStatus reads the force status, returning nil if the controller
+// does not support the attribute
+func (c *EipClient) readForcesStatus(layout DiagnosticsLayout) (*ForcesStatus, error) {
+	values, err := c.getAttributeList(layout.ForceClass, layout.ForceInstance, []attributeSpec{{layout.ForceStatusAttr, 2}})
+	if err != nil {
+		return nil, err
+	}
+	v, ok := values[layout.ForceStatusAttr]
+	if !ok {
+		return nil, nil
+	}
+	forces := ParseForcesStatus(binary.LittleEndian.Uint16(v))
+	return &forces, nil
+}
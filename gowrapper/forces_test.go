@@ -0,0 +1,31 @@
+package ethernetip
+
+import "testing"
+
+// TestParseForcesStatus tests decoding the force status bits
+func TestParseForcesStatus(t *testing.T) {
+	tests := []struct {
+		word   uint16
+		active bool
+		text   string
+	}{
+		{0x0000, false, "none"},
+		{0x0001, false, "installed, disabled"},
+		{0x0002, false, "none installed, enabled"},
+		{0x0003, true, "installed, enabled"},
+	}
+	for _, tt := range tests {
+		f := ParseForcesStatus(tt.word)
+		if f.Active() != tt.active || f.String() != tt.text || f.Raw != tt.word {
+			t.Errorf("0x%04X: got %+v %q", tt.word, f, f.String())
+		}
+	}
+
+	c := &EipClient{}
+	layout := DefaultDiagnosticsLayout
+	layout.ForceClass = 0
+	c.SetDiagnosticsLayout(layout)
+	if _, err := c.GetForcesStatus(); err == nil {
+		t.Error("Expected an error without a force status attribute")
+	}
+}
@@ -0,0 +1,143 @@
+package ethernetip
+
+/*
+// Forward Open parameters
+extern int eip_set_forward_open_params(int client_id, unsigned int rpi_us, int connection_size, int timeout_multiplier, int transport_trigger);
+*/
+import "C"
+import (
+	"fmt"
+	"time"
+)
+
+// TransportTrigger is the production trigger requested for a connection
+type TransportTrigger int
+
+// Production triggers, with their CIP codes
+const (
+	TriggerCyclic        TransportTrigger = 0
+	TriggerChangeOfState TransportTrigger = 1
+	TriggerApplication   TransportTrigger = 2
+)
+
+// String returns the name of the trigger
+func (t TransportTrigger) String() string {
+	switch t {
+	case TriggerCyclic:
+		return "cyclic"
+	case TriggerChangeOfState:
+		return "change_of_state"
+	case TriggerApplication:
+		return "application"
+	default:
+		return fmt.Sprintf("TransportTrigger(%d)", int(t))
+	}
+}
+
+// ForwardOpenParams tunes the Forward Open of the Class 3 connection used in
+// connected messaging mode (see SetMessagingMode). A GigE controller on the
+// local network does well with the defaults. A controller behind a slow
+// serial or DH+ bridge needs a longer RPI and timeout and a smaller
+// connection:
+//
+//	params := ethernetip.DefaultForwardOpenParams()
+//	params.RPI = time.Second
+//	params.ConnectionSize = 244
+//	params.TimeoutMultiplier = 7
+type ForwardOpenParams struct {
+	// RPI is the requested packet interval, in whole microseconds
+	RPI time.Duration `json:"rpi"`
+	// ConnectionSize is the size of the connection in bytes, up to
+	// MaxConnectionSize. Sizes above MaxForwardOpenSize are requested with
+	// Large Forward Open, falling back to FallbackConnectionSize when the
+	// controller rejects it. SetMaxPacketSize caps the size.
+	ConnectionSize int `json:"connection_size"`
+	// TimeoutMultiplier, 0-7, sets how long the connection may go without
+	// traffic before the controller closes it: RPI × 4 × 2^TimeoutMultiplier
+	TimeoutMultiplier int `json:"timeout_multiplier"`
+	// Trigger is the production trigger; explicit messaging normally uses
+	// TriggerApplication
+	Trigger TransportTrigger `json:"trigger"`
+}
+
+// Limits of the Forward Open parameters
+const (
+	MaxConnectionSize      = 4000 // Largest connection requested with Large Forward Open
+	MaxForwardOpenSize     = 511  // Largest connection of a standard Forward Open
+	FallbackConnectionSize = 500  // Size requested when Large Forward Open is rejected
+	MaxTimeoutMultiplier   = 7
+	maxForwardOpenRPI      = time.Duration(1<<32-1) * time.Microsecond
+	defaultForwardOpenRPI  = 100 * time.Millisecond
+)
+
+// DefaultForwardOpenParams returns the parameters used unless
+// SetForwardOpenParams is called: 100ms RPI, 4000 bytes (or 500 without
+// Large Forward Open), a timeout of 128 RPIs and application triggering
+func DefaultForwardOpenParams() ForwardOpenParams {
+	return ForwardOpenParams{
+		RPI:               defaultForwardOpenRPI,
+		ConnectionSize:    MaxConnectionSize,
+		TimeoutMultiplier: 5,
+		Trigger:           TriggerApplication,
+	}
+}
+
+// ConnectionTimeout returns how long the connection may go without traffic
+// before the controller closes it
+func (p ForwardOpenParams) ConnectionTimeout() time.Duration {
+	return p.RPI * time.Duration(4<<p.TimeoutMultiplier)
+}
+
+// Validate checks that the parameters can be sent in a Forward Open
+func (p ForwardOpenParams) Validate() error {
+	switch {
+	case p.RPI < time.Microsecond || p.RPI > maxForwardOpenRPI:
+		return NewEipError(ErrInvalidValue, fmt.Sprintf("RPI must be from 1µs to %v, got %v", maxForwardOpenRPI, p.RPI))
+	case p.ConnectionSize < 1 || p.ConnectionSize > MaxConnectionSize:
+		return NewEipError(ErrInvalidValue, fmt.Sprintf("connection size must be 1-%d bytes, got %d", MaxConnectionSize, p.ConnectionSize))
+	case p.TimeoutMultiplier < 0 || p.TimeoutMultiplier > MaxTimeoutMultiplier:
+		return NewEipError(ErrInvalidValue, fmt.Sprintf("timeout multiplier must be 0-%d, got %d", MaxTimeoutMultiplier, p.TimeoutMultiplier))
+	case p.Trigger < TriggerCyclic || p.Trigger > TriggerApplication:
+		return NewEipError(ErrInvalidValue, fmt.Sprintf("unknown transport trigger %d", int(p.Trigger)))
+	}
+	return nil
+}
+
+// SetForwardOpenParams tunes the Class 3 connection of connected messaging
+// mode. An open connection is closed so the next request opens one with the
+// new parameters. The parameters also apply to the sessions opened later on
+// reconnects and for warm standby; a spare session opened with the old ones
+// is replaced.
+func (c *EipClient) SetForwardOpenParams(params ForwardOpenParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	if err := setForwardOpenParams(c.id(), params); err != nil {
+		return err
+	}
+	c.forwardOpen.Store(&params)
+	c.resetStandby()
+	return nil
+}
+
+// ForwardOpenParams returns the Forward Open parameters of the client
+func (c *EipClient) ForwardOpenParams() ForwardOpenParams {
+	if p := c.forwardOpen.Load(); p != nil {
+		return *p
+	}
+	return DefaultForwardOpenParams()
+}
+
+// setForwardOpenParams applies validated parameters to a native session
+func setForwardOpenParams(id int, params ForwardOpenParams) error {
+	retCode := int(C.eip_set_forward_open_params(C.int(id), C.uint(params.RPI/time.Microsecond),
+		C.int(params.ConnectionSize), C.int(params.TimeoutMultiplier), C.int(params.Trigger)))
+	if retCode != 0 {
+		return NewEipErrorWithDetails(ErrInvalidOperation, "Failed to set Forward Open parameters",
+			map[string]interface{}{
+				"error_code": retCode,
+				"client_id":  id,
+			})
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package ethernetip
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestForwardOpenParams tests validation and the connection timeout
+func TestForwardOpenParams(t *testing.T) {
+	params := DefaultForwardOpenParams()
+	if err := params.Validate(); err != nil {
+		t.Fatalf("Expected valid defaults, got %v", err)
+	}
+	if params.ConnectionTimeout() != 12800*time.Millisecond {
+		t.Errorf("Expected a timeout of 128 RPIs, got %v", params.ConnectionTimeout())
+	}
+
+	invalid := map[string]func(*ForwardOpenParams){
+		"zero RPI":         func(p *ForwardOpenParams) { p.RPI = 0 },
+		"sub-µs RPI":       func(p *ForwardOpenParams) { p.RPI = 500 * time.Nanosecond },
+		"long RPI":         func(p *ForwardOpenParams) { p.RPI = 2 * time.Hour },
+		"zero size":        func(p *ForwardOpenParams) { p.ConnectionSize = 0 },
+		"large size":       func(p *ForwardOpenParams) { p.ConnectionSize = MaxConnectionSize + 1 },
+		"negative timeout": func(p *ForwardOpenParams) { p.TimeoutMultiplier = -1 },
+		"timeout":          func(p *ForwardOpenParams) { p.TimeoutMultiplier = 8 },
+		"unknown trigger":  func(p *ForwardOpenParams) { p.Trigger = 3 },
+		"negative trigger": func(p *ForwardOpenParams) { p.Trigger = -1 },
+	}
+	var eipErr *EipError
+	for name, modify := range invalid {
+		p := DefaultForwardOpenParams()
+		modify(&p)
+		if err := p.Validate(); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidValue {
+			t.Errorf("%s: expected an invalid value, got %v", name, err)
+		}
+	}
+
+	if TriggerApplication.String() != "application" || TransportTrigger(9).String() != "TransportTrigger(9)" {
+		t.Error("Unexpected trigger names")
+	}
+}
+
+// TestSetForwardOpenParams tests that the client keeps its parameters only
+// once the native driver accepts them
+func TestSetForwardOpenParams(t *testing.T) {
+	client := &EipClient{}
+	if client.ForwardOpenParams() != DefaultForwardOpenParams() {
+		t.Errorf("Expected the default parameters, got %+v", client.ForwardOpenParams())
+	}
+	slow := DefaultForwardOpenParams()
+	slow.RPI = time.Second
+	slow.ConnectionSize = 244
+	if err := client.SetForwardOpenParams(slow); err == nil || client.ForwardOpenParams() != DefaultForwardOpenParams() {
+		t.Errorf("Expected the parameters to be kept after a failed call, got %+v, %v", client.ForwardOpenParams(), err)
+	}
+
+	var o clientOptions
+	WithForwardOpenParams(slow)(&o)
+	if o.forwardOpen == nil || *o.forwardOpen != slow {
+		t.Errorf("Expected the option to carry the parameters, got %+v", o.forwardOpen)
+	}
+	slow.ConnectionSize = 5000
+	var eipErr *EipError
+	if _, err := NewClientWithOptions("192.0.2.1", WithForwardOpenParams(slow)); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidValue {
+		t.Errorf("Expected invalid parameters to fail before connecting, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// TagValue is the response of GET /api/tag
+type TagValue struct {
+	Tag       string      `json:"tag"`
+	Type      string      `json:"type"`
+	Value     interface{} `json:"value"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// readBatch collects the single-tag reads that arrive during one window
+type readBatch struct {
+	tags   map[string]ethernetip.PlcDataType
+	done   chan struct{}
+	values map[string]*ethernetip.PlcValue
+	errs   map[string]error
+}
+
+// coalescer merges concurrent single-tag reads into batched reads
+type coalescer struct {
+	mu      sync.Mutex
+	pending *readBatch
+}
+
+// SetCoalesceWindow enables request coalescing for GET /api/tag: reads that
+// arrive within window of the first one are merged into a single batched
+// read. A window of 0 (the default) reads each tag individually. The response
+// of each request is unchanged.
+func (s *Server) SetCoalesceWindow(window time.Duration) {
+	if window < 0 {
+		window = 0
+	}
+	s.coalesceWindow.Store(int64(window))
+}
+
+// CoalesceWindow returns the window set with SetCoalesceWindow
+func (s *Server) CoalesceWindow() time.Duration {
+	return time.Duration(s.coalesceWindow.Load())
+}
+
+// ReadTag reads a single tag, coalescing it with concurrent reads when a
+// coalescing window is set
+func (s *Server) ReadTag(ctx context.Context, tagName string, dataType ethernetip.PlcDataType) (*ethernetip.PlcValue, error) {
+	window := s.CoalesceWindow()
+	if window <= 0 {
+		return s.plc.ReadValue(tagName, dataType)
+	}
+
+	c := &s.coalesce
+	c.mu.Lock()
+	batch := c.pending
+	if batch != nil {
+		if existing, ok := batch.tags[tagName]; ok && existing != dataType {
+			// The same tag read as another type cannot share the batch entry
+			c.mu.Unlock()
+			return s.plc.ReadValue(tagName, dataType)
+		}
+		batch.tags[tagName] = dataType
+		c.mu.Unlock()
+	} else {
+		// The first request of a window waits it out and reads for everyone
+		batch = &readBatch{
+			tags: map[string]ethernetip.PlcDataType{tagName: dataType},
+			done: make(chan struct{}),
+		}
+		c.pending = batch
+		c.mu.Unlock()
+
+		<-s.Clock().NewTimer(window).C()
+		c.mu.Lock()
+		c.pending = nil
+		c.mu.Unlock()
+		s.flushBatch(batch)
+	}
+
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if err := batch.errs[tagName]; err != nil {
+		return nil, err
+	}
+	return batch.values[tagName], nil
+}
+
+// flushBatch reads every tag of batch in one ReadTags call, which packs
+// them into Multiple Service Packets. Each request gets its own value or
+// error, so one missing tag does not fail the others.
+func (s *Server) flushBatch(batch *readBatch) {
+	defer close(batch.done)
+
+	batch.values, batch.errs = s.plc.ReadTags(batch.tags)
+	if batch.values == nil {
+		batch.values = make(map[string]*ethernetip.PlcValue)
+	}
+}
+
+// handleReadTag handles GET /api/tag?name=X&type=DINT. The type may be
+// omitted for tags in the server's TagTypes.
+func (s *Server) handleReadTag(w http.ResponseWriter, r *http.Request) {
+	tagName := r.URL.Query().Get("name")
+	if tagName == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	dataType, err := s.resolveType(tagName, r.URL.Query().Get("type"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	value, err := s.ReadTag(r.Context(), tagName, dataType)
+	if err != nil {
+		writePLCError(w, err)
+		return
+	}
+	if value == nil {
+		writeError(w, http.StatusBadGateway, "no value returned for tag "+tagName)
+		return
+	}
+	s.writeResponse(w, r, http.StatusOK, TagValue{
+		Tag:       tagName,
+		Type:      dataType.String(),
+		Value:     wireValue(dataType, value.Value),
+		Timestamp: time.Now(),
+	})
+}
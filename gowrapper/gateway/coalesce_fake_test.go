@@ -0,0 +1,83 @@
+//go:build eipfake
+
+package gateway
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// newFakeClientPLC connects a client to the in-memory native layer, with
+// DINT tags FlowA = 3 and FlowB = 4, the alias line.flow of FlowA and the
+// virtual tag TotalFlow = FlowA + FlowB
+func newFakeClientPLC(t *testing.T) *ethernetip.EipClient {
+	t.Helper()
+	client, err := ethernetip.NewClient("10.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to connect to the fake native layer: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	for name, value := range map[string]int32{"FlowA": 3, "FlowB": 4} {
+		if err := client.WriteDint(name, value); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		client.TagTypes().Set(name, ethernetip.Dint)
+	}
+	if err := client.SetTagAliases(map[string]string{"line.flow": "FlowA"}); err != nil {
+		t.Fatalf("Failed to set aliases: %v", err)
+	}
+	if err := client.DefineVirtualTag("TotalFlow", "FlowA + FlowB"); err != nil {
+		t.Fatalf("Failed to define virtual tag: %v", err)
+	}
+	return client
+}
+
+// TestReadTagCoalescingAliasVirtual tests that a coalesced batch resolves
+// aliases and computes virtual tags instead of reading them from the PLC,
+// with the window timed on the client's clock
+func TestReadTagCoalescingAliasVirtual(t *testing.T) {
+	client := newFakeClientPLC(t)
+	clock := ethernetip.NewFakeClock(time.Unix(0, 0))
+	client.SetClock(clock)
+
+	s := NewServer(client)
+	defer s.Close()
+	s.SetCoalesceWindow(50 * time.Millisecond)
+
+	want := map[string]int32{"line.flow": 3, "TotalFlow": 7, "FlowB": 4}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	started := 0
+	for name, expected := range want {
+		wg.Add(1)
+		go func(name string, expected int32) {
+			defer wg.Done()
+			mu.Lock()
+			started++
+			mu.Unlock()
+			value, err := s.ReadTag(context.Background(), name, ethernetip.Dint)
+			if err != nil || value.Value != expected {
+				t.Errorf("%s: expected %d, got %v, %v", name, expected, value, err)
+			}
+		}(name, expected)
+	}
+	// The first read waits out the window on the fake clock; let the others
+	// join it before the window ends
+	clock.BlockUntil(1)
+	for {
+		mu.Lock()
+		n := started
+		mu.Unlock()
+		if n == len(want) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	clock.Advance(50 * time.Millisecond)
+	wg.Wait()
+}
@@ -0,0 +1,158 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// TestReadTagEndpoint tests single-tag reads without coalescing
+func TestReadTagEndpoint(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"Speed": float64(12.5)}}
+	s := NewServer(plc)
+	defer s.Close()
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tag?name=Speed&type=float", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var value TagValue
+	if err := json.NewDecoder(rec.Body).Decode(&value); err != nil {
+		t.Fatalf("Failed to decode value: %v", err)
+	}
+	if value.Tag != "Speed" || value.Type != "REAL" || value.Value != 12.5 {
+		t.Errorf("Unexpected value: %+v", value)
+	}
+
+	for path, code := range map[string]int{
+		"/api/tag?type=DINT":             http.StatusBadRequest,
+		"/api/tag?name=Speed&type=nope":  http.StatusBadRequest,
+		"/api/tag?name=Speed[1&type=INT": http.StatusBadRequest,
+		"/api/tag?name=Missing&type=INT": http.StatusBadGateway,
+	} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != code {
+			t.Errorf("%s: expected %d, got %d", path, code, rec.Code)
+		}
+	}
+}
+
+// TestReadTagCoalescing tests that concurrent reads share one batched read
+func TestReadTagCoalescing(t *testing.T) {
+	values := make(map[string]interface{})
+	for i := 0; i < 20; i++ {
+		values[fmt.Sprintf("Tag%d", i)] = int32(i)
+	}
+	plc := &fakePLC{values: values}
+	s := NewServer(plc)
+	defer s.Close()
+	s.SetCoalesceWindow(50 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err := s.ReadTag(context.Background(), fmt.Sprintf("Tag%d", i), ethernetip.Dint)
+			if err != nil || value.Value != int32(i) {
+				t.Errorf("Tag%d: got %v, %v", i, value, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if plc.reads.Load() != 0 || plc.batchReads.Load() > 2 {
+		t.Errorf("Expected reads to be merged, got %d single and %d batched reads", plc.reads.Load(), plc.batchReads.Load())
+	}
+}
+
+// TestReadTagCoalescingErrors tests that a failing tag does not fail the rest of its batch
+func TestReadTagCoalescingErrors(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"Good": int32(1)}}
+	s := NewServer(plc)
+	defer s.Close()
+	s.SetCoalesceWindow(50 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	var goodErr, badErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, goodErr = s.ReadTag(context.Background(), "Good", ethernetip.Dint)
+	}()
+	go func() {
+		defer wg.Done()
+		_, badErr = s.ReadTag(context.Background(), "Bad", ethernetip.Dint)
+	}()
+	wg.Wait()
+
+	if goodErr != nil {
+		t.Errorf("Expected Good to be read, got %v", goodErr)
+	}
+	if badErr == nil {
+		t.Error("Expected error for Bad")
+	}
+	if plc.reads.Load() != 0 {
+		t.Errorf("Expected no single-tag retries, got %d", plc.reads.Load())
+	}
+}
+
+// TestReadTagDefaultType tests reading a tag without a type parameter
+func TestReadTagDefaultType(t *testing.T) {
+	plc := &fakePLC{
+		values: map[string]interface{}{"Speed": float64(3)},
+		tags:   []ethernetip.TagInfo{{Name: "Speed", SymbolType: ethernetip.CIPTypeReal}},
+	}
+	s := NewServer(plc)
+	defer s.Close()
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tag?name=Speed", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 before the type is known, got %d", rec.Code)
+	}
+
+	s.StartDiscovery()
+	waitForState(t, s, DiscoveryCompleted)
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tag?name=Speed", nil))
+	var value TagValue
+	if err := json.NewDecoder(rec.Body).Decode(&value); err != nil || value.Type != "REAL" {
+		t.Errorf("Expected REAL from the discovered type, got %d %+v (%v)", rec.Code, value, err)
+	}
+}
+
+// TestReadTagEncoding tests that REAL values keep single precision and
+// integers stay integers in JSON
+func TestReadTagEncoding(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{
+		"Speed": float64(float32(0.1)),
+		"Total": int64(9007199254740993),
+		"Trend": []interface{}{float64(float32(0.2)), float64(float32(1.5))},
+	}}
+	s := NewServer(plc)
+	defer s.Close()
+
+	for path, want := range map[string]string{
+		"/api/tag?name=Speed&type=REAL":  `"value":0.1,`,
+		"/api/tag?name=Total&type=LINT":  `"value":9007199254740993,`,
+		"/api/tag?name=Trend&type=REAL":  `"value":[0.2,1.5],`,
+		"/api/tag?name=Speed&type=LREAL": `"value":0.10000000149011612,`,
+	} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("%s: expected %s in %s", path, want, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package gateway
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Discovery job states
+const (
+	DiscoveryIdle      = "idle"
+	DiscoveryRunning   = "running"
+	DiscoveryCompleted = "completed"
+	DiscoveryFailed    = "failed"
+)
+
+// DiscoveryStatus reports the progress of a tag discovery run
+type DiscoveryStatus struct {
+	State      string     `json:"state"`
+	TagsFound  int        `json:"tags_found"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// discoveryJob tracks the single discovery run a server may have in flight
+type discoveryJob struct {
+	mu     sync.Mutex
+	status DiscoveryStatus
+}
+
+// snapshot returns a copy of the current status
+func (j *discoveryJob) snapshot() DiscoveryStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	status := j.status
+	if status.State == "" {
+		status.State = DiscoveryIdle
+	}
+	return status
+}
+
+// StartDiscovery begins an asynchronous tag discovery. It returns false if a
+// discovery is already running. When discovery completes the new tag database
+// replaces the served one atomically; on failure the previous one is kept.
+func (s *Server) StartDiscovery() (DiscoveryStatus, bool) {
+	j := &s.discovery
+	j.mu.Lock()
+	if j.status.State == DiscoveryRunning {
+		status := j.status
+		j.mu.Unlock()
+		return status, false
+	}
+	now := time.Now()
+	j.status = DiscoveryStatus{State: DiscoveryRunning, StartedAt: &now}
+	status := j.status
+	j.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		db, err := s.plc.DiscoverTagDatabase(s.ctx, func(found int) {
+			j.mu.Lock()
+			j.status.TagsFound = found
+			j.mu.Unlock()
+		})
+
+		finished := time.Now()
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		j.status.FinishedAt = &finished
+		if err != nil {
+			j.status.State = DiscoveryFailed
+			j.status.Error = err.Error()
+			return
+		}
+		s.tags.Store(db)
+		s.types.AddDatabase(db)
+		j.status.State = DiscoveryCompleted
+		j.status.TagsFound = db.Len()
+	}()
+
+	return status, true
+}
+
+// DiscoveryStatus returns the progress of the current or last discovery run
+func (s *Server) DiscoveryStatus() DiscoveryStatus {
+	return s.discovery.snapshot()
+}
+
+// handleStartDiscovery handles POST /api/discover
+func (s *Server) handleStartDiscovery(w http.ResponseWriter, r *http.Request) {
+	status, started := s.StartDiscovery()
+	if !started {
+		s.writeResponse(w, r, http.StatusConflict, status)
+		return
+	}
+	s.writeResponse(w, r, http.StatusAccepted, status)
+}
+
+// handleDiscoveryStatus handles GET /api/discover
+func (s *Server) handleDiscoveryStatus(w http.ResponseWriter, r *http.Request) {
+	s.writeResponse(w, r, http.StatusOK, s.DiscoveryStatus())
+}
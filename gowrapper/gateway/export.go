@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"net/http"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// maxImportSize limits the body of POST /api/tags/import
+const maxImportSize = 64 << 20
+
+// importingPLC is implemented by clients that can load an exported tag
+// database themselves, such as *ethernetip.EipClient
+type importingPLC interface {
+	ImportTagDatabase(e *ethernetip.TagExport) *ethernetip.TagDatabase
+}
+
+// ExportTags exports the served tag database. Structure templates are
+// included when the PLC can read them (see ethernetip.TemplateSource).
+func (s *Server) ExportTags(r *http.Request) (*ethernetip.TagExport, error) {
+	templates, _ := s.plc.(ethernetip.TemplateSource)
+	return ethernetip.NewTagExport(r.Context(), s.tags.Load(), templates)
+}
+
+// ImportTags replaces the served tag database with an export, as a completed
+// discovery would, and passes it on to the PLC client when it supports that
+func (s *Server) ImportTags(e *ethernetip.TagExport) *ethernetip.TagDatabase {
+	var db *ethernetip.TagDatabase
+	if importer, ok := s.plc.(importingPLC); ok {
+		db = importer.ImportTagDatabase(e)
+	} else {
+		db = e.Database()
+	}
+	s.tags.Store(db)
+	s.types.AddDatabase(db)
+	return db
+}
+
+// handleExportTags handles GET /api/tags/export
+func (s *Server) handleExportTags(w http.ResponseWriter, r *http.Request) {
+	if s.tags.Load() == nil {
+		writeError(w, http.StatusNotFound, "no tag database; run POST /api/discover first")
+		return
+	}
+	export, err := s.ExportTags(r)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="tags.json"`)
+	w.Header().Set("Content-Type", "application/json")
+	ethernetip.WriteTagExport(w, export)
+}
+
+// handleImportTags handles POST /api/tags/import with a tag export as the body
+func (s *Server) handleImportTags(w http.ResponseWriter, r *http.Request) {
+	export, err := ethernetip.ReadTagExport(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	db := s.ImportTags(export)
+	s.writeResponse(w, r, http.StatusOK, map[string]interface{}{"tags": db.Len(), "templates": len(export.Templates)})
+}
@@ -0,0 +1,52 @@
+package gateway
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// TestExportImportEndpoints tests moving a tag database between gateways
+func TestExportImportEndpoints(t *testing.T) {
+	plc := &fakePLC{tags: []ethernetip.TagInfo{{Name: "Speed", SymbolType: ethernetip.CIPTypeReal}, {Name: "Level"}}}
+	source := NewServer(plc)
+	defer source.Close()
+
+	rec := httptest.NewRecorder()
+	source.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tags/export", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 before discovery, got %d", rec.Code)
+	}
+
+	source.StartDiscovery()
+	waitForState(t, source, DiscoveryCompleted)
+	rec = httptest.NewRecorder()
+	source.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tags/export", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	exported := rec.Body.Bytes()
+
+	target := NewServer(&fakePLC{})
+	defer target.Close()
+	rec = httptest.NewRecorder()
+	target.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/tags/import", bytes.NewReader(exported)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if target.TagDatabase().Len() != 2 {
+		t.Errorf("Expected 2 imported tags, got %d", target.TagDatabase().Len())
+	}
+	if dataType, ok := target.TagTypes().Lookup("Speed"); !ok || dataType != ethernetip.Real {
+		t.Errorf("Expected Speed to be typed REAL after import, got %v, %v", dataType, ok)
+	}
+
+	rec = httptest.NewRecorder()
+	target.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/tags/import", bytes.NewReader([]byte(`{"tags": []}`))))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a file that is not an export, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,357 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// Streaming limits for GET /api/groups/{name}/stream
+const (
+	defaultStreamInterval = time.Second
+	minStreamInterval     = 50 * time.Millisecond
+)
+
+// TagGroup is a named set of tags defined by an HTTP client and read as a unit
+type TagGroup struct {
+	Name string                   `json:"name"`
+	Tags []ethernetip.GroupMember `json:"tags"`
+}
+
+// GroupValues is the result of reading a tag group
+type GroupValues struct {
+	Name   string                 `json:"name"`
+	Values map[string]interface{} `json:"values"`
+	// Errors holds the error of each tag that could not be read
+	Errors    map[string]string `json:"errors,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// groupRegistry holds the tag groups defined on a server
+type groupRegistry struct {
+	mu     sync.RWMutex
+	groups map[string]*TagGroup
+}
+
+// validate checks a group definition received from a client
+func (g *TagGroup) validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("group name is required")
+	}
+	if len(g.Tags) == 0 {
+		return fmt.Errorf("group must have at least one tag")
+	}
+	seen := make(map[string]bool, len(g.Tags))
+	for _, tag := range g.Tags {
+		if tag.TagName == "" {
+			return fmt.Errorf("tag name is required")
+		}
+		if seen[tag.TagName] {
+			return fmt.Errorf("duplicate tag '%s'", tag.TagName)
+		}
+		seen[tag.TagName] = true
+	}
+	return nil
+}
+
+// DefineGroup registers a tag group. It returns false if a group with the
+// same name already exists.
+func (s *Server) DefineGroup(group TagGroup) (bool, error) {
+	if err := group.validate(); err != nil {
+		return false, err
+	}
+	r := &s.groups
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.groups[group.Name]; exists {
+		return false, nil
+	}
+	if r.groups == nil {
+		r.groups = make(map[string]*TagGroup)
+	}
+	group.Tags = append([]ethernetip.GroupMember(nil), group.Tags...)
+	r.groups[group.Name] = &group
+	return true, nil
+}
+
+// Group returns the tag group with the given name
+func (s *Server) Group(name string) (*TagGroup, bool) {
+	s.groups.mu.RLock()
+	defer s.groups.mu.RUnlock()
+	group, ok := s.groups.groups[name]
+	return group, ok
+}
+
+// ReadGroup reads every tag of a group, packed into as few Multiple Service
+// Packets as fit (see EipClient.ReadTags). Tags that fail are reported in
+// Errors; the read fails as a whole only if no tag could be read.
+func (s *Server) ReadGroup(name string) (*GroupValues, error) {
+	group, ok := s.Group(name)
+	if !ok {
+		return nil, fmt.Errorf("group '%s' not found", name)
+	}
+	tags := make(map[string]ethernetip.PlcDataType, len(group.Tags))
+	for _, tag := range group.Tags {
+		tags[tag.TagName] = tag.DataType
+	}
+	values, errs := s.plc.ReadTags(tags)
+	if len(values) == 0 {
+		for _, tag := range group.Tags {
+			if err := errs[tag.TagName]; err != nil {
+				return nil, err
+			}
+		}
+	}
+	result := &GroupValues{
+		Name:      name,
+		Values:    make(map[string]interface{}, len(values)),
+		Timestamp: time.Now(),
+	}
+	for tagName, value := range values {
+		result.Values[tagName] = wireValue(value.Type, value.Value)
+	}
+	if len(errs) > 0 {
+		result.Errors = make(map[string]string, len(errs))
+		for tagName, err := range errs {
+			result.Errors[tagName] = err.Error()
+		}
+	}
+	return result, nil
+}
+
+// handleDefineGroup handles POST /api/groups
+func (s *Server) handleDefineGroup(w http.ResponseWriter, r *http.Request) {
+	var group TagGroup
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid group definition: "+err.Error())
+		return
+	}
+	created, err := s.DefineGroup(group)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !created {
+		writeError(w, http.StatusConflict, fmt.Sprintf("group '%s' already exists", group.Name))
+		return
+	}
+	s.writeResponse(w, r, http.StatusCreated, group)
+}
+
+// handleListGroups handles GET /api/groups
+func (s *Server) handleListGroups(w http.ResponseWriter, r *http.Request) {
+	s.groups.mu.RLock()
+	groups := make([]*TagGroup, 0, len(s.groups.groups))
+	for _, group := range s.groups.groups {
+		groups = append(groups, group)
+	}
+	s.groups.mu.RUnlock()
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	s.writeResponse(w, r, http.StatusOK, groups)
+}
+
+// handleGetGroup handles GET /api/groups/{name}
+func (s *Server) handleGetGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := s.Group(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "group not found")
+		return
+	}
+	s.writeResponse(w, r, http.StatusOK, group)
+}
+
+// handleDeleteGroup handles DELETE /api/groups/{name}
+func (s *Server) handleDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	s.groups.mu.Lock()
+	_, ok := s.groups.groups[name]
+	delete(s.groups.groups, name)
+	s.groups.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "group not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleReadGroup handles GET /api/groups/{name}/values
+func (s *Server) handleReadGroup(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, ok := s.Group(name); !ok {
+		writeError(w, http.StatusNotFound, "group not found")
+		return
+	}
+	values, err := s.ReadGroup(name)
+	if err != nil {
+		writePLCError(w, err)
+		return
+	}
+	s.writeResponse(w, r, http.StatusOK, values)
+}
+
+// handleStreamGroup handles GET /api/groups/{name}/stream, sending the group's
+// values as server-sent events every interval (query parameter, default 1s)
+// until the client disconnects. Streams of a group at the same interval share
+// one poll.
+func (s *Server) handleStreamGroup(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, ok := s.Group(name); !ok {
+		writeError(w, http.StatusNotFound, "group not found")
+		return
+	}
+	interval := defaultStreamInterval
+	if v := r.URL.Query().Get("interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < minStreamInterval {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("interval must be a duration of at least %v", minStreamInterval))
+			return
+		}
+		interval = d
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	updates, unsubscribe := s.streams.subscribe(s, name, interval)
+	defer unsubscribe()
+
+	events := &eventWriter{w: w, flusher: flusher, serializer: s.negotiate(r)}
+	events.start()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.ctx.Done():
+			return
+		case update := <-updates:
+			if update.err != nil {
+				events.send("error", map[string]interface{}{"error": update.err.Error()})
+			} else {
+				events.send("", update.values)
+			}
+		}
+	}
+}
+
+// groupStreams shares one poll of a group among its streams, per interval,
+// as the Hub does for GET /api/stream
+type groupStreams struct {
+	mu    sync.Mutex
+	polls map[groupPollKey]*groupPoll
+}
+
+// groupPollKey identifies a shared group poll
+type groupPollKey struct {
+	name     string
+	interval time.Duration
+}
+
+// groupPoll reads a group every interval and hands each result to its
+// streams. A stream that falls behind gets the latest result only.
+type groupPoll struct {
+	mu   sync.Mutex
+	subs map[chan groupUpdate]struct{}
+	last *groupUpdate // Latest result, sent to streams as they join
+	stop chan struct{}
+}
+
+// groupUpdate is one read of a streamed group
+type groupUpdate struct {
+	values *GroupValues
+	err    error
+}
+
+// subscribe joins the poll of group name at interval, starting it if it is
+// the first stream. unsubscribe leaves it, stopping the poll after the last
+// stream.
+func (g *groupStreams) subscribe(s *Server, name string, interval time.Duration) (updates <-chan groupUpdate, unsubscribe func()) {
+	key := groupPollKey{name: name, interval: interval}
+	ch := make(chan groupUpdate, 1)
+
+	g.mu.Lock()
+	poll, ok := g.polls[key]
+	if !ok {
+		poll = &groupPoll{subs: make(map[chan groupUpdate]struct{}), stop: make(chan struct{})}
+		if g.polls == nil {
+			g.polls = make(map[groupPollKey]*groupPoll)
+		}
+		g.polls[key] = poll
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			poll.run(s, name, interval)
+		}()
+	}
+	poll.mu.Lock()
+	poll.subs[ch] = struct{}{}
+	if poll.last != nil {
+		ch <- *poll.last
+	}
+	poll.mu.Unlock()
+	g.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			poll.mu.Lock()
+			delete(poll.subs, ch)
+			last := len(poll.subs) == 0
+			poll.mu.Unlock()
+			if last {
+				close(poll.stop)
+				delete(g.polls, key)
+			}
+		})
+	}
+}
+
+// count returns the number of running group polls
+func (g *groupStreams) count() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.polls)
+}
+
+// run reads the group every interval until the last stream leaves or the
+// server closes
+func (p *groupPoll) run(s *Server, name string, interval time.Duration) {
+	ticker := s.Clock().NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		values, err := s.ReadGroup(name)
+		p.publish(groupUpdate{values: values, err: err})
+
+		select {
+		case <-p.stop:
+			return
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// publish hands update to every stream, replacing a result a stream has not
+// taken yet
+func (p *groupPoll) publish(update groupUpdate) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.last = &update
+	for ch := range p.subs {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- update
+	}
+}
@@ -0,0 +1,34 @@
+//go:build eipfake
+
+package gateway
+
+import (
+	"testing"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// TestReadGroupAliasVirtual tests that a group read resolves aliases and
+// computes virtual tags instead of reading them from the PLC
+func TestReadGroupAliasVirtual(t *testing.T) {
+	s := NewServer(newFakeClientPLC(t))
+	defer s.Close()
+	s.DefineGroup(TagGroup{Name: "flows", Tags: []ethernetip.GroupMember{
+		{TagName: "line.flow", DataType: ethernetip.Dint},
+		{TagName: "TotalFlow", DataType: ethernetip.Dint},
+		{TagName: "FlowB", DataType: ethernetip.Dint},
+	}})
+
+	values, err := s.ReadGroup("flows")
+	if err != nil {
+		t.Fatalf("Failed to read group: %v", err)
+	}
+	if len(values.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", values.Errors)
+	}
+	for name, want := range map[string]int32{"line.flow": 3, "TotalFlow": 7, "FlowB": 4} {
+		if got := values.Values[name]; got != want {
+			t.Errorf("%s: expected %d, got %v", name, want, got)
+		}
+	}
+}
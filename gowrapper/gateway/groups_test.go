@@ -0,0 +1,191 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// TestGroupEndpoints tests defining, listing, reading and deleting tag groups
+func TestGroupEndpoints(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"PartCount": int32(12), "BatchID": "B-7"}}
+	s := NewServer(plc)
+	defer s.Close()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	body := `{"name":"line1","tags":[{"tag_name":"PartCount","data_type":3},{"tag_name":"BatchID","data_type":11}]}`
+	if rec := do(http.MethodPost, "/api/groups", body); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodPost, "/api/groups", body); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for duplicate group, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/groups", `{"name":"empty","tags":[]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for empty group, got %d", rec.Code)
+	}
+
+	rec := do(http.MethodGet, "/api/groups", "")
+	var groups []TagGroup
+	if err := json.NewDecoder(rec.Body).Decode(&groups); err != nil || len(groups) != 1 || groups[0].Name != "line1" {
+		t.Errorf("Unexpected group list: %+v (%v)", groups, err)
+	}
+
+	rec = do(http.MethodGet, "/api/groups/line1/values", "")
+	var values GroupValues
+	if err := json.NewDecoder(rec.Body).Decode(&values); err != nil {
+		t.Fatalf("Failed to decode values: %v", err)
+	}
+	if values.Values["PartCount"] != float64(12) || values.Values["BatchID"] != "B-7" || len(values.Errors) != 0 {
+		t.Errorf("Unexpected values: %+v", values)
+	}
+	if n := plc.batchReads.Load(); n != 1 || plc.reads.Load() != 0 {
+		t.Errorf("Expected one batched read, got %d batched and %d single", n, plc.reads.Load())
+	}
+
+	if rec := do(http.MethodGet, "/api/groups/missing/values", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown group, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/api/groups/line1", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", rec.Code)
+	}
+	if _, ok := s.Group("line1"); ok {
+		t.Error("Expected group to be deleted")
+	}
+}
+
+// TestReadGroupPartial tests that a tag that fails is reported without
+// hiding the others, and that a group fails only if no tag could be read
+func TestReadGroupPartial(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"Level": 4.5}}
+	s := NewServer(plc)
+	defer s.Close()
+	s.DefineGroup(TagGroup{Name: "tank", Tags: []ethernetip.GroupMember{
+		{TagName: "Level", DataType: ethernetip.Real},
+		{TagName: "Missing", DataType: ethernetip.Dint},
+	}})
+	s.DefineGroup(TagGroup{Name: "gone", Tags: []ethernetip.GroupMember{{TagName: "Missing", DataType: ethernetip.Dint}}})
+
+	values, err := s.ReadGroup("tank")
+	if err != nil || values.Values["Level"] == nil || !strings.Contains(values.Errors["Missing"], "tag not found") {
+		t.Errorf("Expected Level with an error for Missing, got %+v, %v", values, err)
+	}
+	if _, err := s.ReadGroup("gone"); err == nil {
+		t.Error("Expected an error when no tag could be read")
+	}
+}
+
+// TestGroupStream tests that a group stream sends server-sent events
+func TestGroupStream(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"Level": 4.5}}
+	s := NewServer(plc)
+	defer s.Close()
+	s.DefineGroup(TagGroup{Name: "tank", Tags: []ethernetip.GroupMember{{TagName: "Level", DataType: ethernetip.Real}}})
+
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/groups/tank/stream?interval=50ms", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	events := 0
+	for events < 2 && scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var values GroupValues
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &values); err != nil {
+			t.Fatalf("Invalid event %q: %v", line, err)
+		}
+		if values.Values["Level"] != 4.5 {
+			t.Errorf("Unexpected values: %+v", values.Values)
+		}
+		events++
+	}
+	if events != 2 {
+		t.Errorf("Expected 2 events, got %d", events)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/groups/tank/stream?interval=1ms", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for too short interval, got %d", rec.Code)
+	}
+}
+
+// TestGroupStreamSharesPolls tests that concurrent streams of a group at the
+// same interval share one poll
+func TestGroupStreamSharesPolls(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"Level": 4.5}}
+	s := NewServer(plc)
+	defer s.Close()
+	// The clock never advances, so the poll reads the group only once
+	s.poller.SetClock(ethernetip.NewFakeClock(time.Unix(0, 0)))
+	s.DefineGroup(TagGroup{Name: "tank", Tags: []ethernetip.GroupMember{{TagName: "Level", DataType: ethernetip.Real}}})
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/groups/tank/stream?interval=50ms", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var values GroupValues
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &values); err != nil {
+				t.Fatalf("Invalid event %q: %v", line, err)
+			}
+			if values.Values["Level"] != 4.5 {
+				t.Errorf("Unexpected values: %+v", values.Values)
+			}
+			break
+		}
+	}
+
+	if n := s.streams.count(); n != 1 {
+		t.Errorf("Expected 1 group poll, got %d", n)
+	}
+	if n := plc.batchReads.Load(); n != 1 {
+		t.Errorf("Expected the streams to share 1 read, got %d", n)
+	}
+
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for s.streams.count() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := s.streams.count(); n != 0 {
+		t.Errorf("Expected the poll to stop with its last stream, got %d polls", n)
+	}
+}
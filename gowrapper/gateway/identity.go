@@ -0,0 +1,41 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// IdentityFunc returns the caller identity of a request
+type IdentityFunc func(r *http.Request) ethernetip.CallerIdentity
+
+// RequestIdentity is the default IdentityFunc. The user is the username of
+// the request's basic authentication and the correlation ID its
+// X-Request-ID header.
+func RequestIdentity(r *http.Request) ethernetip.CallerIdentity {
+	user, _, _ := r.BasicAuth()
+	return ethernetip.CallerIdentity{User: user, CorrelationID: r.Header.Get("X-Request-ID")}
+}
+
+// SetIdentityFunc sets how the caller identity of write requests is taken
+// from the request, for example from a session cookie or a header set by an
+// authenticating proxy. The identity is attached to the context of every
+// write with ethernetip.WithCallerIdentity, so it reaches interceptors and
+// the audit log. A nil fn restores RequestIdentity.
+func (s *Server) SetIdentityFunc(fn IdentityFunc) {
+	if fn == nil {
+		s.identify.Store(nil)
+		return
+	}
+	s.identify.Store(&fn)
+}
+
+// withIdentity returns a copy of ctx carrying the caller identity of r
+func (s *Server) withIdentity(ctx context.Context, r *http.Request) context.Context {
+	identify := RequestIdentity
+	if fn := s.identify.Load(); fn != nil {
+		identify = *fn
+	}
+	return ethernetip.WithCallerIdentity(ctx, identify(r))
+}
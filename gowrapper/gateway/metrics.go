@@ -0,0 +1,216 @@
+package gateway
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// diagnosticsPLC is implemented by clients that can read controller load,
+// such as *ethernetip.EipClient
+type diagnosticsPLC interface {
+	Diagnostics() (*ethernetip.ControllerDiagnostics, error)
+}
+
+// queuePLC is implemented by clients that report their operation queue, such
+// as *ethernetip.EipClient
+type queuePLC interface {
+	QueueStats() ethernetip.QueueStats
+}
+
+// sessionPLC is implemented by clients that keep a reconnect history, such
+// as *ethernetip.EipClient
+type sessionPLC interface {
+	SessionDiagnostics() ethernetip.SessionDiagnostics
+}
+
+// Diagnostics reads the controller's utilization and task scan times. It
+// fails with ErrInvalidOperation if the PLC client cannot report them.
+func (s *Server) Diagnostics() (*ethernetip.ControllerDiagnostics, error) {
+	plc, ok := s.plc.(diagnosticsPLC)
+	if !ok {
+		return nil, ethernetip.NewEipError(ethernetip.ErrInvalidOperation, "the PLC client does not report diagnostics")
+	}
+	return plc.Diagnostics()
+}
+
+// handleDiagnostics handles GET /api/diagnostics
+func (s *Server) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	diag, err := s.Diagnostics()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	s.writeResponse(w, r, http.StatusOK, diag)
+}
+
+// handleMetrics handles GET /metrics in the Prometheus text format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.WriteMetrics(w)
+}
+
+// WriteMetrics writes the gateway's metrics and the controller's diagnostics
+// in the Prometheus text exposition format, so embedding applications can
+// serve them from their own collector. The controller is read on every call;
+// eip_controller_diagnostics_up is 0 when that read fails.
+func (s *Server) WriteMetrics(w io.Writer) error {
+	m := &metricsWriter{w: w}
+
+	m.gauge("eip_gateway_stream_consumers", "Consumers subscribed through the hub",
+		sample(float64(s.hub.ConsumerCount())))
+	m.gauge("eip_gateway_polled_tags", "Tags polled for the hub",
+		sample(float64(s.hub.TagCount())))
+
+	health := s.poller.Health()
+	states := map[ethernetip.HealthState]int{}
+	missed := make([]metricSample, 0, len(health))
+	for _, h := range health {
+		states[h.State]++
+		missed = append(missed, sample(float64(h.MissedScans), "tag", h.TagName, "interval", h.Interval.String()))
+	}
+	m.gauge("eip_gateway_subscriptions", "Poll loops by health state",
+		sample(float64(states[ethernetip.HealthOK]), "state", ethernetip.HealthOK.String()),
+		sample(float64(states[ethernetip.HealthDegraded]), "state", ethernetip.HealthDegraded.String()),
+		sample(float64(states[ethernetip.HealthStalled]), "state", ethernetip.HealthStalled.String()))
+	m.gauge("eip_gateway_subscription_missed_scans", "Scans missed by a poll loop since its last successful read", missed...)
+
+	usage := ethernetip.DefaultConnectionBudget.Usage()
+	held := make([]metricSample, 0, len(usage))
+	for _, u := range usage {
+		held = append(held, sample(float64(u.Held), "controller", u.Address))
+	}
+	m.gauge("eip_connections_held", "CIP connections this process holds to a controller", held...)
+
+	if plc, ok := s.plc.(queuePLC); ok {
+		stats := plc.QueueStats()
+		m.gauge("eip_queue_depth", "Operations queued or in progress on the client", sample(float64(stats.Depth)))
+		m.gauge("eip_queue_peak_depth", "Largest queue depth since the client started", sample(float64(stats.PeakDepth)))
+		m.counter("eip_queue_operations_total", "Operations admitted to the queue", sample(float64(stats.Submitted)))
+		m.counter("eip_queue_shed_total", "Reads rejected because the queue was too deep", sample(float64(stats.Shed)))
+		m.counter("eip_queue_wait_seconds_total", "Time operations spent from submission to completion", sample(stats.WaitTotal.Seconds()))
+		m.gauge("eip_queue_max_wait_seconds", "Longest time an operation spent queued", sample(stats.MaxWait.Seconds()))
+	}
+
+	if plc, ok := s.plc.(sessionPLC); ok {
+		session := plc.SessionDiagnostics()
+		reasons := make([]string, 0, len(session.Reconnects))
+		for reason := range session.Reconnects {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		reconnects := make([]metricSample, 0, len(reasons))
+		for _, reason := range reasons {
+			reconnects = append(reconnects, sample(float64(session.Reconnects[reason]), "reason", reason))
+		}
+		m.counter("eip_session_reconnects_total", "Session reconnects by reason", reconnects...)
+		m.counter("eip_session_idle_closes_total", "Sessions closed for inactivity", sample(float64(session.IdleCloses)))
+	}
+
+	if plc, ok := s.plc.(diagnosticsPLC); ok {
+		diag, err := plc.Diagnostics()
+		up := 1.0
+		if err != nil {
+			up = 0
+		}
+		m.gauge("eip_controller_diagnostics_up", "Whether the controller diagnostics could be read", sample(up))
+		if err == nil {
+			writeDiagnostics(m, diag)
+		}
+	}
+	return m.err
+}
+
+// writeDiagnostics writes the controller's utilization and task scan times
+func writeDiagnostics(m *metricsWriter, diag *ethernetip.ControllerDiagnostics) {
+	if diag.CPUUtilization != nil {
+		m.gauge("eip_controller_cpu_utilization_percent", "Controller CPU utilization", sample(*diag.CPUUtilization))
+	}
+	if diag.CommUtilization != nil {
+		m.gauge("eip_controller_comm_utilization_percent", "Controller communications utilization", sample(*diag.CommUtilization))
+	}
+	last := make([]metricSample, 0, len(diag.Tasks))
+	maxScan := make([]metricSample, 0, len(diag.Tasks))
+	avg := make([]metricSample, 0, len(diag.Tasks))
+	for _, task := range diag.Tasks {
+		instance := strconv.FormatUint(uint64(task.Instance), 10)
+		last = append(last, sample(task.LastScan.Seconds(), "task", instance))
+		maxScan = append(maxScan, sample(task.MaxScan.Seconds(), "task", instance))
+		avg = append(avg, sample(task.AvgScan.Seconds(), "task", instance))
+	}
+	if diag.Forces != nil {
+		m.gauge("eip_controller_forces_installed", "Whether I/O forces are installed", sample(boolValue(diag.Forces.Installed)))
+		m.gauge("eip_controller_forces_enabled", "Whether I/O forces are enabled", sample(boolValue(diag.Forces.Enabled)))
+	}
+	m.gauge("eip_controller_task_last_scan_seconds", "Duration of the task's last scan", last...)
+	m.gauge("eip_controller_task_max_scan_seconds", "Longest scan of the task since the controller last reset it", maxScan...)
+	m.gauge("eip_controller_task_avg_scan_seconds", "Average of the last scan times the gateway has read", avg...)
+}
+
+// boolValue returns 1 for true and 0 for false
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// metricSample is one labelled value of a metric
+type metricSample struct {
+	labels []string // Name, value pairs
+	value  float64
+}
+
+func sample(value float64, labels ...string) metricSample {
+	return metricSample{labels: labels, value: value}
+}
+
+// metricsWriter writes the Prometheus text format, keeping the first error
+type metricsWriter struct {
+	w   io.Writer
+	err error
+}
+
+// gauge writes a gauge family. Families without samples are left out.
+func (m *metricsWriter) gauge(name, help string, samples ...metricSample) {
+	m.family("gauge", name, help, samples)
+}
+
+// counter writes a counter family. Families without samples are left out.
+func (m *metricsWriter) counter(name, help string, samples ...metricSample) {
+	m.family("counter", name, help, samples)
+}
+
+// family writes a metric family of the given type
+func (m *metricsWriter) family(kind, name, help string, samples []metricSample) {
+	if m.err != nil || len(samples) == 0 {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	for _, s := range samples {
+		b.WriteString(name)
+		if len(s.labels) > 0 {
+			b.WriteByte('{')
+			for i := 0; i+1 < len(s.labels); i += 2 {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				fmt.Fprintf(&b, "%s=\"%s\"", s.labels[i], labelEscaper.Replace(s.labels[i+1]))
+			}
+			b.WriteByte('}')
+		}
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatFloat(s.value, 'g', -1, 64))
+		b.WriteByte('\n')
+	}
+	_, m.err = io.WriteString(m.w, b.String())
+}
+
+// labelEscaper escapes label values as the text format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// diagnosticPLC is a fakePLC that reports controller diagnostics
+type diagnosticPLC struct {
+	fakePLC
+	err error
+}
+
+func (d *diagnosticPLC) Diagnostics() (*ethernetip.ControllerDiagnostics, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	cpu := 42.0
+	return &ethernetip.ControllerDiagnostics{
+		Timestamp:      time.Now(),
+		CPUUtilization: &cpu,
+		Forces:         &ethernetip.ForcesStatus{Installed: true, Enabled: true, Raw: 3},
+		Tasks: []ethernetip.TaskDiagnostics{
+			{Instance: 1, LastScan: 2 * time.Millisecond, MaxScan: 5 * time.Millisecond, AvgScan: 3 * time.Millisecond},
+		},
+	}, nil
+}
+
+// TestMetrics tests the Prometheus exposition of gateway and controller metrics
+func TestMetrics(t *testing.T) {
+	plc := &diagnosticPLC{}
+	s := NewServer(plc)
+	defer s.Close()
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("Unexpected content type %s", rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE eip_gateway_stream_consumers gauge\neip_gateway_stream_consumers 0\n",
+		`eip_gateway_subscriptions{state="stalled"} 0`,
+		"eip_controller_diagnostics_up 1\n",
+		"eip_controller_cpu_utilization_percent 42\n",
+		`eip_controller_task_max_scan_seconds{task="1"} 0.005`,
+		`eip_controller_task_avg_scan_seconds{task="1"} 0.003`,
+		"eip_controller_forces_enabled 1\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in metrics:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "comm_utilization") {
+		t.Error("Expected unreported utilization to be left out")
+	}
+
+	plc.err = errors.New("offline")
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "eip_controller_diagnostics_up 0\n") || strings.Contains(body, "task_last_scan") {
+		t.Errorf("Unexpected metrics after a failed read:\n%s", body)
+	}
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/diagnostics", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502, got %d", rec.Code)
+	}
+}
+
+// TestDiagnosticsUnsupported tests a PLC client without diagnostics
+func TestDiagnosticsUnsupported(t *testing.T) {
+	s := NewServer(&fakePLC{})
+	defer s.Close()
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if strings.Contains(rec.Body.String(), "eip_controller") {
+		t.Errorf("Expected only gateway metrics:\n%s", rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/diagnostics", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502, got %d", rec.Code)
+	}
+}
+
+// TestLabelEscaping tests escaping of label values
+func TestLabelEscaping(t *testing.T) {
+	var b strings.Builder
+	m := &metricsWriter{w: &b}
+	m.gauge("x", "help", sample(1, "tag", "a\"b\\c\n"))
+	if !strings.Contains(b.String(), `x{tag="a\"b\\c\n"} 1`) {
+		t.Errorf("Unexpected output %q", b.String())
+	}
+}
+
+// overloadedPLC is a fakePLC whose client sheds every read and reconnects
+type overloadedPLC struct {
+	fakePLC
+}
+
+func (o *overloadedPLC) ReadValue(tagName string, dataType ethernetip.PlcDataType) (*ethernetip.PlcValue, error) {
+	return nil, ethernetip.NewEipError(ethernetip.ErrOverloaded, "read of '"+tagName+"' rejected")
+}
+
+func (o *overloadedPLC) QueueStats() ethernetip.QueueStats {
+	return ethernetip.QueueStats{Depth: 3, PeakDepth: 8, Submitted: 40, Shed: 5, WaitTotal: 2 * time.Second}
+}
+
+func (o *overloadedPLC) SessionDiagnostics() ethernetip.SessionDiagnostics {
+	return ethernetip.SessionDiagnostics{Reconnects: map[string]int64{"keep_alive_failure": 2, "explicit_close": 1}}
+}
+
+// TestOverload tests that shed reads answer 503 and the queue and reconnects
+// are exported as metrics
+func TestOverload(t *testing.T) {
+	s := NewServer(&overloadedPLC{})
+	defer s.Close()
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tag?name=Speed&type=REAL", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 503 with Retry-After for a shed read, got %d %v", rec.Code, rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE eip_queue_depth gauge\neip_queue_depth 3\n",
+		"eip_queue_peak_depth 8\n",
+		"# TYPE eip_queue_shed_total counter\neip_queue_shed_total 5\n",
+		"eip_queue_operations_total 40\n",
+		"eip_queue_wait_seconds_total 2\n",
+		"# TYPE eip_session_reconnects_total counter\neip_session_reconnects_total{reason=\"explicit_close\"} 1\neip_session_reconnects_total{reason=\"keep_alive_failure\"} 2\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in metrics:\n%s", want, body)
+		}
+	}
+}
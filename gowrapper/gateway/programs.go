@@ -0,0 +1,49 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// programsPLC is implemented by clients that list the controller's tasks and
+// programs, such as *ethernetip.EipClient
+type programsPLC interface {
+	ListTasks(ctx context.Context) ([]ethernetip.TaskInfo, error)
+	ListPrograms(ctx context.Context) ([]ethernetip.ProgramInfo, error)
+}
+
+// ControllerPrograms is the response of GET /api/programs
+type ControllerPrograms struct {
+	Tasks    []ethernetip.TaskInfo    `json:"tasks"`
+	Programs []ethernetip.ProgramInfo `json:"programs"`
+}
+
+// Programs lists the controller's tasks and its programs with their routines.
+// It fails with ErrInvalidOperation if the PLC client cannot list them.
+func (s *Server) Programs(ctx context.Context) (*ControllerPrograms, error) {
+	plc, ok := s.plc.(programsPLC)
+	if !ok {
+		return nil, ethernetip.NewEipError(ethernetip.ErrInvalidOperation, "the PLC client does not list programs")
+	}
+	tasks, err := plc.ListTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	programs, err := plc.ListPrograms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ControllerPrograms{Tasks: tasks, Programs: programs}, nil
+}
+
+// handlePrograms handles GET /api/programs
+func (s *Server) handlePrograms(w http.ResponseWriter, r *http.Request) {
+	programs, err := s.Programs(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	s.writeResponse(w, r, http.StatusOK, programs)
+}
@@ -0,0 +1,68 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// programPLC is a fakePLC that lists tasks and programs
+type programPLC struct {
+	fakePLC
+}
+
+func (p *programPLC) ListTasks(ctx context.Context) ([]ethernetip.TaskInfo, error) {
+	return []ethernetip.TaskInfo{
+		{Instance: 1, Name: "MainTask", Type: ethernetip.TaskContinuous, Priority: 10, Watchdog: 500 * time.Millisecond},
+		{Instance: 2, Name: "Fast", Type: ethernetip.TaskPeriodic, Priority: 5, Rate: 10 * time.Millisecond, Inhibited: true},
+	}, nil
+}
+
+func (p *programPLC) ListPrograms(ctx context.Context) ([]ethernetip.ProgramInfo, error) {
+	return []ethernetip.ProgramInfo{
+		{Instance: 1, Name: "Main", Routines: []ethernetip.RoutineInfo{{Instance: 1, Name: "MainRoutine"}}},
+	}, nil
+}
+
+// TestPrograms tests listing tasks and programs, and a PLC client that
+// cannot list them
+func TestPrograms(t *testing.T) {
+	s := NewServer(&programPLC{})
+	defer s.Close()
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/programs", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var got struct {
+		Tasks []struct {
+			Name      string `json:"name"`
+			Type      string `json:"type"`
+			Inhibited bool   `json:"inhibited"`
+		} `json:"tasks"`
+		Programs []ethernetip.ProgramInfo `json:"programs"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tasks) != 2 || got.Tasks[1].Type != "PERIODIC" || !got.Tasks[1].Inhibited {
+		t.Errorf("Unexpected tasks %+v", got.Tasks)
+	}
+	if len(got.Programs) != 1 || got.Programs[0].Routines[0].Name != "MainRoutine" {
+		t.Errorf("Unexpected programs %+v", got.Programs)
+	}
+
+	s = NewServer(&fakePLC{})
+	defer s.Close()
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/programs", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,88 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// selfTestPLC is implemented by clients that can run a startup self-test,
+// such as *ethernetip.EipClient
+type selfTestPLC interface {
+	SelfTest(ctx context.Context, opts ethernetip.SelfTestOptions) (*ethernetip.SelfTestReport, error)
+}
+
+// selfTestState holds the self-test options and the last report
+type selfTestState struct {
+	mu     sync.Mutex
+	opts   ethernetip.SelfTestOptions
+	report *ethernetip.SelfTestReport
+}
+
+// SetSelfTestOptions sets the metadata and scratch tags RunSelfTest checks
+func (s *Server) SetSelfTestOptions(opts ethernetip.SelfTestOptions) {
+	s.selfTest.mu.Lock()
+	s.selfTest.opts = opts
+	s.selfTest.mu.Unlock()
+}
+
+// RunSelfTest runs the PLC client's self-test and keeps the report for GET
+// /api/selftest. Call it at boot to refuse to serve a controller the gateway
+// cannot talk to. It fails with ErrInvalidOperation if the PLC client has no
+// self-test.
+func (s *Server) RunSelfTest(ctx context.Context) (*ethernetip.SelfTestReport, error) {
+	plc, ok := s.plc.(selfTestPLC)
+	if !ok {
+		return nil, ethernetip.NewEipError(ethernetip.ErrInvalidOperation, "the PLC client does not support self-tests")
+	}
+	s.selfTest.mu.Lock()
+	opts := s.selfTest.opts
+	s.selfTest.mu.Unlock()
+
+	report, err := plc.SelfTest(ctx, opts)
+	if report != nil {
+		s.selfTest.mu.Lock()
+		s.selfTest.report = report
+		s.selfTest.mu.Unlock()
+	}
+	return report, err
+}
+
+// SelfTestReport returns the report of the last RunSelfTest, or nil
+func (s *Server) SelfTestReport() *ethernetip.SelfTestReport {
+	s.selfTest.mu.Lock()
+	defer s.selfTest.mu.Unlock()
+	return s.selfTest.report
+}
+
+// handleSelfTest handles GET /api/selftest, returning the last report and
+// running a self-test if none has run yet
+func (s *Server) handleSelfTest(w http.ResponseWriter, r *http.Request) {
+	if report := s.SelfTestReport(); report != nil {
+		s.writeSelfTestReport(w, r, report)
+		return
+	}
+	s.handleRunSelfTest(w, r)
+}
+
+// handleRunSelfTest handles POST /api/selftest
+func (s *Server) handleRunSelfTest(w http.ResponseWriter, r *http.Request) {
+	report, err := s.RunSelfTest(r.Context())
+	if report == nil {
+		writeError(w, http.StatusNotImplemented, err.Error())
+		return
+	}
+	s.writeSelfTestReport(w, r, report)
+}
+
+// writeSelfTestReport writes a report with 503 Service Unavailable if it
+// failed, so load balancers and orchestrators can use it as a health check
+func (s *Server) writeSelfTestReport(w http.ResponseWriter, r *http.Request, report *ethernetip.SelfTestReport) {
+	status := http.StatusOK
+	if !report.Passed {
+		status = http.StatusServiceUnavailable
+	}
+	s.writeResponse(w, r, status, report)
+}
@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// selfTestingPLC is a fakePLC with a self-test
+type selfTestingPLC struct {
+	fakePLC
+	fail bool
+	runs int
+	opts ethernetip.SelfTestOptions
+}
+
+func (p *selfTestingPLC) SelfTest(ctx context.Context, opts ethernetip.SelfTestOptions) (*ethernetip.SelfTestReport, error) {
+	p.runs++
+	p.opts = opts
+	report := &ethernetip.SelfTestReport{Passed: !p.fail, Checks: []ethernetip.SelfTestCheck{
+		{Name: ethernetip.SelfTestSession, Status: ethernetip.SelfTestPassed},
+	}}
+	if p.fail {
+		report.Checks[0].Status = ethernetip.SelfTestFailed
+		return report, errors.New("no session")
+	}
+	return report, nil
+}
+
+// TestSelfTestEndpoint tests running and reporting the self-test
+func TestSelfTestEndpoint(t *testing.T) {
+	plc := &selfTestingPLC{}
+	s := NewServer(plc)
+	defer s.Close()
+	s.SetSelfTestOptions(ethernetip.SelfTestOptions{ScratchTag: "Scratch", ScratchType: ethernetip.Dint})
+
+	get := func(method string) (*httptest.ResponseRecorder, ethernetip.SelfTestReport) {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(method, "/api/selftest", nil))
+		var report ethernetip.SelfTestReport
+		json.Unmarshal(rec.Body.Bytes(), &report)
+		return rec, report
+	}
+
+	// The first GET runs a self-test, later ones return its report
+	rec, report := get(http.MethodGet)
+	if rec.Code != http.StatusOK || !report.Passed || len(report.Checks) != 1 {
+		t.Fatalf("Unexpected response %d %s", rec.Code, rec.Body)
+	}
+	get(http.MethodGet)
+	if plc.runs != 1 || plc.opts.ScratchTag != "Scratch" {
+		t.Errorf("Expected one run with the configured options, got %d %+v", plc.runs, plc.opts)
+	}
+
+	plc.fail = true
+	rec, report = get(http.MethodPost)
+	if rec.Code != http.StatusServiceUnavailable || report.Passed || plc.runs != 2 {
+		t.Errorf("Expected a failed run to return 503, got %d %s", rec.Code, rec.Body)
+	}
+	if s.SelfTestReport().Passed {
+		t.Error("Expected the failed report to be kept")
+	}
+}
+
+// TestSelfTestUnsupported tests a PLC client without a self-test
+func TestSelfTestUnsupported(t *testing.T) {
+	s := NewServer(&fakePLC{})
+	defer s.Close()
+	if _, err := s.RunSelfTest(context.Background()); err == nil {
+		t.Error("Expected error")
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/selftest", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("Expected 501, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,670 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Serializer encodes gateway payloads in one wire format. Clients pick a
+// format with the Accept header or the format query parameter; JSON is the
+// default. Streams send one encoded payload after another, so formats other
+// than JSON must be self-delimiting.
+type Serializer interface {
+	// Name is the value of the format query parameter that selects it
+	Name() string
+	// ContentType is the media type matched against Accept
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+}
+
+// Built-in serializers
+var (
+	JSON        Serializer = jsonSerializer{}
+	MessagePack Serializer = msgpackSerializer{}
+	CBOR        Serializer = cborSerializer{}
+)
+
+// contentTypeAliases maps alternative media types to the ones the built-in
+// serializers report
+var contentTypeAliases = map[string]string{
+	"application/x-msgpack":   "application/msgpack",
+	"application/vnd.msgpack": "application/msgpack",
+	"application/cbor-seq":    "application/cbor",
+}
+
+// serializerRegistry holds the formats a server can negotiate, in preference order
+type serializerRegistry struct {
+	mu          sync.RWMutex
+	serializers []Serializer
+}
+
+// RegisterSerializer makes a format available for negotiation, replacing a
+// registered serializer of the same name. JSON, MessagePack and CBOR are
+// registered by NewServer.
+func (s *Server) RegisterSerializer(serializer Serializer) {
+	s.serializers.mu.Lock()
+	defer s.serializers.mu.Unlock()
+	for i, existing := range s.serializers.serializers {
+		if existing.Name() == serializer.Name() {
+			s.serializers.serializers[i] = serializer
+			return
+		}
+	}
+	s.serializers.serializers = append(s.serializers.serializers, serializer)
+}
+
+// negotiate picks the serializer for a request: the format query parameter if
+// given, otherwise the registered type with the highest quality in Accept,
+// falling back to JSON
+func (s *Server) negotiate(r *http.Request) Serializer {
+	s.serializers.mu.RLock()
+	defer s.serializers.mu.RUnlock()
+	if name := r.URL.Query().Get("format"); name != "" {
+		for _, serializer := range s.serializers.serializers {
+			if strings.EqualFold(serializer.Name(), name) {
+				return serializer
+			}
+		}
+		return JSON
+	}
+
+	best, bestQ := JSON, 0.0
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		if alias, ok := contentTypeAliases[mediaType]; ok {
+			mediaType = alias
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		for _, serializer := range s.serializers.serializers {
+			if serializer.ContentType() == mediaType {
+				best, bestQ = serializer, q
+				break
+			}
+		}
+	}
+	return best
+}
+
+// writeResponse writes v with the given status in the format negotiated for r
+func (s *Server) writeResponse(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	serializer := s.negotiate(r)
+	if serializer == JSON {
+		writeJSON(w, status, v)
+		return
+	}
+	data, err := serializer.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", serializer.ContentType())
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// jsonSerializer encodes payloads as JSON
+type jsonSerializer struct{}
+
+func (jsonSerializer) Name() string        { return "json" }
+func (jsonSerializer) ContentType() string { return "application/json" }
+
+func (jsonSerializer) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// msgpackSerializer encodes payloads as MessagePack
+type msgpackSerializer struct{}
+
+func (msgpackSerializer) Name() string        { return "msgpack" }
+func (msgpackSerializer) ContentType() string { return "application/msgpack" }
+
+func (msgpackSerializer) Marshal(v interface{}) ([]byte, error) {
+	e := &msgpackEncoder{}
+	if err := encodeValue(e, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// cborSerializer encodes payloads as CBOR (RFC 8949)
+type cborSerializer struct{}
+
+func (cborSerializer) Name() string        { return "cbor" }
+func (cborSerializer) ContentType() string { return "application/cbor" }
+
+func (cborSerializer) Marshal(v interface{}) ([]byte, error) {
+	e := &cborEncoder{}
+	if err := encodeValue(e, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// valueEncoder writes the items of a binary format. encodeValue walks a Go
+// value and calls it the way encoding/json would emit the same value, so the
+// binary formats carry the same field names and structure as JSON.
+type valueEncoder interface {
+	writeNil()
+	writeBool(b bool)
+	writeInt(i int64)
+	writeUint(u uint64)
+	writeFloat32(f float32)
+	writeFloat64(f float64)
+	writeString(s string)
+	writeBytes(b []byte)
+	writeTime(t time.Time)
+	arrayHeader(n int)
+	mapHeader(n int)
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	numberType        = reflect.TypeOf(json.Number(""))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// encodeValue encodes v following the encoding/json rules: struct field tags,
+// omitempty, embedded structs, json.Marshaler and encoding.TextMarshaler.
+// Times are written in the format's native timestamp encoding.
+func encodeValue(e valueEncoder, v reflect.Value) error {
+	if !v.IsValid() {
+		e.writeNil()
+		return nil
+	}
+	t := v.Type()
+	switch {
+	case t == timeType:
+		e.writeTime(v.Interface().(time.Time))
+		return nil
+	case t == numberType:
+		return encodeNumber(e, v.Interface().(json.Number))
+	case (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil():
+		e.writeNil()
+		return nil
+	case t.Implements(jsonMarshalerType):
+		return encodeMarshaler(e, v.Interface().(json.Marshaler))
+	case v.CanAddr() && reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return encodeMarshaler(e, v.Addr().Interface().(json.Marshaler))
+	case t.Implements(textMarshalerType):
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		e.writeString(string(text))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		e.writeBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.writeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.writeUint(v.Uint())
+	case reflect.Float32:
+		e.writeFloat32(float32(v.Float()))
+	case reflect.Float64:
+		e.writeFloat64(v.Float())
+	case reflect.String:
+		e.writeString(v.String())
+	case reflect.Pointer, reflect.Interface:
+		return encodeValue(e, v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.writeNil()
+			return nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			e.writeBytes(v.Bytes())
+			return nil
+		}
+		return encodeArray(e, v)
+	case reflect.Array:
+		return encodeArray(e, v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.writeNil()
+			return nil
+		}
+		return encodeMap(e, v)
+	case reflect.Struct:
+		return encodeStruct(e, v)
+	default:
+		return fmt.Errorf("unsupported type %s", t)
+	}
+	return nil
+}
+
+// encodeNumber encodes a json.Number as an integer when it is one
+func encodeNumber(e valueEncoder, n json.Number) error {
+	if i, err := n.Int64(); err == nil {
+		e.writeInt(i)
+		return nil
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		e.writeUint(u)
+		return nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	e.writeFloat64(f)
+	return nil
+}
+
+// encodeMarshaler encodes a type with custom JSON by decoding its JSON into
+// generic values
+func encodeMarshaler(e valueEncoder, m json.Marshaler) error {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return err
+	}
+	return encodeValue(e, reflect.ValueOf(generic))
+}
+
+func encodeArray(e valueEncoder, v reflect.Value) error {
+	e.arrayHeader(v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if err := encodeValue(e, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeMap encodes a map with its keys converted to strings and sorted, as
+// encoding/json does
+func encodeMap(e valueEncoder, v reflect.Value) error {
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{key, iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	e.mapHeader(len(entries))
+	for _, entry := range entries {
+		e.writeString(entry.key)
+		if err := encodeValue(e, entry.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerType) {
+		text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type %s", k.Type())
+}
+
+func encodeStruct(e valueEncoder, v reflect.Value) error {
+	type present struct {
+		name  string
+		value reflect.Value
+	}
+	var fields []present
+	for _, f := range cachedFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		fields = append(fields, present{f.name, fv})
+	}
+	e.mapHeader(len(fields))
+	for _, f := range fields {
+		e.writeString(f.name)
+		if err := encodeValue(e, f.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex that reports false when an
+// embedded pointer on the way is nil
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// structField is an encoded field of a struct type
+type structField struct {
+	name      string
+	index     []int
+	depth     int
+	omitEmpty bool
+}
+
+var fieldCache sync.Map // reflect.Type -> []structField
+
+// cachedFields returns the encoded fields of a struct type. Fields of embedded
+// structs are promoted; of fields sharing a name the shallowest wins.
+func cachedFields(t reflect.Type) []structField {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	var all []structField
+	collectFields(t, nil, &all)
+	byName := map[string]int{}
+	var fields []structField
+	for _, f := range all {
+		if i, seen := byName[f.name]; seen {
+			if f.depth < fields[i].depth {
+				fields[i] = f
+			}
+			continue
+		}
+		byName[f.name] = len(fields)
+		fields = append(fields, f)
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, index []int, out *[]structField) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectFields(ft, fieldIndex, out)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		*out = append(*out, structField{
+			name:      name,
+			index:     fieldIndex,
+			depth:     len(index),
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+}
+
+// msgpackEncoder writes MessagePack items
+type msgpackEncoder struct {
+	bytes.Buffer
+}
+
+func (e *msgpackEncoder) writeNil() { e.WriteByte(0xc0) }
+
+func (e *msgpackEncoder) writeBool(b bool) {
+	if b {
+		e.WriteByte(0xc3)
+	} else {
+		e.WriteByte(0xc2)
+	}
+}
+
+func (e *msgpackEncoder) writeInt(i int64) {
+	switch {
+	case i >= 0:
+		e.writeUint(uint64(i))
+	case i >= -32:
+		e.WriteByte(byte(i)) // Negative fixint
+	case i >= math.MinInt8:
+		e.Write([]byte{0xd0, byte(i)})
+	case i >= math.MinInt16:
+		e.WriteByte(0xd1)
+		e.Write(binary.BigEndian.AppendUint16(nil, uint16(i)))
+	case i >= math.MinInt32:
+		e.WriteByte(0xd2)
+		e.Write(binary.BigEndian.AppendUint32(nil, uint32(i)))
+	default:
+		e.WriteByte(0xd3)
+		e.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	}
+}
+
+func (e *msgpackEncoder) writeUint(u uint64) {
+	switch {
+	case u <= 0x7f:
+		e.WriteByte(byte(u)) // Positive fixint
+	case u <= math.MaxUint8:
+		e.Write([]byte{0xcc, byte(u)})
+	case u <= math.MaxUint16:
+		e.WriteByte(0xcd)
+		e.Write(binary.BigEndian.AppendUint16(nil, uint16(u)))
+	case u <= math.MaxUint32:
+		e.WriteByte(0xce)
+		e.Write(binary.BigEndian.AppendUint32(nil, uint32(u)))
+	default:
+		e.WriteByte(0xcf)
+		e.Write(binary.BigEndian.AppendUint64(nil, u))
+	}
+}
+
+func (e *msgpackEncoder) writeFloat32(f float32) {
+	e.WriteByte(0xca)
+	e.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f)))
+}
+
+func (e *msgpackEncoder) writeFloat64(f float64) {
+	e.WriteByte(0xcb)
+	e.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+}
+
+func (e *msgpackEncoder) writeString(s string) {
+	e.length(len(s), 0xa0, 31, 0xd9, 0xda, 0xdb)
+	e.WriteString(s)
+}
+
+func (e *msgpackEncoder) writeBytes(b []byte) {
+	e.length(len(b), 0, -1, 0xc4, 0xc5, 0xc6)
+	e.Write(b)
+}
+
+// time writes the timestamp extension type (-1) in its smallest form
+func (e *msgpackEncoder) writeTime(t time.Time) {
+	sec, nsec := t.Unix(), uint64(t.Nanosecond())
+	switch {
+	case sec>>34 == 0 && nsec == 0 && sec <= math.MaxUint32:
+		e.Write([]byte{0xd6, 0xff})
+		e.Write(binary.BigEndian.AppendUint32(nil, uint32(sec)))
+	case sec>>34 == 0:
+		e.Write([]byte{0xd7, 0xff})
+		e.Write(binary.BigEndian.AppendUint64(nil, nsec<<34|uint64(sec)))
+	default:
+		e.Write([]byte{0xc7, 12, 0xff})
+		e.Write(binary.BigEndian.AppendUint32(nil, uint32(nsec)))
+		e.Write(binary.BigEndian.AppendUint64(nil, uint64(sec)))
+	}
+}
+
+func (e *msgpackEncoder) arrayHeader(n int) { e.length(n, 0x90, 15, 0, 0xdc, 0xdd) }
+func (e *msgpackEncoder) mapHeader(n int)   { e.length(n, 0x80, 15, 0, 0xde, 0xdf) }
+
+// length writes the type byte and length of a string, binary, array or map:
+// packed into fix for lengths up to fixMax (-1 for none), otherwise followed
+// by an 8-, 16- or 32-bit length. tag8 is 0 for types without the 8-bit form.
+func (e *msgpackEncoder) length(n int, fix byte, fixMax int, tag8, tag16, tag32 byte) {
+	switch {
+	case n <= fixMax:
+		e.WriteByte(fix | byte(n))
+	case n <= math.MaxUint8 && tag8 != 0:
+		e.Write([]byte{tag8, byte(n)})
+	case n <= math.MaxUint16:
+		e.WriteByte(tag16)
+		e.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		e.WriteByte(tag32)
+		e.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+// CBOR major types
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+)
+
+// cborEncoder writes CBOR items
+type cborEncoder struct {
+	bytes.Buffer
+}
+
+func (e *cborEncoder) writeNil() { e.WriteByte(0xf6) }
+
+func (e *cborEncoder) writeBool(b bool) {
+	if b {
+		e.WriteByte(0xf5)
+	} else {
+		e.WriteByte(0xf4)
+	}
+}
+
+func (e *cborEncoder) writeInt(i int64) {
+	if i >= 0 {
+		e.head(cborUint, uint64(i))
+	} else {
+		e.head(cborNegInt, uint64(-1-i))
+	}
+}
+
+func (e *cborEncoder) writeUint(u uint64) { e.head(cborUint, u) }
+
+func (e *cborEncoder) writeFloat32(f float32) {
+	e.WriteByte(0xfa)
+	e.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f)))
+}
+
+func (e *cborEncoder) writeFloat64(f float64) {
+	e.WriteByte(0xfb)
+	e.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+}
+
+func (e *cborEncoder) writeString(s string) {
+	e.head(cborText, uint64(len(s)))
+	e.WriteString(s)
+}
+
+func (e *cborEncoder) writeBytes(b []byte) {
+	e.head(cborBytes, uint64(len(b)))
+	e.Write(b)
+}
+
+// time writes tag 0, an RFC 3339 date/time string
+func (e *cborEncoder) writeTime(t time.Time) {
+	e.head(cborTag, 0)
+	e.writeString(t.Format(time.RFC3339Nano))
+}
+
+func (e *cborEncoder) arrayHeader(n int) { e.head(cborArray, uint64(n)) }
+func (e *cborEncoder) mapHeader(n int)   { e.head(cborMap, uint64(n)) }
+
+// head writes the initial byte of an item and its argument
+func (e *cborEncoder) head(major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		e.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		e.Write([]byte{major | 24, byte(n)})
+	case n <= math.MaxUint16:
+		e.WriteByte(major | 25)
+		e.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n <= math.MaxUint32:
+		e.WriteByte(major | 26)
+		e.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		e.WriteByte(major | 27)
+		e.Write(binary.BigEndian.AppendUint64(nil, n))
+	}
+}
@@ -0,0 +1,226 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// TestMessagePackEncoding tests MessagePack encodings of scalar and container values
+func TestMessagePackEncoding(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "c0"},
+		{true, "c3"},
+		{5, "05"},
+		{-1, "ff"},
+		{-33, "d0df"},
+		{200, "ccc8"},
+		{300, "cd012c"},
+		{70000, "ce00011170"},
+		{int64(-70000), "d2fffeee90"},
+		{float32(1.5), "ca3fc00000"},
+		{1.5, "cb3ff8000000000000"},
+		{"a", "a161"},
+		{strings.Repeat("x", 32), "d920" + strings.Repeat("78", 32)},
+		{[]byte{1}, "c40101"},
+		{[]int{1, 2}, "920102"},
+		{map[string]int{"b": 2, "a": 1}, "82a16101a16202"},
+		{time.Unix(1, 0), "d6ff00000001"},
+		{time.Unix(1, 1), "d7ff0000000400000001"},
+	}
+	for _, test := range tests {
+		data, err := MessagePack.Marshal(test.value)
+		if err != nil {
+			t.Errorf("%v: %v", test.value, err)
+			continue
+		}
+		if got := hex.EncodeToString(data); got != test.want {
+			t.Errorf("%#v: got %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+// TestCBOREncoding tests CBOR encodings against the examples of RFC 8949 Appendix A
+func TestCBOREncoding(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{100, "1864"},
+		{1000, "1903e8"},
+		{1000000, "1a000f4240"},
+		{-1, "20"},
+		{-100, "3863"},
+		{-1000, "3903e7"},
+		{1.1, "fb3ff199999999999a"},
+		{false, "f4"},
+		{nil, "f6"},
+		{"a", "6161"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{[]int{1, 2, 3}, "83010203"},
+		{map[string]interface{}{"a": 1, "b": []int{2, 3}}, "a26161016162820203"},
+		{time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC), "c074323031332d30332d32315432303a30343a30305a"},
+	}
+	for _, test := range tests {
+		data, err := CBOR.Marshal(test.value)
+		if err != nil {
+			t.Errorf("%v: %v", test.value, err)
+			continue
+		}
+		if got := hex.EncodeToString(data); got != test.want {
+			t.Errorf("%#v: got %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+// TestStructEncoding tests that binary formats follow the JSON field rules
+func TestStructEncoding(t *testing.T) {
+	type inner struct {
+		B int `json:"b,omitempty"`
+	}
+	type sample struct {
+		inner
+		A       string             `json:"a"`
+		C       *int               `json:"c,omitempty"`
+		D       int                `json:"-"`
+		Quality ethernetip.Quality `json:"q"`
+		hidden  int
+	}
+
+	data, err := CBOR.Marshal(sample{inner: inner{B: 2}, A: "x", D: 7, Quality: ethernetip.QualityGood, hidden: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// {"b": 2, "a": "x", "q": "good"}
+	if got, want := hex.EncodeToString(data), "a361620261616178617164676f6f64"; got != want {
+		t.Errorf("Got %s, want %s", got, want)
+	}
+
+	data, err = MessagePack.Marshal(&sample{A: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != 0x82 {
+		t.Errorf("Expected omitempty fields to be left out, got %x", data)
+	}
+
+	if _, err := CBOR.Marshal(map[string]interface{}{"f": func() {}}); err == nil {
+		t.Error("Expected error for a function value")
+	}
+}
+
+// TestNegotiation tests choosing the response format
+func TestNegotiation(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"Speed": float64(12.5)}}
+	s := NewServer(plc)
+	defer s.Close()
+
+	tests := []struct {
+		accept, query string
+		want          string
+	}{
+		{"", "", "application/json"},
+		{"text/html", "", "application/json"},
+		{"application/msgpack", "", "application/msgpack"},
+		{"application/x-msgpack", "", "application/msgpack"},
+		{"application/json;q=0.9, application/cbor", "", "application/cbor"},
+		{"application/cbor;q=0.5, application/json", "", "application/json"},
+		{"application/msgpack", "&format=cbor", "application/cbor"},
+		{"", "&format=nope", "application/json"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/tag?name=Speed&type=REAL"+test.query, nil)
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if got := rec.Header().Get("Content-Type"); got != test.want {
+			t.Errorf("Accept %q%s: got %s, want %s", test.accept, test.query, got, test.want)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tag?name=Speed&type=REAL&format=msgpack", nil)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	// A map of the four TagValue fields, starting with "tag": "Speed"
+	if body := rec.Body.Bytes(); !bytes.HasPrefix(body, []byte("\x84\xa3tag\xa5Speed")) {
+		t.Errorf("Unexpected MessagePack body %x", body)
+	}
+
+	// Errors stay JSON
+	req = httptest.NewRequest(http.MethodGet, "/api/tag?name=Missing&type=REAL&format=msgpack", nil)
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadGateway || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected error response %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
+
+// customSerializer is a registered third-party format
+type customSerializer struct{}
+
+func (customSerializer) Name() string                          { return "custom" }
+func (customSerializer) ContentType() string                   { return "application/x-custom" }
+func (customSerializer) Marshal(v interface{}) ([]byte, error) { return []byte("custom"), nil }
+
+// TestRegisterSerializer tests adding a format
+func TestRegisterSerializer(t *testing.T) {
+	s := NewServer(&fakePLC{})
+	defer s.Close()
+	s.RegisterSerializer(customSerializer{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/groups", nil)
+	req.Header.Set("Accept", "application/x-custom")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Header().Get("Content-Type") != "application/x-custom" || rec.Body.String() != "custom" {
+		t.Errorf("Unexpected response %s %q", rec.Header().Get("Content-Type"), rec.Body)
+	}
+}
+
+// TestBinaryStream tests streaming samples in a binary format
+func TestBinaryStream(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"Level": 4.5}}
+	s := NewServer(plc)
+	defer s.Close()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/stream?tag=Level:REAL", nil)
+	req.Header.Set("Accept", "application/cbor")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/cbor" {
+		t.Fatalf("Expected application/cbor, got %s", ct)
+	}
+
+	// A map of the five encoded TagSample fields, starting with "tag_name": "Level"
+	want := []byte("\xa5\x68tag_name\x65Level")
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(resp.Body, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Got %x, want %x", got, want)
+	}
+}
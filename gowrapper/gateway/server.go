@@ -0,0 +1,185 @@
+// Package gateway exposes an EtherNet/IP client over HTTP.
+//
+// The Server is a plain http.Handler so it can be mounted on any router or
+// served directly with http.ListenAndServe.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// PLC is the client functionality used by the gateway. *ethernetip.EipClient
+// implements it; tests can substitute a fake. Writes go through
+// WriteValueContext so they carry the caller identity of the request.
+type PLC interface {
+	ethernetip.Client
+	WriteValueContext(ctx context.Context, tagName string, value *ethernetip.PlcValue) error
+	ReadTags(tags map[string]ethernetip.PlcDataType) (map[string]*ethernetip.PlcValue, map[string]error)
+	DiscoverTagDatabase(ctx context.Context, progress func(found int)) (*ethernetip.TagDatabase, error)
+}
+
+// Server is an HTTP gateway in front of a PLC client
+type Server struct {
+	plc PLC
+	mux *http.ServeMux
+
+	// ctx is cancelled by Close to stop background work such as discovery
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	tags      atomic.Pointer[ethernetip.TagDatabase]
+	discovery discoveryJob
+	groups    groupRegistry
+	streams   groupStreams
+	selfTest  selfTestState
+
+	serializers serializerRegistry
+
+	coalesceWindow atomic.Int64
+	coalesce       coalescer
+
+	identify atomic.Pointer[IdentityFunc]
+
+	poller *ethernetip.Poller
+	hub    *ethernetip.Hub
+	types  *ethernetip.TagTypes
+}
+
+// NewServer creates a gateway for plc
+func NewServer(plc PLC) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	poller := ethernetip.NewPoller(plc)
+	s := &Server{
+		plc:    plc,
+		mux:    http.NewServeMux(),
+		ctx:    ctx,
+		cancel: cancel,
+		poller: poller,
+		hub:    ethernetip.NewHub(poller, DefaultHubInterval),
+	}
+	for _, serializer := range []Serializer{JSON, MessagePack, CBOR} {
+		s.RegisterSerializer(serializer)
+	}
+	if typed, ok := plc.(typedPLC); ok {
+		s.types = typed.TagTypes()
+	} else {
+		s.types = &ethernetip.TagTypes{}
+	}
+	s.routes()
+	return s
+}
+
+// routes registers the gateway endpoints
+func (s *Server) routes() {
+	s.mux.HandleFunc("POST /api/discover", s.handleStartDiscovery)
+	s.mux.HandleFunc("GET /api/discover", s.handleDiscoveryStatus)
+	s.mux.HandleFunc("GET /api/tags", s.handleTags)
+	s.mux.HandleFunc("GET /api/tags/export", s.handleExportTags)
+	s.mux.HandleFunc("POST /api/tags/import", s.handleImportTags)
+	s.mux.HandleFunc("GET /api/tag", s.handleReadTag)
+	s.mux.HandleFunc("GET /api/tag/wait", s.handleWaitTag)
+	s.mux.HandleFunc("GET /api/stream", s.handleStream)
+	s.mux.HandleFunc("GET /api/subscriptions/health", s.handleSubscriptionHealth)
+	s.mux.HandleFunc("GET /api/diagnostics", s.handleDiagnostics)
+	s.mux.HandleFunc("GET /api/programs", s.handlePrograms)
+	s.mux.HandleFunc("GET /metrics", s.handleMetrics)
+	s.mux.HandleFunc("GET /api/selftest", s.handleSelfTest)
+	s.mux.HandleFunc("POST /api/selftest", s.handleRunSelfTest)
+	s.mux.HandleFunc("POST /api/groups", s.handleDefineGroup)
+	s.mux.HandleFunc("GET /api/groups", s.handleListGroups)
+	s.mux.HandleFunc("GET /api/groups/{name}", s.handleGetGroup)
+	s.mux.HandleFunc("DELETE /api/groups/{name}", s.handleDeleteGroup)
+	s.mux.HandleFunc("GET /api/groups/{name}/values", s.handleReadGroup)
+	s.mux.HandleFunc("GET /api/groups/{name}/stream", s.handleStreamGroup)
+	s.mux.HandleFunc("POST /api/writes", s.handleWriteStream)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close stops background work started by the server and waits for it to finish
+func (s *Server) Close() {
+	s.cancel()
+	s.hub.Close()
+	s.poller.Close()
+	s.wg.Wait()
+}
+
+// Clock returns the clock the server times coalescing windows and polling
+// on: its poller's, which follows the PLC client's (see EipClient.SetClock)
+func (s *Server) Clock() ethernetip.Clock {
+	return s.poller.Clock()
+}
+
+// TagDatabase returns the tag database currently served by the gateway
+func (s *Server) TagDatabase() *ethernetip.TagDatabase {
+	return s.tags.Load()
+}
+
+// handleTags lists the tags of the current tag database, or those matching
+// the search in the query (see tagSearch)
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request) {
+	db := s.tags.Load()
+	if db == nil {
+		writeError(w, http.StatusNotFound, "no tag database; run POST /api/discover first")
+		return
+	}
+	pattern, filter, search, err := tagSearch(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !search {
+		s.writeResponse(w, r, http.StatusOK, db)
+		return
+	}
+	tags, err := s.SearchTags(pattern, filter)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if tags == nil {
+		tags = []ethernetip.TagInfo{}
+	}
+	s.writeResponse(w, r, http.StatusOK, &ethernetip.TagDatabase{Tags: tags, DiscoveredAt: db.DiscoveredAt})
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"error": message})
+}
+
+// writePLCError writes the error of a failed PLC read. Reads the client shed
+// under overload are answered with 503 and a Retry-After header, other
+// failures with 502.
+func writePLCError(w http.ResponseWriter, err error) {
+	if isOverloaded(err) {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeError(w, http.StatusBadGateway, err.Error())
+}
+
+// isOverloaded reports whether err is a read the client shed
+func isOverloaded(err error) bool {
+	var eipErr *ethernetip.EipError
+	return errors.As(err, &eipErr) && eipErr.Code == ethernetip.ErrOverloaded
+}
@@ -0,0 +1,177 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// fakePLC is an in-memory PLC for gateway tests
+type fakePLC struct {
+	tags     []ethernetip.TagInfo
+	release  chan struct{}
+	discover error
+	values   map[string]interface{}
+	readOnly map[string]bool
+	callers  []ethernetip.CallerIdentity // identities of WriteValueContext calls
+	mu       sync.Mutex
+
+	reads      atomic.Int32 // ReadValue calls
+	batchReads atomic.Int32 // ReadTags calls
+}
+
+func (f *fakePLC) ReadValue(tagName string, dataType ethernetip.PlcDataType) (*ethernetip.PlcValue, error) {
+	f.reads.Add(1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.values[tagName]
+	if !ok {
+		return nil, errors.New("tag not found: " + tagName)
+	}
+	return &ethernetip.PlcValue{Type: dataType, Value: v}, nil
+}
+
+func (f *fakePLC) WriteValue(tagName string, value *ethernetip.PlcValue) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.readOnly[tagName] {
+		return errors.New("tag is read-only: " + tagName)
+	}
+	f.values[tagName] = value.Value
+	return nil
+}
+
+func (f *fakePLC) WriteValueContext(ctx context.Context, tagName string, value *ethernetip.PlcValue) error {
+	if id, ok := ethernetip.CallerIdentityFromContext(ctx); ok {
+		f.mu.Lock()
+		f.callers = append(f.callers, id)
+		f.mu.Unlock()
+	}
+	return f.WriteValue(tagName, value)
+}
+
+func (f *fakePLC) ReadTags(tags map[string]ethernetip.PlcDataType) (map[string]*ethernetip.PlcValue, map[string]error) {
+	f.batchReads.Add(1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	results := make(map[string]*ethernetip.PlcValue, len(tags))
+	errs := make(map[string]error)
+	for name, dataType := range tags {
+		v, ok := f.values[name]
+		if !ok {
+			errs[name] = errors.New("tag not found: " + name)
+			continue
+		}
+		results[name] = &ethernetip.PlcValue{Type: dataType, Value: v}
+	}
+	return results, errs
+}
+
+func (f *fakePLC) set(tagName string, value interface{}) {
+	f.mu.Lock()
+	f.values[tagName] = value
+	f.mu.Unlock()
+}
+
+func (f *fakePLC) DiscoverTagDatabase(ctx context.Context, progress func(found int)) (*ethernetip.TagDatabase, error) {
+	for i := range f.tags {
+		progress(i + 1)
+	}
+	if f.release != nil {
+		select {
+		case <-f.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if f.discover != nil {
+		return nil, f.discover
+	}
+	return ethernetip.NewTagDatabase(f.tags), nil
+}
+
+// waitForState polls the discovery status until it reaches state
+func waitForState(t *testing.T, s *Server, state string) DiscoveryStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if status := s.DiscoveryStatus(); status.State == state {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for discovery state %s", state)
+	return DiscoveryStatus{}
+}
+
+// TestDiscoverEndpoint tests asynchronous discovery through the HTTP API
+func TestDiscoverEndpoint(t *testing.T) {
+	plc := &fakePLC{
+		tags:    []ethernetip.TagInfo{{Name: "Speed"}, {Name: "Level"}},
+		release: make(chan struct{}),
+	}
+	s := NewServer(plc)
+	defer s.Close()
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tags", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 before discovery, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/discover", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/discover", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 while discovery is running, got %d", rec.Code)
+	}
+
+	close(plc.release)
+	status := waitForState(t, s, DiscoveryCompleted)
+	if status.TagsFound != 2 || status.FinishedAt == nil {
+		t.Errorf("Unexpected completed status: %+v", status)
+	}
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tags", nil))
+	var db ethernetip.TagDatabase
+	if err := json.NewDecoder(rec.Body).Decode(&db); err != nil {
+		t.Fatalf("Failed to decode tag database: %v", err)
+	}
+	if len(db.Tags) != 2 {
+		t.Errorf("Expected 2 tags, got %d", len(db.Tags))
+	}
+}
+
+// TestDiscoverFailureKeepsDatabase tests that a failed discovery keeps the previous database
+func TestDiscoverFailureKeepsDatabase(t *testing.T) {
+	plc := &fakePLC{tags: []ethernetip.TagInfo{{Name: "Speed"}}}
+	s := NewServer(plc)
+	defer s.Close()
+
+	s.StartDiscovery()
+	waitForState(t, s, DiscoveryCompleted)
+
+	plc.discover = errors.New("connection lost")
+	s.StartDiscovery()
+	status := waitForState(t, s, DiscoveryFailed)
+	if status.Error != "connection lost" {
+		t.Errorf("Expected failure reason, got %q", status.Error)
+	}
+	if s.TagDatabase().Len() != 1 {
+		t.Error("Expected previous tag database to be kept")
+	}
+}
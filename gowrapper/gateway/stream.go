@@ -0,0 +1,125 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// DefaultHubInterval is how often the gateway polls tags watched through GET /api/stream
+const DefaultHubInterval = 500 * time.Millisecond
+
+// Hub returns the hub behind GET /api/stream. Embedding applications can add
+// their own consumers to share the same PLC polls.
+func (s *Server) Hub() *ethernetip.Hub {
+	return s.hub
+}
+
+// parseStreamTags parses tag parameters of the form Name:TYPE, or Name for
+// tags in the server's TagTypes
+func (s *Server) parseStreamTags(values []string) ([]ethernetip.GroupMember, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("at least one tag parameter is required")
+	}
+	members := make([]ethernetip.GroupMember, 0, len(values))
+	for _, v := range values {
+		// "Program:Main.Tag" contains a colon but has no type suffix
+		name, typeName := v, ""
+		if i := strings.LastIndex(v, ":"); i > 0 && !strings.Contains(v[i:], ".") {
+			name, typeName = v[:i], v[i+1:]
+		}
+		dataType, err := s.resolveType(name, typeName)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, ethernetip.GroupMember{TagName: name, DataType: dataType})
+	}
+	return members, nil
+}
+
+// handleStream handles GET /api/stream?tag=Speed:REAL&tag=Level, sending
+// every change of the watched tags as a server-sent event until the client
+// disconnects. All streams share one poll per tag. Clients that negotiate a
+// binary format receive the samples back to back in that format instead.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	members, err := s.parseStreamTags(r.URL.Query()["tag"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+	consumer, err := s.hub.Subscribe(ethernetip.ConsumerOptions{Tags: members})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	defer consumer.Close()
+
+	events := &eventWriter{w: w, flusher: flusher, serializer: s.negotiate(r)}
+	events.start()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.ctx.Done():
+			return
+		case sample, ok := <-consumer.C:
+			if !ok {
+				return
+			}
+			sample.Value = wireValue(sample.Type, sample.Value)
+			events.send("", sample)
+		}
+	}
+}
+
+// handleSubscriptionHealth handles GET /api/subscriptions/health, listing the
+// health of every poll loop behind the hub
+func (s *Server) handleSubscriptionHealth(w http.ResponseWriter, r *http.Request) {
+	s.writeResponse(w, r, http.StatusOK, s.poller.Health())
+}
+
+// eventWriter writes the payloads of a stream: as server-sent events for
+// JSON, or back to back for binary formats, which are self-delimiting
+type eventWriter struct {
+	w          http.ResponseWriter
+	flusher    http.Flusher
+	serializer Serializer
+}
+
+// start writes the response headers
+func (e *eventWriter) start() {
+	contentType := "text/event-stream"
+	if e.serializer != JSON {
+		contentType = e.serializer.ContentType()
+	}
+	e.w.Header().Set("Content-Type", contentType)
+	e.w.Header().Set("Cache-Control", "no-cache")
+	e.w.WriteHeader(http.StatusOK)
+	e.flusher.Flush()
+}
+
+// send writes one payload. event is the server-sent event type, empty for the
+// default; binary formats have no event types.
+func (e *eventWriter) send(event string, v interface{}) {
+	data, err := e.serializer.Marshal(v)
+	if err != nil {
+		return
+	}
+	if e.serializer != JSON {
+		e.w.Write(data)
+	} else if event != "" {
+		fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event, data)
+	} else {
+		fmt.Fprintf(e.w, "data: %s\n\n", data)
+	}
+	e.flusher.Flush()
+}
@@ -0,0 +1,84 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestStreamSharesPolls tests that concurrent streams of a tag share one poll
+func TestStreamSharesPolls(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"Level": 4.5}}
+	s := NewServer(plc)
+	defer s.Close()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/stream?tag=Level:REAL", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var sample struct {
+				TagName string      `json:"tag_name"`
+				Value   interface{} `json:"value"`
+				Quality string      `json:"quality"`
+			}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &sample); err != nil {
+				t.Fatalf("Invalid event %q: %v", line, err)
+			}
+			if sample.TagName != "Level" || sample.Value != 4.5 || sample.Quality != "good" {
+				t.Errorf("Unexpected sample: %+v", sample)
+			}
+			break
+		}
+	}
+
+	if n := s.Hub().ConsumerCount(); n != 3 {
+		t.Errorf("Expected 3 consumers, got %d", n)
+	}
+	if n := s.Hub().TagCount(); n != 1 {
+		t.Errorf("Expected 1 polled tag, got %d", n)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/subscriptions/health", nil))
+	var health []struct {
+		TagName string `json:"tag_name"`
+		State   string `json:"state"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if len(health) != 1 || health[0].TagName != "Level" || health[0].State != "ok" {
+		t.Errorf("Unexpected subscription health: %+v", health)
+	}
+}
+
+// TestStreamValidation tests rejected stream requests
+func TestStreamValidation(t *testing.T) {
+	s := NewServer(&fakePLC{})
+	defer s.Close()
+	for _, path := range []string{"/api/stream", "/api/stream?tag=Level", "/api/stream?tag=Level:QUUX"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// tagSearchParams are the query parameters that make GET /api/tags a search
+var tagSearchParams = []string{"pattern", "regexp", "scope", "type", "system", "limit"}
+
+// SearchTags returns the tags of the served tag database that match pattern
+// and pass filter (see ethernetip.EipClient.SearchTags). Structure types are
+// named from their templates when the PLC can read them (see
+// ethernetip.TemplateSource).
+func (s *Server) SearchTags(pattern string, filter ethernetip.TagFilter) ([]ethernetip.TagInfo, error) {
+	db := s.tags.Load()
+	if db == nil {
+		return nil, ethernetip.NewEipError(ethernetip.ErrInvalidOperation, "no tag database; run POST /api/discover first")
+	}
+	templates, _ := s.plc.(ethernetip.TemplateSource)
+	return db.Search(pattern, filter, templates)
+}
+
+// tagSearch reads the search in the query of GET /api/tags, reporting false
+// when there is none
+func tagSearch(r *http.Request) (string, ethernetip.TagFilter, bool, error) {
+	query := r.URL.Query()
+	search := false
+	for _, param := range tagSearchParams {
+		if query.Has(param) {
+			search = true
+		}
+	}
+	if !search {
+		return "", ethernetip.TagFilter{}, false, nil
+	}
+	filter := ethernetip.TagFilter{Scope: query.Get("scope"), Type: query.Get("type")}
+	for param, flag := range map[string]*bool{"regexp": &filter.Regexp, "system": &filter.System} {
+		if v := query.Get(param); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return "", filter, true, fmt.Errorf("%s must be true or false", param)
+			}
+			*flag = b
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return "", filter, true, fmt.Errorf("limit must be a non-negative integer")
+		}
+		filter.Limit = n
+	}
+	return query.Get("pattern"), filter, true, nil
+}
@@ -0,0 +1,56 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// TestTagSearchEndpoint tests filtering GET /api/tags with query parameters
+func TestTagSearchEndpoint(t *testing.T) {
+	s := NewServer(&fakePLC{})
+	defer s.Close()
+	s.tags.Store(ethernetip.NewTagDatabase([]ethernetip.TagInfo{
+		{Name: "Motor1", SymbolType: ethernetip.CIPTypeReal},
+		{Name: "Level", SymbolType: ethernetip.CIPTypeDint},
+		{Name: "Program:Line1.Motor2", SymbolType: ethernetip.CIPTypeReal, Program: "Line1"},
+	}))
+
+	search := func(query string) (int, []string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tags"+query, nil))
+		var db ethernetip.TagDatabase
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&db); err != nil {
+				t.Fatalf("%s: %v", query, err)
+			}
+		}
+		var names []string
+		for _, tag := range db.Tags {
+			names = append(names, tag.Name)
+		}
+		return rec.Code, names
+	}
+
+	if _, names := search(""); len(names) != 3 {
+		t.Errorf("Expected the whole database without a search, got %v", names)
+	}
+	if _, names := search("?pattern=motor*&scope=controller"); len(names) != 1 || names[0] != "Motor1" {
+		t.Errorf("Expected Motor1, got %v", names)
+	}
+	if _, names := search("?type=REAL&limit=1"); len(names) != 1 || names[0] != "Motor1" {
+		t.Errorf("Expected one REAL tag, got %v", names)
+	}
+	if code, names := search("?pattern=Nothing*"); code != http.StatusOK || names != nil {
+		t.Errorf("Expected an empty result, got %d %v", code, names)
+	}
+	for _, query := range []string{"?pattern=Motor[&regexp=true", "?limit=-1", "?system=maybe"} {
+		if code, _ := search(query); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, code)
+		}
+	}
+}
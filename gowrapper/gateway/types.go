@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"fmt"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/tagpath"
+)
+
+// typedPLC is implemented by clients that keep their own default type map,
+// such as *ethernetip.EipClient
+type typedPLC interface {
+	TagTypes() *ethernetip.TagTypes
+}
+
+// TagTypes returns the type map used when a request omits the data type. It
+// is the client's own map when the PLC has one; completed discoveries add to it.
+func (s *Server) TagTypes() *ethernetip.TagTypes {
+	return s.types
+}
+
+// resolveType returns the data type named by typeName, or the type recorded
+// for tagName when typeName is empty. Malformed tag names are rejected here,
+// before they reach the client.
+func (s *Server) resolveType(tagName, typeName string) (ethernetip.PlcDataType, error) {
+	if _, err := tagpath.Parse(tagName); err != nil {
+		return 0, err
+	}
+	if typeName != "" {
+		return ethernetip.ParsePlcDataType(typeName)
+	}
+	if dataType, ok := s.types.Lookup(tagName); ok {
+		return dataType, nil
+	}
+	return 0, fmt.Errorf("type is required for tag '%s': it is not in the tag type map", tagName)
+}
+
+// wireValue returns the value to encode for a tag of dataType. REAL values
+// are held as float64 but have single precision, so they are encoded as
+// float32, which prints the shortest decimal that reads back as the same
+// REAL ("0.1" rather than "0.10000000149011612"). Integers keep their Go
+// types and are encoded exactly.
+func wireValue(dataType ethernetip.PlcDataType, v interface{}) interface{} {
+	if dataType != ethernetip.Real {
+		return v
+	}
+	switch value := v.(type) {
+	case float64:
+		return float32(value)
+	case []float64:
+		single := make([]float32, len(value))
+		for i, f := range value {
+			single[i] = float32(f)
+		}
+		return single
+	case []interface{}:
+		elements := make([]interface{}, len(value))
+		for i, element := range value {
+			elements[i] = wireValue(dataType, element)
+		}
+		return elements
+	}
+	return v
+}
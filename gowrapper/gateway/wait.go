@@ -0,0 +1,117 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// Timeout limits for GET /api/tag/wait
+const (
+	defaultWaitTimeout = 30 * time.Second
+	maxWaitTimeout     = 5 * time.Minute
+)
+
+// WaitResult is the response of GET /api/tag/wait
+type WaitResult struct {
+	TagValue
+	// Changed is false when the request timed out without a change
+	Changed bool               `json:"changed"`
+	Quality ethernetip.Quality `json:"quality"`
+}
+
+// handleWaitTag handles GET /api/tag/wait?name=X&type=DINT&timeout=30s. It
+// returns as soon as the tag's value differs from the baseline, or with the
+// current value and changed=false once the timeout expires. The baseline is
+// the optional last parameter (the value the client already has, as JSON) or
+// else the value at the time of the request. Waits share the polls of
+// GET /api/stream.
+func (s *Server) handleWaitTag(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	tagName := query.Get("name")
+	if tagName == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	dataType, err := s.resolveType(tagName, query.Get("type"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	timeout := defaultWaitTimeout
+	if v := query.Get("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > maxWaitTimeout {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("timeout must be a positive duration of at most %v", maxWaitTimeout))
+			return
+		}
+		timeout = d
+	}
+	var baseline []byte
+	hasBaseline := query.Has("last")
+	if hasBaseline {
+		var last interface{}
+		decoder := json.NewDecoder(strings.NewReader(query.Get("last")))
+		decoder.UseNumber()
+		if err := decoder.Decode(&last); err != nil {
+			writeError(w, http.StatusBadRequest, "last must be a JSON value")
+			return
+		}
+		// Normalize the baseline to how samples of the tag's type are encoded
+		if value, err := ethernetip.NewPlcValue(dataType, last); err == nil {
+			last = wireValue(dataType, value.Value)
+		}
+		baseline, _ = json.Marshal(last)
+	}
+
+	consumer, err := s.hub.Subscribe(ethernetip.ConsumerOptions{
+		Tags: []ethernetip.GroupMember{{TagName: tagName, DataType: dataType}},
+		// Only samples carrying a value can count as a change
+		Filter: func(sample ethernetip.TagSample) bool { return sample.Quality != ethernetip.QualityUncertain },
+	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	defer consumer.Close()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	result := WaitResult{TagValue: TagValue{Tag: tagName, Type: dataType.String()}}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.ctx.Done():
+			writeError(w, http.StatusServiceUnavailable, "server is shutting down")
+			return
+		case <-timer.C:
+			s.writeResponse(w, r, http.StatusOK, result)
+			return
+		case sample, ok := <-consumer.C:
+			if !ok {
+				writeError(w, http.StatusServiceUnavailable, "subscription closed")
+				return
+			}
+			// Compare the JSON encodings so the comparison matches what the client sees
+			value := wireValue(sample.Type, sample.Value)
+			encoded, _ := json.Marshal(value)
+			result.Value = value
+			result.Quality = sample.Quality
+			result.Timestamp = sample.Timestamp
+			if !hasBaseline {
+				baseline, hasBaseline = encoded, true
+				continue
+			}
+			if string(encoded) != string(baseline) {
+				result.Changed = true
+				s.writeResponse(w, r, http.StatusOK, result)
+				return
+			}
+		}
+	}
+}
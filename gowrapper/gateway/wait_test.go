@@ -0,0 +1,76 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// waitRequest issues GET /api/tag/wait and decodes the result
+func waitRequest(t *testing.T, s *Server, query string) (int, WaitResult) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tag/wait?"+query, nil))
+	var result WaitResult
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+			t.Fatalf("Failed to decode result: %v", err)
+		}
+	}
+	return rec.Code, result
+}
+
+// TestWaitTagChange tests that a wait returns when the value changes
+func TestWaitTagChange(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"Count": int32(1)}}
+	s := NewServer(plc)
+	defer s.Close()
+
+	go func() {
+		time.Sleep(700 * time.Millisecond)
+		plc.set("Count", int32(2))
+	}()
+	start := time.Now()
+	code, result := waitRequest(t, s, "name=Count&type=DINT&timeout=10s")
+	if code != http.StatusOK || !result.Changed || result.Value != float64(2) {
+		t.Errorf("Expected change to 2, got %d %+v", code, result)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Wait took %v", elapsed)
+	}
+}
+
+// TestWaitTagBaseline tests that a stale client baseline returns immediately
+func TestWaitTagBaseline(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"Count": int32(5)}}
+	s := NewServer(plc)
+	defer s.Close()
+
+	code, result := waitRequest(t, s, "name=Count&type=DINT&timeout=10s&last=4")
+	if code != http.StatusOK || !result.Changed || result.Value != float64(5) {
+		t.Errorf("Expected immediate change, got %d %+v", code, result)
+	}
+
+	code, result = waitRequest(t, s, "name=Count&type=DINT&timeout=800ms&last=5")
+	if code != http.StatusOK || result.Changed || result.Value != float64(5) {
+		t.Errorf("Expected timeout with current value, got %d %+v", code, result)
+	}
+}
+
+// TestWaitTagValidation tests rejected wait requests
+func TestWaitTagValidation(t *testing.T) {
+	s := NewServer(&fakePLC{})
+	defer s.Close()
+	for _, query := range []string{
+		"type=DINT",
+		"name=A&type=nope",
+		"name=A&type=DINT&timeout=1h",
+		"name=A&type=DINT&last=%7Bbad",
+	} {
+		if code, _ := waitRequest(t, s, query); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, code)
+		}
+	}
+}
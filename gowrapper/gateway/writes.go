@@ -0,0 +1,251 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// writeStreamQueue is how many accepted writes a stream holds before it stops
+// reading further commands
+const writeStreamQueue = 64
+
+// AckStatus is the stage a streamed write has reached
+type AckStatus string
+
+// Acknowledgement stages. Every write is acknowledged as accepted, then either
+// executed or failed; writes with Verify set are additionally verified or
+// failed after the read-back.
+const (
+	AckAccepted AckStatus = "accepted"
+	AckExecuted AckStatus = "executed"
+	AckVerified AckStatus = "verified"
+	AckFailed   AckStatus = "failed"
+)
+
+// WriteCommand is one write of a write stream
+type WriteCommand struct {
+	// ID is chosen by the sender and echoed in every acknowledgement
+	ID  string `json:"id"`
+	Tag string `json:"tag"`
+	// Type may be omitted for tags in the server's TagTypes
+	Type  string      `json:"type,omitempty"`
+	Value interface{} `json:"value"`
+	// Verify reads the tag back after the write and compares the value
+	Verify bool `json:"verify,omitempty"`
+
+	// invalid is set for commands that could not be decoded
+	invalid error
+}
+
+// WriteAck acknowledges a stage of a WriteCommand. Timestamp is taken from the
+// server's clock (see Server.Clock).
+type WriteAck struct {
+	ID        string    `json:"id"`
+	Tag       string    `json:"tag"`
+	Status    AckStatus `json:"status"`
+	Reason    string    `json:"reason,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// StreamWrites executes the writes received on commands in order, sending
+// acknowledgements on acks, until commands is closed or ctx is done. It closes
+// acks when it returns. Transports other than the built-in HTTP endpoint (for
+// example a gRPC bidirectional stream) can bridge their messages to it.
+//
+// Writes are issued with the caller identity of ctx (see
+// ethernetip.WithCallerIdentity); a command's ID is used as the correlation
+// ID if ctx has none. Cancelling ctx stops the stream, but a write already
+// sent to the PLC is not aborted.
+func (s *Server) StreamWrites(ctx context.Context, commands <-chan WriteCommand, acks chan<- WriteAck) {
+	defer close(acks)
+	clock := s.Clock()
+	send := func(cmd WriteCommand, status AckStatus, reason string) bool {
+		ack := WriteAck{ID: cmd.ID, Tag: cmd.Tag, Status: status, Reason: reason, Timestamp: clock.Now()}
+		select {
+		case acks <- ack:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	// Commands are acknowledged as accepted on arrival and executed by a
+	// separate goroutine, so a slow write does not delay the next accept
+	type accepted struct {
+		cmd   WriteCommand
+		value *ethernetip.PlcValue
+	}
+	queue := make(chan accepted, writeStreamQueue)
+	done := make(chan struct{})
+	caller, _ := ethernetip.CallerIdentityFromContext(ctx)
+	writeCtx := context.WithoutCancel(ctx)
+	go func() {
+		defer close(done)
+		for item := range queue {
+			id := caller
+			if id.CorrelationID == "" {
+				id.CorrelationID = item.cmd.ID
+			}
+			ctx := ethernetip.WithCallerIdentity(writeCtx, id)
+			if err := s.plc.WriteValueContext(ctx, item.cmd.Tag, item.value); err != nil {
+				if !send(item.cmd, AckFailed, err.Error()) {
+					return
+				}
+				continue
+			}
+			if !send(item.cmd, AckExecuted, "") {
+				return
+			}
+			if !item.cmd.Verify {
+				continue
+			}
+			status, reason := s.verifyWrite(item.cmd.Tag, item.value)
+			if !send(item.cmd, status, reason) {
+				return
+			}
+		}
+	}()
+	defer func() {
+		close(queue)
+		<-done
+	}()
+
+	for {
+		var cmd WriteCommand
+		var ok bool
+		select {
+		case cmd, ok = <-commands:
+			if !ok {
+				return
+			}
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		}
+
+		value, err := s.writeValue(cmd)
+		if err != nil {
+			if !send(cmd, AckFailed, err.Error()) {
+				return
+			}
+			continue
+		}
+		if !send(cmd, AckAccepted, "") {
+			return
+		}
+		select {
+		case queue <- accepted{cmd: cmd, value: value}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// writeValue validates a command and converts its value for the PLC
+func (s *Server) writeValue(cmd WriteCommand) (*ethernetip.PlcValue, error) {
+	if cmd.invalid != nil {
+		return nil, cmd.invalid
+	}
+	if cmd.Tag == "" {
+		return nil, fmt.Errorf("tag is required")
+	}
+	dataType, err := s.resolveType(cmd.Tag, cmd.Type)
+	if err != nil {
+		return nil, err
+	}
+	return ethernetip.NewPlcValue(dataType, cmd.Value)
+}
+
+// verifyWrite reads tagName back and compares it with the written value, as
+// ethernetip.WriteValueVerified does
+func (s *Server) verifyWrite(tagName string, written *ethernetip.PlcValue) (AckStatus, string) {
+	read, err := s.plc.ReadValue(tagName, written.Type)
+	if err != nil {
+		return AckFailed, "verification read failed: " + err.Error()
+	}
+	if read == nil || !ethernetip.ValuesMatch(written.Type, written.Value, read.Value, 0) {
+		var got interface{}
+		if read != nil {
+			got = read.Value
+		}
+		return AckFailed, fmt.Sprintf("verification failed: wrote %v, read back %v", written.Value, got)
+	}
+	return AckVerified, ""
+}
+
+// handleWriteStream handles POST /api/writes. The request body is a stream of
+// newline-delimited JSON WriteCommands and the response a stream of
+// newline-delimited WriteAcks, sent as each stage completes, so a client can
+// keep one connection open and tell exactly which commands took effect.
+// Writes run in the order received.
+func (s *Server) handleWriteStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+	// Acknowledgements are written while the request body is still being read
+	http.NewResponseController(w).EnableFullDuplex()
+
+	ctx, cancel := context.WithCancel(s.withIdentity(r.Context(), r))
+	defer cancel()
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	commands := make(chan WriteCommand)
+	acks := make(chan WriteAck)
+	go s.StreamWrites(ctx, commands, acks)
+
+	// Malformed lines are acknowledged as failed without an ID, in order
+	// with the other acknowledgements
+	go func() {
+		defer close(commands)
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+			// Numbers are kept as json.Number so LINT values are not
+			// rounded to float64
+			var cmd WriteCommand
+			decoder := json.NewDecoder(bytes.NewReader(line))
+			decoder.UseNumber()
+			if err := decoder.Decode(&cmd); err != nil {
+				cmd = WriteCommand{invalid: fmt.Errorf("invalid command: %v", err)}
+			}
+			select {
+			case commands <- cmd:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	encoder := json.NewEncoder(w)
+	for ack := range acks {
+		if err := encoder.Encode(ack); err != nil {
+			cancel()
+			continue
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,260 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// collectAcks runs commands through StreamWrites and returns all acknowledgements
+func collectAcks(s *Server, commands ...WriteCommand) []WriteAck {
+	in := make(chan WriteCommand, len(commands))
+	for _, cmd := range commands {
+		in <- cmd
+	}
+	close(in)
+	out := make(chan WriteAck)
+	go s.StreamWrites(context.Background(), in, out)
+	var acks []WriteAck
+	for ack := range out {
+		acks = append(acks, ack)
+	}
+	return acks
+}
+
+// TestStreamWritesAcknowledgesStages tests the acknowledgement sequence of
+// successful, verified, rejected and failed writes
+func TestStreamWritesAcknowledgesStages(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{}, readOnly: map[string]bool{"Locked": true}}
+	s := NewServer(plc)
+	defer s.Close()
+
+	acks := collectAcks(s,
+		WriteCommand{ID: "1", Tag: "Speed", Type: "DINT", Value: float64(42)},
+		WriteCommand{ID: "2", Tag: "Level", Type: "REAL", Value: 12.1, Verify: true},
+		WriteCommand{ID: "3", Tag: "Speed", Type: "DINT", Value: 1.5},
+		WriteCommand{ID: "4", Tag: "Locked", Type: "BOOL", Value: true},
+	)
+
+	var got []string
+	for _, ack := range acks {
+		got = append(got, ack.ID+":"+string(ack.Status))
+	}
+	// Rejected commands are failed before the earlier accepted ones finish
+	// executing, so compare the sequence of each command separately
+	want := map[string][]AckStatus{
+		"1": {AckAccepted, AckExecuted},
+		"2": {AckAccepted, AckExecuted, AckVerified},
+		"3": {AckFailed},
+		"4": {AckAccepted, AckFailed},
+	}
+	for id, stages := range want {
+		var seen []AckStatus
+		for _, ack := range acks {
+			if ack.ID == id {
+				seen = append(seen, ack.Status)
+			}
+		}
+		if len(seen) != len(stages) {
+			t.Fatalf("Expected %v for command %s, got sequence %v", stages, id, got)
+		}
+		for i := range stages {
+			if seen[i] != stages[i] {
+				t.Errorf("Expected %v for command %s, got sequence %v", stages, id, got)
+			}
+		}
+	}
+	for _, ack := range acks {
+		if ack.Status == AckFailed && ack.Reason == "" {
+			t.Errorf("Expected a reason for failed command %s", ack.ID)
+		}
+	}
+	if plc.values["Speed"] != int32(42) {
+		t.Errorf("Expected Speed to be written as int32(42), got %#v", plc.values["Speed"])
+	}
+}
+
+// TestVerifyWrite tests write verification comparisons
+func TestVerifyWrite(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"Level": float64(float32(12.1)), "Count": int32(5)}}
+	s := NewServer(plc)
+	defer s.Close()
+
+	if status, reason := s.verifyWrite("Level", &ethernetip.PlcValue{Type: ethernetip.Real, Value: 12.1}); status != AckVerified {
+		t.Errorf("Expected REAL values to compare at single precision, got %s: %s", status, reason)
+	}
+	if status, _ := s.verifyWrite("Level", &ethernetip.PlcValue{Type: ethernetip.Real, Value: 12.2}); status != AckFailed {
+		t.Error("Expected different REAL values to differ")
+	}
+	if status, reason := s.verifyWrite("Count", &ethernetip.PlcValue{Type: ethernetip.Dint, Value: int32(5)}); status != AckVerified {
+		t.Errorf("Expected equal DINT values to match, got %s: %s", status, reason)
+	}
+}
+
+// TestWriteStreamEndpoint tests POST /api/writes with acknowledgements read
+// while commands are still being sent
+func TestWriteStreamEndpoint(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{}}
+	s := NewServer(plc)
+	defer s.Close()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	body, commands := io.Pipe()
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/writes", body)
+	respc := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Error(err)
+			close(respc)
+			return
+		}
+		respc <- resp
+	}()
+
+	encoder := json.NewEncoder(commands)
+	encoder.Encode(WriteCommand{ID: "a", Tag: "Mode", Type: "INT", Value: float64(3)})
+
+	var resp *http.Response
+	select {
+	case resp = <-respc:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the response")
+	}
+	if resp == nil {
+		t.FailNow()
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Expected application/x-ndjson, got %s", ct)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	next := func() WriteAck {
+		t.Helper()
+		if !scanner.Scan() {
+			t.Fatalf("Stream ended early: %v", scanner.Err())
+		}
+		var ack WriteAck
+		if err := json.Unmarshal(scanner.Bytes(), &ack); err != nil {
+			t.Fatal(err)
+		}
+		return ack
+	}
+
+	if ack := next(); ack.ID != "a" || ack.Status != AckAccepted {
+		t.Errorf("Expected a accepted, got %+v", ack)
+	}
+	if ack := next(); ack.ID != "a" || ack.Status != AckExecuted {
+		t.Errorf("Expected a executed, got %+v", ack)
+	}
+
+	commands.Write([]byte("not json\n"))
+	if ack := next(); ack.Status != AckFailed || ack.Reason == "" {
+		t.Errorf("Expected a failed acknowledgement for a malformed line, got %+v", ack)
+	}
+	commands.Close()
+	if scanner.Scan() {
+		t.Errorf("Expected the stream to end, got %s", scanner.Text())
+	}
+}
+
+// TestVerifyWriteTimes tests verification of DT values written in a local zone
+func TestVerifyWriteTimes(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{"Started": time.Date(2024, 5, 1, 7, 0, 0, 1000, time.UTC)}}
+	s := NewServer(plc)
+	defer s.Close()
+
+	zone := time.FixedZone("CET", 3600)
+	written := &ethernetip.PlcValue{Type: ethernetip.Dt, Value: time.Date(2024, 5, 1, 8, 0, 0, 1500, zone)}
+	if status, reason := s.verifyWrite("Started", written); status != AckVerified {
+		t.Errorf("Expected the same instant at microsecond precision to match, got %s: %s", status, reason)
+	}
+	written.Value = time.Date(2024, 5, 1, 8, 0, 1, 0, zone)
+	if status, _ := s.verifyWrite("Started", written); status != AckFailed {
+		t.Error("Expected different instants to differ")
+	}
+}
+
+// TestWriteStreamExactIntegers tests that LINT values beyond 2^53 are written exactly
+func TestWriteStreamExactIntegers(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{}}
+	s := NewServer(plc)
+	defer s.Close()
+
+	body := `{"id":"a","tag":"Total","type":"LINT","value":9007199254740993}` + "\n" +
+		`{"id":"b","tag":"Max","type":"ULINT","value":18446744073709551615}` + "\n"
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/writes", strings.NewReader(body)))
+
+	if got := plc.values["Total"]; got != int64(9007199254740993) {
+		t.Errorf("Expected int64(9007199254740993), got %#v (%s)", got, rec.Body)
+	}
+	if got := plc.values["Max"]; got != uint64(18446744073709551615) {
+		t.Errorf("Expected the largest ULINT, got %#v (%s)", got, rec.Body)
+	}
+}
+
+// TestWriteStreamCallerIdentity tests that streamed writes carry the caller
+// identity of the request
+func TestWriteStreamCallerIdentity(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{}}
+	s := NewServer(plc)
+	defer s.Close()
+
+	body := `{"id":"a","tag":"Speed","type":"DINT","value":1}` + "\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/writes", strings.NewReader(body))
+	req.SetBasicAuth("operator", "secret")
+	s.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/writes", strings.NewReader(body))
+	req.Header.Set("X-Request-ID", "req-7")
+	s.SetIdentityFunc(func(r *http.Request) ethernetip.CallerIdentity {
+		return ethernetip.CallerIdentity{User: "session-user", CorrelationID: r.Header.Get("X-Request-ID")}
+	})
+	s.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := []ethernetip.CallerIdentity{
+		{User: "operator", CorrelationID: "a"},
+		{User: "session-user", CorrelationID: "req-7"},
+	}
+	plc.mu.Lock()
+	defer plc.mu.Unlock()
+	if len(plc.callers) != len(want) {
+		t.Fatalf("Expected identities %v, got %v", want, plc.callers)
+	}
+	for i := range want {
+		if plc.callers[i] != want[i] {
+			t.Errorf("Expected identity %v, got %v", want[i], plc.callers[i])
+		}
+	}
+}
+
+// TestWriteAckTimestamps tests that acknowledgements are stamped with the
+// server's clock
+func TestWriteAckTimestamps(t *testing.T) {
+	plc := &fakePLC{values: map[string]interface{}{}}
+	s := NewServer(plc)
+	defer s.Close()
+	start := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	s.poller.SetClock(ethernetip.NewFakeClock(start))
+
+	acks := collectAcks(s, WriteCommand{ID: "1", Tag: "Speed", Type: "DINT", Value: float64(42)})
+	if len(acks) != 2 {
+		t.Fatalf("Expected 2 acknowledgements, got %+v", acks)
+	}
+	for _, ack := range acks {
+		if !ack.Timestamp.Equal(start) {
+			t.Errorf("Expected timestamp %v, got %v", start, ack.Timestamp)
+		}
+	}
+}
@@ -5,3 +5,7 @@ go 1.23.0
 toolchain go1.24.3
 
 replace github.com/sergiogallegos/rust-ethernet-ip => ../
+
+require go.etcd.io/bbolt v1.4.3
+
+require golang.org/x/sys v0.29.0 // indirect
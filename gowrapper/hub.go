@@ -0,0 +1,259 @@
+package ethernetip
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultHubBuffer is the channel capacity of a hub consumer when none is given
+const DefaultHubBuffer = 64
+
+// EchoWindow is how long Consumer.SuppressEcho waits for the echo of a write
+const EchoWindow = 5 * time.Second
+
+// ConsumerOptions selects what a hub consumer receives
+type ConsumerOptions struct {
+	// Tags are the tags the consumer watches. The hub polls each distinct tag
+	// once, however many consumers watch it.
+	Tags []GroupMember
+	// Filter, if set, drops samples for which it returns false
+	Filter func(sample TagSample) bool
+	// Buffer is the channel capacity; defaults to DefaultHubBuffer
+	Buffer int
+}
+
+// Consumer receives the samples of the tags it watches on C. A consumer that
+// falls behind loses samples rather than slowing down the others; Dropped
+// reports how many.
+type Consumer struct {
+	C <-chan TagSample
+
+	hub     *Hub
+	id      int
+	ch      chan TagSample
+	tags    map[GroupMember]bool
+	filter  func(sample TagSample) bool
+	echoes  map[GroupMember]hubEcho // Guarded by the hub lock
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// hubEcho is a written value whose change notification a consumer skips
+type hubEcho struct {
+	value   interface{}
+	expires time.Time
+}
+
+// Dropped returns the number of samples discarded because C was full
+func (c *Consumer) Dropped() uint64 {
+	return c.dropped.Load()
+}
+
+// SuppressEcho tells the hub that the consumer's owner just wrote value to a
+// watched tag, so the next sample of the tag is not delivered to this
+// consumer if it carries that value: a writer that has its confirmation does
+// not also get its own change back as an update. Other consumers still
+// receive it. A sample with another value, such as a setpoint the program
+// clamped, is delivered as usual, and the suppression lapses after
+// EchoWindow. It reports whether the consumer watches the tag.
+func (c *Consumer) SuppressEcho(tagName string, dataType PlcDataType, value interface{}) bool {
+	member := GroupMember{TagName: c.hub.poller.TagKey(tagName), DataType: dataType}
+	expires := c.hub.poller.Clock().Now().Add(EchoWindow)
+
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	if !c.tags[member] {
+		return false
+	}
+	if c.echoes == nil {
+		c.echoes = make(map[GroupMember]hubEcho)
+	}
+	c.echoes[member] = hubEcho{value: value, expires: expires}
+	return true
+}
+
+// Close stops delivery and closes C. Tags no longer watched by any consumer
+// stop being polled.
+func (c *Consumer) Close() {
+	c.once.Do(func() { c.hub.remove(c) })
+}
+
+// hubTag is a tag polled on behalf of one or more consumers
+type hubTag struct {
+	watchers    int
+	unsubscribe func()
+	last        TagSample
+	hasLast     bool
+}
+
+// Hub owns tag subscriptions and fans their samples out to many consumers
+// (WebSocket connections, SSE streams, internal channels), so any number of
+// consumers watching a tag cost a single PLC poll.
+type Hub struct {
+	poller   *Poller
+	interval time.Duration
+
+	mu        sync.Mutex
+	tags      map[GroupMember]*hubTag
+	consumers map[int]*Consumer
+	nextID    int
+	closed    bool
+}
+
+// NewHub creates a hub that polls tags through poller every interval
+func NewHub(poller *Poller, interval time.Duration) *Hub {
+	return &Hub{
+		poller:    poller,
+		interval:  interval,
+		tags:      make(map[GroupMember]*hubTag),
+		consumers: make(map[int]*Consumer),
+	}
+}
+
+// NewHub creates a hub on top of the client's poller
+func (c *EipClient) NewHub(interval time.Duration) *Hub {
+	return NewHub(c.poller, interval)
+}
+
+// Subscribe adds a consumer. The latest known sample of each watched tag, if
+// any, is delivered immediately so late joiners start with current state.
+func (h *Hub) Subscribe(opts ConsumerOptions) (*Consumer, error) {
+	if len(opts.Tags) == 0 {
+		return nil, NewEipError(ErrInvalidTagSubscription, "consumer must watch at least one tag")
+	}
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = DefaultHubBuffer
+	}
+	ch := make(chan TagSample, buffer)
+	consumer := &Consumer{
+		C:      ch,
+		hub:    h,
+		ch:     ch,
+		tags:   make(map[GroupMember]bool, len(opts.Tags)),
+		filter: opts.Filter,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, NewEipError(ErrInvalidOperation, "hub is closed")
+	}
+	h.nextID++
+	consumer.id = h.nextID
+	h.consumers[consumer.id] = consumer
+
+	for _, watched := range opts.Tags {
+		// Tags are keyed by the poller's name key so differently spelled names
+		// of one tag share an entry
+		member := GroupMember{TagName: h.poller.TagKey(watched.TagName), DataType: watched.DataType}
+		if consumer.tags[member] {
+			continue
+		}
+		consumer.tags[member] = true
+		tag, ok := h.tags[member]
+		if !ok {
+			tag = &hubTag{}
+			h.tags[member] = tag
+			tag.unsubscribe = h.poller.SubscribeSamples(watched.TagName, h.interval, watched.DataType, func(sample TagSample) {
+				h.publish(member, sample)
+			})
+		}
+		tag.watchers++
+		if tag.hasLast {
+			consumer.deliver(member, tag.last)
+		}
+	}
+	return consumer, nil
+}
+
+// publish records a sample and delivers it to the consumers watching the tag
+func (h *Hub) publish(member GroupMember, sample TagSample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	tag, ok := h.tags[member]
+	if !ok {
+		return
+	}
+	tag.last = sample
+	tag.hasLast = true
+	for _, consumer := range h.consumers {
+		if consumer.tags[member] {
+			consumer.deliver(member, sample)
+		}
+	}
+}
+
+// deliver sends a sample of member without blocking, unless it is the echo
+// of the consumer's own write. Must be called with the hub lock held.
+func (c *Consumer) deliver(member GroupMember, sample TagSample) {
+	if echo, ok := c.echoes[member]; ok {
+		delete(c.echoes, member)
+		if sample.Quality == QualityGood && c.hub.poller.Clock().Now().Before(echo.expires) &&
+			ValuesMatch(member.DataType, echo.value, sample.Value, 0) {
+			return
+		}
+	}
+	if c.filter != nil && !c.filter(sample) {
+		return
+	}
+	select {
+	case c.ch <- sample:
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// remove detaches a consumer and releases the tags only it watched
+func (h *Hub) remove(c *Consumer) {
+	h.mu.Lock()
+	var unsubscribes []func()
+	if _, ok := h.consumers[c.id]; ok {
+		delete(h.consumers, c.id)
+		for member := range c.tags {
+			tag := h.tags[member]
+			tag.watchers--
+			if tag.watchers == 0 {
+				delete(h.tags, member)
+				unsubscribes = append(unsubscribes, tag.unsubscribe)
+			}
+		}
+		close(c.ch)
+	}
+	h.mu.Unlock()
+
+	// The poller may be delivering to publish, which takes h.mu
+	for _, unsubscribe := range unsubscribes {
+		unsubscribe()
+	}
+}
+
+// ConsumerCount returns the number of active consumers
+func (h *Hub) ConsumerCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.consumers)
+}
+
+// TagCount returns the number of distinct tags being polled
+func (h *Hub) TagCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.tags)
+}
+
+// Close closes every consumer and stops polling their tags
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	consumers := make([]*Consumer, 0, len(h.consumers))
+	for _, consumer := range h.consumers {
+		consumers = append(consumers, consumer)
+	}
+	h.mu.Unlock()
+
+	for _, consumer := range consumers {
+		consumer.Close()
+	}
+}
@@ -0,0 +1,181 @@
+package ethernetip
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// nextSample waits for a sample on c
+func nextSample(t *testing.T, c *Consumer) TagSample {
+	t.Helper()
+	select {
+	case sample, ok := <-c.C:
+		if !ok {
+			t.Fatal("Consumer channel closed")
+		}
+		return sample
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for sample")
+		return TagSample{}
+	}
+}
+
+// TestHubFanOut tests that many consumers of one tag share a single poll
+func TestHubFanOut(t *testing.T) {
+	client := newFakeClient()
+	client.set("Speed", float64(1))
+	poller := NewPoller(client)
+	defer poller.Close()
+	hub := NewHub(poller, 10*time.Millisecond)
+	defer hub.Close()
+
+	var consumers []*Consumer
+	for i := 0; i < 50; i++ {
+		c, err := hub.Subscribe(ConsumerOptions{Tags: []GroupMember{{TagName: "Speed", DataType: Real}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		consumers = append(consumers, c)
+	}
+	if hub.TagCount() != 1 || poller.SubscriptionCount() != 1 {
+		t.Errorf("Expected one poll subscription, got %d tags and %d subscriptions", hub.TagCount(), poller.SubscriptionCount())
+	}
+	for _, c := range consumers {
+		if sample := nextSample(t, c); sample.Value != float64(1) {
+			t.Errorf("Unexpected sample %+v", sample)
+		}
+	}
+
+	// A late joiner gets the current value straight away
+	late, err := hub.Subscribe(ConsumerOptions{Tags: []GroupMember{{TagName: "Speed", DataType: Real}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case sample := <-late.C:
+		if sample.Value != float64(1) {
+			t.Errorf("Unexpected initial sample %+v", sample)
+		}
+	default:
+		t.Error("Expected the last sample to be delivered on subscribe")
+	}
+
+	for _, c := range append(consumers, late) {
+		c.Close()
+	}
+	if hub.ConsumerCount() != 0 || hub.TagCount() != 0 || poller.SubscriptionCount() != 0 {
+		t.Errorf("Expected everything released, got %d consumers, %d tags, %d subscriptions",
+			hub.ConsumerCount(), hub.TagCount(), poller.SubscriptionCount())
+	}
+	if _, ok := <-late.C; ok {
+		t.Error("Expected closed channel")
+	}
+}
+
+// TestHubFilter tests per-consumer filters
+func TestHubFilter(t *testing.T) {
+	client := newFakeClient()
+	client.set("Level", int32(5))
+	poller := NewPoller(client)
+	defer poller.Close()
+	hub := NewHub(poller, 10*time.Millisecond)
+	defer hub.Close()
+
+	high, err := hub.Subscribe(ConsumerOptions{
+		Tags:   []GroupMember{{TagName: "Level", DataType: Dint}},
+		Filter: func(s TagSample) bool { return s.Value.(int32) > 10 },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := hub.Subscribe(ConsumerOptions{Tags: []GroupMember{{TagName: "Level", DataType: Dint}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nextSample(t, all)
+	client.set("Level", int32(20))
+	if sample := nextSample(t, high); sample.Value != int32(20) {
+		t.Errorf("Expected filtered consumer to see 20, got %v", sample.Value)
+	}
+}
+
+// TestHubSlowConsumer tests that a full consumer drops samples without blocking others
+func TestHubSlowConsumer(t *testing.T) {
+	client := newFakeClient()
+	poller := NewPoller(client)
+	defer poller.Close()
+	hub := NewHub(poller, 5*time.Millisecond)
+	defer hub.Close()
+
+	var n atomic.Int32
+	client.set("Count", int32(0))
+	slow, _ := hub.Subscribe(ConsumerOptions{Tags: []GroupMember{{TagName: "Count", DataType: Dint}}, Buffer: 1})
+	fast, _ := hub.Subscribe(ConsumerOptions{Tags: []GroupMember{{TagName: "Count", DataType: Dint}}})
+
+	for i := 0; i < 5; i++ {
+		client.set("Count", n.Add(1))
+		for {
+			if nextSample(t, fast).Value == n.Load() {
+				break
+			}
+		}
+	}
+	if slow.Dropped() == 0 {
+		t.Error("Expected the slow consumer to drop samples")
+	}
+}
+
+// TestHubSuppressEcho tests that a writer does not get its own change back
+func TestHubSuppressEcho(t *testing.T) {
+	client := newFakeClient()
+	client.set("Setpoint", int32(10))
+	poller := NewPoller(client)
+	defer poller.Close()
+	hub := NewHub(poller, 5*time.Millisecond)
+	defer hub.Close()
+
+	tags := []GroupMember{{TagName: "Setpoint", DataType: Dint}}
+	writer, _ := hub.Subscribe(ConsumerOptions{Tags: tags})
+	observer, _ := hub.Subscribe(ConsumerOptions{Tags: tags})
+	nextSample(t, writer)
+	nextSample(t, observer)
+
+	if writer.SuppressEcho("Other", Dint, int32(1)) {
+		t.Error("Expected SuppressEcho of an unwatched tag to report false")
+	}
+	if !writer.SuppressEcho("Setpoint", Dint, int32(50)) {
+		t.Fatal("Expected SuppressEcho to accept a watched tag")
+	}
+	client.set("Setpoint", int32(50))
+	if sample := nextSample(t, observer); sample.Value != int32(50) {
+		t.Errorf("Expected other consumers to see 50, got %v", sample.Value)
+	}
+	client.set("Setpoint", int32(60))
+	if sample := nextSample(t, observer); sample.Value != int32(60) {
+		t.Errorf("Expected other consumers to see 60, got %v", sample.Value)
+	}
+	if sample := nextSample(t, writer); sample.Value != int32(60) {
+		t.Errorf("Expected the writer's echo of 50 to be suppressed, got %v", sample.Value)
+	}
+
+	// A value other than the one written is delivered
+	writer.SuppressEcho("Setpoint", Dint, int32(100))
+	client.set("Setpoint", int32(90))
+	if sample := nextSample(t, writer); sample.Value != int32(90) {
+		t.Errorf("Expected the clamped value 90, got %v", sample.Value)
+	}
+}
+
+// TestHubSubscribeValidation tests rejected subscriptions
+func TestHubSubscribeValidation(t *testing.T) {
+	hub := NewHub(NewPoller(newFakeClient()), time.Second)
+	if _, err := hub.Subscribe(ConsumerOptions{}); err == nil {
+		t.Error("Expected error for consumer without tags")
+	}
+	hub.Close()
+	if _, err := hub.Subscribe(ConsumerOptions{Tags: []GroupMember{{TagName: "A", DataType: Dint}}}); err == nil {
+		t.Error("Expected error after Close")
+	}
+}
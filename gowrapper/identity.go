@@ -0,0 +1,58 @@
+package ethernetip
+
+import (
+	"context"
+)
+
+// CallerIdentity identifies who initiated an operation. It is attached to a
+// context with WithCallerIdentity and flows into interceptors and error
+// details, so every write issued through a multi-user gateway can be traced
+// back to the initiating user or request.
+type CallerIdentity struct {
+	User          string `json:"user,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// IsZero reports whether no identity information is set
+func (id CallerIdentity) IsZero() bool {
+	return id.User == "" && id.CorrelationID == ""
+}
+
+type callerIdentityKey struct{}
+
+// WithCallerIdentity returns a copy of ctx carrying the given caller identity
+func WithCallerIdentity(ctx context.Context, id CallerIdentity) context.Context {
+	return context.WithValue(ctx, callerIdentityKey{}, id)
+}
+
+// CallerIdentityFromContext returns the caller identity stored in ctx, if any
+func CallerIdentityFromContext(ctx context.Context) (CallerIdentity, bool) {
+	if ctx == nil {
+		return CallerIdentity{}, false
+	}
+	id, ok := ctx.Value(callerIdentityKey{}).(CallerIdentity)
+	return id, ok
+}
+
+// annotateError adds the caller identity from ctx to the details of an EipError.
+// Other errors are returned unchanged.
+func annotateError(ctx context.Context, err error) error {
+	eipErr, ok := err.(*EipError)
+	if !ok {
+		return err
+	}
+	id, ok := CallerIdentityFromContext(ctx)
+	if !ok || id.IsZero() {
+		return err
+	}
+	if eipErr.Details == nil {
+		eipErr.Details = make(map[string]interface{})
+	}
+	if id.User != "" {
+		eipErr.Details["caller"] = id.User
+	}
+	if id.CorrelationID != "" {
+		eipErr.Details["correlation_id"] = id.CorrelationID
+	}
+	return eipErr
+}
@@ -0,0 +1,73 @@
+package ethernetip
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestCallerIdentityContext tests storing and retrieving caller identity
+func TestCallerIdentityContext(t *testing.T) {
+	if _, ok := CallerIdentityFromContext(context.Background()); ok {
+		t.Error("Expected no identity in background context")
+	}
+
+	ctx := WithCallerIdentity(context.Background(), CallerIdentity{User: "alice", CorrelationID: "req-42"})
+	id, ok := CallerIdentityFromContext(ctx)
+	if !ok {
+		t.Fatal("Expected identity in context")
+	}
+	if id.User != "alice" || id.CorrelationID != "req-42" {
+		t.Errorf("Unexpected identity: %+v", id)
+	}
+}
+
+// TestAnnotateError tests that caller identity is added to error details
+func TestAnnotateError(t *testing.T) {
+	ctx := WithCallerIdentity(context.Background(), CallerIdentity{User: "alice", CorrelationID: "req-42"})
+
+	err := annotateError(ctx, NewEipError(ErrTagNotFound, "missing"))
+	eipErr := err.(*EipError)
+	if eipErr.Details["caller"] != "alice" || eipErr.Details["correlation_id"] != "req-42" {
+		t.Errorf("Expected caller details, got %v", eipErr.Details)
+	}
+
+	plain := errors.New("plain")
+	if annotateError(ctx, plain) != plain {
+		t.Error("Expected non-EipError to be returned unchanged")
+	}
+}
+
+// TestInterceptorChain tests interceptor ordering and identity propagation
+func TestInterceptorChain(t *testing.T) {
+	client := &EipClient{}
+
+	var order []string
+	client.AddInterceptor(func(ctx context.Context, op *Operation, next func(context.Context, *Operation) error) error {
+		order = append(order, "first:"+op.Caller.User)
+		return next(ctx, op)
+	})
+	client.AddInterceptor(func(ctx context.Context, op *Operation, next func(context.Context, *Operation) error) error {
+		order = append(order, "second:"+string(op.Kind))
+		// Short-circuit so no native call is made
+		return NewEipError(ErrInvalidTagAccess, "denied")
+	})
+
+	ctx := WithCallerIdentity(context.Background(), CallerIdentity{User: "bob"})
+	err := client.WriteValueContext(ctx, "Setpoint", &PlcValue{Type: Dint, Value: int32(5)})
+	if err == nil {
+		t.Fatal("Expected error from short-circuiting interceptor")
+	}
+	if len(order) != 2 || order[0] != "first:bob" || order[1] != "second:write" {
+		t.Errorf("Unexpected interceptor order: %v", order)
+	}
+	if err.(*EipError).Details["caller"] != "bob" {
+		t.Errorf("Expected caller in error details, got %v", err.(*EipError).Details)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := client.ReadValueContext(cancelled, "Setpoint", Dint); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
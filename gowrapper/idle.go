@@ -0,0 +1,112 @@
+package ethernetip
+
+import (
+	"log/slog"
+	"time"
+)
+
+// SetIdleTimeout makes the client close its session after timeout without any
+// operation, freeing the controller's connection resources, and transparently
+// re-open it on the next operation. This suits gateways that talk to many
+// controllers sporadically. The check runs on the keep-alive interval, so a
+// session may stay open up to one interval longer than timeout. Active
+// subscriptions count as activity, and an operation in flight keeps the
+// session open. Zero disables the policy.
+func (c *EipClient) SetIdleTimeout(timeout time.Duration) {
+	c.lastUsed.Store(c.Clock().Now().UnixNano())
+	c.idleTimeout.Store(int64(timeout))
+}
+
+// IdleTimeout returns the inactivity period after which the session is closed
+func (c *EipClient) IdleTimeout() time.Duration {
+	return time.Duration(c.idleTimeout.Load())
+}
+
+// IsIdle reports whether the session is currently closed for inactivity
+func (c *EipClient) IsIdle() bool {
+	return c.idleClosed.Load()
+}
+
+// IdleCloses returns how many times the session was closed for inactivity
+func (c *EipClient) IdleCloses() int64 {
+	return c.idleCloses.Load()
+}
+
+// touch records activity and returns the active session, re-opening it if it
+// was closed for inactivity. If re-opening fails it returns 0, which the
+// native library rejects, so the operation fails with its usual error.
+func (c *EipClient) touch() int32 {
+	c.lastUsed.Store(c.Clock().Now().UnixNano())
+	if id := c.session.Load(); id != 0 || !c.idleClosed.Load() {
+		return id
+	}
+
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+	if id := c.session.Load(); id != 0 || !c.idleClosed.Load() {
+		return id
+	}
+	start := c.Clock().Now()
+	event := ReconnectEvent{Time: start, Reason: ReconnectExplicitClose, Cause: "idle timeout"}
+	id, err := c.openSession()
+	event.Duration = c.Clock().Now().Sub(start)
+	if err != nil {
+		c.recordReconnect(event, nil, err)
+		c.logf(slog.LevelError, "❌", "Failed to re-open idle session to %s: %v", c.ipAddr, err)
+		return 0
+	}
+	c.session.Store(id)
+	c.idleClosed.Store(false)
+	event.NewSession = id
+	c.recordReconnect(event, nil, nil)
+	c.logf(slog.LevelInfo, "🔌", "Re-opened idle session to %s as client ID %d", c.ipAddr, id)
+	return id
+}
+
+// beginUse marks an operation in flight until the matching endUse, which
+// records the end of the operation as activity. closeIfIdle leaves the
+// session open while any operation is in flight. Operations may nest, as
+// closeIfIdle never waits for the lock. The pair is two methods rather than a
+// returned closure so the scalar read fast path does not allocate.
+func (c *EipClient) beginUse() {
+	c.activeMu.RLock()
+}
+
+// endUse ends an operation started with beginUse
+func (c *EipClient) endUse() {
+	c.lastUsed.Store(c.Clock().Now().UnixNano())
+	c.activeMu.RUnlock()
+}
+
+// closeIfIdle closes the session if the idle timeout has elapsed since the
+// last operation and none is in flight. It reports whether the session is
+// closed for inactivity.
+func (c *EipClient) closeIfIdle() bool {
+	timeout := c.IdleTimeout()
+	if timeout <= 0 {
+		return c.idleClosed.Load()
+	}
+
+	// An operation in flight is using the session; check again next time
+	if !c.activeMu.TryLock() {
+		return c.idleClosed.Load()
+	}
+	defer c.activeMu.Unlock()
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+	if c.idleClosed.Load() {
+		return true
+	}
+	idle := c.Clock().Now().Sub(time.Unix(0, c.lastUsed.Load()))
+	if idle < timeout {
+		return false
+	}
+
+	old := c.session.Swap(0)
+	c.idleClosed.Store(true)
+	c.idleCloses.Add(1)
+	disconnectSession(c.ipAddr, old)
+	c.closeStandby()
+	c.logf(slog.LevelInfo, "💤", "Closed session %d to %s after %v idle", old, c.ipAddr, idle.Round(time.Second))
+	return true
+}
@@ -0,0 +1,94 @@
+package ethernetip
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCloseIfIdle tests that the session is closed only after the idle timeout
+func TestCloseIfIdle(t *testing.T) {
+	client := &EipClient{}
+	client.session.Store(-3)
+
+	if client.closeIfIdle() {
+		t.Fatal("Expected no idle close without a timeout")
+	}
+
+	client.SetIdleTimeout(time.Hour)
+	if client.closeIfIdle() || client.IsIdle() {
+		t.Fatal("Expected a recently used session to stay open")
+	}
+
+	client.lastUsed.Store(time.Now().Add(-2 * time.Hour).UnixNano())
+	if !client.closeIfIdle() || !client.IsIdle() {
+		t.Fatal("Expected the session to be closed for inactivity")
+	}
+	if client.session.Load() != 0 || client.IdleCloses() != 1 {
+		t.Errorf("Expected no active session and 1 idle close, got %d/%d", client.session.Load(), client.IdleCloses())
+	}
+	if !client.closeIfIdle() || client.IdleCloses() != 1 {
+		t.Error("Expected repeated checks to leave the session closed without counting again")
+	}
+}
+
+// TestCloseIfIdleInFlight tests that the session stays open while an
+// operation is in flight and that its end counts as activity
+func TestCloseIfIdleInFlight(t *testing.T) {
+	client := &EipClient{}
+	client.session.Store(-3)
+	client.SetIdleTimeout(time.Hour)
+	client.lastUsed.Store(time.Now().Add(-2 * time.Hour).UnixNano())
+
+	client.beginUse()
+	if client.closeIfIdle() || client.IsIdle() || client.session.Load() != -3 {
+		t.Fatal("Expected the session to stay open while an operation is in flight")
+	}
+	client.endUse()
+	if client.closeIfIdle() {
+		t.Fatal("Expected the end of the operation to count as activity")
+	}
+
+	client.lastUsed.Store(time.Now().Add(-2 * time.Hour).UnixNano())
+	if !client.closeIfIdle() {
+		t.Error("Expected the session to be closed once nothing is in flight")
+	}
+}
+
+// TestTouchRecordsActivity tests that operations on an open session refresh
+// the activity time without re-opening anything
+func TestTouchRecordsActivity(t *testing.T) {
+	client := &EipClient{}
+	client.session.Store(-3)
+	client.SetIdleTimeout(time.Minute)
+	client.lastUsed.Store(0)
+
+	if id := client.id(); id != -3 {
+		t.Errorf("Expected session -3, got %d", id)
+	}
+	if time.Since(time.Unix(0, client.lastUsed.Load())) > time.Second {
+		t.Error("Expected the operation to be recorded as activity")
+	}
+}
+
+// TestIdleReopen tests re-opening an idle session against a real PLC
+func TestIdleReopen(t *testing.T) {
+	skipIfNoPlc(t)
+
+	client, err := NewClient(getTestPlcIP())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	client.SetIdleTimeout(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if !client.closeIfIdle() {
+		t.Fatal("Expected the session to be closed")
+	}
+	if healthy, err := client.CheckHealth(); err != nil || !healthy {
+		t.Fatalf("Expected the session to be re-opened on demand: %v", err)
+	}
+	if client.IsIdle() {
+		t.Error("Expected the client to be active again")
+	}
+}
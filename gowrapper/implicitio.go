@@ -0,0 +1,749 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultIOPort is the UDP port of Class 1 I/O. Adapters send point-to-point
+// inputs to this port of the scanner.
+const DefaultIOPort = 2222
+
+// defaultIOInputBuffer is the capacity of IOConnection.Inputs
+const defaultIOInputBuffer = 16
+
+// Connection Manager services and objects used by Class 1 connections
+const (
+	cipServiceForwardOpen      byte   = 0x54
+	cipServiceLargeForwardOpen byte   = 0x5B
+	cipServiceForwardClose     byte   = 0x4E
+	cipClassAssembly           uint16 = 0x04
+)
+
+// Common Packet Format items of Class 1 packets
+const (
+	cpfSequencedAddressItem uint16 = 0x8002
+	cpfConnectedDataItem    uint16 = 0x00B1
+)
+
+// Forward Open fields of Class 1 connections
+const (
+	ioTransportClass1Cyclic byte   = 0x01 // Client, cyclic trigger, class 1
+	ioOriginatorVendorID    uint16 = 0x1337
+	ioPointToPoint          uint32 = 2 // Connection type
+	ioPriorityScheduled     uint32 = 2
+)
+
+// ioOriginatorSerial identifies this process as the originator of its
+// connections
+var ioOriginatorSerial = rand.Uint32()
+
+// ioConnectionSerial numbers the connections opened by this process
+var ioConnectionSerial atomic.Uint32
+
+// connectionManagerErrors explains the extended status of common Forward Open
+// failures
+var connectionManagerErrors = map[uint16]string{
+	0x0100: "connection in use or duplicate Forward Open",
+	0x0103: "transport class and trigger not supported",
+	0x0106: "ownership conflict",
+	0x0109: "invalid connection size",
+	0x0111: "RPI not supported",
+	0x0113: "out of connections",
+	0x0114: "vendor ID or product code mismatch",
+	0x0115: "device type mismatch",
+	0x0116: "revision mismatch",
+	0x0117: "invalid produced or consumed application path",
+	0x0118: "invalid or inconsistent configuration application path",
+	0x0127: "invalid O->T connection size",
+	0x0128: "invalid T->O connection size",
+	0x0315: "invalid segment in connection path",
+}
+
+// cipMessenger sends CIP Message Router requests, as *EipClient does
+type cipMessenger interface {
+	SendCIPMessage(service byte, path []byte, data []byte) (*CIPResponse, error)
+}
+
+// IOConnectionConfig describes a Class 1 (implicit, cyclic UDP) connection
+// to an adapter such as a drive or an I/O block. The assembly instances and
+// sizes come from the adapter's EDS file or manual.
+type IOConnectionConfig struct {
+	// ConfigAssembly is the configuration assembly instance; adapters
+	// without configuration usually accept 1
+	ConfigAssembly uint32 `json:"config_assembly"`
+	// ConfigData is written to the configuration assembly by the Forward Open
+	ConfigData []byte `json:"config_data,omitempty"`
+	// OutputAssembly is the O->T connection point. With OutputSize 0 it is
+	// the adapter's input-only heartbeat point and no output data is sent.
+	OutputAssembly uint32 `json:"output_assembly"`
+	OutputSize     int    `json:"output_size"`
+	// InputAssembly is the T->O connection point
+	InputAssembly uint32 `json:"input_assembly"`
+	InputSize     int    `json:"input_size"`
+	// RPI is the requested packet interval of both directions
+	RPI time.Duration `json:"rpi"`
+	// TimeoutMultiplier, 0-7, sets how long either side waits for packets
+	// before dropping the connection: RPI × 4 × 2^TimeoutMultiplier
+	TimeoutMultiplier int `json:"timeout_multiplier"`
+	// OmitRunIdleHeader leaves the 32-bit run/idle header out of outputs,
+	// for adapters whose O->T format has none
+	OmitRunIdleHeader bool `json:"omit_run_idle_header,omitempty"`
+	// InputRunIdleHeader expects a 32-bit run/idle header before the inputs
+	InputRunIdleHeader bool `json:"input_run_idle_header,omitempty"`
+	// LocalAddress is the UDP address inputs are received on, port
+	// DefaultIOPort on all interfaces by default
+	LocalAddress string `json:"local_address,omitempty"`
+	// RemoteAddress is the adapter's UDP address, its host at DefaultIOPort
+	// by default
+	RemoteAddress string `json:"remote_address,omitempty"`
+	// InputBuffer is the capacity of the Inputs channel, 16 by default.
+	// Inputs that do not fit are dropped and counted.
+	InputBuffer int `json:"input_buffer,omitempty"`
+}
+
+// Timeout returns how long either side waits for packets before dropping
+// the connection
+func (cfg IOConnectionConfig) Timeout() time.Duration {
+	return cfg.RPI * time.Duration(4<<cfg.TimeoutMultiplier)
+}
+
+// Validate checks that the connection can be requested
+func (cfg IOConnectionConfig) Validate() error {
+	switch {
+	case cfg.ConfigAssembly == 0 || cfg.OutputAssembly == 0 || cfg.InputAssembly == 0:
+		return NewEipError(ErrInvalidValue, "configuration, output and input assembly instances are required")
+	case cfg.OutputSize < 0 || cfg.InputSize < 0:
+		return NewEipError(ErrInvalidValue, "assembly sizes cannot be negative")
+	case cfg.outputConnectionSize() > 0xFFFF || cfg.inputConnectionSize() > 0xFFFF:
+		return NewEipError(ErrInvalidValue, "assembly sizes exceed a connection")
+	case cfg.RPI < time.Microsecond || cfg.RPI > maxForwardOpenRPI:
+		return NewEipError(ErrInvalidValue, fmt.Sprintf("RPI must be from 1µs to %v, got %v", maxForwardOpenRPI, cfg.RPI))
+	case cfg.TimeoutMultiplier < 0 || cfg.TimeoutMultiplier > MaxTimeoutMultiplier:
+		return NewEipError(ErrInvalidValue, fmt.Sprintf("timeout multiplier must be 0-%d, got %d", MaxTimeoutMultiplier, cfg.TimeoutMultiplier))
+	case cfg.InputBuffer < 0:
+		return NewEipError(ErrInvalidValue, "input buffer cannot be negative")
+	}
+	return nil
+}
+
+// outputHeaderSize returns the size of the run/idle header of outputs
+func (cfg *IOConnectionConfig) outputHeaderSize() int {
+	if cfg.OutputSize == 0 || cfg.OmitRunIdleHeader {
+		return 0
+	}
+	return 4
+}
+
+// inputHeaderSize returns the size of the run/idle header of inputs
+func (cfg *IOConnectionConfig) inputHeaderSize() int {
+	if cfg.InputRunIdleHeader {
+		return 4
+	}
+	return 0
+}
+
+// outputConnectionSize returns the O->T connection size: the sequence
+// count, the run/idle header and the outputs
+func (cfg *IOConnectionConfig) outputConnectionSize() int {
+	return 2 + cfg.outputHeaderSize() + cfg.OutputSize
+}
+
+// inputConnectionSize returns the T->O connection size
+func (cfg *IOConnectionConfig) inputConnectionSize() int {
+	return 2 + cfg.inputHeaderSize() + cfg.InputSize
+}
+
+// connectionPath encodes the route to the adapter's assemblies: the
+// configuration assembly, the output and input connection points and,
+// when withData is set, the configuration data
+func (cfg *IOConnectionConfig) connectionPath(route CIPPath, withData bool) ([]byte, error) {
+	path, err := NewPathBuilder().Route(route).
+		Class(uint32(cipClassAssembly)).Instance(cfg.ConfigAssembly).Build()
+	if err != nil {
+		return nil, err
+	}
+	path = append(path, logicalSegment(0x2C, cfg.OutputAssembly)...)
+	path = append(path, logicalSegment(0x2C, cfg.InputAssembly)...)
+	if withData && len(cfg.ConfigData) > 0 {
+		data := cfg.ConfigData
+		if len(data)%2 != 0 {
+			data = append(append([]byte(nil), data...), 0)
+		}
+		if len(data)/2 > 0xFF {
+			return nil, NewEipError(ErrInvalidValue, fmt.Sprintf("configuration data of %d bytes exceeds 510", len(cfg.ConfigData)))
+		}
+		path = append(path, 0x80, byte(len(data)/2)) // Simple data segment
+		path = append(path, data...)
+	}
+	if len(path) > 0xFF*2 {
+		return nil, NewEipError(ErrInvalidTagAddress, fmt.Sprintf("connection path of %d bytes exceeds 510", len(path)))
+	}
+	return path, nil
+}
+
+// IOInput is one production of the adapter's input assembly
+type IOInput struct {
+	Data []byte `json:"data"`
+	// Run is the run/idle bit of the input header; true without a header
+	Run bool `json:"run"`
+	// Sequence is the CIP sequence count, which changes with new data
+	Sequence uint16    `json:"sequence"`
+	Time     time.Time `json:"time"`
+}
+
+// IOStats counts the packets of an I/O connection
+type IOStats struct {
+	Received uint64 `json:"received"` // Input packets delivered
+	Dropped  uint64 `json:"dropped"`  // Inputs dropped because Inputs was full
+	Stale    uint64 `json:"stale"`    // Input packets out of order or repeated
+	Sent     uint64 `json:"sent"`     // Output packets sent
+}
+
+// IOConnectionInfo describes an open I/O connection as negotiated with the
+// adapter
+type IOConnectionInfo struct {
+	OutputConnectionID uint32        `json:"output_connection_id"` // O->T, assigned by the adapter
+	InputConnectionID  uint32        `json:"input_connection_id"`  // T->O, chosen by the scanner
+	ConnectionSerial   uint16        `json:"connection_serial"`
+	OutputRPI          time.Duration `json:"output_rpi"` // Actual packet interval of outputs
+	InputRPI           time.Duration `json:"input_rpi"`  // Actual packet interval of inputs
+	LargeForwardOpen   bool          `json:"large_forward_open"`
+}
+
+// IOConnection is an open Class 1 connection. Inputs arrive on Inputs as the
+// adapter produces them; the outputs set with SetOutputs are sent every RPI.
+// The connection ends when it is closed or when inputs stop for the
+// configured timeout (see Done and Err).
+type IOConnection struct {
+	cfg    IOConnectionConfig
+	msg    cipMessenger
+	clock  Clock
+	logf   func(level slog.Level, icon, format string, args ...interface{})
+	path   []byte // Connection path without configuration data
+	socket *ioSocket
+	remote *net.UDPAddr
+	inputs chan IOInput
+	done   chan struct{}
+	info   IOConnectionInfo
+
+	mu        sync.Mutex
+	closed    bool
+	err       error
+	outputs   []byte
+	run       bool
+	outputSeq uint16 // CIP sequence count of outputs
+	packetSeq uint32 // Encapsulation sequence number of output packets
+	lastInput time.Time
+	inputSeq  uint32 // Encapsulation sequence number of the last input
+	haveInput bool
+
+	received, dropped, stale, sent atomic.Uint64
+}
+
+// OpenIOConnection opens a Class 1 connection to the adapter at the client's
+// address, along the client's route (see SetRoute), so the client's session
+// carries the Forward Open and Forward Close while the I/O runs over UDP:
+//
+//	conn, err := client.OpenIOConnection(ethernetip.IOConnectionConfig{
+//		ConfigAssembly: 1, OutputAssembly: 150, OutputSize: 4,
+//		InputAssembly: 100, InputSize: 8, RPI: 10 * time.Millisecond,
+//	})
+//	conn.SetOutputs([]byte{1, 0, 0, 0})
+//	conn.SetRun(true)
+//	for input := range conn.Inputs() {
+//		...
+//	}
+//
+// Outputs start in idle mode with zero data until SetRun(true).
+func (c *EipClient) OpenIOConnection(cfg IOConnectionConfig) (*IOConnection, error) {
+	host := c.ipAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	io, err := openIOConnection(c, c.Clock(), c.Route(), host, cfg, c.logf)
+	if err != nil {
+		c.logf(slog.LevelError, "❌", "Failed to open I/O connection to %s: %v", host, err)
+		return nil, err
+	}
+	c.logf(slog.LevelInfo, "🔌", "Opened I/O connection 0x%08X to %s (RPI %v)", io.info.OutputConnectionID, host, io.info.OutputRPI)
+	return io, nil
+}
+
+// openIOConnection opens a Class 1 connection with a Forward Open sent
+// through msg
+func openIOConnection(msg cipMessenger, clock Clock, route CIPPath, host string, cfg IOConnectionConfig,
+	logf func(level slog.Level, icon, format string, args ...interface{})) (*IOConnection, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.LocalAddress == "" {
+		cfg.LocalAddress = ":" + strconv.Itoa(DefaultIOPort)
+	}
+	if cfg.RemoteAddress == "" {
+		cfg.RemoteAddress = net.JoinHostPort(host, strconv.Itoa(DefaultIOPort))
+	}
+	if cfg.InputBuffer == 0 {
+		cfg.InputBuffer = defaultIOInputBuffer
+	}
+	if logf == nil {
+		logf = func(slog.Level, string, string, ...interface{}) {}
+	}
+	remote, err := net.ResolveUDPAddr("udp4", cfg.RemoteAddress)
+	if err != nil {
+		return nil, NewEipError(ErrInvalidValue, fmt.Sprintf("invalid adapter address '%s': %v", cfg.RemoteAddress, err))
+	}
+	path, err := cfg.connectionPath(route, true)
+	if err != nil {
+		return nil, err
+	}
+	closePath, err := cfg.connectionPath(route, false)
+	if err != nil {
+		return nil, err
+	}
+	socket, err := acquireIOSocket(cfg.LocalAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	io := &IOConnection{
+		cfg:     cfg,
+		msg:     msg,
+		clock:   clock,
+		logf:    logf,
+		path:    closePath,
+		socket:  socket,
+		remote:  remote,
+		inputs:  make(chan IOInput, cfg.InputBuffer),
+		done:    make(chan struct{}),
+		outputs: make([]byte, cfg.OutputSize),
+	}
+	io.info.ConnectionSerial = uint16(ioConnectionSerial.Add(1))
+	// Register before the Forward Open so the first inputs are not lost
+	io.info.InputConnectionID = socket.register(io)
+	if err := io.forwardOpen(path); err != nil {
+		socket.unregister(io.info.InputConnectionID)
+		socket.release()
+		return nil, err
+	}
+	io.lastInput = clock.Now()
+
+	interval := io.info.OutputRPI
+	if interval <= 0 {
+		interval = cfg.RPI
+	}
+	go io.produce(interval)
+	return io, nil
+}
+
+// forwardOpen requests the connection, with Large Forward Open when either
+// direction exceeds a standard connection
+func (io *IOConnection) forwardOpen(path []byte) error {
+	cfg := &io.cfg
+	large := cfg.outputConnectionSize() > MaxForwardOpenSize || cfg.inputConnectionSize() > MaxForwardOpenSize
+	rpi := uint32(cfg.RPI / time.Microsecond)
+
+	data := []byte{0x0A, 0x05}                       // Priority/time tick: 1024 ms ticks; about 5 s for the request
+	data = binary.LittleEndian.AppendUint32(data, 0) // O->T ID, assigned by the adapter
+	data = binary.LittleEndian.AppendUint32(data, io.info.InputConnectionID)
+	data = binary.LittleEndian.AppendUint16(data, io.info.ConnectionSerial)
+	data = binary.LittleEndian.AppendUint16(data, ioOriginatorVendorID)
+	data = binary.LittleEndian.AppendUint32(data, ioOriginatorSerial)
+	data = append(data, byte(cfg.TimeoutMultiplier), 0, 0, 0)
+	data = binary.LittleEndian.AppendUint32(data, rpi)
+	data = appendNetworkParameters(data, cfg.outputConnectionSize(), large)
+	data = binary.LittleEndian.AppendUint32(data, rpi)
+	data = appendNetworkParameters(data, cfg.inputConnectionSize(), large)
+	data = append(data, ioTransportClass1Cyclic, byte(len(path)/2))
+	data = append(data, path...)
+
+	service := cipServiceForwardOpen
+	if large {
+		service = cipServiceLargeForwardOpen
+	}
+	resp, err := io.msg.SendCIPMessage(service, classInstancePath(cipClassConnectionManager, 1), data)
+	if err != nil {
+		return forwardOpenError(resp, err)
+	}
+	if len(resp.Data) < 24 {
+		return NewEipErrorWithDetails(ErrInvalidOperation, "Forward Open reply too short",
+			map[string]interface{}{"length": len(resp.Data)})
+	}
+	io.info.OutputConnectionID = binary.LittleEndian.Uint32(resp.Data[0:])
+	io.info.OutputRPI = time.Duration(binary.LittleEndian.Uint32(resp.Data[16:])) * time.Microsecond
+	io.info.InputRPI = time.Duration(binary.LittleEndian.Uint32(resp.Data[20:])) * time.Microsecond
+	io.info.LargeForwardOpen = large
+	return nil
+}
+
+// appendNetworkParameters appends fixed-size, point-to-point, scheduled
+// network connection parameters in the 16-bit form of Forward Open or the
+// 32-bit form of Large Forward Open
+func appendNetworkParameters(data []byte, size int, large bool) []byte {
+	if large {
+		return binary.LittleEndian.AppendUint32(data, ioPointToPoint<<29|ioPriorityScheduled<<26|uint32(size))
+	}
+	return binary.LittleEndian.AppendUint16(data, uint16(ioPointToPoint<<13|ioPriorityScheduled<<10|uint32(size)))
+}
+
+// forwardOpenError explains a rejected Forward Open with the Connection
+// Manager's extended status
+func forwardOpenError(resp *CIPResponse, err error) error {
+	var eipErr *EipError
+	if resp == nil || len(resp.ExtendedStatus) == 0 || !errors.As(err, &eipErr) {
+		return err
+	}
+	ext := resp.ExtendedStatus[0]
+	reason, ok := connectionManagerErrors[ext]
+	if !ok {
+		reason = "connection request rejected"
+	}
+	eipErr.Message = fmt.Sprintf("Forward Open failed: %s (extended status 0x%04X)", reason, ext)
+	return eipErr
+}
+
+// Inputs returns the channel of input productions. It is closed when the
+// connection ends.
+func (io *IOConnection) Inputs() <-chan IOInput {
+	return io.inputs
+}
+
+// SetOutputs sets the output assembly sent every RPI. data must have the
+// configured OutputSize.
+func (io *IOConnection) SetOutputs(data []byte) error {
+	if len(data) != io.cfg.OutputSize {
+		return NewEipError(ErrInvalidValue, fmt.Sprintf("outputs must be %d bytes, got %d", io.cfg.OutputSize, len(data)))
+	}
+	io.mu.Lock()
+	defer io.mu.Unlock()
+	if io.closed {
+		return io.closedError()
+	}
+	copy(io.outputs, data)
+	io.outputSeq++
+	return nil
+}
+
+// SetRun sets the run/idle bit of the output header. Adapters apply outputs
+// in run mode and hold their idle state otherwise.
+func (io *IOConnection) SetRun(run bool) {
+	io.mu.Lock()
+	defer io.mu.Unlock()
+	if io.run != run {
+		io.run = run
+		io.outputSeq++
+	}
+}
+
+// Info returns the connection as negotiated with the adapter
+func (io *IOConnection) Info() IOConnectionInfo {
+	return io.info
+}
+
+// Stats returns the packet counters of the connection
+func (io *IOConnection) Stats() IOStats {
+	return IOStats{
+		Received: io.received.Load(),
+		Dropped:  io.dropped.Load(),
+		Stale:    io.stale.Load(),
+		Sent:     io.sent.Load(),
+	}
+}
+
+// LocalAddr returns the UDP address inputs are received on
+func (io *IOConnection) LocalAddr() net.Addr {
+	return io.socket.conn.LocalAddr()
+}
+
+// Done is closed when the connection ends
+func (io *IOConnection) Done() <-chan struct{} {
+	return io.done
+}
+
+// Err returns why the connection ended: nil while it is open or after Close,
+// an ErrTimeout error when inputs stopped
+func (io *IOConnection) Err() error {
+	io.mu.Lock()
+	defer io.mu.Unlock()
+	return io.err
+}
+
+// Close stops producing outputs and closes the connection with Forward
+// Close. Closing a connection that has already ended does nothing.
+func (io *IOConnection) Close() error {
+	if !io.shutdown(nil) {
+		return nil
+	}
+	data := []byte{0x0A, 0x05}
+	data = binary.LittleEndian.AppendUint16(data, io.info.ConnectionSerial)
+	data = binary.LittleEndian.AppendUint16(data, ioOriginatorVendorID)
+	data = binary.LittleEndian.AppendUint32(data, ioOriginatorSerial)
+	data = append(data, byte(len(io.path)/2), 0)
+	data = append(data, io.path...)
+	_, err := io.msg.SendCIPMessage(cipServiceForwardClose, classInstancePath(cipClassConnectionManager, 1), data)
+	return err
+}
+
+// shutdown ends the connection with err, reporting false if it had already
+// ended
+func (io *IOConnection) shutdown(err error) bool {
+	io.mu.Lock()
+	if io.closed {
+		io.mu.Unlock()
+		return false
+	}
+	io.closed = true
+	io.err = err
+	close(io.done)
+	close(io.inputs)
+	io.mu.Unlock()
+
+	io.socket.unregister(io.info.InputConnectionID)
+	io.socket.release()
+	return true
+}
+
+// closedError is the error of operations on an ended connection
+func (io *IOConnection) closedError() error {
+	if io.err != nil {
+		return io.err
+	}
+	return NewEipError(ErrInvalidOperation, "I/O connection is closed")
+}
+
+// produce sends the outputs every interval and watches for inputs stopping
+func (io *IOConnection) produce(interval time.Duration) {
+	ticker := io.clock.NewTicker(interval)
+	defer ticker.Stop()
+	timeout := io.cfg.Timeout()
+	if io.info.InputRPI > 0 {
+		timeout = io.info.InputRPI * time.Duration(4<<io.cfg.TimeoutMultiplier)
+	}
+	for {
+		select {
+		case <-io.done:
+			return
+		case <-ticker.C():
+		}
+		io.mu.Lock()
+		silent := io.clock.Now().Sub(io.lastInput)
+		io.mu.Unlock()
+		if silent > timeout {
+			err := NewEipErrorWithDetails(ErrTimeout, fmt.Sprintf("I/O connection timed out after %v without inputs", silent.Round(time.Millisecond)),
+				map[string]interface{}{"connection_id": io.info.InputConnectionID, "timeout": timeout.String()})
+			if io.shutdown(err) {
+				io.logf(slog.LevelWarn, "⚠️", "I/O connection 0x%08X to %s timed out", io.info.OutputConnectionID, io.remote)
+			}
+			return
+		}
+		io.sendOutputs()
+	}
+}
+
+// sendOutputs sends one output packet
+func (io *IOConnection) sendOutputs() {
+	io.mu.Lock()
+	io.packetSeq++
+	data := binary.LittleEndian.AppendUint16(make([]byte, 0, io.cfg.outputConnectionSize()), io.outputSeq)
+	if io.cfg.outputHeaderSize() > 0 {
+		var header uint32
+		if io.run {
+			header = 1
+		}
+		data = binary.LittleEndian.AppendUint32(data, header)
+	}
+	data = append(data, io.outputs...)
+	packet := encodeIOPacket(io.info.OutputConnectionID, io.packetSeq, data)
+	io.mu.Unlock()
+
+	if _, err := io.socket.conn.WriteToUDP(packet, io.remote); err == nil {
+		io.sent.Add(1)
+	}
+}
+
+// deliver passes an input packet to Inputs
+func (io *IOConnection) deliver(seq uint32, data []byte) {
+	headerSize := io.cfg.inputHeaderSize()
+	if len(data) < 2+headerSize {
+		io.stale.Add(1)
+		return
+	}
+	input := IOInput{Sequence: binary.LittleEndian.Uint16(data), Run: true}
+	if headerSize > 0 {
+		input.Run = binary.LittleEndian.Uint32(data[2:])&1 != 0
+	}
+	input.Data = append([]byte(nil), data[2+headerSize:]...)
+
+	io.mu.Lock()
+	defer io.mu.Unlock()
+	if io.closed {
+		return
+	}
+	// Sequence numbers wrap; anything not ahead of the last input is stale
+	if io.haveInput && int32(seq-io.inputSeq) <= 0 {
+		io.stale.Add(1)
+		return
+	}
+	io.inputSeq, io.haveInput = seq, true
+	io.lastInput = io.clock.Now()
+	input.Time = io.lastInput
+	select {
+	case io.inputs <- input:
+		io.received.Add(1)
+	default:
+		io.dropped.Add(1)
+	}
+}
+
+// encodeIOPacket encodes a Class 1 packet: a sequenced address item and a
+// connected data item
+func encodeIOPacket(connectionID, seq uint32, data []byte) []byte {
+	p := make([]byte, 0, 18+len(data))
+	p = binary.LittleEndian.AppendUint16(p, 2) // Item count
+	p = binary.LittleEndian.AppendUint16(p, cpfSequencedAddressItem)
+	p = binary.LittleEndian.AppendUint16(p, 8)
+	p = binary.LittleEndian.AppendUint32(p, connectionID)
+	p = binary.LittleEndian.AppendUint32(p, seq)
+	p = binary.LittleEndian.AppendUint16(p, cpfConnectedDataItem)
+	p = binary.LittleEndian.AppendUint16(p, uint16(len(data)))
+	return append(p, data...)
+}
+
+// decodeIOPacket decodes a Class 1 packet
+func decodeIOPacket(b []byte) (connectionID, seq uint32, data []byte, err error) {
+	if len(b) < 2 {
+		return 0, 0, nil, fmt.Errorf("packet too short")
+	}
+	count := int(binary.LittleEndian.Uint16(b))
+	pos := 2
+	haveAddress, haveData := false, false
+	for i := 0; i < count; i++ {
+		if pos+4 > len(b) {
+			return 0, 0, nil, fmt.Errorf("item %d truncated", i)
+		}
+		itemType := binary.LittleEndian.Uint16(b[pos:])
+		length := int(binary.LittleEndian.Uint16(b[pos+2:]))
+		pos += 4
+		if pos+length > len(b) {
+			return 0, 0, nil, fmt.Errorf("item %d truncated", i)
+		}
+		switch {
+		case itemType == cpfSequencedAddressItem && length == 8:
+			connectionID = binary.LittleEndian.Uint32(b[pos:])
+			seq = binary.LittleEndian.Uint32(b[pos+4:])
+			haveAddress = true
+		case itemType == cpfConnectedDataItem:
+			data = b[pos : pos+length]
+			haveData = true
+		}
+		pos += length
+	}
+	if !haveAddress || !haveData {
+		return 0, 0, nil, fmt.Errorf("missing sequenced address or connected data item")
+	}
+	return connectionID, seq, data, nil
+}
+
+// ioSocket is a UDP socket shared by the I/O connections receiving on one
+// local address, which adapters all send to
+type ioSocket struct {
+	addr string
+	conn *net.UDPConn
+
+	mu    sync.Mutex
+	conns map[uint32]*IOConnection
+	refs  int // Guarded by ioSockets
+}
+
+// ioSockets holds the open I/O sockets by local address
+var ioSockets = struct {
+	sync.Mutex
+	m map[string]*ioSocket
+}{m: make(map[string]*ioSocket)}
+
+// acquireIOSocket returns the socket listening on addr, opening it if needed
+func acquireIOSocket(addr string) (*ioSocket, error) {
+	ioSockets.Lock()
+	defer ioSockets.Unlock()
+	if s, ok := ioSockets.m[addr]; ok {
+		s.refs++
+		return s, nil
+	}
+	udpAddr, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, NewEipError(ErrInvalidValue, fmt.Sprintf("invalid local I/O address '%s': %v", addr, err))
+	}
+	conn, err := net.ListenUDP("udp4", udpAddr)
+	if err != nil {
+		return nil, NewEipErrorWithDetails(ErrConnectionFailed, fmt.Sprintf("Failed to listen for I/O on %s: %v", addr, err),
+			map[string]interface{}{"local_address": addr})
+	}
+	s := &ioSocket{addr: addr, conn: conn, conns: make(map[uint32]*IOConnection), refs: 1}
+	ioSockets.m[addr] = s
+	go s.read()
+	return s, nil
+}
+
+// release drops a reference to the socket, closing it with the last one
+func (s *ioSocket) release() {
+	ioSockets.Lock()
+	defer ioSockets.Unlock()
+	s.refs--
+	if s.refs == 0 {
+		delete(ioSockets.m, s.addr)
+		s.conn.Close()
+	}
+}
+
+// register picks an unused T->O connection ID for io
+func (s *ioSocket) register(io *IOConnection) uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		id := rand.Uint32()
+		if _, used := s.conns[id]; id != 0 && !used {
+			s.conns[id] = io
+			return id
+		}
+	}
+}
+
+// unregister stops delivering the inputs of connection id
+func (s *ioSocket) unregister(id uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, id)
+}
+
+// read delivers input packets to their connections until the socket closes
+func (s *ioSocket) read() {
+	buf := make([]byte, 65536)
+	for {
+		n, from, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		id, seq, data, err := decodeIOPacket(buf[:n])
+		if err != nil {
+			continue
+		}
+		s.mu.Lock()
+		io := s.conns[id]
+		s.mu.Unlock()
+		// Only the adapter of a connection may produce its inputs
+		if io != nil && from.IP.Equal(io.remote.IP) {
+			io.deliver(seq, data)
+		}
+	}
+}
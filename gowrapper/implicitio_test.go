@@ -0,0 +1,254 @@
+package ethernetip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConnectionManager answers Forward Open and Forward Close requests
+type fakeConnectionManager struct {
+	mu       sync.Mutex
+	services []byte
+	requests [][]byte
+	status   byte
+	ext      []uint16
+}
+
+func (m *fakeConnectionManager) SendCIPMessage(service byte, path []byte, data []byte) (*CIPResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.services = append(m.services, service)
+	m.requests = append(m.requests, append([]byte(nil), data...))
+	resp := &CIPResponse{Service: service | 0x80, GeneralStatus: m.status, ExtendedStatus: m.ext}
+	if m.status != 0 {
+		return resp, cipStatusError(service, resp)
+	}
+	if service != cipServiceForwardClose {
+		resp.Data = make([]byte, 26)
+		binary.LittleEndian.PutUint32(resp.Data[0:], 0xAABBCCDD)
+		copy(resp.Data[4:8], data[6:10])                     // T->O ID
+		binary.LittleEndian.PutUint32(resp.Data[16:], 10000) // O->T API
+		binary.LittleEndian.PutUint32(resp.Data[20:], 10000) // T->O API
+	}
+	return resp, nil
+}
+
+// testIOConfig returns a connection to an adapter listening on adapter
+func testIOConfig(adapter *net.UDPConn) IOConnectionConfig {
+	return IOConnectionConfig{
+		ConfigAssembly: 1, OutputAssembly: 150, OutputSize: 4,
+		InputAssembly: 100, InputSize: 8, RPI: 10 * time.Millisecond,
+		LocalAddress:  "127.0.0.1:0",
+		RemoteAddress: adapter.LocalAddr().String(),
+	}
+}
+
+// listenAdapter opens the adapter's side of a test connection
+func listenAdapter(t *testing.T) *net.UDPConn {
+	t.Helper()
+	adapter, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Skipf("UDP unavailable: %v", err)
+	}
+	t.Cleanup(func() { adapter.Close() })
+	return adapter
+}
+
+// TestIOConnectionConfig tests validation and connection sizes
+func TestIOConnectionConfig(t *testing.T) {
+	cfg := IOConnectionConfig{ConfigAssembly: 1, OutputAssembly: 150, OutputSize: 4, InputAssembly: 100, InputSize: 8, RPI: 10 * time.Millisecond, TimeoutMultiplier: 2}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected a valid configuration, got %v", err)
+	}
+	if cfg.outputConnectionSize() != 10 || cfg.inputConnectionSize() != 10 || cfg.Timeout() != 160*time.Millisecond {
+		t.Errorf("Unexpected sizes %d, %d or timeout %v", cfg.outputConnectionSize(), cfg.inputConnectionSize(), cfg.Timeout())
+	}
+	heartbeat := cfg
+	heartbeat.OutputSize = 0
+	if heartbeat.outputConnectionSize() != 2 {
+		t.Errorf("Expected a heartbeat of only the sequence count, got %d", heartbeat.outputConnectionSize())
+	}
+
+	invalid := map[string]func(*IOConnectionConfig){
+		"no config assembly": func(c *IOConnectionConfig) { c.ConfigAssembly = 0 },
+		"no input assembly":  func(c *IOConnectionConfig) { c.InputAssembly = 0 },
+		"negative size":      func(c *IOConnectionConfig) { c.InputSize = -1 },
+		"huge size":          func(c *IOConnectionConfig) { c.OutputSize = 0x10000 },
+		"zero RPI":           func(c *IOConnectionConfig) { c.RPI = 0 },
+		"timeout":            func(c *IOConnectionConfig) { c.TimeoutMultiplier = 8 },
+		"buffer":             func(c *IOConnectionConfig) { c.InputBuffer = -1 },
+	}
+	var eipErr *EipError
+	for name, modify := range invalid {
+		c := cfg
+		modify(&c)
+		if err := c.Validate(); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidValue {
+			t.Errorf("%s: expected an invalid value, got %v", name, err)
+		}
+	}
+}
+
+// TestIOConnectionPath tests the path to the adapter's assemblies
+func TestIOConnectionPath(t *testing.T) {
+	cfg := IOConnectionConfig{ConfigAssembly: 1, OutputAssembly: 150, InputAssembly: 0x164, ConfigData: []byte{1, 2, 3}}
+	path, err := cfg.connectionPath(CIPPath{{Port: 1, Link: "2"}}, true)
+	want := []byte{0x01, 0x02, 0x20, 0x04, 0x24, 0x01, 0x2C, 0x96, 0x2D, 0x00, 0x64, 0x01, 0x80, 0x02, 1, 2, 3, 0}
+	if err != nil || !bytes.Equal(path, want) {
+		t.Errorf("Expected % X, got % X, %v", want, path, err)
+	}
+	path, err = cfg.connectionPath(nil, false)
+	if err != nil || !bytes.Equal(path, want[2:12]) {
+		t.Errorf("Expected the path without data, got % X, %v", path, err)
+	}
+}
+
+// TestIOPacket tests encoding and decoding Class 1 packets
+func TestIOPacket(t *testing.T) {
+	packet := encodeIOPacket(0x11223344, 7, []byte{1, 0, 0xAA})
+	id, seq, data, err := decodeIOPacket(packet)
+	if err != nil || id != 0x11223344 || seq != 7 || !bytes.Equal(data, []byte{1, 0, 0xAA}) {
+		t.Errorf("Unexpected packet %X %d % X %v", id, seq, data, err)
+	}
+	for _, bad := range [][]byte{nil, packet[:10], {1, 0, 0xB1, 0, 0, 0}} {
+		if _, _, _, err := decodeIOPacket(bad); err == nil {
+			t.Errorf("Expected an error for % X", bad)
+		}
+	}
+}
+
+// TestOpenIOConnection tests the Forward Open, cyclic outputs, inputs and
+// Forward Close of a connection
+func TestOpenIOConnection(t *testing.T) {
+	adapter := listenAdapter(t)
+	cm := &fakeConnectionManager{}
+	clock := NewFakeClock(time.Unix(1000, 0))
+	conn, err := openIOConnection(cm, clock, CIPPath{{Port: 1, Link: "0"}}, "127.0.0.1", testIOConfig(adapter), nil)
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer conn.Close()
+
+	req := cm.requests[0]
+	if cm.services[0] != cipServiceForwardOpen || req[18] != 0 || req[34] != ioTransportClass1Cyclic {
+		t.Errorf("Unexpected Forward Open 0x%02X % X", cm.services[0], req)
+	}
+	if got := binary.LittleEndian.Uint16(req[26:]); got != 0x4800|10 {
+		t.Errorf("Unexpected O->T parameters 0x%04X", got)
+	}
+	info := conn.Info()
+	if info.OutputConnectionID != 0xAABBCCDD || info.InputConnectionID == 0 || info.OutputRPI != 10*time.Millisecond || info.LargeForwardOpen {
+		t.Errorf("Unexpected connection %+v", info)
+	}
+
+	// Outputs are idle until run, then carry the run bit and the data
+	if err := conn.SetOutputs([]byte{1, 2}); err == nil {
+		t.Error("Expected an error for outputs of the wrong size")
+	}
+	conn.SetOutputs([]byte{1, 2, 3, 4})
+	conn.SetRun(true)
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Millisecond)
+	adapter.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 256)
+	n, from, err := adapter.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Expected an output packet: %v", err)
+	}
+	id, seq, data, err := decodeIOPacket(buf[:n])
+	if err != nil || id != 0xAABBCCDD || seq != 1 || !bytes.Equal(data[2:], []byte{1, 0, 0, 0, 1, 2, 3, 4}) {
+		t.Errorf("Unexpected output packet %X %d % X %v", id, seq, data, err)
+	}
+
+	send := func(seq uint32, value byte) {
+		data := append([]byte{byte(seq), 0}, value, 0, 0, 0, 0, 0, 0, 0)
+		adapter.WriteToUDP(encodeIOPacket(info.InputConnectionID, seq, data), from)
+	}
+	send(5, 0x42)
+	select {
+	case input := <-conn.Inputs():
+		if input.Data[0] != 0x42 || len(input.Data) != 8 || !input.Run || input.Sequence != 5 {
+			t.Errorf("Unexpected input %+v", input)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected an input")
+	}
+	send(5, 0x43) // Repeated
+	send(6, 0x44)
+	select {
+	case input := <-conn.Inputs():
+		if input.Data[0] != 0x44 {
+			t.Errorf("Expected the repeated packet to be discarded, got %+v", input)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected an input")
+	}
+	if stats := conn.Stats(); stats.Received != 2 || stats.Stale != 1 || stats.Sent != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	if err := conn.Close(); err != nil || cm.services[1] != cipServiceForwardClose {
+		t.Errorf("Expected a Forward Close, got %v %X", err, cm.services)
+	}
+	if _, open := <-conn.Inputs(); open || conn.Err() != nil {
+		t.Error("Expected the inputs closed without an error")
+	}
+	if conn.Close() != nil || len(cm.services) != 2 {
+		t.Error("Expected closing twice to do nothing")
+	}
+}
+
+// TestIOConnectionTimeout tests that the connection ends when inputs stop
+func TestIOConnectionTimeout(t *testing.T) {
+	adapter := listenAdapter(t)
+	cm := &fakeConnectionManager{}
+	clock := NewFakeClock(time.Unix(1000, 0))
+	conn, err := openIOConnection(cm, clock, nil, "127.0.0.1", testIOConfig(adapter), nil)
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	clock.BlockUntil(1)
+	clock.Advance(50 * time.Millisecond)
+	select {
+	case <-conn.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the connection to time out")
+	}
+	var eipErr *EipError
+	if !errors.As(conn.Err(), &eipErr) || eipErr.Code != ErrTimeout {
+		t.Errorf("Expected a timeout, got %v", conn.Err())
+	}
+	if err := conn.SetOutputs(make([]byte, 4)); !errors.Is(err, conn.Err()) {
+		t.Errorf("Expected the timeout from SetOutputs, got %v", err)
+	}
+	if conn.Close() != nil || len(cm.services) != 1 {
+		t.Error("Expected no Forward Close after a timeout")
+	}
+}
+
+// TestOpenIOConnectionRejected tests large connections and explaining a
+// rejected Forward Open
+func TestOpenIOConnectionRejected(t *testing.T) {
+	adapter := listenAdapter(t)
+	cm := &fakeConnectionManager{status: 0x01, ext: []uint16{0x0114}}
+	cfg := testIOConfig(adapter)
+	cfg.InputSize = 1000
+	_, err := openIOConnection(cm, NewFakeClock(time.Unix(1000, 0)), nil, "127.0.0.1", cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "vendor ID or product code mismatch") {
+		t.Errorf("Expected the extended status explained, got %v", err)
+	}
+	if cm.services[0] != cipServiceLargeForwardOpen {
+		t.Errorf("Expected a Large Forward Open, got 0x%02X", cm.services[0])
+	}
+	ioSockets.Lock()
+	open := len(ioSockets.m)
+	ioSockets.Unlock()
+	if open != 0 {
+		t.Errorf("Expected the I/O socket released, %d open", open)
+	}
+}
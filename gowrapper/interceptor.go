@@ -0,0 +1,98 @@
+package ethernetip
+
+import (
+	"context"
+)
+
+// OperationKind distinguishes the operations seen by interceptors
+type OperationKind string
+
+const (
+	OperationRead  OperationKind = "read"
+	OperationWrite OperationKind = "write"
+)
+
+// Operation describes a single tag operation passed through the interceptor chain
+type Operation struct {
+	Kind     OperationKind
+	TagName  string
+	DataType PlcDataType
+	// Value is the value being written, or the value read once the
+	// operation has completed successfully
+	Value  *PlcValue
+	Caller CallerIdentity
+	// RequestID is the tracing ID assigned when the operation reaches the
+	// PLC; zero while interceptors run before next is called
+	RequestID uint64
+}
+
+// Interceptor wraps tag operations issued through the context-aware API.
+// It must call next to continue the chain; it may inspect or modify op,
+// short-circuit by returning an error, or observe the result.
+type Interceptor func(ctx context.Context, op *Operation, next func(ctx context.Context, op *Operation) error) error
+
+// AddInterceptor appends an interceptor to the client's chain. Interceptors run
+// in the order they were added.
+func (c *EipClient) AddInterceptor(interceptor Interceptor) {
+	c.interceptorMu.Lock()
+	c.interceptors = append(c.interceptors, interceptor)
+	c.interceptorMu.Unlock()
+}
+
+// runInterceptors executes op through interceptors and finally calls final
+func runInterceptors(ctx context.Context, interceptors []Interceptor, op *Operation, final func(ctx context.Context, op *Operation) error) error {
+	if len(interceptors) == 0 {
+		return final(ctx, op)
+	}
+	return interceptors[0](ctx, op, func(ctx context.Context, op *Operation) error {
+		return runInterceptors(ctx, interceptors[1:], op, final)
+	})
+}
+
+// invoke runs op through the client's interceptors, honouring ctx cancellation
+// and annotating errors with the caller identity.
+func (c *EipClient) invoke(ctx context.Context, op *Operation, final func(ctx context.Context, op *Operation) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if id, ok := CallerIdentityFromContext(ctx); ok {
+		op.Caller = id
+	}
+
+	c.interceptorMu.RLock()
+	interceptors := c.interceptors
+	c.interceptorMu.RUnlock()
+
+	return annotateError(ctx, runInterceptors(ctx, interceptors, op, final))
+}
+
+// ReadValueContext reads a value like ReadValue, running the operation through
+// the client's interceptors with the caller identity from ctx
+func (c *EipClient) ReadValueContext(ctx context.Context, tagName string, dataType PlcDataType) (*PlcValue, error) {
+	op := &Operation{Kind: OperationRead, TagName: tagName, DataType: dataType}
+	err := c.invoke(ctx, op, func(ctx context.Context, op *Operation) error {
+		return c.traced(op, func() error {
+			value, err := c.ReadValue(op.TagName, op.DataType)
+			if err != nil {
+				return err
+			}
+			op.Value = value
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return op.Value, nil
+}
+
+// WriteValueContext writes a value like WriteValue, running the operation through
+// the client's interceptors with the caller identity from ctx
+func (c *EipClient) WriteValueContext(ctx context.Context, tagName string, value *PlcValue) error {
+	op := &Operation{Kind: OperationWrite, TagName: tagName, DataType: value.Type, Value: value}
+	return c.invoke(ctx, op, func(ctx context.Context, op *Operation) error {
+		return c.traced(op, func() error {
+			return c.writeValueFor(op.Caller, op.TagName, op.Value)
+		})
+	})
+}
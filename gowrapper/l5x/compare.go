@@ -0,0 +1,57 @@
+package l5x
+
+import (
+	"strings"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// TagList lists the tags of the project as EipClient.TagList lists the tags
+// found online: without the program entries, and without alias tags, which
+// the controller does not list as symbols
+func (p *Project) TagList() []ethernetip.TagListEntry {
+	var entries []ethernetip.TagListEntry
+	for _, tag := range p.Tags {
+		if _, alias := p.Aliases[tag.Name]; alias || (tag.Program == "" && strings.HasPrefix(tag.Name, "Program:")) {
+			continue
+		}
+		entry := ethernetip.TagListEntry{Name: tag.Name, Scope: "controller", Program: tag.Program}
+		if tag.Program != "" {
+			entry.Scope = "program"
+		}
+		if meta := p.Metadata[tag.Name]; meta != nil {
+			entry.Type = meta.TypeName
+			entry.Dimensions = meta.Dimensions
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// CompareTags compares the project with the tags client found online with
+// its last DiscoverTagDatabase, and reports the tags added, removed or
+// retyped online since the project was exported. Alias tags and the tags the
+// project skipped are left out of the comparison. The client must not have
+// been seeded with the project, or its structure types would be named after
+// the project's template numbering.
+func (p *Project) CompareTags(client *ethernetip.EipClient) (*ethernetip.TagDrift, error) {
+	online, err := client.TagList()
+	if err != nil {
+		return nil, err
+	}
+	ignored := make(map[string]bool, len(p.Aliases)+len(p.Skipped))
+	for name := range p.Aliases {
+		ignored[strings.ToUpper(name)] = true
+	}
+	for _, s := range p.Skipped {
+		name, _, _ := strings.Cut(s, ": ")
+		ignored[strings.ToUpper(name)] = true
+	}
+	var kept []ethernetip.TagListEntry
+	for _, e := range online {
+		if !ignored[strings.ToUpper(e.Name)] {
+			kept = append(kept, e)
+		}
+	}
+	return ethernetip.CompareTagLists(p.TagList(), kept), nil
+}
@@ -0,0 +1,72 @@
+package l5x
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// TestTagList tests that the project lists its tags without aliases and
+// program entries
+func TestTagList(t *testing.T) {
+	var got []string
+	for _, e := range readProject(t).TagList() {
+		got = append(got, e.Name+":"+e.TypeString())
+	}
+	want := "Speed:DINT Setpoints:REAL[10] Grid:INT[4,3] Alarms:BOOL[64] Recipe:RECIPE Message:STRING20 V101:Valve " +
+		"Program:Main.Count:DINT Program:Main.Delay:TIMER"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Expected %s, got %s", want, strings.Join(got, " "))
+	}
+}
+
+// TestCompareTags tests comparing the project with a controller edited online
+func TestCompareTags(t *testing.T) {
+	project := readProject(t)
+
+	data, err := os.ReadFile("testdata/line1.L5X")
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.NewReplacer(
+		`Name="Speed" TagType="Base" DataType="DINT"`, `Name="Speed" TagType="Base" DataType="REAL"`,
+		`DataType="REAL" Dimensions="10"`, `DataType="REAL" Dimensions="12"`,
+		`<Tag Name="Grid" TagType="Base" DataType="INT" Dimensions="4 3" Radix="Decimal" Constant="false" ExternalAccess="Read/Write"/>`,
+		`<Tag Name="Extra" TagType="Base" DataType="DINT"/>`,
+	).Replace(string(data))
+	online, err := Read(strings.NewReader(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &ethernetip.EipClient{}
+	if _, err := project.CompareTags(client); err == nil {
+		t.Error("Expected an error before discovery")
+	}
+	online.Seed(client)
+
+	drift, err := project.CompareTags(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift.Added) != 1 || drift.Added[0].Name != "Extra" {
+		t.Errorf("Unexpected added tags %+v", drift.Added)
+	}
+	if len(drift.Removed) != 1 || drift.Removed[0].Name != "Grid" {
+		t.Errorf("Unexpected removed tags %+v", drift.Removed)
+	}
+	var retyped []string
+	for _, r := range drift.Retyped {
+		retyped = append(retyped, r.Name+":"+r.Project.TypeString()+"->"+r.Online.TypeString())
+	}
+	if strings.Join(retyped, " ") != "Speed:DINT->REAL Setpoints:REAL[10]->REAL[12]" {
+		t.Errorf("Unexpected retyped tags %v", retyped)
+	}
+
+	client.ImportTagDatabase(project.TagExport())
+	client.SeedTagMetadata(project.Metadata)
+	if drift, err := project.CompareTags(client); err != nil || !drift.Empty() {
+		t.Errorf("Expected no drift, got %+v, %v", drift, err)
+	}
+}
@@ -0,0 +1,496 @@
+// Package l5x reads Studio 5000 L5X project exports and describes their tags
+// and structure types the way the online browse does: as TagInfo entries,
+// StructTemplate layouts and TagMetadata. Tools can then be built and checked
+// against a project without a controller, and a client's caches can be
+// seeded before it first touches the PLC:
+//
+//	project, err := l5x.ReadFile("Line1.L5X")
+//	project.Seed(client) // tag database, templates and metadata
+//	def, err := project.TagExport().UdtDefinition("RECIPE")
+//
+// An L5X file does not hold what only the controller assigns: symbol
+// instance IDs are 0, template instances are numbered in the order the types
+// are first used, and structure handles are 0. Run DiscoverTagDatabase to
+// replace them with the controller's. Add-On Instruction types are laid out
+// with the UDT rules from their parameters and local tags; compare them with
+// the controller's templates before relying on their member offsets.
+package l5x
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/codec"
+)
+
+// Symbol type word layout, as in the Symbol Object
+const (
+	symbolTypeStructBit = 0x8000
+	symbolTypeDimsShift = 13
+	// symbolTypeProgram is the symbol type of the "Program:Name" entries
+	symbolTypeProgram = 0x68
+	// typeDword is the code of the 32-bit words BOOL arrays are stored in
+	typeDword uint16 = 0xD3
+)
+
+// Project is the controller of an L5X export
+type Project struct {
+	Controller       string
+	ProcessorType    string
+	SoftwareRevision string
+	// Tags are the controller and program tags, and a "Program:Name" entry
+	// per program, as DiscoverTagDatabase lists them
+	Tags []ethernetip.TagInfo
+	// Templates are the structure types the tags use, nested ones included
+	Templates []*ethernetip.StructTemplate
+	// Metadata describes each tag in Tags except the program entries, with
+	// the array sizes and external access the symbol list does not carry
+	Metadata map[string]*ethernetip.TagMetadata
+	// Aliases maps the alias tags in Tags to their targets as written
+	Aliases map[string]string
+	// Skipped lists the tags left out because their type could not be
+	// resolved, such as aliases of module tags, with the reason
+	Skipped []string
+}
+
+// ReadFile reads an L5X file
+func ReadFile(path string) (*Project, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read reads an L5X export
+func Read(r io.Reader) (*Project, error) {
+	var content xmlContent
+	if err := xml.NewDecoder(r).Decode(&content); err != nil {
+		return nil, ethernetip.NewEipError(ethernetip.ErrInvalidValue, fmt.Sprintf("invalid L5X file: %v", err))
+	}
+	if content.XMLName.Local != "RSLogix5000Content" || content.Controller == nil {
+		return nil, ethernetip.NewEipError(ethernetip.ErrInvalidValue, "not an L5X controller export")
+	}
+	ctl := content.Controller
+
+	b := newBuilder(ctl)
+	p := &Project{
+		Controller:       ctl.Name,
+		ProcessorType:    ctl.ProcessorType,
+		SoftwareRevision: content.SoftwareRevision,
+		Metadata:         make(map[string]*ethernetip.TagMetadata),
+		Aliases:          make(map[string]string),
+	}
+	controllerTags := b.addTags(p, "", ctl.Tags, nil)
+	for _, program := range ctl.Programs {
+		p.Tags = append(p.Tags, ethernetip.TagInfo{Name: "Program:" + program.Name, SymbolType: symbolTypeProgram})
+		b.addTags(p, program.Name, program.Tags, controllerTags)
+	}
+	for _, t := range b.order {
+		p.Templates = append(p.Templates, t.template)
+	}
+	return p, nil
+}
+
+// Database returns the tags of the project as a TagDatabase
+func (p *Project) Database() *ethernetip.TagDatabase {
+	return ethernetip.NewTagDatabase(p.Tags)
+}
+
+// TagExport returns the project in the tag export format, to write with
+// WriteTagExport, load with ImportTagDatabase or pass to eipgen
+func (p *Project) TagExport() *ethernetip.TagExport {
+	// The templates are all in memory, so the export cannot fail
+	export, _ := ethernetip.NewTagExport(context.Background(), p.Database(), p)
+	return export
+}
+
+// GetTemplate returns the template of a structure type by instance, so a
+// Project is an ethernetip.TemplateSource
+func (p *Project) GetTemplate(instance uint16) (*ethernetip.StructTemplate, error) {
+	for _, t := range p.Templates {
+		if t.Instance == instance {
+			return t, nil
+		}
+	}
+	return nil, ethernetip.NewEipErrorWithDetails(ethernetip.ErrInvalidDataType,
+		fmt.Sprintf("template %d is not in the project", instance), map[string]interface{}{"instance": instance})
+}
+
+// Seed loads the project into client as if its tags had been discovered: the
+// tag database and templates are imported and the tag metadata is cached.
+// It returns the tag database.
+func (p *Project) Seed(client *ethernetip.EipClient) *ethernetip.TagDatabase {
+	db := client.ImportTagDatabase(p.TagExport())
+	client.SeedTagMetadata(p.Metadata)
+	return db
+}
+
+// tagType is a resolved tag or member type
+type tagType struct {
+	code     uint16 // Atomic type code, 0 for structures
+	size     int    // Element size in bytes
+	name     string // Type name as written in the project
+	template *builtType
+}
+
+// builtType is a structure type laid out as a template
+type builtType struct {
+	template *ethernetip.StructTemplate
+	layout   *codec.Layout
+}
+
+// builder resolves type names and lays out structure types on first use
+type builder struct {
+	udts  map[string]xmlDataType
+	aois  map[string]xmlAOI
+	built map[string]*builtType
+	// order lists the built types by template instance
+	order    []*builtType
+	resolves map[string]bool // Types being built, to detect recursion
+}
+
+func newBuilder(ctl *xmlController) *builder {
+	b := &builder{
+		udts:     make(map[string]xmlDataType),
+		aois:     make(map[string]xmlAOI),
+		built:    make(map[string]*builtType),
+		resolves: make(map[string]bool),
+	}
+	for _, dt := range ctl.DataTypes {
+		b.udts[strings.ToUpper(dt.Name)] = dt
+	}
+	for _, aoi := range ctl.AOIs {
+		b.aois[strings.ToUpper(aoi.Name)] = aoi
+	}
+	return b
+}
+
+// atomicTypes are the elementary types by L5X name
+var atomicTypes = map[string]uint16{
+	"BOOL": codec.TypeBool, "SINT": codec.TypeSint, "INT": codec.TypeInt, "DINT": codec.TypeDint,
+	"LINT": codec.TypeLint, "USINT": codec.TypeUsint, "UINT": codec.TypeUint, "UDINT": codec.TypeUdint,
+	"ULINT": codec.TypeUlint, "REAL": codec.TypeReal, "LREAL": codec.TypeLreal,
+}
+
+// resolve returns the type named name
+func (b *builder) resolve(name string) (tagType, error) {
+	upper := strings.ToUpper(name)
+	if code, ok := atomicTypes[upper]; ok {
+		size, _ := codec.TypeSize(code)
+		return tagType{code: code, size: size, name: upper}, nil
+	}
+	t, err := b.structure(name)
+	if err != nil {
+		return tagType{}, err
+	}
+	return tagType{size: t.template.Size, name: t.template.Name, template: t}, nil
+}
+
+// structure builds the structure type named name, matched ignoring case
+func (b *builder) structure(name string) (*builtType, error) {
+	key := strings.ToUpper(name)
+	if t, ok := b.built[key]; ok {
+		return t, nil
+	}
+	if b.resolves[key] {
+		return nil, fmt.Errorf("type %s contains itself", name)
+	}
+	b.resolves[key] = true
+	defer delete(b.resolves, key)
+
+	var members []xmlMember
+	typeName := key
+	if dt, ok := b.udts[key]; ok {
+		members, typeName = dt.Members, dt.Name
+	} else if aoi, ok := b.aois[key]; ok {
+		members, typeName = aoi.members(), aoi.Name
+	} else if predefined, ok := predefinedTypes[key]; ok {
+		members = predefined
+	} else {
+		return nil, fmt.Errorf("unknown data type %s", name)
+	}
+
+	t, err := b.layout(typeName, members)
+	if err != nil {
+		return nil, err
+	}
+	t.template.Instance = uint16(len(b.order) + 1)
+	b.built[key] = t
+	b.order = append(b.order, t)
+	return t, nil
+}
+
+// layout lays out members as the controller does and describes them as
+// template members. BIT members are placed in the hidden host member they
+// name as Target; scalar BOOLs, which only Add-On Instructions declare, are
+// packed by the layout.
+func (b *builder) layout(name string, members []xmlMember) (*builtType, error) {
+	var fields []codec.Member
+	types := make(map[string]tagType, len(members))
+	for _, m := range members {
+		if strings.EqualFold(m.DataType, "BIT") {
+			continue
+		}
+		t, err := b.resolve(m.DataType)
+		if err != nil {
+			return nil, fmt.Errorf("member %s of %s: %w", m.Name, name, err)
+		}
+		types[m.Name] = t
+		field := codec.Member{Name: m.Name, Type: t.code, Count: m.Dimension}
+		if t.code == codec.TypeBool && m.Dimension > 0 {
+			// BOOL arrays take whole 32-bit words
+			field.Count = max(m.Dimension, 32)
+		}
+		if t.template != nil {
+			field.Type, field.Struct = codec.TypeStruct, t.template.layout
+		}
+		fields = append(fields, field)
+	}
+	layout, err := codec.NewLayout(fields)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	template := &ethernetip.StructTemplate{Name: name, Size: layout.Size}
+	for _, m := range members {
+		if strings.EqualFold(m.DataType, "BIT") {
+			host, ok := layout.Field(m.Target)
+			if !ok {
+				return nil, fmt.Errorf("member %s of %s: unknown target %s", m.Name, name, m.Target)
+			}
+			template.Members = append(template.Members, ethernetip.TemplateMember{
+				Name: m.Name, Type: codec.TypeBool, Info: uint16(m.BitNumber), Offset: uint32(host.Offset)})
+			continue
+		}
+		field, _ := layout.Field(m.Name)
+		t := types[m.Name]
+		member := ethernetip.TemplateMember{Name: m.Name, Type: t.code, Offset: uint32(field.Offset)}
+		switch {
+		case t.template != nil:
+			member.Type = symbolTypeStructBit | t.template.template.Instance
+		case t.code == codec.TypeBool && m.Dimension > 0:
+			member.Type = typeDword
+			member.Info = uint16(codec.BoolArraySize(m.Dimension) / 4)
+		case field.Bit >= 0:
+			member.Info = uint16(field.Bit)
+		}
+		if m.Dimension > 0 {
+			member.Type |= 1 << symbolTypeDimsShift
+			if member.Info == 0 {
+				member.Info = uint16(m.Dimension)
+			}
+		}
+		template.Members = append(template.Members, member)
+	}
+	return &builtType{template: template, layout: layout}, nil
+}
+
+// addTags adds the tags of a scope (program empty for the controller) and
+// returns them by upper-case name, for aliases in program scopes to resolve
+// against. Aliases are resolved after the base tags.
+func (b *builder) addTags(p *Project, program string, tags []xmlTag, controller map[string]resolvedTag) map[string]resolvedTag {
+	scope := make(map[string]resolvedTag, len(tags))
+	prefix := ""
+	if program != "" {
+		prefix = "Program:" + program + "."
+	}
+	add := func(tag xmlTag, r resolvedTag) {
+		name := prefix + tag.Name
+		scope[strings.ToUpper(tag.Name)] = r
+		p.Tags = append(p.Tags, ethernetip.TagInfo{Name: name, SymbolType: r.symbolType(), Program: program})
+		p.Metadata[name] = r.metadata(program, tag.ExternalAccess)
+	}
+	skip := func(tag xmlTag, err error) {
+		p.Skipped = append(p.Skipped, fmt.Sprintf("%s%s: %v", prefix, tag.Name, err))
+	}
+
+	for _, tag := range tags {
+		if strings.EqualFold(tag.TagType, "Alias") {
+			continue
+		}
+		t, err := b.resolve(tag.DataType)
+		if err != nil {
+			skip(tag, err)
+			continue
+		}
+		dims, err := parseDimensions(tag.Dimensions)
+		if err != nil {
+			skip(tag, err)
+			continue
+		}
+		add(tag, resolvedTag{tagType: t, dims: dims})
+	}
+	for _, tag := range tags {
+		if !strings.EqualFold(tag.TagType, "Alias") {
+			continue
+		}
+		r, err := b.resolveAlias(tag.AliasFor, scope, controller)
+		if err != nil {
+			skip(tag, err)
+			continue
+		}
+		add(tag, r)
+		p.Aliases[prefix+tag.Name] = tag.AliasFor
+	}
+	return scope
+}
+
+// resolvedTag is the type and dimensions of a tag
+type resolvedTag struct {
+	tagType
+	dims []int
+	bit  bool // An alias of a bit of an integer
+}
+
+// symbolType returns the symbol type word of the tag
+func (r resolvedTag) symbolType() uint16 {
+	code := r.code
+	switch {
+	case r.template != nil:
+		code = symbolTypeStructBit | r.template.template.Instance
+	case r.code == codec.TypeBool && len(r.dims) > 0:
+		code = typeDword
+	}
+	return code | uint16(len(r.dims))<<symbolTypeDimsShift
+}
+
+// metadata describes the tag as GetTagMetadata does
+func (r resolvedTag) metadata(program, access string) *ethernetip.TagMetadata {
+	meta := &ethernetip.TagMetadata{
+		DataType:       int(r.code),
+		ArrayDimension: len(r.dims),
+		TypeName:       r.name,
+		ElementSize:    r.size,
+		Program:        program,
+		ExternalAccess: ethernetip.ExternalAccess(access),
+	}
+	if r.template != nil {
+		meta.DataType = int(r.template.template.Instance)
+		meta.Template = r.template.template.Name
+	}
+	if program != "" {
+		meta.Scope = ethernetip.ScopeProgram
+	}
+	if meta.ExternalAccess == "" {
+		meta.ExternalAccess = ethernetip.AccessReadWrite
+	}
+	if len(r.dims) > 0 {
+		meta.Dimensions = r.dims
+		meta.ArraySize = 1
+		for _, d := range r.dims {
+			meta.ArraySize *= d
+		}
+	}
+	return meta
+}
+
+// resolveAlias resolves the target of an alias tag: a tag of the same scope or
+// the controller scope, optionally followed by array indexes, members and a
+// bit number ("Motor.Status.3")
+func (b *builder) resolveAlias(target string, scope, controller map[string]resolvedTag) (resolvedTag, error) {
+	parts := strings.Split(target, ".")
+	base, indexed := strings.CutSuffix(parts[0], "]")
+	if indexed {
+		base = base[:strings.Index(base, "[")]
+	}
+	r, ok := scope[strings.ToUpper(base)]
+	if !ok {
+		r, ok = controller[strings.ToUpper(base)]
+	}
+	if !ok {
+		return resolvedTag{}, fmt.Errorf("alias target %s is not a tag of the project", target)
+	}
+	if indexed {
+		r.dims = nil
+	}
+
+	for _, part := range parts[1:] {
+		if _, err := strconv.Atoi(part); err == nil && r.template == nil && r.code != 0 && !r.bit {
+			r = resolvedTag{tagType: tagType{code: codec.TypeBool, size: 1, name: "BOOL"}, bit: true}
+			continue
+		}
+		if r.template == nil || r.dims != nil {
+			return resolvedTag{}, fmt.Errorf("cannot resolve %s in alias target %s", part, target)
+		}
+		name, indexed := strings.CutSuffix(part, "]")
+		if indexed {
+			name = name[:strings.Index(name, "[")]
+		}
+		member, ok := findMember(r.template.template, name)
+		if !ok {
+			return resolvedTag{}, fmt.Errorf("%s has no member %s", r.template.template.Name, name)
+		}
+		next, err := b.memberType(member)
+		if err != nil {
+			return resolvedTag{}, err
+		}
+		if member.IsArray() && !indexed {
+			next.dims = []int{int(member.Info)}
+			if member.TypeCode() == typeDword {
+				next.dims[0] *= 32
+			}
+		}
+		r = next
+	}
+	return r, nil
+}
+
+// memberType returns the type of a template member
+func (b *builder) memberType(m ethernetip.TemplateMember) (resolvedTag, error) {
+	code := m.TypeCode()
+	if m.IsStructure() {
+		for _, t := range b.order {
+			if t.template.Instance == code {
+				return resolvedTag{tagType: tagType{size: t.template.Size, name: t.template.Name, template: t}}, nil
+			}
+		}
+		return resolvedTag{}, fmt.Errorf("unknown template %d", code)
+	}
+	if code == typeDword {
+		return resolvedTag{tagType: tagType{code: codec.TypeBool, size: 1, name: "BOOL"}}, nil
+	}
+	size, _ := codec.TypeSize(code)
+	for name, c := range atomicTypes {
+		if c == code {
+			return resolvedTag{tagType: tagType{code: code, size: size, name: name}}, nil
+		}
+	}
+	return resolvedTag{}, fmt.Errorf("unknown type 0x%02X", code)
+}
+
+// findMember returns the member named name, ignoring case
+func findMember(t *ethernetip.StructTemplate, name string) (ethernetip.TemplateMember, bool) {
+	for _, m := range t.Members {
+		if strings.EqualFold(m.Name, name) {
+			return m, true
+		}
+	}
+	return ethernetip.TemplateMember{}, false
+}
+
+// parseDimensions parses a Dimensions attribute such as "10" or "4 3"
+func parseDimensions(s string) ([]int, error) {
+	fields := strings.Fields(s)
+	if len(fields) > 3 {
+		return nil, fmt.Errorf("more than 3 dimensions in %q", s)
+	}
+	var dims []int
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid dimensions %q", s)
+		}
+		if n > 0 {
+			dims = append(dims, n)
+		}
+	}
+	return dims, nil
+}
@@ -0,0 +1,185 @@
+package l5x
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	ethernetip "github.com/sergiogallegos/rust-ethernet-ip/gowrapper"
+)
+
+// readProject reads the test project
+func readProject(t *testing.T) *Project {
+	t.Helper()
+	project, err := ReadFile("testdata/line1.L5X")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return project
+}
+
+// TestReadTags tests the symbol types of the tags, including arrays, aliases
+// and program tags, as DiscoverTagDatabase would list them
+func TestReadTags(t *testing.T) {
+	project := readProject(t)
+	if project.Controller != "Line1" || project.ProcessorType != "1756-L83E" || project.SoftwareRevision != "33.01" {
+		t.Errorf("Unexpected controller %q %q %q", project.Controller, project.ProcessorType, project.SoftwareRevision)
+	}
+
+	db := project.Database()
+	want := map[string]uint16{
+		"Speed":                  0x00C4,
+		"Setpoints":              0x20CA,
+		"Grid":                   0x40C3,
+		"Alarms":                 0x20D3,
+		"Recipe":                 0x8003,
+		"Message":                0x8004,
+		"V101":                   0x8006,
+		"FirstTemp":              0x00CA,
+		"RecipeFlags":            0x20D3,
+		"SpeedBit":               0x00C1,
+		"Program:Main":           0x0068,
+		"Program:Main.Count":     0x00C4,
+		"Program:Main.Delay":     0x8005,
+		"Program:Main.LineSpeed": 0x00C4,
+	}
+	for name, symbolType := range want {
+		info, ok := db.Lookup(name)
+		if !ok {
+			t.Errorf("Tag %s missing", name)
+		} else if info.SymbolType != symbolType {
+			t.Errorf("Expected %s of symbol type 0x%04X, got 0x%04X", name, symbolType, info.SymbolType)
+		}
+	}
+	if db.Len() != len(want) {
+		t.Errorf("Expected %d tags, got %d", len(want), db.Len())
+	}
+	if info, _ := db.Lookup("Program:Main.Count"); info.Program != "Main" {
+		t.Errorf("Expected a program tag, got %+v", info)
+	}
+
+	// Tags of unknown types and aliases of module tags are reported
+	if len(project.Skipped) != 2 || !strings.Contains(project.Skipped[0], "PowerFlex755") || !strings.HasPrefix(project.Skipped[1], "Input:") {
+		t.Errorf("Unexpected skipped tags %q", project.Skipped)
+	}
+}
+
+// TestReadTemplates tests the layout of UDTs, predefined types and Add-On
+// Instructions
+func TestReadTemplates(t *testing.T) {
+	project := readProject(t)
+	names := map[string]*ethernetip.StructTemplate{}
+	for _, template := range project.Templates {
+		names[template.Name] = template
+	}
+
+	recipe := names["RECIPE"]
+	if recipe == nil || recipe.Size != 128 {
+		t.Fatalf("Unexpected RECIPE template %+v", recipe)
+	}
+	members := []ethernetip.TemplateMember{
+		{Name: "ZZZZZZZZZZRECIPE0", Type: 0xC2},
+		{Name: "Running", Type: 0xC1, Info: 0},
+		{Name: "Done", Type: 0xC1, Info: 1},
+		{Name: "Steps", Type: 0xA001, Info: 3, Offset: 4},
+		{Name: "Flags", Type: 0x20D3, Info: 1, Offset: 28},
+		{Name: "Total", Type: 0xCB, Offset: 32},
+		{Name: "Name", Type: 0x8002, Offset: 40},
+	}
+	if !reflect.DeepEqual(recipe.Members, members) {
+		t.Errorf("Expected RECIPE members %+v, got %+v", members, recipe.Members)
+	}
+	if capacity, ok := names["STRING"].StringCapacity(); !ok || capacity != 82 || names["STRING"].Size != 88 {
+		t.Errorf("Unexpected STRING template %+v", names["STRING"])
+	}
+	if capacity, ok := names["STRING20"].StringCapacity(); !ok || capacity != 20 || names["STRING20"].Size != 24 {
+		t.Errorf("Unexpected STRING20 template %+v", names["STRING20"])
+	}
+
+	timer := names["TIMER"]
+	if timer.Size != 12 || timer.Members[3] != (ethernetip.TemplateMember{Name: "EN", Type: 0xC1, Info: 31}) {
+		t.Errorf("Unexpected TIMER template %+v", timer)
+	}
+
+	// BOOL parameters are packed; InOut parameters are references and left out
+	valve := names["Valve"]
+	if valve.Size != 20 || len(valve.Members) != 5 || valve.Members[2] != (ethernetip.TemplateMember{Name: "Open", Type: 0xC1, Info: 2}) ||
+		valve.Members[4].Offset != 8 {
+		t.Errorf("Unexpected Valve template %+v", valve)
+	}
+}
+
+// TestMetadata tests the metadata of arrays, structures, aliases and program
+// tags, with the external access of each
+func TestMetadata(t *testing.T) {
+	project := readProject(t)
+	want := map[string]ethernetip.TagMetadata{
+		"Setpoints": {DataType: 0xCA, ArrayDimension: 1, ArraySize: 10, TypeName: "REAL", ElementSize: 4,
+			Dimensions: []int{10}, ExternalAccess: ethernetip.AccessReadOnly},
+		"Grid": {DataType: 0xC3, ArrayDimension: 2, ArraySize: 12, TypeName: "INT", ElementSize: 2,
+			Dimensions: []int{4, 3}, ExternalAccess: ethernetip.AccessReadWrite},
+		"Alarms": {DataType: 0xC1, ArrayDimension: 1, ArraySize: 64, TypeName: "BOOL", ElementSize: 1,
+			Dimensions: []int{64}, ExternalAccess: ethernetip.AccessReadWrite},
+		"Message": {DataType: 4, TypeName: "STRING20", Template: "STRING20", ElementSize: 24,
+			ExternalAccess: ethernetip.AccessNone},
+		"RecipeFlags": {DataType: 0xC1, ArrayDimension: 1, ArraySize: 32, TypeName: "BOOL", ElementSize: 1,
+			Dimensions: []int{32}, ExternalAccess: ethernetip.AccessReadWrite},
+		"Program:Main.LineSpeed": {DataType: 0xC4, Scope: ethernetip.ScopeProgram, TypeName: "DINT", ElementSize: 4,
+			Program: "Main", ExternalAccess: ethernetip.AccessReadOnly},
+	}
+	for name, meta := range want {
+		if got := project.Metadata[name]; got == nil || !reflect.DeepEqual(*got, meta) {
+			t.Errorf("Expected %s metadata %+v, got %+v", name, meta, got)
+		}
+	}
+	if _, ok := project.Metadata["Program:Main"]; ok {
+		t.Error("Expected no metadata for program entries")
+	}
+}
+
+// TestUdtDefinitionOffline tests looking up a structure type from the export
+// of a project
+func TestUdtDefinitionOffline(t *testing.T) {
+	export := readProject(t).TagExport()
+	def, err := export.UdtDefinition("recipe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range def.Members {
+		names = append(names, m.Name+":"+m.Type)
+	}
+	want := []string{"Running:BOOL", "Done:BOOL", "Steps:STEP", "Flags:0xD3", "Total:LREAL", "Name:STRING"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected members %v, got %v", want, names)
+	}
+	if _, err := export.UdtDefinition("MISSING"); err == nil {
+		t.Error("Expected an error for an unknown type")
+	}
+}
+
+// TestReadInvalid tests that other XML documents are rejected
+func TestReadInvalid(t *testing.T) {
+	for _, doc := range []string{"", "<RSLogix5000Content/>", "<Project><Controller/></Project>"} {
+		if _, err := Read(strings.NewReader(doc)); err == nil {
+			t.Errorf("Expected an error for %q", doc)
+		}
+	}
+}
+
+// TestSeed tests that a seeded client answers from its caches
+func TestSeed(t *testing.T) {
+	client := &ethernetip.EipClient{}
+	readProject(t).Seed(client)
+
+	if def, err := client.GetUdtDefinition("STEP"); err != nil || def.Size != 8 {
+		t.Errorf("Unexpected definition %+v (%v)", def, err)
+	}
+	meta, err := client.GetTagMetadataCached("Setpoints")
+	if err != nil || meta.ArraySize != 10 {
+		t.Fatalf("Unexpected metadata %+v (%v)", meta, err)
+	}
+	if err := client.CheckArrayBounds("Setpoints", 9, 2); err == nil {
+		t.Error("Expected the seeded array size to be checked")
+	}
+}
@@ -0,0 +1,115 @@
+package l5x
+
+import "encoding/xml"
+
+// The subset of the L5X schema the package reads
+
+type xmlContent struct {
+	XMLName          xml.Name
+	SoftwareRevision string         `xml:"SoftwareRevision,attr"`
+	Controller       *xmlController `xml:"Controller"`
+}
+
+type xmlController struct {
+	Name          string        `xml:"Name,attr"`
+	ProcessorType string        `xml:"ProcessorType,attr"`
+	DataTypes     []xmlDataType `xml:"DataTypes>DataType"`
+	AOIs          []xmlAOI      `xml:"AddOnInstructionDefinitions>AddOnInstructionDefinition"`
+	Tags          []xmlTag      `xml:"Tags>Tag"`
+	Programs      []xmlProgram  `xml:"Programs>Program"`
+}
+
+type xmlDataType struct {
+	Name    string      `xml:"Name,attr"`
+	Members []xmlMember `xml:"Members>Member"`
+}
+
+type xmlMember struct {
+	Name      string `xml:"Name,attr"`
+	DataType  string `xml:"DataType,attr"`
+	Dimension int    `xml:"Dimension,attr"`
+	Hidden    bool   `xml:"Hidden,attr"`
+	// Target and BitNumber place a BIT member in its hidden host member
+	Target    string `xml:"Target,attr"`
+	BitNumber int    `xml:"BitNumber,attr"`
+}
+
+type xmlAOI struct {
+	Name       string         `xml:"Name,attr"`
+	Parameters []xmlParameter `xml:"Parameters>Parameter"`
+	LocalTags  []xmlLocalTag  `xml:"LocalTags>LocalTag"`
+}
+
+type xmlParameter struct {
+	Name     string `xml:"Name,attr"`
+	DataType string `xml:"DataType,attr"`
+	Usage    string `xml:"Usage,attr"`
+}
+
+type xmlLocalTag struct {
+	Name       string `xml:"Name,attr"`
+	DataType   string `xml:"DataType,attr"`
+	Dimensions string `xml:"Dimensions,attr"`
+}
+
+type xmlTag struct {
+	Name           string `xml:"Name,attr"`
+	TagType        string `xml:"TagType,attr"`
+	DataType       string `xml:"DataType,attr"`
+	Dimensions     string `xml:"Dimensions,attr"`
+	AliasFor       string `xml:"AliasFor,attr"`
+	ExternalAccess string `xml:"ExternalAccess,attr"`
+}
+
+type xmlProgram struct {
+	Name string   `xml:"Name,attr"`
+	Tags []xmlTag `xml:"Tags>Tag"`
+}
+
+// members returns the members of the structure backing an Add-On
+// Instruction: its parameters, except InOut parameters, which are references
+// to the caller's tags, followed by its local tags
+func (a xmlAOI) members() []xmlMember {
+	var members []xmlMember
+	for _, p := range a.Parameters {
+		if p.Usage != "InOut" {
+			members = append(members, xmlMember{Name: p.Name, DataType: p.DataType})
+		}
+	}
+	for _, t := range a.LocalTags {
+		member := xmlMember{Name: t.Name, DataType: t.DataType}
+		if dims, err := parseDimensions(t.Dimensions); err == nil && len(dims) == 1 {
+			member.Dimension = dims[0]
+		}
+		members = append(members, member)
+	}
+	return members
+}
+
+// predefinedTypes are the controller's predefined structures that projects
+// use without declaring them. The status bits of TIMER, COUNTER and CONTROL
+// are allocated downwards from bit 31 of a hidden DINT.
+var predefinedTypes = map[string][]xmlMember{
+	"STRING": {
+		{Name: "LEN", DataType: "DINT"},
+		{Name: "DATA", DataType: "SINT", Dimension: 82},
+	},
+	"TIMER":   predefined("TIMER", "PRE", "ACC", "EN", "TT", "DN"),
+	"COUNTER": predefined("COUNTER", "PRE", "ACC", "CU", "CD", "DN", "OV", "UN"),
+	"CONTROL": predefined("CONTROL", "LEN", "POS", "EN", "EU", "DN", "EM", "ER", "UL", "IN", "FD"),
+}
+
+// predefined returns the members of a 12-byte predefined structure: a hidden
+// DINT of status bits followed by two DINTs
+func predefined(name, first, second string, bits ...string) []xmlMember {
+	host := "ZZZZZZZZZZ" + name + "0"
+	members := []xmlMember{
+		{Name: host, DataType: "DINT", Hidden: true},
+		{Name: first, DataType: "DINT"},
+		{Name: second, DataType: "DINT"},
+	}
+	for i, bit := range bits {
+		members = append(members, xmlMember{Name: bit, DataType: "BIT", Target: host, BitNumber: 31 - i})
+	}
+	return members
+}
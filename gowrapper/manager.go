@@ -0,0 +1,174 @@
+package ethernetip
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BatchReader reads several tags of one controller at once, with an error
+// for each tag that could not be read. *EipClient implements it.
+type BatchReader interface {
+	ReadTags(tags map[string]PlcDataType) (map[string]*PlcValue, map[string]error)
+}
+
+// ControllerResult is the outcome of a Manager batch on one controller. Err
+// is set when nothing could be read from the controller; otherwise the tags
+// that failed are listed in Errors and the rest are in Values.
+type ControllerResult struct {
+	Values   map[string]*PlcValue `json:"values,omitempty"`
+	Errors   map[string]error     `json:"-"`
+	Err      error                `json:"-"`
+	Duration time.Duration        `json:"duration"`
+}
+
+// Manager holds clients for several controllers by name and runs batches
+// against them concurrently, for overview screens spanning a whole line.
+// A controller that fails or hangs does not affect the results of the others.
+type Manager struct {
+	mu          sync.RWMutex
+	controllers map[string]BatchReader
+}
+
+// NewManager creates an empty manager
+func NewManager() *Manager {
+	return &Manager{controllers: make(map[string]BatchReader)}
+}
+
+// Add registers a controller client under name, replacing any previous one
+func (m *Manager) Add(name string, client BatchReader) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.controllers[name] = client
+}
+
+// Connect connects to the controller at ipAddress and registers it under name
+func (m *Manager) Connect(name, ipAddress string) (*EipClient, error) {
+	client, err := NewClient(ipAddress)
+	if err != nil {
+		return nil, err
+	}
+	m.Add(name, client)
+	return client, nil
+}
+
+// Remove unregisters a controller and returns its client
+func (m *Manager) Remove(name string) (BatchReader, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	client, ok := m.controllers[name]
+	delete(m.controllers, name)
+	return client, ok
+}
+
+// Get returns the client registered under name
+func (m *Manager) Get(name string) (BatchReader, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	client, ok := m.controllers[name]
+	return client, ok
+}
+
+// Names returns the registered controller names in sorted order
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.controllers))
+	for name := range m.controllers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close closes every registered client that has a Close method and removes
+// all controllers
+func (m *Manager) Close() {
+	m.mu.Lock()
+	controllers := m.controllers
+	m.controllers = make(map[string]BatchReader)
+	m.mu.Unlock()
+	for _, client := range controllers {
+		if closer, ok := client.(interface{ Close() error }); ok {
+			closer.Close()
+		}
+	}
+}
+
+// BatchRead reads the given tags of each controller, keyed by controller
+// name, with all controllers read concurrently. Every requested controller
+// gets a result: unknown controllers, unreachable controllers and panics are
+// reported in that controller's Err only, and a tag that fails is reported in
+// Errors without discarding the controller's other values. Controllers still running when ctx is done get
+// ctx.Err() and their late results are discarded.
+func (m *Manager) BatchRead(ctx context.Context, requests map[string][]GroupMember) map[string]ControllerResult {
+	type outcome struct {
+		name   string
+		result ControllerResult
+	}
+	results := make(map[string]ControllerResult, len(requests))
+	done := make(chan outcome, len(requests))
+	pending := 0
+
+	for name, members := range requests {
+		client, ok := m.Get(name)
+		if !ok {
+			results[name] = ControllerResult{Err: NewEipErrorWithDetails(ErrInvalidOperation,
+				fmt.Sprintf("unknown controller '%s'", name), map[string]interface{}{"controller": name})}
+			continue
+		}
+		tags := make(map[string]PlcDataType, len(members))
+		for _, member := range members {
+			tags[member.TagName] = member.DataType
+		}
+		pending++
+		go func(name string, client BatchReader) {
+			done <- outcome{name, readController(client, tags)}
+		}(name, client)
+	}
+
+	for ; pending > 0; pending-- {
+		select {
+		case o := <-done:
+			results[o.name] = o.result
+		case <-ctx.Done():
+			for name := range requests {
+				if _, ok := results[name]; !ok {
+					results[name] = ControllerResult{Err: ctx.Err()}
+				}
+			}
+			return results
+		}
+	}
+	return results
+}
+
+// readController runs one controller's batch, converting a panic into an error
+func readController(client BatchReader, tags map[string]PlcDataType) (result ControllerResult) {
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start)
+		if r := recover(); r != nil {
+			result = ControllerResult{
+				Err:      NewEipError(ErrBatchOperationFailed, fmt.Sprintf("controller batch panicked: %v", r)),
+				Duration: time.Since(start),
+			}
+		}
+	}()
+	values, errs := client.ReadTags(tags)
+	if len(errs) == 0 {
+		return ControllerResult{Values: values}
+	}
+	if len(values) == 0 {
+		// Nothing was read, so report the first failure for the controller
+		names := make([]string, 0, len(errs))
+		for name := range errs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return ControllerResult{Errors: errs, Err: errs[names[0]]}
+	}
+	return ControllerResult{Values: values, Errors: errs}
+}
@@ -0,0 +1,123 @@
+package ethernetip
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeBatchReader is a BatchReader with scripted results
+type fakeBatchReader struct {
+	values map[string]*PlcValue
+	err    error
+	delay  time.Duration
+	panics bool
+}
+
+func (f *fakeBatchReader) ReadTags(tags map[string]PlcDataType) (map[string]*PlcValue, map[string]error) {
+	time.Sleep(f.delay)
+	if f.panics {
+		panic("boom")
+	}
+	values := make(map[string]*PlcValue, len(tags))
+	errs := make(map[string]error)
+	for name := range tags {
+		if value, ok := f.values[name]; ok && f.err == nil {
+			values[name] = value
+		} else if f.err != nil {
+			errs[name] = f.err
+		} else {
+			errs[name] = NewEipError(ErrTagNotFound, "tag not found: "+name)
+		}
+	}
+	return values, errs
+}
+
+// TestManagerBatchReadIsolation tests that one controller's failure does not
+// affect the others
+func TestManagerBatchReadIsolation(t *testing.T) {
+	m := NewManager()
+	m.Add("line1", &fakeBatchReader{values: map[string]*PlcValue{"Count": {Type: Dint, Value: int32(7)}}})
+	m.Add("line2", &fakeBatchReader{err: NewEipError(ErrConnectionFailed, "unreachable")})
+	m.Add("line3", &fakeBatchReader{panics: true})
+
+	members := []GroupMember{{TagName: "Count", DataType: Dint}}
+	results := m.BatchRead(context.Background(), map[string][]GroupMember{
+		"line1": members, "line2": members, "line3": members, "line4": members,
+	})
+
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+	if r := results["line1"]; r.Err != nil || r.Values["Count"].Value != int32(7) {
+		t.Errorf("Unexpected line1 result %+v", r)
+	}
+	for _, name := range []string{"line2", "line3", "line4"} {
+		if results[name].Err == nil {
+			t.Errorf("Expected %s to fail", name)
+		}
+	}
+}
+
+// TestManagerBatchReadPartial tests that a missing tag is reported on its own
+// without discarding the controller's other values
+func TestManagerBatchReadPartial(t *testing.T) {
+	m := NewManager()
+	m.Add("line1", &fakeBatchReader{values: map[string]*PlcValue{"Count": {Type: Dint, Value: int32(7)}}})
+
+	results := m.BatchRead(context.Background(), map[string][]GroupMember{
+		"line1": {{TagName: "Count", DataType: Dint}, {TagName: "Missing", DataType: Dint}},
+	})
+
+	r := results["line1"]
+	if r.Err != nil || r.Values["Count"] == nil || r.Values["Count"].Value != int32(7) {
+		t.Errorf("Expected Count despite the missing tag, got %+v", r)
+	}
+	var eipErr *EipError
+	if len(r.Errors) != 1 || !errors.As(r.Errors["Missing"], &eipErr) || eipErr.Code != ErrTagNotFound {
+		t.Errorf("Expected only Missing to fail, got %v", r.Errors)
+	}
+}
+
+// TestManagerBatchReadContext tests that a hung controller is cut off by ctx
+func TestManagerBatchReadContext(t *testing.T) {
+	m := NewManager()
+	m.Add("fast", &fakeBatchReader{values: map[string]*PlcValue{}})
+	m.Add("slow", &fakeBatchReader{delay: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	results := m.BatchRead(ctx, map[string][]GroupMember{"fast": nil, "slow": nil})
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("Expected BatchRead to return at the deadline")
+	}
+	if results["fast"].Err != nil {
+		t.Errorf("Unexpected fast error %v", results["fast"].Err)
+	}
+	if !errors.Is(results["slow"].Err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error for slow, got %v", results["slow"].Err)
+	}
+}
+
+// TestManagerRegistry tests adding and removing controllers
+func TestManagerRegistry(t *testing.T) {
+	m := NewManager()
+	m.Add("b", &fakeBatchReader{})
+	m.Add("a", &fakeBatchReader{})
+	if names := m.Names(); len(names) != 2 || names[0] != "a" {
+		t.Errorf("Unexpected names %v", names)
+	}
+	if _, ok := m.Remove("a"); !ok {
+		t.Error("Expected a to be removed")
+	}
+	if _, ok := m.Get("a"); ok {
+		t.Error("Expected a to be gone")
+	}
+	m.Close()
+	if len(m.Names()) != 0 {
+		t.Error("Expected Close to remove all controllers")
+	}
+}
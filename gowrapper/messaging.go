@@ -0,0 +1,63 @@
+package ethernetip
+
+/*
+// Messaging mode
+extern int eip_set_messaging_mode(int client_id, int mode);
+*/
+import "C"
+import (
+	"fmt"
+)
+
+// MessagingMode selects how requests reach the controller's Message Router
+type MessagingMode int
+
+const (
+	// MessagingUnconnected sends every request as an unconnected message
+	// through the controller's Unconnected Message Manager (UCMM). It takes
+	// no connection resources, so it works with devices that reject Forward
+	// Open or have used up their connections. This is the default.
+	MessagingUnconnected MessagingMode = iota
+	// MessagingConnected opens a Class 3 connection with Forward Open on the
+	// first request and sends requests over it, which spares a routed
+	// controller from routing each request
+	MessagingConnected
+)
+
+// String returns the name of the mode
+func (m MessagingMode) String() string {
+	switch m {
+	case MessagingUnconnected:
+		return "unconnected"
+	case MessagingConnected:
+		return "connected"
+	default:
+		return fmt.Sprintf("MessagingMode(%d)", int(m))
+	}
+}
+
+// SetMessagingMode selects how requests reach the controller. The mode also
+// applies to the sessions opened later on reconnects and for warm standby; a
+// spare session opened in the old mode is replaced.
+// Switching to MessagingUnconnected closes the Class 3 connection.
+func (c *EipClient) SetMessagingMode(mode MessagingMode) error {
+	if mode != MessagingUnconnected && mode != MessagingConnected {
+		return NewEipError(ErrInvalidOperation, fmt.Sprintf("unknown messaging mode %d", int(mode)))
+	}
+	retCode := int(C.eip_set_messaging_mode(C.int(c.id()), C.int(mode)))
+	if retCode != 0 {
+		return NewEipErrorWithDetails(ErrInvalidOperation, "Failed to set messaging mode",
+			map[string]interface{}{
+				"error_code": retCode,
+				"mode":       mode.String(),
+			})
+	}
+	c.messagingMode.Store(int32(mode))
+	c.resetStandby()
+	return nil
+}
+
+// MessagingMode returns how requests reach the controller
+func (c *EipClient) MessagingMode() MessagingMode {
+	return MessagingMode(c.messagingMode.Load())
+}
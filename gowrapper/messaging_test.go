@@ -0,0 +1,35 @@
+package ethernetip
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestMessagingMode tests mode names and rejected modes
+func TestMessagingMode(t *testing.T) {
+	if MessagingUnconnected.String() != "unconnected" || MessagingConnected.String() != "connected" || MessagingMode(7).String() != "MessagingMode(7)" {
+		t.Error("Unexpected messaging mode names")
+	}
+
+	client := &EipClient{}
+	if client.MessagingMode() != MessagingUnconnected {
+		t.Errorf("Expected unconnected messaging by default, got %v", client.MessagingMode())
+	}
+	var eipErr *EipError
+	if err := client.SetMessagingMode(MessagingMode(7)); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidOperation {
+		t.Errorf("Expected an error for an unknown mode, got %v", err)
+	}
+	// Without a session the native call fails and the mode is kept
+	if err := client.SetMessagingMode(MessagingConnected); err == nil || client.MessagingMode() != MessagingUnconnected {
+		t.Errorf("Expected the mode to be kept after a failed call, got %v, %v", client.MessagingMode(), err)
+	}
+
+	var o clientOptions
+	WithMessagingMode(MessagingConnected)(&o)
+	if o.messagingMode != MessagingConnected {
+		t.Errorf("Expected the option to select connected messaging, got %v", o.messagingMode)
+	}
+	if _, err := NewClientWithOptions("192.0.2.1", WithMessagingMode(MessagingMode(-1))); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidOperation {
+		t.Errorf("Expected an error for an unknown mode option, got %v", err)
+	}
+}
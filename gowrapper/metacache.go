@@ -0,0 +1,225 @@
+package ethernetip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// cipClassChangeDetection is the Logix object whose attributes change when
+// the program is downloaded or edited online
+const cipClassChangeDetection uint16 = 0xAC
+
+// changeDetectionAttributes are the attributes of cipClassChangeDetection
+// instance 1 compared between checks
+var changeDetectionAttributes = []uint16{1, 2, 3, 4, 10}
+
+// MetadataCacheOptions controls how long the tag metadata cache is trusted
+type MetadataCacheOptions struct {
+	// TTL is how long a cached entry is used before the tag is looked up
+	// again; 0 keeps entries until the cache is flushed
+	TTL time.Duration
+	// CheckInterval is how often metadata lookups check whether the program
+	// in the controller changed, at most once per interval. 0 leaves the
+	// check to explicit CheckProgramChange calls.
+	CheckInterval time.Duration
+	// AuditTag is a LINT tag the program copies the controller's audit value
+	// into (GSV Controller AuditValue). When set, it is read to detect
+	// changes instead of the controller's change detection attributes.
+	AuditTag string
+}
+
+// programWatch is the state of program change detection
+type programWatch struct {
+	mu        sync.Mutex
+	opts      MetadataCacheOptions
+	lastCheck time.Time
+	// signature is the last change detection value read, nil before the
+	// first read
+	signature []byte
+	// changes counts the changes detected, the last at lastChange
+	changes    int64
+	lastChange time.Time
+
+	// OnProgramChange listeners and the poll that runs while there are any
+	listeners    map[int]func(ProgramChange)
+	nextListener int
+	stop         chan struct{}
+	wg           sync.WaitGroup
+}
+
+// SetMetadataCacheOptions sets the TTL of the tag metadata cache and how the
+// client detects that the program in the controller changed. When a change
+// is detected, FlushCaches runs and the tag database is dropped, so type and
+// instance information from before a download is not used; run
+// DiscoverTagDatabase again to rebuild it.
+func (c *EipClient) SetMetadataCacheOptions(opts MetadataCacheOptions) {
+	c.programWatch.mu.Lock()
+	defer c.programWatch.mu.Unlock()
+	if opts.AuditTag != c.programWatch.opts.AuditTag {
+		c.programWatch.signature = nil
+	}
+	c.programWatch.opts = opts
+	c.programWatch.lastCheck = time.Time{}
+}
+
+// metadataCacheOptions returns the options set with SetMetadataCacheOptions
+func (c *EipClient) metadataCacheOptions() MetadataCacheOptions {
+	c.programWatch.mu.Lock()
+	defer c.programWatch.mu.Unlock()
+	return c.programWatch.opts
+}
+
+// CheckProgramChange reads the controller's change detection value and
+// reports whether it differs from the one read last, in which case the
+// caches have been invalidated. The first call only records the value.
+func (c *EipClient) CheckProgramChange() (bool, error) {
+	c.programWatch.mu.Lock()
+	auditTag := c.programWatch.opts.AuditTag
+	c.programWatch.lastCheck = c.Clock().Now()
+	c.programWatch.mu.Unlock()
+
+	signature, err := c.programSignature(auditTag)
+	if err != nil {
+		return false, err
+	}
+	return c.noteProgramSignature(signature), nil
+}
+
+// programSignature reads the audit tag, or the change detection attributes
+// when auditTag is empty
+func (c *EipClient) programSignature(auditTag string) ([]byte, error) {
+	if auditTag != "" {
+		value, err := c.readValue(auditTag, Lint)
+		if err != nil {
+			return nil, err
+		}
+		n, ok := value.Value.(int64)
+		if !ok {
+			return nil, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("audit tag %s is not a LINT", auditTag),
+				map[string]interface{}{"tag_name": auditTag})
+		}
+		return binary.LittleEndian.AppendUint64(nil, uint64(n)), nil
+	}
+
+	request := binary.LittleEndian.AppendUint16(nil, uint16(len(changeDetectionAttributes)))
+	for _, attr := range changeDetectionAttributes {
+		request = binary.LittleEndian.AppendUint16(request, attr)
+	}
+	resp, err := c.SendCIPMessage(CIPServiceGetAttributeList, classInstancePath(cipClassChangeDetection, 1), request)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), resp.Data...), nil
+}
+
+// noteProgramSignature records signature and, if it differs from the
+// previous one, invalidates the caches and reports true
+func (c *EipClient) noteProgramSignature(signature []byte) bool {
+	w := &c.programWatch
+	w.mu.Lock()
+	previous := w.signature
+	w.signature = signature
+	if previous == nil || bytes.Equal(previous, signature) {
+		w.mu.Unlock()
+		return false
+	}
+	w.changes++
+	w.lastChange = c.Clock().Now()
+	change := ProgramChange{Time: w.lastChange, Previous: previous, Current: signature, Changes: w.changes}
+	listeners := make([]func(ProgramChange), 0, len(w.listeners))
+	for _, fn := range w.listeners {
+		listeners = append(listeners, fn)
+	}
+	w.mu.Unlock()
+
+	c.FlushCaches()
+	c.tagDB.Store(nil)
+	for _, fn := range listeners {
+		fn(change)
+	}
+	return true
+}
+
+// checkProgramChangeDue runs CheckProgramChange when CheckInterval has passed
+// since the last check. Failures leave the caches as they are; the check is
+// retried after the next interval.
+func (c *EipClient) checkProgramChangeDue() {
+	c.programWatch.mu.Lock()
+	interval := c.programWatch.opts.CheckInterval
+	due := interval > 0 && c.Clock().Now().Sub(c.programWatch.lastCheck) >= interval
+	c.programWatch.mu.Unlock()
+	if due {
+		c.CheckProgramChange()
+	}
+}
+
+// cachedTagMetadata returns the cached metadata under key unless it is older
+// than the TTL. The caller holds tagCacheMu.
+func (c *EipClient) cachedTagMetadata(key string, ttl time.Duration) (*TagMetadata, bool) {
+	meta, ok := c.tagCache[key]
+	if !ok {
+		return nil, false
+	}
+	if added, ok := c.tagCacheAdded[key]; ok && ttl > 0 && c.Clock().Now().Sub(added) >= ttl {
+		return nil, false
+	}
+	return meta, true
+}
+
+// cacheTagMetadata stores meta under key. The caller holds tagCacheMu for
+// writing.
+func (c *EipClient) cacheTagMetadata(key string, meta *TagMetadata) {
+	if c.tagCache == nil {
+		c.tagCache = make(map[string]*TagMetadata)
+	}
+	if c.tagCacheAdded == nil {
+		c.tagCacheAdded = make(map[string]time.Time)
+	}
+	c.tagCache[key] = meta
+	c.tagCacheAdded[key] = c.Clock().Now()
+}
+
+// SeedTagMetadata adds metadata obtained elsewhere, such as from a project
+// file, to the cache GetTagMetadataCached reads from, keyed by tag name
+func (c *EipClient) SeedTagMetadata(metas map[string]*TagMetadata) {
+	c.tagCacheMu.Lock()
+	defer c.tagCacheMu.Unlock()
+	for tag, meta := range metas {
+		c.cacheTagMetadata(c.tagNames.Key(tag), meta)
+	}
+}
+
+// Tag metadata cache: get with cache. Entries older than the TTL set with
+// SetMetadataCacheOptions are looked up again, and the cache is flushed when
+// the program in the controller changed.
+func (c *EipClient) GetTagMetadataCached(tagName string) (*TagMetadata, error) {
+	c.checkProgramChangeDue()
+	tagName = c.ResolveAlias(tagName)
+	ttl := c.metadataCacheOptions().TTL
+	c.tagCacheMu.RLock()
+	names := c.tagNames
+	key := names.Key(tagName)
+	if meta, ok := c.cachedTagMetadata(key, ttl); ok {
+		c.tagCacheMu.RUnlock()
+		return meta, nil
+	}
+	c.tagCacheMu.RUnlock()
+	meta, err := c.getTagMetadata(names.Clean(tagName))
+	if err == nil {
+		c.tagCacheMu.Lock()
+		c.cacheTagMetadata(key, meta)
+		c.tagCacheMu.Unlock()
+	}
+	return meta, err
+}
+
+// ClearTagCache clears the tag metadata cache
+func (c *EipClient) ClearTagCache() {
+	c.tagCacheMu.Lock()
+	c.tagCache = make(map[string]*TagMetadata)
+	c.tagCacheAdded = nil
+	c.tagCacheMu.Unlock()
+}
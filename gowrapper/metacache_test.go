@@ -0,0 +1,79 @@
+package ethernetip
+
+import (
+	"testing"
+	"time"
+)
+
+// TestMetadataCacheTTL tests that cached metadata expires after the TTL
+func TestMetadataCacheTTL(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	client := &EipClient{}
+	client.SetClock(clock)
+	client.cacheTagMetadata("Speed", &TagMetadata{DataType: int(CIPTypeDint)})
+
+	if _, ok := client.cachedTagMetadata("Speed", time.Minute); !ok {
+		t.Fatal("Expected a fresh entry to be cached")
+	}
+	clock.Advance(2 * time.Minute)
+	if _, ok := client.cachedTagMetadata("Speed", time.Minute); ok {
+		t.Error("Expected the entry to expire after the TTL")
+	}
+	if _, ok := client.cachedTagMetadata("Speed", 0); !ok {
+		t.Error("Expected entries to be kept without a TTL")
+	}
+
+	client.ClearTagCache()
+	if _, ok := client.cachedTagMetadata("Speed", 0); ok {
+		t.Error("Expected the cache to be cleared")
+	}
+}
+
+// TestProgramChange tests that a changed signature flushes the caches and
+// drops the tag database, and that checks run at most once per interval
+func TestProgramChange(t *testing.T) {
+	client := browseClient()
+	client.cacheTagMetadata("Speed", &TagMetadata{DataType: int(CIPTypeDint)})
+
+	if client.noteProgramSignature([]byte{1, 0, 0, 0}) {
+		t.Error("Expected the first signature to be recorded only")
+	}
+	if client.noteProgramSignature([]byte{1, 0, 0, 0}) {
+		t.Error("Expected an unchanged signature to keep the caches")
+	}
+	if _, ok := client.CacheContents().TagMetadata["Speed"]; !ok || client.TagDatabase() == nil {
+		t.Fatal("Expected the caches to be kept")
+	}
+	if !client.noteProgramSignature([]byte{2, 0, 0, 0}) {
+		t.Error("Expected a changed signature to be reported")
+	}
+	if contents := client.CacheContents(); len(contents.TagMetadata) != 0 || len(contents.Templates) != 0 {
+		t.Errorf("Expected the caches to be flushed, got %+v", contents)
+	}
+	if client.TagDatabase() != nil {
+		t.Error("Expected the tag database to be dropped")
+	}
+
+	// Without a controller the check fails and the caches stay as they are
+	clock := NewFakeClock(time.Now())
+	client.SetClock(clock)
+	client.SetMetadataCacheOptions(MetadataCacheOptions{CheckInterval: time.Minute})
+	client.checkProgramChangeDue()
+	if checked := client.programWatch.lastCheck; !checked.Equal(clock.Now()) {
+		t.Errorf("Expected a check at %v, got %v", clock.Now(), checked)
+	}
+	clock.Advance(30 * time.Second)
+	client.checkProgramChangeDue()
+	if client.programWatch.lastCheck.Equal(clock.Now()) {
+		t.Error("Expected no check before the interval passed")
+	}
+	if client.programWatch.signature == nil {
+		t.Error("Expected a failed check to keep the last signature")
+	}
+
+	// A new audit tag starts from a new signature
+	client.SetMetadataCacheOptions(MetadataCacheOptions{AuditTag: "PlcAudit"})
+	if client.programWatch.signature != nil {
+		t.Error("Expected the signature to be reset for a new audit tag")
+	}
+}
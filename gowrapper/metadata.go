@@ -0,0 +1,391 @@
+package ethernetip
+
+/*
+#include <stdlib.h>
+
+// Tag management
+extern int eip_discover_tags(int client_id);
+extern int eip_get_tag_metadata_json(int client_id, const char* tag_name, char* result, int capacity);
+*/
+import "C"
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"unsafe"
+)
+
+// tagMetadataBufferSize is the initial buffer for the metadata JSON, and
+// maxTagMetadataSize the most GetTagMetadata grows it to
+const (
+	tagMetadataBufferSize = 512
+	maxTagMetadataSize    = 64 * 1024
+)
+
+// Symbol Object attributes read by GetTagMetadataBulk
+const (
+	symbolAttrType        = 2
+	symbolAttrElementSize = 7  // Size of one element in bytes
+	symbolAttrDimensions  = 8  // Array dimension sizes, three UDINTs
+	symbolAttrAccess      = 10 // External Access, one USINT
+)
+
+// External Access values of the Symbol Object
+const (
+	symbolAccessReadWrite = 0
+	symbolAccessReadOnly  = 2
+	symbolAccessNone      = 3
+)
+
+// metadataPerPacket bounds the Get Attribute List requests packed into one
+// Multiple Service Packet, so that the replies of about 41 bytes each fit in
+// an unconnected message
+const metadataPerPacket = 10
+
+// nativeTagMetadata is the JSON object written by eip_get_tag_metadata_json
+type nativeTagMetadata struct {
+	DataType   uint16 `json:"data_type"`
+	Size       int    `json:"size"`
+	Dimensions []int  `json:"dimensions"`
+	Readable   bool   `json:"readable"`
+	Writable   bool   `json:"writable"`
+	Scope      string `json:"scope"`
+	Program    string `json:"program"`
+}
+
+// parseTagMetadata converts the metadata JSON of the native library. The
+// library lists a 0 for each dimension whose size it does not know; the
+// dimension count is kept but Dimensions and ArraySize are then left empty.
+func parseTagMetadata(tagName string, data []byte) (*TagMetadata, error) {
+	var native nativeTagMetadata
+	if err := json.Unmarshal(data, &native); err != nil {
+		return nil, NewEipErrorWithDetails(ErrInvalidTagMetadata, fmt.Sprintf("invalid metadata for tag %s: %v", tagName, err),
+			map[string]interface{}{"tag_name": tagName})
+	}
+
+	meta := &TagMetadata{
+		DataType:       int(native.DataType),
+		ArrayDimension: len(native.Dimensions),
+		Program:        native.Program,
+	}
+	switch native.Scope {
+	case "program":
+		meta.Scope = ScopeProgram
+	case "local":
+		meta.Scope = ScopeLocal
+	}
+	switch {
+	case native.Readable && native.Writable:
+		meta.ExternalAccess = AccessReadWrite
+	case native.Readable:
+		meta.ExternalAccess = AccessReadOnly
+	default:
+		meta.ExternalAccess = AccessNone
+	}
+	setDimensions(meta, native.Dimensions)
+	if native.Size > 0 && meta.ArraySize > 0 && native.Size%meta.ArraySize == 0 {
+		meta.ElementSize = native.Size / meta.ArraySize
+	} else if meta.ArrayDimension == 0 {
+		meta.ElementSize = native.Size
+	}
+	return meta, nil
+}
+
+// setDimensions sets Dimensions and ArraySize when every dimension is known
+func setDimensions(meta *TagMetadata, dims []int) {
+	total := 1
+	for _, d := range dims {
+		if d <= 0 {
+			return
+		}
+		total *= d
+	}
+	if len(dims) > 0 {
+		meta.Dimensions = append([]int(nil), dims...)
+		meta.ArraySize = total
+	}
+}
+
+// describeTag fills in the type name, structure template and element size of
+// meta. The native library reports the type code without the structure bit,
+// so the tag database is consulted first when it knows the tag; structure
+// templates are read (and cached) through GetTemplate. What cannot be
+// resolved is left empty rather than failing the lookup.
+func (c *EipClient) describeTag(tagName string, meta *TagMetadata) {
+	code, structure := uint16(meta.DataType), false
+	if info, ok := c.TagDatabase().Lookup(tagName); ok {
+		code, structure = info.TypeCode(), info.IsStructure()
+		meta.DataType = int(code)
+		if meta.ArrayDimension == 0 {
+			meta.ArrayDimension = info.Dimensions()
+		}
+		if info.Program != "" {
+			meta.Scope, meta.Program = ScopeProgram, info.Program
+		}
+	}
+
+	if !structure {
+		if dataType, ok := atomicDataType(code); ok {
+			_, size, _ := cipTypeInfo(dataType)
+			meta.TypeName, meta.ElementSize = dataType.String(), size
+			return
+		}
+	}
+
+	var template *StructTemplate
+	if structure {
+		template, _ = c.GetTemplate(code)
+	} else if cached, ok := c.templates.Load(code); ok {
+		template = cached.(*StructTemplate)
+	}
+	if template != nil {
+		meta.TypeName, meta.Template, meta.ElementSize = template.Name, template.Name, template.Size
+	}
+}
+
+// GetTagMetadataBulk returns the metadata of many tags, keyed by the names
+// given, and adds it to the cache GetTagMetadataCached reads from. Tags already
+// cached, within the TTL, are not looked up again. Tags in the tag database are described by
+// reading their Symbol Object attributes, ten tags per Multiple Service
+// Packet, and structure templates are read once per type; other tags, such as
+// structure members, fall back to one GetTagMetadata call each. Tags that
+// could not be described are listed in an ErrBatchOperationFailed error,
+// alongside the metadata of the others.
+func (c *EipClient) GetTagMetadataBulk(tags []string) (map[string]*TagMetadata, error) {
+	c.checkProgramChangeDue()
+	ttl := c.metadataCacheOptions().TTL
+	result := make(map[string]*TagMetadata, len(tags))
+	db := c.TagDatabase()
+
+	var symbols, others []string
+	var requests [][]byte
+	c.tagCacheMu.RLock()
+	names := c.tagNames
+	for _, tag := range tags {
+		if _, ok := result[tag]; ok {
+			continue
+		}
+		if meta, ok := c.cachedTagMetadata(names.Key(tag), ttl); ok {
+			result[tag] = meta
+			continue
+		}
+		if info, ok := db.Lookup(names.Clean(tag)); ok {
+			symbols = append(symbols, tag)
+			requests = append(requests, symbolAttributeRequest(info))
+		} else {
+			others = append(others, tag)
+		}
+		result[tag] = nil
+	}
+	c.tagCacheMu.RUnlock()
+
+	var failed []string
+	found := make(map[string]*TagMetadata, len(symbols)+len(others))
+	describe := func(tag string, meta *TagMetadata, err error) {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", tag, err))
+			delete(result, tag)
+			return
+		}
+		result[tag], found[tag] = meta, meta
+	}
+	for start := 0; start < len(requests); start += metadataPerPacket {
+		end := min(start+metadataPerPacket, len(requests))
+		replies, err := c.sendServicePacket(requests[start:end])
+		for i, tag := range symbols[start:end] {
+			if err != nil {
+				describe(tag, nil, err)
+				continue
+			}
+			meta, err := c.symbolMetadata(names.Clean(tag), replies[i])
+			describe(tag, meta, err)
+		}
+	}
+	for _, tag := range others {
+		meta, err := c.getTagMetadata(names.Clean(tag))
+		describe(tag, meta, err)
+	}
+
+	c.tagCacheMu.Lock()
+	for tag, meta := range found {
+		c.cacheTagMetadata(names.Key(tag), meta)
+	}
+	c.tagCacheMu.Unlock()
+	return result, bindingError("metadata lookup", failed)
+}
+
+// symbolAttributeRequest encodes a Get Attribute List request for the type,
+// element size, dimensions and external access of the Symbol Object instance
+// of tag
+func symbolAttributeRequest(tag TagInfo) []byte {
+	var path []byte
+	if tag.Program != "" {
+		path = symbolicSegment("Program:" + tag.Program)
+	}
+	path = append(path, classInstancePath(CIPClassSymbol, tag.InstanceID)...)
+	request := []byte{CIPServiceGetAttributeList, byte(len(path) / 2)}
+	request = append(request, path...)
+	return append(request, 0x04, 0x00, symbolAttrType, 0x00, symbolAttrElementSize, 0x00, symbolAttrDimensions, 0x00,
+		symbolAttrAccess, 0x00)
+}
+
+// symbolMetadata describes tagName from its Get Attribute List reply
+func (c *EipClient) symbolMetadata(tagName string, reply *CIPResponse) (*TagMetadata, error) {
+	if reply.GeneralStatus != CIPStatusSuccess {
+		return nil, cipStatusError(CIPServiceGetAttributeList, reply)
+	}
+	meta, err := parseSymbolAttributes(reply.Data)
+	if err != nil {
+		return nil, err
+	}
+	c.describeTag(tagName, meta)
+	return meta, nil
+}
+
+// parseSymbolAttributes decodes a Get Attribute List reply for the symbol
+// attributes: [count UINT] then [id UINT][status UINT][value] per attribute.
+// The symbol type is required; the element size, dimensions and external
+// access are used when the controller supports them.
+func parseSymbolAttributes(data []byte) (*TagMetadata, error) {
+	if len(data) < 2 {
+		return nil, NewEipError(ErrInvalidTagMetadata, "symbol attribute reply too short")
+	}
+	meta := &TagMetadata{}
+	var symbolType uint16
+	var dims []int
+	haveType := false
+	count := int(binary.LittleEndian.Uint16(data))
+	offset := 2
+	for i := 0; i < count; i++ {
+		if offset+4 > len(data) {
+			return nil, NewEipError(ErrInvalidTagMetadata, "symbol attribute reply truncated")
+		}
+		id := binary.LittleEndian.Uint16(data[offset:])
+		status := binary.LittleEndian.Uint16(data[offset+2:])
+		offset += 4
+		if status != 0 {
+			continue
+		}
+		size := 2
+		switch id {
+		case symbolAttrDimensions:
+			size = 12
+		case symbolAttrAccess:
+			size = 1
+		}
+		if offset+size > len(data) {
+			return nil, NewEipError(ErrInvalidTagMetadata, "symbol attribute reply truncated")
+		}
+		switch id {
+		case symbolAttrType:
+			symbolType, haveType = binary.LittleEndian.Uint16(data[offset:]), true
+		case symbolAttrElementSize:
+			meta.ElementSize = int(binary.LittleEndian.Uint16(data[offset:]))
+		case symbolAttrDimensions:
+			for j := 0; j < 3; j++ {
+				dims = append(dims, int(binary.LittleEndian.Uint32(data[offset+4*j:])))
+			}
+		case symbolAttrAccess:
+			meta.ExternalAccess = symbolExternalAccess(data[offset])
+		}
+		offset += size
+	}
+	if !haveType {
+		return nil, NewEipError(ErrInvalidTagMetadata, "symbol type not available")
+	}
+
+	info := TagInfo{SymbolType: symbolType}
+	meta.DataType = int(info.TypeCode())
+	meta.ArrayDimension = info.Dimensions()
+	if dims != nil {
+		setDimensions(meta, dims[:meta.ArrayDimension])
+	}
+	return meta, nil
+}
+
+// symbolExternalAccess converts the External Access attribute of a symbol;
+// reserved values are left unknown
+func symbolExternalAccess(value byte) ExternalAccess {
+	switch value {
+	case symbolAccessReadWrite:
+		return AccessReadWrite
+	case symbolAccessReadOnly:
+		return AccessReadOnly
+	case symbolAccessNone:
+		return AccessNone
+	}
+	return ""
+}
+
+// sendServicePacket sends requests in one Multiple Service Packet and returns
+// the embedded replies, whose status the caller checks
+func (c *EipClient) sendServicePacket(requests [][]byte) ([]*CIPResponse, error) {
+	resp, err := c.SendCIPMessage(CIPServiceMultipleServicePacket,
+		classInstancePath(CIPClassMessageRouter, 1), buildMultipleServicePacket(requests))
+	if err != nil && (resp == nil || resp.GeneralStatus != CIPStatusEmbeddedService) {
+		return nil, err
+	}
+	replies, err := parseMultipleServiceReply(resp.Data)
+	if err == nil && len(replies) != len(requests) {
+		err = NewEipErrorWithDetails(ErrInvalidOperation, "reply count mismatch",
+			map[string]interface{}{"expected": len(requests), "actual": len(replies)})
+	}
+	return replies, err
+}
+
+// DiscoverTags discovers all tags in the PLC
+func (c *EipClient) DiscoverTags() error {
+	c.beginUse()
+	defer c.endUse()
+	retCode := int(C.eip_discover_tags(C.int(c.id())))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: "Failed to discover tags from PLC",
+		}
+	}
+	return nil
+}
+
+// GetTagMetadata gets metadata for a specific tag. The native library reports
+// it as JSON, which is completed from the tag database and structure templates
+// when DiscoverTagDatabase has run: the type and template names, the element
+// size, the array dimensions and the tag's external access rights. An alias
+// describes the tag it stands for.
+func (c *EipClient) GetTagMetadata(tagName string) (*TagMetadata, error) {
+	return c.getTagMetadata(c.ResolveAlias(tagName))
+}
+
+// getTagMetadata is GetTagMetadata for a tag name that is not an alias
+func (c *EipClient) getTagMetadata(tagName string) (*TagMetadata, error) {
+	c.beginUse()
+	defer c.endUse()
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	size := tagMetadataBufferSize
+	for {
+		cResult := C.malloc(C.size_t(size))
+		retCode := int(C.eip_get_tag_metadata_json(C.int(c.id()), cTagName, (*C.char)(cResult), C.int(size)))
+		if retCode == 0 {
+			data := C.GoString((*C.char)(cResult))
+			C.free(cResult)
+			meta, err := parseTagMetadata(tagName, []byte(data))
+			if err != nil {
+				return nil, err
+			}
+			c.describeTag(tagName, meta)
+			return meta, nil
+		}
+		C.free(cResult)
+
+		next, ok := growStringBuffer(size, maxTagMetadataSize)
+		if retCode != stringBufferTooSmall || !ok {
+			return nil, &EipError{
+				Code:    retCode,
+				Message: fmt.Sprintf("Failed to get metadata for tag %s", tagName),
+			}
+		}
+		size = next
+	}
+}
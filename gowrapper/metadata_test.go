@@ -0,0 +1,142 @@
+package ethernetip
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseTagMetadata tests converting the metadata JSON of the native library
+func TestParseTagMetadata(t *testing.T) {
+	meta, err := parseTagMetadata("Temps", []byte(`{"data_type":202,"size":40,"dimensions":[10],"readable":true,"writable":false,"scope":"program","program":"Main"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &TagMetadata{DataType: 0xCA, Scope: ScopeProgram, ArrayDimension: 1, ArraySize: 10, ElementSize: 4,
+		Dimensions: []int{10}, Program: "Main", ExternalAccess: AccessReadOnly}
+	if !reflect.DeepEqual(meta, want) {
+		t.Errorf("Expected %+v, got %+v", want, meta)
+	}
+	if meta.ExternalAccess.CanWrite() || !meta.ExternalAccess.CanRead() {
+		t.Errorf("Unexpected rights for %q", meta.ExternalAccess)
+	}
+
+	// Dimensions the library does not know are reported as 0
+	meta, err = parseTagMetadata("Grid", []byte(`{"data_type":196,"size":0,"dimensions":[0,0],"readable":true,"writable":true,"scope":"controller","program":""}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ArrayDimension != 2 || meta.ArraySize != 0 || meta.Dimensions != nil || meta.ExternalAccess != AccessReadWrite {
+		t.Errorf("Unexpected metadata %+v", meta)
+	}
+	if err := checkArrayBounds("Grid", meta, 100, 1); err != nil {
+		t.Errorf("Expected arrays of unknown size to be left to the controller, got %v", err)
+	}
+
+	if _, err := parseTagMetadata("Bad", []byte("{")); err == nil {
+		t.Error("Expected an error for malformed JSON")
+	} else if eipErr, ok := err.(*EipError); !ok || eipErr.Code != ErrInvalidTagMetadata {
+		t.Errorf("Expected ErrInvalidTagMetadata, got %v", err)
+	}
+}
+
+// TestDescribeTag tests completing metadata from the tag database and templates
+func TestDescribeTag(t *testing.T) {
+	client := browseClient()
+
+	// The native type code lacks the structure bit; the tag database has it
+	recipe := &TagMetadata{DataType: 0x100}
+	client.describeTag("Recipe", recipe)
+	if recipe.TypeName != "RECIPE" || recipe.Template != "RECIPE" || recipe.DataType != 0x100 {
+		t.Errorf("Unexpected structure metadata %+v", recipe)
+	}
+
+	values := &TagMetadata{DataType: int(CIPTypeDint)}
+	client.describeTag("Values", values)
+	if values.TypeName != "DINT" || values.Template != "" || values.ElementSize != 4 || values.ArrayDimension != 1 {
+		t.Errorf("Unexpected array metadata %+v", values)
+	}
+
+	count := &TagMetadata{DataType: int(CIPTypeDint)}
+	client.describeTag("Program:Main.Count", count)
+	if count.Scope != ScopeProgram || count.Program != "Main" {
+		t.Errorf("Unexpected program tag metadata %+v", count)
+	}
+
+	// Tags missing from the tag database fall back to cached templates
+	step := &TagMetadata{DataType: 0x101}
+	client.describeTag("Unknown", step)
+	if step.Template != "STEP" {
+		t.Errorf("Unexpected metadata %+v", step)
+	}
+	unknown := &TagMetadata{DataType: 0x200}
+	client.describeTag("Unknown", unknown)
+	if unknown.TypeName != "" || unknown.Template != "" {
+		t.Errorf("Expected an unresolved type to be left empty, got %+v", unknown)
+	}
+}
+
+// TestSymbolAttributes tests the Get Attribute List request and reply used by
+// GetTagMetadataBulk
+func TestSymbolAttributes(t *testing.T) {
+	request := symbolAttributeRequest(TagInfo{Name: "Program:Main.Count", InstanceID: 0x1234, Program: "Main"})
+	want := append([]byte{CIPServiceGetAttributeList, 10}, symbolicSegment("Program:Main")...)
+	want = append(want, 0x20, 0x6B, 0x25, 0x00, 0x34, 0x12, 0x04, 0x00, 0x02, 0x00, 0x07, 0x00, 0x08, 0x00, 0x0A, 0x00)
+	if !reflect.DeepEqual(request, want) {
+		t.Errorf("Expected request % X, got % X", want, request)
+	}
+
+	// A two-dimensional, read-only DINT array of 4x3 elements
+	reply := []byte{0x04, 0x00,
+		0x02, 0x00, 0x00, 0x00, 0xC4, 0x40,
+		0x07, 0x00, 0x00, 0x00, 0x04, 0x00,
+		0x08, 0x00, 0x00, 0x00, 4, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0,
+		0x0A, 0x00, 0x00, 0x00, 0x02}
+	meta, err := parseSymbolAttributes(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.DataType != 0xC4 || meta.ArrayDimension != 2 || meta.ArraySize != 12 || !reflect.DeepEqual(meta.Dimensions, []int{4, 3}) || meta.ElementSize != 4 {
+		t.Errorf("Unexpected metadata %+v", meta)
+	}
+	if meta.ExternalAccess != AccessReadOnly {
+		t.Errorf("Expected Read Only access, got %q", meta.ExternalAccess)
+	}
+	for value, want := range map[byte]ExternalAccess{0: AccessReadWrite, 1: "", 3: AccessNone} {
+		if got := symbolExternalAccess(value); got != want {
+			t.Errorf("symbolExternalAccess(%d) = %q, want %q", value, got, want)
+		}
+	}
+
+	// Attributes the controller does not support are skipped, but the type is required
+	meta, err = parseSymbolAttributes([]byte{0x02, 0x00, 0x02, 0x00, 0x00, 0x00, 0xCA, 0x00, 0x07, 0x00, 0x14, 0x00})
+	if err != nil || meta.DataType != 0xCA || meta.ElementSize != 0 || meta.ExternalAccess != "" {
+		t.Errorf("Unexpected metadata %+v (%v)", meta, err)
+	}
+	if _, err := parseSymbolAttributes([]byte{0x01, 0x00, 0x02, 0x00, 0x05, 0x00}); err == nil {
+		t.Error("Expected an error without the symbol type")
+	}
+	if _, err := parseSymbolAttributes(reply[:len(reply)-1]); err == nil {
+		t.Error("Expected an error for a truncated reply")
+	}
+}
+
+// TestGetTagMetadataBulk tests that cached tags are returned without requests
+// and that failed lookups are reported alongside them
+func TestGetTagMetadataBulk(t *testing.T) {
+	client := browseClient()
+	client.tagCache["Speed"] = &TagMetadata{DataType: int(CIPTypeDint), TypeName: "DINT"}
+
+	result, err := client.GetTagMetadataBulk([]string{"Speed", "Speed", "Recipe", "Recipe.Setpoint"})
+	if eipErr, ok := err.(*EipError); !ok || eipErr.Code != ErrBatchOperationFailed {
+		t.Fatalf("Expected ErrBatchOperationFailed without a controller, got %v", err)
+	}
+	if failed := err.(*EipError).Details["failed_items"].([]string); len(failed) != 2 {
+		t.Errorf("Expected two failed tags, got %v", failed)
+	}
+	if len(result) != 1 || result["Speed"].TypeName != "DINT" {
+		t.Errorf("Expected only the cached tag, got %v", result)
+	}
+	if _, ok := client.tagCache["Recipe"]; ok {
+		t.Error("Expected failed lookups to stay out of the cache")
+	}
+}
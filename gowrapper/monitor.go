@@ -0,0 +1,208 @@
+package ethernetip
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ControllerEventType identifies a controller status transition
+type ControllerEventType string
+
+const (
+	// EventModeChanged is sent when the controller mode changes (e.g. RUN to PROGRAM)
+	EventModeChanged ControllerEventType = "mode_changed"
+	// EventFaulted is sent when the controller reports a major fault
+	EventFaulted ControllerEventType = "faulted"
+	// EventFaultCleared is sent when a major fault is cleared
+	EventFaultCleared ControllerEventType = "fault_cleared"
+	// EventStatusUnavailable is sent when the status cannot be read after it
+	// previously could; EventStatusRestored when it can be read again
+	EventStatusUnavailable ControllerEventType = "status_unavailable"
+	EventStatusRestored    ControllerEventType = "status_restored"
+)
+
+// ControllerEvent is a controller status transition observed by a ControllerMonitor
+type ControllerEvent struct {
+	Type     ControllerEventType `json:"type"`
+	Previous ControllerStatus    `json:"previous"`
+	Current  ControllerStatus    `json:"current"`
+	Time     time.Time           `json:"time"`
+	Err      error               `json:"-"`
+}
+
+// StatusReader reads a controller's status. *EipClient implements it.
+type StatusReader interface {
+	ReadControllerStatus() (ControllerStatus, error)
+}
+
+// ControllerMonitor polls a controller's status and emits typed events on mode
+// changes and fault transitions
+type ControllerMonitor struct {
+	reader   StatusReader
+	interval time.Duration
+	clock    Clock
+
+	mu          sync.Mutex
+	status      ControllerStatus
+	known       bool // status has been read at least once
+	unavailable bool
+	subscribers map[int]chan ControllerEvent
+	nextID      int
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewControllerMonitor starts polling reader's status every interval, on the
+// reader's clock if it has one (see EipClient.SetClock)
+func NewControllerMonitor(reader StatusReader, interval time.Duration) *ControllerMonitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &ControllerMonitor{
+		reader:      reader,
+		interval:    interval,
+		clock:       clockOf(reader),
+		subscribers: make(map[int]chan ControllerEvent),
+		cancel:      cancel,
+	}
+	m.wg.Add(1)
+	go m.run(ctx)
+	return m
+}
+
+// MonitorController starts a ControllerMonitor for this client
+func (c *EipClient) MonitorController(interval time.Duration) *ControllerMonitor {
+	return NewControllerMonitor(c, interval)
+}
+
+// Subscribe returns a channel receiving controller events and a function to
+// unsubscribe. Events are dropped for a subscriber whose buffer is full, so a
+// slow consumer cannot stall the monitor.
+func (m *ControllerMonitor) Subscribe(buffer int) (<-chan ControllerEvent, func()) {
+	ch := make(chan ControllerEvent, buffer)
+	m.mu.Lock()
+	id := m.nextID
+	m.nextID++
+	m.subscribers[id] = ch
+	m.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			m.mu.Lock()
+			if _, ok := m.subscribers[id]; ok {
+				delete(m.subscribers, id)
+				close(ch)
+			}
+			m.mu.Unlock()
+		})
+	}
+}
+
+// Status returns the last status read and whether one has been read yet
+func (m *ControllerMonitor) Status() (ControllerStatus, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status, m.known && !m.unavailable
+}
+
+// Close stops polling and closes all subscriber channels
+func (m *ControllerMonitor) Close() {
+	m.cancel()
+	m.wg.Wait()
+	m.mu.Lock()
+	for id, ch := range m.subscribers {
+		delete(m.subscribers, id)
+		close(ch)
+	}
+	m.mu.Unlock()
+}
+
+// run polls the status until ctx is cancelled
+func (m *ControllerMonitor) run(ctx context.Context) {
+	defer m.wg.Done()
+	ticker := m.clock.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.poll()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// poll reads the status once and publishes any transitions
+func (m *ControllerMonitor) poll() {
+	status, err := m.reader.ReadControllerStatus()
+	now := m.clock.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err != nil {
+		if m.known && !m.unavailable {
+			m.unavailable = true
+			m.publish(ControllerEvent{Type: EventStatusUnavailable, Previous: m.status, Current: m.status, Time: now, Err: err})
+		}
+		return
+	}
+
+	prev, hadPrev := m.status, m.known
+	if m.unavailable {
+		m.unavailable = false
+		m.publish(ControllerEvent{Type: EventStatusRestored, Previous: prev, Current: status, Time: now})
+	}
+	m.status, m.known = status, true
+	if !hadPrev {
+		if status.Faulted() {
+			m.publish(ControllerEvent{Type: EventFaulted, Previous: prev, Current: status, Time: now})
+		}
+		return
+	}
+
+	if status.Mode != prev.Mode {
+		m.publish(ControllerEvent{Type: EventModeChanged, Previous: prev, Current: status, Time: now})
+	}
+	switch {
+	case status.Faulted() && !prev.Faulted():
+		m.publish(ControllerEvent{Type: EventFaulted, Previous: prev, Current: status, Time: now})
+	case !status.Faulted() && prev.Faulted():
+		m.publish(ControllerEvent{Type: EventFaultCleared, Previous: prev, Current: status, Time: now})
+	}
+}
+
+// publish delivers an event to every subscriber without blocking. Callers hold m.mu.
+func (m *ControllerMonitor) publish(event ControllerEvent) {
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// SuspendWritesUnlessRunning returns an interceptor that rejects writes while
+// the monitored controller is not in RUN mode, is faulted, or its status is
+// unknown. Reads pass through.
+func SuspendWritesUnlessRunning(m *ControllerMonitor) Interceptor {
+	return func(ctx context.Context, op *Operation, next func(ctx context.Context, op *Operation) error) error {
+		if op.Kind != OperationWrite {
+			return next(ctx, op)
+		}
+		status, ok := m.Status()
+		if !ok || status.Mode != ModeRun || status.Faulted() {
+			return NewEipErrorWithDetails(ErrInvalidOperation,
+				fmt.Sprintf("writes suspended: controller is %s", status),
+				map[string]interface{}{
+					"tag_name":     op.TagName,
+					"status_known": ok,
+					"controller":   status.Mode.String(),
+					"status_word":  status.Raw,
+				})
+		}
+		return next(ctx, op)
+	}
+}
@@ -0,0 +1,115 @@
+package ethernetip
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStatusReader returns a settable status
+type fakeStatusReader struct {
+	mu   sync.Mutex
+	word uint16
+	err  error
+}
+
+func (f *fakeStatusReader) ReadControllerStatus() (ControllerStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return ControllerStatus{}, f.err
+	}
+	return ParseControllerStatus(f.word), nil
+}
+
+func (f *fakeStatusReader) set(word uint16, err error) {
+	f.mu.Lock()
+	f.word, f.err = word, err
+	f.mu.Unlock()
+}
+
+// waitFor polls cond until it holds
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// nextEvent waits for an event of the given type
+func nextEvent(t *testing.T, events <-chan ControllerEvent, want ControllerEventType) ControllerEvent {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type == want {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s event", want)
+			return ControllerEvent{}
+		}
+	}
+}
+
+// TestControllerMonitorEvents tests mode, fault and availability transitions
+func TestControllerMonitorEvents(t *testing.T) {
+	reader := &fakeStatusReader{word: 0x3060}
+	monitor := NewControllerMonitor(reader, 5*time.Millisecond)
+	defer monitor.Close()
+	events, unsubscribe := monitor.Subscribe(16)
+	defer unsubscribe()
+
+	waitFor(t, func() bool { _, ok := monitor.Status(); return ok })
+
+	reader.set(0x3070, nil)
+	event := nextEvent(t, events, EventModeChanged)
+	if event.Previous.Mode != ModeRun || event.Current.Mode != ModeProgram {
+		t.Errorf("unexpected transition: %s -> %s", event.Previous, event.Current)
+	}
+
+	reader.set(0x3450, nil)
+	nextEvent(t, events, EventFaulted)
+	reader.set(0x3060, nil)
+	nextEvent(t, events, EventFaultCleared)
+
+	reader.set(0, errors.New("connection lost"))
+	if event := nextEvent(t, events, EventStatusUnavailable); event.Err == nil {
+		t.Error("expected error on unavailable event")
+	}
+	reader.set(0x3060, nil)
+	nextEvent(t, events, EventStatusRestored)
+}
+
+// TestSuspendWritesUnlessRunning tests that writes are rejected outside RUN mode
+func TestSuspendWritesUnlessRunning(t *testing.T) {
+	reader := &fakeStatusReader{word: 0x3070}
+	monitor := NewControllerMonitor(reader, 5*time.Millisecond)
+	defer monitor.Close()
+	waitFor(t, func() bool { _, ok := monitor.Status(); return ok })
+
+	interceptor := SuspendWritesUnlessRunning(monitor)
+	called := false
+	next := func(ctx context.Context, op *Operation) error { called = true; return nil }
+
+	if err := interceptor(context.Background(), &Operation{Kind: OperationWrite, TagName: "Setpoint"}, next); err == nil || called {
+		t.Error("expected write to be suspended in PROGRAM mode")
+	}
+	if err := interceptor(context.Background(), &Operation{Kind: OperationRead, TagName: "Setpoint"}, next); err != nil || !called {
+		t.Error("expected read to pass through")
+	}
+
+	reader.set(0x3060, nil)
+	waitFor(t, func() bool { s, _ := monitor.Status(); return s.Mode == ModeRun })
+	called = false
+	if err := interceptor(context.Background(), &Operation{Kind: OperationWrite, TagName: "Setpoint"}, next); err != nil || !called {
+		t.Errorf("expected write to pass in RUN mode, got %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package ethernetip
+
+import "github.com/sergiogallegos/rust-ethernet-ip/gowrapper/tagpath"
+
+// TagNameOptions controls how tag names are matched by the client's caches,
+// subscriptions and type map. The zero value matches names exactly.
+type TagNameOptions = tagpath.NameOptions
+
+// LogixTagNames matches names the way a Logix controller resolves them
+var LogixTagNames = tagpath.LogixNames
+
+// SetTagNameOptions sets how the client matches tag names in its metadata
+// cache, type map and subscriptions. With LogixTagNames, subscribing to
+// "Motor1" and "motor1" polls the tag once and both share cached values.
+// Existing subscriptions keep the loop they were created with.
+func (c *EipClient) SetTagNameOptions(opts TagNameOptions) {
+	c.tagCacheMu.Lock()
+	c.tagNames = opts
+	c.tagCache = make(map[string]*TagMetadata)
+	c.tagCacheAdded = nil
+	c.tagCacheMu.Unlock()
+	c.tagTypes.SetNameOptions(opts)
+	c.poller.SetTagNameOptions(opts)
+}
+
+// TagNameOptions returns how the client matches tag names
+func (c *EipClient) TagNameOptions() TagNameOptions {
+	c.tagCacheMu.RLock()
+	defer c.tagCacheMu.RUnlock()
+	return c.tagNames
+}
@@ -0,0 +1,48 @@
+package ethernetip
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTagTypesNameOptions tests case-insensitive type lookups
+func TestTagTypesNameOptions(t *testing.T) {
+	types := NewTagTypes(map[string]PlcDataType{"Speed": Real})
+	if _, ok := types.Lookup("speed"); ok {
+		t.Error("Lookup should be exact by default")
+	}
+	types.SetNameOptions(LogixTagNames)
+	if dataType, ok := types.Lookup(" SPEED "); !ok || dataType != Real {
+		t.Errorf("Expected REAL, got %v %v", dataType, ok)
+	}
+	types.Set("speed", Lreal)
+	if types.Len() != 1 {
+		t.Errorf("Expected 1 entry, got %d", types.Len())
+	}
+}
+
+// TestHubCaseInsensitiveTags tests that a consumer watching two spellings of a
+// tag is subscribed once
+func TestHubCaseInsensitiveTags(t *testing.T) {
+	client := newFakeClient()
+	client.set("Motor1", int32(5))
+	poller := NewPoller(client)
+	defer poller.Close()
+	poller.SetTagNameOptions(LogixTagNames)
+	hub := NewHub(poller, 10*time.Millisecond)
+	defer hub.Close()
+
+	_, err := hub.Subscribe(ConsumerOptions{Tags: []GroupMember{
+		{TagName: "Motor1", DataType: Dint},
+		{TagName: "MOTOR1", DataType: Dint},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hub.TagCount() != 1 {
+		t.Errorf("Expected 1 hub tag, got %d", hub.TagCount())
+	}
+	if poller.SubscriptionCount() != 1 {
+		t.Errorf("Expected 1 subscription, got %d", poller.SubscriptionCount())
+	}
+}
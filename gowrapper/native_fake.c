@@ -0,0 +1,443 @@
+//go:build eipfake
+
+// In-memory stand-in for the native library, built with the eipfake tag so
+// the package's tests can drive the real client code without a PLC or the
+// Rust library:
+//
+//	go test -tags eipfake ./...
+//
+// Tags live in a single table shared by every client. The typed read and
+// write functions and the Read Tag, Write Tag and Multiple Service Packet
+// CIP services are implemented on it; the batch, UDT and discovery functions
+// fail with -1.
+
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <pthread.h>
+
+typedef void (*eip_log_callback)(int level, const char* target, const char* message);
+
+#define FAKE_MAX_NAME 256
+#define FAKE_MAX_DATA 512
+
+// CIP type codes
+#define FAKE_BOOL 0xC1
+#define FAKE_SINT 0xC2
+#define FAKE_INT 0xC3
+#define FAKE_DINT 0xC4
+#define FAKE_LINT 0xC5
+#define FAKE_USINT 0xC6
+#define FAKE_UINT 0xC7
+#define FAKE_UDINT 0xC8
+#define FAKE_ULINT 0xC9
+#define FAKE_REAL 0xCA
+#define FAKE_LREAL 0xCB
+#define FAKE_STRUCT 0x02A0
+#define FAKE_STRING_HANDLE 0x0FCE
+#define FAKE_STRING_DATA 82
+
+typedef struct fake_tag {
+	char name[FAKE_MAX_NAME];
+	unsigned short type;
+	unsigned short handle; // Structure handle when type is FAKE_STRUCT
+	int size;
+	unsigned char data[FAKE_MAX_DATA];
+	struct fake_tag* next;
+} fake_tag;
+
+static pthread_mutex_t fake_mu = PTHREAD_MUTEX_INITIALIZER;
+static fake_tag* fake_tags;
+static unsigned char* fake_clients; // Open flag by client ID
+static int fake_next_client = 1;
+static __thread unsigned long long fake_request_id;
+
+// fake_find returns the tag called name; fake_mu must be held
+static fake_tag* fake_find(const char* name) {
+	for (fake_tag* t = fake_tags; t != NULL; t = t->next) {
+		if (strcmp(t->name, name) == 0) {
+			return t;
+		}
+	}
+	return NULL;
+}
+
+// fake_store sets the value of a tag, creating it if needed
+static int fake_store(const char* name, unsigned short type, unsigned short handle, const void* data, int size) {
+	if (strlen(name) >= FAKE_MAX_NAME || size > FAKE_MAX_DATA) {
+		return -1;
+	}
+	pthread_mutex_lock(&fake_mu);
+	fake_tag* t = fake_find(name);
+	if (t == NULL) {
+		t = calloc(1, sizeof(fake_tag));
+		strcpy(t->name, name);
+		t->next = fake_tags;
+		fake_tags = t;
+	}
+	t->type = type;
+	t->handle = handle;
+	t->size = size;
+	memcpy(t->data, data, size);
+	pthread_mutex_unlock(&fake_mu);
+	return 0;
+}
+
+// fake_load copies the value of a tag of the given type into out
+static int fake_load(const char* name, unsigned short type, void* out, int size) {
+	pthread_mutex_lock(&fake_mu);
+	fake_tag* t = fake_find(name);
+	int ret = -1;
+	if (t != NULL && t->type == type && t->size == size) {
+		memcpy(out, t->data, size);
+		ret = 0;
+	}
+	pthread_mutex_unlock(&fake_mu);
+	return ret;
+}
+
+// fake_open reports whether client_id is a connected client
+static int fake_open(int client_id) {
+	pthread_mutex_lock(&fake_mu);
+	int open = client_id > 0 && client_id < fake_next_client && fake_clients[client_id];
+	pthread_mutex_unlock(&fake_mu);
+	return open;
+}
+
+// eip_connect fails for the documentation range 192.0.2.0/24, standing in
+// for an unreachable controller
+int eip_connect(const char* ip_address) {
+	if (ip_address == NULL || ip_address[0] == '\0' || strncmp(ip_address, "192.0.2.", 8) == 0) {
+		return -1;
+	}
+	pthread_mutex_lock(&fake_mu);
+	int id = fake_next_client++;
+	fake_clients = realloc(fake_clients, fake_next_client);
+	fake_clients[id] = 1;
+	pthread_mutex_unlock(&fake_mu);
+	return id;
+}
+
+int eip_disconnect(int client_id) {
+	if (!fake_open(client_id)) {
+		return -1;
+	}
+	pthread_mutex_lock(&fake_mu);
+	fake_clients[client_id] = 0;
+	pthread_mutex_unlock(&fake_mu);
+	return 0;
+}
+
+int eip_check_health(int client_id, int* is_healthy) {
+	if (!fake_open(client_id)) {
+		return -1;
+	}
+	*is_healthy = 1;
+	return 0;
+}
+
+int eip_check_health_detailed(int client_id, int* is_healthy, char* details, int details_capacity) {
+	if (!fake_open(client_id)) {
+		return -1;
+	}
+	*is_healthy = 1;
+	if (details_capacity > 0) {
+		strncpy(details, "fake", details_capacity - 1);
+		details[details_capacity - 1] = '\0';
+	}
+	return 0;
+}
+
+int eip_set_max_packet_size(int client_id, int size) { return fake_open(client_id) ? 0 : -1; }
+int eip_set_messaging_mode(int client_id, int mode) { return fake_open(client_id) ? 0 : -1; }
+int eip_set_route_path(int client_id, const unsigned char* path, int path_len) { return fake_open(client_id) ? 0 : -1; }
+int eip_set_forward_open_params(int client_id, unsigned int rpi_us, int connection_size, int timeout_multiplier, int transport_trigger) { return fake_open(client_id) ? 0 : -1; }
+int eip_set_log_callback(eip_log_callback callback, int max_level) { return 0; }
+
+// eip_set_request_id clears the ID even for an unknown client, as the
+// library does
+int eip_set_request_id(int client_id, unsigned long long request_id) {
+	fake_request_id = request_id;
+	return fake_open(client_id) ? 0 : -1;
+}
+
+unsigned long long eip_get_request_id(void) { return fake_request_id; }
+
+// Typed reads and writes
+
+#define FAKE_SCALAR(suffix, ctype, code, stored) \
+	int eip_read_##suffix(int client_id, const char* tag_name, ctype* result) { \
+		stored v; \
+		if (!fake_open(client_id) || fake_load(tag_name, code, &v, sizeof(v)) != 0) { \
+			return -1; \
+		} \
+		*result = (ctype)v; \
+		return 0; \
+	} \
+	int eip_write_##suffix(int client_id, const char* tag_name, ctype value) { \
+		stored v = (stored)value; \
+		if (!fake_open(client_id)) { \
+			return -1; \
+		} \
+		return fake_store(tag_name, code, 0, &v, sizeof(v)); \
+	}
+
+FAKE_SCALAR(bool, int, FAKE_BOOL, unsigned char)
+FAKE_SCALAR(sint, signed char, FAKE_SINT, signed char)
+FAKE_SCALAR(int, short, FAKE_INT, short)
+FAKE_SCALAR(dint, int, FAKE_DINT, int)
+FAKE_SCALAR(lint, long long, FAKE_LINT, long long)
+FAKE_SCALAR(usint, unsigned char, FAKE_USINT, unsigned char)
+FAKE_SCALAR(uint, unsigned short, FAKE_UINT, unsigned short)
+FAKE_SCALAR(udint, unsigned int, FAKE_UDINT, unsigned int)
+FAKE_SCALAR(ulint, unsigned long long, FAKE_ULINT, unsigned long long)
+FAKE_SCALAR(real, double, FAKE_REAL, float)
+FAKE_SCALAR(lreal, double, FAKE_LREAL, double)
+
+int eip_read_string(int client_id, const char* tag_name, char* result, int max_length) {
+	unsigned char data[4 + FAKE_STRING_DATA];
+	if (!fake_open(client_id)) {
+		return -1;
+	}
+	pthread_mutex_lock(&fake_mu);
+	fake_tag* t = fake_find(tag_name);
+	int ok = t != NULL && t->type == FAKE_STRUCT && t->handle == FAKE_STRING_HANDLE;
+	if (ok) {
+		memcpy(data, t->data, sizeof(data));
+	}
+	pthread_mutex_unlock(&fake_mu);
+	if (!ok) {
+		return -1;
+	}
+	int len = data[0] | data[1] << 8;
+	if (len >= max_length) {
+		return -1;
+	}
+	memcpy(result, data + 4, len);
+	result[len] = '\0';
+	return 0;
+}
+
+int eip_write_string(int client_id, const char* tag_name, const char* value) {
+	unsigned char data[4 + FAKE_STRING_DATA] = {0};
+	size_t len = strlen(value);
+	if (!fake_open(client_id) || len > FAKE_STRING_DATA) {
+		return -1;
+	}
+	data[0] = len & 0xFF;
+	data[1] = len >> 8;
+	memcpy(data + 4, value, len);
+	return fake_store(tag_name, FAKE_STRUCT, FAKE_STRING_HANDLE, data, sizeof(data));
+}
+
+// Unsupported functions
+
+int eip_read_udt(int client_id, const char* tag_name, char* result, int max_size) { return -1; }
+int eip_write_udt(int client_id, const char* tag_name, const char* value, int size) { return -1; }
+int eip_discover_tags(int client_id) { return -1; }
+int eip_get_tag_metadata_json(int client_id, const char* tag_name, char* result, int capacity) { return -1; }
+int eip_configure_batch_operations(int client_id, void* config) { return -1; }
+int eip_get_batch_config(int client_id, void* config) { return -1; }
+int eip_get_last_batch_timing(int client_id, void* timing) { return -1; }
+int eip_get_connection_info(int client_id, void* info) { return -1; }
+int eip_read_tags_batch(int client_id, char** tag_names, int tag_count, char* results, int results_capacity) { return -1; }
+int eip_write_tags_batch(int client_id, const char* tag_values, int tag_count, char* results, int results_capacity) { return -1; }
+int eip_execute_batch(int client_id, const char* operations, int operation_count, char* results, int results_capacity) { return -1; }
+
+// CIP messaging
+
+// fake_reply writes a reply header for service with status and returns its length
+static int fake_reply(unsigned char* out, unsigned char service, unsigned char status) {
+	out[0] = service | 0x80;
+	out[1] = 0;
+	out[2] = status;
+	out[3] = 0;
+	return 4;
+}
+
+// fake_tag_path decodes a request path of symbolic and element segments into
+// a tag name such as "Program:Main.Arr[2,3]". It returns the length of the
+// path in bytes, or -1 if it holds other segments.
+static int fake_tag_path(const unsigned char* req, int len, char* name) {
+	if (len < 2) {
+		return -1;
+	}
+	int pathLen = req[1] * 2;
+	if (2 + pathLen > len) {
+		return -1;
+	}
+	const unsigned char* p = req + 2;
+	const unsigned char* end = p + pathLen;
+	int n = 0, inIndex = 0;
+	name[0] = '\0';
+	while (p < end) {
+		unsigned long index;
+		if (p[0] == 0x91) {
+			int size = p[1];
+			if (p + 2 + size > end || n + size + 3 >= FAKE_MAX_NAME) {
+				return -1;
+			}
+			if (inIndex) {
+				name[n++] = ']';
+				inIndex = 0;
+			}
+			if (n > 0) {
+				name[n++] = '.';
+			}
+			memcpy(name + n, p + 2, size);
+			n += size;
+			p += 2 + size + (size % 2);
+			continue;
+		}
+		switch (p[0]) {
+		case 0x28:
+			index = p[1];
+			p += 2;
+			break;
+		case 0x29:
+			index = p[2] | p[3] << 8;
+			p += 4;
+			break;
+		case 0x2A:
+			index = p[2] | p[3] << 8 | p[4] << 16 | (unsigned long)p[5] << 24;
+			p += 6;
+			break;
+		default:
+			return -1;
+		}
+		if (n + 16 >= FAKE_MAX_NAME) {
+			return -1;
+		}
+		n += sprintf(name + n, inIndex ? ",%lu" : "[%lu", index);
+		inIndex = 1;
+	}
+	if (inIndex) {
+		name[n++] = ']';
+	}
+	name[n] = '\0';
+	return 2 + pathLen;
+}
+
+// fake_service handles a single CIP request and returns the reply length
+static int fake_service(const unsigned char* req, int len, unsigned char* out, int capacity);
+
+// fake_multiple handles a Multiple Service Packet
+static int fake_multiple(const unsigned char* data, int len, unsigned char* out, int capacity) {
+	if (len < 2) {
+		return fake_reply(out, 0x0A, 0x13);
+	}
+	int count = data[0] | data[1] << 8;
+	if (len < 2 + 2 * count) {
+		return fake_reply(out, 0x0A, 0x13);
+	}
+	if (capacity < 6 + 2 * count) {
+		return -1;
+	}
+	int n = fake_reply(out, 0x0A, 0);
+	int base = n;
+	out[n++] = count & 0xFF;
+	out[n++] = count >> 8;
+	n += 2 * count;
+	for (int i = 0; i < count; i++) {
+		int start = data[2 + 2 * i] | data[3 + 2 * i] << 8;
+		int end = i + 1 < count ? (data[4 + 2 * i] | data[5 + 2 * i] << 8) : len;
+		if (start > end || end > len) {
+			return fake_reply(out, 0x0A, 0x13);
+		}
+		int offset = n - base;
+		out[base + 2 + 2 * i] = offset & 0xFF;
+		out[base + 3 + 2 * i] = offset >> 8;
+		int size = fake_service(data + start, end - start, out + n, capacity - n);
+		if (size < 0) {
+			return -1;
+		}
+		if (out[n + 2] != 0) {
+			out[2] = 0x1E;
+		}
+		n += size;
+	}
+	return n;
+}
+
+static int fake_service(const unsigned char* req, int len, unsigned char* out, int capacity) {
+	char name[FAKE_MAX_NAME];
+	if (capacity < 4) {
+		return -1;
+	}
+	if (len < 2) {
+		return fake_reply(out, len > 0 ? req[0] : 0, 0x13);
+	}
+	unsigned char service = req[0];
+	if (service == 0x0A) {
+		int pathLen = 2 + req[1] * 2;
+		if (pathLen > len) {
+			return fake_reply(out, service, 0x13);
+		}
+		return fake_multiple(req + pathLen, len - pathLen, out, capacity);
+	}
+	if (service != 0x4C && service != 0x4D) {
+		return fake_reply(out, service, 0x08);
+	}
+	int pathLen = fake_tag_path(req, len, name);
+	if (pathLen < 0) {
+		return fake_reply(out, service, 0x04);
+	}
+	const unsigned char* data = req + pathLen;
+	int dataLen = len - pathLen;
+
+	if (service == 0x4D) {
+		if (dataLen < 4) {
+			return fake_reply(out, service, 0x13);
+		}
+		unsigned short type = data[0] | data[1] << 8;
+		unsigned short handle = 0;
+		int header = 4;
+		if (type == FAKE_STRUCT) {
+			handle = data[2] | data[3] << 8;
+			header = 6;
+		}
+		if (dataLen < header || fake_store(name, type, handle, data + header, dataLen - header) != 0) {
+			return fake_reply(out, service, 0x13);
+		}
+		return fake_reply(out, service, 0);
+	}
+
+	pthread_mutex_lock(&fake_mu);
+	fake_tag* t = fake_find(name);
+	if (t == NULL) {
+		pthread_mutex_unlock(&fake_mu);
+		return fake_reply(out, service, 0x04);
+	}
+	if (capacity < 8 + t->size) {
+		pthread_mutex_unlock(&fake_mu);
+		return -1;
+	}
+	int n = fake_reply(out, service, 0);
+	out[n++] = t->type & 0xFF;
+	out[n++] = t->type >> 8;
+	if (t->type == FAKE_STRUCT) {
+		out[n++] = t->handle & 0xFF;
+		out[n++] = t->handle >> 8;
+	}
+	memcpy(out + n, t->data, t->size);
+	n += t->size;
+	pthread_mutex_unlock(&fake_mu);
+	return n;
+}
+
+int eip_send_cip_request(int client_id, const unsigned char* request, int request_len, unsigned char* response, int response_capacity, int* response_len) {
+	if (!fake_open(client_id)) {
+		return -1;
+	}
+	unsigned char reply[8192];
+	int n = fake_service(request, request_len, reply, sizeof(reply));
+	if (n < 0) {
+		return -1;
+	}
+	*response_len = n;
+	if (n > response_capacity) {
+		return -2;
+	}
+	memcpy(response, reply, n);
+	return 0;
+}
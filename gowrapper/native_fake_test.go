@@ -0,0 +1,191 @@
+//go:build eipfake
+
+package ethernetip
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// newNativeFakeClient connects a client to the in-memory native layer
+func newNativeFakeClient(t testing.TB) *EipClient {
+	t.Helper()
+	client, err := NewClient("10.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to connect to the fake native layer: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// TestReadDintNoAlloc verifies that a successful ReadDint, including its
+// queuing and in-use tracking, performs no Go heap allocations
+func TestReadDintNoAlloc(t *testing.T) {
+	client := newNativeFakeClient(t)
+	if err := client.WriteDint("FastDint", 42); err != nil {
+		t.Fatalf("Failed to write dint value: %v", err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		value, err := client.ReadDint("FastDint")
+		if err != nil || value != 42 {
+			t.Fatalf("Expected 42, got %d, %v", value, err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Expected 0 allocs per ReadDint, got %v", allocs)
+	}
+}
+
+// TestQueuedOperationsTraced tests that plain reads are traced and that the
+// interceptor's operation and the error of a context read share one ID, also
+// when the read issues its own CIP requests (a bit of an integer tag)
+func TestQueuedOperationsTraced(t *testing.T) {
+	client := newNativeFakeClient(t)
+	if err := client.WriteDint("TracedDint", 7); err != nil {
+		t.Fatalf("Failed to write dint value: %v", err)
+	}
+	before := client.LastRequestID()
+	if _, err := client.ReadDint("TracedDint"); err != nil {
+		t.Fatalf("Failed to read dint value: %v", err)
+	}
+	if client.LastRequestID() != before+1 {
+		t.Errorf("Expected ReadDint to be assigned request %d, last is %d", before+1, client.LastRequestID())
+	}
+
+	var seen *Operation
+	client.AddInterceptor(func(ctx context.Context, op *Operation, next func(context.Context, *Operation) error) error {
+		err := next(ctx, op)
+		seen = op
+		return err
+	})
+	_, err := client.ReadValueContext(context.Background(), "Missing.3", Bool)
+	eipErr, ok := err.(*EipError)
+	if !ok {
+		t.Fatalf("Expected an *EipError, got %v", err)
+	}
+	if seen.RequestID == 0 || eipErr.Details["request_id"] != seen.RequestID || client.LastRequestID() != seen.RequestID {
+		t.Errorf("Expected the operation, the error and the last ID to agree, got %d, %v and %d",
+			seen.RequestID, eipErr.Details["request_id"], client.LastRequestID())
+	}
+}
+
+// TestAliasEntryPoints tests that single-tag calls, read plans, tag groups
+// and struct bindings all accept aliases, keying results by the names asked
+// for
+func TestAliasEntryPoints(t *testing.T) {
+	client := newNativeFakeClient(t)
+	if err := client.SetTagAliases(map[string]string{"line.count": "LineCount", "line.speed": "LineSpeed"}); err != nil {
+		t.Fatalf("Failed to set aliases: %v", err)
+	}
+	if err := client.WriteDint("line.count", 12); err != nil {
+		t.Fatalf("Failed to write through the alias: %v", err)
+	}
+	if value, err := client.ReadDint("LineCount"); err != nil || value != 12 {
+		t.Fatalf("Expected the alias write to reach LineCount, got %d, %v", value, err)
+	}
+
+	values, errs := client.ReadTags(map[string]PlcDataType{"line.count": Dint, "LineCount": Dint})
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	for _, name := range []string{"line.count", "LineCount"} {
+		if values[name] == nil || values[name].Value != int32(12) {
+			t.Errorf("Expected %s = 12, got %v", name, values[name])
+		}
+	}
+
+	group := client.NewTagGroup()
+	if err := group.Add("line.speed", Real); err != nil {
+		t.Fatalf("Failed to add alias to group: %v", err)
+	}
+	if err := group.WriteAll(map[string]interface{}{"line.speed": float32(1.5)}); err != nil {
+		t.Fatalf("Failed to write group: %v", err)
+	}
+	if value, err := client.ReadReal("LineSpeed"); err != nil || value != 1.5 {
+		t.Errorf("Expected the group write to reach LineSpeed, got %v, %v", value, err)
+	}
+
+	var line struct {
+		Count int32   `eip:"line.count"`
+		Speed float32 `eip:"line.speed"`
+	}
+	if err := client.ReadInto(&line); err != nil {
+		t.Fatalf("Failed to read struct: %v", err)
+	}
+	if line.Count != 12 || line.Speed != 1.5 {
+		t.Errorf("Expected {12 1.5}, got %+v", line)
+	}
+	line.Count = 13
+	if err := client.WriteFrom(&line); err != nil {
+		t.Fatalf("Failed to write struct: %v", err)
+	}
+	if value, err := client.ReadDint("LineCount"); err != nil || value != 13 {
+		t.Errorf("Expected the struct write to reach LineCount, got %d, %v", value, err)
+	}
+}
+
+// TestConsistencyGroupRead tests that a consistency group read resolves
+// aliases and is queued as one operation
+func TestConsistencyGroupRead(t *testing.T) {
+	client := newNativeFakeClient(t)
+	if err := client.SetTagAliases(map[string]string{"parts": "GroupParts"}); err != nil {
+		t.Fatalf("Failed to set aliases: %v", err)
+	}
+	if err := client.WriteDint("GroupParts", 21); err != nil {
+		t.Fatalf("Failed to write dint value: %v", err)
+	}
+	if err := client.WriteBool("GroupRunning", true); err != nil {
+		t.Fatalf("Failed to write bool value: %v", err)
+	}
+	group, err := client.NewConsistencyGroup(GroupMember{"parts", Dint}, GroupMember{"GroupRunning", Bool})
+	if err != nil {
+		t.Fatalf("Failed to create group: %v", err)
+	}
+
+	before := client.QueueStats().Submitted
+	values, err := group.Read()
+	if err != nil {
+		t.Fatalf("Failed to read group: %v", err)
+	}
+	if values["parts"] == nil || values["parts"].Value != int32(21) || values["GroupRunning"].Value != true {
+		t.Errorf("Expected parts = 21 and GroupRunning = true, got %+v", values)
+	}
+	if submitted := client.QueueStats().Submitted - before; submitted != 1 {
+		t.Errorf("Expected the read to be queued as 1 operation, got %d", submitted)
+	}
+}
+
+// TestTypedUpdate tests the generic Update and that a compare-and-swap update
+// of a NaN REAL is not taken for a concurrent modification
+func TestTypedUpdate(t *testing.T) {
+	client := newNativeFakeClient(t)
+	if err := client.WriteDint("UpdateDint", 41); err != nil {
+		t.Fatalf("Failed to write dint value: %v", err)
+	}
+	written, err := Update(client, "UpdateDint", Dint, func(old int32) int32 { return old + 1 })
+	if err != nil || written != 42 {
+		t.Fatalf("Expected 42 to be written, got %d, %v", written, err)
+	}
+	if value, err := client.ReadDint("UpdateDint"); err != nil || value != 42 {
+		t.Errorf("Expected 42, got %d, %v", value, err)
+	}
+	if _, err := Update(client, "UpdateDint", Dint, func(old int8) int8 { return old }); err != nil {
+		t.Errorf("Expected 42 to fit an int8, got %v", err)
+	}
+	if _, err := Update(client, "UpdateDint", Dint, func(old bool) bool { return !old }); err == nil {
+		t.Error("Expected an error converting a DINT to bool")
+	}
+
+	if err := client.WriteReal("UpdateReal", math.NaN()); err != nil {
+		t.Fatalf("Failed to write real value: %v", err)
+	}
+	value, err := client.UpdateWithRetry("UpdateReal", Real, func(old interface{}) interface{} { return 1.5 }, 1)
+	if err != nil {
+		t.Fatalf("Expected the NaN value to compare unchanged, got %v", err)
+	}
+	if value.Value != 1.5 {
+		t.Errorf("Expected 1.5 to be written, got %v", value.Value)
+	}
+}
@@ -0,0 +1,94 @@
+package ethernetip
+
+/*
+// Native log forwarding
+typedef void (*eip_log_callback)(int level, const char* target, const char* message);
+extern int eip_set_log_callback(eip_log_callback callback, int max_level);
+extern void goEipLog(int level, char* target, char* message);
+*/
+import "C"
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Native log levels, as passed to the log callback
+const (
+	nativeLevelError = 1
+	nativeLevelWarn  = 2
+	nativeLevelInfo  = 3
+	nativeLevelDebug = 4
+	nativeLevelTrace = 5
+)
+
+// LevelTrace is the slog level of the native library's trace records
+const LevelTrace = slog.LevelDebug - 4
+
+// nativeLogger receives forwarded native records; nil means slog.Default()
+var nativeLogger atomic.Pointer[slog.Logger]
+
+// ForwardNativeLogs delivers the native library's log records at level and
+// above to logger, or to slog.Default() when logger is nil. Records keep
+// their level (trace records use LevelTrace) and carry the emitting Rust
+// module in the "target" attribute. Forwarding to slog.Default() at
+// slog.LevelInfo is enabled when the package is loaded.
+func ForwardNativeLogs(logger *slog.Logger, level slog.Level) error {
+	nativeLogger.Store(logger)
+	if C.eip_set_log_callback(C.eip_log_callback(C.goEipLog), C.int(nativeMaxLevel(level))) != 0 {
+		return NewEipError(ErrInvalidOperation, "native library logging is owned by another logger")
+	}
+	return nil
+}
+
+// StopNativeLogs stops forwarding native log records
+func StopNativeLogs() {
+	C.eip_set_log_callback(nil, 0)
+}
+
+// nativeMaxLevel converts a minimum slog level to the most verbose native level
+func nativeMaxLevel(level slog.Level) int {
+	switch {
+	case level <= LevelTrace:
+		return nativeLevelTrace
+	case level <= slog.LevelDebug:
+		return nativeLevelDebug
+	case level <= slog.LevelInfo:
+		return nativeLevelInfo
+	case level <= slog.LevelWarn:
+		return nativeLevelWarn
+	default:
+		return nativeLevelError
+	}
+}
+
+// slogLevel converts a native level to a slog level
+func slogLevel(level int) slog.Level {
+	switch level {
+	case nativeLevelError:
+		return slog.LevelError
+	case nativeLevelWarn:
+		return slog.LevelWarn
+	case nativeLevelInfo:
+		return slog.LevelInfo
+	case nativeLevelDebug:
+		return slog.LevelDebug
+	default:
+		return LevelTrace
+	}
+}
+
+// logNative writes one native record to the configured logger
+func logNative(level int, target, message string) {
+	logger := nativeLogger.Load()
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.LogAttrs(context.Background(), slogLevel(level), message,
+		slog.String("source", "native"), slog.String("target", target))
+}
+
+//export goEipLog
+func goEipLog(level C.int, target *C.char, message *C.char) {
+	logNative(int(level), C.GoString(target), C.GoString(message))
+}
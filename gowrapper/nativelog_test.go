@@ -0,0 +1,68 @@
+package ethernetip
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+// recordingHandler is a slog.Handler that keeps the records it handles
+type recordingHandler struct {
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.records = append(h.records, r)
+	return nil
+}
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordingHandler) WithGroup(string) slog.Handler      { return h }
+
+// TestLogNative tests that native records keep their level and target
+func TestLogNative(t *testing.T) {
+	handler := &recordingHandler{}
+	previous := nativeLogger.Swap(slog.New(handler))
+	defer nativeLogger.Store(previous)
+
+	logNative(nativeLevelWarn, "rust_ethernet_ip", "Response too short")
+	logNative(nativeLevelTrace, "rust_ethernet_ip::tag_manager", "raw bytes")
+
+	if len(handler.records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(handler.records))
+	}
+	r := handler.records[0]
+	if r.Level != slog.LevelWarn || r.Message != "Response too short" {
+		t.Errorf("Unexpected record %v %q", r.Level, r.Message)
+	}
+	attrs := map[string]string{}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.String()
+		return true
+	})
+	if attrs["source"] != "native" || attrs["target"] != "rust_ethernet_ip" {
+		t.Errorf("Unexpected attributes %v", attrs)
+	}
+	if handler.records[1].Level != LevelTrace {
+		t.Errorf("Expected trace level, got %v", handler.records[1].Level)
+	}
+}
+
+// TestNativeLevels tests the mapping between slog and native levels
+func TestNativeLevels(t *testing.T) {
+	cases := map[slog.Level]int{
+		LevelTrace:      nativeLevelTrace,
+		slog.LevelDebug: nativeLevelDebug,
+		slog.LevelInfo:  nativeLevelInfo,
+		slog.LevelWarn:  nativeLevelWarn,
+		slog.LevelError: nativeLevelError,
+	}
+	for level, native := range cases {
+		if got := nativeMaxLevel(level); got != native {
+			t.Errorf("%v: got native level %d, want %d", level, got, native)
+		}
+		if got := slogLevel(native); got != level {
+			t.Errorf("native %d: got %v, want %v", native, got, level)
+		}
+	}
+}
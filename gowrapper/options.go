@@ -0,0 +1,220 @@
+package ethernetip
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"time"
+)
+
+// Settings of a client created by NewClient, which NewClientWithOptions
+// starts from
+const (
+	DefaultMaxPacketSize     = 4000
+	DefaultKeepAliveInterval = 30 * time.Second
+)
+
+// ClientOption configures a client created by NewClientWithOptions
+type ClientOption func(*clientOptions)
+
+// clientOptions collects the ClientOptions of NewClientWithOptions
+type clientOptions struct {
+	connectTimeout time.Duration
+	slot           *int
+	route          CIPPath
+	maxPacketSize  int
+	messagingMode  MessagingMode
+	forwardOpen    *ForwardOpenParams
+	keepAlive      time.Duration
+	logger         *slog.Logger
+}
+
+// WithConnectTimeout bounds the time to open the session, including any wait
+// for a connection of DefaultConnectionBudget. Zero, the default, waits as
+// long as the native connect takes.
+func WithConnectTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) { o.connectTimeout = timeout }
+}
+
+// WithSlot routes requests to the processor in the given backplane slot, as
+// SetTargetProfile does with a Logix profile, for controllers that are not
+// the EtherNet/IP endpoint themselves, such as a ControlLogix in slot 3
+func WithSlot(slot int) ClientOption {
+	return func(o *clientOptions) { o.slot = &slot }
+}
+
+// WithRoute routes requests along route to a processor behind the endpoint,
+// such as one in a remote chassis (see SetRoute). It overrides WithSlot and
+// cannot be combined with a route in the address.
+func WithRoute(route CIPPath) ClientOption {
+	return func(o *clientOptions) { o.route = route }
+}
+
+// WithMaxPacketSize sets the maximum packet size, DefaultMaxPacketSize by
+// default (see SetMaxPacketSize)
+func WithMaxPacketSize(size int) ClientOption {
+	return func(o *clientOptions) { o.maxPacketSize = size }
+}
+
+// WithMessagingMode selects how requests reach the controller,
+// MessagingUnconnected (UCMM) by default (see SetMessagingMode)
+func WithMessagingMode(mode MessagingMode) ClientOption {
+	return func(o *clientOptions) { o.messagingMode = mode }
+}
+
+// WithForwardOpenParams tunes the Class 3 connection of connected messaging
+// mode (see SetForwardOpenParams)
+func WithForwardOpenParams(params ForwardOpenParams) ClientOption {
+	return func(o *clientOptions) { o.forwardOpen = &params }
+}
+
+// WithKeepAlive sets the interval of the session health check, which also
+// drives reconnects, warm standby and the idle timeout.
+// DefaultKeepAliveInterval by default; zero or a negative interval turns it
+// off.
+func WithKeepAlive(interval time.Duration) ClientOption {
+	return func(o *clientOptions) { o.keepAlive = interval }
+}
+
+// WithLogger sends the client's log messages to logger (see SetLogger)
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(o *clientOptions) { o.logger = logger }
+}
+
+// NewClientWithOptions connects to the PLC at ipAddress like NewClient, with
+// the connection tuned by opts. The address may end in a route to the
+// processor behind the endpoint, "192.168.1.100/1,3" for the one in slot 3
+// (see ParseConnectionAddress).
+//
+//	client, err := ethernetip.NewClientWithOptions("192.168.1.100",
+//		ethernetip.WithConnectTimeout(5*time.Second),
+//		ethernetip.WithSlot(3),
+//		ethernetip.WithMaxPacketSize(504),
+//		ethernetip.WithKeepAlive(10*time.Second),
+//		ethernetip.WithLogger(slog.Default()))
+//
+// Options that cannot be applied fail the call and close the session.
+func NewClientWithOptions(ipAddress string, opts ...ClientOption) (*EipClient, error) {
+	o := clientOptions{keepAlive: DefaultKeepAliveInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ipAddress, route, err := ParseConnectionAddress(ipAddress)
+	if err != nil {
+		return nil, err
+	}
+	if ipAddress == "" {
+		return nil, NewEipError(ErrInvalidOperation, "IP address cannot be empty")
+	}
+	if len(route) > 0 && len(o.route) > 0 {
+		return nil, NewEipError(ErrInvalidOperation, "route given both in the address and with WithRoute")
+	}
+	if len(o.route) > 0 {
+		route = o.route
+	}
+	if _, err := route.Encode(); err != nil {
+		return nil, err
+	}
+	if o.messagingMode != MessagingUnconnected && o.messagingMode != MessagingConnected {
+		return nil, NewEipError(ErrInvalidOperation, fmt.Sprintf("unknown messaging mode %d", int(o.messagingMode)))
+	}
+	if o.forwardOpen != nil {
+		if err := o.forwardOpen.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if o.connectTimeout < 0 {
+		return nil, NewEipError(ErrInvalidOperation, fmt.Sprintf("connect timeout cannot be negative, got %v", o.connectTimeout))
+	}
+	if o.maxPacketSize < 0 {
+		return nil, NewEipError(ErrInvalidOperation, fmt.Sprintf("max packet size cannot be negative, got %d", o.maxPacketSize))
+	}
+
+	client := &EipClient{
+		ipAddr:        ipAddress,
+		tagCache:      make(map[string]*TagMetadata),
+		keepAliveStop: make(chan struct{}),
+	}
+	client.SetLogger(o.logger)
+	client.logf(slog.LevelDebug, "🔌", "Attempting to connect to PLC at %s", ipAddress)
+
+	ctx := context.Background()
+	if o.connectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.connectTimeout)
+		defer cancel()
+	}
+	clientID, err := connectSession(ctx, ipAddress)
+	if err != nil {
+		client.logf(slog.LevelError, "❌", "Failed to connect to PLC at %s: %v", ipAddress, err)
+		return nil, err
+	}
+	client.logf(slog.LevelInfo, "✅", "Successfully connected to PLC at %s with client ID %d", ipAddress, clientID)
+	client.session.Store(clientID)
+	client.poller = NewPoller(client)
+
+	if o.maxPacketSize > 0 {
+		if err := client.SetMaxPacketSize(o.maxPacketSize); err != nil {
+			client.Close()
+			return nil, err
+		}
+	} else if err := client.SetMaxPacketSize(DefaultMaxPacketSize); err != nil {
+		client.logf(slog.LevelWarn, "⚠️", "Failed to set max packet size: %v", err)
+	}
+	if o.slot != nil || len(route) > 0 {
+		profile := LogixProfile()
+		if o.slot != nil {
+			profile.Slot = *o.slot
+		}
+		profile.Route = route
+		if err := client.SetTargetProfile(profile); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	if o.forwardOpen != nil {
+		if err := client.SetForwardOpenParams(*o.forwardOpen); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	if o.messagingMode != MessagingUnconnected {
+		if err := client.SetMessagingMode(o.messagingMode); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	if o.keepAlive > 0 {
+		client.startKeepAlive(o.keepAlive)
+	}
+	return client, nil
+}
+
+// SetLogger sends the client's log messages, such as connects, reconnects
+// and failed requests, to logger with the PLC address in the "plc"
+// attribute. nil, the default, writes them with the standard log package.
+// The native library's records are forwarded separately (see
+// ForwardNativeLogs).
+func (c *EipClient) SetLogger(logger *slog.Logger) {
+	c.logger.Store(logger)
+}
+
+// Logger returns the logger set with SetLogger, nil if none
+func (c *EipClient) Logger() *slog.Logger {
+	return c.logger.Load()
+}
+
+// logf logs a client message at level. Without a logger it is written with
+// the standard log package, marked with icon.
+func (c *EipClient) logf(level slog.Level, icon, format string, args ...interface{}) {
+	logger := c.logger.Load()
+	if logger == nil {
+		log.Printf(icon+" [DEBUG] "+format, args...)
+		return
+	}
+	ctx := context.Background()
+	if logger.Enabled(ctx, level) {
+		logger.Log(ctx, level, fmt.Sprintf(format, args...), "plc", c.ipAddr)
+	}
+}
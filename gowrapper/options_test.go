@@ -0,0 +1,65 @@
+package ethernetip
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestClientOptions tests collecting the options of NewClientWithOptions
+func TestClientOptions(t *testing.T) {
+	logger := slog.Default()
+	o := clientOptions{keepAlive: DefaultKeepAliveInterval}
+	for _, opt := range []ClientOption{
+		WithConnectTimeout(5 * time.Second),
+		WithSlot(3),
+		WithMaxPacketSize(504),
+		WithKeepAlive(-1),
+		WithLogger(logger),
+	} {
+		opt(&o)
+	}
+	if o.connectTimeout != 5*time.Second || o.slot == nil || *o.slot != 3 || o.maxPacketSize != 504 || o.keepAlive != -1 || o.logger != logger {
+		t.Errorf("Unexpected options %+v", o)
+	}
+}
+
+// TestNewClientWithOptions tests invalid options and logging a failed
+// connect to the client's logger
+func TestNewClientWithOptions(t *testing.T) {
+	var eipErr *EipError
+	if _, err := NewClientWithOptions(""); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidOperation {
+		t.Errorf("Expected an error for an empty address, got %v", err)
+	}
+	if _, err := NewClientWithOptions("192.0.2.1", WithConnectTimeout(-time.Second)); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidOperation {
+		t.Errorf("Expected an error for a negative timeout, got %v", err)
+	}
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	_, err := NewClientWithOptions("192.0.2.1", WithLogger(logger), WithConnectTimeout(time.Second))
+	if !errors.As(err, &eipErr) || eipErr.Code != ErrConnectionFailed {
+		t.Fatalf("Expected the connect to fail offline, got %v", err)
+	}
+	if out := logs.String(); !strings.Contains(out, "Failed to connect to PLC at 192.0.2.1") || !strings.Contains(out, "plc=192.0.2.1") {
+		t.Errorf("Expected the failure in the client's log, got:\n%s", out)
+	}
+	if DefaultConnectionBudget.Held("192.0.2.1") != 0 {
+		t.Error("Expected the failed connect to release its connection")
+	}
+}
+
+// TestPacketSize tests the packet size of new sessions
+func TestPacketSize(t *testing.T) {
+	client := &EipClient{}
+	if client.packetSize() != DefaultMaxPacketSize {
+		t.Errorf("Expected the default packet size, got %d", client.packetSize())
+	}
+	client.maxPacketSize.Store(504)
+	if client.packetSize() != 504 {
+		t.Errorf("Expected the packet size set, got %d", client.packetSize())
+	}
+}
@@ -0,0 +1,122 @@
+package ethernetip
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ShedPolicy configures load shedding: rejecting reads while too many
+// operations are queued on the client, so a gateway under overload answers
+// reads quickly with ErrOverloaded instead of letting every request time
+// out. Writes are always admitted.
+type ShedPolicy struct {
+	// MaxQueueDepth is the number of queued operations at which reads are
+	// rejected; 0 admits every read
+	MaxQueueDepth int `json:"max_queue_depth"`
+}
+
+// QueueStats describes the operations submitted to a client. Operations run
+// one at a time, as the native driver sends one request at a time, so an
+// operation waits behind every operation queued ahead of it.
+type QueueStats struct {
+	// Depth is the number of operations queued or in progress, and PeakDepth
+	// the largest depth seen
+	Depth     int `json:"depth"`
+	PeakDepth int `json:"peak_depth"`
+	// Submitted counts the operations admitted and Shed the reads rejected
+	Submitted uint64 `json:"submitted"`
+	Shed      uint64 `json:"shed"`
+	// WaitTotal is the time admitted operations spent queued, from
+	// submission until they started, and LastWait and MaxWait that of the
+	// latest and longest wait
+	WaitTotal time.Duration `json:"wait_total"`
+	LastWait  time.Duration `json:"last_wait"`
+	MaxWait   time.Duration `json:"max_wait"`
+	Policy    ShedPolicy    `json:"policy"`
+}
+
+// AverageWait returns the mean time operations spent queued
+func (s QueueStats) AverageWait() time.Duration {
+	completed := s.Submitted - uint64(s.Depth)
+	if completed == 0 {
+		return 0
+	}
+	return s.WaitTotal / time.Duration(completed)
+}
+
+// submitQueue tracks the operations submitted to a client. The zero value
+// admits everything.
+type submitQueue struct {
+	mu    sync.Mutex
+	stats QueueStats
+	slot  sync.Mutex // held by the operation in progress
+}
+
+// SetShedPolicy sets when the client rejects reads with ErrOverloaded
+func (c *EipClient) SetShedPolicy(policy ShedPolicy) {
+	c.queue.mu.Lock()
+	defer c.queue.mu.Unlock()
+	policy.MaxQueueDepth = max(policy.MaxQueueDepth, 0)
+	c.queue.stats.Policy = policy
+}
+
+// ShedPolicy returns the client's load-shedding policy
+func (c *EipClient) ShedPolicy() ShedPolicy {
+	c.queue.mu.Lock()
+	defer c.queue.mu.Unlock()
+	return c.queue.stats.Policy
+}
+
+// QueueStats returns the depth and wait time of the client's operations
+func (c *EipClient) QueueStats() QueueStats {
+	c.queue.mu.Lock()
+	defer c.queue.mu.Unlock()
+	return c.queue.stats
+}
+
+// submit runs fn as a queued operation of the given kind, once the operations
+// ahead of it have finished, with a request ID set for its requests (see
+// traced). A read submitted while the queue is at the policy's depth fails
+// with ErrOverloaded without being sent. fn must not submit another
+// operation.
+func (c *EipClient) submit(kind OperationKind, tagName string, fn func() error) error {
+	q := &c.queue
+	q.mu.Lock()
+	if limit := q.stats.Policy.MaxQueueDepth; kind == OperationRead && limit > 0 && q.stats.Depth >= limit {
+		q.stats.Shed++
+		depth := q.stats.Depth
+		q.mu.Unlock()
+		return NewEipErrorWithDetails(ErrOverloaded,
+			fmt.Sprintf("read of '%s' rejected: %d operations queued", tagName, depth),
+			map[string]interface{}{"tag_name": tagName, "queue_depth": depth, "max_queue_depth": limit})
+	}
+	q.stats.Depth++
+	q.stats.PeakDepth = max(q.stats.PeakDepth, q.stats.Depth)
+	q.stats.Submitted++
+	q.mu.Unlock()
+
+	c.beginUse()
+	defer c.endUse()
+	submitted := time.Now()
+	q.slot.Lock()
+	wait := time.Since(submitted)
+	defer func() {
+		q.slot.Unlock()
+		q.mu.Lock()
+		q.stats.Depth--
+		q.stats.WaitTotal += wait
+		q.stats.LastWait = wait
+		q.stats.MaxWait = max(q.stats.MaxWait, wait)
+		q.mu.Unlock()
+	}()
+	return c.traced(nil, fn)
+}
+
+// submitTag is submit for an operation on a single tag. The tag name is
+// resolved as an alias here, once for every single-tag entry point, and fn
+// receives the name to send to the PLC.
+func (c *EipClient) submitTag(kind OperationKind, tagName string, fn func(tagName string) error) error {
+	tagName = c.ResolveAlias(tagName)
+	return c.submit(kind, tagName, func() error { return fn(tagName) })
+}
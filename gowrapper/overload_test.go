@@ -0,0 +1,107 @@
+package ethernetip
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSubmitShedsReads tests that reads beyond the queue depth are rejected while writes are admitted
+func TestSubmitShedsReads(t *testing.T) {
+	c := &EipClient{}
+	c.SetShedPolicy(ShedPolicy{MaxQueueDepth: 1})
+
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- c.submit(OperationRead, "Slow", func() error {
+			<-release
+			return nil
+		})
+	}()
+	waitFor(t, func() bool { return c.QueueStats().Depth == 1 })
+
+	called := false
+	err := c.submit(OperationRead, "Speed", func() error {
+		called = true
+		return nil
+	})
+	var eipErr *EipError
+	if !errors.As(err, &eipErr) || eipErr.Code != ErrOverloaded {
+		t.Fatalf("Expected ErrOverloaded, got %v", err)
+	}
+	if called {
+		t.Error("Expected a shed read not to be sent")
+	}
+
+	written := make(chan error)
+	go func() {
+		written <- c.submit(OperationWrite, "Setpoint", func() error { return nil })
+	}()
+	waitFor(t, func() bool { return c.QueueStats().Depth == 2 })
+
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-written; err != nil {
+		t.Errorf("Expected writes to be admitted under overload, got %v", err)
+	}
+
+	stats := c.QueueStats()
+	if stats.Depth != 0 || stats.PeakDepth != 2 || stats.Submitted != 2 || stats.Shed != 1 {
+		t.Errorf("Unexpected queue stats %+v", stats)
+	}
+	if stats.MaxWait < 5*time.Millisecond || stats.AverageWait() <= 0 || stats.AverageWait() > stats.MaxWait {
+		t.Errorf("Unexpected wait times %+v, average %v", stats, stats.AverageWait())
+	}
+
+	c.SetShedPolicy(ShedPolicy{})
+	if err := c.submit(OperationRead, "Speed", func() error { return nil }); err != nil {
+		t.Errorf("Expected every read admitted without a policy, got %v", err)
+	}
+}
+
+// TestSubmitWaitsForSlot tests that operations run one at a time and that the
+// wait is the time spent queued, not running
+func TestSubmitWaitsForSlot(t *testing.T) {
+	c := &EipClient{}
+	var running atomic.Bool
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- c.submit(OperationRead, "Slow", func() error {
+			running.Store(true)
+			defer running.Store(false)
+			<-release
+			return nil
+		})
+	}()
+	waitFor(t, func() bool { return c.QueueStats().Depth == 1 })
+
+	queued := make(chan error)
+	go func() {
+		queued <- c.submit(OperationWrite, "Setpoint", func() error {
+			if running.Load() {
+				return errors.New("ran while another operation was in progress")
+			}
+			time.Sleep(200 * time.Millisecond)
+			return nil
+		})
+	}()
+	waitFor(t, func() bool { return c.QueueStats().Depth == 2 })
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-queued; err != nil {
+		t.Fatal(err)
+	}
+
+	if wait := c.QueueStats().LastWait; wait < 10*time.Millisecond || wait >= 200*time.Millisecond {
+		t.Errorf("Expected the time spent behind the first operation, got %v", wait)
+	}
+}
@@ -0,0 +1,191 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// Well-known port numbers for port segments
+const (
+	PortBackplane  uint16 = 1 // ControlLogix backplane
+	PortEthernet   uint16 = 2 // Front EtherNet/IP port of a communication module
+	PortControlNet uint16 = 2 // ControlNet channel of a 1756-CNB bridge
+)
+
+// PathBuilder builds CIP paths segment by segment: port segments for routing,
+// symbolic segments for tags and logical segments for class, instance,
+// attribute and element addressing. Methods can be chained; the first
+// invalid segment is reported by Build.
+//
+//	path, err := ethernetip.NewPathBuilder().Class(0x01).Instance(1).Attribute(7).Build()
+//	route, err := ethernetip.NewPathBuilder().Backplane(2).Build()
+type PathBuilder struct {
+	path []byte
+	err  error
+}
+
+// NewPathBuilder creates an empty path
+func NewPathBuilder() *PathBuilder {
+	return &PathBuilder{}
+}
+
+// fail records the first error
+func (b *PathBuilder) fail(format string, args ...interface{}) *PathBuilder {
+	if b.err == nil {
+		b.err = NewEipError(ErrInvalidTagAddress, fmt.Sprintf(format, args...))
+	}
+	return b
+}
+
+// Port appends a port segment that routes through port to the node with the
+// given link address (a slot number on a backplane, an IP address on EtherNet/IP)
+func (b *PathBuilder) Port(port uint16, link []byte) *PathBuilder {
+	if port == 0 {
+		return b.fail("port 0 is reserved")
+	}
+	if len(link) == 0 || len(link) > 0xFF {
+		return b.fail("link address must be 1-255 bytes, got %d", len(link))
+	}
+	b.path = append(b.path, portSegment(port, link)...)
+	return b
+}
+
+// Backplane appends a port segment to the given slot of the backplane
+func (b *PathBuilder) Backplane(slot int) *PathBuilder {
+	if slot < 0 || slot > 0xFF {
+		return b.fail("invalid slot %d", slot)
+	}
+	return b.Port(PortBackplane, []byte{byte(slot)})
+}
+
+// Ethernet appends a port segment that leaves a communication module through
+// port (usually PortEthernet) to the device at the given IPv4 address
+func (b *PathBuilder) Ethernet(port uint16, address string) *PathBuilder {
+	ip := net.ParseIP(address)
+	if ip == nil || ip.To4() == nil {
+		return b.fail("invalid IPv4 address '%s'", address)
+	}
+	return b.Port(port, []byte(ip.To4().String()))
+}
+
+// Route appends the port segments of a route built with RouteBuilder or
+// parsed with ParseCIPPath
+func (b *PathBuilder) Route(route CIPPath) *PathBuilder {
+	for _, hop := range route {
+		link, err := hop.link()
+		if err != nil {
+			if b.err == nil {
+				b.err = err
+			}
+			return b
+		}
+		b.Port(hop.Port, link)
+	}
+	return b
+}
+
+// Class appends a class logical segment
+func (b *PathBuilder) Class(class uint32) *PathBuilder {
+	if class > 0xFFFF {
+		return b.fail("class 0x%X out of range", class)
+	}
+	b.path = append(b.path, logicalSegment(0x20, class)...)
+	return b
+}
+
+// Instance appends an instance logical segment
+func (b *PathBuilder) Instance(instance uint32) *PathBuilder {
+	b.path = append(b.path, logicalSegment(0x24, instance)...)
+	return b
+}
+
+// Attribute appends an attribute logical segment
+func (b *PathBuilder) Attribute(attribute uint32) *PathBuilder {
+	if attribute > 0xFFFF {
+		return b.fail("attribute 0x%X out of range", attribute)
+	}
+	b.path = append(b.path, logicalSegment(0x30, attribute)...)
+	return b
+}
+
+// Element appends an element (array index) logical segment. CIP calls this
+// the member ID segment.
+func (b *PathBuilder) Element(index uint32) *PathBuilder {
+	b.path = append(b.path, logicalSegment(0x28, index)...)
+	return b
+}
+
+// Symbol appends an ANSI extended symbolic segment
+func (b *PathBuilder) Symbol(name string) *PathBuilder {
+	if name == "" || len(name) > 0xFF {
+		return b.fail("symbol must be 1-255 characters, got %d", len(name))
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] < 0x20 || name[i] > 0x7E {
+			return b.fail("symbol '%s' contains a non-printable character", name)
+		}
+	}
+	b.path = append(b.path, symbolicSegment(name)...)
+	return b
+}
+
+// Tag appends the symbolic and element segments of a Logix tag name such as
+// "Program:Main.Recipe[2].Speed"
+func (b *PathBuilder) Tag(tagName string) *PathBuilder {
+	path, err := tagRequestPath(tagName)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return b
+	}
+	b.path = append(b.path, path...)
+	return b
+}
+
+// Raw appends already encoded segments, which must be an even number of bytes
+func (b *PathBuilder) Raw(segments []byte) *PathBuilder {
+	if len(segments)%2 != 0 {
+		return b.fail("raw segments must be an even number of bytes, got %d", len(segments))
+	}
+	b.path = append(b.path, segments...)
+	return b
+}
+
+// Build returns the encoded path, or the first error found while building it
+func (b *PathBuilder) Build() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.path) > 0xFF*2 {
+		return nil, NewEipError(ErrInvalidTagAddress, fmt.Sprintf("path of %d bytes exceeds 510", len(b.path)))
+	}
+	return append([]byte(nil), b.path...), nil
+}
+
+// portSegment encodes a port segment. Link addresses longer than one byte
+// set the extended link address flag and carry their size; ports above 14
+// use the extended port identifier. The segment is padded to an even length.
+func portSegment(port uint16, link []byte) []byte {
+	var seg []byte
+	first := byte(0)
+	if port < 0x0F {
+		first = byte(port)
+	} else {
+		first = 0x0F
+	}
+	if len(link) > 1 {
+		seg = append(seg, first|0x10, byte(len(link)))
+	} else {
+		seg = append(seg, first)
+	}
+	if port >= 0x0F {
+		seg = binary.LittleEndian.AppendUint16(seg, port)
+	}
+	seg = append(seg, link...)
+	if len(seg)%2 != 0 {
+		seg = append(seg, 0x00)
+	}
+	return seg
+}
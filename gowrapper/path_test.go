@@ -0,0 +1,64 @@
+package ethernetip
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestPathBuilder tests encoding of the supported segment types
+func TestPathBuilder(t *testing.T) {
+	cases := []struct {
+		name string
+		b    *PathBuilder
+		want []byte
+	}{
+		{"identity attribute", NewPathBuilder().Class(0x01).Instance(1).Attribute(7),
+			[]byte{0x20, 0x01, 0x24, 0x01, 0x30, 0x07}},
+		{"16-bit instance", NewPathBuilder().Class(0x6B).Instance(0x1234),
+			[]byte{0x20, 0x6B, 0x25, 0x00, 0x34, 0x12}},
+		{"backplane slot", NewPathBuilder().Backplane(3), []byte{0x01, 0x03}},
+		{"ethernet hop", NewPathBuilder().Backplane(1).Ethernet(PortEthernet, "10.0.0.5"),
+			[]byte{0x01, 0x01, 0x12, 0x08, '1', '0', '.', '0', '.', '0', '.', '5'}},
+		{"odd link padded", NewPathBuilder().Port(2, []byte("1.2.3.4")),
+			[]byte{0x12, 0x07, '1', '.', '2', '.', '3', '.', '4', 0x00}},
+		{"route", NewPathBuilder().Route(CIPPath{{Port: 1, Link: "2"}, {Port: 2, Link: "10.0.0.5"}}).Class(0x01),
+			[]byte{0x01, 0x02, 0x12, 0x08, '1', '0', '.', '0', '.', '0', '.', '5', 0x20, 0x01}},
+		{"extended port", NewPathBuilder().Port(18, []byte{0x05}),
+			[]byte{0x0F, 0x12, 0x00, 0x05}},
+		{"tag and element", NewPathBuilder().Symbol("Recipe").Element(4),
+			[]byte{0x91, 0x06, 'R', 'e', 'c', 'i', 'p', 'e', 0x28, 0x04}},
+		{"tag name", NewPathBuilder().Tag("Data[2]"),
+			[]byte{0x91, 0x04, 'D', 'a', 't', 'a', 0x28, 0x02}},
+	}
+	for _, c := range cases {
+		got, err := c.b.Build()
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !bytes.Equal(got, c.want) {
+			t.Errorf("%s: got % X, want % X", c.name, got, c.want)
+		}
+	}
+}
+
+// TestPathBuilderValidation tests that invalid segments are reported by Build
+func TestPathBuilderValidation(t *testing.T) {
+	invalid := map[string]*PathBuilder{
+		"slot":       NewPathBuilder().Backplane(256),
+		"port zero":  NewPathBuilder().Port(0, []byte{1}),
+		"empty link": NewPathBuilder().Port(1, nil),
+		"ip":         NewPathBuilder().Ethernet(PortEthernet, "plc.local"),
+		"class":      NewPathBuilder().Class(0x10000),
+		"symbol":     NewPathBuilder().Symbol(""),
+		"tag":        NewPathBuilder().Tag("Data[x]"),
+		"raw":        NewPathBuilder().Raw([]byte{0x20}),
+		"route":      NewPathBuilder().Route(CIPPath{{Port: 1, Link: "x"}}),
+	}
+	for name, b := range invalid {
+		// Later valid segments must not hide the first error
+		if _, err := b.Class(1).Build(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
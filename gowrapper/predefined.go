@@ -0,0 +1,232 @@
+package ethernetip
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/codec"
+)
+
+// Logix predefined structures all take 12 bytes: a DINT of status bits
+// followed by two DINTs
+const predefinedStructSize = 12
+
+// Timer is a Logix TIMER
+type Timer struct {
+	PRE int32 `json:"PRE"`
+	ACC int32 `json:"ACC"`
+	EN  bool  `json:"EN"`
+	TT  bool  `json:"TT"`
+	DN  bool  `json:"DN"`
+}
+
+// Counter is a Logix COUNTER
+type Counter struct {
+	PRE int32 `json:"PRE"`
+	ACC int32 `json:"ACC"`
+	CU  bool  `json:"CU"`
+	CD  bool  `json:"CD"`
+	DN  bool  `json:"DN"`
+	OV  bool  `json:"OV"`
+	UN  bool  `json:"UN"`
+}
+
+// Control is a Logix CONTROL, used by file and shift instructions
+type Control struct {
+	LEN int32 `json:"LEN"`
+	POS int32 `json:"POS"`
+	EN  bool  `json:"EN"`
+	EU  bool  `json:"EU"`
+	DN  bool  `json:"DN"`
+	EM  bool  `json:"EM"`
+	ER  bool  `json:"ER"`
+	UL  bool  `json:"UL"`
+	IN  bool  `json:"IN"`
+	FD  bool  `json:"FD"`
+}
+
+// statusBit reports whether a bit of the status DINT is set. The status bits
+// of the predefined structures are allocated downwards from bit 31.
+func statusBit(ctl uint32, bit uint) bool {
+	return ctl&(1<<bit) != 0
+}
+
+// DecodeTimer decodes the value bytes of a TIMER (without the structure handle)
+func DecodeTimer(data []byte) (*Timer, error) {
+	ctl, pre, acc, err := decodePredefined("TIMER", data)
+	if err != nil {
+		return nil, err
+	}
+	return &Timer{PRE: pre, ACC: acc, EN: statusBit(ctl, 31), TT: statusBit(ctl, 30), DN: statusBit(ctl, 29)}, nil
+}
+
+// DecodeCounter decodes the value bytes of a COUNTER (without the structure handle)
+func DecodeCounter(data []byte) (*Counter, error) {
+	ctl, pre, acc, err := decodePredefined("COUNTER", data)
+	if err != nil {
+		return nil, err
+	}
+	return &Counter{
+		PRE: pre, ACC: acc,
+		CU: statusBit(ctl, 31), CD: statusBit(ctl, 30), DN: statusBit(ctl, 29), OV: statusBit(ctl, 28), UN: statusBit(ctl, 27),
+	}, nil
+}
+
+// DecodeControl decodes the value bytes of a CONTROL (without the structure handle)
+func DecodeControl(data []byte) (*Control, error) {
+	ctl, length, pos, err := decodePredefined("CONTROL", data)
+	if err != nil {
+		return nil, err
+	}
+	return &Control{
+		LEN: length, POS: pos,
+		EN: statusBit(ctl, 31), EU: statusBit(ctl, 30), DN: statusBit(ctl, 29), EM: statusBit(ctl, 28),
+		ER: statusBit(ctl, 27), UL: statusBit(ctl, 26), IN: statusBit(ctl, 25), FD: statusBit(ctl, 24),
+	}, nil
+}
+
+// decodePredefined splits a predefined structure into its status DINT and
+// the two DINTs that follow it
+func decodePredefined(typeName string, data []byte) (ctl uint32, first, second int32, err error) {
+	r := codec.NewReader(data)
+	ctl, first, second = r.Uint32(), r.Int32(), r.Int32()
+	if r.Err() != nil {
+		return 0, 0, 0, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("%s data too short", typeName),
+			map[string]interface{}{"length": len(data), "expected": predefinedStructSize})
+	}
+	return ctl, first, second, nil
+}
+
+// DecodePredefined decodes a structure value (without the structure handle)
+// as the predefined type its template names: *Timer, *Counter or *Control.
+// The second return value is false for other structures.
+func DecodePredefined(template *StructTemplate, data []byte) (interface{}, bool, error) {
+	if template.Size != 0 && template.Size != predefinedStructSize {
+		return nil, false, nil
+	}
+	switch strings.ToUpper(template.Name) {
+	case "TIMER":
+		v, err := DecodeTimer(data)
+		return v, true, err
+	case "COUNTER":
+		v, err := DecodeCounter(data)
+		return v, true, err
+	case "CONTROL":
+		v, err := DecodeControl(data)
+		return v, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+// ReadTimer reads a TIMER tag
+func (c *EipClient) ReadTimer(tagName string) (*Timer, error) {
+	data, err := c.readPredefined(tagName, "TIMER")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeTimer(data)
+}
+
+// ReadCounter reads a COUNTER tag
+func (c *EipClient) ReadCounter(tagName string) (*Counter, error) {
+	data, err := c.readPredefined(tagName, "COUNTER")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeCounter(data)
+}
+
+// ReadControl reads a CONTROL tag
+func (c *EipClient) ReadControl(tagName string) (*Control, error) {
+	data, err := c.readPredefined(tagName, "CONTROL")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeControl(data)
+}
+
+// ReadStructure reads a structure tag and decodes it as *Timer, *Counter or
+// *Control, recognizing the type from the template metadata of the tag
+// database. Other structures fail with ErrInvalidDataType; read them with
+// ReadRaw.
+func (c *EipClient) ReadStructure(tagName string) (interface{}, error) {
+	var value interface{}
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readStructure(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readStructure is ReadStructure without queuing
+func (c *EipClient) readStructure(tagName string) (interface{}, error) {
+	template, err := c.tagTemplate(tagName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.readStructData(tagName)
+	if err != nil {
+		return nil, err
+	}
+	value, ok, err := DecodePredefined(template, data)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, NewEipErrorWithDetails(ErrInvalidDataType,
+			fmt.Sprintf("tag %s is a %s, not a predefined structure", tagName, template.Name),
+			map[string]interface{}{"tag_name": tagName, "template": template.Name})
+	}
+	return value, nil
+}
+
+// tagTemplate returns the template of a structure tag in the tag database
+func (c *EipClient) tagTemplate(tagName string) (*StructTemplate, error) {
+	info, ok := c.TagDatabase().Lookup(tagName)
+	if !ok {
+		return nil, NewEipErrorWithDetails(ErrTagNotFound, fmt.Sprintf("tag %s is not in the tag database; run DiscoverTagDatabase first", tagName),
+			map[string]interface{}{"tag_name": tagName})
+	}
+	if !info.IsStructure() || info.Dimensions() != 0 {
+		return nil, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("tag %s is not a scalar structure", tagName),
+			map[string]interface{}{"tag_name": tagName, "symbol_type": info.SymbolType})
+	}
+	return c.GetTemplate(info.TypeCode())
+}
+
+// readPredefined reads a predefined structure as one queued read, checking
+// its template when the tag is in the tag database
+func (c *EipClient) readPredefined(tagName, typeName string) ([]byte, error) {
+	var data []byte
+	err := c.submitTag(OperationRead, tagName, func(tagName string) error {
+		if _, known := c.TagDatabase().Lookup(tagName); known {
+			template, err := c.tagTemplate(tagName)
+			if err != nil {
+				return err
+			}
+			if !strings.EqualFold(template.Name, typeName) {
+				return NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("tag %s is a %s, not a %s", tagName, template.Name, typeName),
+					map[string]interface{}{"tag_name": tagName, "template": template.Name})
+			}
+		}
+		var err error
+		data, err = c.readStructData(tagName)
+		return err
+	})
+	return data, err
+}
+
+// readStructData reads a structure tag and returns its value bytes without
+// the structure handle
+func (c *EipClient) readStructData(tagName string) ([]byte, error) {
+	raw, code, err := c.readRaw(tagName)
+	if err != nil {
+		return nil, err
+	}
+	if code != CIPTypeStruct || len(raw) < 2 {
+		return nil, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("tag %s is not a structure", tagName),
+			map[string]interface{}{"tag_name": tagName, "cip_type": code})
+	}
+	return raw[2:], nil
+}
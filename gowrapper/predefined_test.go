@@ -0,0 +1,59 @@
+package ethernetip
+
+import (
+	"testing"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/codec"
+)
+
+// predefinedData encodes a predefined structure value
+func predefinedData(ctl uint32, first, second int32) []byte {
+	w := codec.NewWriter(predefinedStructSize)
+	w.PutUint32(ctl)
+	w.PutInt32(first)
+	w.PutInt32(second)
+	return w.Bytes()
+}
+
+// TestDecodePredefined tests decoding of TIMER, COUNTER and CONTROL
+func TestDecodePredefined(t *testing.T) {
+	timer, ok, err := DecodePredefined(&StructTemplate{Name: "TIMER", Size: 12}, predefinedData(1<<31|1<<29, 5000, 5000))
+	if err != nil || !ok {
+		t.Fatalf("Expected a TIMER, got %v, %v", ok, err)
+	}
+	if got := timer.(*Timer); *got != (Timer{PRE: 5000, ACC: 5000, EN: true, DN: true}) {
+		t.Errorf("Unexpected timer: %+v", got)
+	}
+
+	counter, _, _ := DecodePredefined(&StructTemplate{Name: "COUNTER", Size: 12}, predefinedData(1<<31|1<<28, 10, 12))
+	if got := counter.(*Counter); *got != (Counter{PRE: 10, ACC: 12, CU: true, OV: true}) {
+		t.Errorf("Unexpected counter: %+v", got)
+	}
+
+	control, err := DecodeControl(predefinedData(1<<29|1<<24, 8, 3))
+	if err != nil || *control != (Control{LEN: 8, POS: 3, DN: true, FD: true}) {
+		t.Errorf("Unexpected control: %+v, %v", control, err)
+	}
+
+	if _, ok, _ := DecodePredefined(&StructTemplate{Name: "Recipe", Size: 12}, predefinedData(0, 0, 0)); ok {
+		t.Error("Expected a UDT not to decode as a predefined type")
+	}
+	if _, ok, _ := DecodePredefined(&StructTemplate{Name: "TIMER", Size: 40}, nil); ok {
+		t.Error("Expected a TIMER-named UDT of another size not to decode")
+	}
+	if _, err := DecodeTimer(make([]byte, 8)); err == nil {
+		t.Error("Expected error for short TIMER data")
+	}
+}
+
+// TestReadStructureRequiresTemplate tests template lookup failures
+func TestReadStructureRequiresTemplate(t *testing.T) {
+	client := &EipClient{}
+	if _, err := client.ReadStructure("MyTimer"); err == nil {
+		t.Error("Expected error without a tag database")
+	}
+	client.tagDB.Store(NewTagDatabase([]TagInfo{{Name: "Count", SymbolType: CIPTypeDint}}))
+	if _, err := client.ReadStructure("Count"); err == nil {
+		t.Error("Expected error for an atomic tag")
+	}
+}
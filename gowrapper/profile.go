@@ -0,0 +1,153 @@
+package ethernetip
+
+/*
+#include <stdlib.h>
+
+// Routing
+extern int eip_set_route_path(int client_id, const unsigned char* path, int path_len);
+*/
+import "C"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// TargetProfile describes how to reach and recognise a kind of controller.
+// Hardware Logix controllers answer at the EtherNet/IP endpoint itself, while
+// emulated and soft controllers sit in a slot of a virtual chassis behind it.
+type TargetProfile struct {
+	Name string `json:"name"`
+	// Slot is the backplane slot of the processor, or -1 to address the
+	// device at the EtherNet/IP endpoint directly
+	Slot int `json:"slot"`
+	// ProductNames lists case-insensitive fragments expected in the
+	// processor's product name; empty accepts any controller
+	ProductNames []string `json:"product_names,omitempty"`
+	// Route, if not empty, is the full route to the processor and takes
+	// precedence over Slot
+	Route CIPPath `json:"route,omitempty"`
+}
+
+// LogixProfile addresses a hardware controller at the EtherNet/IP endpoint.
+// This is the default.
+func LogixProfile() TargetProfile {
+	return TargetProfile{Name: "Logix", Slot: -1}
+}
+
+// LogixEmulateProfile addresses a Studio 5000 Logix Emulate controller in the
+// given slot of the Chassis Monitor
+func LogixEmulateProfile(slot int) TargetProfile {
+	return TargetProfile{Name: "Logix Emulate", Slot: slot, ProductNames: []string{"emulat"}}
+}
+
+// SoftLogixProfile addresses a SoftLogix controller in the given slot of its
+// virtual chassis
+func SoftLogixProfile(slot int) TargetProfile {
+	return TargetProfile{Name: "SoftLogix", Slot: slot, ProductNames: []string{"softlogix"}}
+}
+
+// route returns the profile's route: Route, or a backplane hop (port 1) to
+// the processor's slot, or nil for a direct connection
+func (p TargetProfile) route() CIPPath {
+	if len(p.Route) > 0 {
+		return p.Route
+	}
+	if p.Slot < 0 {
+		return nil
+	}
+	return CIPPath{{Port: PortBackplane, Link: strconv.Itoa(p.Slot)}}
+}
+
+// routePath encodes the profile's route, nil for a direct connection
+func (p TargetProfile) routePath() ([]byte, error) {
+	route := p.route()
+	if route == nil {
+		return nil, nil
+	}
+	return route.Encode()
+}
+
+// matches reports whether identity looks like a processor of this profile
+func (p TargetProfile) matches(identity *DeviceIdentity) bool {
+	if identity.DeviceType != DeviceTypePLC {
+		return false
+	}
+	if len(p.ProductNames) == 0 {
+		return true
+	}
+	name := strings.ToLower(identity.ProductName)
+	for _, fragment := range p.ProductNames {
+		if strings.Contains(name, strings.ToLower(fragment)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTargetProfile selects how the client reaches its processor. Subsequent
+// requests are routed to the profile's slot, or along its route; a warm
+// standby session opened for the old route is replaced.
+func (c *EipClient) SetTargetProfile(profile TargetProfile) error {
+	path, err := profile.routePath()
+	if err != nil {
+		return err
+	}
+	if err := c.setRoutePath(path); err != nil {
+		return err
+	}
+	c.profile.Store(&profile)
+	c.resetStandby()
+	return nil
+}
+
+// TargetProfile returns the client's target profile
+func (c *EipClient) TargetProfile() TargetProfile {
+	if p := c.profile.Load(); p != nil {
+		return *p
+	}
+	return LogixProfile()
+}
+
+// VerifyTargetProfile reads the identity of the routed processor and checks
+// that it is a controller matching the target profile. It returns the identity
+// either way so callers can report what was found.
+func (c *EipClient) VerifyTargetProfile() (*DeviceIdentity, error) {
+	identity, err := c.ReadIdentity()
+	if err != nil {
+		return nil, err
+	}
+	profile := c.TargetProfile()
+	if !profile.matches(identity) {
+		return identity, NewEipErrorWithDetails(ErrInvalidOperation,
+			fmt.Sprintf("target '%s' does not match profile %s", identity.ProductName, profile.Name),
+			map[string]interface{}{
+				"profile":      profile.Name,
+				"slot":         profile.Slot,
+				"device_type":  identity.DeviceType,
+				"product_code": identity.ProductCode,
+				"product_name": identity.ProductName,
+			})
+	}
+	return identity, nil
+}
+
+// setRoutePath sets the native route path; nil addresses the endpoint directly
+func (c *EipClient) setRoutePath(path []byte) error {
+	var ptr *C.uchar
+	if len(path) > 0 {
+		ptr = (*C.uchar)(unsafe.Pointer(&path[0]))
+	}
+	retCode := int(C.eip_set_route_path(C.int(c.id()), ptr, C.int(len(path))))
+	if retCode != 0 {
+		return NewEipErrorWithDetails(ErrInvalidOperation, "Failed to set route path",
+			map[string]interface{}{
+				"error_code": retCode,
+				"client_id":  c.id(),
+			})
+	}
+	// Another processor may answer now
+	c.resetTargetVerification()
+	return nil
+}
@@ -0,0 +1,46 @@
+package ethernetip
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestTargetProfileRoutePath tests route encoding for direct and slotted targets
+func TestTargetProfileRoutePath(t *testing.T) {
+	path, err := LogixProfile().routePath()
+	if err != nil || path != nil {
+		t.Errorf("expected direct route, got % X, %v", path, err)
+	}
+	path, err = LogixEmulateProfile(2).routePath()
+	if err != nil || !bytes.Equal(path, []byte{0x01, 0x02}) {
+		t.Errorf("expected backplane slot 2, got % X, %v", path, err)
+	}
+	if _, err := SoftLogixProfile(300).routePath(); err == nil {
+		t.Error("expected error for invalid slot")
+	}
+}
+
+// TestTargetProfileMatches tests identity matching per profile
+func TestTargetProfileMatches(t *testing.T) {
+	emulator := &DeviceIdentity{DeviceType: DeviceTypePLC, ProductName: "Emulate 5570 Controller"}
+	hardware := &DeviceIdentity{DeviceType: DeviceTypePLC, ProductName: "1756-L83E/B"}
+	bridge := &DeviceIdentity{DeviceType: DeviceTypeCommunicationsAdapter, ProductName: "1756-EN2T/D"}
+
+	if !LogixEmulateProfile(2).matches(emulator) || LogixEmulateProfile(2).matches(hardware) {
+		t.Error("Logix Emulate profile should match only the emulator")
+	}
+	if !LogixProfile().matches(hardware) || LogixProfile().matches(bridge) {
+		t.Error("Logix profile should match any controller but not a bridge")
+	}
+	if !SoftLogixProfile(1).matches(&DeviceIdentity{DeviceType: DeviceTypePLC, ProductName: "SoftLogix5800"}) {
+		t.Error("SoftLogix profile should match SoftLogix")
+	}
+}
+
+// TestTargetProfileDefault tests that clients default to the Logix profile
+func TestTargetProfileDefault(t *testing.T) {
+	client := &EipClient{}
+	if got := client.TargetProfile(); got.Name != "Logix" || got.Slot != -1 {
+		t.Errorf("unexpected default profile: %+v", got)
+	}
+}
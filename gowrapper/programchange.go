@@ -0,0 +1,130 @@
+package ethernetip
+
+import "time"
+
+// defaultProgramCheckInterval is how often OnProgramChange polls when no
+// CheckInterval is set
+const defaultProgramCheckInterval = 10 * time.Second
+
+// ProgramChange describes a change of the program in the controller, such as
+// a download or an accepted online edit
+type ProgramChange struct {
+	Time time.Time `json:"time"`
+	// Previous and Current are the change detection values before and after
+	// (see ReadProgramSignature)
+	Previous []byte `json:"previous"`
+	Current  []byte `json:"current"`
+	// Changes counts the changes the client has detected, this one included
+	Changes int64 `json:"changes"`
+}
+
+// ProgramChangeStatus is the state of program change detection
+type ProgramChangeStatus struct {
+	// Signature is the change detection value read last, nil before the
+	// first check
+	Signature  []byte    `json:"signature"`
+	Changes    int64     `json:"changes"`
+	LastCheck  time.Time `json:"last_check"`
+	LastChange time.Time `json:"last_change"`
+}
+
+// ReadProgramSignature reads the controller's change detection value: the
+// audit tag as 8 little-endian bytes when MetadataCacheOptions.AuditTag is
+// set, otherwise the raw change detection attributes. Any change of the
+// program changes the value. Reading it does not record it; use
+// CheckProgramChange for that.
+func (c *EipClient) ReadProgramSignature() ([]byte, error) {
+	return c.programSignature(c.metadataCacheOptions().AuditTag)
+}
+
+// ProgramChangeStatus returns the last change detection value and how many
+// changes were detected, without talking to the controller
+func (c *EipClient) ProgramChangeStatus() ProgramChangeStatus {
+	w := &c.programWatch
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return ProgramChangeStatus{
+		Signature:  w.signature,
+		Changes:    w.changes,
+		LastCheck:  w.lastCheck,
+		LastChange: w.lastChange,
+	}
+}
+
+// OnProgramChange registers fn to be called when a download or online edit
+// changes the program in the controller, after the caches have been
+// invalidated, so dependent mappings can be rebuilt. While any listener is
+// registered, the client checks every CheckInterval set with
+// SetMetadataCacheOptions (10 s if it is 0), as of the first registration.
+// Changes found by metadata lookups and CheckProgramChange calls are
+// reported too. Returns a function that removes the listener.
+func (c *EipClient) OnProgramChange(fn func(change ProgramChange)) (remove func()) {
+	w := &c.programWatch
+	w.mu.Lock()
+	w.nextListener++
+	id := w.nextListener
+	if w.listeners == nil {
+		w.listeners = make(map[int]func(ProgramChange))
+	}
+	w.listeners[id] = fn
+	if w.stop == nil {
+		interval := w.opts.CheckInterval
+		if interval <= 0 {
+			interval = defaultProgramCheckInterval
+		}
+		w.stop = make(chan struct{})
+		c.pollProgramChanges(interval, w.stop)
+	}
+	w.mu.Unlock()
+
+	return func() {
+		w.mu.Lock()
+		delete(w.listeners, id)
+		var stop chan struct{}
+		if len(w.listeners) == 0 && w.stop != nil {
+			stop, w.stop = w.stop, nil
+		}
+		w.mu.Unlock()
+		if stop != nil {
+			close(stop)
+			w.wg.Wait()
+		}
+	}
+}
+
+// pollProgramChanges starts the goroutine that checks for program changes
+// every interval until stop is closed. Must be called with programWatch.mu
+// held.
+func (c *EipClient) pollProgramChanges(interval time.Duration, stop chan struct{}) {
+	w := &c.programWatch
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := c.Clock().NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C():
+				// Failures are retried on the next tick
+				c.CheckProgramChange()
+			}
+		}
+	}()
+}
+
+// stopProgramChanges removes every OnProgramChange listener and stops the
+// poll
+func (c *EipClient) stopProgramChanges() {
+	w := &c.programWatch
+	w.mu.Lock()
+	w.listeners = nil
+	stop := w.stop
+	w.stop = nil
+	w.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		w.wg.Wait()
+	}
+}
@@ -0,0 +1,50 @@
+package ethernetip
+
+import (
+	"testing"
+	"time"
+)
+
+// TestOnProgramChange tests notifying listeners after the caches are
+// invalidated, and stopping the poll with the last listener
+func TestOnProgramChange(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	client := browseClient()
+	client.SetClock(clock)
+
+	var changes []ProgramChange
+	var dbAtChange *TagDatabase
+	remove := client.OnProgramChange(func(change ProgramChange) {
+		changes = append(changes, change)
+		dbAtChange = client.TagDatabase()
+	})
+	clock.BlockUntil(1)
+
+	client.noteProgramSignature([]byte{1})
+	client.noteProgramSignature([]byte{1})
+	clock.Advance(time.Second)
+	client.noteProgramSignature([]byte{2})
+	if len(changes) != 1 {
+		t.Fatalf("Expected one change, got %+v", changes)
+	}
+	if c := changes[0]; c.Changes != 1 || string(c.Previous) != "\x01" || string(c.Current) != "\x02" || !c.Time.Equal(clock.Now()) {
+		t.Errorf("Unexpected change %+v", c)
+	}
+	if dbAtChange != nil {
+		t.Error("Expected the tag database to be dropped before listeners run")
+	}
+
+	status := client.ProgramChangeStatus()
+	if status.Changes != 1 || !status.LastChange.Equal(clock.Now()) || string(status.Signature) != "\x02" {
+		t.Errorf("Unexpected status %+v", status)
+	}
+
+	remove()
+	if client.programWatch.stop != nil || clock.Waiters() != 0 {
+		t.Error("Expected the poll to stop with the last listener")
+	}
+	client.noteProgramSignature([]byte{3})
+	if len(changes) != 1 {
+		t.Error("Expected a removed listener not to be called")
+	}
+}
@@ -0,0 +1,260 @@
+package ethernetip
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+)
+
+// ProgramLayout locates the Logix objects and attributes ListTasks and
+// ListPrograms read. The Task, Program and Routine objects are not publicly
+// documented and their attributes differ between firmware revisions, so they
+// can be changed with SetProgramLayout. A zero attribute leaves that property
+// unread; a zero RoutineClass skips routines.
+type ProgramLayout struct {
+	TaskClass    uint16 `json:"task_class"`
+	ProgramClass uint16 `json:"program_class"`
+	RoutineClass uint16 `json:"routine_class"`
+	// NameAttr is the name of tasks, programs and routines, read with Get
+	// Instance Attribute List as [length UINT][characters]
+	NameAttr uint16 `json:"name_attribute"`
+
+	TaskTypeAttr     uint16 `json:"task_type_attribute"`     // UINT, 1 continuous, 2 periodic, 3 event
+	TaskPriorityAttr uint16 `json:"task_priority_attribute"` // UINT
+	TaskRateAttr     uint16 `json:"task_rate_attribute"`     // UDINT, microseconds
+	TaskWatchdogAttr uint16 `json:"task_watchdog_attribute"` // UDINT, microseconds
+	TaskInhibitAttr  uint16 `json:"task_inhibit_attribute"`  // UINT, non-zero when inhibited
+
+	ProgramInhibitAttr uint16 `json:"program_inhibit_attribute"` // UINT, non-zero when inhibited
+}
+
+// DefaultProgramLayout is the layout of Logix controllers
+var DefaultProgramLayout = ProgramLayout{
+	TaskClass:          0x70,
+	ProgramClass:       0x68,
+	RoutineClass:       0x6D,
+	NameAttr:           0x01,
+	TaskTypeAttr:       0x02,
+	TaskPriorityAttr:   0x03,
+	TaskRateAttr:       0x04,
+	TaskWatchdogAttr:   0x05,
+	TaskInhibitAttr:    0x06,
+	ProgramInhibitAttr: 0x02,
+}
+
+// TaskType is how a task is triggered
+type TaskType int
+
+const (
+	TaskUnknown TaskType = iota
+	TaskContinuous
+	TaskPeriodic
+	TaskEvent
+)
+
+// String returns the task type as Studio 5000 names it
+func (t TaskType) String() string {
+	switch t {
+	case TaskContinuous:
+		return "CONTINUOUS"
+	case TaskPeriodic:
+		return "PERIODIC"
+	case TaskEvent:
+		return "EVENT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MarshalJSON encodes the task type as its name
+func (t TaskType) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + t.String() + `"`), nil
+}
+
+// TaskInfo describes a task of the controller. Properties the controller
+// does not report are left zero.
+type TaskInfo struct {
+	Instance uint32   `json:"instance"`
+	Name     string   `json:"name"`
+	Type     TaskType `json:"type"`
+	Priority int      `json:"priority"`
+	// Rate is the period of periodic tasks
+	Rate      time.Duration `json:"rate"`
+	Watchdog  time.Duration `json:"watchdog"`
+	Inhibited bool          `json:"inhibited"`
+}
+
+// ProgramInfo describes a program of the controller and its routines
+type ProgramInfo struct {
+	Instance  uint32        `json:"instance"`
+	Name      string        `json:"name"`
+	Inhibited bool          `json:"inhibited"`
+	Routines  []RoutineInfo `json:"routines"`
+}
+
+// RoutineInfo names a routine of a program
+type RoutineInfo struct {
+	Instance uint32 `json:"instance"`
+	Name     string `json:"name"`
+}
+
+// SetProgramLayout changes the objects and attributes ListTasks and
+// ListPrograms read
+func (c *EipClient) SetProgramLayout(layout ProgramLayout) {
+	c.programLayout.Store(&layout)
+}
+
+// ProgramLayout returns the objects and attributes ListTasks and ListPrograms
+// read
+func (c *EipClient) ProgramLayout() ProgramLayout {
+	if layout := c.programLayout.Load(); layout != nil {
+		return *layout
+	}
+	return DefaultProgramLayout
+}
+
+// ListTasks lists the tasks of the controller with their type, priority,
+// rate, watchdog and whether they are inhibited
+func (c *EipClient) ListTasks(ctx context.Context) ([]TaskInfo, error) {
+	layout := c.ProgramLayout()
+	instances, err := c.listInstanceNames(ctx, nil, layout.TaskClass, layout.NameAttr)
+	if err != nil {
+		return nil, err
+	}
+	attrs := presentAttributes([]attributeSpec{
+		{layout.TaskTypeAttr, 2}, {layout.TaskPriorityAttr, 2}, {layout.TaskRateAttr, 4},
+		{layout.TaskWatchdogAttr, 4}, {layout.TaskInhibitAttr, 2},
+	})
+
+	tasks := make([]TaskInfo, 0, len(instances))
+	for _, inst := range instances {
+		task := TaskInfo{Instance: inst.instance, Name: inst.name}
+		if len(attrs) > 0 {
+			values, err := c.getAttributeList(layout.TaskClass, inst.instance, attrs)
+			if err != nil && !objectMissing(err) {
+				return nil, err
+			}
+			decodeTask(&task, layout, values)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// decodeTask sets the properties of task from its attributes
+func decodeTask(task *TaskInfo, layout ProgramLayout, values map[uint16][]byte) {
+	if v, ok := values[layout.TaskTypeAttr]; ok {
+		if t := TaskType(binary.LittleEndian.Uint16(v)); t <= TaskEvent {
+			task.Type = t
+		}
+	}
+	if v, ok := values[layout.TaskPriorityAttr]; ok {
+		task.Priority = int(binary.LittleEndian.Uint16(v))
+	}
+	if v, ok := values[layout.TaskRateAttr]; ok {
+		task.Rate = time.Duration(binary.LittleEndian.Uint32(v)) * time.Microsecond
+	}
+	if v, ok := values[layout.TaskWatchdogAttr]; ok {
+		task.Watchdog = time.Duration(binary.LittleEndian.Uint32(v)) * time.Microsecond
+	}
+	if v, ok := values[layout.TaskInhibitAttr]; ok {
+		task.Inhibited = binary.LittleEndian.Uint16(v) != 0
+	}
+}
+
+// ListPrograms lists the programs of the controller, whether they are
+// inhibited, and their routines
+func (c *EipClient) ListPrograms(ctx context.Context) ([]ProgramInfo, error) {
+	layout := c.ProgramLayout()
+	instances, err := c.listInstanceNames(ctx, nil, layout.ProgramClass, layout.NameAttr)
+	if err != nil {
+		return nil, err
+	}
+
+	programs := make([]ProgramInfo, 0, len(instances))
+	for _, inst := range instances {
+		program := ProgramInfo{Instance: inst.instance, Name: inst.name, Routines: []RoutineInfo{}}
+		if layout.ProgramInhibitAttr != 0 {
+			values, err := c.getAttributeList(layout.ProgramClass, inst.instance, []attributeSpec{{layout.ProgramInhibitAttr, 2}})
+			if err != nil && !objectMissing(err) {
+				return nil, err
+			}
+			if v, ok := values[layout.ProgramInhibitAttr]; ok {
+				program.Inhibited = binary.LittleEndian.Uint16(v) != 0
+			}
+		}
+		if layout.RoutineClass != 0 {
+			routines, err := c.listInstanceNames(ctx, symbolicSegment("Program:"+inst.name), layout.RoutineClass, layout.NameAttr)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range routines {
+				program.Routines = append(program.Routines, RoutineInfo{Instance: r.instance, Name: r.name})
+			}
+		}
+		programs = append(programs, program)
+	}
+	return programs, nil
+}
+
+// presentAttributes returns attrs without the zero attributes
+func presentAttributes(attrs []attributeSpec) []attributeSpec {
+	present := attrs[:0:0]
+	for _, attr := range attrs {
+		if attr.id != 0 {
+			present = append(present, attr)
+		}
+	}
+	return present
+}
+
+// namedInstance is an object instance and its name
+type namedInstance struct {
+	instance uint32
+	name     string
+}
+
+// listInstanceNames walks the instances of class, under the object prefix
+// addresses (empty for the controller), with Get Instance Attribute List for
+// the name attribute
+func (c *EipClient) listInstanceNames(ctx context.Context, prefix []byte, class, nameAttr uint16) ([]namedInstance, error) {
+	request := []byte{0x01, 0x00, byte(nameAttr), byte(nameAttr >> 8)}
+
+	var instances []namedInstance
+	instance := uint32(0)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		path := append(append([]byte{}, prefix...), classInstancePath(class, instance)...)
+		resp, err := c.SendCIPMessage(CIPServiceGetInstanceAttributeList, path, request)
+		if err != nil {
+			return nil, err
+		}
+
+		page := parseInstanceNames(resp.Data)
+		instances = append(instances, page...)
+		if resp.GeneralStatus != CIPStatusPartialTransfer || len(page) == 0 {
+			return instances, nil
+		}
+		instance = page[len(page)-1].instance + 1
+	}
+}
+
+// parseInstanceNames decodes a Get Instance Attribute List reply for a name
+// attribute. Each entry is [instance UDINT][name length UINT][name].
+func parseInstanceNames(data []byte) []namedInstance {
+	var instances []namedInstance
+	for offset := 0; offset+6 <= len(data); {
+		instance := binary.LittleEndian.Uint32(data[offset:])
+		nameLen := int(binary.LittleEndian.Uint16(data[offset+4:]))
+		offset += 6
+		if offset+nameLen > len(data) {
+			break
+		}
+		instances = append(instances, namedInstance{instance: instance, name: string(data[offset : offset+nameLen])})
+		offset += nameLen
+	}
+	return instances
+}
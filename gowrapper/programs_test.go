@@ -0,0 +1,69 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestParseInstanceNames tests decoding a page of names, including a
+// truncated last entry
+func TestParseInstanceNames(t *testing.T) {
+	var data []byte
+	for i, name := range []string{"MainTask", "Fast"} {
+		data = binary.LittleEndian.AppendUint32(data, uint32(i+1))
+		data = binary.LittleEndian.AppendUint16(data, uint16(len(name)))
+		data = append(data, name...)
+	}
+	data = append(data, 3, 0, 0, 0, 10, 0, 'X')
+
+	got := parseInstanceNames(data)
+	if len(got) != 2 || got[0] != (namedInstance{1, "MainTask"}) || got[1] != (namedInstance{2, "Fast"}) {
+		t.Errorf("Unexpected instances %+v", got)
+	}
+}
+
+// TestDecodeTask tests decoding task attributes, leaving unreported ones zero
+func TestDecodeTask(t *testing.T) {
+	layout := DefaultProgramLayout
+	task := TaskInfo{Name: "Fast"}
+	decodeTask(&task, layout, map[uint16][]byte{
+		layout.TaskTypeAttr:     {2, 0},
+		layout.TaskPriorityAttr: {5, 0},
+		layout.TaskRateAttr:     binary.LittleEndian.AppendUint32(nil, 10000),
+		layout.TaskInhibitAttr:  {1, 0},
+	})
+	want := TaskInfo{Name: "Fast", Type: TaskPeriodic, Priority: 5, Rate: 10 * time.Millisecond, Inhibited: true}
+	if task != want {
+		t.Errorf("Expected %+v, got %+v", want, task)
+	}
+
+	task = TaskInfo{}
+	decodeTask(&task, layout, map[uint16][]byte{layout.TaskTypeAttr: {9, 0}})
+	if task.Type != TaskUnknown {
+		t.Errorf("Expected an unknown type, got %v", task.Type)
+	}
+	if b, _ := json.Marshal(TaskContinuous); string(b) != `"CONTINUOUS"` {
+		t.Errorf("Unexpected JSON %s", b)
+	}
+}
+
+// TestProgramLayout tests overriding the layout and skipping zero attributes
+func TestProgramLayout(t *testing.T) {
+	c := &EipClient{}
+	if c.ProgramLayout() != DefaultProgramLayout {
+		t.Error("Expected the default layout")
+	}
+	layout := DefaultProgramLayout
+	layout.TaskWatchdogAttr = 0
+	c.SetProgramLayout(layout)
+	if c.ProgramLayout().TaskWatchdogAttr != 0 {
+		t.Error("Expected the layout to be replaced")
+	}
+
+	attrs := presentAttributes([]attributeSpec{{1, 2}, {0, 4}, {3, 2}})
+	if len(attrs) != 2 || attrs[0].id != 1 || attrs[1].id != 3 {
+		t.Errorf("Unexpected attributes %+v", attrs)
+	}
+}
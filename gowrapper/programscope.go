@@ -0,0 +1,134 @@
+package ethernetip
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ProgramScope reads and writes the tags of one program by their names in
+// the program, e.g. "Count" for "Program:MainProgram.Count". It is obtained
+// with Program.
+type ProgramScope struct {
+	client *EipClient
+	name   string
+	prefix string // "Program:Name."
+}
+
+// Program returns a handle on the tags of the named program, after checking
+// that the program exists: in the tag database if one has been discovered,
+// where the name is matched ignoring case and its spelling in the
+// controller is used, or else by asking the controller for the program's
+// symbols. Unknown programs fail with ErrTagNotFound.
+func (c *EipClient) Program(name string) (*ProgramScope, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "Program:")
+	if name == "" || strings.ContainsAny(name, ".[]: ") {
+		return nil, NewEipErrorWithDetails(ErrInvalidTagName, fmt.Sprintf("invalid program name '%s'", name),
+			map[string]interface{}{"program": name})
+	}
+
+	if db := c.TagDatabase(); db != nil {
+		found := false
+		for _, tag := range db.Tags {
+			if tag.Program == "" && strings.EqualFold(tag.Name, "Program:"+name) {
+				name, found = strings.TrimPrefix(tag.Name, "Program:"), true
+				break
+			}
+		}
+		if !found {
+			return nil, programNotFound(name)
+		}
+	} else if err := c.probeProgram(name); err != nil {
+		return nil, err
+	}
+	return &ProgramScope{client: c, name: name, prefix: "Program:" + name + "."}, nil
+}
+
+// probeProgram asks the controller for the first symbol of a program, to
+// learn whether the program exists
+func (c *EipClient) probeProgram(name string) error {
+	path := append(symbolicSegment("Program:"+name), classInstancePath(CIPClassSymbol, 0)...)
+	_, err := c.SendCIPMessage(CIPServiceGetInstanceAttributeList, path, []byte{0x01, 0x00, 0x01, 0x00})
+	if objectMissing(err) {
+		return programNotFound(name)
+	}
+	return err
+}
+
+// programNotFound returns the error for a program the controller does not have
+func programNotFound(name string) error {
+	return NewEipErrorWithDetails(ErrTagNotFound, fmt.Sprintf("program '%s' not found", name),
+		map[string]interface{}{"program": name})
+}
+
+// Name returns the program name
+func (p *ProgramScope) Name() string {
+	return p.name
+}
+
+// Tag returns the controller-wide name of a tag of the program:
+// "Program:MainProgram.Count" for "Count"
+func (p *ProgramScope) Tag(tagName string) string {
+	return p.prefix + tagName
+}
+
+// ReadValue reads a tag of the program (see EipClient.ReadValue)
+func (p *ProgramScope) ReadValue(tagName string, dataType PlcDataType) (*PlcValue, error) {
+	return p.client.ReadValue(p.Tag(tagName), dataType)
+}
+
+// WriteValue writes a tag of the program (see EipClient.WriteValue)
+func (p *ProgramScope) WriteValue(tagName string, value *PlcValue) error {
+	return p.client.WriteValue(p.Tag(tagName), value)
+}
+
+// ReadValueContext reads a tag of the program (see EipClient.ReadValueContext)
+func (p *ProgramScope) ReadValueContext(ctx context.Context, tagName string, dataType PlcDataType) (*PlcValue, error) {
+	return p.client.ReadValueContext(ctx, p.Tag(tagName), dataType)
+}
+
+// WriteValueContext writes a tag of the program (see
+// EipClient.WriteValueContext)
+func (p *ProgramScope) WriteValueContext(ctx context.Context, tagName string, value *PlcValue) error {
+	return p.client.WriteValueContext(ctx, p.Tag(tagName), value)
+}
+
+// ReadTag reads a tag of the program with its type looked up (see
+// EipClient.ReadTag)
+func (p *ProgramScope) ReadTag(tagName string) (*PlcValue, error) {
+	return p.client.ReadTag(p.Tag(tagName))
+}
+
+// ReadUdt reads a structure tag of the program (see EipClient.ReadUdt)
+func (p *ProgramScope) ReadUdt(tagName string) (*UdtValue, error) {
+	return p.client.ReadUdt(p.Tag(tagName))
+}
+
+// WriteUdt writes a structure tag of the program (see EipClient.WriteUdt)
+func (p *ProgramScope) WriteUdt(tagName string, value *UdtValue) error {
+	return p.client.WriteUdt(p.Tag(tagName), value)
+}
+
+// ReadMultipleTags reads several tags of the program in one request (see
+// EipClient.ReadMultipleTags). Results are keyed by the names in the program.
+func (p *ProgramScope) ReadMultipleTags(tags map[string]PlcDataType) (map[string]*PlcValue, error) {
+	scoped := make(map[string]PlcDataType, len(tags))
+	for name, dataType := range tags {
+		scoped[p.Tag(name)] = dataType
+	}
+	values, err := p.client.ReadMultipleTags(scoped)
+	if values == nil {
+		return nil, err
+	}
+	results := make(map[string]*PlcValue, len(values))
+	for name, value := range values {
+		results[strings.TrimPrefix(name, p.prefix)] = value
+	}
+	return results, err
+}
+
+// GetTagMetadata describes a tag of the program (see
+// EipClient.GetTagMetadataCached)
+func (p *ProgramScope) GetTagMetadata(tagName string) (*TagMetadata, error) {
+	return p.client.GetTagMetadataCached(p.Tag(tagName))
+}
@@ -0,0 +1,38 @@
+package ethernetip
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestProgram tests validating the program against the tag database and
+// prefixing tag names
+func TestProgram(t *testing.T) {
+	client := browseClient()
+
+	main, err := client.Program("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if main.Name() != "Main" || main.Tag("Count") != "Program:Main.Count" {
+		t.Errorf("Unexpected scope %q, %q", main.Name(), main.Tag("Count"))
+	}
+	if p, err := client.Program("Program:Main"); err != nil || p.Name() != "Main" {
+		t.Errorf("Expected the Program: prefix to be accepted, got %v", err)
+	}
+
+	var eipErr *EipError
+	if _, err := client.Program("Other"); !errors.As(err, &eipErr) || eipErr.Code != ErrTagNotFound {
+		t.Errorf("Expected ErrTagNotFound, got %v", err)
+	}
+	for _, name := range []string{"", "Main.Count", "Main[1]"} {
+		if _, err := client.Program(name); err == nil {
+			t.Errorf("Expected an error for %q", name)
+		}
+	}
+
+	// Without a tag database the controller is asked, which fails offline
+	if _, err := (&EipClient{}).Program("Main"); err == nil {
+		t.Error("Expected an error without a controller")
+	}
+}
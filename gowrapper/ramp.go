@@ -0,0 +1,111 @@
+package ethernetip
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RampTag moves a numeric tag from its current value to target at
+// ratePerSecond, writing an intermediate value every interval instead of
+// stepping the setpoint at once. Each value is computed from the time since
+// the ramp started, so slow writes do not stretch the ramp; integer tags are
+// written rounded. done, if not nil, is called once when the ramp ends: with
+// nil after target was written, with context.Canceled after cancel, or with
+// the error of a failed write, which stops the ramp. The tag is read once to
+// start the ramp; errors reading it or invalid arguments are returned
+// instead of starting.
+func (c *EipClient) RampTag(tagName string, target *PlcValue, ratePerSecond float64, interval time.Duration, done func(err error)) (cancel func(), err error) {
+	return rampTag(c, tagName, target, ratePerSecond, interval, done)
+}
+
+// rampTag runs a ramp through client
+func rampTag(client Client, tagName string, target *PlcValue, ratePerSecond float64, interval time.Duration, done func(err error)) (func(), error) {
+	if target == nil {
+		return nil, NewEipError(ErrInvalidValue, "ramp target cannot be nil")
+	}
+	if target.Type == Bool || target.Type == String {
+		return nil, NewEipError(ErrInvalidDataType, fmt.Sprintf("cannot ramp a %s tag", target.Type))
+	}
+	end, ok := numericValue(target.Value)
+	if !ok {
+		return nil, NewEipError(ErrInvalidTagValue, fmt.Sprintf("ramp target must be a number, got %T", target.Value))
+	}
+	if !(ratePerSecond > 0) || math.IsInf(ratePerSecond, 0) {
+		return nil, NewEipError(ErrInvalidValue, fmt.Sprintf("ramp rate must be positive, got %v", ratePerSecond))
+	}
+	if interval <= 0 {
+		return nil, NewEipError(ErrInvalidValue, fmt.Sprintf("ramp interval must be positive, got %v", interval))
+	}
+	if _, err := NewPlcValue(target.Type, end); err != nil {
+		return nil, err
+	}
+
+	current, err := client.ReadValue(tagName, target.Type)
+	if err != nil {
+		return nil, err
+	}
+	start, ok := numericValue(current.Value)
+	if !ok {
+		return nil, NewEipError(ErrInvalidDataType, fmt.Sprintf("cannot ramp '%s': read %T", tagName, current.Value))
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	var once sync.Once
+	finish := func(err error) {
+		once.Do(func() {
+			stop()
+			if done != nil {
+				done(err)
+			}
+		})
+	}
+	go func() {
+		finish(runRamp(ctx, client, tagName, target.Type, start, end, ratePerSecond, interval))
+	}()
+	return stop, nil
+}
+
+// runRamp writes the ramp's values until target is written or ctx is done
+func runRamp(ctx context.Context, client Client, tagName string, dataType PlcDataType, start, end, rate float64, interval time.Duration) error {
+	clock := clockOf(client)
+	began := clock.Now()
+	ticker := clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+		next, last := rampValue(start, end, rate, clock.Now().Sub(began))
+		if dataType != Real && dataType != Lreal {
+			next = math.Round(next)
+		}
+		value, err := NewPlcValue(dataType, next)
+		if err != nil {
+			return err
+		}
+		if err := client.WriteValue(tagName, value); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+// rampValue returns the value of a ramp from start to end at rate per second
+// after elapsed, and whether it has reached end
+func rampValue(start, end, rate float64, elapsed time.Duration) (float64, bool) {
+	step := rate * elapsed.Seconds()
+	if math.Abs(end-start) <= step {
+		return end, true
+	}
+	if end < start {
+		return start - step, false
+	}
+	return start + step, false
+}
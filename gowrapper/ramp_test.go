@@ -0,0 +1,107 @@
+package ethernetip
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRampValue(t *testing.T) {
+	cases := []struct {
+		start, end, rate float64
+		elapsed          time.Duration
+		want             float64
+		last             bool
+	}{
+		{0, 100, 10, 0, 0, false},
+		{0, 100, 10, 2 * time.Second, 20, false},
+		{100, 0, 10, 2 * time.Second, 80, false},
+		{0, 100, 10, 10 * time.Second, 100, true},
+		{0, 100, 10, 15 * time.Second, 100, true},
+		{50, 50, 10, 0, 50, true},
+	}
+	for _, tc := range cases {
+		got, last := rampValue(tc.start, tc.end, tc.rate, tc.elapsed)
+		if got != tc.want || last != tc.last {
+			t.Errorf("rampValue(%v, %v, %v, %v) = %v, %v; expected %v, %v",
+				tc.start, tc.end, tc.rate, tc.elapsed, got, last, tc.want, tc.last)
+		}
+	}
+}
+
+func TestRampTag(t *testing.T) {
+	fake := newFakeClient()
+	fake.set("Setpoint", int32(0))
+
+	done := make(chan error, 1)
+	_, err := rampTag(fake, "Setpoint", &PlcValue{Type: Dint, Value: int32(20)}, 1000, time.Millisecond,
+		func(err error) { done <- err })
+	if err != nil {
+		t.Fatalf("Failed to start ramp: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Ramp failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Ramp did not complete")
+	}
+	if v, _ := fake.ReadValue("Setpoint", Dint); v.Value != int32(20) {
+		t.Errorf("Expected the ramp to end at 20, got %v", v.Value)
+	}
+
+	// Cancel stops the ramp and reports context.Canceled
+	fake.set("Setpoint", 0.0)
+	cancel, err := rampTag(fake, "Setpoint", &PlcValue{Type: Real, Value: 1000.0}, 1, time.Millisecond,
+		func(err error) { done <- err })
+	if err != nil {
+		t.Fatalf("Failed to start ramp: %v", err)
+	}
+	cancel()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if v, _ := fake.ReadValue("Setpoint", Real); v.Value.(float64) >= 1 {
+		t.Errorf("Expected the cancelled ramp to stop near 0, got %v", v.Value)
+	}
+
+	// A failed write stops the ramp with its error
+	fake.set("Setpoint", int32(0))
+	if _, err := rampTag(fake, "Setpoint", &PlcValue{Type: Dint, Value: int32(1000)}, 1, 10*time.Millisecond,
+		func(err error) { done <- err }); err != nil {
+		t.Fatalf("Failed to start ramp: %v", err)
+	}
+	fake.mu.Lock()
+	fake.err = NewEipError(ErrTimeout, "timed out")
+	fake.mu.Unlock()
+	var eipErr *EipError
+	if err := <-done; !errors.As(err, &eipErr) || eipErr.Code != ErrTimeout {
+		t.Errorf("Expected the write error, got %v", err)
+	}
+	fake.mu.Lock()
+	fake.err = nil
+	fake.mu.Unlock()
+
+	for _, tc := range []struct {
+		target   *PlcValue
+		rate     float64
+		interval time.Duration
+	}{
+		{nil, 1, time.Second},
+		{&PlcValue{Type: Bool, Value: true}, 1, time.Second},
+		{&PlcValue{Type: Dint, Value: "high"}, 1, time.Second},
+		{&PlcValue{Type: Sint, Value: 1000}, 1, time.Second},
+		{&PlcValue{Type: Dint, Value: int32(10)}, 0, time.Second},
+		{&PlcValue{Type: Dint, Value: int32(10)}, 1, 0},
+	} {
+		if _, err := rampTag(fake, "Setpoint", tc.target, tc.rate, tc.interval, nil); err == nil {
+			t.Errorf("Expected ramp to %v at %v/s every %v to be rejected", tc.target, tc.rate, tc.interval)
+		}
+	}
+	if _, err := rampTag(fake, "Missing", &PlcValue{Type: Dint, Value: int32(10)}, 1, time.Second, nil); !errors.As(err, &eipErr) || eipErr.Code != ErrTagNotFound {
+		t.Errorf("Expected ErrTagNotFound reading the start value, got %v", err)
+	}
+}
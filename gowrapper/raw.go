@@ -0,0 +1,158 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ReadRaw reads a tag and returns its value bytes undecoded together with the
+// CIP type code from the reply, for types the wrapper does not understand
+// (custom structures, vendor types). For structures the code is
+// CIPTypeStruct and the data starts with the 2-byte structure handle, exactly
+// as WriteRaw expects it back. The value is read with Read Tag Fragmented, so
+// values larger than one packet take several round trips.
+func (c *EipClient) ReadRaw(tagName string) ([]byte, uint16, error) {
+	var data []byte
+	var code uint16
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		data, code, err = c.readRaw(tagName)
+		return err
+	})
+	return data, code, err
+}
+
+// readRaw is ReadRaw without queuing
+func (c *EipClient) readRaw(tagName string) ([]byte, uint16, error) {
+	path, err := tagRequestPath(tagName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var code uint16
+	var data []byte
+	for {
+		resp, err := c.SendCIPMessage(CIPServiceReadTagFragmented, path, readFragmentedRequest(1, rawValueLen(code, data)))
+		if err != nil {
+			return nil, 0, err
+		}
+		fragment, err := splitRawReply(resp.Data)
+		if err != nil {
+			return nil, 0, err
+		}
+		if data == nil {
+			code = binary.LittleEndian.Uint16(resp.Data)
+			data = fragment.handle
+		}
+		data = append(data, fragment.value...)
+		if resp.GeneralStatus != CIPStatusPartialTransfer {
+			return data, code, nil
+		}
+		if len(fragment.value) == 0 {
+			return nil, 0, NewEipError(ErrInvalidValue, "Read Tag Fragmented made no progress")
+		}
+	}
+}
+
+// WriteRaw writes value bytes to a tag without encoding them. cipType is the
+// tag's CIP type code; for structures pass CIPTypeStruct and start data with
+// the 2-byte structure handle, as returned by ReadRaw. Values larger than one
+// packet are written with Write Tag Fragmented.
+func (c *EipClient) WriteRaw(tagName string, cipType uint16, data []byte) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.auditedAs(tagName, fmt.Sprintf("CIP 0x%04X", cipType), data, func() error { return c.writeRaw(tagName, cipType, data) })
+	})
+}
+
+// writeRaw is WriteRaw without auditing
+func (c *EipClient) writeRaw(tagName string, cipType uint16, data []byte) error {
+	path, err := tagRequestPath(tagName)
+	if err != nil {
+		return err
+	}
+	header, value, err := splitRawValue(cipType, data)
+	if err != nil {
+		return err
+	}
+
+	requests := writeRawRequests(header, value, len(path))
+	if len(requests) == 1 {
+		// Type, element count and value
+		req := append(append([]byte{}, header...), 0x01, 0x00)
+		_, err := c.SendCIPMessage(CIPServiceWriteTag, path, append(req, value...))
+		return err
+	}
+	for _, req := range requests {
+		if _, err := c.SendCIPMessage(CIPServiceWriteTagFragmented, path, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rawFragment is the data of one Read Tag (Fragmented) reply
+type rawFragment struct {
+	handle []byte // Structure handle, empty for other types
+	value  []byte
+}
+
+// splitRawReply splits a read reply into its structure handle and value bytes
+func splitRawReply(data []byte) (rawFragment, error) {
+	if len(data) < 2 {
+		return rawFragment{}, NewEipError(ErrInvalidValue, "Read Tag reply too short")
+	}
+	headerLen := 2
+	if binary.LittleEndian.Uint16(data) == CIPTypeStruct {
+		headerLen = 4
+	}
+	if len(data) < headerLen {
+		return rawFragment{}, NewEipError(ErrInvalidValue, "Read Tag reply missing structure handle")
+	}
+	return rawFragment{handle: append([]byte{}, data[2:headerLen]...), value: data[headerLen:]}, nil
+}
+
+// rawValueLen returns the number of value bytes in data accumulated by ReadRaw,
+// which is the byte offset of the next fragment
+func rawValueLen(code uint16, data []byte) int {
+	if code == CIPTypeStruct {
+		return len(data) - 2
+	}
+	return len(data)
+}
+
+// splitRawValue builds the type header of a write and separates the
+// structure handle from the value bytes
+func splitRawValue(cipType uint16, data []byte) (header, value []byte, err error) {
+	header = binary.LittleEndian.AppendUint16(nil, cipType)
+	if cipType != CIPTypeStruct {
+		if len(data) == 0 {
+			return nil, nil, NewEipError(ErrInvalidTagValue, "no data to write")
+		}
+		return header, data, nil
+	}
+	if len(data) < 3 {
+		return nil, nil, NewEipError(ErrInvalidTagValue,
+			fmt.Sprintf("structure data must start with a 2-byte structure handle, got %d bytes", len(data)))
+	}
+	return append(header, data[:2]...), data[2:], nil
+}
+
+// writeRawRequests encodes the request data of Write Tag Fragmented requests
+// for value, each small enough for an unconnected message. A single request
+// means the value fits in a plain Write Tag.
+func writeRawRequests(header, value []byte, pathLen int) [][]byte {
+	// Service, path size, path, type header, element count, byte offset
+	overhead := 2 + pathLen + len(header) + 2 + 4
+	chunk := (maxUnconnectedMessageSize - overhead) / 4 * 4
+	var requests [][]byte
+	for offset := 0; offset < len(value); offset += chunk {
+		end := offset + chunk
+		if end > len(value) {
+			end = len(value)
+		}
+		req := append([]byte{}, header...)
+		req = binary.LittleEndian.AppendUint16(req, 1)
+		req = binary.LittleEndian.AppendUint32(req, uint32(offset))
+		requests = append(requests, append(req, value[offset:end]...))
+	}
+	return requests
+}
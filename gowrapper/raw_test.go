@@ -0,0 +1,77 @@
+package ethernetip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// TestSplitRawReply tests separating the type header from raw value bytes
+func TestSplitRawReply(t *testing.T) {
+	fragment, err := splitRawReply([]byte{0xC4, 0x00, 0x2A, 0x00, 0x00, 0x00})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fragment.handle) != 0 || !bytes.Equal(fragment.value, []byte{0x2A, 0, 0, 0}) {
+		t.Errorf("Unexpected DINT fragment %+v", fragment)
+	}
+
+	fragment, err = splitRawReply([]byte{0xA0, 0x02, 0x34, 0x12, 0x01, 0x02})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(fragment.handle, []byte{0x34, 0x12}) || !bytes.Equal(fragment.value, []byte{1, 2}) {
+		t.Errorf("Unexpected structure fragment %+v", fragment)
+	}
+	if rawValueLen(CIPTypeStruct, []byte{0x34, 0x12, 1, 2}) != 2 {
+		t.Error("Expected the structure handle to be excluded from the offset")
+	}
+
+	if _, err := splitRawReply([]byte{0xA0, 0x02, 0x34}); err == nil {
+		t.Error("Expected error for missing structure handle")
+	}
+}
+
+// TestWriteRawRequests tests raw write encoding for scalars and large structures
+func TestWriteRawRequests(t *testing.T) {
+	header, value, err := splitRawValue(CIPTypeDint, []byte{1, 0, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests := writeRawRequests(header, value, 6); len(requests) != 1 {
+		t.Errorf("Expected a DINT to fit in one request, got %d", len(requests))
+	}
+
+	body := make([]byte, 1000)
+	header, value, err = splitRawValue(CIPTypeStruct, append([]byte{0xCE, 0x0F}, body...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := writeRawRequests(header, value, 10)
+	if len(requests) < 3 {
+		t.Fatalf("Expected 1000 bytes to be split, got %d request(s)", len(requests))
+	}
+	total := 0
+	for _, req := range requests {
+		if 2+10+len(req) > maxUnconnectedMessageSize {
+			t.Errorf("Request of %d bytes exceeds the packet limit", len(req))
+		}
+		if binary.LittleEndian.Uint16(req) != CIPTypeStruct || binary.LittleEndian.Uint16(req[2:]) != 0x0FCE {
+			t.Errorf("Unexpected header % X", req[:4])
+		}
+		if got := int(binary.LittleEndian.Uint32(req[6:])); got != total {
+			t.Errorf("Expected offset %d, got %d", total, got)
+		}
+		total += len(req) - 10
+	}
+	if total != 1000 {
+		t.Errorf("Expected 1000 bytes written, got %d", total)
+	}
+
+	if _, _, err := splitRawValue(CIPTypeStruct, []byte{0xCE}); err == nil {
+		t.Error("Expected error for structure data without handle")
+	}
+	if _, _, err := splitRawValue(CIPTypeDint, nil); err == nil {
+		t.Error("Expected error for empty data")
+	}
+}
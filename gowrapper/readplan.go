@@ -0,0 +1,573 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/batch"
+)
+
+// ReadItem is a tag to read as part of a ReadPlan
+type ReadItem struct {
+	// TagName is a tag, UDT member or array element, e.g. "Motor.Speed" or
+	// "Recipe.Steps[10]"
+	TagName  string      `json:"tag_name"`
+	DataType PlcDataType `json:"data_type"`
+	// Count is the number of array elements to read starting at TagName; 0 or
+	// 1 reads a single value
+	Count int `json:"count,omitempty"`
+}
+
+// elements returns the number of elements the item reads
+func (item ReadItem) elements() int {
+	if item.Count < 1 {
+		return 1
+	}
+	return item.Count
+}
+
+// ReadStepKind is the kind of request a ReadStep sends
+type ReadStepKind int
+
+const (
+	// ReadStepTag is a single Read Tag request
+	ReadStepTag ReadStepKind = iota
+	// ReadStepMultiple packs several Read Tag requests in one Multiple Service Packet
+	ReadStepMultiple
+	// ReadStepFragmented reads an array too large for one packet with Read Tag
+	// Fragmented, one round trip per packet
+	ReadStepFragmented
+)
+
+// String returns the name of the step kind
+func (k ReadStepKind) String() string {
+	switch k {
+	case ReadStepTag:
+		return "read_tag"
+	case ReadStepMultiple:
+		return "multiple_service_packet"
+	case ReadStepFragmented:
+		return "read_tag_fragmented"
+	default:
+		return fmt.Sprintf("ReadStepKind(%d)", int(k))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (k ReadStepKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// ReadStep is one request of a ReadPlan
+type ReadStep struct {
+	Kind  ReadStepKind `json:"kind"`
+	Items []ReadItem   `json:"items"`
+	// RequestSize and ReplySize are the expected message sizes in bytes; for
+	// fragmented steps ReplySize is the total data size
+	RequestSize int `json:"request_size"`
+	ReplySize   int `json:"reply_size"`
+	// RoundTrips is the number of requests the step sends each scan
+	RoundTrips int `json:"round_trips"`
+	// Reason explains why the items were planned this way
+	Reason string `json:"reason"`
+
+	path    []byte // Request path of single-item steps
+	request []byte // Encoded request data
+}
+
+// ReadPlan is a compiled set of reads. Compiling once and calling Read every
+// scan avoids re-encoding requests, and the plan can be inspected (Steps,
+// String) to see how many round trips a scan costs and why.
+type ReadPlan struct {
+	Steps []ReadStep `json:"steps"`
+
+	client *EipClient
+	// requested holds the names each tag was requested under when items
+	// were named by alias, see resolveAliases
+	requested map[string][]string
+}
+
+// readItemRequest is an item's encoded Read Tag request and expected reply size
+type readItemRequest struct {
+	item      ReadItem
+	path      []byte
+	request   []byte
+	replySize int
+	group     int // Order of the item's parent structure, see compileReadPlan
+}
+
+// CompileReadPlan compiles a plan for reading items. Members of the same
+// structure are kept next to each other and reads are packed into as few
+// Multiple Service Packets as fit, while arrays whose reply exceeds a packet
+// are read with Read Tag Fragmented. Items named by an alias read its tag,
+// and Read returns their values under the alias.
+func (c *EipClient) CompileReadPlan(items []ReadItem) (*ReadPlan, error) {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.TagName
+	}
+	tags, requested := c.resolveAliases(names)
+	if requested != nil {
+		resolved := make([]ReadItem, 0, len(tags))
+		byTag := make(map[string]ReadItem, len(tags))
+		seen := make(map[string]bool, len(items))
+		for _, item := range items {
+			if seen[item.TagName] {
+				return nil, NewEipError(ErrInvalidTagName, fmt.Sprintf("duplicate read plan item '%s'", item.TagName))
+			}
+			seen[item.TagName] = true
+			tagName := c.ResolveAlias(item.TagName)
+			if other, ok := byTag[tagName]; ok {
+				if other.DataType != item.DataType || other.elements() != item.elements() {
+					return nil, NewEipErrorWithDetails(ErrInvalidDataType,
+						fmt.Sprintf("'%s' and '%s' read '%s' differently", other.TagName, item.TagName, tagName),
+						map[string]interface{}{"tag_name": tagName})
+				}
+				continue
+			}
+			byTag[tagName] = item
+			item.TagName = tagName
+			resolved = append(resolved, item)
+		}
+		items = resolved
+	}
+
+	plan, err := compileReadPlan(items)
+	if err != nil {
+		return nil, err
+	}
+	plan.client = c
+	plan.requested = requested
+	return plan, nil
+}
+
+// compileReadPlan builds the steps of a plan for items
+func compileReadPlan(items []ReadItem) (*ReadPlan, error) {
+	if len(items) == 0 {
+		return nil, NewEipError(ErrInvalidOperation, "read plan must have at least one item")
+	}
+
+	seen := make(map[string]bool, len(items))
+	groups := make(map[string]int)
+	plan := &ReadPlan{}
+	var packable []readItemRequest
+	for _, item := range items {
+		if seen[item.TagName] {
+			return nil, NewEipError(ErrInvalidTagName, fmt.Sprintf("duplicate read plan item '%s'", item.TagName))
+		}
+		seen[item.TagName] = true
+
+		req, err := encodeReadItem(item)
+		if err != nil {
+			return nil, err
+		}
+		if 4+req.replySize > maxUnconnectedMessageSize {
+			// Reply header plus type word and data would overflow one packet
+			payload := req.replySize - 2
+			perTrip := maxUnconnectedMessageSize - 4 - 2
+			plan.Steps = append(plan.Steps, ReadStep{
+				Kind:        ReadStepFragmented,
+				Items:       []ReadItem{item},
+				RequestSize: 2 + len(req.path) + 6,
+				ReplySize:   payload,
+				RoundTrips:  (payload + perTrip - 1) / perTrip,
+				Reason:      fmt.Sprintf("reply of %d bytes exceeds the %d-byte packet limit", 4+req.replySize, maxUnconnectedMessageSize),
+				path:        req.path,
+			})
+			continue
+		}
+
+		parent := structureOf(item.TagName)
+		group, ok := groups[parent]
+		if !ok {
+			group = len(groups)
+			groups[parent] = group
+		}
+		req.group = group
+		packable = append(packable, req)
+	}
+
+	// Keep members of one structure together, in the order first seen
+	sort.SliceStable(packable, func(i, j int) bool { return packable[i].group < packable[j].group })
+
+	type packet struct {
+		reqs        []readItemRequest
+		requestSize int
+		replySize   int
+	}
+	// Sizes of an empty Multiple Service Packet: service, path size, Message
+	// Router path and service count; reply header and service count
+	emptyRequest := 2 + len(classInstancePath(CIPClassMessageRouter, 1)) + 2
+	emptyReply := 4 + 2
+	var packets []*packet
+	for _, req := range packable {
+		var target *packet
+		for _, p := range packets {
+			if p.requestSize+2+len(req.request) <= maxUnconnectedMessageSize &&
+				p.replySize+2+4+req.replySize <= maxUnconnectedMessageSize {
+				target = p
+				break
+			}
+		}
+		if target == nil {
+			target = &packet{requestSize: emptyRequest, replySize: emptyReply}
+			packets = append(packets, target)
+		}
+		target.reqs = append(target.reqs, req)
+		target.requestSize += 2 + len(req.request)
+		target.replySize += 2 + 4 + req.replySize
+	}
+
+	for _, p := range packets {
+		if len(p.reqs) == 1 {
+			req := p.reqs[0]
+			plan.Steps = append(plan.Steps, ReadStep{
+				Kind:        ReadStepTag,
+				Items:       []ReadItem{req.item},
+				RequestSize: len(req.request),
+				ReplySize:   4 + req.replySize,
+				RoundTrips:  1,
+				Reason:      "single read",
+				path:        req.path,
+				request:     req.request[2+len(req.path):],
+			})
+			continue
+		}
+		step := ReadStep{
+			Kind:        ReadStepMultiple,
+			RequestSize: p.requestSize,
+			ReplySize:   p.replySize,
+			RoundTrips:  1,
+			Reason:      fmt.Sprintf("%d reads share one packet", len(p.reqs)),
+		}
+		requests := make([][]byte, len(p.reqs))
+		for i, req := range p.reqs {
+			step.Items = append(step.Items, req.item)
+			requests[i] = req.request
+		}
+		step.request = buildMultipleServicePacket(requests)
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	// Packed reads first, then the fragmented ones
+	sort.SliceStable(plan.Steps, func(i, j int) bool {
+		return plan.Steps[i].Kind != ReadStepFragmented && plan.Steps[j].Kind == ReadStepFragmented
+	})
+	return plan, nil
+}
+
+// encodeReadItem validates item and encodes its Read Tag request
+func encodeReadItem(item ReadItem) (readItemRequest, error) {
+	if item.Count < 0 || item.Count > 0xFFFF {
+		return readItemRequest{}, NewEipError(ErrInvalidTagLength,
+			fmt.Sprintf("invalid element count %d for '%s'", item.Count, item.TagName))
+	}
+	_, size, ok := cipTypeInfo(item.DataType)
+	if !ok {
+		return readItemRequest{}, NewEipError(ErrInvalidDataType, fmt.Sprintf("unsupported data type for '%s'", item.TagName))
+	}
+	if item.elements() > 1 && (item.DataType == Bool || item.DataType == String) {
+		return readItemRequest{}, NewEipError(ErrInvalidDataType,
+			fmt.Sprintf("array reads of %s are not supported for '%s'", item.DataType, item.TagName))
+	}
+	path, err := tagRequestPath(item.TagName)
+	if err != nil {
+		return readItemRequest{}, err
+	}
+	// Read Tag: service, path size, path, element count
+	req := append([]byte{CIPServiceReadTag, byte(len(path) / 2)}, path...)
+	req = binary.LittleEndian.AppendUint16(req, uint16(item.elements()))
+	return readItemRequest{
+		item:      item,
+		path:      path,
+		request:   req,
+		replySize: 2 + size*item.elements(),
+	}, nil
+}
+
+// structureOf returns the structure containing a tag, e.g. "Motors[2]" for
+// "Motors[2].Speed", or "" for a top-level tag
+func structureOf(tagName string) string {
+	if i := strings.LastIndex(tagName, "."); i > 0 {
+		return tagName[:i]
+	}
+	return ""
+}
+
+// RoundTrips returns the number of requests a Read sends
+func (p *ReadPlan) RoundTrips() int {
+	n := 0
+	for _, step := range p.Steps {
+		n += step.RoundTrips
+	}
+	return n
+}
+
+// Items returns the number of items in the plan
+func (p *ReadPlan) Items() int {
+	n := 0
+	for _, step := range p.Steps {
+		n += len(step.Items)
+	}
+	return n
+}
+
+// String describes the plan, one line per step followed by its items
+func (p *ReadPlan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "read plan: %d items in %d steps, %d round trips\n", p.Items(), len(p.Steps), p.RoundTrips())
+	for i, step := range p.Steps {
+		fmt.Fprintf(&b, "  %d. %s: request %d bytes, reply %d bytes, %d round trip(s) (%s)\n",
+			i+1, step.Kind, step.RequestSize, step.ReplySize, step.RoundTrips, step.Reason)
+		for _, item := range step.Items {
+			if item.elements() > 1 {
+				fmt.Fprintf(&b, "       %s %s x%d\n", item.TagName, item.DataType, item.Count)
+			} else {
+				fmt.Fprintf(&b, "       %s %s\n", item.TagName, item.DataType)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Read executes the plan. Array items are returned as []interface{} values.
+// Items that fail are left out of the result and reported together in an
+// ErrBatchOperationFailed error, so one bad tag does not hide the others.
+func (p *ReadPlan) Read() (map[string]*PlcValue, error) {
+	if p.client == nil {
+		return nil, NewEipError(ErrInvalidOperation, "read plan has no client; use EipClient.CompileReadPlan")
+	}
+	var values map[string]*PlcValue
+	err := p.client.submit(OperationRead, fmt.Sprintf("%d tags", p.Items()), func() (err error) {
+		values, err = p.readAll()
+		return err
+	})
+	return values, err
+}
+
+// readAll is Read without queuing
+func (p *ReadPlan) readAll() (map[string]*PlcValue, error) {
+	values, errs := p.execute()
+	values = batch.ByRequestedName(values, p.requested)
+	if len(errs) > 0 {
+		failed := p.failures(errs)
+		return values, NewEipErrorWithDetails(ErrBatchOperationFailed,
+			"read plan failed: "+strings.Join(failed, ", "),
+			map[string]interface{}{"failed_items": failed})
+	}
+	return values, nil
+}
+
+// ReadTags reads a set of tags with as few requests as a ReadPlan packs them
+// in, reading strings, bits and virtual tags, which cannot be packed, one by
+// one. Aliases are resolved and their values keyed by the alias. It returns
+// the values read and the error of each tag that failed, so one bad tag does
+// not hide the others. The packed reads are queued as one
+// operation (see QueueStats).
+func (c *EipClient) ReadTags(tags map[string]PlcDataType) (map[string]*PlcValue, map[string]error) {
+	values := make(map[string]*PlcValue, len(tags))
+	errs := make(map[string]error)
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var items []ReadItem
+	for _, name := range names {
+		item := ReadItem{TagName: name, DataType: tags[name]}
+		if tagName, ok := c.packable(name, item.DataType); ok {
+			if _, err := encodeReadItem(ReadItem{TagName: tagName, DataType: item.DataType}); err == nil {
+				items = append(items, item)
+				continue
+			}
+		}
+		value, err := c.ReadValue(name, item.DataType)
+		if err != nil {
+			errs[name] = err
+			continue
+		}
+		values[name] = value
+	}
+	if len(items) == 0 {
+		return values, errs
+	}
+
+	plan, err := c.CompileReadPlan(items)
+	if err == nil {
+		err = c.submit(OperationRead, fmt.Sprintf("%d tags", len(items)), func() error {
+			read, failed := plan.read()
+			for name, value := range read {
+				values[name] = value
+			}
+			for name, err := range failed {
+				errs[name] = err
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		failItems(items, err, errs)
+	}
+	return values, errs
+}
+
+// read executes the plan, returning the values read and the error of each
+// item that failed, keyed by the names requested
+func (p *ReadPlan) read() (map[string]*PlcValue, map[string]error) {
+	values, errs := p.execute()
+	return batch.ByRequestedName(values, p.requested), batch.ByRequestedName(errs, p.requested)
+}
+
+// execute runs the steps of the plan, returning the values read and the
+// errors keyed by the tags read
+func (p *ReadPlan) execute() (map[string]*PlcValue, map[string]error) {
+	values := make(map[string]*PlcValue, p.Items())
+	errs := make(map[string]error)
+	for i := range p.Steps {
+		p.Steps[i].execute(p.client, values, errs)
+	}
+	return values, errs
+}
+
+// failures describes the items that failed, in plan order, given the errors
+// returned by execute
+func (p *ReadPlan) failures(errs map[string]error) []string {
+	var failed []string
+	for _, step := range p.Steps {
+		for _, item := range step.Items {
+			err, ok := errs[item.TagName]
+			if !ok {
+				continue
+			}
+			reason := err.Error()
+			var eipErr *EipError
+			if errors.As(err, &eipErr) {
+				reason = eipErr.Message
+			}
+			failed = append(failed, fmt.Sprintf("%s (%s)", item.TagName, reason))
+		}
+	}
+	return failed
+}
+
+// execute sends the step and stores the values it read and the error of
+// each item that failed
+func (s *ReadStep) execute(c *EipClient, values map[string]*PlcValue, errs map[string]error) {
+	switch s.Kind {
+	case ReadStepFragmented:
+		item := s.Items[0]
+		code, data, err := c.readFragmented(s.path, item.elements())
+		if err != nil {
+			failItems(s.Items, err, errs)
+			return
+		}
+		value, err := decodeArrayItem(item, code, data)
+		if err != nil {
+			failItems(s.Items, err, errs)
+			return
+		}
+		values[item.TagName] = value
+	case ReadStepTag:
+		resp, err := c.SendCIPMessage(CIPServiceReadTag, s.path, s.request)
+		if err != nil {
+			if resp != nil {
+				err = readItemStatusError(s.Items[0], resp.GeneralStatus)
+			}
+			failItems(s.Items, err, errs)
+			return
+		}
+		value, err := decodeReadItem(s.Items[0], resp.Data)
+		if err != nil {
+			failItems(s.Items, err, errs)
+			return
+		}
+		values[s.Items[0].TagName] = value
+	default:
+		resp, err := c.SendCIPMessage(CIPServiceMultipleServicePacket,
+			classInstancePath(CIPClassMessageRouter, 1), s.request)
+		if err != nil && (resp == nil || resp.GeneralStatus != CIPStatusEmbeddedService) {
+			failItems(s.Items, err, errs)
+			return
+		}
+		s.decodeMultiple(resp.Data, values, errs)
+	}
+}
+
+// decodeMultiple stores the values of a Multiple Service Packet reply and
+// the error of each item that failed
+func (s *ReadStep) decodeMultiple(data []byte, values map[string]*PlcValue, errs map[string]error) {
+	replies, err := parseMultipleServiceReply(data)
+	if err == nil && len(replies) != len(s.Items) {
+		err = NewEipErrorWithDetails(ErrInvalidOperation, "read plan reply count mismatch",
+			map[string]interface{}{"expected": len(s.Items), "actual": len(replies)})
+	}
+	if err != nil {
+		failItems(s.Items, err, errs)
+		return
+	}
+
+	for i, item := range s.Items {
+		reply := replies[i]
+		if reply.GeneralStatus != CIPStatusSuccess {
+			errs[item.TagName] = readItemStatusError(item, reply.GeneralStatus)
+			continue
+		}
+		value, err := decodeReadItem(item, reply.Data)
+		if err != nil {
+			errs[item.TagName] = err
+			continue
+		}
+		values[item.TagName] = value
+	}
+}
+
+// readItemStatusError is the error of an item whose Read Tag reply failed
+// with a CIP status; path errors mean the tag does not exist
+func readItemStatusError(item ReadItem, status byte) error {
+	code := ErrInvalidOperation
+	if status == 0x04 || status == 0x05 {
+		code = ErrTagNotFound
+	}
+	return NewEipErrorWithDetails(code, fmt.Sprintf("status 0x%02X", status),
+		map[string]interface{}{"tag_name": item.TagName, "cip_status": status})
+}
+
+// failItems records err as the error of every item
+func failItems(items []ReadItem, err error, errs map[string]error) {
+	for _, item := range items {
+		errs[item.TagName] = err
+	}
+}
+
+// decodeReadItem decodes the data of an item's Read Tag reply
+func decodeReadItem(item ReadItem, data []byte) (*PlcValue, error) {
+	if item.elements() == 1 {
+		value, err := decodeTagValue(item.DataType, data)
+		if err != nil {
+			return nil, err
+		}
+		return &PlcValue{Type: item.DataType, Value: value}, nil
+	}
+	if len(data) < 2 {
+		return nil, NewEipError(ErrInvalidValue, "Read Tag reply too short")
+	}
+	return decodeArrayItem(item, binary.LittleEndian.Uint16(data), data[2:])
+}
+
+// decodeArrayItem decodes the elements of an array item and checks their type
+func decodeArrayItem(item ReadItem, code uint16, data []byte) (*PlcValue, error) {
+	dataType, elements, err := decodeArrayElements(code, data, item.elements())
+	if err != nil {
+		return nil, err
+	}
+	if dataType != item.DataType {
+		return nil, NewEipErrorWithDetails(ErrInvalidDataType,
+			fmt.Sprintf("'%s' is %s, not %s", item.TagName, dataType, item.DataType),
+			map[string]interface{}{"expected": item.DataType.String(), "actual": dataType.String()})
+	}
+	return &PlcValue{Type: dataType, Value: elements}, nil
+}
@@ -0,0 +1,165 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestCompileReadPlanPacking tests that small reads share packets and large
+// arrays are fragmented
+func TestCompileReadPlanPacking(t *testing.T) {
+	items := []ReadItem{
+		{TagName: "Motor1.Speed", DataType: Real},
+		{TagName: "Counter", DataType: Dint},
+		{TagName: "Motor1.Running", DataType: Bool},
+		{TagName: "Recipe.Steps[0]", DataType: Dint, Count: 500},
+		{TagName: "Setpoints[4]", DataType: Real, Count: 10},
+	}
+	plan, err := compileReadPlan(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Steps) != 2 {
+		t.Fatalf("Expected 2 steps, got %d:\n%s", len(plan.Steps), plan)
+	}
+
+	packed := plan.Steps[0]
+	if packed.Kind != ReadStepMultiple || len(packed.Items) != 4 {
+		t.Fatalf("Expected 4 reads in one packet, got %s with %d", packed.Kind, len(packed.Items))
+	}
+	if packed.Items[0].TagName != "Motor1.Speed" || packed.Items[1].TagName != "Motor1.Running" {
+		t.Errorf("Expected members of Motor1 to be adjacent, got %v", packed.Items)
+	}
+	if packed.RequestSize > maxUnconnectedMessageSize || packed.ReplySize > maxUnconnectedMessageSize {
+		t.Errorf("Packet exceeds limit: %d/%d", packed.RequestSize, packed.ReplySize)
+	}
+	if count := binary.LittleEndian.Uint16(packed.request); count != 4 {
+		t.Errorf("Expected 4 embedded services, got %d", count)
+	}
+
+	fragmented := plan.Steps[1]
+	if fragmented.Kind != ReadStepFragmented || fragmented.RoundTrips != 5 {
+		t.Errorf("Expected 2000 bytes in 5 fragmented round trips, got %s in %d", fragmented.Kind, fragmented.RoundTrips)
+	}
+	if plan.RoundTrips() != 6 || plan.Items() != 5 {
+		t.Errorf("Unexpected totals %d round trips, %d items", plan.RoundTrips(), plan.Items())
+	}
+	if !strings.Contains(plan.String(), "exceeds the 504-byte packet limit") {
+		t.Errorf("Plan description lacks the fragmentation reason:\n%s", plan)
+	}
+}
+
+// TestCompileReadPlanSplitsPackets tests that reads overflowing one packet
+// are spread over several
+func TestCompileReadPlanSplitsPackets(t *testing.T) {
+	var items []ReadItem
+	for i := 0; i < 10; i++ {
+		items = append(items, ReadItem{TagName: "Name" + string(rune('A'+i)), DataType: String})
+	}
+	plan, err := compileReadPlan(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Steps) < 2 {
+		t.Fatalf("Expected 10 STRING reads to need several packets, got:\n%s", plan)
+	}
+	for _, step := range plan.Steps {
+		if step.ReplySize > maxUnconnectedMessageSize {
+			t.Errorf("Step reply of %d bytes exceeds limit", step.ReplySize)
+		}
+	}
+	if plan.Items() != 10 {
+		t.Errorf("Expected 10 items, got %d", plan.Items())
+	}
+}
+
+// TestCompileReadPlanSingle tests that a lone read is sent without a Multiple
+// Service Packet
+func TestCompileReadPlanSingle(t *testing.T) {
+	plan, err := compileReadPlan([]ReadItem{{TagName: "Counter", DataType: Dint}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Steps) != 1 || plan.Steps[0].Kind != ReadStepTag {
+		t.Fatalf("Expected a single Read Tag step, got:\n%s", plan)
+	}
+	if got := plan.Steps[0].request; len(got) != 2 || got[0] != 1 {
+		t.Errorf("Unexpected request data % X", got)
+	}
+}
+
+// TestCompileReadPlanErrors tests validation of plan items
+func TestCompileReadPlanErrors(t *testing.T) {
+	cases := [][]ReadItem{
+		nil,
+		{{TagName: "A", DataType: Dint}, {TagName: "A", DataType: Dint}},
+		{{TagName: "Flags", DataType: Bool, Count: 4}},
+		{{TagName: "A", DataType: Dint, Count: -1}},
+		{{TagName: "A", DataType: Udt}},
+		{{TagName: "A..B", DataType: Dint}},
+	}
+	for i, items := range cases {
+		if _, err := compileReadPlan(items); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+// TestReadStepDecodeMultiple tests decoding a packed reply with a failed item
+func TestReadStepDecodeMultiple(t *testing.T) {
+	step := ReadStep{
+		Kind: ReadStepMultiple,
+		Items: []ReadItem{
+			{TagName: "Counter", DataType: Dint},
+			{TagName: "Missing", DataType: Dint},
+			{TagName: "Setpoints[0]", DataType: Int, Count: 2},
+		},
+	}
+	replies := [][]byte{
+		{0xCC, 0x00, 0x00, 0x00, 0xC4, 0x00, 0x2A, 0x00, 0x00, 0x00},
+		{0xCC, 0x00, 0x04, 0x00},
+		{0xCC, 0x00, 0x00, 0x00, 0xC3, 0x00, 0x01, 0x00, 0x02, 0x00},
+	}
+	values := map[string]*PlcValue{}
+	errs := map[string]error{}
+	step.decodeMultiple(buildMultipleServicePacket(replies), values, errs)
+
+	var eipErr *EipError
+	if len(errs) != 1 || !errors.As(errs["Missing"], &eipErr) || eipErr.Code != ErrTagNotFound {
+		t.Errorf("Expected Missing to fail as not found, got %v", errs)
+	}
+	plan := &ReadPlan{Steps: []ReadStep{step}}
+	if failed := plan.failures(errs); len(failed) != 1 || failed[0] != "Missing (status 0x04)" {
+		t.Errorf("Unexpected failure descriptions %v", failed)
+	}
+	if v := values["Counter"]; v == nil || v.Value != int32(42) {
+		t.Errorf("Unexpected Counter %v", v)
+	}
+	elements, ok := values["Setpoints[0]"].Value.([]interface{})
+	if !ok || len(elements) != 2 || elements[1] != int16(2) {
+		t.Errorf("Unexpected array value %v", values["Setpoints[0]"])
+	}
+}
+
+// TestDecodeArrayItemTypeMismatch tests that arrays of the wrong type are rejected
+func TestDecodeArrayItemTypeMismatch(t *testing.T) {
+	item := ReadItem{TagName: "Setpoints[0]", DataType: Real, Count: 1}
+	if _, err := decodeArrayItem(item, CIPTypeDint, []byte{1, 0, 0, 0}); err == nil {
+		t.Error("Expected type mismatch error")
+	}
+}
+
+// TestReadTagsReportsEachTag tests that a failed read is reported for every
+// tag, packed or not, instead of failing the whole read
+func TestReadTagsReportsEachTag(t *testing.T) {
+	client := &EipClient{}
+	values, errs := client.ReadTags(map[string]PlcDataType{"Speed": Real, "Count": Dint, "Name": String})
+	if len(values) != 0 || len(errs) != 3 || errs["Speed"] == nil || errs["Name"] == nil {
+		t.Errorf("Expected an error for each tag offline, got %v %v", values, errs)
+	}
+	if stats := client.QueueStats(); stats.Submitted != 2 {
+		t.Errorf("Expected the packed reads queued as one operation next to the string, got %d", stats.Submitted)
+	}
+}
@@ -0,0 +1,501 @@
+package ethernetip
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recipe is a named set of tag values downloaded to the controller together,
+// such as the setpoints of one product
+type Recipe struct {
+	Name        string `json:"-"`
+	Description string `json:"description,omitempty"`
+	// Tags maps tag names to the values the recipe sets
+	Tags map[string]interface{} `json:"tags"`
+	// Types gives the data type of tags; tags without one use the type in
+	// the client's TagTypes
+	Types map[string]PlcDataType `json:"types,omitempty"`
+}
+
+// RecipeReport describes a recipe download tag by tag
+type RecipeReport struct {
+	Recipe   string           `json:"recipe"`
+	Results  []TagWriteResult `json:"results"` // Sorted by tag name
+	Written  int              `json:"written"`
+	Verified int              `json:"verified"`
+	Failed   int              `json:"failed"`
+}
+
+// OK reports whether every tag was written and verified
+func (r *RecipeReport) OK() bool {
+	return r.Failed == 0
+}
+
+// recipes is the client's registry of recipes, keyed by name
+type recipes struct {
+	mu sync.RWMutex
+	m  map[string]*Recipe
+}
+
+// DefineRecipe adds a recipe, replacing any recipe of the same name. Values
+// of tags whose type is known are checked against it; tags whose type is
+// only known at download time are checked then.
+func (c *EipClient) DefineRecipe(recipe Recipe) error {
+	defined, err := c.checkRecipe(recipe)
+	if err != nil {
+		return err
+	}
+	c.recipes.mu.Lock()
+	defer c.recipes.mu.Unlock()
+	if c.recipes.m == nil {
+		c.recipes.m = make(map[string]*Recipe)
+	}
+	c.recipes.m[defined.Name] = defined
+	return nil
+}
+
+// checkRecipe validates a recipe and returns a copy of it with its values
+// converted to the Go types of their data types
+func (c *EipClient) checkRecipe(recipe Recipe) (*Recipe, error) {
+	if recipe.Name == "" {
+		return nil, NewEipError(ErrInvalidValue, "recipe name cannot be empty")
+	}
+	if len(recipe.Tags) == 0 {
+		return nil, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("recipe '%s' has no tags", recipe.Name),
+			map[string]interface{}{"recipe": recipe.Name})
+	}
+	opts := c.TagNameOptions()
+	defined := &Recipe{
+		Name:        recipe.Name,
+		Description: recipe.Description,
+		Tags:        make(map[string]interface{}, len(recipe.Tags)),
+		Types:       make(map[string]PlcDataType, len(recipe.Types)),
+	}
+	for tagName, dataType := range recipe.Types {
+		if _, ok := recipe.Tags[tagName]; !ok {
+			return nil, NewEipErrorWithDetails(ErrInvalidTagName,
+				fmt.Sprintf("recipe '%s' has a type but no value for '%s'", recipe.Name, tagName),
+				map[string]interface{}{"recipe": recipe.Name, "tag_name": tagName})
+		}
+		defined.Types[opts.Clean(tagName)] = dataType
+	}
+	keys := make(map[string]string, len(recipe.Tags))
+	for tagName, value := range recipe.Tags {
+		cleaned := opts.Clean(tagName)
+		if cleaned == "" {
+			return nil, NewEipErrorWithDetails(ErrInvalidTagName, fmt.Sprintf("recipe '%s' has an empty tag name", recipe.Name),
+				map[string]interface{}{"recipe": recipe.Name})
+		}
+		if other, dup := keys[opts.Key(cleaned)]; dup {
+			return nil, NewEipErrorWithDetails(ErrInvalidTagName,
+				fmt.Sprintf("recipe '%s' sets '%s' and '%s', which name the same tag", recipe.Name, other, tagName),
+				map[string]interface{}{"recipe": recipe.Name, "tag_name": tagName})
+		}
+		keys[opts.Key(cleaned)] = tagName
+		if dataType, ok := defined.Types[cleaned]; ok {
+			converted, err := recipeValue(recipe.Name, cleaned, dataType, value)
+			if err != nil {
+				return nil, err
+			}
+			value = converted
+		}
+		defined.Tags[cleaned] = value
+	}
+	return defined, nil
+}
+
+// recipeValue converts a recipe value to the Go type used for dataType. It
+// accepts both JSON-decoded values and values of the Go type itself.
+func recipeValue(recipeName, tagName string, dataType PlcDataType, value interface{}) (interface{}, error) {
+	if dataType == Udt {
+		return nil, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("recipe '%s' cannot set UDT '%s'", recipeName, tagName),
+			map[string]interface{}{"recipe": recipeName, "tag_name": tagName})
+	}
+	converted, err := coerceValue(dataType, value)
+	if err == nil {
+		return converted, nil
+	}
+	if _, encErr := encodeElement(dataType, value); encErr == nil {
+		return value, nil
+	}
+	return nil, NewEipErrorWithDetails(ErrInvalidTagValue,
+		fmt.Sprintf("recipe '%s' has an invalid %s value for '%s': %v", recipeName, dataType, tagName, err),
+		map[string]interface{}{"recipe": recipeName, "tag_name": tagName, "type": dataType.String()})
+}
+
+// Recipe returns a copy of a defined recipe
+func (c *EipClient) Recipe(name string) (*Recipe, bool) {
+	c.recipes.mu.RLock()
+	defer c.recipes.mu.RUnlock()
+	recipe, ok := c.recipes.m[name]
+	if !ok {
+		return nil, false
+	}
+	copied := *recipe
+	copied.Tags = make(map[string]interface{}, len(recipe.Tags))
+	for tagName, value := range recipe.Tags {
+		copied.Tags[tagName] = value
+	}
+	copied.Types = make(map[string]PlcDataType, len(recipe.Types))
+	for tagName, dataType := range recipe.Types {
+		copied.Types[tagName] = dataType
+	}
+	return &copied, true
+}
+
+// Recipes returns the names of the defined recipes, sorted
+func (c *EipClient) Recipes() []string {
+	c.recipes.mu.RLock()
+	defer c.recipes.mu.RUnlock()
+	names := make([]string, 0, len(c.recipes.m))
+	for name := range c.recipes.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RemoveRecipe removes a recipe and reports whether it was defined
+func (c *EipClient) RemoveRecipe(name string) bool {
+	c.recipes.mu.Lock()
+	defer c.recipes.mu.Unlock()
+	_, ok := c.recipes.m[name]
+	delete(c.recipes.m, name)
+	return ok
+}
+
+// LoadRecipes defines the recipes of a JSON or YAML document mapping recipe
+// names to recipes:
+//
+//	Bread:
+//	  description: White loaf
+//	  tags:
+//	    Oven.Setpoint: 180
+//	    Mixer.Speed: 1200
+//	  types:
+//	    Oven.Setpoint: REAL
+//
+// YAML is limited to nested mappings of scalars and comments, which is what
+// a recipe needs. Nothing is defined if any recipe is invalid.
+func (c *EipClient) LoadRecipes(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read recipes: %w", err)
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		doc, err := parseYAML(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse recipes: %w", err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("failed to parse recipes: %w", err)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]Recipe
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("failed to parse recipes: %w", err)
+	}
+
+	defined := make([]*Recipe, 0, len(doc))
+	for name, recipe := range doc {
+		recipe.Name = name
+		checked, err := c.checkRecipe(recipe)
+		if err != nil {
+			return err
+		}
+		defined = append(defined, checked)
+	}
+	c.recipes.mu.Lock()
+	defer c.recipes.mu.Unlock()
+	if c.recipes.m == nil {
+		c.recipes.m = make(map[string]*Recipe)
+	}
+	for _, recipe := range defined {
+		c.recipes.m[recipe.Name] = recipe
+	}
+	return nil
+}
+
+// SaveRecipes writes the defined recipes as JSON that LoadRecipes reads back,
+// with type names instead of numbers
+func (c *EipClient) SaveRecipes(w io.Writer) error {
+	type savedRecipe struct {
+		Description string                 `json:"description,omitempty"`
+		Tags        map[string]interface{} `json:"tags"`
+		Types       map[string]string      `json:"types,omitempty"`
+	}
+	doc := make(map[string]savedRecipe)
+	for _, name := range c.Recipes() {
+		recipe, ok := c.Recipe(name)
+		if !ok {
+			continue
+		}
+		saved := savedRecipe{Description: recipe.Description, Tags: recipe.Tags, Types: make(map[string]string, len(recipe.Types))}
+		for tagName, dataType := range recipe.Types {
+			saved.Types[tagName] = dataType.String()
+			// TIME is saved as a duration string, which reads back exactly
+			if d, ok := saved.Tags[tagName].(time.Duration); ok && dataType == Time {
+				saved.Tags[tagName] = d.String()
+			}
+		}
+		doc[name] = saved
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// recipeItems returns the tags of a recipe with their types and values,
+// sorted by tag name. It fails if a type is unknown or a value invalid.
+func (c *EipClient) recipeItems(recipe *Recipe) ([]TagWriteResult, error) {
+	items := make([]TagWriteResult, 0, len(recipe.Tags))
+	for tagName, value := range recipe.Tags {
+		dataType, ok := recipe.Types[tagName]
+		if !ok {
+			if dataType, ok = c.tagTypes.Lookup(tagName); !ok {
+				return nil, NewEipErrorWithDetails(ErrInvalidDataType,
+					fmt.Sprintf("recipe '%s': no data type known for tag '%s'", recipe.Name, tagName),
+					map[string]interface{}{"recipe": recipe.Name, "tag_name": tagName})
+			}
+			converted, err := recipeValue(recipe.Name, tagName, dataType, value)
+			if err != nil {
+				return nil, err
+			}
+			value = converted
+		}
+		items = append(items, TagWriteResult{TagName: tagName, Type: dataType, Value: value})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].TagName < items[j].TagName })
+	return items, nil
+}
+
+// DownloadRecipe writes the values of a recipe to the controller and reads
+// them back, as described for Restore. The report gives the outcome of every
+// tag; if any tag was not written or verified, an ErrBatchOperationFailed
+// error naming them is returned with it. Nothing is written if a tag's type
+// is unknown or a value does not fit its type.
+func (c *EipClient) DownloadRecipe(name string) (*RecipeReport, error) {
+	recipe, ok := c.Recipe(name)
+	if !ok {
+		return nil, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("recipe '%s' is not defined", name),
+			map[string]interface{}{"recipe": name})
+	}
+	results, err := c.recipeItems(recipe)
+	if err != nil {
+		return nil, err
+	}
+	report := &RecipeReport{Recipe: name, Results: results}
+	var failed []string
+	report.Written, report.Verified, failed = c.writeTagsVerified(results)
+	report.Failed = len(failed)
+	return report, bindingError(fmt.Sprintf("recipe '%s' download", name), failed)
+}
+
+// UploadRecipe reads the current values of tags from the controller and
+// stores them as recipe name, replacing its values. Without tag names, the
+// tags of the existing recipe are read. Types come from the existing recipe
+// or the client's TagTypes and are recorded in the uploaded recipe.
+func (c *EipClient) UploadRecipe(name string, tagNames ...string) (*Recipe, error) {
+	existing, ok := c.Recipe(name)
+	if !ok {
+		existing = &Recipe{Name: name}
+	}
+	if len(tagNames) == 0 {
+		for tagName := range existing.Tags {
+			tagNames = append(tagNames, tagName)
+		}
+		if len(tagNames) == 0 {
+			return nil, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("recipe '%s' is not defined and no tags were given", name),
+				map[string]interface{}{"recipe": name})
+		}
+	}
+
+	opts := c.TagNameOptions()
+	group := c.NewTagGroup()
+	types := make(map[string]PlcDataType, len(tagNames))
+	for _, tagName := range tagNames {
+		tagName = opts.Clean(tagName)
+		dataType, ok := existing.Types[tagName]
+		if !ok {
+			if dataType, ok = c.tagTypes.Lookup(tagName); !ok {
+				return nil, NewEipErrorWithDetails(ErrInvalidDataType,
+					fmt.Sprintf("recipe '%s': no data type known for tag '%s'", name, tagName),
+					map[string]interface{}{"recipe": name, "tag_name": tagName})
+			}
+		}
+		if err := group.Add(tagName, dataType); err != nil {
+			return nil, err
+		}
+		types[tagName] = dataType
+	}
+	values, err := group.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	uploaded := Recipe{Name: name, Description: existing.Description, Tags: make(map[string]interface{}, len(values)), Types: types}
+	for tagName, value := range values {
+		uploaded.Tags[tagName] = value.Value
+	}
+	if err := c.DefineRecipe(uploaded); err != nil {
+		return nil, err
+	}
+	recipe, _ := c.Recipe(name)
+	return recipe, nil
+}
+
+// yamlLine is a non-blank line of a YAML document without its comment
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// parseYAML parses the subset of YAML used by recipe files: nested block
+// mappings whose values are scalars, with comments. Numbers are returned as
+// json.Number so integers keep their precision.
+func parseYAML(data []byte) (map[string]interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(string(data), "\n") {
+		text := strings.TrimRight(stripYAMLComment(raw), " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if trimmed[0] == '\t' {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		lines = append(lines, yamlLine{num: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	doc, next, err := parseYAMLMapping(lines, 0, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", lines[next].num)
+	}
+	return doc, nil
+}
+
+// parseYAMLMapping parses the mapping whose keys are indented by indent,
+// starting at lines[i], and returns it with the index of the next line
+func parseYAMLMapping(lines []yamlLine, i, indent int) (map[string]interface{}, int, error) {
+	mapping := make(map[string]interface{})
+	for i < len(lines) && lines[i].indent == indent {
+		line := lines[i]
+		if line.text == "-" || strings.HasPrefix(line.text, "- ") {
+			return nil, 0, fmt.Errorf("line %d: lists are not supported", line.num)
+		}
+		key, rest, err := splitYAMLKey(line.text)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: %v", line.num, err)
+		}
+		if _, dup := mapping[key]; dup {
+			return nil, 0, fmt.Errorf("line %d: duplicate key %q", line.num, key)
+		}
+		i++
+		switch {
+		case rest != "":
+			if mapping[key], err = yamlScalar(rest); err != nil {
+				return nil, 0, fmt.Errorf("line %d: %v", line.num, err)
+			}
+		case i < len(lines) && lines[i].indent > indent:
+			if mapping[key], i, err = parseYAMLMapping(lines, i, lines[i].indent); err != nil {
+				return nil, 0, err
+			}
+		default:
+			mapping[key] = nil
+		}
+	}
+	if i < len(lines) && lines[i].indent > indent {
+		return nil, 0, fmt.Errorf("line %d: unexpected indentation", lines[i].num)
+	}
+	return mapping, i, nil
+}
+
+// splitYAMLKey splits "key: value" into its key and value. Keys may be
+// quoted and may contain colons not followed by a space, as in
+// "Program:Main.Speed".
+func splitYAMLKey(text string) (string, string, error) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+		key, err := yamlScalar(text[:end+2])
+		if err != nil {
+			return "", "", err
+		}
+		rest := strings.TrimLeft(text[end+2:], " ")
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", fmt.Errorf("expected ':' after key")
+		}
+		return key.(string), strings.TrimSpace(rest[1:]), nil
+	}
+	if i := strings.Index(text, ": "); i > 0 {
+		return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+2:]), nil
+	}
+	if strings.HasSuffix(text, ":") && len(text) > 1 {
+		return strings.TrimSpace(text[:len(text)-1]), "", nil
+	}
+	return "", "", fmt.Errorf("expected 'key: value', got %q", text)
+}
+
+// yamlScalar parses a scalar value: a quoted or plain string, a boolean,
+// null or a number
+func yamlScalar(text string) (interface{}, error) {
+	switch text[0] {
+	case '"':
+		return strconv.Unquote(text)
+	case '\'':
+		if len(text) < 2 || text[len(text)-1] != '\'' {
+			return nil, fmt.Errorf("unterminated string %s", text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case '{', '[', '&', '*', '!', '|', '>':
+		return nil, fmt.Errorf("unsupported YAML value %s", text)
+	}
+	switch text {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	}
+	if _, err := strconv.ParseFloat(text, 64); err == nil && json.Valid([]byte(text)) {
+		return json.Number(text), nil
+	}
+	return text, nil
+}
+
+// stripYAMLComment removes a comment: a '#' at the start of the line or
+// after a space, outside quotes
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,201 @@
+package ethernetip
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseYAML tests the YAML subset read by LoadRecipes
+func TestParseYAML(t *testing.T) {
+	doc, err := parseYAML([]byte(`
+# Recipes for line 1
+---
+Bread:
+  description: "White loaf # not a comment"
+  tags:
+    Program:Main.Speed: 1200   # RPM
+    'Oven.Setpoint': 180.5
+    Label: it's plain
+    Enabled: true
+    Count: -3
+    Missing:
+  empty:
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	want := map[string]interface{}{
+		"Bread": map[string]interface{}{
+			"description": "White loaf # not a comment",
+			"tags": map[string]interface{}{
+				"Program:Main.Speed": json.Number("1200"),
+				"Oven.Setpoint":      json.Number("180.5"),
+				"Label":              "it's plain",
+				"Enabled":            true,
+				"Count":              json.Number("-3"),
+				"Missing":            nil,
+			},
+			"empty": nil,
+		},
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("Parsed %#v, expected %#v", doc, want)
+	}
+
+	for _, bad := range []string{
+		"a:\n  - 1\n  - 2",
+		"a:\n    b: 1\n  c: 2",
+		"a: 1\n  b: 2",
+		"a:\n\tb: 1",
+		"a: 1\na: 2",
+		"just text",
+		"a: {b: 1}",
+		`"a: 1`,
+	} {
+		if _, err := parseYAML([]byte(bad)); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+// TestLoadRecipes tests loading, converting and saving recipes
+func TestLoadRecipes(t *testing.T) {
+	client := &EipClient{}
+	client.TagTypes().Set("Mixer.Speed", Dint)
+
+	err := client.LoadRecipes(strings.NewReader(`
+Bread:
+  description: White loaf
+  tags:
+    Oven.Setpoint: 180
+    Mixer.Speed: 1200
+    Oven.Bake: 25m
+  types:
+    Oven.Setpoint: REAL
+    Oven.Bake: TIME
+Rolls:
+  tags:
+    Oven.Setpoint: 200
+  types:
+    Oven.Setpoint: REAL
+`))
+	if err != nil {
+		t.Fatalf("Failed to load recipes: %v", err)
+	}
+	if names := client.Recipes(); !reflect.DeepEqual(names, []string{"Bread", "Rolls"}) {
+		t.Fatalf("Expected Bread and Rolls, got %v", names)
+	}
+	bread, _ := client.Recipe("Bread")
+	if bread.Description != "White loaf" || bread.Tags["Oven.Setpoint"] != 180.0 || bread.Tags["Oven.Bake"] != 25*time.Minute {
+		t.Errorf("Expected typed values, got %+v", bread)
+	}
+	items, err := client.recipeItems(bread)
+	if err != nil || len(items) != 3 || items[1].TagName != "Oven.Bake" || items[0].Type != Dint || items[0].Value != int32(1200) {
+		t.Errorf("Expected the speed resolved as DINT from TagTypes, got %+v (%v)", items, err)
+	}
+
+	// Saved recipes load back unchanged
+	var saved bytes.Buffer
+	if err := client.SaveRecipes(&saved); err != nil {
+		t.Fatalf("Failed to save recipes: %v", err)
+	}
+	reloaded := &EipClient{}
+	if err := reloaded.LoadRecipes(&saved); err != nil {
+		t.Fatalf("Failed to reload %s: %v", saved.String(), err)
+	}
+	again, _ := reloaded.Recipe("Bread")
+	if again.Tags["Oven.Setpoint"] != 180.0 || again.Tags["Oven.Bake"] != 25*time.Minute || again.Types["Oven.Bake"] != Time {
+		t.Errorf("Expected the saved recipe back, got %+v", again)
+	}
+
+	// Nothing is defined if any recipe is invalid
+	err = client.LoadRecipes(strings.NewReader(`{
+		"Cake": {"tags": {"Oven.Setpoint": 170}, "types": {"Oven.Setpoint": "REAL"}},
+		"Broken": {"tags": {"Count": 300}, "types": {"Count": "SINT"}}
+	}`))
+	var eipErr *EipError
+	if !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagValue {
+		t.Errorf("Expected ErrInvalidTagValue, got %v", err)
+	}
+	if _, ok := client.Recipe("Cake"); ok {
+		t.Error("Expected no recipe to be defined from an invalid document")
+	}
+
+	if !client.RemoveRecipe("Rolls") || client.RemoveRecipe("Rolls") {
+		t.Error("Expected RemoveRecipe to report whether the recipe was defined")
+	}
+}
+
+// TestDefineRecipe tests recipe validation and the errors raised before a
+// download sends anything
+func TestDefineRecipe(t *testing.T) {
+	client := &EipClient{}
+	client.tagNames = LogixTagNames
+
+	var eipErr *EipError
+	for _, recipe := range []Recipe{
+		{Tags: map[string]interface{}{"A": 1}},
+		{Name: "Empty"},
+		{Name: "Dup", Tags: map[string]interface{}{"Speed": 1, "speed": 2}},
+		{Name: "Orphan", Tags: map[string]interface{}{"A": 1}, Types: map[string]PlcDataType{"B": Dint}},
+		{Name: "Udt", Tags: map[string]interface{}{"A": 1}, Types: map[string]PlcDataType{"A": Udt}},
+	} {
+		if err := client.DefineRecipe(recipe); err == nil {
+			t.Errorf("Expected recipe %+v to be rejected", recipe)
+		}
+	}
+
+	// Values of the Go type are accepted as they are
+	err := client.DefineRecipe(Recipe{Name: "Native", Tags: map[string]interface{}{"Count": int32(7), "Name": "A1"},
+		Types: map[string]PlcDataType{"Count": Dint, "Name": String}})
+	if err != nil {
+		t.Fatalf("Failed to define recipe: %v", err)
+	}
+
+	if _, err := client.DownloadRecipe("Unknown"); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidValue {
+		t.Errorf("Expected ErrInvalidValue for an unknown recipe, got %v", err)
+	}
+	if err := client.DefineRecipe(Recipe{Name: "Untyped", Tags: map[string]interface{}{"Level": 1.5}}); err != nil {
+		t.Fatalf("Failed to define recipe: %v", err)
+	}
+	if _, err := client.DownloadRecipe("Untyped"); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidDataType {
+		t.Errorf("Expected ErrInvalidDataType for a tag without a type, got %v", err)
+	}
+	if _, err := client.UploadRecipe("Unknown"); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidValue {
+		t.Errorf("Expected ErrInvalidValue uploading an unknown recipe without tags, got %v", err)
+	}
+}
+
+// TestRecipeDownload downloads and uploads a recipe on a real PLC
+func TestRecipeDownload(t *testing.T) {
+	skipIfNoPlc(t)
+
+	client, err := NewClient(getTestPlcIP())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	err = client.DefineRecipe(Recipe{Name: "Test", Tags: map[string]interface{}{"TestDint": 42, "TestReal": 1.5},
+		Types: map[string]PlcDataType{"TestDint": Dint, "TestReal": Real}})
+	if err != nil {
+		t.Fatalf("Failed to define recipe: %v", err)
+	}
+	report, err := client.DownloadRecipe("Test")
+	if err != nil || !report.OK() || report.Verified != 2 {
+		t.Fatalf("Failed to download recipe: %+v (%v)", report, err)
+	}
+
+	if err := client.WriteValue("TestDint", &PlcValue{Type: Dint, Value: int32(7)}); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	uploaded, err := client.UploadRecipe("Test")
+	if err != nil || uploaded.Tags["TestDint"] != int32(7) {
+		t.Errorf("Expected the upload to capture 7, got %+v (%v)", uploaded, err)
+	}
+}
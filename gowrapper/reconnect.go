@@ -0,0 +1,196 @@
+package ethernetip
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// ReconnectReason is why the client replaced or re-opened its session
+type ReconnectReason int
+
+const (
+	// ReconnectKeepAliveFailure means the keep-alive health check found the
+	// session dead
+	ReconnectKeepAliveFailure ReconnectReason = iota + 1
+	// ReconnectEncapsulationError means the controller answered with an
+	// encapsulation error, such as an invalid session handle
+	ReconnectEncapsulationError
+	// ReconnectTCPReset means the TCP connection was reset or closed by the
+	// peer
+	ReconnectTCPReset
+	// ReconnectExplicitClose means the session had been closed on purpose:
+	// by the idle policy, or by the application calling Failover
+	ReconnectExplicitClose
+)
+
+// String returns the name of the reason
+func (r ReconnectReason) String() string {
+	switch r {
+	case ReconnectKeepAliveFailure:
+		return "keep_alive_failure"
+	case ReconnectEncapsulationError:
+		return "encapsulation_error"
+	case ReconnectTCPReset:
+		return "tcp_reset"
+	case ReconnectExplicitClose:
+		return "explicit_close"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalJSON encodes the reason as its name
+func (r ReconnectReason) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// ReconnectEvent records one reconnect of the client
+type ReconnectEvent struct {
+	Time   time.Time       `json:"time"`
+	Reason ReconnectReason `json:"reason"`
+	// Cause is the error that led to the reconnect, if one was given
+	Cause string `json:"cause,omitempty"`
+	// OldSession and NewSession are native client IDs; NewSession is 0 if
+	// the reconnect failed
+	OldSession int32 `json:"old_session"`
+	NewSession int32 `json:"new_session"`
+	// Warm reports whether a warm standby session was swapped in
+	Warm     bool          `json:"warm"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// DefaultReconnectHistory is how many reconnect events a client keeps
+const DefaultReconnectHistory = 64
+
+// reconnectLog is a ring buffer of the latest reconnect events with a count
+// of all events by reason, and the listeners of restored sessions
+type reconnectLog struct {
+	mu     sync.Mutex
+	events []ReconnectEvent
+	next   int
+	counts map[ReconnectReason]int64
+
+	listeners    map[int]func(ReconnectEvent)
+	nextListener int
+}
+
+// add records an event, overwriting the oldest once the buffer is full
+func (l *reconnectLog) add(event ReconnectEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.counts == nil {
+		l.counts = make(map[ReconnectReason]int64)
+	}
+	l.counts[event.Reason]++
+	if len(l.events) < DefaultReconnectHistory {
+		l.events = append(l.events, event)
+		return
+	}
+	l.events[l.next] = event
+	l.next = (l.next + 1) % len(l.events)
+}
+
+// history returns the events kept, oldest first
+func (l *reconnectLog) history() []ReconnectEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := make([]ReconnectEvent, 0, len(l.events))
+	events = append(events, l.events[l.next:]...)
+	return append(events, l.events[:l.next]...)
+}
+
+// listen registers fn and returns a function that removes it
+func (l *reconnectLog) listen(fn func(ReconnectEvent)) (remove func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.nextListener++
+	id := l.nextListener
+	if l.listeners == nil {
+		l.listeners = make(map[int]func(ReconnectEvent))
+	}
+	l.listeners[id] = fn
+	return func() {
+		l.mu.Lock()
+		delete(l.listeners, id)
+		l.mu.Unlock()
+	}
+}
+
+// notify calls every listener with event
+func (l *reconnectLog) notify(event ReconnectEvent) {
+	l.mu.Lock()
+	listeners := make([]func(ReconnectEvent), 0, len(l.listeners))
+	for _, fn := range l.listeners {
+		listeners = append(listeners, fn)
+	}
+	l.mu.Unlock()
+	for _, fn := range listeners {
+		fn(event)
+	}
+}
+
+// totals returns the number of events recorded by reason name
+func (l *reconnectLog) totals() map[string]int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	totals := make(map[string]int64, len(l.counts))
+	for reason, n := range l.counts {
+		totals[reason.String()] = n
+	}
+	return totals
+}
+
+// SessionDiagnostics describes the client's own session: how often it was
+// replaced and why
+type SessionDiagnostics struct {
+	Failovers  int64 `json:"failovers"`
+	IdleCloses int64 `json:"idle_closes"`
+	// Reconnects counts every reconnect by reason name, including those no
+	// longer in History
+	Reconnects map[string]int64 `json:"reconnects"`
+	// History holds the latest DefaultReconnectHistory reconnects, oldest
+	// first
+	History []ReconnectEvent `json:"history"`
+}
+
+// SessionDiagnostics returns the client's reconnect history. Unlike
+// Diagnostics, which includes it, it does not talk to the controller, so it
+// is available while the controller is unreachable.
+func (c *EipClient) SessionDiagnostics() SessionDiagnostics {
+	return SessionDiagnostics{
+		Failovers:  c.Failovers(),
+		IdleCloses: c.IdleCloses(),
+		Reconnects: c.reconnects.totals(),
+		History:    c.reconnects.history(),
+	}
+}
+
+// OnReconnect registers fn to be called whenever the client has a session
+// again after replacing or re-opening it: a failover, including one of the
+// keep-alive, or the re-open after an idle close. Failed reconnects are only
+// recorded in SessionDiagnostics. fn is called on the goroutine that
+// reconnected, possibly in the middle of an operation, so it must not block
+// or use the client; hand the event to another goroutine instead. Returns a
+// function that removes the listener.
+func (c *EipClient) OnReconnect(fn func(event ReconnectEvent)) (remove func()) {
+	return c.reconnects.listen(fn)
+}
+
+// recordReconnect adds a reconnect to the client's history and tells the
+// OnReconnect listeners if it succeeded. The controller is verified against
+// the target expectation again before the next write.
+func (c *EipClient) recordReconnect(event ReconnectEvent, cause, err error) {
+	c.resetTargetVerification()
+	if cause != nil {
+		event.Cause = cause.Error()
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	c.reconnects.add(event)
+	if err == nil {
+		c.reconnects.notify(event)
+	}
+}
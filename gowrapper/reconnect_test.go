@@ -0,0 +1,83 @@
+package ethernetip
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestReconnectLog tests that the ring buffer keeps the latest events in
+// order and counts every event
+func TestReconnectLog(t *testing.T) {
+	var l reconnectLog
+	start := time.Now()
+	for i := 0; i < DefaultReconnectHistory+3; i++ {
+		reason := ReconnectKeepAliveFailure
+		if i%2 == 1 {
+			reason = ReconnectTCPReset
+		}
+		l.add(ReconnectEvent{Time: start.Add(time.Duration(i) * time.Second), Reason: reason})
+	}
+
+	history := l.history()
+	if len(history) != DefaultReconnectHistory {
+		t.Fatalf("Expected %d events, got %d", DefaultReconnectHistory, len(history))
+	}
+	for i, event := range history {
+		if want := start.Add(time.Duration(i+3) * time.Second); !event.Time.Equal(want) {
+			t.Fatalf("Expected event %d at %v, got %v", i, want.Sub(start), event.Time.Sub(start))
+		}
+	}
+	totals := l.totals()
+	if totals["keep_alive_failure"] != 34 || totals["tcp_reset"] != 33 {
+		t.Errorf("Expected every event counted, got %v", totals)
+	}
+}
+
+// TestFailoverRecordsReason tests that failovers, successful or not, are
+// recorded with their reason and cause, and that successful ones are
+// reported to OnReconnect listeners
+func TestFailoverRecordsReason(t *testing.T) {
+	client := &EipClient{}
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client.SetClock(clock)
+	client.session.Store(-3)
+	client.warm = true
+	client.standby = -5
+
+	var restored []ReconnectEvent
+	remove := client.OnReconnect(func(event ReconnectEvent) { restored = append(restored, event) })
+	defer remove()
+
+	if err := client.FailoverFor(ReconnectEncapsulationError, errors.New("invalid session handle")); err != nil {
+		t.Fatalf("Failover failed: %v", err)
+	}
+	// Without a standby a new session is connected, which fails here
+	if err := client.Failover(); err == nil {
+		t.Fatal("Expected the failover to fail without a PLC")
+	}
+
+	session := client.SessionDiagnostics()
+	if len(session.History) != 2 || session.Failovers != 1 {
+		t.Fatalf("Expected 2 reconnects and 1 failover, got %+v", session)
+	}
+	first, second := session.History[0], session.History[1]
+	if first.Reason != ReconnectEncapsulationError || first.Cause != "invalid session handle" ||
+		first.OldSession != -3 || first.NewSession != -5 || !first.Warm || !first.Time.Equal(clock.Now()) {
+		t.Errorf("Unexpected first event %+v", first)
+	}
+	if second.Reason != ReconnectExplicitClose || second.NewSession != 0 || second.Error == "" {
+		t.Errorf("Expected the failed failover recorded with its error, got %+v", second)
+	}
+
+	if len(restored) != 1 || restored[0].NewSession != -5 {
+		t.Errorf("Expected only the successful failover reported to OnReconnect, got %+v", restored)
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil || !strings.Contains(string(data), `"reason":"encapsulation_error"`) {
+		t.Errorf("Expected reasons encoded by name, got %s (%v)", data, err)
+	}
+}
@@ -0,0 +1,405 @@
+package ethernetip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+)
+
+// Retry budget defaults
+const (
+	DefaultRetryInitialDelay = 100 * time.Millisecond
+	DefaultRetryMaxDelay     = 2 * time.Second
+)
+
+// RetryBudget bounds retries by elapsed time instead of attempt count, so the
+// worst-case latency of an operation is known up front: attempts are repeated
+// with exponential backoff until one succeeds, the budget is spent or the
+// context is done, whichever comes first. A retry is never started if its
+// backoff would end past the deadline. The zero value retries until the
+// context is done.
+type RetryBudget struct {
+	// Budget is the total time allowed for all attempts and waits; 0 leaves
+	// the limit to the context deadline
+	Budget time.Duration
+	// InitialDelay is the wait before the first retry; defaults to
+	// DefaultRetryInitialDelay
+	InitialDelay time.Duration
+	// MaxDelay caps the doubling wait between retries; defaults to
+	// DefaultRetryMaxDelay
+	MaxDelay time.Duration
+	// Retryable reports whether an error is worth retrying; nil retries every error
+	Retryable func(err error) bool
+	// Clock measures the budget and backoff; nil uses SystemClock, or the
+	// client's clock in the EipClient *WithBudget methods
+	Clock Clock
+}
+
+// Do runs op until it succeeds or the budget is exhausted and returns the last
+// error. If ctx is done before op ever ran, ctx.Err() is returned.
+func (b RetryBudget) Do(ctx context.Context, op func(ctx context.Context) error) error {
+	clock := b.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	deadline, hasDeadline := ctx.Deadline()
+	if b.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = withTimeout(ctx, clock, b.Budget)
+		defer cancel()
+		if end := clock.Now().Add(b.Budget); !hasDeadline || end.Before(deadline) {
+			deadline, hasDeadline = end, true
+		}
+	}
+	delay := b.InitialDelay
+	if delay <= 0 {
+		delay = DefaultRetryInitialDelay
+	}
+	maxDelay := b.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+
+	var lastErr error
+	for {
+		if err := ctx.Err(); err != nil {
+			if lastErr == nil {
+				return err
+			}
+			return lastErr
+		}
+		lastErr = op(ctx)
+		if lastErr == nil {
+			return nil
+		}
+		if b.Retryable != nil && !b.Retryable(lastErr) {
+			return lastErr
+		}
+		if hasDeadline && clock.Now().Add(delay).After(deadline) {
+			return lastErr
+		}
+
+		timer := clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return lastErr
+		case <-timer.C():
+		}
+		if delay *= 2; delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+}
+
+// ConnectWithBudget connects to a PLC, retrying failed connects within budget
+func ConnectWithBudget(ctx context.Context, ipAddress string, budget RetryBudget) (*EipClient, error) {
+	var client *EipClient
+	err := budget.Do(ctx, func(context.Context) error {
+		var err error
+		client, err = NewClient(ipAddress)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// ReadTagWithBudget reads a tag value, retrying failed reads within budget
+func (c *EipClient) ReadTagWithBudget(ctx context.Context, tagName string, dataType PlcDataType, budget RetryBudget) (*PlcValue, error) {
+	budget = c.withClock(budget)
+	var value *PlcValue
+	err := budget.Do(ctx, func(ctx context.Context) error {
+		var err error
+		value, err = c.ReadValueContext(ctx, tagName, dataType)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// WriteTagWithBudget writes a tag value, retrying failed writes within budget
+func (c *EipClient) WriteTagWithBudget(ctx context.Context, tagName string, value *PlcValue, budget RetryBudget) error {
+	budget = c.withClock(budget)
+	return budget.Do(ctx, func(ctx context.Context) error {
+		return c.WriteValueContext(ctx, tagName, value)
+	})
+}
+
+// BatchReadWithBudget performs a batch read, retrying failures within budget
+func (c *EipClient) BatchReadWithBudget(ctx context.Context, tagNames []string, budget RetryBudget) (map[string]interface{}, error) {
+	budget = c.withClock(budget)
+	var result map[string]interface{}
+	err := budget.Do(ctx, func(context.Context) error {
+		var err error
+		result, err = c.BatchRead(tagNames)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BatchWriteWithBudget performs a batch write, retrying failures within budget
+func (c *EipClient) BatchWriteWithBudget(ctx context.Context, tagValues map[string]interface{}, budget RetryBudget) error {
+	budget = c.withClock(budget)
+	return budget.Do(ctx, func(context.Context) error {
+		return c.BatchWrite(tagValues)
+	})
+}
+
+// ExecuteBatchWithBudget executes a batch of operations, retrying failures within budget
+func (c *EipClient) ExecuteBatchWithBudget(ctx context.Context, operations []BatchOperation, budget RetryBudget) ([]BatchOperationResult, error) {
+	budget = c.withClock(budget)
+	var results []BatchOperationResult
+	err := budget.Do(ctx, func(context.Context) error {
+		var err error
+		results, err = c.ExecuteBatch(operations)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// UpdateWithBudget performs a compare-and-swap read-modify-write like
+// UpdateWithRetry, restarting when another writer changes the tag until the
+// budget is spent. Other errors are returned without retrying.
+func (c *EipClient) UpdateWithBudget(ctx context.Context, tagName string, dataType PlcDataType, fn func(old interface{}) interface{}, budget RetryBudget) (*PlcValue, error) {
+	budget = c.withClock(budget)
+	budget.Retryable = isConcurrentModification
+	var written *PlcValue
+	err := budget.Do(ctx, func(context.Context) error {
+		var err error
+		written, err = c.updateOnce(tagName, dataType, infallible(fn), true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return written, nil
+}
+
+// withClock returns budget measured on the client's clock unless it has its own
+func (c *EipClient) withClock(budget RetryBudget) RetryBudget {
+	if budget.Clock == nil {
+		budget.Clock = c.Clock()
+	}
+	return budget
+}
+
+// isConcurrentModification reports whether err is ErrConcurrentModification
+func isConcurrentModification(err error) bool {
+	var eipErr *EipError
+	return errors.As(err, &eipErr) && eipErr.Code == ErrConcurrentModification
+}
+
+// ConnectWithRetry connects to a PLC, making up to maxRetries attempts delay apart.
+//
+// Deprecated: use ConnectWithBudget, which bounds the total time instead.
+func ConnectWithRetry(ipAddress string, maxRetries int, delay time.Duration) (*EipClient, error) {
+	log.Printf("Attempting to connect to PLC at %s with retry logic", ipAddress)
+	var client *EipClient
+	var err error
+	for i := 0; i < maxRetries; i++ {
+		client, err = NewClient(ipAddress)
+		if err == nil {
+			log.Printf("Successfully connected to PLC at %s after %d retries", ipAddress, i)
+			return client, nil
+		}
+		log.Printf("Retry %d: Failed to connect to PLC at %s", i+1, ipAddress)
+		time.Sleep(delay)
+	}
+	log.Printf("Failed to connect to PLC at %s after %d retries", ipAddress, maxRetries)
+	return nil, err
+}
+
+// BatchReadWithRetry performs a batch read operation with retries
+//
+// Deprecated: use BatchReadWithBudget, which bounds the total time instead.
+func (c *EipClient) BatchReadWithRetry(tagNames []string, retries int) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	var err error
+
+	for i := 0; i < retries; i++ {
+		result, err = c.BatchRead(tagNames)
+		if err == nil {
+			return result, nil
+		}
+		sleep(c.Clock(), time.Second*time.Duration(i+1))
+	}
+	return nil, err
+}
+
+// BatchWriteWithRetry performs a batch write operation with retries
+//
+// Deprecated: use BatchWriteWithBudget, which bounds the total time instead.
+func (c *EipClient) BatchWriteWithRetry(tagValues map[string]interface{}, retries int) error {
+	var err error
+
+	for i := 0; i < retries; i++ {
+		err = c.BatchWrite(tagValues)
+		if err == nil {
+			return nil
+		}
+		sleep(c.Clock(), time.Second*time.Duration(i+1))
+	}
+	return err
+}
+
+// ExecuteBatchWithRetry executes a batch of operations with retries
+//
+// Deprecated: use ExecuteBatchWithBudget, which bounds the total time instead.
+func (c *EipClient) ExecuteBatchWithRetry(operations []BatchOperation, retries int) ([]BatchOperationResult, error) {
+	var results []BatchOperationResult
+	var err error
+
+	for i := 0; i < retries; i++ {
+		results, err = c.ExecuteBatch(operations)
+		if err == nil {
+			return results, nil
+		}
+		sleep(c.Clock(), time.Second*time.Duration(i+1))
+	}
+	return nil, err
+}
+
+// ReadTagWithRetry reads a tag value with retries
+//
+// Deprecated: use ReadTagWithBudget, which bounds the total time instead.
+func (c *EipClient) ReadTagWithRetry(tagName string, dataType PlcDataType, retries int) (*PlcValue, error) {
+	var result *PlcValue
+	var err error
+
+	for i := 0; i < retries; i++ {
+		result, err = c.ReadValue(tagName, dataType)
+		if err == nil {
+			return result, nil
+		}
+		sleep(c.Clock(), time.Second*time.Duration(i+1))
+	}
+	return nil, err
+}
+
+// WriteTagWithRetry writes a tag value with retries
+//
+// Deprecated: use WriteTagWithBudget, which bounds the total time instead.
+func (c *EipClient) WriteTagWithRetry(tagName string, value *PlcValue, retries int) error {
+	var err error
+
+	for i := 0; i < retries; i++ {
+		err = c.WriteValue(tagName, value)
+		if err == nil {
+			return nil
+		}
+		sleep(c.Clock(), time.Second*time.Duration(i+1))
+	}
+	return err
+}
+
+// Update reads a tag, applies fn to the current value and writes the result back.
+// It returns the value that was written.
+func (c *EipClient) Update(tagName string, dataType PlcDataType, fn func(old interface{}) interface{}) (*PlcValue, error) {
+	return c.UpdateWithRetry(tagName, dataType, fn, 0)
+}
+
+// UpdateWithRetry performs a read-modify-write like Update, but re-reads the tag
+// immediately before writing and starts over if the value changed in the meantime
+// (compare-and-swap). Up to retries restarts are attempted before giving up with
+// ErrConcurrentModification. A retries value of 0 disables the check.
+func (c *EipClient) UpdateWithRetry(tagName string, dataType PlcDataType, fn func(old interface{}) interface{}, retries int) (*PlcValue, error) {
+	for attempt := 0; ; attempt++ {
+		written, err := c.updateOnce(tagName, dataType, infallible(fn), retries > 0)
+		if err == nil || !isConcurrentModification(err) {
+			return written, err
+		}
+		if attempt < retries {
+			continue
+		}
+		var eipErr *EipError
+		if errors.As(err, &eipErr) {
+			eipErr.Details["attempts"] = attempt + 1
+		}
+		return nil, err
+	}
+}
+
+// Update is EipClient.Update with a typed function. The current value is
+// converted to T as ReadInto converts fields, so fn can take an int32 for a
+// DINT or a float32 for a REAL, and the value fn returns is written back and
+// returned. Nothing is written if the value does not fit T.
+func Update[T any](c *EipClient, tagName string, dataType PlcDataType, fn func(old T) T) (T, error) {
+	var written T
+	_, err := c.updateOnce(tagName, dataType, func(old interface{}) (interface{}, error) {
+		var typed T
+		if err := assignField(reflect.ValueOf(&typed).Elem(), old); err != nil {
+			return nil, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("Tag %s: %v", tagName, err),
+				map[string]interface{}{"tag_name": tagName, "data_type": dataType})
+		}
+		written = fn(typed)
+		// Written back as the Go type the tag was read as
+		value := reflect.New(reflect.TypeOf(old)).Elem()
+		if err := assignField(value, written); err != nil {
+			return nil, NewEipErrorWithDetails(ErrInvalidTagValue, fmt.Sprintf("Tag %s: %v", tagName, err),
+				map[string]interface{}{"tag_name": tagName, "data_type": dataType})
+		}
+		return value.Interface(), nil
+	}, false)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return written, nil
+}
+
+// infallible adapts an update function for updateOnce
+func infallible(fn func(old interface{}) interface{}) func(old interface{}) (interface{}, error) {
+	return func(old interface{}) (interface{}, error) {
+		return fn(old), nil
+	}
+}
+
+// updateOnce performs one read-modify-write. With check set the tag is
+// re-read before writing and ErrConcurrentModification is returned, without
+// writing, if it changed. Values are compared as write verification compares
+// them, so a NaN REAL is unchanged when it reads back as NaN.
+func (c *EipClient) updateOnce(tagName string, dataType PlcDataType, fn func(old interface{}) (interface{}, error), check bool) (*PlcValue, error) {
+	old, err := c.ReadValue(tagName, dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := fn(old.Value)
+	if err != nil {
+		return nil, err
+	}
+	newValue := &PlcValue{Type: dataType, Value: value}
+
+	if check {
+		current, err := c.ReadValue(tagName, dataType)
+		if err != nil {
+			return nil, err
+		}
+		if !ValuesMatch(dataType, old.Value, current.Value, 0) {
+			return nil, NewEipErrorWithDetails(ErrConcurrentModification,
+				fmt.Sprintf("Tag %s changed during update", tagName),
+				map[string]interface{}{
+					"tag_name":  tagName,
+					"data_type": dataType,
+				})
+		}
+	}
+
+	if err := c.WriteValue(tagName, newValue); err != nil {
+		return nil, err
+	}
+	return newValue, nil
+}
@@ -0,0 +1,86 @@
+package ethernetip
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestRetryBudgetSucceeds tests that failed attempts are retried until success
+func TestRetryBudgetSucceeds(t *testing.T) {
+	attempts := 0
+	budget := RetryBudget{Budget: time.Second, InitialDelay: time.Millisecond}
+	err := budget.Do(context.Background(), func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return NewEipError(ErrTimeout, "timeout")
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("Expected success on attempt 3, got %v after %d", err, attempts)
+	}
+}
+
+// TestRetryBudgetBoundsLatency tests that retries stop when the budget is spent
+func TestRetryBudgetBoundsLatency(t *testing.T) {
+	failure := NewEipError(ErrConnectionFailed, "unreachable")
+	attempts := 0
+	budget := RetryBudget{Budget: 50 * time.Millisecond, InitialDelay: 5 * time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	start := time.Now()
+	err := budget.Do(context.Background(), func(context.Context) error {
+		attempts++
+		return failure
+	})
+	elapsed := time.Since(start)
+
+	if err != failure {
+		t.Errorf("Expected the last error, got %v", err)
+	}
+	if attempts < 2 {
+		t.Errorf("Expected several attempts, got %d", attempts)
+	}
+	if elapsed > 50*time.Millisecond+25*time.Millisecond {
+		t.Errorf("Budget of 50ms took %v", elapsed)
+	}
+}
+
+// TestRetryBudgetContext tests that the context deadline and cancellation win
+func TestRetryBudgetContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	err := RetryBudget{}.Do(ctx, func(context.Context) error {
+		called = true
+		return nil
+	})
+	if called || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected no attempt and context.Canceled, got %v (called %v)", err, called)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	attempts := 0
+	err = RetryBudget{InitialDelay: time.Second}.Do(ctx, func(context.Context) error {
+		attempts++
+		return NewEipError(ErrTimeout, "timeout")
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("Expected one attempt when the backoff exceeds the deadline, got %d", attempts)
+	}
+}
+
+// TestRetryBudgetRetryable tests that non-retryable errors end the loop
+func TestRetryBudgetRetryable(t *testing.T) {
+	attempts := 0
+	budget := RetryBudget{Budget: time.Second, InitialDelay: time.Millisecond, Retryable: isConcurrentModification}
+	budget.Do(context.Background(), func(context.Context) error {
+		attempts++
+		return NewEipError(ErrTagNotFound, "missing")
+	})
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+}
@@ -0,0 +1,197 @@
+package ethernetip
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// RouteHop is one hop of a route: leave the current device through Port and
+// go to the device at Link, a slot or node number or an IPv4 address
+type RouteHop struct {
+	Port uint16 `json:"port"`
+	Link string `json:"link"`
+}
+
+// CIPPath is the route from the EtherNet/IP endpoint to the target
+// processor, for controllers that do not answer at the endpoint themselves.
+// In the usual text form it lists port and link pairs separated by commas:
+// "1,3" goes through the backplane (port 1) to slot 3, the ControlLogix
+// processor behind a 1756-EN2T, and "1,2,2,10.0.1.5,1,0" goes on to a
+// remote chassis. A CompactLogix answers at its own address and needs no
+// route.
+type CIPPath []RouteHop
+
+// ParseCIPPath parses a route in its text form, e.g. "1,3". An empty string
+// is the empty route.
+func ParseCIPPath(s string) (CIPPath, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	fields := strings.Split(s, ",")
+	if len(fields)%2 != 0 {
+		return nil, NewEipErrorWithDetails(ErrInvalidTagAddress,
+			fmt.Sprintf("route '%s' must be port and link pairs", s), map[string]interface{}{"route": s})
+	}
+	var path CIPPath
+	for i := 0; i < len(fields); i += 2 {
+		port, err := strconv.ParseUint(strings.TrimSpace(fields[i]), 10, 16)
+		if err != nil || port == 0 {
+			return nil, NewEipErrorWithDetails(ErrInvalidTagAddress,
+				fmt.Sprintf("invalid port '%s' in route '%s'", fields[i], s), map[string]interface{}{"route": s})
+		}
+		hop := RouteHop{Port: uint16(port), Link: strings.TrimSpace(fields[i+1])}
+		if _, err := hop.link(); err != nil {
+			return nil, err
+		}
+		path = append(path, hop)
+	}
+	return path, nil
+}
+
+// String returns the route in its text form
+func (p CIPPath) String() string {
+	fields := make([]string, 0, 2*len(p))
+	for _, hop := range p {
+		fields = append(fields, strconv.Itoa(int(hop.Port)), hop.Link)
+	}
+	return strings.Join(fields, ",")
+}
+
+// Encode returns the port segments of the route
+func (p CIPPath) Encode() ([]byte, error) {
+	return NewPathBuilder().Route(p).Build()
+}
+
+// link encodes the link address of the hop: one byte for a slot or node
+// number, the address text for an IPv4 address
+func (h RouteHop) link() ([]byte, error) {
+	if n, err := strconv.ParseUint(h.Link, 10, 8); err == nil {
+		return []byte{byte(n)}, nil
+	}
+	if ip := net.ParseIP(h.Link); ip != nil && ip.To4() != nil {
+		return []byte(ip.To4().String()), nil
+	}
+	return nil, NewEipErrorWithDetails(ErrInvalidTagAddress,
+		fmt.Sprintf("link '%s' must be a number from 0 to 255 or an IPv4 address", h.Link),
+		map[string]interface{}{"port": h.Port, "link": h.Link})
+}
+
+// RouteBuilder builds a CIPPath hop by hop, so routes through bridges need
+// not be written as port and link numbers. Each hop leaves the device reached
+// so far; methods can be chained and the first invalid hop is reported by
+// Build.
+//
+//	// EN2T in slot 2 of the local chassis, out to the EN2T at 10.0.1.5,
+//	// then the processor in slot 0 of the remote chassis
+//	route, err := ethernetip.NewRouteBuilder().Backplane(2).Ethernet("10.0.1.5").Backplane(0).Build()
+//
+//	// 1756-CNB in slot 4, ControlNet node 12, processor in slot 1 there
+//	route, err := ethernetip.NewRouteBuilder().Backplane(4).ControlNet(12).Backplane(1).Build()
+type RouteBuilder struct {
+	route CIPPath
+	err   error
+}
+
+// NewRouteBuilder creates an empty route
+func NewRouteBuilder() *RouteBuilder {
+	return &RouteBuilder{}
+}
+
+// fail records the first error
+func (b *RouteBuilder) fail(format string, args ...interface{}) *RouteBuilder {
+	if b.err == nil {
+		b.err = NewEipError(ErrInvalidTagAddress, fmt.Sprintf(format, args...))
+	}
+	return b
+}
+
+// Port appends a hop out of port to the given link, a slot or node number
+// or an IPv4 address
+func (b *RouteBuilder) Port(port uint16, link string) *RouteBuilder {
+	if port == 0 {
+		return b.fail("port 0 is reserved")
+	}
+	hop := RouteHop{Port: port, Link: link}
+	if _, err := hop.link(); err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return b
+	}
+	b.route = append(b.route, hop)
+	return b
+}
+
+// Backplane appends a hop across the backplane (port 1) to a slot
+func (b *RouteBuilder) Backplane(slot int) *RouteBuilder {
+	if slot < 0 || slot > 0xFF {
+		return b.fail("invalid slot %d", slot)
+	}
+	return b.Port(PortBackplane, strconv.Itoa(slot))
+}
+
+// Ethernet appends a hop out of an Ethernet bridge's port (port 2 of a
+// 1756-ENBT or EN2T) to the device at the given IPv4 address
+func (b *RouteBuilder) Ethernet(address string) *RouteBuilder {
+	ip := net.ParseIP(address)
+	if ip == nil || ip.To4() == nil {
+		return b.fail("invalid IPv4 address '%s'", address)
+	}
+	return b.Port(PortEthernet, ip.To4().String())
+}
+
+// ControlNet appends a hop out of a ControlNet bridge's channel (port 2 of a
+// 1756-CNB) to the node with the given MAC ID, 1 to 99
+func (b *RouteBuilder) ControlNet(node int) *RouteBuilder {
+	if node < 1 || node > 99 {
+		return b.fail("invalid ControlNet node %d", node)
+	}
+	return b.Port(PortControlNet, strconv.Itoa(node))
+}
+
+// Build returns the route, or the first error found while building it.
+// Routes that do not fit in a CIP path are rejected.
+func (b *RouteBuilder) Build() (CIPPath, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if _, err := b.route.Encode(); err != nil {
+		return nil, err
+	}
+	return append(CIPPath(nil), b.route...), nil
+}
+
+// ParseConnectionAddress splits a connection address such as
+// "192.168.0.10/1,3" into the address of the EtherNet/IP endpoint and the
+// route behind it. An address without "/" has the empty route.
+func ParseConnectionAddress(address string) (string, CIPPath, error) {
+	host, route, found := strings.Cut(address, "/")
+	if !found {
+		return address, nil, nil
+	}
+	path, err := ParseCIPPath(route)
+	if err != nil {
+		return "", nil, err
+	}
+	return host, path, nil
+}
+
+// SetRoute routes the client's requests along path to a processor behind
+// the endpoint, keeping the rest of the target profile; the empty route
+// addresses the endpoint (or the profile's slot) again. Like
+// SetTargetProfile, it replaces a warm standby session opened for the old
+// route.
+func (c *EipClient) SetRoute(path CIPPath) error {
+	profile := c.TargetProfile()
+	profile.Route = append(CIPPath(nil), path...)
+	return c.SetTargetProfile(profile)
+}
+
+// Route returns the route requests take to the processor: the profile's
+// route, or the backplane hop to its slot
+func (c *EipClient) Route() CIPPath {
+	return c.TargetProfile().route()
+}
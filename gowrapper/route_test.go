@@ -0,0 +1,133 @@
+package ethernetip
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestParseCIPPath tests parsing and encoding routes
+func TestParseCIPPath(t *testing.T) {
+	tests := []struct {
+		text    string
+		path    CIPPath
+		encoded []byte
+	}{
+		{"", nil, nil},
+		{"1,0", CIPPath{{Port: 1, Link: "0"}}, []byte{0x01, 0x00}},
+		{" 1, 3 ", CIPPath{{Port: 1, Link: "3"}}, []byte{0x01, 0x03}},
+		{"1,2,2,10.0.1.5,1,0", CIPPath{{Port: 1, Link: "2"}, {Port: 2, Link: "10.0.1.5"}, {Port: 1, Link: "0"}},
+			append(append([]byte{0x01, 0x02, 0x12, 0x08}, "10.0.1.5"...), 0x01, 0x00)},
+	}
+	for _, tt := range tests {
+		path, err := ParseCIPPath(tt.text)
+		if err != nil || !reflect.DeepEqual(path, tt.path) {
+			t.Errorf("%q: expected %v, got %v, %v", tt.text, tt.path, path, err)
+			continue
+		}
+		encoded, err := path.Encode()
+		if err != nil || !bytes.Equal(encoded, tt.encoded) {
+			t.Errorf("%q: expected % X, got % X, %v", tt.text, tt.encoded, encoded, err)
+		}
+	}
+	if got := (CIPPath{{Port: 1, Link: "2"}, {Port: 2, Link: "10.0.1.5"}}).String(); got != "1,2,2,10.0.1.5" {
+		t.Errorf("Unexpected text form %q", got)
+	}
+
+	var eipErr *EipError
+	for _, text := range []string{"1", "0,3", "x,3", "1,256", "2,plc.local", "1,3,"} {
+		if _, err := ParseCIPPath(text); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagAddress {
+			t.Errorf("%q: expected an invalid route, got %v", text, err)
+		}
+	}
+}
+
+// TestRouteBuilder tests building multi-hop routes through bridges
+func TestRouteBuilder(t *testing.T) {
+	route, err := NewRouteBuilder().Backplane(2).Ethernet("10.0.1.5").Backplane(0).Build()
+	if err != nil || route.String() != "1,2,2,10.0.1.5,1,0" {
+		t.Errorf("Unexpected Ethernet route %v, %v", route, err)
+	}
+	route, err = NewRouteBuilder().Backplane(4).ControlNet(12).Backplane(1).Build()
+	if err != nil || route.String() != "1,4,2,12,1,1" {
+		t.Errorf("Unexpected ControlNet route %v, %v", route, err)
+	}
+	route, err = NewRouteBuilder().Port(18, "5").Build()
+	if err != nil || route.String() != "18,5" {
+		t.Errorf("Unexpected route %v, %v", route, err)
+	}
+
+	invalid := map[string]*RouteBuilder{
+		"slot":      NewRouteBuilder().Backplane(256),
+		"ip":        NewRouteBuilder().Backplane(2).Ethernet("plc.local"),
+		"node":      NewRouteBuilder().ControlNet(100),
+		"port zero": NewRouteBuilder().Port(0, "1"),
+		"link":      NewRouteBuilder().Port(1, ""),
+	}
+	for name, b := range invalid {
+		// Later valid hops must not hide the first error
+		if _, err := b.Backplane(0).Build(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	long := NewRouteBuilder()
+	for i := 0; i < 40; i++ {
+		long.Ethernet("192.168.100.200")
+	}
+	if _, err := long.Build(); err == nil {
+		t.Error("Expected an error for a route longer than a CIP path")
+	}
+}
+
+// TestParseConnectionAddress tests splitting the route off an address
+func TestParseConnectionAddress(t *testing.T) {
+	host, path, err := ParseConnectionAddress("192.168.0.10/1,3")
+	if err != nil || host != "192.168.0.10" || !reflect.DeepEqual(path, CIPPath{{Port: 1, Link: "3"}}) {
+		t.Errorf("Unexpected split %q %v %v", host, path, err)
+	}
+	host, path, err = ParseConnectionAddress("192.168.0.10:44818")
+	if err != nil || host != "192.168.0.10:44818" || path != nil {
+		t.Errorf("Unexpected split %q %v %v", host, path, err)
+	}
+	if _, _, err := ParseConnectionAddress("192.168.0.10/1"); err == nil {
+		t.Error("Expected an error for a route without a link")
+	}
+}
+
+// TestTargetProfileRoute tests that a profile's route takes precedence over
+// its slot
+func TestTargetProfileRoute(t *testing.T) {
+	profile := LogixEmulateProfile(2)
+	profile.Route = CIPPath{{Port: 1, Link: "1"}, {Port: 2, Link: "10.0.1.5"}, {Port: 1, Link: "3"}}
+	if got := profile.route(); !reflect.DeepEqual(got, profile.Route) {
+		t.Errorf("Expected the profile's route, got %v", got)
+	}
+	path, err := profile.routePath()
+	if err != nil || len(path) != 14 {
+		t.Errorf("Unexpected route path % X, %v", path, err)
+	}
+
+	client := &EipClient{}
+	if got := client.Route(); got != nil {
+		t.Errorf("Expected a direct route by default, got %v", got)
+	}
+	client.profile.Store(&TargetProfile{Name: "Logix", Slot: 3})
+	if got := client.Route().String(); got != "1,3" {
+		t.Errorf("Expected the backplane hop to slot 3, got %q", got)
+	}
+}
+
+// TestNewClientWithOptionsRoute tests routes that fail before connecting
+func TestNewClientWithOptionsRoute(t *testing.T) {
+	var eipErr *EipError
+	if _, err := NewClientWithOptions("192.0.2.1/1,x"); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagAddress {
+		t.Errorf("Expected an invalid route, got %v", err)
+	}
+	if _, err := NewClientWithOptions("192.0.2.1/1,3", WithRoute(CIPPath{{Port: 1, Link: "0"}})); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidOperation {
+		t.Errorf("Expected an error for two routes, got %v", err)
+	}
+	if _, err := NewClientWithOptions("/1,3"); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidOperation {
+		t.Errorf("Expected an error for a route without an address, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package ethernetip
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseSafetySignature tests decoding the signature ID, time and date
+func TestParseSafetySignature(t *testing.T) {
+	data := []byte{
+		0x4D, 0x3C, 0x2B, 0x1A, // ID
+		0x7B, 0x2E, 0x93, 0x02, // 12:00:00.123
+		0xE6, 0x4A, // 2024-06-30: 19174 days after 1972-01-01
+	}
+	sig := parseSafetySignature(data)
+	if sig == nil {
+		t.Fatal("Expected a signature")
+	}
+	if sig.String() != "1A2B3C4D 2024-06-30 12:00:00.123" {
+		t.Errorf("Unexpected signature %s", sig)
+	}
+	if parseSafetySignature(make([]byte, safetySignatureSize)) != nil {
+		t.Error("Expected no signature for a zero ID")
+	}
+}
+
+// TestVerifySafetySignature tests matching the signature and lock state
+func TestVerifySafetySignature(t *testing.T) {
+	expected := SafetySignature{ID: 0x1A2B3C4D, Timestamp: time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)}
+	locked, unlocked := true, false
+
+	status := &SafetyStatus{Signature: &expected, Locked: &locked}
+	if err := status.verify(expected, true); err != nil {
+		t.Errorf("Expected a match, got %v", err)
+	}
+	status.Locked = &unlocked
+	if err := status.verify(expected, true); err == nil {
+		t.Error("Expected an error for an unlocked safety task")
+	}
+	if err := status.verify(expected, false); err != nil {
+		t.Errorf("Expected the lock to be ignored, got %v", err)
+	}
+	status.Locked = nil
+	if err := status.verify(expected, true); err == nil {
+		t.Error("Expected an error when the lock state is unknown")
+	}
+
+	changed := expected
+	changed.Timestamp = changed.Timestamp.Add(time.Second)
+	if err := (&SafetyStatus{Signature: &changed}).verify(expected, false); err == nil {
+		t.Error("Expected an error for a different signature")
+	}
+	if err := (&SafetyStatus{}).verify(expected, false); err == nil {
+		t.Error("Expected an error without a signature")
+	}
+
+	c := &EipClient{}
+	if c.SafetyLayout() != DefaultSafetyLayout {
+		t.Error("Expected the default layout")
+	}
+	c.SetSafetyLayout(SafetyLayout{Class: 0x39, Instance: 1, SignatureAttr: 0x1A, LockedTag: "SafetyLocked"})
+	if c.SafetyLayout().LockedTag != "SafetyLocked" {
+		t.Error("Expected the layout to be replaced")
+	}
+}
@@ -0,0 +1,669 @@
+package ethernetip
+
+/*
+#include <stdlib.h>
+
+// Boolean operations
+extern int eip_read_bool(int client_id, const char* tag_name, int* result);
+extern int eip_write_bool(int client_id, const char* tag_name, int value);
+
+// Integer operations
+extern int eip_read_sint(int client_id, const char* tag_name, signed char* result);
+extern int eip_write_sint(int client_id, const char* tag_name, signed char value);
+extern int eip_read_int(int client_id, const char* tag_name, short* result);
+extern int eip_write_int(int client_id, const char* tag_name, short value);
+extern int eip_read_dint(int client_id, const char* tag_name, int* result);
+extern int eip_write_dint(int client_id, const char* tag_name, int value);
+extern int eip_read_lint(int client_id, const char* tag_name, long long* result);
+extern int eip_write_lint(int client_id, const char* tag_name, long long value);
+
+// Unsigned integer operations
+extern int eip_read_usint(int client_id, const char* tag_name, unsigned char* result);
+extern int eip_write_usint(int client_id, const char* tag_name, unsigned char value);
+extern int eip_read_uint(int client_id, const char* tag_name, unsigned short* result);
+extern int eip_write_uint(int client_id, const char* tag_name, unsigned short value);
+extern int eip_read_udint(int client_id, const char* tag_name, unsigned int* result);
+extern int eip_write_udint(int client_id, const char* tag_name, unsigned int value);
+extern int eip_read_ulint(int client_id, const char* tag_name, unsigned long long* result);
+extern int eip_write_ulint(int client_id, const char* tag_name, unsigned long long value);
+
+// Float operations
+extern int eip_read_real(int client_id, const char* tag_name, double* result);
+extern int eip_write_real(int client_id, const char* tag_name, double value);
+extern int eip_read_lreal(int client_id, const char* tag_name, double* result);
+extern int eip_write_lreal(int client_id, const char* tag_name, double value);
+*/
+import "C"
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// maxFastTagName is the largest tag name (including the NUL terminator) that
+// the scalar read fast path can hold without falling back to C.CString.
+const maxFastTagName = 256
+
+// scalarBuf holds a NUL-terminated copy of a tag name together with the result
+// slots used by the scalar read functions. Buffers are pooled so that a
+// successful BOOL/INT/DINT/REAL read performs no Go heap allocations.
+type scalarBuf struct {
+	name  [maxFastTagName]byte
+	cName *C.char // C-allocated copy for names that do not fit in name
+	i     C.int
+	s     C.short
+	d     C.double
+}
+
+var scalarBufPool = sync.Pool{
+	New: func() interface{} { return new(scalarBuf) },
+}
+
+// getScalarBuf returns a pooled buffer and a C pointer to tagName. The buffer
+// must be released with putScalarBuf once the native call has returned.
+func getScalarBuf(tagName string) (*scalarBuf, *C.char) {
+	b := scalarBufPool.Get().(*scalarBuf)
+	if len(tagName) < maxFastTagName && strings.IndexByte(tagName, 0) < 0 {
+		n := copy(b.name[:], tagName)
+		b.name[n] = 0
+		return b, (*C.char)(unsafe.Pointer(&b.name[0]))
+	}
+	b.cName = C.CString(tagName)
+	return b, b.cName
+}
+
+// putScalarBuf frees any C memory held by b and returns it to the pool.
+func putScalarBuf(b *scalarBuf) {
+	if b.cName != nil {
+		C.free(unsafe.Pointer(b.cName))
+		b.cName = nil
+	}
+	scalarBufPool.Put(b)
+}
+
+// ReadBool reads a boolean value from the PLC
+func (c *EipClient) ReadBool(tagName string) (bool, error) {
+	var value bool
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readBool(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readBool is ReadBool without queuing
+func (c *EipClient) readBool(tagName string) (bool, error) {
+	// Validate tag name
+	if tagName == "" {
+		return false, NewEipError(ErrInvalidTagName, "Tag name cannot be empty")
+	}
+	// Bits of integer tags ("Status.5") are not symbols of their own
+	if base, bit, ok := splitBitMember(tagName); ok {
+		return c.readBit(base, bit)
+	}
+
+	buf, cTagName := getScalarBuf(tagName)
+	defer putScalarBuf(buf)
+
+	// Call the Rust library to read the boolean value
+	retCode := int(C.eip_read_bool(C.int(c.id()), cTagName, &buf.i))
+	if retCode != 0 {
+		c.logf(slog.LevelDebug, "❌", "Failed to read boolean from tag '%s': error code %d", tagName, retCode)
+		return false, NewEipErrorWithDetails(ErrTagNotFound,
+			fmt.Sprintf("Failed to read boolean tag '%s'", tagName),
+			map[string]interface{}{
+				"tag_name":   tagName,
+				"data_type":  "BOOL",
+				"error_code": retCode,
+				"client_id":  c.id(),
+			})
+	}
+
+	return buf.i != 0, nil
+}
+
+// WriteBool writes a boolean value to the PLC
+func (c *EipClient) WriteBool(tagName string, value bool) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, Bool, value, func() error { return c.writeBool(tagName, value) })
+	})
+}
+
+// writeBool is WriteBool without auditing
+func (c *EipClient) writeBool(tagName string, value bool) error {
+	c.logf(slog.LevelDebug, "📤", "Writing boolean %v to tag '%s'", value, tagName)
+
+	// Validate tag name
+	if tagName == "" {
+		return NewEipError(ErrInvalidTagName, "Tag name cannot be empty")
+	}
+	if base, bit, ok := splitBitMember(tagName); ok {
+		return c.writeBit(base, bit, value)
+	}
+
+	// Convert tag name to C string
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	// Convert boolean to C int
+	var cValue C.int
+	if value {
+		cValue = 1
+	}
+
+	// Call the Rust library to write the boolean value
+	retCode := int(C.eip_write_bool(C.int(c.id()), cTagName, cValue))
+	if retCode != 0 {
+		c.logf(slog.LevelDebug, "❌", "Failed to write boolean to tag '%s': error code %d", tagName, retCode)
+		return NewEipErrorWithDetails(ErrTagNotFound,
+			fmt.Sprintf("Failed to write boolean tag '%s'", tagName),
+			map[string]interface{}{
+				"tag_name":   tagName,
+				"data_type":  "BOOL",
+				"value":      value,
+				"error_code": retCode,
+				"client_id":  c.id(),
+			})
+	}
+
+	c.logf(slog.LevelDebug, "✅", "Successfully wrote boolean to tag '%s'", tagName)
+	return nil
+}
+
+// ReadSint reads a signed 8-bit integer from the PLC
+func (c *EipClient) ReadSint(tagName string) (int8, error) {
+	var value int8
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readSint(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readSint is ReadSint without queuing
+func (c *EipClient) readSint(tagName string) (int8, error) {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	var result C.schar
+	retCode := int(C.eip_read_sint(C.int(c.id()), cTagName, &result))
+	if retCode != 0 {
+		return 0, &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to read SINT tag %s", tagName),
+		}
+	}
+
+	return int8(result), nil
+}
+
+// WriteSint writes a signed 8-bit integer to the PLC
+func (c *EipClient) WriteSint(tagName string, value int8) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, Sint, value, func() error { return c.writeSint(tagName, value) })
+	})
+}
+
+// writeSint is WriteSint without auditing
+func (c *EipClient) writeSint(tagName string, value int8) error {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	retCode := int(C.eip_write_sint(C.int(c.id()), cTagName, C.schar(value)))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to write SINT tag %s", tagName),
+		}
+	}
+
+	return nil
+}
+
+// ReadInt reads a 16-bit integer from the PLC
+func (c *EipClient) ReadInt(tagName string) (int16, error) {
+	var value int16
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readInt(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readInt is ReadInt without queuing
+func (c *EipClient) readInt(tagName string) (int16, error) {
+	buf, cTagName := getScalarBuf(tagName)
+	defer putScalarBuf(buf)
+
+	retCode := int(C.eip_read_int(C.int(c.id()), cTagName, &buf.s))
+	if retCode != 0 {
+		return 0, &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to read INT tag %s", tagName),
+		}
+	}
+
+	return int16(buf.s), nil
+}
+
+// WriteInt writes a 16-bit integer to the PLC
+func (c *EipClient) WriteInt(tagName string, value int16) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, Int, value, func() error { return c.writeInt(tagName, value) })
+	})
+}
+
+// writeInt is WriteInt without auditing
+func (c *EipClient) writeInt(tagName string, value int16) error {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	retCode := int(C.eip_write_int(C.int(c.id()), cTagName, C.short(value)))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to write INT tag %s", tagName),
+		}
+	}
+
+	return nil
+}
+
+// ReadDint reads a 32-bit integer from the PLC
+func (c *EipClient) ReadDint(tagName string) (int32, error) {
+	var value int32
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readDint(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readDint is ReadDint without queuing
+func (c *EipClient) readDint(tagName string) (int32, error) {
+	buf, cTagName := getScalarBuf(tagName)
+	defer putScalarBuf(buf)
+
+	retCode := int(C.eip_read_dint(C.int(c.id()), cTagName, &buf.i))
+	if retCode != 0 {
+		return 0, &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to read DINT tag %s", tagName),
+		}
+	}
+
+	return int32(buf.i), nil
+}
+
+// WriteDint writes a 32-bit integer to the PLC
+func (c *EipClient) WriteDint(tagName string, value int32) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, Dint, value, func() error { return c.writeDint(tagName, value) })
+	})
+}
+
+// writeDint is WriteDint without auditing
+func (c *EipClient) writeDint(tagName string, value int32) error {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	retCode := int(C.eip_write_dint(C.int(c.id()), cTagName, C.int(value)))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to write DINT tag %s", tagName),
+		}
+	}
+
+	return nil
+}
+
+// ReadLint reads a 64-bit integer from the PLC
+func (c *EipClient) ReadLint(tagName string) (int64, error) {
+	var value int64
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readLint(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readLint is ReadLint without queuing
+func (c *EipClient) readLint(tagName string) (int64, error) {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	var result C.longlong
+	retCode := int(C.eip_read_lint(C.int(c.id()), cTagName, &result))
+	if retCode != 0 {
+		return 0, &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to read LINT tag %s", tagName),
+		}
+	}
+
+	return int64(result), nil
+}
+
+// WriteLint writes a 64-bit integer to the PLC
+func (c *EipClient) WriteLint(tagName string, value int64) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, Lint, value, func() error { return c.writeLint(tagName, value) })
+	})
+}
+
+// writeLint is WriteLint without auditing
+func (c *EipClient) writeLint(tagName string, value int64) error {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	retCode := int(C.eip_write_lint(C.int(c.id()), cTagName, C.longlong(value)))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to write LINT tag %s", tagName),
+		}
+	}
+
+	return nil
+}
+
+// ReadUsint reads an unsigned 8-bit integer from the PLC
+func (c *EipClient) ReadUsint(tagName string) (uint8, error) {
+	var value uint8
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readUsint(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readUsint is ReadUsint without queuing
+func (c *EipClient) readUsint(tagName string) (uint8, error) {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	var result C.uchar
+	retCode := int(C.eip_read_usint(C.int(c.id()), cTagName, &result))
+	if retCode != 0 {
+		return 0, &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to read USINT tag %s", tagName),
+		}
+	}
+
+	return uint8(result), nil
+}
+
+// WriteUsint writes an unsigned 8-bit integer to the PLC
+func (c *EipClient) WriteUsint(tagName string, value uint8) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, Usint, value, func() error { return c.writeUsint(tagName, value) })
+	})
+}
+
+// writeUsint is WriteUsint without auditing
+func (c *EipClient) writeUsint(tagName string, value uint8) error {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	retCode := int(C.eip_write_usint(C.int(c.id()), cTagName, C.uchar(value)))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to write USINT tag %s", tagName),
+		}
+	}
+
+	return nil
+}
+
+// ReadUint reads an unsigned 16-bit integer from the PLC
+func (c *EipClient) ReadUint(tagName string) (uint16, error) {
+	var value uint16
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readUint(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readUint is ReadUint without queuing
+func (c *EipClient) readUint(tagName string) (uint16, error) {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	var result C.ushort
+	retCode := int(C.eip_read_uint(C.int(c.id()), cTagName, &result))
+	if retCode != 0 {
+		return 0, &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to read UINT tag %s", tagName),
+		}
+	}
+
+	return uint16(result), nil
+}
+
+// WriteUint writes an unsigned 16-bit integer to the PLC
+func (c *EipClient) WriteUint(tagName string, value uint16) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, Uint, value, func() error { return c.writeUint(tagName, value) })
+	})
+}
+
+// writeUint is WriteUint without auditing
+func (c *EipClient) writeUint(tagName string, value uint16) error {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	retCode := int(C.eip_write_uint(C.int(c.id()), cTagName, C.ushort(value)))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to write UINT tag %s", tagName),
+		}
+	}
+
+	return nil
+}
+
+// ReadUdint reads an unsigned 32-bit integer from the PLC
+func (c *EipClient) ReadUdint(tagName string) (uint32, error) {
+	var value uint32
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readUdint(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readUdint is ReadUdint without queuing
+func (c *EipClient) readUdint(tagName string) (uint32, error) {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	var result C.uint
+	retCode := int(C.eip_read_udint(C.int(c.id()), cTagName, &result))
+	if retCode != 0 {
+		return 0, &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to read UDINT tag %s", tagName),
+		}
+	}
+
+	return uint32(result), nil
+}
+
+// WriteUdint writes an unsigned 32-bit integer to the PLC
+func (c *EipClient) WriteUdint(tagName string, value uint32) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, Udint, value, func() error { return c.writeUdint(tagName, value) })
+	})
+}
+
+// writeUdint is WriteUdint without auditing
+func (c *EipClient) writeUdint(tagName string, value uint32) error {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	retCode := int(C.eip_write_udint(C.int(c.id()), cTagName, C.uint(value)))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to write UDINT tag %s", tagName),
+		}
+	}
+
+	return nil
+}
+
+// ReadUlint reads an unsigned 64-bit integer from the PLC
+func (c *EipClient) ReadUlint(tagName string) (uint64, error) {
+	var value uint64
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readUlint(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readUlint is ReadUlint without queuing
+func (c *EipClient) readUlint(tagName string) (uint64, error) {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	var result C.ulonglong
+	retCode := int(C.eip_read_ulint(C.int(c.id()), cTagName, &result))
+	if retCode != 0 {
+		return 0, &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to read ULINT tag %s", tagName),
+		}
+	}
+
+	return uint64(result), nil
+}
+
+// WriteUlint writes an unsigned 64-bit integer to the PLC
+func (c *EipClient) WriteUlint(tagName string, value uint64) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, Ulint, value, func() error { return c.writeUlint(tagName, value) })
+	})
+}
+
+// writeUlint is WriteUlint without auditing
+func (c *EipClient) writeUlint(tagName string, value uint64) error {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	retCode := int(C.eip_write_ulint(C.int(c.id()), cTagName, C.ulonglong(value)))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to write ULINT tag %s", tagName),
+		}
+	}
+
+	return nil
+}
+
+// ReadReal reads a 32-bit float from the PLC
+func (c *EipClient) ReadReal(tagName string) (float64, error) {
+	var value float64
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readReal(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readReal is ReadReal without queuing
+func (c *EipClient) readReal(tagName string) (float64, error) {
+	buf, cTagName := getScalarBuf(tagName)
+	defer putScalarBuf(buf)
+
+	retCode := int(C.eip_read_real(C.int(c.id()), cTagName, &buf.d))
+	if retCode != 0 {
+		return 0, &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to read REAL tag %s", tagName),
+		}
+	}
+
+	return float64(buf.d), nil
+}
+
+// WriteReal writes a 32-bit float to the PLC
+func (c *EipClient) WriteReal(tagName string, value float64) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, Real, value, func() error { return c.writeReal(tagName, value) })
+	})
+}
+
+// writeReal is WriteReal without auditing
+func (c *EipClient) writeReal(tagName string, value float64) error {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	retCode := int(C.eip_write_real(C.int(c.id()), cTagName, C.double(value)))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to write REAL tag %s", tagName),
+		}
+	}
+
+	return nil
+}
+
+// ReadLreal reads a 64-bit float from the PLC
+func (c *EipClient) ReadLreal(tagName string) (float64, error) {
+	var value float64
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readLreal(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readLreal is ReadLreal without queuing
+func (c *EipClient) readLreal(tagName string) (float64, error) {
+	buf, cTagName := getScalarBuf(tagName)
+	defer putScalarBuf(buf)
+
+	retCode := int(C.eip_read_lreal(C.int(c.id()), cTagName, &buf.d))
+	if retCode != 0 {
+		return 0, &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to read LREAL tag %s", tagName),
+		}
+	}
+
+	return float64(buf.d), nil
+}
+
+// WriteLreal writes a 64-bit float to the PLC
+func (c *EipClient) WriteLreal(tagName string, value float64) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, Lreal, value, func() error { return c.writeLreal(tagName, value) })
+	})
+}
+
+// writeLreal is WriteLreal without auditing
+func (c *EipClient) writeLreal(tagName string, value float64) error {
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	retCode := int(C.eip_write_lreal(C.int(c.id()), cTagName, C.double(value)))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to write LREAL tag %s", tagName),
+		}
+	}
+
+	return nil
+}
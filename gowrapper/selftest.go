@@ -0,0 +1,217 @@
+package ethernetip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SelfTestOptions configures the checks SelfTest runs
+type SelfTestOptions struct {
+	// MetadataTag is the tag whose metadata is looked up; it defaults to
+	// ScratchTag, and the lookup is skipped if both are empty
+	MetadataTag string `json:"metadata_tag,omitempty"`
+	// ScratchTag is a tag set aside for the self-test. It is read, written
+	// with a different value and read back; the check is skipped if empty.
+	// Only BOOL, numeric and STRING tags are supported.
+	ScratchTag  string      `json:"scratch_tag,omitempty"`
+	ScratchType PlcDataType `json:"scratch_type,omitempty"`
+}
+
+// SelfTestStatus is the outcome of one self-test check
+type SelfTestStatus string
+
+const (
+	SelfTestPassed  SelfTestStatus = "passed"
+	SelfTestFailed  SelfTestStatus = "failed"
+	SelfTestSkipped SelfTestStatus = "skipped"
+)
+
+// Self-test check names, in the order they run
+const (
+	SelfTestSession  = "session"
+	SelfTestIdentity = "identity"
+	SelfTestMetadata = "metadata"
+	SelfTestScratch  = "scratch_tag"
+)
+
+// SelfTestCheck is the result of one check of a self-test
+type SelfTestCheck struct {
+	Name     string         `json:"name"`
+	Status   SelfTestStatus `json:"status"`
+	Duration time.Duration  `json:"duration"`
+	// Detail describes what was checked, e.g. the controller's product name
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// SelfTestReport is the result of SelfTest
+type SelfTestReport struct {
+	Started  time.Time       `json:"started"`
+	Duration time.Duration   `json:"duration"`
+	Passed   bool            `json:"passed"`
+	Checks   []SelfTestCheck `json:"checks"`
+	// Identity is the controller identity read by the identity check
+	Identity *DeviceIdentity `json:"identity,omitempty"`
+}
+
+// Check returns the result of the named check
+func (r *SelfTestReport) Check(name string) (SelfTestCheck, bool) {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return SelfTestCheck{}, false
+}
+
+// selfTestStep is a check run by runSelfTest. It returns a detail for the
+// report, or errSelfTestSkip to skip the check.
+type selfTestStep struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// errSelfTestSkip marks a check skipped by its options
+type errSelfTestSkip string
+
+func (e errSelfTestSkip) Error() string { return string(e) }
+
+// SelfTest verifies that the client can talk to the controller: the session
+// is registered, the Identity Object can be read, tag metadata can be looked
+// up and, if a scratch tag is configured, a write is read back. It runs at
+// startup to fail fast with a structured report instead of on the first
+// production read. Every check runs even if an earlier one fails; the report
+// is always returned, and the error is that of the first failed check.
+func (c *EipClient) SelfTest(ctx context.Context, opts SelfTestOptions) (*SelfTestReport, error) {
+	metadataTag := opts.MetadataTag
+	if metadataTag == "" {
+		metadataTag = opts.ScratchTag
+	}
+	var identity *DeviceIdentity
+	report, err := runSelfTest(ctx, []selfTestStep{
+		{SelfTestSession, func(ctx context.Context) (string, error) {
+			id := c.id()
+			if id <= 0 || !sessionHealthy(int32(id)) {
+				return "", NewEipError(ErrConnectionFailed, "no registered session")
+			}
+			return fmt.Sprintf("client %d", id), nil
+		}},
+		{SelfTestIdentity, func(ctx context.Context) (string, error) {
+			id, err := c.ReadIdentity()
+			if err != nil {
+				return "", err
+			}
+			identity = id
+			return fmt.Sprintf("%s rev %s, serial %08X", id.ProductName, id.Revision(), id.SerialNumber), nil
+		}},
+		{SelfTestMetadata, func(ctx context.Context) (string, error) {
+			if metadataTag == "" {
+				return "", errSelfTestSkip("no metadata or scratch tag configured")
+			}
+			meta, err := c.GetTagMetadata(metadataTag)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s: type 0x%04X, %d dimensions", metadataTag, meta.DataType, meta.ArrayDimension), nil
+		}},
+		{SelfTestScratch, func(ctx context.Context) (string, error) {
+			if opts.ScratchTag == "" {
+				return "", errSelfTestSkip("no scratch tag configured")
+			}
+			return c.checkScratchTag(opts.ScratchTag, opts.ScratchType)
+		}},
+	})
+	report.Identity = identity
+	return report, err
+}
+
+// checkScratchTag writes a value different from the tag's current one and
+// reads it back
+func (c *EipClient) checkScratchTag(tagName string, dataType PlcDataType) (string, error) {
+	current, err := c.ReadValue(tagName, dataType)
+	if err != nil {
+		return "", err
+	}
+	next, err := scratchValue(current)
+	if err != nil {
+		return "", err
+	}
+	if err := c.WriteValueVerified(tagName, next, WriteVerification{}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s: wrote and read back %v", tagName, next.Value), nil
+}
+
+// scratchValue returns a value of the same type that differs from current
+func scratchValue(current *PlcValue) (*PlcValue, error) {
+	switch current.Type {
+	case Bool:
+		b, _ := current.Value.(bool)
+		return &PlcValue{Type: Bool, Value: !b}, nil
+	case String:
+		s, _ := current.Value.(string)
+		next := "selftest " + time.Now().UTC().Format(time.RFC3339)
+		if next == s {
+			next += "."
+		}
+		return &PlcValue{Type: String, Value: next}, nil
+	}
+	n, ok := numericValue(current.Value)
+	if !ok {
+		return nil, NewEipError(ErrInvalidDataType, fmt.Sprintf("scratch tag must be BOOL, numeric or STRING, not %s", current.Type))
+	}
+	if n == 0 {
+		return NewPlcValue(current.Type, float64(1))
+	}
+	return NewPlcValue(current.Type, float64(0))
+}
+
+// runSelfTest runs the steps in order and collects their results. Once ctx
+// is done, the remaining checks fail with its error.
+func runSelfTest(ctx context.Context, steps []selfTestStep) (*SelfTestReport, error) {
+	report := &SelfTestReport{Started: time.Now(), Passed: true, Checks: make([]SelfTestCheck, 0, len(steps))}
+	var failed []string
+	var firstErr error
+	for _, step := range steps {
+		check := SelfTestCheck{Name: step.name}
+		start := time.Now()
+		detail, err := "", ctx.Err()
+		if err == nil {
+			detail, err = step.run(ctx)
+		}
+		check.Duration = time.Since(start)
+		check.Detail = detail
+
+		if skip, ok := err.(errSelfTestSkip); ok {
+			check.Status = SelfTestSkipped
+			check.Detail = string(skip)
+		} else if err != nil {
+			check.Status = SelfTestFailed
+			check.Error = err.Error()
+			report.Passed = false
+			failed = append(failed, step.name)
+			if firstErr == nil {
+				firstErr = err
+			}
+		} else {
+			check.Status = SelfTestPassed
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	report.Duration = time.Since(report.Started)
+
+	if firstErr == nil {
+		return report, nil
+	}
+	code := ErrConnectionFailed
+	var eipErr *EipError
+	if errors.As(firstErr, &eipErr) {
+		code = eipErr.Code
+	}
+	return report, NewEipErrorWithDetails(code,
+		fmt.Sprintf("self-test failed: %s: %v", strings.Join(failed, ", "), firstErr),
+		map[string]interface{}{"failed_checks": failed})
+}
@@ -0,0 +1,89 @@
+package ethernetip
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestRunSelfTest tests collecting check results into a report
+func TestRunSelfTest(t *testing.T) {
+	ran := 0
+	steps := []selfTestStep{
+		{"first", func(ctx context.Context) (string, error) { ran++; return "ok", nil }},
+		{"second", func(ctx context.Context) (string, error) {
+			ran++
+			return "", NewEipError(ErrTagNotFound, "no such tag")
+		}},
+		{"third", func(ctx context.Context) (string, error) { ran++; return "", errSelfTestSkip("not configured") }},
+		{"fourth", func(ctx context.Context) (string, error) { ran++; return "", errors.New("broken") }},
+	}
+	report, err := runSelfTest(context.Background(), steps)
+	if ran != 4 {
+		t.Errorf("Expected every check to run, ran %d", ran)
+	}
+	if report.Passed || len(report.Checks) != 4 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+	want := []SelfTestStatus{SelfTestPassed, SelfTestFailed, SelfTestSkipped, SelfTestFailed}
+	for i, check := range report.Checks {
+		if check.Status != want[i] {
+			t.Errorf("%s: got %s, want %s", check.Name, check.Status, want[i])
+		}
+	}
+	if check, _ := report.Check("third"); check.Detail != "not configured" {
+		t.Errorf("Expected the skip reason as detail, got %q", check.Detail)
+	}
+
+	var eipErr *EipError
+	if !errors.As(err, &eipErr) || eipErr.Code != ErrTagNotFound {
+		t.Fatalf("Expected the first failure's code, got %v", err)
+	}
+	if failed, _ := eipErr.Details["failed_checks"].([]string); len(failed) != 2 || failed[0] != "second" || failed[1] != "fourth" {
+		t.Errorf("Unexpected failed checks %v", failed)
+	}
+}
+
+// TestRunSelfTestCancelled tests that a done context fails the remaining checks
+func TestRunSelfTestCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	report, err := runSelfTest(ctx, []selfTestStep{
+		{"first", func(ctx context.Context) (string, error) { cancel(); return "", nil }},
+		{"second", func(ctx context.Context) (string, error) { t.Error("Expected the check not to run"); return "", nil }},
+	})
+	if err == nil || report.Checks[0].Status != SelfTestPassed || report.Checks[1].Status != SelfTestFailed {
+		t.Errorf("Unexpected report %+v (%v)", report, err)
+	}
+}
+
+// TestScratchValue tests choosing a value that differs from the current one
+func TestScratchValue(t *testing.T) {
+	tests := []struct {
+		current *PlcValue
+		want    interface{}
+	}{
+		{&PlcValue{Type: Bool, Value: true}, false},
+		{&PlcValue{Type: Dint, Value: int32(0)}, int32(1)},
+		{&PlcValue{Type: Dint, Value: int32(7)}, int32(0)},
+		{&PlcValue{Type: Real, Value: float64(0)}, float64(1)},
+		{&PlcValue{Type: Usint, Value: uint8(255)}, uint8(0)},
+	}
+	for _, test := range tests {
+		next, err := scratchValue(test.current)
+		if err != nil {
+			t.Errorf("%v: %v", test.current, err)
+			continue
+		}
+		if next.Type != test.current.Type || next.Value != test.want {
+			t.Errorf("%v: got %#v, want %#v", test.current.Value, next.Value, test.want)
+		}
+	}
+
+	next, err := scratchValue(&PlcValue{Type: String, Value: "x"})
+	if err != nil || next.Value == "x" {
+		t.Errorf("Expected a different string, got %v (%v)", next, err)
+	}
+	if _, err := scratchValue(&PlcValue{Type: Udt, Value: map[string]interface{}{}}); err == nil {
+		t.Error("Expected error for a UDT scratch tag")
+	}
+}
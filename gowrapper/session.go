@@ -0,0 +1,255 @@
+package ethernetip
+
+/*
+#include <stdlib.h>
+
+// Session management
+extern int eip_connect(const char* ip_address);
+extern int eip_disconnect(int client_id);
+extern int eip_check_health(int client_id, int* is_healthy);
+extern int eip_set_max_packet_size(int client_id, int size);
+extern int eip_set_route_path(int client_id, const unsigned char* path, int path_len);
+extern int eip_set_messaging_mode(int client_id, int mode);
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"unsafe"
+)
+
+// id returns the native client ID of the active session, re-opening a
+// session closed for inactivity (see SetIdleTimeout)
+func (c *EipClient) id() int {
+	return int(c.touch())
+}
+
+// sessionHealthy reports whether the native session id is alive
+func sessionHealthy(id int32) bool {
+	var healthy C.int
+	return C.eip_check_health(C.int(id), &healthy) == 0 && healthy != 0
+}
+
+// connectSession opens a native session (TCP connection and Register Session)
+// to the PLC and returns its client ID. The session takes a connection from
+// DefaultConnectionBudget, queuing if the controller's budget is used up.
+// The native connect cannot be interrupted, so if ctx ends first the call
+// returns and the session is closed once it has been opened.
+func connectSession(ctx context.Context, ipAddress string) (int32, error) {
+	if err := DefaultConnectionBudget.Acquire(ctx, ipAddress); err != nil {
+		return 0, err
+	}
+	connected := make(chan C.int, 1)
+	go func() {
+		cIPAddress := C.CString(ipAddress)
+		defer C.free(unsafe.Pointer(cIPAddress))
+		connected <- C.eip_connect(cIPAddress)
+	}()
+
+	var clientID C.int
+	select {
+	case clientID = <-connected:
+	case <-ctx.Done():
+		go func() {
+			if id := <-connected; id >= 0 {
+				disconnectSession(ipAddress, int32(id))
+			} else {
+				DefaultConnectionBudget.Release(ipAddress)
+			}
+		}()
+		return 0, NewEipErrorWithDetails(ErrTimeout, fmt.Sprintf("Timed out connecting to PLC at %s", ipAddress),
+			map[string]interface{}{"ip_address": ipAddress})
+	}
+	if clientID < 0 {
+		DefaultConnectionBudget.Release(ipAddress)
+		return 0, NewEipErrorWithDetails(ErrConnectionFailed,
+			fmt.Sprintf("Failed to connect to PLC at %s", ipAddress),
+			map[string]interface{}{
+				"ip_address": ipAddress,
+				"error_code": int(clientID),
+			})
+	}
+	return int32(clientID), nil
+}
+
+// disconnectSession closes a native session and returns its connection to
+// DefaultConnectionBudget. It returns the native result code.
+func disconnectSession(ipAddress string, id int32) int {
+	if id == 0 {
+		return 0
+	}
+	result := int(C.eip_disconnect(C.int(id)))
+	DefaultConnectionBudget.Release(ipAddress)
+	return result
+}
+
+// openSession connects a new session and applies the client's per-session
+// settings (packet size, route path, messaging mode and Forward Open
+// parameters) so it is ready to take over
+func (c *EipClient) openSession() (int32, error) {
+	id, err := connectSession(context.Background(), c.ipAddr)
+	if err != nil {
+		c.logf(slog.LevelError, "❌", "Failed to connect to PLC at %s: %v", c.ipAddr, err)
+		return 0, err
+	}
+	c.logf(slog.LevelInfo, "✅", "Successfully connected to PLC at %s with client ID %d", c.ipAddr, id)
+	C.eip_set_max_packet_size(C.int(id), C.int(c.packetSize()))
+	if path, _ := c.TargetProfile().routePath(); path != nil {
+		C.eip_set_route_path(C.int(id), (*C.uchar)(unsafe.Pointer(&path[0])), C.int(len(path)))
+	}
+	if params := c.forwardOpen.Load(); params != nil {
+		setForwardOpenParams(int(id), *params)
+	}
+	if mode := c.MessagingMode(); mode != MessagingUnconnected {
+		C.eip_set_messaging_mode(C.int(id), C.int(mode))
+	}
+	return id, nil
+}
+
+// SetWarmStandby enables or disables a warm standby session. When enabled the
+// client keeps a second, already registered session to the controller, and
+// failover swaps to it instead of performing a full TCP connect and Register
+// Session handshake. Connected (Forward Open) sessions are re-established on
+// first use after a swap.
+func (c *EipClient) SetWarmStandby(enabled bool) error {
+	c.sessionMu.Lock()
+	c.warm = enabled
+	c.sessionMu.Unlock()
+
+	if !enabled {
+		c.closeStandby()
+		return nil
+	}
+	return c.ensureStandby()
+}
+
+// HasWarmStandby reports whether a spare session is currently established
+func (c *EipClient) HasWarmStandby() bool {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	return c.standby != 0
+}
+
+// Failovers returns how many times the client has replaced its active session
+func (c *EipClient) Failovers() int64 {
+	return c.failovers.Load()
+}
+
+// Failover replaces the active session: with a warm standby the spare session
+// is swapped in immediately, otherwise a new session is connected. The old
+// session is closed. The keep-alive loop calls this when a health check fails;
+// applications may call it when they detect a dead connection themselves. It
+// is recorded as ReconnectExplicitClose; see FailoverFor to give the reason.
+func (c *EipClient) Failover() error {
+	return c.FailoverFor(ReconnectExplicitClose, nil)
+}
+
+// FailoverFor is Failover recording reason and the error that caused it in
+// the client's reconnect history (see SessionDiagnostics). The native driver
+// only reports whether a session is healthy, so applications that see an
+// encapsulation error or a TCP reset should pass ReconnectEncapsulationError
+// or ReconnectTCPReset.
+func (c *EipClient) FailoverFor(reason ReconnectReason, cause error) error {
+	clock := c.Clock()
+	start := clock.Now()
+	c.sessionMu.Lock()
+	next, warm := c.standby, true
+	c.standby = 0
+	c.sessionMu.Unlock()
+
+	event := ReconnectEvent{Time: start, Reason: reason, OldSession: c.session.Load()}
+	if next == 0 {
+		warm = false
+		var err error
+		if next, err = c.openSession(); err != nil {
+			event.Duration = clock.Now().Sub(start)
+			c.recordReconnect(event, cause, err)
+			return err
+		}
+	}
+
+	old := c.session.Swap(next)
+	disconnectSession(c.ipAddr, old)
+	c.failovers.Add(1)
+	event.OldSession, event.NewSession, event.Warm = old, next, warm
+	event.Duration = clock.Now().Sub(start)
+	c.recordReconnect(event, cause, nil)
+	c.logf(slog.LevelInfo, "🔁", "Replaced session %d with %d (warm standby: %v, reason: %s) in %v", old, next, warm, reason, event.Duration)
+	return nil
+}
+
+// ensureStandby opens a spare session if warm standby is enabled and none exists
+func (c *EipClient) ensureStandby() error {
+	c.sessionMu.Lock()
+	needed, gen := c.warm && c.standby == 0, c.standbyGen
+	c.sessionMu.Unlock()
+	if !needed {
+		return nil
+	}
+
+	id, err := c.openSession()
+	if err != nil {
+		return err
+	}
+
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	if !c.warm || c.standby != 0 || c.standbyGen != gen {
+		// Disabled, replaced or outdated by new settings while connecting
+		disconnectSession(c.ipAddr, id)
+		return nil
+	}
+	c.standby = id
+	return nil
+}
+
+// maintainStandby checks the spare session and re-establishes it if it has
+// been lost or consumed by a failover
+func (c *EipClient) maintainStandby() {
+	c.sessionMu.Lock()
+	standby := c.standby
+	c.sessionMu.Unlock()
+
+	if standby != 0 {
+		if sessionHealthy(standby) {
+			return
+		}
+		c.sessionMu.Lock()
+		if c.standby == standby {
+			c.standby = 0
+		}
+		c.sessionMu.Unlock()
+		disconnectSession(c.ipAddr, standby)
+	}
+	if err := c.ensureStandby(); err != nil {
+		c.logf(slog.LevelWarn, "⚠️", "Failed to establish standby session to %s: %v", c.ipAddr, err)
+	}
+}
+
+// closeStandby disconnects the spare session, if any
+func (c *EipClient) closeStandby() {
+	c.sessionMu.Lock()
+	standby := c.standby
+	c.standby = 0
+	c.sessionMu.Unlock()
+	if standby != 0 {
+		disconnectSession(c.ipAddr, standby)
+	}
+}
+
+// resetStandby discards the spare session after a per-session setting
+// (route, packet size, messaging mode or Forward Open parameters) has
+// changed, so a failover never swaps in a session that still talks to the
+// old processor or with the old parameters. The keep-alive opens a new spare
+// with the current settings.
+func (c *EipClient) resetStandby() {
+	c.sessionMu.Lock()
+	c.standbyGen++
+	standby := c.standby
+	c.standby = 0
+	c.sessionMu.Unlock()
+	if standby != 0 {
+		disconnectSession(c.ipAddr, standby)
+	}
+}
@@ -0,0 +1,81 @@
+package ethernetip
+
+import (
+	"testing"
+)
+
+// TestFailoverSwapsStandby tests that failover swaps in the warm standby session
+func TestFailoverSwapsStandby(t *testing.T) {
+	client := &EipClient{}
+	client.session.Store(-3)
+	client.warm = true
+	client.standby = -5
+
+	if err := client.Failover(); err != nil {
+		t.Fatalf("Failover failed: %v", err)
+	}
+	if client.id() != -5 {
+		t.Errorf("Expected standby session -5 to be active, got %d", client.id())
+	}
+	if client.HasWarmStandby() {
+		t.Error("Expected standby to be consumed by failover")
+	}
+	if client.Failovers() != 1 {
+		t.Errorf("Expected 1 failover, got %d", client.Failovers())
+	}
+}
+
+// TestSetWarmStandbyDisable tests that disabling warm standby drops the spare session
+func TestSetWarmStandbyDisable(t *testing.T) {
+	client := &EipClient{}
+	client.warm = true
+	client.standby = -7
+
+	if err := client.SetWarmStandby(false); err != nil {
+		t.Fatal(err)
+	}
+	if client.HasWarmStandby() || client.warm {
+		t.Error("Expected warm standby to be disabled")
+	}
+}
+
+// TestResetStandby tests that changed session settings drop the spare
+// session, which keeps the warm standby policy
+func TestResetStandby(t *testing.T) {
+	client := &EipClient{}
+	client.warm = true
+	client.standby = -7
+
+	client.resetStandby()
+	if client.HasWarmStandby() || !client.warm || client.standbyGen != 1 {
+		t.Errorf("Expected the spare session dropped, standby %d, generation %d", client.standby, client.standbyGen)
+	}
+}
+
+// TestWarmStandbyFailover tests failover against a real PLC
+func TestWarmStandbyFailover(t *testing.T) {
+	skipIfNoPlc(t)
+
+	client, err := NewClient(getTestPlcIP())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SetWarmStandby(true); err != nil {
+		t.Fatalf("Failed to open standby session: %v", err)
+	}
+	if !client.HasWarmStandby() {
+		t.Fatal("Expected a standby session")
+	}
+	before := client.GetClientID()
+	if err := client.Failover(); err != nil {
+		t.Fatalf("Failover failed: %v", err)
+	}
+	if client.GetClientID() == before {
+		t.Error("Expected a different session after failover")
+	}
+	if _, err := client.CheckHealth(); err != nil {
+		t.Errorf("Health check failed after failover: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package ethernetip
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// snapshotDoc is the JSON layout of a snapshot
+type snapshotDoc struct {
+	TakenAt    time.Time     `json:"taken_at"`
+	Controller string        `json:"controller,omitempty"`
+	Tags       []snapshotTag `json:"tags"`
+}
+
+// snapshotTag is one tag of a snapshot. The type is saved by name; TIME
+// values are saved as duration strings and non-finite REAL and LREAL values
+// as "NaN", "+Inf" or "-Inf", which JSON numbers cannot hold.
+type snapshotTag struct {
+	TagName  string      `json:"tag_name"`
+	DataType string      `json:"data_type"`
+	Value    interface{} `json:"value"`
+}
+
+// RestoreReport describes a snapshot restore tag by tag
+type RestoreReport struct {
+	TakenAt  time.Time        `json:"taken_at"`
+	Results  []TagWriteResult `json:"results"` // In snapshot order
+	Written  int              `json:"written"`
+	Verified int              `json:"verified"`
+	Failed   int              `json:"failed"`
+}
+
+// OK reports whether every tag was written and verified
+func (r *RestoreReport) OK() bool {
+	return r.Failed == 0
+}
+
+// Snapshot reads tags in one batch and returns their values as JSON that
+// Restore writes back, for example to save the state of a machine before
+// maintenance. The types of the tags come from the client's TagTypes. The
+// snapshot fails if a type is unknown or any tag cannot be read, so a
+// snapshot is never partial.
+func (c *EipClient) Snapshot(tags []string) ([]byte, error) {
+	if len(tags) == 0 {
+		return nil, NewEipError(ErrInvalidValue, "snapshot needs at least one tag")
+	}
+	group := c.NewTagGroup()
+	types := make([]PlcDataType, len(tags))
+	for i, tagName := range tags {
+		dataType, ok := c.tagTypes.Lookup(tagName)
+		if !ok {
+			return nil, NewEipErrorWithDetails(ErrInvalidDataType,
+				fmt.Sprintf("snapshot: no data type known for tag '%s'", tagName),
+				map[string]interface{}{"tag_name": tagName})
+		}
+		if dataType == Udt {
+			return nil, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("snapshot cannot hold UDT '%s'", tagName),
+				map[string]interface{}{"tag_name": tagName})
+		}
+		if err := group.Add(tagName, dataType); err != nil {
+			return nil, err
+		}
+		types[i] = dataType
+	}
+	values, err := group.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := snapshotDoc{TakenAt: c.Clock().Now().UTC(), Controller: c.GetIPAddress(), Tags: make([]snapshotTag, len(tags))}
+	for i, tagName := range tags {
+		value := values[tagName].Value
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		doc.Tags[i] = snapshotTag{TagName: tagName, DataType: types[i].String(), Value: jsonValue(value)}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Restore writes the values of a snapshot back to the controller and reads
+// them back. Tags are written in Multiple Service Packets, except strings
+// and BOOL members of integers, which are written one by one, then read back
+// in one batch and compared as by WriteValueVerified, using the client's
+// write verification tolerance and delay if set. The report gives the
+// outcome of every tag; if any tag was not written or verified, an
+// ErrBatchOperationFailed error naming them is returned with it. Nothing is
+// written if the snapshot cannot be parsed.
+func (c *EipClient) Restore(snapshot []byte) (*RestoreReport, error) {
+	dec := json.NewDecoder(bytes.NewReader(snapshot))
+	dec.UseNumber()
+	var doc snapshotDoc
+	if err := dec.Decode(&doc); err != nil {
+		return nil, NewEipError(ErrInvalidValue, fmt.Sprintf("failed to parse snapshot: %v", err))
+	}
+	if len(doc.Tags) == 0 {
+		return nil, NewEipError(ErrInvalidValue, "snapshot has no tags")
+	}
+
+	results := make([]TagWriteResult, len(doc.Tags))
+	for i, tag := range doc.Tags {
+		dataType, err := ParsePlcDataType(tag.DataType)
+		if err != nil || dataType == Udt {
+			return nil, NewEipErrorWithDetails(ErrInvalidDataType,
+				fmt.Sprintf("snapshot has an invalid data type '%s' for '%s'", tag.DataType, tag.TagName),
+				map[string]interface{}{"tag_name": tag.TagName, "type": tag.DataType})
+		}
+		value, err := snapshotValue(dataType, tag.Value)
+		if err != nil {
+			return nil, NewEipErrorWithDetails(ErrInvalidTagValue,
+				fmt.Sprintf("snapshot has an invalid %s value for '%s': %v", dataType, tag.TagName, err),
+				map[string]interface{}{"tag_name": tag.TagName, "type": dataType.String()})
+		}
+		results[i] = TagWriteResult{TagName: tag.TagName, Type: dataType, Value: value}
+	}
+
+	report := &RestoreReport{TakenAt: doc.TakenAt, Results: results}
+	var failed []string
+	report.Written, report.Verified, failed = c.writeTagsVerified(results)
+	report.Failed = len(failed)
+	return report, bindingError("snapshot restore", failed)
+}
+
+// snapshotValue converts a value of a snapshot to the Go type used for
+// dataType, accepting the strings Snapshot writes for non-finite floats
+func snapshotValue(dataType PlcDataType, v interface{}) (interface{}, error) {
+	if s, ok := v.(string); ok && (dataType == Real || dataType == Lreal) {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || !(math.IsNaN(f) || math.IsInf(f, 0)) {
+			return nil, fmt.Errorf("expected number, got %q", s)
+		}
+		return f, nil
+	}
+	return coerceValue(dataType, v)
+}
+
+// jsonValue returns v with non-finite floats, which JSON numbers cannot
+// hold, replaced by "NaN", "+Inf" or "-Inf"
+func jsonValue(v interface{}) interface{} {
+	if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return v
+}
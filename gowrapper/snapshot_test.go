@@ -0,0 +1,96 @@
+package ethernetip
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestSnapshotValue tests the conversion of snapshot values, including the
+// strings written for TIME and non-finite floats
+func TestSnapshotValue(t *testing.T) {
+	if v, err := snapshotValue(Real, "NaN"); err != nil || !math.IsNaN(v.(float64)) {
+		t.Errorf("Expected NaN, got %v (%v)", v, err)
+	}
+	if v, err := snapshotValue(Lreal, "-Inf"); err != nil || !math.IsInf(v.(float64), -1) {
+		t.Errorf("Expected -Inf, got %v (%v)", v, err)
+	}
+	if v, err := snapshotValue(Time, "1m30s"); err != nil || v != 90*time.Second {
+		t.Errorf("Expected 1m30s, got %v (%v)", v, err)
+	}
+	for _, tc := range []struct {
+		dataType PlcDataType
+		value    interface{}
+	}{
+		{Real, "1.5"},
+		{Real, "fast"},
+		{Sint, 300.0},
+		{Bool, "true"},
+	} {
+		if _, err := snapshotValue(tc.dataType, tc.value); err == nil {
+			t.Errorf("Expected %v to be rejected as %s", tc.value, tc.dataType)
+		}
+	}
+}
+
+// TestRestoreErrors tests the errors raised before a snapshot is taken or a
+// restore sends anything
+func TestRestoreErrors(t *testing.T) {
+	client := &EipClient{}
+	var eipErr *EipError
+
+	if _, err := client.Snapshot(nil); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidValue {
+		t.Errorf("Expected ErrInvalidValue for an empty snapshot, got %v", err)
+	}
+	if _, err := client.Snapshot([]string{"Unknown"}); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidDataType {
+		t.Errorf("Expected ErrInvalidDataType for a tag without a type, got %v", err)
+	}
+
+	for _, tc := range []struct {
+		snapshot string
+		code     int
+	}{
+		{`not json`, ErrInvalidValue},
+		{`{"tags": []}`, ErrInvalidValue},
+		{`{"tags": [{"tag_name": "A", "data_type": "FLOAT9", "value": 1}]}`, ErrInvalidDataType},
+		{`{"tags": [{"tag_name": "A", "data_type": "UDT", "value": 1}]}`, ErrInvalidDataType},
+		{`{"tags": [{"tag_name": "A", "data_type": "DINT", "value": 1}, {"tag_name": "B", "data_type": "SINT", "value": 300}]}`, ErrInvalidTagValue},
+	} {
+		if _, err := client.Restore([]byte(tc.snapshot)); !errors.As(err, &eipErr) || eipErr.Code != tc.code {
+			t.Errorf("Expected error %v restoring %s, got %v", tc.code, tc.snapshot, err)
+		}
+	}
+}
+
+// TestSnapshotRestore takes and restores a snapshot on a real PLC
+func TestSnapshotRestore(t *testing.T) {
+	skipIfNoPlc(t)
+
+	client, err := NewClient(getTestPlcIP())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	client.TagTypes().Set("TestDint", Dint)
+	client.TagTypes().Set("TestReal", Real)
+	if err := client.WriteValue("TestDint", &PlcValue{Type: Dint, Value: int32(42)}); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	snapshot, err := client.Snapshot([]string{"TestDint", "TestReal"})
+	if err != nil {
+		t.Fatalf("Failed to take snapshot: %v", err)
+	}
+
+	if err := client.WriteValue("TestDint", &PlcValue{Type: Dint, Value: int32(7)}); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	report, err := client.Restore(snapshot)
+	if err != nil || !report.OK() || report.Verified != 2 {
+		t.Fatalf("Failed to restore snapshot: %+v (%v)", report, err)
+	}
+	if v, err := client.ReadValue("TestDint", Dint); err != nil || v.Value != int32(42) {
+		t.Errorf("Expected the restore to bring back 42, got %v (%v)", v, err)
+	}
+}
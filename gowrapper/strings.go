@@ -0,0 +1,296 @@
+package ethernetip
+
+/*
+#include <stdlib.h>
+
+// String operations
+extern int eip_read_string(int client_id, const char* tag_name, char* result, int max_length);
+extern int eip_write_string(int client_id, const char* tag_name, const char* value);
+*/
+import "C"
+import (
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+)
+
+// String buffer sizes for ReadString, including the NUL terminator
+const (
+	// DefaultMaxStringSize is the largest string ReadString accepts unless
+	// changed with SetMaxStringSize
+	DefaultMaxStringSize = 64 * 1024
+	// initialStringBuffer is the first buffer tried; it holds any predefined STRING
+	initialStringBuffer = 1024
+)
+
+// stringBufferTooSmall is returned by eip_read_string when the value does not fit
+const stringBufferTooSmall = -2
+
+// SetMaxStringSize sets the largest string, in bytes including the NUL
+// terminator, that ReadString accepts and WriteString sends. Longer values
+// fail with ErrInvalidTagLength. Zero or less restores DefaultMaxStringSize.
+func (c *EipClient) SetMaxStringSize(size int) {
+	if size < 0 {
+		size = 0
+	}
+	c.maxStringSize.Store(int64(size))
+}
+
+// MaxStringSize returns the largest string ReadString accepts
+func (c *EipClient) MaxStringSize() int {
+	if size := int(c.maxStringSize.Load()); size > 0 {
+		return size
+	}
+	return DefaultMaxStringSize
+}
+
+// growStringBuffer returns the next buffer size to try after size was too
+// small, or false if size already reached max
+func growStringBuffer(size, max int) (int, bool) {
+	if size >= max {
+		return 0, false
+	}
+	if size *= 2; size > max {
+		size = max
+	}
+	return size, true
+}
+
+// stringType is the layout of a string structure: its handle, DATA capacity
+// and total size in bytes, excluding the handle
+type stringType struct {
+	handle   uint16
+	capacity int
+	size     int
+}
+
+// standard reports whether t is the predefined 82-byte STRING type, which the
+// native driver reads and writes
+func (t stringType) standard() bool {
+	return t.handle == logixStringHandle && t.capacity == logixStringMaxData
+}
+
+// stringTypeFor returns the string type of a tag, looking it up in the
+// discovered tag database and the controller's structure templates. It
+// returns false when the type is not known yet or the tag is not a string.
+func (c *EipClient) stringTypeFor(tagName string) (stringType, bool) {
+	key := c.TagNameOptions().Key(tagName)
+	if cached, ok := c.stringTypes.Load(key); ok {
+		return cached.(stringType), true
+	}
+	db := c.TagDatabase()
+	if db == nil {
+		return stringType{}, false
+	}
+	info, ok := db.Lookup(tagName)
+	if !ok || !info.IsStructure() || info.Dimensions() != 0 {
+		return stringType{}, false
+	}
+	template, err := c.GetTemplate(info.TypeCode())
+	if err != nil {
+		return stringType{}, false
+	}
+	capacity, ok := template.StringCapacity()
+	if !ok {
+		return stringType{}, false
+	}
+	t := stringType{handle: template.Handle, capacity: capacity, size: template.Size}
+	c.stringTypes.Store(key, t)
+	return t, true
+}
+
+// readCustomString reads a custom string type (e.g. STRING20 or a long
+// STRING) with Read Tag Fragmented, so values over one packet are supported
+func (c *EipClient) readCustomString(tagName string) (string, error) {
+	raw, code, err := c.readRaw(tagName)
+	if err != nil {
+		return "", err
+	}
+	if code != CIPTypeStruct || len(raw) < 6 {
+		return "", NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("tag %s is not a string structure", tagName),
+			map[string]interface{}{"tag_name": tagName, "cip_type": code})
+	}
+	data := raw[6:]
+	length := int(binary.LittleEndian.Uint32(raw[2:]))
+	if length > len(data) {
+		length = len(data)
+	}
+	return string(data[:length]), nil
+}
+
+// writeStringType writes value to a tag whose string type is known, encoding
+// the whole structure so no read is needed first
+func (c *EipClient) writeStringType(tagName string, t stringType, value string) error {
+	data, err := t.encode(value)
+	if err != nil {
+		return NewEipErrorWithDetails(ErrInvalidTagLength, fmt.Sprintf("cannot write string to %s: %v", tagName, err),
+			map[string]interface{}{"tag_name": tagName, "length": len(value), "capacity": t.capacity})
+	}
+	return c.writeRaw(tagName, CIPTypeStruct, data)
+}
+
+// encode returns value as a structure of type t, as written by writeRaw:
+// [handle UINT][LEN DINT][DATA], with unused DATA bytes and any padding after
+// DATA zeroed. Values longer than the DATA array are rejected, even when the
+// padding would hold them.
+func (t stringType) encode(value string) ([]byte, error) {
+	if len(value) > t.capacity {
+		return nil, fmt.Errorf("%d bytes exceed the %d-byte string capacity", len(value), t.capacity)
+	}
+	size := t.size
+	if size < 4+t.capacity {
+		size = 4 + t.capacity
+	}
+	data := make([]byte, 2+size)
+	binary.LittleEndian.PutUint16(data, t.handle)
+	binary.LittleEndian.PutUint32(data[2:], uint32(len(value)))
+	copy(data[6:], value)
+	return data, nil
+}
+
+// writeCustomString writes value to a string type that is not known yet. The
+// tag is read first to learn its structure handle; the DATA capacity comes
+// from the structure template, since the structure size includes up to 3
+// bytes of padding after DATA. The learned type is cached so later writes
+// skip the read.
+func (c *EipClient) writeCustomString(tagName, value string) error {
+	raw, code, err := c.readRaw(tagName)
+	if err != nil {
+		return err
+	}
+	if code != CIPTypeStruct || len(raw) < 6 {
+		return NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("tag %s is not a string structure", tagName),
+			map[string]interface{}{"tag_name": tagName, "cip_type": code})
+	}
+	t := stringType{handle: binary.LittleEndian.Uint16(raw), capacity: logixStringMaxData, size: len(raw) - 2}
+	if !t.standard() {
+		if t, err = c.stringTemplateType(tagName, t.handle); err != nil {
+			return err
+		}
+	}
+	c.stringTypes.Store(c.TagNameOptions().Key(tagName), t)
+	return c.writeStringType(tagName, t, value)
+}
+
+// stringTemplateType returns the string type of a tag whose structure handle
+// is handle, from the template named by the type code in its metadata. It is
+// used for tags the tag database does not know.
+func (c *EipClient) stringTemplateType(tagName string, handle uint16) (stringType, error) {
+	meta, err := c.GetTagMetadataCached(tagName)
+	if err != nil {
+		return stringType{}, err
+	}
+	template, err := c.GetTemplate(uint16(meta.DataType))
+	if err != nil {
+		return stringType{}, err
+	}
+	capacity, ok := template.StringCapacity()
+	if !ok || template.Handle != handle {
+		return stringType{}, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("tag %s is not a string structure", tagName),
+			map[string]interface{}{"tag_name": tagName, "template": template.Name})
+	}
+	return stringType{handle: handle, capacity: capacity, size: template.Size}, nil
+}
+
+// ReadString reads a string from the PLC, trimmed to the tag's .LEN. Strings
+// that do not fit the initial buffer are re-read with a larger one, up to
+// MaxStringSize. Custom string types (e.g. STRING20) found in the tag
+// database are read as structures with Read Tag Fragmented.
+func (c *EipClient) ReadString(tagName string) (string, error) {
+	var value string
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readString(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readString is ReadString without queuing
+func (c *EipClient) readString(tagName string) (string, error) {
+	if t, ok := c.stringTypeFor(tagName); ok && !t.standard() {
+		return c.readCustomString(tagName)
+	}
+
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	maxSize := c.MaxStringSize()
+	size := initialStringBuffer
+	if size > maxSize {
+		size = maxSize
+	}
+	for {
+		cResult := C.malloc(C.size_t(size))
+		retCode := int(C.eip_read_string(C.int(c.id()), cTagName, (*C.char)(cResult), C.int(size)))
+		if retCode == 0 {
+			value := C.GoString((*C.char)(cResult))
+			C.free(cResult)
+			return value, nil
+		}
+		C.free(cResult)
+
+		if retCode != stringBufferTooSmall {
+			return "", &EipError{
+				Code:    retCode,
+				Message: fmt.Sprintf("Failed to read STRING tag %s", tagName),
+			}
+		}
+		next, ok := growStringBuffer(size, maxSize)
+		if !ok {
+			return "", NewEipErrorWithDetails(ErrInvalidTagLength,
+				fmt.Sprintf("STRING tag %s is longer than the maximum string size", tagName),
+				map[string]interface{}{"tag_name": tagName, "max_string_size": maxSize})
+		}
+		size = next
+	}
+}
+
+// WriteString writes a string to the PLC. Custom string types (e.g. STRING20)
+// are detected from the tag database and their templates, or learned from the
+// tag's value when the native STRING write is rejected, and are written by
+// updating the whole .LEN/.DATA structure in one request.
+func (c *EipClient) WriteString(tagName string, value string) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, String, value, func() error { return c.writeString(tagName, value) })
+	})
+}
+
+// writeString is WriteString without auditing
+func (c *EipClient) writeString(tagName string, value string) error {
+	if max := c.MaxStringSize() - 1; len(value) > max {
+		return NewEipErrorWithDetails(ErrInvalidTagLength,
+			fmt.Sprintf("string of %d bytes exceeds the maximum string size", len(value)),
+			map[string]interface{}{"tag_name": tagName, "max_string_size": max + 1})
+	}
+	t, known := c.stringTypeFor(tagName)
+	if known && !t.standard() {
+		return c.writeStringType(tagName, t, value)
+	}
+	if len(value) > logixStringMaxData {
+		// The native driver only writes the 82-byte predefined STRING
+		return c.writeCustomString(tagName, value)
+	}
+
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	cValue := C.CString(value)
+	defer C.free(unsafe.Pointer(cValue))
+
+	retCode := int(C.eip_write_string(C.int(c.id()), cTagName, cValue))
+	if retCode != 0 {
+		if !known {
+			// The tag may be a custom string type (e.g. STRING20), which the
+			// native STRING write is rejected for
+			if err := c.writeCustomString(tagName, value); err == nil {
+				return nil
+			}
+		}
+		return &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to write STRING tag %s", tagName),
+		}
+	}
+
+	return nil
+}
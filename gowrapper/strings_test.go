@@ -0,0 +1,115 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// TestMaxStringSize tests the string size option
+func TestMaxStringSize(t *testing.T) {
+	client := &EipClient{}
+	if client.MaxStringSize() != DefaultMaxStringSize {
+		t.Errorf("Expected default %d, got %d", DefaultMaxStringSize, client.MaxStringSize())
+	}
+	client.SetMaxStringSize(4096)
+	if client.MaxStringSize() != 4096 {
+		t.Errorf("Expected 4096, got %d", client.MaxStringSize())
+	}
+	client.SetMaxStringSize(0)
+	if client.MaxStringSize() != DefaultMaxStringSize {
+		t.Error("Expected zero to restore the default")
+	}
+
+	client.SetMaxStringSize(10)
+	if err := client.WriteString("Message", strings.Repeat("x", 10)); err == nil {
+		t.Error("Expected error for a string without room for the terminator")
+	}
+}
+
+// TestGrowStringBuffer tests buffer growth on truncation
+func TestGrowStringBuffer(t *testing.T) {
+	sizes := []int{}
+	size, ok := 1024, true
+	for ok {
+		sizes = append(sizes, size)
+		size, ok = growStringBuffer(size, 5000)
+	}
+	want := []int{1024, 2048, 4096, 5000}
+	if len(sizes) != len(want) {
+		t.Fatalf("Expected sizes %v, got %v", want, sizes)
+	}
+	for i := range want {
+		if sizes[i] != want[i] {
+			t.Errorf("Expected sizes %v, got %v", want, sizes)
+		}
+	}
+}
+
+// TestStringTypeEncode tests encoding LEN and DATA of a string structure
+func TestStringTypeEncode(t *testing.T) {
+	// Custom string type with 200 DATA bytes
+	st := stringType{handle: 0x1234, capacity: 200, size: 204}
+	value := strings.Repeat("a", 120)
+	data, err := st.encode(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2+204 || binary.LittleEndian.Uint16(data) != 0x1234 {
+		t.Fatalf("Expected the structure shape to be kept")
+	}
+	if n := binary.LittleEndian.Uint32(data[2:]); n != 120 {
+		t.Errorf("Expected LEN 120, got %d", n)
+	}
+	if string(data[6:126]) != value || data[126] != 0 {
+		t.Error("Expected DATA to hold the value followed by zeros")
+	}
+	if _, err := st.encode(strings.Repeat("a", 201)); err == nil {
+		t.Error("Expected capacity error")
+	}
+
+	// STRING21 is 28 bytes: LEN, 21 DATA bytes and 3 bytes of padding
+	string21 := stringType{handle: 0x4321, capacity: 21, size: 28}
+	if data, err := string21.encode(strings.Repeat("a", 21)); err != nil || len(data) != 30 {
+		t.Errorf("Expected 21 bytes to fit, got %d bytes, %v", len(data), err)
+	}
+	if _, err := string21.encode(strings.Repeat("a", 22)); err == nil {
+		t.Error("Expected the padding not to count towards the capacity")
+	}
+}
+
+// TestStringTypeFor tests detection of custom string types from the tag
+// database and cached templates
+func TestStringTypeFor(t *testing.T) {
+	client := &EipClient{}
+	if _, ok := client.stringTypeFor("Message"); ok {
+		t.Error("Expected no string type without a tag database")
+	}
+
+	client.tagDB.Store(NewTagDatabase([]TagInfo{
+		{Name: "Message", SymbolType: symbolTypeStructBit | 0x0F10},
+		{Name: "Speed", SymbolType: CIPTypeDint},
+	}))
+	client.templates.Store(uint16(0x0F10), &StructTemplate{
+		Instance: 0x0F10,
+		Name:     "STRING20",
+		Handle:   0x1234,
+		Size:     24,
+		Members: []TemplateMember{
+			{Name: "LEN", Type: CIPTypeDint},
+			{Name: "DATA", Type: 0x2000 | CIPTypeSint, Info: 20, Offset: 4},
+		},
+	})
+
+	st, ok := client.stringTypeFor("Message")
+	if !ok || st.handle != 0x1234 || st.capacity != 20 || st.size != 24 || st.standard() {
+		t.Errorf("Unexpected string type: %+v, %v", st, ok)
+	}
+	if _, ok := client.stringTypeFor("Speed"); ok {
+		t.Error("Expected no string type for a DINT tag")
+	}
+
+	if err := client.WriteString("Message", strings.Repeat("x", 21)); err == nil {
+		t.Error("Expected error for a string over the type's capacity")
+	}
+}
@@ -0,0 +1,131 @@
+package ethernetip
+
+import (
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/subscribe"
+)
+
+// Client is the subset of tag operations needed by the polling machinery.
+// *EipClient implements it, and so can fakes or remote proxies.
+type Client = subscribe.Client
+
+// Poller drives periodic tag reads against a Client and delivers value
+// changes to subscribers. It is defined in the subscribe package, which
+// does not need the native library, so it can be used and tested with any
+// Client.
+type Poller = subscribe.Poller
+
+// DefaultStaleAfter is the number of poll intervals without a successful read
+// after which a subscribed tag is reported as stale
+const DefaultStaleAfter = subscribe.DefaultStaleAfter
+
+// NewPoller creates a Poller that reads tags through client
+func NewPoller(client Client) *Poller {
+	return subscribe.NewPoller(client)
+}
+
+// SubscribeToTag subscribes to changes in a tag value at a polling interval.
+// Subscriptions to the same tag, type and interval share a single poll.
+// Returns an unsubscribe function.
+func (c *EipClient) SubscribeToTag(tagName string, interval time.Duration, dataType PlcDataType, callback func(value interface{}, err error)) (unsubscribe func()) {
+	return c.poller.Subscribe(tagName, interval, dataType, callback)
+}
+
+// UnsubscribeFromAllTags stops all tag subscriptions
+func (c *EipClient) UnsubscribeFromAllTags() {
+	c.poller.UnsubscribeAll()
+}
+
+// Poller returns the poller that drives this client's tag subscriptions
+func (c *EipClient) Poller() *Poller {
+	return c.poller
+}
+
+// Async read for a tag
+func (c *EipClient) ReadTagAsync(tagName string, dataType PlcDataType) <-chan PlcValueResult {
+	return subscribe.ReadAsync(c, tagName, dataType)
+}
+
+// Async write for a tag
+func (c *EipClient) WriteTagAsync(tagName string, value *PlcValue) <-chan error {
+	return subscribe.WriteAsync(c, tagName, value)
+}
+
+// WaitForTagValue waits for a tag to reach a specific value
+func (c *EipClient) WaitForTagValue(tagName string, dataType PlcDataType, expectedValue interface{}, timeout time.Duration) error {
+	return subscribe.WaitForValue(c, tagName, dataType, expectedValue, timeout)
+}
+
+// WaitForTagCondition waits for a tag to satisfy a condition
+func (c *EipClient) WaitForTagCondition(tagName string, dataType PlcDataType, condition func(interface{}) bool, timeout time.Duration) error {
+	return subscribe.WaitForCondition(c, tagName, dataType, condition, timeout)
+}
+
+// ReadTagPeriodically reads a tag value periodically and sends updates to a channel
+func (c *EipClient) ReadTagPeriodically(tagName string, dataType PlcDataType, interval time.Duration) (<-chan *PlcValue, <-chan error) {
+	return subscribe.ReadPeriodically(c, tagName, dataType, interval)
+}
+
+// TagSample is a polled tag value together with its quality
+type TagSample = subscribe.TagSample
+
+// ReadCached returns the last polled value of a subscribed tag together with
+// its quality, without a round trip to the PLC. The second return value is
+// false when the tag is not subscribed.
+func (c *EipClient) ReadCached(tagName string, dataType PlcDataType) (TagSample, bool) {
+	return c.poller.Sample(tagName, dataType)
+}
+
+// SubscribeToTagSamples subscribes to a tag like SubscribeToTag, but delivers
+// TagSamples so that a tag that stops updating is reported as QualityStale.
+// Returns an unsubscribe function.
+func (c *EipClient) SubscribeToTagSamples(tagName string, interval time.Duration, dataType PlcDataType, callback func(sample TagSample)) (unsubscribe func()) {
+	return c.poller.SubscribeSamples(tagName, interval, dataType, callback)
+}
+
+// Deadband suppresses callbacks for small changes of a numeric tag (see
+// subscribe.Deadband)
+type Deadband = subscribe.Deadband
+
+// SubscribeToTagDeadband subscribes to a tag like SubscribeToTag, but only
+// calls callback when the value moves outside deadband. Subscribers with
+// different deadbands share one poll of the tag. Returns an unsubscribe
+// function.
+func (c *EipClient) SubscribeToTagDeadband(tagName string, interval time.Duration, dataType PlcDataType, deadband Deadband, callback func(value interface{}, err error)) (unsubscribe func()) {
+	return c.poller.SubscribeDeadband(tagName, interval, dataType, deadband, callback)
+}
+
+// HealthState is the health of a poll loop
+type HealthState = subscribe.HealthState
+
+const (
+	HealthOK       = subscribe.HealthOK
+	HealthDegraded = subscribe.HealthDegraded
+	HealthStalled  = subscribe.HealthStalled
+)
+
+// Default missed-scan thresholds for subscription health
+const (
+	DefaultDegradedAfter = subscribe.DefaultDegradedAfter
+	DefaultStalledAfter  = subscribe.DefaultStalledAfter
+)
+
+// SubscriptionHealth describes the health of one poll loop, which serves every
+// subscription of a tag, data type and interval
+type SubscriptionHealth = subscribe.SubscriptionHealth
+
+// HealthEvent reports a change of a poll loop's health state
+type HealthEvent = subscribe.HealthEvent
+
+// SubscriptionHealth returns the health of the client's poll loops
+func (c *EipClient) SubscriptionHealth() []SubscriptionHealth {
+	return c.poller.Health()
+}
+
+// OnSubscriptionHealthChange registers fn to be called when a subscription's
+// poll loop becomes degraded or stalled, or recovers. Returns a function that
+// removes the listener.
+func (c *EipClient) OnSubscriptionHealthChange(fn func(event HealthEvent)) (remove func()) {
+	return c.poller.OnHealthChange(fn)
+}
@@ -0,0 +1,195 @@
+package subscribe
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for keep-alive, retries, subscriptions and the
+// wait helpers. SystemClock is used unless one is set with SetClock; tests
+// set a FakeClock to drive timers without sleeping.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) ClockTimer
+	NewTicker(d time.Duration) ClockTicker
+}
+
+// ClockTimer is a single event created by a Clock, like time.Timer
+type ClockTimer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// ClockTicker is a repeating event created by a Clock, like time.Ticker
+type ClockTicker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// SystemClock is the real time of the time package
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) ClockTimer { return systemTimer{time.NewTimer(d)} }
+
+func (systemClock) NewTicker(d time.Duration) ClockTicker { return systemTicker{time.NewTicker(d)} }
+
+type systemTimer struct{ *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+type systemTicker struct{ *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// clockSource is implemented by clients that carry a Clock, such as
+// *ethernetip.EipClient
+type clockSource interface {
+	Clock() Clock
+}
+
+// ClockOf returns the clock of v if it has one and SystemClock otherwise
+func ClockOf(v interface{}) Clock {
+	if src, ok := v.(clockSource); ok {
+		if clock := src.Clock(); clock != nil {
+			return clock
+		}
+	}
+	return SystemClock
+}
+
+// Sleep waits for d on clock
+func Sleep(clock Clock, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	<-clock.NewTimer(d).C()
+}
+
+// FakeClock is a Clock that only moves when advanced, for deterministic tests
+// of time-driven behaviour. Timers and tickers fire during Advance, in time
+// order; like those of the time package, a tick is dropped if the previous
+// one was not received yet.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// NewFakeClock returns a FakeClock set to start
+func NewFakeClock(start time.Time) *FakeClock {
+	f := &FakeClock{now: start}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the clock's current time
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTimer returns a timer firing once the clock is advanced by d
+func (f *FakeClock) NewTimer(d time.Duration) ClockTimer {
+	return f.add(d, 0)
+}
+
+// NewTicker returns a ticker firing every d of advanced time
+func (f *FakeClock) NewTicker(d time.Duration) ClockTicker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	return fakeTicker{f.add(d, d)}
+}
+
+// Advance moves the clock forward by d, firing every timer and ticker due
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	end := f.now.Add(d)
+	for {
+		sort.SliceStable(f.waiters, func(i, j int) bool { return f.waiters[i].when.Before(f.waiters[j].when) })
+		if len(f.waiters) == 0 || f.waiters[0].when.After(end) {
+			break
+		}
+		t := f.waiters[0]
+		f.now = t.when
+		select {
+		case t.c <- t.when:
+		default:
+		}
+		if t.period > 0 {
+			t.when = t.when.Add(t.period)
+		} else {
+			f.remove(t)
+		}
+	}
+	f.now = end
+}
+
+// Waiters returns the number of pending timers and tickers
+func (f *FakeClock) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil waits until at least n timers and tickers are pending, so a test
+// can advance the clock once the code under test is waiting on it
+func (f *FakeClock) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// add registers a timer due after d, repeating every period if not zero
+func (f *FakeClock) add(d, period time.Duration) *fakeTimer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTimer{clock: f, when: f.now.Add(d), period: period, c: make(chan time.Time, 1)}
+	f.waiters = append(f.waiters, t)
+	f.cond.Broadcast()
+	return t
+}
+
+// remove unregisters t and reports whether it was pending. Callers hold f.mu.
+func (f *FakeClock) remove(t *fakeTimer) bool {
+	for i, w := range f.waiters {
+		if w == t {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTimer is a ClockTimer of a FakeClock and the events of a fakeTicker
+type fakeTimer struct {
+	clock  *FakeClock
+	when   time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+// Stop stops the timer and reports whether it was pending
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+// fakeTicker is a ClockTicker of a FakeClock
+type fakeTicker struct{ *fakeTimer }
+
+func (t fakeTicker) Stop() { t.fakeTimer.Stop() }
@@ -0,0 +1,83 @@
+package subscribe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// TestFakeClock tests that timers and tickers fire only when advanced
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	timer := clock.NewTimer(time.Second)
+	ticker := clock.NewTicker(400 * time.Millisecond)
+	if clock.Waiters() != 2 {
+		t.Fatalf("Expected 2 waiters, got %d", clock.Waiters())
+	}
+
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case <-timer.C():
+		t.Fatal("Timer fired early")
+	default:
+	}
+	if at := <-ticker.C(); !at.Equal(start.Add(400 * time.Millisecond)) {
+		t.Errorf("Expected the first tick at 400ms, got %v", at.Sub(start))
+	}
+	// The tick at 800ms was dropped like a real ticker's
+	select {
+	case <-ticker.C():
+		t.Fatal("Expected the unreceived tick to be dropped")
+	default:
+	}
+
+	clock.Advance(time.Millisecond)
+	if at := <-timer.C(); !at.Equal(start.Add(time.Second)) {
+		t.Errorf("Expected the timer at 1s, got %v", at.Sub(start))
+	}
+	if timer.Stop() {
+		t.Error("Expected Stop of a fired timer to report false")
+	}
+	ticker.Stop()
+	if clock.Waiters() != 0 || !clock.Now().Equal(start.Add(time.Second)) {
+		t.Errorf("Expected no waiters at 1s, got %d at %v", clock.Waiters(), clock.Now().Sub(start))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		Sleep(clock, time.Minute)
+		close(done)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-done
+}
+
+// TestPollerFakeClock tests subscription quality on a fake clock
+func TestPollerFakeClock(t *testing.T) {
+	fake := newFakeClient()
+	fake.set("Level", 1.5)
+	clock := NewFakeClock(time.Now())
+	poller := NewPoller(fake)
+	poller.SetClock(clock)
+	defer poller.Close()
+
+	poller.SubscribeSamples("Level", time.Second, types.Real, func(TagSample) {})
+	clock.BlockUntil(2) // the poll loop and the heartbeat
+	clock.Advance(time.Second)
+	waitFor(t, func() bool {
+		sample, _ := poller.Sample("Level", types.Real)
+		return sample.Quality == types.QualityGood
+	})
+
+	fake.mu.Lock()
+	fake.err = types.NewEipError(types.ErrTimeout, "timeout")
+	fake.mu.Unlock()
+	clock.Advance(time.Duration(DefaultStaleAfter) * time.Second)
+	if sample, _ := poller.Sample("Level", types.Real); sample.Quality != types.QualityStale {
+		t.Errorf("Expected types.QualityStale after %d intervals, got %v", DefaultStaleAfter, sample.Quality)
+	}
+}
@@ -0,0 +1,46 @@
+package subscribe
+
+import (
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// Deadband suppresses callbacks for small changes of a numeric tag, such as
+// a noisy analog REAL. A new value is delivered only when it moves outside
+// the band around the last value delivered, so a slow drift is reported
+// once it adds up. The band is the larger of Absolute and Percent of the
+// last value; the zero Deadband delivers every change.
+type Deadband struct {
+	// Absolute is the change, in engineering units, that must be exceeded
+	Absolute float64 `json:"absolute,omitempty"`
+	// Percent is the change, as a percentage of the magnitude of the last
+	// value delivered, that must be exceeded
+	Percent float64 `json:"percent,omitempty"`
+}
+
+// exceeded reports whether value lies outside the band around last.
+// Non-numeric values, NaN and infinities are delivered on any change.
+func (d Deadband) exceeded(last, value interface{}) bool {
+	if reflect.DeepEqual(last, value) {
+		return false
+	}
+	from, ok1 := types.NumericValue(last)
+	to, ok2 := types.NumericValue(value)
+	if !ok1 || !ok2 || math.IsNaN(from) || math.IsNaN(to) || math.IsInf(from, 0) || math.IsInf(to, 0) {
+		return true
+	}
+	band := math.Max(d.Absolute, d.Percent/100*math.Abs(from))
+	return math.Abs(to-from) > band
+}
+
+// SubscribeDeadband polls tagName every interval like Subscribe, but only
+// calls callback when the value moves outside deadband around the last value
+// delivered. Errors are always delivered. Returns an unsubscribe function.
+func (p *Poller) SubscribeDeadband(tagName string, interval time.Duration, dataType types.PlcDataType, deadband Deadband, callback func(value interface{}, err error)) (unsubscribe func()) {
+	deadband.Absolute = math.Max(deadband.Absolute, 0)
+	deadband.Percent = math.Max(deadband.Percent, 0)
+	return p.subscribe(tagName, interval, dataType, &pollSubscriber{callback: callback, deadband: deadband})
+}
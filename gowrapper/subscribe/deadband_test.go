@@ -0,0 +1,69 @@
+package subscribe
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// TestDeadbandExceeded tests the absolute and percent bands
+func TestDeadbandExceeded(t *testing.T) {
+	for _, tc := range []struct {
+		deadband    Deadband
+		last, value interface{}
+		want        bool
+	}{
+		{Deadband{}, 1.0, 1.0001, true},
+		{Deadband{}, 1.0, 1.0, false},
+		{Deadband{Absolute: 0.5}, 20.0, 20.4, false},
+		{Deadband{Absolute: 0.5}, 20.0, 20.5, false},
+		{Deadband{Absolute: 0.5}, 20.0, 19.4, true},
+		{Deadband{Percent: 10}, 200.0, 219.0, false},
+		{Deadband{Percent: 10}, 200.0, 221.0, true},
+		{Deadband{Percent: 10}, -200.0, -221.0, true},
+		{Deadband{Absolute: 5, Percent: 1}, 100.0, 104.0, false},
+		{Deadband{Absolute: 5}, int32(10), int32(16), true},
+		{Deadband{Absolute: 5}, 1.0, math.NaN(), true},
+		{Deadband{Absolute: 5}, true, false, true},
+	} {
+		if got := tc.deadband.exceeded(tc.last, tc.value); got != tc.want {
+			t.Errorf("%+v.exceeded(%v, %v) = %v, want %v", tc.deadband, tc.last, tc.value, got, tc.want)
+		}
+	}
+}
+
+// TestSubscribeDeadband tests that subscribers of one poll loop each apply
+// their own deadband against the last value they were given
+func TestSubscribeDeadband(t *testing.T) {
+	poller := NewPoller(newFakeClient())
+	poller.SetClock(NewFakeClock(time.Now()))
+	defer poller.Close()
+
+	var banded, all []interface{}
+	poller.SubscribeDeadband("Temp", time.Second, types.Real, Deadband{Absolute: 1}, func(value interface{}, err error) {
+		banded = append(banded, value)
+	})
+	poller.Subscribe("Temp", time.Second, types.Real, func(value interface{}, err error) {
+		all = append(all, value)
+	})
+	if len(poller.loops) != 1 {
+		t.Fatalf("Expected one shared poll loop, got %d", len(poller.loops))
+	}
+	var loop *pollLoop
+	for _, l := range poller.loops {
+		loop = l
+	}
+
+	// Drifting by 0.4 per scan is reported once it adds up to more than 1
+	for _, v := range []float64{20, 20.4, 20.8, 21.2, 21.1, 20.3} {
+		poller.dispatch(loop, &types.PlcValue{Type: types.Real, Value: v}, nil)
+	}
+	if want := []interface{}{20.0, 21.2}; len(banded) != len(want) || banded[0] != want[0] || banded[1] != want[1] {
+		t.Errorf("Expected %v with the deadband, got %v", want, banded)
+	}
+	if len(all) != 6 {
+		t.Errorf("Expected every change without a deadband, got %v", all)
+	}
+}
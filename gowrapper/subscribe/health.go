@@ -0,0 +1,284 @@
+package subscribe
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// HealthState is the health of a poll loop
+type HealthState int
+
+const (
+	// HealthOK means the loop is completing its scans on time
+	HealthOK HealthState = iota
+	// HealthDegraded means recent scans were missed, because reads failed or
+	// took longer than the interval
+	HealthDegraded
+	// HealthStalled means the loop has missed so many scans that its values
+	// should be considered frozen
+	HealthStalled
+)
+
+// Default missed-scan thresholds for subscription health
+const (
+	DefaultDegradedAfter = 2
+	DefaultStalledAfter  = 5
+)
+
+// healthCheckPeriod is how often the poller's heartbeat re-evaluates loops
+// that may be blocked in a read and cannot report on their own
+var healthCheckPeriod = 100 * time.Millisecond
+
+// String returns the name of the state
+func (s HealthState) String() string {
+	switch s {
+	case HealthDegraded:
+		return "degraded"
+	case HealthStalled:
+		return "stalled"
+	default:
+		return "ok"
+	}
+}
+
+// MarshalJSON encodes the state as its name
+func (s HealthState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// SubscriptionHealth describes the health of one poll loop, which serves every
+// subscription of a tag, data type and interval
+type SubscriptionHealth struct {
+	TagName  string            `json:"tag_name"`
+	Type     types.PlcDataType `json:"data_type"`
+	Interval time.Duration     `json:"interval"`
+	State    HealthState       `json:"state"`
+	// MissedScans counts the scans since the last successful read that failed
+	// or never completed
+	MissedScans int `json:"missed_scans"`
+	// LastScan is when the last read, successful or not, completed
+	LastScan time.Time `json:"last_scan"`
+	// LastSuccess is when the last successful read completed
+	LastSuccess time.Time `json:"last_success"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// HealthEvent reports a change of a poll loop's health state
+type HealthEvent struct {
+	SubscriptionHealth
+	Previous HealthState `json:"previous"`
+}
+
+// loopHealth is the scan bookkeeping of a poll loop
+type loopHealth struct {
+	started     time.Time
+	lastScan    time.Time
+	lastSuccess time.Time
+	failures    int // Consecutive failed scans
+	state       HealthState
+}
+
+// SetHealthThresholds sets after how many missed scans a poll loop becomes
+// degraded and stalled. Values below 1 keep the current setting; stalled is
+// raised to degraded if lower.
+func (p *Poller) SetHealthThresholds(degradedAfter, stalledAfter int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if degradedAfter >= 1 {
+		p.degradedAfter = degradedAfter
+	}
+	if stalledAfter >= 1 {
+		p.stalledAfter = stalledAfter
+	}
+	if p.stalledAfter < p.degradedAfter {
+		p.stalledAfter = p.degradedAfter
+	}
+}
+
+// Health returns the health of every poll loop, sorted by tag name and interval
+func (p *Poller) Health() []SubscriptionHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.Clock().Now()
+	health := make([]SubscriptionHealth, 0, len(p.loops))
+	for _, loop := range p.loops {
+		health = append(health, p.loopHealth(loop, now))
+	}
+	sort.Slice(health, func(i, j int) bool {
+		if health[i].TagName != health[j].TagName {
+			return health[i].TagName < health[j].TagName
+		}
+		return health[i].Interval < health[j].Interval
+	})
+	return health
+}
+
+// OnHealthChange registers fn to be called whenever a poll loop changes health
+// state, including recoveries to HealthOK. A heartbeat checks the loops even
+// while a read is blocked, so a hung loop is reported as it happens. fn runs
+// on the poller's goroutines and must not block. Returns a function that
+// removes the listener.
+func (p *Poller) OnHealthChange(fn func(event HealthEvent)) (remove func()) {
+	p.mu.Lock()
+	p.nextListener++
+	id := p.nextListener
+	if p.healthListeners == nil {
+		p.healthListeners = make(map[int]func(HealthEvent))
+	}
+	p.healthListeners[id] = fn
+	p.startHeartbeat()
+	p.mu.Unlock()
+
+	return func() {
+		p.mu.Lock()
+		delete(p.healthListeners, id)
+		p.mu.Unlock()
+	}
+}
+
+// startHeartbeat starts the goroutine that re-evaluates loop health and
+// sample quality every healthCheckPeriod. Must be called with p.mu held.
+func (p *Poller) startHeartbeat() {
+	if p.heartbeat != nil {
+		return
+	}
+	p.heartbeat = make(chan struct{})
+	stop := p.heartbeat
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := p.Clock().NewTicker(healthCheckPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C():
+				p.checkHealth()
+			}
+		}
+	}()
+}
+
+// checkHealth re-evaluates every loop, notifies listeners of changes and
+// delivers samples that went stale since the last read
+func (p *Poller) checkHealth() {
+	p.mu.Lock()
+	now := p.Clock().Now()
+	var events []HealthEvent
+	type batch struct {
+		loop       *pollLoop
+		ticket     uint64
+		deliveries []func()
+	}
+	var batches []batch
+	for _, loop := range p.loops {
+		if event, changed := p.updateHealth(loop, now); changed {
+			events = append(events, event)
+		}
+		sample := p.currentSample(loop, now)
+		var deliveries []func()
+		for _, sub := range loop.subscribers {
+			if sub.sampleCallback == nil {
+				continue
+			}
+			if deliver := sub.deliverSample(sample); deliver != nil {
+				deliveries = append(deliveries, deliver)
+			}
+		}
+		if len(deliveries) > 0 {
+			batches = append(batches, batch{loop, loop.deliveries.ticket(), deliveries})
+		}
+	}
+	listeners := p.listeners()
+	p.mu.Unlock()
+
+	// In ticket order with the loop's own deliveries, so a stale sample
+	// computed here cannot overtake a fresh one from the loop
+	for _, b := range batches {
+		b.loop.deliveries.deliver(b.ticket, b.deliveries)
+	}
+	notifyHealth(listeners, events)
+}
+
+// listeners returns a snapshot of the health listeners. Must be called with p.mu held.
+func (p *Poller) listeners() []func(HealthEvent) {
+	listeners := make([]func(HealthEvent), 0, len(p.healthListeners))
+	for _, fn := range p.healthListeners {
+		listeners = append(listeners, fn)
+	}
+	return listeners
+}
+
+// notifyHealth delivers events to listeners
+func notifyHealth(listeners []func(HealthEvent), events []HealthEvent) {
+	for _, event := range events {
+		for _, fn := range listeners {
+			fn(event)
+		}
+	}
+}
+
+// recordScan updates the loop's scan bookkeeping after a read. Must be called
+// with p.mu held.
+func (p *Poller) recordScan(loop *pollLoop, now time.Time, err error) {
+	loop.health.lastScan = now
+	if err != nil {
+		loop.health.failures++
+		return
+	}
+	loop.health.lastSuccess = now
+	loop.health.failures = 0
+}
+
+// updateHealth re-evaluates the loop's state and reports whether it changed.
+// Must be called with p.mu held.
+func (p *Poller) updateHealth(loop *pollLoop, now time.Time) (HealthEvent, bool) {
+	health := p.loopHealth(loop, now)
+	previous := loop.health.state
+	if health.State == previous {
+		return HealthEvent{}, false
+	}
+	loop.health.state = health.State
+	return HealthEvent{SubscriptionHealth: health, Previous: previous}, true
+}
+
+// loopHealth computes the loop's health at now. Missed scans are the failed
+// scans since the last success plus the intervals that passed without any
+// scan completing, allowing one interval for the read in progress. Must be
+// called with p.mu held.
+func (p *Poller) loopHealth(loop *pollLoop, now time.Time) SubscriptionHealth {
+	h := loop.health
+	last := h.lastScan
+	if last.IsZero() {
+		last = h.started
+	}
+	missed := h.failures
+	if overdue := int(now.Sub(last)/loop.key.interval) - 1; overdue > 0 {
+		missed += overdue
+	}
+
+	state := HealthOK
+	switch {
+	case missed >= p.stalledAfter:
+		state = HealthStalled
+	case missed >= p.degradedAfter:
+		state = HealthDegraded
+	}
+	health := SubscriptionHealth{
+		TagName:     loop.tagName,
+		Type:        loop.key.dataType,
+		Interval:    loop.key.interval,
+		State:       state,
+		MissedScans: missed,
+		LastScan:    h.lastScan,
+		LastSuccess: h.lastSuccess,
+	}
+	if loop.sample.Err != nil {
+		health.LastError = loop.sample.Err.Error()
+	}
+	return health
+}
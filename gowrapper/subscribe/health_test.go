@@ -0,0 +1,117 @@
+package subscribe
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// blockingClient is a Client whose reads block while gate is held
+type blockingClient struct {
+	*fakeClient
+	gate sync.RWMutex
+}
+
+func (b *blockingClient) ReadValue(tagName string, dataType types.PlcDataType) (*types.PlcValue, error) {
+	b.gate.RLock()
+	defer b.gate.RUnlock()
+	return b.fakeClient.ReadValue(tagName, dataType)
+}
+
+// healthEvents collects health events of a poller
+func healthEvents(p *Poller) (events func() []HealthEvent) {
+	var mu sync.Mutex
+	var seen []HealthEvent
+	p.OnHealthChange(func(event HealthEvent) {
+		mu.Lock()
+		seen = append(seen, event)
+		mu.Unlock()
+	})
+	return func() []HealthEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]HealthEvent(nil), seen...)
+	}
+}
+
+// hasState reports whether events contains a transition to state
+func hasState(events []HealthEvent, state HealthState) bool {
+	for _, event := range events {
+		if event.State == state {
+			return true
+		}
+	}
+	return false
+}
+
+// TestHealthDegradesOnFailures tests that failing reads degrade and stall a
+// loop and that it recovers once reads succeed
+func TestHealthDegradesOnFailures(t *testing.T) {
+	fake := newFakeClient()
+	fake.set("Level", 1.5)
+	poller := NewPoller(fake)
+	defer poller.Close()
+	poller.SetHealthThresholds(2, 4)
+	events := healthEvents(poller)
+
+	unsubscribe := poller.Subscribe("Level", 5*time.Millisecond, types.Real, func(interface{}, error) {})
+	defer unsubscribe()
+	waitFor(t, func() bool { return len(poller.Health()) == 1 && !poller.Health()[0].LastSuccess.IsZero() })
+	if state := poller.Health()[0].State; state != HealthOK {
+		t.Errorf("Expected a healthy loop, got %v", state)
+	}
+
+	fake.setErr(errors.New("connection lost"))
+	waitFor(t, func() bool { return hasState(events(), HealthStalled) })
+	if !hasState(events(), HealthDegraded) {
+		t.Error("Expected the loop to degrade before it stalled")
+	}
+	if health := poller.Health()[0]; health.LastError != "connection lost" || health.MissedScans < 4 {
+		t.Errorf("Unexpected stalled health: %+v", health)
+	}
+
+	fake.setErr(nil)
+	waitFor(t, func() bool {
+		all := events()
+		return all[len(all)-1].State == HealthOK
+	})
+	if last := events()[len(events())-1]; last.Previous != HealthStalled || last.TagName != "Level" {
+		t.Errorf("Unexpected recovery event: %+v", last)
+	}
+}
+
+// TestHealthDetectsBlockedReads tests that the heartbeat reports a loop whose
+// read never returns
+func TestHealthDetectsBlockedReads(t *testing.T) {
+	saved := healthCheckPeriod
+	healthCheckPeriod = 5 * time.Millisecond
+	defer func() { healthCheckPeriod = saved }()
+
+	client := &blockingClient{fakeClient: newFakeClient()}
+	client.set("Count", int32(1))
+	poller := NewPoller(client)
+	defer poller.Close()
+	events := healthEvents(poller)
+
+	unsubscribe := poller.Subscribe("Count", 5*time.Millisecond, types.Dint, func(interface{}, error) {})
+	defer unsubscribe()
+	waitFor(t, func() bool { return !poller.Health()[0].LastSuccess.IsZero() })
+
+	client.gate.Lock()
+	waitFor(t, func() bool { return hasState(events(), HealthStalled) })
+	client.gate.Unlock()
+	if health := poller.Health(); len(health) != 1 {
+		t.Errorf("Expected one loop, got %d", len(health))
+	}
+}
+
+// TestHealthStateJSON tests the JSON form of health states
+func TestHealthStateJSON(t *testing.T) {
+	data, _ := HealthDegraded.MarshalJSON()
+	if string(data) != `"degraded"` {
+		t.Errorf("Expected \"degraded\", got %s", data)
+	}
+}
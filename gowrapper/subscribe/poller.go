@@ -0,0 +1,447 @@
+package subscribe
+
+import (
+	"hash/fnv"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/tagpath"
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// pollKey identifies a poll loop. Subscriptions with the same tag, data type
+// and interval are coalesced onto a single loop so the tag is read once per
+// interval no matter how many subscribers there are. tagName is the name's
+// tagpath.NameOptions key.
+type pollKey struct {
+	tagName  string
+	dataType types.PlcDataType
+	interval time.Duration
+}
+
+// pollSubscriber is a single callback attached to a poll loop. Exactly one of
+// callback and sampleCallback is set.
+type pollSubscriber struct {
+	callback       func(value interface{}, err error)
+	sampleCallback func(sample TagSample)
+	lastValue      interface{}
+	lastQuality    types.Quality
+	hasValue       bool
+	// deadband filters the values of callback (see deadband.go)
+	deadband Deadband
+}
+
+// deliverSample records sample as seen by a sample subscriber and returns
+// the call delivering it, or nil when neither the value nor the quality
+// changed. Must be called with the poller's lock held.
+func (sub *pollSubscriber) deliverSample(sample TagSample) func() {
+	if sub.hasValue && sub.lastQuality == sample.Quality && reflect.DeepEqual(sub.lastValue, sample.Value) {
+		return nil
+	}
+	if !sub.hasValue && sample.Quality == types.QualityUncertain {
+		return nil
+	}
+	sub.lastValue = sample.Value
+	sub.lastQuality = sample.Quality
+	sub.hasValue = true
+	return func() { sub.sampleCallback(sample) }
+}
+
+// pollLoop reads one tag periodically and notifies its subscribers
+type pollLoop struct {
+	key         pollKey
+	tagName     string // Name read from the PLC, as spelled by the first subscriber
+	subscribers map[int]*pollSubscriber
+	stop        chan struct{}
+	phase       time.Duration // Wait before the first tick when spreading
+
+	// Most recent state of the tag, used for quality tracking and cached reads
+	sample TagSample
+	// Scan bookkeeping for subscription health (see health.go)
+	health loopHealth
+	// The loop goroutine and the health heartbeat both deliver samples;
+	// deliveries keeps them in order and one at a time
+	deliveries deliveryOrder
+}
+
+// deliveryOrder runs batches of callbacks one at a time, in the order their
+// tickets were taken, so a batch computed earlier never overtakes a later
+// one and callbacks never run concurrently with themselves
+type deliveryOrder struct {
+	taken uint64 // Last ticket taken, under the poller's lock
+
+	mu   sync.Mutex
+	cond sync.Cond
+	done uint64 // Last ticket delivered
+}
+
+// ticket reserves the next batch. Must be called with the poller's lock held.
+func (d *deliveryOrder) ticket() uint64 {
+	d.taken++
+	return d.taken
+}
+
+// deliver runs the batch of ticket once every earlier batch has run. It must
+// be called exactly once for every ticket taken.
+func (d *deliveryOrder) deliver(ticket uint64, deliveries []func()) {
+	d.mu.Lock()
+	if d.cond.L == nil {
+		d.cond.L = &d.mu
+	}
+	for d.done != ticket-1 {
+		d.cond.Wait()
+	}
+	d.mu.Unlock()
+
+	for _, deliver := range deliveries {
+		deliver()
+	}
+
+	d.mu.Lock()
+	d.done = ticket
+	d.cond.Broadcast()
+	d.mu.Unlock()
+}
+
+// Poller drives periodic tag reads against a Client and delivers value changes
+// to subscribers.
+type Poller struct {
+	client Client
+	clock  atomic.Pointer[Clock] // Set with SetClock; nil means the client's
+
+	mu         sync.Mutex
+	loops      map[pollKey]*pollLoop
+	owners     map[int]*pollLoop
+	nextID     int
+	staleAfter int
+	names      tagpath.NameOptions
+	spread     bool
+	wg         sync.WaitGroup
+
+	// Subscription health (see health.go); heartbeat is closed by Close
+	degradedAfter   int
+	stalledAfter    int
+	healthListeners map[int]func(HealthEvent)
+	nextListener    int
+	heartbeat       chan struct{}
+}
+
+// DefaultStaleAfter is the number of poll intervals without a successful read
+// after which a subscribed tag is reported as stale
+const DefaultStaleAfter = 3
+
+// NewPoller creates a Poller that reads tags through client
+func NewPoller(client Client) *Poller {
+	return &Poller{
+		client:        client,
+		loops:         make(map[pollKey]*pollLoop),
+		owners:        make(map[int]*pollLoop),
+		staleAfter:    DefaultStaleAfter,
+		degradedAfter: DefaultDegradedAfter,
+		stalledAfter:  DefaultStalledAfter,
+	}
+}
+
+// Clock returns the clock the poller runs on: the one set with SetClock, or
+// else the client's if it has one
+func (p *Poller) Clock() Clock {
+	if clock := p.clock.Load(); clock != nil {
+		return *clock
+	}
+	return ClockOf(p.client)
+}
+
+// SetClock sets the clock of poll loops and the health heartbeat started
+// afterwards; nil returns to the client's clock
+func (p *Poller) SetClock(clock Clock) {
+	if clock == nil {
+		p.clock.Store(nil)
+		return
+	}
+	p.clock.Store(&clock)
+}
+
+// SetStaleAfter sets how many poll intervals may pass without a successful
+// read before a tag's quality becomes types.QualityStale
+func (p *Poller) SetStaleAfter(intervals int) {
+	if intervals < 1 {
+		intervals = 1
+	}
+	p.mu.Lock()
+	p.staleAfter = intervals
+	p.mu.Unlock()
+}
+
+// SetTagNameOptions sets how subscribed tag names are matched, so that for
+// example "Motor1" and "motor1" share one poll loop. It applies to
+// subscriptions made afterwards.
+func (p *Poller) SetTagNameOptions(opts tagpath.NameOptions) {
+	p.mu.Lock()
+	p.names = opts
+	p.mu.Unlock()
+}
+
+// SetPhaseSpread staggers poll loops across their interval instead of
+// ticking from the moment they are subscribed. Each loop reads at a fixed
+// phase of its interval derived from its tag and type, so hundreds of tags
+// subscribed together at one interval are read spread out over the interval
+// rather than in a burst. The first read of a loop is delayed by up to one
+// interval. It applies to loops started afterwards.
+func (p *Poller) SetPhaseSpread(enabled bool) {
+	p.mu.Lock()
+	p.spread = enabled
+	p.mu.Unlock()
+}
+
+// pollPhase returns how long a loop started at now waits to reach its phase:
+// the offset into the interval, derived from the tag key and type, at which
+// the loop reads
+func pollPhase(key pollKey, now time.Time) time.Duration {
+	if key.interval <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key.tagName))
+	h.Write([]byte{byte(key.dataType), byte(key.dataType >> 8)})
+	interval := uint64(key.interval)
+	offset := h.Sum64() % interval
+	return time.Duration((offset + interval - uint64(now.UnixNano())%interval) % interval)
+}
+
+// TagKey returns the key under which tagName is polled: two names with the
+// same key share poll loops (see SetTagNameOptions)
+func (p *Poller) TagKey(tagName string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.names.Key(tagName)
+}
+
+// Subscribe polls tagName every interval and calls callback with the new value
+// whenever it changes, or with the error when a read fails.
+// Returns an unsubscribe function.
+func (p *Poller) Subscribe(tagName string, interval time.Duration, dataType types.PlcDataType, callback func(value interface{}, err error)) (unsubscribe func()) {
+	return p.subscribe(tagName, interval, dataType, &pollSubscriber{callback: callback})
+}
+
+// SubscribeSamples polls tagName every interval and calls callback with a
+// TagSample whenever the value or its quality changes. Unlike Subscribe, a tag
+// that stops updating is reported as types.QualityStale instead of silently keeping
+// its last value, even while a read is blocked: the health heartbeat
+// re-evaluates the quality from the sample's timestamp. Samples are delivered
+// one at a time, in the order they were taken. Returns an unsubscribe
+// function.
+func (p *Poller) SubscribeSamples(tagName string, interval time.Duration, dataType types.PlcDataType, callback func(sample TagSample)) (unsubscribe func()) {
+	return p.subscribe(tagName, interval, dataType, &pollSubscriber{sampleCallback: callback})
+}
+
+// subscribe attaches sub to the poll loop for the given tag, creating the loop if needed
+func (p *Poller) subscribe(tagName string, interval time.Duration, dataType types.PlcDataType, sub *pollSubscriber) (unsubscribe func()) {
+	p.mu.Lock()
+	tagName = p.names.Clean(tagName)
+	key := pollKey{tagName: p.names.Key(tagName), dataType: dataType, interval: interval}
+	loop, ok := p.loops[key]
+	if !ok {
+		now := p.Clock().Now()
+		var phase time.Duration
+		if p.spread {
+			phase = pollPhase(key, now)
+		}
+		loop = &pollLoop{
+			key:         key,
+			tagName:     tagName,
+			subscribers: make(map[int]*pollSubscriber),
+			stop:        make(chan struct{}),
+			phase:       phase,
+			sample:      TagSample{TagName: tagName, Type: dataType, Quality: types.QualityUncertain},
+			// The loop is not overdue while it waits for its phase
+			health: loopHealth{started: now.Add(phase)},
+		}
+		p.loops[key] = loop
+		p.wg.Add(1)
+		go p.run(loop)
+	}
+	p.nextID++
+	id := p.nextID
+	loop.subscribers[id] = sub
+	p.owners[id] = loop
+	if sub.sampleCallback != nil {
+		// A read that never returns must still turn the sample stale
+		p.startHeartbeat()
+	}
+	p.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { p.unsubscribe(id) })
+	}
+}
+
+// unsubscribe removes a subscriber and stops its loop when it was the last one
+func (p *Poller) unsubscribe(id int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	loop, ok := p.owners[id]
+	if !ok {
+		return
+	}
+	delete(p.owners, id)
+	delete(loop.subscribers, id)
+	if len(loop.subscribers) == 0 {
+		close(loop.stop)
+		delete(p.loops, loop.key)
+	}
+}
+
+// UnsubscribeAll stops every poll loop and removes all subscribers
+func (p *Poller) UnsubscribeAll() {
+	p.mu.Lock()
+	for key, loop := range p.loops {
+		close(loop.stop)
+		delete(p.loops, key)
+	}
+	p.owners = make(map[int]*pollLoop)
+	p.mu.Unlock()
+}
+
+// Close stops all subscriptions and the health heartbeat and waits for the
+// poll loops to exit
+func (p *Poller) Close() {
+	p.UnsubscribeAll()
+	p.mu.Lock()
+	if p.heartbeat != nil {
+		close(p.heartbeat)
+		p.heartbeat = nil
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+// SubscriptionCount returns the number of active subscribers
+func (p *Poller) SubscriptionCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.owners)
+}
+
+// Sample returns the most recent polled state of a subscribed tag without
+// touching the PLC. The second return value is false when no subscription
+// polls the tag. When several intervals poll the same tag, the freshest
+// sample is returned.
+func (p *Poller) Sample(tagName string, dataType types.PlcDataType) (TagSample, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best TagSample
+	found := false
+	tagKey := p.names.Key(tagName)
+	for key, loop := range p.loops {
+		if key.tagName != tagKey || key.dataType != dataType {
+			continue
+		}
+		sample := p.currentSample(loop, p.Clock().Now())
+		if !found || sample.Timestamp.After(best.Timestamp) {
+			best = sample
+			found = true
+		}
+	}
+	return best, found
+}
+
+// currentSample returns the loop's sample with its quality re-evaluated at now.
+// Must be called with p.mu held.
+func (p *Poller) currentSample(loop *pollLoop, now time.Time) TagSample {
+	sample := loop.sample
+	if sample.Quality == types.QualityGood && now.Sub(sample.Timestamp) >= time.Duration(p.staleAfter)*loop.key.interval {
+		sample.Quality = types.QualityStale
+	}
+	return sample
+}
+
+// run is the body of a poll loop goroutine
+func (p *Poller) run(loop *pollLoop) {
+	defer p.wg.Done()
+	clock := p.Clock()
+	if loop.phase > 0 {
+		timer := clock.NewTimer(loop.phase)
+		select {
+		case <-loop.stop:
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+	}
+	ticker := clock.NewTicker(loop.key.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-loop.stop:
+			return
+		case <-ticker.C():
+			val, err := p.client.ReadValue(loop.tagName, loop.key.dataType)
+			p.dispatch(loop, val, err)
+		}
+	}
+}
+
+// dispatch records a poll result and delivers it to the subscribers of loop.
+// Value callbacks receive values they have not yet seen, or that left their
+// deadband, and every error; sample callbacks receive the sample whenever its
+// value or quality changes.
+func (p *Poller) dispatch(loop *pollLoop, val *types.PlcValue, err error) {
+	p.mu.Lock()
+	select {
+	case <-loop.stop:
+		p.mu.Unlock()
+		return
+	default:
+	}
+
+	now := p.Clock().Now()
+	if err != nil {
+		loop.sample.Err = err
+	} else {
+		loop.sample.Value = val.Value
+		loop.sample.Quality = types.QualityGood
+		loop.sample.Timestamp = now
+		loop.sample.Err = nil
+	}
+	sample := p.currentSample(loop, now)
+	p.recordScan(loop, now, err)
+	var events []HealthEvent
+	if event, changed := p.updateHealth(loop, now); changed {
+		events = append(events, event)
+	}
+	listeners := p.listeners()
+
+	deliveries := make([]func(), 0, len(loop.subscribers))
+	for _, sub := range loop.subscribers {
+		sub := sub
+		if sub.sampleCallback != nil {
+			if deliver := sub.deliverSample(sample); deliver != nil {
+				deliveries = append(deliveries, deliver)
+			}
+			continue
+		}
+		if err != nil {
+			deliveries = append(deliveries, func() { sub.callback(nil, err) })
+			continue
+		}
+		if sub.hasValue && !sub.deadband.exceeded(sub.lastValue, val.Value) {
+			continue
+		}
+		sub.lastValue = val.Value
+		sub.hasValue = true
+		value := val.Value
+		deliveries = append(deliveries, func() { sub.callback(value, nil) })
+	}
+	ticket := loop.deliveries.ticket()
+	p.mu.Unlock()
+
+	// Callbacks run outside the lock so they may unsubscribe
+	loop.deliveries.deliver(ticket, deliveries)
+	notifyHealth(listeners, events)
+}
@@ -0,0 +1,264 @@
+package subscribe
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/eiptest"
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/tagpath"
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// eiptest.FakeClient is usable wherever a Client is
+var _ Client = (*eiptest.FakeClient)(nil)
+
+// fakeClient is an in-memory Client used to exercise the polling machinery
+type fakeClient struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+	reads  int32
+	err    error
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{values: make(map[string]interface{})}
+}
+
+func (f *fakeClient) ReadValue(tagName string, dataType types.PlcDataType) (*types.PlcValue, error) {
+	atomic.AddInt32(&f.reads, 1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	v, ok := f.values[tagName]
+	if !ok {
+		return nil, types.NewEipError(types.ErrTagNotFound, "tag not found")
+	}
+	return &types.PlcValue{Type: dataType, Value: v}, nil
+}
+
+func (f *fakeClient) WriteValue(tagName string, value *types.PlcValue) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.values[tagName] = value.Value
+	return nil
+}
+
+func (f *fakeClient) set(tagName string, value interface{}) {
+	f.mu.Lock()
+	f.values[tagName] = value
+	f.mu.Unlock()
+}
+
+func (f *fakeClient) setErr(err error) {
+	f.mu.Lock()
+	f.err = err
+	f.mu.Unlock()
+}
+
+// waitFor polls cond until it holds
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestPollerDeliversChanges tests that only changed values are delivered
+func TestPollerDeliversChanges(t *testing.T) {
+	fake := newFakeClient()
+	fake.set("Counter", int32(1))
+
+	poller := NewPoller(fake)
+	defer poller.Close()
+
+	values := make(chan interface{}, 10)
+	unsubscribe := poller.Subscribe("Counter", 5*time.Millisecond, types.Dint, func(value interface{}, err error) {
+		if err == nil {
+			values <- value
+		}
+	})
+	defer unsubscribe()
+
+	if v := <-values; v != int32(1) {
+		t.Errorf("Expected first value 1, got %v", v)
+	}
+
+	// Unchanged values must not be delivered again
+	select {
+	case v := <-values:
+		t.Errorf("Expected no update for unchanged value, got %v", v)
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	fake.set("Counter", int32(2))
+	select {
+	case v := <-values:
+		if v != int32(2) {
+			t.Errorf("Expected changed value 2, got %v", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for changed value")
+	}
+}
+
+// TestPollerCoalescesSubscriptions tests that identical subscriptions share one poll
+func TestPollerCoalescesSubscriptions(t *testing.T) {
+	fake := newFakeClient()
+	fake.set("Speed", 1.5)
+
+	poller := NewPoller(fake)
+	defer poller.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	for i := 0; i < 3; i++ {
+		var once sync.Once
+		poller.Subscribe("Speed", 20*time.Millisecond, types.Real, func(value interface{}, err error) {
+			once.Do(wg.Done)
+		})
+	}
+	wg.Wait()
+
+	if n := poller.SubscriptionCount(); n != 3 {
+		t.Errorf("Expected 3 subscriptions, got %d", n)
+	}
+	if reads := atomic.LoadInt32(&fake.reads); reads > 2 {
+		t.Errorf("Expected coalesced polling, got %d reads for 3 subscribers", reads)
+	}
+}
+
+// TestPollerErrorsAndUnsubscribe tests error delivery and unsubscription
+func TestPollerErrorsAndUnsubscribe(t *testing.T) {
+	fake := newFakeClient()
+	fake.setErr(errors.New("connection lost"))
+
+	poller := NewPoller(fake)
+	defer poller.Close()
+
+	errs := make(chan error, 10)
+	unsubscribe := poller.Subscribe("Missing", 5*time.Millisecond, types.Bool, func(value interface{}, err error) {
+		if err != nil {
+			select {
+			case errs <- err:
+			default:
+			}
+		}
+	})
+
+	select {
+	case <-errs:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for error callback")
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if n := poller.SubscriptionCount(); n != 0 {
+		t.Errorf("Expected 0 subscriptions after unsubscribe, got %d", n)
+	}
+}
+
+// TestPollPhase tests that loops keep a fixed phase and are spread over the interval
+func TestPollPhase(t *testing.T) {
+	interval := 100 * time.Millisecond
+	key := pollKey{tagName: "Speed", dataType: types.Real, interval: interval}
+
+	start := time.Unix(1700000000, 0)
+	for _, after := range []time.Duration{0, 13 * time.Millisecond, 250 * time.Millisecond, time.Hour} {
+		now := start.Add(after)
+		delay := pollPhase(key, now)
+		if delay < 0 || delay >= interval {
+			t.Fatalf("Delay %v outside the interval", delay)
+		}
+		if got, want := now.Add(delay).UnixNano()%int64(interval), start.Add(pollPhase(key, start)).UnixNano()%int64(interval); got != want {
+			t.Errorf("Loop started %v later reads at phase %d, expected %d", after, got, want)
+		}
+	}
+
+	buckets := make(map[time.Duration]int)
+	for i := 0; i < 200; i++ {
+		key := pollKey{tagName: fmt.Sprintf("Tag%d", i), dataType: types.Dint, interval: interval}
+		buckets[pollPhase(key, start)/(10*time.Millisecond)]++
+	}
+	for bucket := time.Duration(0); bucket < 10; bucket++ {
+		if n := buckets[bucket]; n == 0 || n > 50 {
+			t.Errorf("Expected phases spread over the interval, got %d of 200 in bucket %d", n, bucket)
+		}
+	}
+
+	if delay := pollPhase(pollKey{tagName: "Speed"}, start); delay != 0 {
+		t.Errorf("Expected no delay without an interval, got %v", delay)
+	}
+}
+
+// TestPollerPhaseSpread tests that spread loops still poll and are healthy while waiting
+func TestPollerPhaseSpread(t *testing.T) {
+	fake := newFakeClient()
+	fake.set("Speed", 1.5)
+
+	poller := NewPoller(fake)
+	defer poller.Close()
+	poller.SetPhaseSpread(true)
+
+	var got atomic.Value
+	poller.Subscribe("Speed", 20*time.Millisecond, types.Real, func(value interface{}, err error) {
+		if err == nil {
+			got.Store(value)
+		}
+	})
+	for _, health := range poller.Health() {
+		if health.State != HealthOK {
+			t.Errorf("Expected a loop waiting for its phase to be healthy, got %s", health.State)
+		}
+	}
+	waitFor(t, func() bool { return got.Load() == 1.5 })
+}
+
+// TestPollerCaseInsensitiveSubscriptions tests that differently spelled names
+// of one tag share a poll loop
+func TestPollerCaseInsensitiveSubscriptions(t *testing.T) {
+	client := newFakeClient()
+	client.set("Motor1", int32(5))
+	poller := NewPoller(client)
+	defer poller.Close()
+	poller.SetTagNameOptions(tagpath.LogixNames)
+
+	values := make(chan interface{}, 4)
+	callback := func(value interface{}, err error) { values <- value }
+	poller.Subscribe("Motor1", 10*time.Millisecond, types.Dint, callback)
+	poller.Subscribe(" motor1", 10*time.Millisecond, types.Dint, callback)
+
+	poller.mu.Lock()
+	loops := len(poller.loops)
+	poller.mu.Unlock()
+	if loops != 1 {
+		t.Fatalf("Expected 1 poll loop, got %d", loops)
+	}
+	for i := 0; i < 2; i++ {
+		if v := <-values; v != int32(5) {
+			t.Errorf("Unexpected value %v", v)
+		}
+	}
+
+	waitFor(t, func() bool {
+		_, ok := poller.Sample("MOTOR1", types.Dint)
+		return ok
+	})
+	sample, _ := poller.Sample("MOTOR1", types.Dint)
+	if sample.TagName != "Motor1" {
+		t.Errorf("Expected the first subscriber's spelling, got %q", sample.TagName)
+	}
+}
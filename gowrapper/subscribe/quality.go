@@ -0,0 +1,19 @@
+package subscribe
+
+import (
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// TagSample is a polled tag value together with its quality
+type TagSample struct {
+	TagName string            `json:"tag_name"`
+	Type    types.PlcDataType `json:"data_type"`
+	Value   interface{}       `json:"value"`
+	Quality types.Quality     `json:"quality"`
+	// Timestamp is the time of the last successful read
+	Timestamp time.Time `json:"timestamp"`
+	// Err is the most recent read error, cleared by the next successful read
+	Err error `json:"-"`
+}
@@ -0,0 +1,178 @@
+package subscribe
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// TestStaleDetection tests that a tag is marked stale after missed updates
+func TestStaleDetection(t *testing.T) {
+	fake := newFakeClient()
+	fake.set("Level", 12.5)
+
+	poller := NewPoller(fake)
+	defer poller.Close()
+	poller.SetStaleAfter(2)
+
+	samples := make(chan TagSample, 10)
+	unsubscribe := poller.SubscribeSamples("Level", 5*time.Millisecond, types.Real, func(sample TagSample) {
+		samples <- sample
+	})
+	defer unsubscribe()
+
+	first := <-samples
+	if first.Quality != types.QualityGood || first.Value != 12.5 {
+		t.Fatalf("Expected good sample with value 12.5, got %+v", first)
+	}
+
+	fake.setErr(errors.New("connection lost"))
+	select {
+	case sample := <-samples:
+		if sample.Quality != types.QualityStale {
+			t.Errorf("Expected stale quality, got %v", sample.Quality)
+		}
+		if sample.Value != 12.5 {
+			t.Errorf("Expected last known value 12.5, got %v", sample.Value)
+		}
+		if sample.Err == nil {
+			t.Error("Expected stale sample to carry the read error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for stale sample")
+	}
+
+	cached, ok := poller.Sample("Level", types.Real)
+	if !ok || cached.Quality != types.QualityStale {
+		t.Errorf("Expected stale cached sample, got %+v (found=%v)", cached, ok)
+	}
+
+	fake.setErr(nil)
+	select {
+	case sample := <-samples:
+		if sample.Quality != types.QualityGood {
+			t.Errorf("Expected recovery to good quality, got %v", sample.Quality)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for recovered sample")
+	}
+
+	if _, ok := poller.Sample("Unsubscribed", types.Real); ok {
+		t.Error("Expected no sample for unsubscribed tag")
+	}
+}
+
+// TestStaleWhileReadBlocked tests that a sample subscriber is told a tag went
+// stale while its read is blocked and never returns
+func TestStaleWhileReadBlocked(t *testing.T) {
+	saved := healthCheckPeriod
+	healthCheckPeriod = 5 * time.Millisecond
+	defer func() { healthCheckPeriod = saved }()
+
+	client := &blockingClient{fakeClient: newFakeClient()}
+	client.set("Level", 12.5)
+	poller := NewPoller(client)
+	defer poller.Close()
+	poller.SetStaleAfter(2)
+
+	samples := make(chan TagSample, 10)
+	unsubscribe := poller.SubscribeSamples("Level", 5*time.Millisecond, types.Real, func(sample TagSample) {
+		samples <- sample
+	})
+	defer unsubscribe()
+	if first := <-samples; first.Quality != types.QualityGood {
+		t.Fatalf("Expected a good sample, got %+v", first)
+	}
+
+	client.gate.Lock()
+	defer client.gate.Unlock()
+	for {
+		select {
+		case sample := <-samples:
+			if sample.Quality == types.QualityStale {
+				if sample.Value != 12.5 {
+					t.Errorf("Expected the last known value 12.5, got %v", sample.Value)
+				}
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for a stale sample while the read is blocked")
+		}
+	}
+}
+
+// TestSampleDeliveriesOrdered tests that a fresh sample read while the
+// heartbeat is delivering a stale one waits for it, so the callback never
+// runs concurrently with itself and the stale sample cannot overtake it
+func TestSampleDeliveriesOrdered(t *testing.T) {
+	saved := healthCheckPeriod
+	healthCheckPeriod = 5 * time.Millisecond
+	defer func() { healthCheckPeriod = saved }()
+
+	client := &blockingClient{fakeClient: newFakeClient()}
+	client.set("Level", 12.5)
+	poller := NewPoller(client)
+	defer poller.Close()
+	poller.SetStaleAfter(2)
+
+	var mu sync.Mutex
+	var qualities []types.Quality
+	var active, overlaps int32
+	var once sync.Once
+	good := make(chan struct{}, 10)
+	staleIn := make(chan struct{})
+	release := make(chan struct{})
+	unsubscribe := poller.SubscribeSamples("Level", 5*time.Millisecond, types.Real, func(sample TagSample) {
+		if atomic.AddInt32(&active, 1) > 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+		defer atomic.AddInt32(&active, -1)
+		mu.Lock()
+		qualities = append(qualities, sample.Quality)
+		mu.Unlock()
+		if sample.Quality == types.QualityStale {
+			// Hold the heartbeat's first stale delivery while the read completes
+			first := false
+			once.Do(func() { first = true })
+			if first {
+				close(staleIn)
+				<-release
+			}
+			return
+		}
+		good <- struct{}{}
+	})
+	defer unsubscribe()
+	<-good
+
+	client.gate.Lock()
+	select {
+	case <-staleIn:
+	case <-time.After(time.Second):
+		client.gate.Unlock()
+		t.Fatal("Timed out waiting for a stale sample while the read is blocked")
+	}
+	client.gate.Unlock()
+	// Give the unblocked read time to compute and deliver its fresh sample
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	select {
+	case <-good:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the fresh sample")
+	}
+	if n := atomic.LoadInt32(&overlaps); n != 0 {
+		t.Errorf("Expected no concurrent callbacks, got %d", n)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := []types.Quality{types.QualityGood, types.QualityStale, types.QualityGood}
+	if len(qualities) < len(want) || qualities[0] != want[0] || qualities[1] != want[1] || qualities[2] != want[2] {
+		t.Errorf("Expected qualities %v, got %v", want, qualities)
+	}
+}
@@ -0,0 +1,124 @@
+// Package subscribe polls tags on behalf of subscribers: the Poller, which
+// coalesces subscriptions to the same tag onto one poll loop, spreads loops
+// over their interval, filters values through deadbands, tracks the
+// quality and health of every loop and reports stale tags from a
+// heartbeat; the helpers that wait for a value or a condition, read
+// periodically or asynchronously; and the Clock that times them. Everything
+// works on any Client, so it runs the same against an EipClient, a gateway
+// client or an eiptest.FakeClient, and the package has no dependency on
+// the native library. The ethernetip package re-exports its types and keeps
+// its EipClient methods, which call into this package, so existing code is
+// unaffected.
+package subscribe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// Client is the subset of tag operations needed by the polling machinery
+// and the helpers of this package. *ethernetip.EipClient implements it, and
+// so can fakes or remote proxies.
+type Client interface {
+	ReadValue(tagName string, dataType types.PlcDataType) (*types.PlcValue, error)
+	WriteValue(tagName string, value *types.PlcValue) error
+}
+
+// waitPoll is the interval at which the wait helpers read the tag
+const waitPoll = 100 * time.Millisecond
+
+// WaitForValue reads tagName from c until it equals expectedValue, or fails
+// with ErrTimeout after timeout on the clock of c
+func WaitForValue(c Client, tagName string, dataType types.PlcDataType, expectedValue interface{}, timeout time.Duration) error {
+	clock := ClockOf(c)
+	deadline := clock.Now().Add(timeout)
+	for clock.Now().Before(deadline) {
+		value, err := c.ReadValue(tagName, dataType)
+		if err == nil && value.Value == expectedValue {
+			return nil
+		}
+		Sleep(clock, waitPoll)
+	}
+	return types.NewEipErrorWithDetails(types.ErrTimeout,
+		fmt.Sprintf("Timeout waiting for tag %s to reach value %v", tagName, expectedValue),
+		map[string]interface{}{
+			"tag_name":       tagName,
+			"data_type":      dataType,
+			"expected_value": expectedValue,
+			"timeout":        timeout,
+		})
+}
+
+// WaitForCondition reads tagName from c until its value satisfies
+// condition, or fails with ErrTimeout after timeout on the clock of c
+func WaitForCondition(c Client, tagName string, dataType types.PlcDataType, condition func(interface{}) bool, timeout time.Duration) error {
+	clock := ClockOf(c)
+	deadline := clock.Now().Add(timeout)
+	for clock.Now().Before(deadline) {
+		value, err := c.ReadValue(tagName, dataType)
+		if err == nil && condition(value.Value) {
+			return nil
+		}
+		Sleep(clock, waitPoll)
+	}
+	return types.NewEipErrorWithDetails(types.ErrTimeout,
+		fmt.Sprintf("Timeout waiting for tag %s to satisfy condition", tagName),
+		map[string]interface{}{
+			"tag_name":  tagName,
+			"data_type": dataType,
+			"timeout":   timeout,
+		})
+}
+
+// ReadPeriodically reads tagName from c at every interval on the clock of
+// c and sends the values to the first channel. The first failed read is
+// sent to the second channel, and both channels are then closed.
+func ReadPeriodically(c Client, tagName string, dataType types.PlcDataType, interval time.Duration) (<-chan *types.PlcValue, <-chan error) {
+	valueChan := make(chan *types.PlcValue)
+	errChan := make(chan error)
+
+	go func() {
+		defer close(valueChan)
+		defer close(errChan)
+
+		ticker := ClockOf(c).NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C() {
+			value, err := c.ReadValue(tagName, dataType)
+			if err != nil {
+				errChan <- err
+				return
+			}
+			valueChan <- value
+		}
+	}()
+
+	return valueChan, errChan
+}
+
+// ReadAsync reads tagName from c in a goroutine and sends the result
+func ReadAsync(c Client, tagName string, dataType types.PlcDataType) <-chan types.PlcValueResult {
+	ch := make(chan types.PlcValueResult, 1)
+	go func() {
+		result := types.PlcValueResult{Tag: tagName, Type: dataType}
+		val, err := c.ReadValue(tagName, dataType)
+		if err == nil {
+			result.Value = val.Value
+		}
+		result.Err = err
+		ch <- result
+	}()
+	return ch
+}
+
+// WriteAsync writes tagName to c in a goroutine and sends the error
+func WriteAsync(c Client, tagName string, value *types.PlcValue) <-chan error {
+	ch := make(chan error, 1)
+	go func() {
+		ch <- c.WriteValue(tagName, value)
+	}()
+	return ch
+}
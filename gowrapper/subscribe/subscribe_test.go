@@ -0,0 +1,70 @@
+package subscribe
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/eiptest"
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+)
+
+// TestWaitForValue tests waiting for a value that is already there and one
+// that never comes
+func TestWaitForValue(t *testing.T) {
+	fake := eiptest.NewFakeClient()
+	fake.Set("Done", true)
+
+	if err := WaitForValue(fake, "Done", types.Bool, true, time.Second); err != nil {
+		t.Errorf("Expected the value to be found, got %v", err)
+	}
+
+	err := WaitForCondition(fake, "Done", types.Bool, func(v interface{}) bool { return v == false }, 50*time.Millisecond)
+	var eipErr *types.EipError
+	if !errors.As(err, &eipErr) || eipErr.Code != types.ErrTimeout {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+}
+
+// TestReadPeriodically tests that the first failed read ends the stream
+func TestReadPeriodically(t *testing.T) {
+	fake := eiptest.NewFakeClient()
+	fake.Set("Speed", int32(5))
+
+	values, errs := ReadPeriodically(fake, "Speed", types.Dint, time.Millisecond)
+	if v := <-values; v == nil || v.Value != int32(5) {
+		t.Errorf("Expected 5, got %+v", v)
+	}
+
+	failure := errors.New("offline")
+	fake.SetErr(failure)
+	for {
+		select {
+		case <-values:
+			// Read before the failure
+		case err := <-errs:
+			if !errors.Is(err, failure) {
+				t.Errorf("Expected %v, got %v", failure, err)
+			}
+			if _, ok := <-values; ok {
+				t.Error("Expected the values to be closed")
+			}
+			return
+		}
+	}
+}
+
+// TestAsync tests async reads and writes, including a failed read
+func TestAsync(t *testing.T) {
+	fake := eiptest.NewFakeClient()
+
+	if err := <-WriteAsync(fake, "Speed", &types.PlcValue{Type: types.Dint, Value: int32(7)}); err != nil {
+		t.Fatalf("WriteAsync failed: %v", err)
+	}
+	if r := <-ReadAsync(fake, "Speed", types.Dint); r.Err != nil || r.Value != int32(7) || r.Tag != "Speed" {
+		t.Errorf("Expected Speed = 7, got %+v", r)
+	}
+	if r := <-ReadAsync(fake, "Missing", types.Dint); r.Err == nil || r.Value != nil {
+		t.Errorf("Expected a failed read, got %+v", r)
+	}
+}
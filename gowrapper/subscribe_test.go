@@ -0,0 +1,87 @@
+package ethernetip
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/eiptest"
+)
+
+// eiptest.FakeClient is usable wherever a Client is
+var _ Client = (*eiptest.FakeClient)(nil)
+
+// fakeClient is an in-memory Client used to exercise the polling machinery
+// through the client's facade
+type fakeClient struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+	reads  int32
+	err    error
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{values: make(map[string]interface{})}
+}
+
+func (f *fakeClient) ReadValue(tagName string, dataType PlcDataType) (*PlcValue, error) {
+	atomic.AddInt32(&f.reads, 1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	v, ok := f.values[tagName]
+	if !ok {
+		return nil, NewEipError(ErrTagNotFound, "tag not found")
+	}
+	return &PlcValue{Type: dataType, Value: v}, nil
+}
+
+func (f *fakeClient) WriteValue(tagName string, value *PlcValue) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.values[tagName] = value.Value
+	return nil
+}
+
+func (f *fakeClient) set(tagName string, value interface{}) {
+	f.mu.Lock()
+	f.values[tagName] = value
+	f.mu.Unlock()
+}
+
+func (f *fakeClient) setErr(err error) {
+	f.mu.Lock()
+	f.err = err
+	f.mu.Unlock()
+}
+
+// TestNewPoller tests the facade over the subscribe package's Poller
+func TestNewPoller(t *testing.T) {
+	fake := newFakeClient()
+	fake.set("Counter", int32(1))
+	poller := NewPoller(fake)
+	defer poller.Close()
+
+	values := make(chan interface{}, 1)
+	unsubscribe := poller.Subscribe("Counter", 5*time.Millisecond, Dint, func(value interface{}, err error) {
+		select {
+		case values <- value:
+		default:
+		}
+	})
+	defer unsubscribe()
+	select {
+	case v := <-values:
+		if v != int32(1) {
+			t.Errorf("Expected 1, got %v", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the first value")
+	}
+}
@@ -0,0 +1,104 @@
+package ethernetip
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/discovery"
+)
+
+// Symbol type word layout (Logix Symbol Object attribute 2), see the
+// discovery package
+const (
+	symbolTypeStructBit = discovery.SymbolTypeStructBit
+	symbolTypeSystemBit = discovery.SymbolTypeSystemBit
+	symbolTypeCodeMask  = discovery.SymbolTypeCodeMask
+	symbolTypeDimsShift = discovery.SymbolTypeDimsShift
+	symbolTypeDimsMask  = discovery.SymbolTypeDimsMask
+)
+
+// TagInfo describes a tag found during discovery
+type TagInfo = discovery.TagInfo
+
+// TagDatabase is an immutable snapshot of the tags discovered on a controller
+type TagDatabase struct {
+	Tags         []TagInfo `json:"tags"`
+	DiscoveredAt time.Time `json:"discovered_at"`
+
+	byName map[string]int
+}
+
+// NewTagDatabase builds a tag database from a list of tags, sorted by name
+func NewTagDatabase(tags []TagInfo) *TagDatabase {
+	sorted := make([]TagInfo, len(tags))
+	copy(sorted, tags)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	db := &TagDatabase{
+		Tags:         sorted,
+		DiscoveredAt: time.Now(),
+		byName:       make(map[string]int, len(sorted)),
+	}
+	for i, tag := range sorted {
+		db.byName[tag.Name] = i
+	}
+	return db
+}
+
+// Len returns the number of tags in the database
+func (db *TagDatabase) Len() int {
+	if db == nil {
+		return 0
+	}
+	return len(db.Tags)
+}
+
+// Lookup returns the tag with the given name
+func (db *TagDatabase) Lookup(name string) (TagInfo, bool) {
+	if db == nil {
+		return TagInfo{}, false
+	}
+	i, ok := db.byName[name]
+	if !ok {
+		return TagInfo{}, false
+	}
+	return db.Tags[i], true
+}
+
+// DiscoverTagDatabase lists every controller- and program-scoped tag by walking
+// the Symbol Object, calling progress with the running total after each reply.
+// Discovery on large controllers can take minutes; cancel ctx to abort. On
+// success the database replaces the one returned by TagDatabase and its scalar
+// tags are added to TagTypes.
+func (c *EipClient) DiscoverTagDatabase(ctx context.Context, progress func(found int)) (*TagDatabase, error) {
+	tags, err := discovery.Walk(ctx, c.symbolPage, progress)
+	if err != nil {
+		return nil, err
+	}
+	db := NewTagDatabase(tags)
+	c.tagDB.Store(db)
+	c.tagTypes.AddDatabase(db)
+	return db, nil
+}
+
+// TagDatabase returns the most recently discovered tag database, or nil if
+// DiscoverTagDatabase has not completed yet
+func (c *EipClient) TagDatabase() *TagDatabase {
+	return c.tagDB.Load()
+}
+
+// symbolPage is the discovery.PageFunc of the client: it reads one page of
+// Symbol Object instances with Get Instance Attribute List
+func (c *EipClient) symbolPage(program string, instance uint32) ([]byte, bool, error) {
+	var path []byte
+	if program != "" {
+		path = symbolicSegment("Program:" + program)
+	}
+	path = append(path, classInstancePath(CIPClassSymbol, instance)...)
+	resp, err := c.SendCIPMessage(CIPServiceGetInstanceAttributeList, path, discovery.SymbolListRequest)
+	if err != nil {
+		return nil, false, err
+	}
+	return resp.Data, resp.GeneralStatus == CIPStatusPartialTransfer, nil
+}
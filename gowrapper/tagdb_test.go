@@ -0,0 +1,22 @@
+package ethernetip
+
+import "testing"
+
+// TestTagDatabaseLookup tests tag database indexing
+func TestTagDatabaseLookup(t *testing.T) {
+	db := NewTagDatabase([]TagInfo{{Name: "b"}, {Name: "a"}})
+	if db.Len() != 2 || db.Tags[0].Name != "a" {
+		t.Errorf("Expected sorted database, got %+v", db.Tags)
+	}
+	if _, ok := db.Lookup("b"); !ok {
+		t.Error("Expected to find tag b")
+	}
+	if _, ok := db.Lookup("c"); ok {
+		t.Error("Did not expect to find tag c")
+	}
+
+	var empty *TagDatabase
+	if empty.Len() != 0 {
+		t.Error("Expected nil database to be empty")
+	}
+}
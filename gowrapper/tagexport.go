@@ -0,0 +1,186 @@
+package ethernetip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Tag export file format identifiers
+const (
+	TagExportFormat  = "rust-ethernet-ip/tags"
+	TagExportVersion = 1
+)
+
+// TagExport is the documented file format for a discovered tag database and
+// the structure templates its tags use. It is plain JSON so exports can be
+// diffed between controller revisions, analysed offline or checked in as test
+// fixtures, and loaded back with ReadTagExport and ImportTagDatabase.
+type TagExport struct {
+	Format       string    `json:"format"`
+	Version      int       `json:"version"`
+	ExportedAt   time.Time `json:"exported_at"`
+	DiscoveredAt time.Time `json:"discovered_at"`
+	// Tags are sorted by name
+	Tags []ExportedTag `json:"tags"`
+	// Templates are sorted by instance and include nested structures
+	Templates []*StructTemplate `json:"templates,omitempty"`
+}
+
+// ExportedTag is a tag in a TagExport. SymbolType holds the raw symbol type
+// word; the other type fields are derived from it for readability.
+type ExportedTag struct {
+	Name       string `json:"name"`
+	Program    string `json:"program,omitempty"`
+	InstanceID uint32 `json:"instance_id"`
+	SymbolType uint16 `json:"symbol_type"`
+	// Type is the atomic type name ("DINT") or the template name of a
+	// structure, when known
+	Type       string `json:"type,omitempty"`
+	Structure  bool   `json:"structure,omitempty"`
+	Template   uint16 `json:"template,omitempty"`
+	Dimensions int    `json:"dimensions,omitempty"`
+	System     bool   `json:"system,omitempty"`
+}
+
+// TemplateSource reads structure templates. *EipClient implements it.
+type TemplateSource interface {
+	GetTemplate(instance uint16) (*StructTemplate, error)
+}
+
+// ExportTagDatabase exports the tag database of the last DiscoverTagDatabase,
+// reading the templates of its structure tags from the controller
+func (c *EipClient) ExportTagDatabase(ctx context.Context) (*TagExport, error) {
+	db := c.TagDatabase()
+	if db == nil {
+		return nil, NewEipError(ErrInvalidOperation, "no tag database; run DiscoverTagDatabase first")
+	}
+	return NewTagExport(ctx, db, c)
+}
+
+// NewTagExport builds an export of db. Templates of structure tags, and of
+// structures nested in them, are read from templates; templates that cannot
+// be read (such as some system types) are left out. templates may be nil to
+// export tags only.
+func NewTagExport(ctx context.Context, db *TagDatabase, templates TemplateSource) (*TagExport, error) {
+	export := &TagExport{
+		Format:     TagExportFormat,
+		Version:    TagExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Tags:       make([]ExportedTag, 0, db.Len()),
+	}
+	if db == nil {
+		return export, nil
+	}
+	export.DiscoveredAt = db.DiscoveredAt
+
+	found := map[uint16]*StructTemplate{}
+	var pending []uint16
+	for _, tag := range db.Tags {
+		if tag.IsStructure() {
+			pending = append(pending, tag.TypeCode())
+		}
+	}
+	for templates != nil && len(pending) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		instance := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if _, seen := found[instance]; seen {
+			continue
+		}
+		template, err := templates.GetTemplate(instance)
+		found[instance] = template
+		if err != nil {
+			continue
+		}
+		for _, m := range template.Members {
+			if m.IsStructure() {
+				pending = append(pending, m.TypeCode())
+			}
+		}
+	}
+	for _, template := range found {
+		if template != nil {
+			export.Templates = append(export.Templates, template)
+		}
+	}
+	sort.Slice(export.Templates, func(i, j int) bool { return export.Templates[i].Instance < export.Templates[j].Instance })
+
+	for _, tag := range db.Tags {
+		exported := ExportedTag{
+			Name:       tag.Name,
+			Program:    tag.Program,
+			InstanceID: tag.InstanceID,
+			SymbolType: tag.SymbolType,
+			Structure:  tag.IsStructure(),
+			Dimensions: tag.Dimensions(),
+			System:     tag.IsSystem(),
+		}
+		if tag.IsStructure() {
+			exported.Template = tag.TypeCode()
+			if template := found[tag.TypeCode()]; template != nil {
+				exported.Type = template.Name
+			}
+		} else if dataType, ok := atomicDataType(tag.TypeCode()); ok {
+			exported.Type = dataType.String()
+		}
+		export.Tags = append(export.Tags, exported)
+	}
+	return export, nil
+}
+
+// Database returns the tags of the export as a TagDatabase
+func (e *TagExport) Database() *TagDatabase {
+	tags := make([]TagInfo, len(e.Tags))
+	for i, tag := range e.Tags {
+		tags[i] = TagInfo{Name: tag.Name, InstanceID: tag.InstanceID, SymbolType: tag.SymbolType, Program: tag.Program}
+	}
+	db := NewTagDatabase(tags)
+	db.DiscoveredAt = e.DiscoveredAt
+	return db
+}
+
+// WriteTagExport writes e as indented JSON
+func WriteTagExport(w io.Writer, e *TagExport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(e)
+}
+
+// ReadTagExport reads an export written by WriteTagExport. Files of another
+// format or a newer version are rejected.
+func ReadTagExport(r io.Reader) (*TagExport, error) {
+	var e TagExport
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, NewEipError(ErrInvalidValue, fmt.Sprintf("invalid tag export: %v", err))
+	}
+	if e.Format != TagExportFormat {
+		return nil, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("not a tag export: format %q", e.Format),
+			map[string]interface{}{"format": e.Format})
+	}
+	if e.Version < 1 || e.Version > TagExportVersion {
+		return nil, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("unsupported tag export version %d", e.Version),
+			map[string]interface{}{"version": e.Version, "supported": TagExportVersion})
+	}
+	return &e, nil
+}
+
+// ImportTagDatabase loads an export as if DiscoverTagDatabase had found it:
+// the tags replace the database returned by TagDatabase, scalar tags are added
+// to TagTypes and the templates are cached for GetTemplate. This lets tools
+// and tests work against a known controller layout without discovery.
+func (c *EipClient) ImportTagDatabase(e *TagExport) *TagDatabase {
+	c.stringTypes.Clear()
+	for _, template := range e.Templates {
+		c.templates.Store(template.Instance, template)
+	}
+	db := e.Database()
+	c.tagDB.Store(db)
+	c.tagTypes.AddDatabase(db)
+	return db
+}
@@ -0,0 +1,92 @@
+package ethernetip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// templateMap is a TemplateSource backed by a map
+type templateMap map[uint16]*StructTemplate
+
+func (m templateMap) GetTemplate(instance uint16) (*StructTemplate, error) {
+	if t, ok := m[instance]; ok {
+		return t, nil
+	}
+	return nil, errors.New("template not found")
+}
+
+// TestTagExportRoundTrip tests exporting, writing, reading and importing a
+// tag database with nested templates
+func TestTagExportRoundTrip(t *testing.T) {
+	db := NewTagDatabase([]TagInfo{
+		{Name: "Speed", InstanceID: 1, SymbolType: CIPTypeReal},
+		{Name: "Recipe", InstanceID: 2, SymbolType: symbolTypeStructBit | 0x0100},
+		{Name: "Alarms", InstanceID: 3, SymbolType: 0x2000 | CIPTypeDint},
+		{Name: "Internal", InstanceID: 4, SymbolType: symbolTypeStructBit | 0x0300},
+	})
+	templates := templateMap{
+		0x0100: {Instance: 0x0100, Name: "Recipe", Handle: 0x1111, Size: 12, Members: []TemplateMember{
+			{Name: "Count", Type: CIPTypeDint},
+			{Name: "Step", Type: symbolTypeStructBit | 0x0200, Offset: 4},
+		}},
+		0x0200: {Instance: 0x0200, Name: "Step", Handle: 0x2222, Size: 8, Members: []TemplateMember{
+			{Name: "Time", Type: CIPTypeLint},
+		}},
+	}
+
+	export, err := NewTagExport(context.Background(), db, templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Templates) != 2 || export.Templates[0].Instance != 0x0100 || export.Templates[1].Instance != 0x0200 {
+		t.Fatalf("Expected both templates sorted by instance, got %+v", export.Templates)
+	}
+	byName := map[string]ExportedTag{}
+	for _, tag := range export.Tags {
+		byName[tag.Name] = tag
+	}
+	if byName["Speed"].Type != "REAL" || byName["Recipe"].Type != "Recipe" || byName["Alarms"].Dimensions != 1 {
+		t.Errorf("Unexpected exported tags: %+v", export.Tags)
+	}
+	if internal := byName["Internal"]; internal.Type != "" || internal.Template != 0x0300 {
+		t.Errorf("Expected an unreadable template to leave the type empty, got %+v", internal)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTagExport(&buf, export); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadTagExport(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &EipClient{}
+	imported := client.ImportTagDatabase(read)
+	if imported.Len() != 4 || client.TagDatabase() != imported {
+		t.Errorf("Expected the imported database to be served, got %d tags", imported.Len())
+	}
+	if dataType, ok := client.TagTypes().Lookup("Speed"); !ok || dataType != Real {
+		t.Errorf("Expected Speed to be typed REAL, got %v, %v", dataType, ok)
+	}
+	template, err := client.GetTemplate(0x0200)
+	if err != nil || template.Handle != 0x2222 || template.Members[0].Name != "Time" {
+		t.Errorf("Expected the imported template to be cached, got %+v, %v", template, err)
+	}
+}
+
+// TestReadTagExportRejectsOtherFiles tests format and version checks
+func TestReadTagExportRejectsOtherFiles(t *testing.T) {
+	for _, data := range []string{
+		`{"tags": []}`,
+		`{"format": "rust-ethernet-ip/tags", "version": 99}`,
+		`not json`,
+	} {
+		if _, err := ReadTagExport(strings.NewReader(data)); err == nil {
+			t.Errorf("Expected error for %s", data)
+		}
+	}
+}
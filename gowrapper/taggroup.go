@@ -0,0 +1,231 @@
+package ethernetip
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TagGroup is a set of tags registered once and then read or written
+// together. The group encodes the Write Tag request headers of its tags when
+// they are registered and keeps its compiled ReadPlan until the tags change,
+// so ReadAll and WriteAll only pack and send requests.
+type TagGroup struct {
+	client *EipClient
+
+	mu   sync.Mutex
+	tags []groupTag
+	keys map[string]int // Index into tags by TagNameOptions.Key
+	plan *ReadPlan      // Compiled on first read after a change
+}
+
+// groupTag is a tag of a TagGroup
+type groupTag struct {
+	ReadItem
+	header []byte // Write Tag request header, nil if not batchable
+}
+
+// NewTagGroup creates an empty tag group read and written through c
+func (c *EipClient) NewTagGroup() *TagGroup {
+	return &TagGroup{client: c, keys: make(map[string]int)}
+}
+
+// Add registers a tag. Adding a tag that is already in the group, under a
+// name the client's TagNameOptions match to it, does nothing; adding it with
+// a different type fails with ErrInvalidDataType.
+func (g *TagGroup) Add(tagName string, dataType PlcDataType) error {
+	opts := g.client.TagNameOptions()
+	tagName = opts.Clean(tagName)
+	if tagName == "" {
+		return NewEipError(ErrInvalidTagName, "tag name cannot be empty")
+	}
+	key := opts.Key(tagName)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if i, ok := g.keys[key]; ok {
+		if existing := g.tags[i]; existing.DataType != dataType {
+			return NewEipErrorWithDetails(ErrInvalidDataType,
+				fmt.Sprintf("'%s' is already in the group as %s", existing.TagName, existing.DataType),
+				map[string]interface{}{"tag_name": existing.TagName, "expected": existing.DataType.String(), "actual": dataType.String()})
+		}
+		return nil
+	}
+
+	tag := groupTag{ReadItem: ReadItem{TagName: tagName, DataType: dataType}}
+	if target := g.client.ResolveAlias(tagName); batchable(target, dataType) {
+		if _, err := encodeReadItem(ReadItem{TagName: target, DataType: dataType}); err != nil {
+			return err
+		}
+		header, err := writeTagHeader(target, dataType)
+		if err != nil {
+			return err
+		}
+		tag.header = header
+	}
+	g.keys[key] = len(g.tags)
+	g.tags = append(g.tags, tag)
+	g.plan = nil
+	return nil
+}
+
+// Remove unregisters a tag and reports whether it was in the group
+func (g *TagGroup) Remove(tagName string) bool {
+	key := g.client.TagNameOptions().Key(tagName)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	i, ok := g.keys[key]
+	if !ok {
+		return false
+	}
+	g.tags = append(g.tags[:i], g.tags[i+1:]...)
+	delete(g.keys, key)
+	for k, j := range g.keys {
+		if j > i {
+			g.keys[k] = j - 1
+		}
+	}
+	g.plan = nil
+	return true
+}
+
+// Tags returns the registered tags in the order they were added
+func (g *TagGroup) Tags() []ReadItem {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	items := make([]ReadItem, len(g.tags))
+	for i, tag := range g.tags {
+		items[i] = tag.ReadItem
+	}
+	return items
+}
+
+// Len returns the number of registered tags
+func (g *TagGroup) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.tags)
+}
+
+// Plan returns the compiled plan ReadAll executes for the batchable tags, or
+// nil if every tag is read on its own
+func (g *TagGroup) Plan() (*ReadPlan, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	plan, _, err := g.readSteps()
+	return plan, err
+}
+
+// readSteps returns the cached plan and the tags read one by one, compiling
+// the plan if the group changed. Must be called with g.mu held.
+func (g *TagGroup) readSteps() (*ReadPlan, []ReadItem, error) {
+	var items, single []ReadItem
+	for _, tag := range g.tags {
+		if tag.header != nil {
+			items = append(items, tag.ReadItem)
+		} else {
+			single = append(single, tag.ReadItem)
+		}
+	}
+	if g.plan == nil && len(items) > 0 {
+		plan, err := g.client.CompileReadPlan(items)
+		if err != nil {
+			return nil, nil, err
+		}
+		g.plan = plan
+	}
+	return g.plan, single, nil
+}
+
+// ReadAll reads every tag of the group, keyed by the name it was added
+// under. Tags that fail are left out of the result and reported together in
+// an ErrBatchOperationFailed error.
+func (g *TagGroup) ReadAll() (map[string]*PlcValue, error) {
+	var values map[string]*PlcValue
+	err := g.client.submit(OperationRead, fmt.Sprintf("%d tags", g.Len()), func() (err error) {
+		values, err = g.readAll()
+		return err
+	})
+	return values, err
+}
+
+// readAll is ReadAll without queuing
+func (g *TagGroup) readAll() (map[string]*PlcValue, error) {
+	g.mu.Lock()
+	if len(g.tags) == 0 {
+		g.mu.Unlock()
+		return nil, NewEipError(ErrInvalidOperation, "tag group is empty")
+	}
+	plan, single, err := g.readSteps()
+	g.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	values, failed, err := g.client.readItems(plan, single)
+	if err != nil {
+		return nil, err
+	}
+	if len(failed) > 0 {
+		return values, bindingError("tag group read", failed)
+	}
+	return values, nil
+}
+
+// WriteAll writes values to tags of the group, keyed by tag name; tags of
+// the group without a value are left alone. Writing a tag that is not in the
+// group fails with ErrInvalidTagName before anything is sent. The writes are
+// packed into Multiple Service Packets and are not atomic: writes that fail
+// are reported together in an ErrBatchOperationFailed error while the others
+// take effect.
+func (g *TagGroup) WriteAll(values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+	opts := g.client.TagNameOptions()
+
+	g.mu.Lock()
+	pending := make([]groupTag, len(g.tags))
+	copy(pending, g.tags)
+	// Values by tag index, so writes go out in registration order
+	byIndex := make(map[int]interface{}, len(values))
+	for name := range values {
+		i, ok := g.keys[opts.Key(name)]
+		if !ok {
+			g.mu.Unlock()
+			return NewEipErrorWithDetails(ErrInvalidTagName, fmt.Sprintf("'%s' is not in the tag group", name),
+				map[string]interface{}{"tag_name": name})
+		}
+		if _, dup := byIndex[i]; dup {
+			g.mu.Unlock()
+			return NewEipErrorWithDetails(ErrInvalidTagName, fmt.Sprintf("'%s' is written more than once", pending[i].TagName),
+				map[string]interface{}{"tag_name": pending[i].TagName})
+		}
+		byIndex[i] = values[name]
+	}
+	g.mu.Unlock()
+
+	var writes []packedWrite
+	var failed []string
+	for i, tag := range pending {
+		value, ok := byIndex[i]
+		if !ok {
+			continue
+		}
+		if tag.header == nil {
+			if err := g.client.WriteValue(tag.TagName, &PlcValue{Type: tag.DataType, Value: value}); err != nil {
+				failed = append(failed, fmt.Sprintf("%s (%v)", tag.TagName, err))
+			}
+			continue
+		}
+		data, err := encodeElement(tag.DataType, value)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", tag.TagName, err))
+			continue
+		}
+		writes = append(writes, packedWrite{tagName: g.client.ResolveAlias(tag.TagName), dataType: tag.DataType, value: value,
+			request: append(tag.header[:len(tag.header):len(tag.header)], data...)})
+	}
+	failed = append(failed, failedWrites(writes, g.client.writePacked(writes))...)
+	return bindingError("tag group write", failed)
+}
@@ -0,0 +1,97 @@
+package ethernetip
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestTagGroupAdd tests registering and deduplicating tags
+func TestTagGroupAdd(t *testing.T) {
+	client := &EipClient{}
+	client.tagNames = LogixTagNames
+	g := client.NewTagGroup()
+
+	for _, tag := range []struct {
+		name     string
+		dataType PlcDataType
+	}{
+		{"Motor.Speed", Real},
+		{"motor.speed ", Real},
+		{"Motor.Status.3", Bool},
+		{"Line.Name", String},
+		{"Count", Dint},
+	} {
+		if err := g.Add(tag.name, tag.dataType); err != nil {
+			t.Fatalf("Add(%q): %v", tag.name, err)
+		}
+	}
+	if g.Len() != 4 {
+		t.Fatalf("Expected 4 tags, got %+v", g.Tags())
+	}
+
+	var eipErr *EipError
+	if err := g.Add("MOTOR.SPEED", Dint); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidDataType {
+		t.Errorf("Expected ErrInvalidDataType for a type conflict, got %v", err)
+	}
+	if err := g.Add("", Dint); err == nil {
+		t.Error("Expected error for an empty name")
+	}
+
+	plan, err := g.Plan()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if plan.Items() != 2 || plan.RoundTrips() != 1 {
+		t.Errorf("Expected the two batchable tags in one packet, got %s", plan)
+	}
+	if again, _ := g.Plan(); again != plan {
+		t.Error("Expected the plan to be reused")
+	}
+
+	if !g.Remove("count") || g.Remove("Count") {
+		t.Error("Expected Remove to report whether the tag was in the group")
+	}
+	if again, _ := g.Plan(); again == plan || again.Items() != 1 {
+		t.Errorf("Expected the plan to be recompiled after Remove, got %v", again)
+	}
+	if err := g.Add("Count", Int); err != nil {
+		t.Errorf("Expected a removed tag to be added again: %v", err)
+	}
+	tags := g.Tags()
+	if tags[len(tags)-1] != (ReadItem{TagName: "Count", DataType: Int}) {
+		t.Errorf("Unexpected tags %+v", tags)
+	}
+}
+
+// TestTagGroupWriteValidation tests that bad writes fail before anything is sent
+func TestTagGroupWriteValidation(t *testing.T) {
+	client := &EipClient{}
+	client.tagNames = LogixTagNames
+	g := client.NewTagGroup()
+	g.Add("Speed", Real)
+	g.Add("Count", Dint)
+
+	var eipErr *EipError
+	if err := g.WriteAll(map[string]interface{}{"Speed": 1.5, "Other": 1}); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagName {
+		t.Errorf("Expected ErrInvalidTagName for a tag outside the group, got %v", err)
+	}
+	if err := g.WriteAll(map[string]interface{}{"count": 1, "COUNT": 2}); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagName {
+		t.Errorf("Expected ErrInvalidTagName for a tag written twice, got %v", err)
+	}
+	if err := g.WriteAll(nil); err != nil {
+		t.Errorf("Expected an empty write to succeed, got %v", err)
+	}
+
+	// A value that does not encode fails its tag without a request
+	err := g.WriteAll(map[string]interface{}{"Count": "x"})
+	if !errors.As(err, &eipErr) || eipErr.Code != ErrBatchOperationFailed {
+		t.Fatalf("Expected ErrBatchOperationFailed, got %v", err)
+	}
+	if failed, _ := eipErr.Details["failed_items"].([]string); len(failed) != 1 {
+		t.Errorf("Expected one failed item, got %v", failed)
+	}
+
+	if _, err := client.NewTagGroup().ReadAll(); err == nil {
+		t.Error("Expected error reading an empty group")
+	}
+}
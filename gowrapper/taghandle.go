@@ -0,0 +1,254 @@
+package ethernetip
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/discovery"
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/tagpath"
+)
+
+// TagHandle reads and writes one tag by the instance ID of its Symbol
+// object, looked up once by ResolveHandle, instead of by its name. The
+// controller does not have to search its symbol table on every request and
+// requests are shorter, which matters for high-rate polling. Handles are
+// safe for concurrent use.
+//
+// Instance IDs change when a program is downloaded. A handle looks the tag up
+// again after the client detects a program change (see
+// SetMetadataCacheOptions), and when the controller reports the instance as
+// missing.
+type TagHandle struct {
+	client *EipClient
+	name   string
+	parsed tagpath.Path
+
+	mu       sync.Mutex
+	dataType PlcDataType
+	typed    bool // dataType is known
+	instance uint32
+	path     []byte
+	// changes is the number of program changes detected when the instance
+	// was looked up
+	changes int64
+}
+
+// ResolveHandle looks up the Symbol instance of the tag tagName, or the tag
+// it is an alias of, is in and returns a handle that addresses it by
+// instance. Members and elements, such
+// as "Recipe[2].Speed", are addressed within that instance. The instance is
+// taken from the tag database if it has the tag, or else found by listing
+// the symbols of the tag's scope once. Bits of integers cannot be addressed;
+// use ReadBit and WriteBit.
+func (c *EipClient) ResolveHandle(tagName string) (*TagHandle, error) {
+	name := c.TagNameOptions().Clean(c.ResolveAlias(tagName))
+	parsed, err := parseTagPath(name)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.HasBit {
+		return nil, NewEipErrorWithDetails(ErrInvalidTagAddress, fmt.Sprintf("bit '%s' cannot have a handle; use ReadBit/WriteBit", name),
+			map[string]interface{}{"tag_name": name})
+	}
+
+	h := &TagHandle{client: c, name: name, parsed: parsed}
+	h.dataType, h.typed = c.tagTypes.Lookup(name)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.resolve(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Name returns the tag name the handle was resolved for
+func (h *TagHandle) Name() string {
+	return h.name
+}
+
+// Instance returns the Symbol instance ID the handle addresses
+func (h *TagHandle) Instance() uint32 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.instance
+}
+
+// DataType returns the tag's type, once known from TagTypes or the first read
+func (h *TagHandle) DataType() (PlcDataType, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.dataType, h.typed
+}
+
+// Read reads the tag. Its type is taken from TagTypes, or else from the
+// first reply; atomic types, time types known to TagTypes and STRING can be
+// read, structures with ReadUdt.
+func (h *TagHandle) Read() (*PlcValue, error) {
+	var value *PlcValue
+	err := h.client.submit(OperationRead, h.name, func() (err error) {
+		value, err = h.read()
+		return err
+	})
+	return value, err
+}
+
+// read reads the tag without queuing
+func (h *TagHandle) read() (*PlcValue, error) {
+	resp, err := h.send(CIPServiceReadTag, []byte{0x01, 0x00})
+	if err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	dataType, typed := h.dataType, h.typed
+	h.mu.Unlock()
+	if !typed {
+		if dataType, err = replyDataType(h.name, resp.Data); err != nil {
+			return nil, err
+		}
+		h.mu.Lock()
+		h.dataType, h.typed = dataType, true
+		h.mu.Unlock()
+	}
+	value, err := decodeTagValue(dataType, resp.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &PlcValue{Type: dataType, Value: value}, nil
+}
+
+// Write writes value to the tag. It is audited and checked against the
+// write policy like WriteValue. STRING values are written by name.
+func (h *TagHandle) Write(value *PlcValue) error {
+	c := h.client
+	return c.submit(OperationWrite, h.name, func() error {
+		return c.audited(CallerIdentity{}, h.name, value.Type, value.Value, func() error {
+			return h.write(value)
+		})
+	})
+}
+
+// write writes value without auditing
+func (h *TagHandle) write(value *PlcValue) error {
+	if value.Type == String {
+		return h.client.writeValue(h.name, value)
+	}
+	code, _, ok := cipTypeInfo(value.Type)
+	if !ok {
+		return NewEipError(ErrInvalidDataType, fmt.Sprintf("unsupported data type %s for a tag handle", value.Type))
+	}
+	data, err := encodeElement(value.Type, value.Value)
+	if err != nil {
+		return err
+	}
+	// Type, element count and value
+	request := binary.LittleEndian.AppendUint16(nil, code)
+	request = append(request, 0x01, 0x00)
+	_, err = h.send(CIPServiceWriteTag, append(request, data...))
+	return err
+}
+
+// send sends a request to the tag's instance, looking the instance up again
+// when the program changed or the controller no longer has it
+func (h *TagHandle) send(service byte, data []byte) (*CIPResponse, error) {
+	h.mu.Lock()
+	if h.client.programChanges() != h.changes {
+		if err := h.resolve(); err != nil {
+			h.mu.Unlock()
+			return nil, err
+		}
+	}
+	path := h.path
+	h.mu.Unlock()
+
+	resp, err := h.client.SendCIPMessage(service, path, data)
+	if !objectMissing(err) {
+		return resp, err
+	}
+	h.mu.Lock()
+	if rerr := h.resolve(); rerr != nil {
+		h.mu.Unlock()
+		return resp, err
+	}
+	path = h.path
+	h.mu.Unlock()
+	return h.client.SendCIPMessage(service, path, data)
+}
+
+// resolve looks up the instance and builds the request path. Must be called
+// with mu held.
+func (h *TagHandle) resolve() error {
+	changes := h.client.programChanges()
+	instance, err := h.client.symbolInstance(h.parsed)
+	if err != nil {
+		return err
+	}
+	h.instance, h.path, h.changes = instance, handlePath(h.parsed, instance), changes
+	return nil
+}
+
+// symbolInstance returns the Symbol instance ID of the tag path is in
+func (c *EipClient) symbolInstance(path tagpath.Path) (uint32, error) {
+	root := path.Tag()
+	if db := c.TagDatabase(); db != nil {
+		if info, ok := db.Lookup(root); ok {
+			return info.InstanceID, nil
+		}
+	}
+	tags, err := discovery.List(context.Background(), c.symbolPage, path.Program, nil)
+	if err != nil {
+		return 0, err
+	}
+	for _, tag := range tags {
+		if strings.EqualFold(tag.Name, root) {
+			return tag.InstanceID, nil
+		}
+	}
+	return 0, NewEipErrorWithDetails(ErrTagNotFound, fmt.Sprintf("tag '%s' not found", root),
+		map[string]interface{}{"tag_name": root})
+}
+
+// programChanges returns the number of program changes the client detected
+func (c *EipClient) programChanges() int64 {
+	c.programWatch.mu.Lock()
+	defer c.programWatch.mu.Unlock()
+	return c.programWatch.changes
+}
+
+// handlePath encodes the request path of a tag path whose tag is the Symbol
+// instance: the program segment, the instance, then the members and
+// elements within it
+func handlePath(path tagpath.Path, instance uint32) []byte {
+	var p []byte
+	if path.Program != "" {
+		p = symbolicSegment("Program:" + path.Program)
+	}
+	p = append(p, classInstancePath(CIPClassSymbol, instance)...)
+	for i, member := range path.Members {
+		if i > 0 {
+			p = append(p, symbolicSegment(member.Name)...)
+		}
+		for _, index := range member.Indices {
+			p = append(p, logicalSegment(0x28, uint32(index))...)
+		}
+	}
+	return p
+}
+
+// replyDataType returns the type of a Read Tag reply of an untyped tag
+func replyDataType(tagName string, data []byte) (PlcDataType, error) {
+	if len(data) < 2 {
+		return 0, NewEipError(ErrInvalidValue, "Read Tag reply too short")
+	}
+	code := binary.LittleEndian.Uint16(data)
+	if dataType, ok := atomicDataType(code); ok {
+		return dataType, nil
+	}
+	if code == CIPTypeStruct && len(data) >= 4 && binary.LittleEndian.Uint16(data[2:]) == logixStringHandle {
+		return String, nil
+	}
+	return 0, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("tag '%s' is a structure; read it with ReadUdt", tagName),
+		map[string]interface{}{"tag_name": tagName, "cip_type": code})
+}
@@ -0,0 +1,75 @@
+package ethernetip
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/tagpath"
+)
+
+// TestResolveHandle tests looking up the instance in the tag database and
+// addressing members and elements within it
+func TestResolveHandle(t *testing.T) {
+	client := &EipClient{}
+	client.tagDB.Store(NewTagDatabase([]TagInfo{
+		{Name: "Speed", InstanceID: 0x12, SymbolType: CIPTypeDint},
+		{Name: "Program:Main.Recipe", InstanceID: 0x345, SymbolType: symbolTypeStructBit | 0x100, Program: "Main"},
+	}))
+	client.tagTypes.Set("Speed", Dint)
+
+	h, err := client.ResolveHandle("Speed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Instance() != 0x12 {
+		t.Errorf("Expected instance 0x12, got 0x%X", h.Instance())
+	}
+	if dataType, ok := h.DataType(); !ok || dataType != Dint {
+		t.Errorf("Expected the type from TagTypes, got %v %v", dataType, ok)
+	}
+
+	h, err = client.ResolveHandle("Program:Main.Recipe[2].Speed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []byte
+	want = append(want, symbolicSegment("Program:Main")...)
+	want = append(want, 0x20, 0x6B, 0x25, 0x00, 0x45, 0x03, 0x28, 0x02)
+	want = append(want, symbolicSegment("Speed")...)
+	if !bytes.Equal(h.path, want) {
+		t.Errorf("got % X, want % X", h.path, want)
+	}
+
+	var eipErr *EipError
+	if _, err := client.ResolveHandle("Speed.3"); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagAddress {
+		t.Errorf("Expected ErrInvalidTagAddress for a bit, got %v", err)
+	}
+	// Tags missing from the database are looked up on the controller, which
+	// fails offline
+	if _, err := client.ResolveHandle("Other"); err == nil {
+		t.Error("Expected an error without a controller")
+	}
+}
+
+// TestHandlePath tests instance paths of controller tags
+func TestHandlePath(t *testing.T) {
+	got := handlePath(tagpath.MustParse("Grid[1,2]"), 7)
+	want := []byte{0x20, 0x6B, 0x24, 0x07, 0x28, 0x01, 0x28, 0x02}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % X, want % X", got, want)
+	}
+}
+
+// TestReplyDataType tests typing untyped handles from their first reply
+func TestReplyDataType(t *testing.T) {
+	if dataType, err := replyDataType("Speed", []byte{0xCA, 0x00, 0, 0, 0, 0}); err != nil || dataType != Real {
+		t.Errorf("Expected REAL, got %v, %v", dataType, err)
+	}
+	if dataType, err := replyDataType("Name", []byte{0xA0, 0x02, 0xCE, 0x0F}); err != nil || dataType != String {
+		t.Errorf("Expected STRING, got %v, %v", dataType, err)
+	}
+	if _, err := replyDataType("Recipe", []byte{0xA0, 0x02, 0x34, 0x12}); err == nil {
+		t.Error("Expected an error for a structure")
+	}
+}
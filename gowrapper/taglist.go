@@ -0,0 +1,160 @@
+package ethernetip
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// TagListFormat is a file format of ExportTags
+type TagListFormat string
+
+const (
+	TagListCSV  TagListFormat = "csv"
+	TagListJSON TagListFormat = "json"
+)
+
+// typeBoolArray is the type code the controller reports for BOOL arrays,
+// which are stored as 32-bit words
+const typeBoolArray uint16 = 0xD3
+
+// TagListEntry is a tag as listed by TagList and ExportTags
+type TagListEntry struct {
+	Name string `json:"name"`
+	// Type is the atomic type name ("DINT") or the structure type name
+	Type string `json:"type"`
+	// Dimensions holds the size of each array dimension, 0 where the size
+	// is not known; it is empty for scalars
+	Dimensions []int `json:"dimensions,omitempty"`
+	// Scope is "controller" or "program"
+	Scope      string `json:"scope"`
+	Program    string `json:"program,omitempty"`
+	InstanceID uint32 `json:"instance_id"`
+}
+
+// TypeString returns the type with its dimensions, as Studio 5000 shows it:
+// "DINT", "REAL[10]" or "INT[4,3]", with "?" for unknown sizes
+func (e TagListEntry) TypeString() string {
+	if len(e.Dimensions) == 0 {
+		return e.Type
+	}
+	dims := make([]string, len(e.Dimensions))
+	for i, d := range e.Dimensions {
+		dims[i] = "?"
+		if d > 0 {
+			dims[i] = strconv.Itoa(d)
+		}
+	}
+	return e.Type + "[" + strings.Join(dims, ",") + "]"
+}
+
+// TagList lists the tags of the last DiscoverTagDatabase by name, without
+// system tags and the entries that stand for programs. Structure type names
+// come from their templates, read as needed, and array sizes from the tag
+// metadata, looked up with GetTagMetadataBulk; sizes that cannot be looked up
+// are listed as 0.
+func (c *EipClient) TagList() ([]TagListEntry, error) {
+	db := c.TagDatabase()
+	if db == nil {
+		return nil, NewEipError(ErrInvalidOperation, "no tag database; run DiscoverTagDatabase first")
+	}
+
+	var arrays []string
+	for _, tag := range db.Tags {
+		if listedTag(tag) && tag.Dimensions() > 0 {
+			arrays = append(arrays, tag.Name)
+		}
+	}
+	// Tags whose metadata cannot be read keep sizes of 0
+	metas, _ := c.GetTagMetadataBulk(arrays)
+
+	entries := make([]TagListEntry, 0, len(db.Tags))
+	for _, tag := range db.Tags {
+		if !listedTag(tag) {
+			continue
+		}
+		entry := TagListEntry{
+			Name:       tag.Name,
+			Type:       c.typeName(tag),
+			Scope:      "controller",
+			Program:    tag.Program,
+			InstanceID: tag.InstanceID,
+		}
+		if tag.Program != "" {
+			entry.Scope = "program"
+		}
+		if n := tag.Dimensions(); n > 0 {
+			entry.Dimensions = make([]int, n)
+			switch meta := metas[tag.Name]; {
+			case meta == nil:
+			case len(meta.Dimensions) == n:
+				copy(entry.Dimensions, meta.Dimensions)
+			case n == 1:
+				entry.Dimensions[0] = meta.ArraySize
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// listedTag reports whether TagList lists tag
+func listedTag(tag TagInfo) bool {
+	return !tag.IsSystem() && !(strings.HasPrefix(tag.Name, "Program:") && tag.Program == "")
+}
+
+// typeName returns the type name of a tag: the atomic type name, or the
+// template name of a structure, or the type code when neither is known
+func (c *EipClient) typeName(tag TagInfo) string {
+	code := tag.TypeCode()
+	if tag.IsStructure() {
+		if template, err := c.GetTemplate(code); err == nil {
+			return template.Name
+		}
+	} else if dataType, ok := atomicDataType(code); ok {
+		return dataType.String()
+	} else if code == typeBoolArray {
+		return Bool.String()
+	}
+	return fmt.Sprintf("0x%04X", code)
+}
+
+// ExportTags writes the tags listed by TagList to w, as CSV with a header row
+// (name, type, dimensions, scope, program, instance_id) or as an indented
+// JSON array of TagListEntry, for documentation and for diffing the
+// controller between visits
+func (c *EipClient) ExportTags(w io.Writer, format TagListFormat) error {
+	if format != TagListCSV && format != TagListJSON {
+		return NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("unknown tag list format %q", format),
+			map[string]interface{}{"format": format})
+	}
+	entries, err := c.TagList()
+	if err != nil {
+		return err
+	}
+	return writeTagList(w, format, entries)
+}
+
+// writeTagList writes entries in format
+func writeTagList(w io.Writer, format TagListFormat, entries []TagListEntry) error {
+	if format == TagListJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"name", "type", "dimensions", "scope", "program", "instance_id"})
+	for _, e := range entries {
+		dims := make([]string, len(e.Dimensions))
+		for i, d := range e.Dimensions {
+			dims[i] = strconv.Itoa(d)
+		}
+		cw.Write([]string{e.Name, e.Type, strings.Join(dims, "x"), e.Scope, e.Program, strconv.FormatUint(uint64(e.InstanceID), 10)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,65 @@
+package ethernetip
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestTagList tests listing the tag database with type names and sizes
+func TestTagList(t *testing.T) {
+	if _, err := (&EipClient{}).TagList(); err == nil {
+		t.Error("Expected an error without a tag database")
+	}
+
+	entries, err := browseClient().TagList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name+":"+e.TypeString()+":"+e.Scope)
+	}
+	want := []string{"Grid:DINT[?,?]:controller", "Program:Main.Count:DINT:program", "Recipe:RECIPE:controller",
+		"Speed:DINT:controller", "Values:DINT[4]:controller"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if entries[1].Program != "Main" {
+		t.Errorf("Expected program Main, got %q", entries[1].Program)
+	}
+}
+
+// TestExportTags tests the CSV and JSON formats
+func TestExportTags(t *testing.T) {
+	client := browseClient()
+
+	var buf bytes.Buffer
+	if err := client.ExportTags(&buf, TagListCSV); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 || lines[0] != "name,type,dimensions,scope,program,instance_id" {
+		t.Fatalf("Unexpected CSV %q", buf.String())
+	}
+	if lines[1] != "Grid,DINT,0x0,controller,,0" || lines[2] != "Program:Main.Count,DINT,,program,Main,0" {
+		t.Errorf("Unexpected CSV rows %q", lines[1:3])
+	}
+
+	buf.Reset()
+	if err := client.ExportTags(&buf, TagListJSON); err != nil {
+		t.Fatal(err)
+	}
+	var entries []TagListEntry
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 || entries[4].Name != "Values" || len(entries[4].Dimensions) != 1 || entries[4].Dimensions[0] != 4 {
+		t.Errorf("Unexpected JSON %s", buf.String())
+	}
+
+	if err := client.ExportTags(&buf, "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
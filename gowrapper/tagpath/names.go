@@ -0,0 +1,44 @@
+package tagpath
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameOptions controls how tag names are matched by caches, subscriptions
+// and type maps. The zero value matches names exactly.
+type NameOptions struct {
+	// CaseInsensitive treats names that differ only in letter case as the same
+	// tag, as Logix controllers do
+	CaseInsensitive bool
+	// Trim removes whitespace from names, e.g. " Motor1 . Speed" becomes
+	// "Motor1.Speed". Logix identifiers never contain whitespace.
+	Trim bool
+}
+
+// LogixNames matches names the way a Logix controller resolves them
+var LogixNames = NameOptions{CaseInsensitive: true, Trim: true}
+
+// Clean returns the name to send to the PLC: the name with whitespace removed
+// when Trim is set, with its letter case preserved
+func (o NameOptions) Clean(name string) string {
+	if !o.Trim {
+		return name
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, name)
+}
+
+// Key returns the name under which a tag is cached. Two names refer to the
+// same tag when their keys are equal.
+func (o NameOptions) Key(name string) string {
+	name = o.Clean(name)
+	if o.CaseInsensitive {
+		name = strings.ToLower(name)
+	}
+	return name
+}
@@ -0,0 +1,17 @@
+package tagpath
+
+import "testing"
+
+// TestNameOptions tests name cleaning and keys
+func TestNameOptions(t *testing.T) {
+	var exact NameOptions
+	if exact.Key(" Motor1") != " Motor1" {
+		t.Error("Zero options should match names exactly")
+	}
+	if got := LogixNames.Clean(" Motor1 . Speed\t"); got != "Motor1.Speed" {
+		t.Errorf("Clean: got %q", got)
+	}
+	if LogixNames.Key("Program:Main.Motor1") != LogixNames.Key(" program:MAIN.motor1 ") {
+		t.Error("Expected names differing in case and whitespace to share a key")
+	}
+}
@@ -0,0 +1,346 @@
+// Package tagpath parses Logix tag names such as
+// "Program:Main.Recipe[2,3].Flags.5" into their program scope, member chain,
+// array indices and bit index, checks them against the Logix naming rules
+// and renders them back. It has no dependency on the native library, so
+// gateways can validate and rewrite tag names before they reach a client.
+package tagpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Limits of Logix tag names
+const (
+	// MaxNameLength is the longest identifier a controller accepts
+	MaxNameLength = 40
+	// MaxDimensions is the most dimensions an array can have
+	MaxDimensions = 3
+	// MaxBit is the highest bit of the widest integer (LINT)
+	MaxBit = 63
+)
+
+// programPrefix starts the name of a program-scoped tag
+const programPrefix = "Program:"
+
+// Path is a parsed tag name
+type Path struct {
+	// Program is the program of a program-scoped tag, empty for controller
+	// tags
+	Program string
+	// Members is the tag followed by the structure members addressed in it,
+	// "Recipe[2]" and "Speed" for "Recipe[2].Speed"
+	Members []Member
+	// Bit is the bit addressed in an integer, when HasBit is set
+	Bit    int
+	HasBit bool
+}
+
+// Member is an identifier of the member chain and the array element it
+// addresses, if any
+type Member struct {
+	Name string
+	// Indices are the subscripts of an array element, one per dimension
+	Indices []int
+}
+
+// Error describes a tag name that does not parse
+type Error struct {
+	Tag string
+	// Offset is the byte offset in Tag where the problem was found
+	Offset int
+	Reason string
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	return fmt.Sprintf("tagpath: %s at offset %d of '%s'", e.Reason, e.Offset, e.Tag)
+}
+
+// Parse parses a tag name. Identifiers must start with a letter or
+// underscore, contain only letters, digits and underscores and be at most
+// MaxNameLength characters; arrays have at most MaxDimensions indices, and a
+// bit index may only end the name. Whitespace is only allowed around array
+// indices. The prefix "Program:" is matched ignoring case.
+func Parse(tag string) (Path, error) {
+	p := parser{tag: tag}
+	return p.parse()
+}
+
+// MustParse is Parse for names known to be valid; it panics on error
+func MustParse(tag string) Path {
+	path, err := Parse(tag)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+// Valid reports whether tag parses
+func Valid(tag string) bool {
+	_, err := Parse(tag)
+	return err == nil
+}
+
+// ValidateName checks a single identifier against the rules Studio 5000
+// applies when a tag, program or member is created: besides what Parse
+// accepts, it may not contain two underscores in a row or end with one.
+// Parse is more lenient so that existing system tags such as
+// "__DEFVAL_0000" can still be addressed.
+func ValidateName(name string) error {
+	if n := identifierLength(name); n != len(name) || n == 0 {
+		return &Error{Tag: name, Offset: n, Reason: "invalid identifier"}
+	}
+	if len(name) > MaxNameLength {
+		return &Error{Tag: name, Offset: MaxNameLength, Reason: fmt.Sprintf("identifier longer than %d characters", MaxNameLength)}
+	}
+	if i := strings.Index(name, "__"); i >= 0 {
+		return &Error{Tag: name, Offset: i, Reason: "consecutive underscores"}
+	}
+	if strings.HasSuffix(name, "_") {
+		return &Error{Tag: name, Offset: len(name) - 1, Reason: "trailing underscore"}
+	}
+	return nil
+}
+
+// String renders the path as a tag name, "Program:Main.Recipe[2,3].Flags.5"
+func (p Path) String() string {
+	var b strings.Builder
+	if p.Program != "" {
+		b.WriteString(programPrefix)
+		b.WriteString(p.Program)
+		b.WriteByte('.')
+	}
+	for i, m := range p.Members {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(m.String())
+	}
+	if p.HasBit {
+		b.WriteByte('.')
+		b.WriteString(strconv.Itoa(p.Bit))
+	}
+	return b.String()
+}
+
+// String renders the member, "Recipe[2,3]"
+func (m Member) String() string {
+	if len(m.Indices) == 0 {
+		return m.Name
+	}
+	indices := make([]string, len(m.Indices))
+	for i, index := range m.Indices {
+		indices[i] = strconv.Itoa(index)
+	}
+	return m.Name + "[" + strings.Join(indices, ",") + "]"
+}
+
+// Tag returns the name of the tag the path is in, with its program scope
+// but without members or indices: "Program:Main.Recipe" for
+// "Program:Main.Recipe[2].Speed". Tag metadata is looked up by this name.
+func (p Path) Tag() string {
+	if len(p.Members) == 0 {
+		return ""
+	}
+	if p.Program != "" {
+		return programPrefix + p.Program + "." + p.Members[0].Name
+	}
+	return p.Members[0].Name
+}
+
+// WithoutBit returns the path of the integer a bit path addresses
+func (p Path) WithoutBit() Path {
+	p.Bit, p.HasBit = 0, false
+	return p
+}
+
+// Equal reports whether p and q address the same data. Identifiers are
+// compared ignoring case, as the controller resolves them.
+func (p Path) Equal(q Path) bool {
+	if !strings.EqualFold(p.Program, q.Program) || len(p.Members) != len(q.Members) ||
+		p.HasBit != q.HasBit || (p.HasBit && p.Bit != q.Bit) {
+		return false
+	}
+	for i, m := range p.Members {
+		n := q.Members[i]
+		if !strings.EqualFold(m.Name, n.Name) || len(m.Indices) != len(n.Indices) {
+			return false
+		}
+		for j := range m.Indices {
+			if m.Indices[j] != n.Indices[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parser scans a tag name
+type parser struct {
+	tag string
+	pos int
+}
+
+// fail returns an error at the current position
+func (p *parser) fail(format string, args ...interface{}) error {
+	return &Error{Tag: p.tag, Offset: p.pos, Reason: fmt.Sprintf(format, args...)}
+}
+
+// parse parses the whole name
+func (p *parser) parse() (Path, error) {
+	var path Path
+	if p.tag == "" {
+		return path, p.fail("empty tag name")
+	}
+	if len(p.tag) > len(programPrefix) && strings.EqualFold(p.tag[:len(programPrefix)], programPrefix) {
+		p.pos = len(programPrefix)
+		name, err := p.identifier()
+		if err != nil {
+			return path, err
+		}
+		if p.pos == len(p.tag) {
+			return path, p.fail("program '%s' names no tag", name)
+		}
+		path.Program = name
+		if err := p.expect('.'); err != nil {
+			return path, err
+		}
+	}
+
+	for {
+		if p.pos < len(p.tag) && isDigit(p.tag[p.pos]) && len(path.Members) > 0 {
+			start := p.pos
+			for p.pos < len(p.tag) && isDigit(p.tag[p.pos]) {
+				p.pos++
+			}
+			if p.pos != len(p.tag) {
+				return path, p.fail("a bit index must end the tag name")
+			}
+			bit, err := strconv.Atoi(p.tag[start:])
+			if err != nil || bit > MaxBit {
+				p.pos = start
+				return path, p.fail("bit %s out of range (0-%d)", p.tag[start:], MaxBit)
+			}
+			path.Bit, path.HasBit = bit, true
+			return path, nil
+		}
+
+		name, err := p.identifier()
+		if err != nil {
+			return path, err
+		}
+		member := Member{Name: name}
+		if p.pos < len(p.tag) && p.tag[p.pos] == '[' {
+			if member.Indices, err = p.indices(); err != nil {
+				return path, err
+			}
+		}
+		path.Members = append(path.Members, member)
+
+		if p.pos == len(p.tag) {
+			return path, nil
+		}
+		if err := p.expect('.'); err != nil {
+			return path, err
+		}
+	}
+}
+
+// identifier scans a name
+func (p *parser) identifier() (string, error) {
+	start := p.pos
+	n := identifierLength(p.tag[start:])
+	if n == 0 {
+		if p.pos == len(p.tag) {
+			return "", p.fail("missing name")
+		}
+		return "", p.fail("unexpected '%c'", p.tag[p.pos])
+	}
+	if n > MaxNameLength {
+		return "", p.fail("name longer than %d characters", MaxNameLength)
+	}
+	p.pos += n
+	return p.tag[start:p.pos], nil
+}
+
+// indices scans "[i,j,k]"
+func (p *parser) indices() ([]int, error) {
+	p.pos++ // '['
+	var indices []int
+	for {
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.tag) && isDigit(p.tag[p.pos]) {
+			p.pos++
+		}
+		if start == p.pos {
+			if p.pos == len(p.tag) {
+				return nil, p.fail("unterminated array index")
+			}
+			return nil, p.fail("invalid array index")
+		}
+		text := p.tag[start:p.pos]
+		index, err := strconv.ParseUint(text, 10, 31)
+		if err != nil {
+			p.pos = start
+			return nil, p.fail("array index %s out of range", text)
+		}
+		indices = append(indices, int(index))
+		if len(indices) > MaxDimensions {
+			p.pos = start
+			return nil, p.fail("more than %d array dimensions", MaxDimensions)
+		}
+		p.skipSpace()
+		if p.pos == len(p.tag) {
+			return nil, p.fail("unterminated array index")
+		}
+		switch p.tag[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return indices, nil
+		default:
+			return nil, p.fail("invalid array index")
+		}
+	}
+}
+
+// expect consumes c
+func (p *parser) expect(c byte) error {
+	if p.pos == len(p.tag) {
+		return p.fail("missing '%c'", c)
+	}
+	if p.tag[p.pos] != c {
+		return p.fail("unexpected '%c'", p.tag[p.pos])
+	}
+	p.pos++
+	if c == '.' && p.pos == len(p.tag) {
+		return p.fail("missing member after '.'")
+	}
+	return nil
+}
+
+// skipSpace skips blanks inside brackets
+func (p *parser) skipSpace() {
+	for p.pos < len(p.tag) && (p.tag[p.pos] == ' ' || p.tag[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// identifierLength returns the length of the identifier s starts with
+func identifierLength(s string) int {
+	if s == "" || !(isLetter(s[0]) || s[0] == '_') {
+		return 0
+	}
+	n := 1
+	for n < len(s) && (isLetter(s[n]) || isDigit(s[n]) || s[n] == '_') {
+		n++
+	}
+	return n
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
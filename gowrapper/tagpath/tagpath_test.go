@@ -0,0 +1,114 @@
+package tagpath
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParse tests parsing tag names into their components
+func TestParse(t *testing.T) {
+	cases := []struct {
+		tag  string
+		want Path
+	}{
+		{"Motor1", Path{Members: []Member{{Name: "Motor1"}}}},
+		{"_Tmp.Speed", Path{Members: []Member{{Name: "_Tmp"}, {Name: "Speed"}}}},
+		{"Program:Main.Recipe[2, 3].Flags.5", Path{
+			Program: "Main",
+			Members: []Member{{Name: "Recipe", Indices: []int{2, 3}}, {Name: "Flags"}},
+			Bit:     5, HasBit: true,
+		}},
+		{"Arr[0].31", Path{Members: []Member{{Name: "Arr", Indices: []int{0}}}, Bit: 31, HasBit: true}},
+		{"PROGRAM:Main.Count", Path{Program: "Main", Members: []Member{{Name: "Count"}}}},
+	}
+	for _, c := range cases {
+		got, err := Parse(c.tag)
+		if err != nil {
+			t.Errorf("%s: %v", c.tag, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.tag, got, c.want)
+		}
+	}
+}
+
+// TestParseErrors tests that malformed names are rejected with the offset of
+// the problem
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		tag    string
+		offset int
+	}{
+		{"", 0},
+		{"Tag.", 4},
+		{"Tag[1", 5},
+		{"Tag[x]", 4},
+		{"Tag[1,2,3,4]", 10},
+		{"Tag[1][2]", 6},
+		{"1Tag", 0},
+		{"Word.3.Bit", 6},
+		{"Word.64", 5},
+		{"Program:Main", 12},
+		{"Motor 1", 5},
+		{"Tag..Member", 4},
+		{strings.Repeat("A", 41), 0},
+	}
+	for _, c := range cases {
+		_, err := Parse(c.tag)
+		var pathErr *Error
+		if !errors.As(err, &pathErr) {
+			t.Errorf("%q: expected *Error, got %v", c.tag, err)
+			continue
+		}
+		if pathErr.Offset != c.offset {
+			t.Errorf("%q: offset %d, want %d (%v)", c.tag, pathErr.Offset, c.offset, err)
+		}
+	}
+	if Valid("Tag[1") || !Valid("Tag[1]") {
+		t.Error("Valid disagrees with Parse")
+	}
+}
+
+// TestRender tests that parsed names render back canonically
+func TestRender(t *testing.T) {
+	for tag, want := range map[string]string{
+		"Program:Main.Recipe[2, 3].Flags.5": "Program:Main.Recipe[2,3].Flags.5",
+		"program:Main.Count":                "Program:Main.Count",
+		"Motor1.Speed":                      "Motor1.Speed",
+	} {
+		if got := MustParse(tag).String(); got != want {
+			t.Errorf("%s: got %s, want %s", tag, got, want)
+		}
+	}
+
+	path := MustParse("Program:Main.Recipe[2].Speed")
+	if path.Tag() != "Program:Main.Recipe" {
+		t.Errorf("Tag: got %s", path.Tag())
+	}
+	if bit := MustParse("Status.5"); bit.WithoutBit().String() != "Status" {
+		t.Errorf("WithoutBit: got %s", bit.WithoutBit())
+	}
+	if !MustParse("program:MAIN.recipe[2].SPEED").Equal(path) || MustParse("Program:Main.Recipe[3].Speed").Equal(path) {
+		t.Error("Equal should ignore case but not indices")
+	}
+}
+
+// TestValidateName tests the naming rules for new identifiers
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"Motor1", "_Tmp", "A_B"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "1A", "A__B", "A_", "A.B", strings.Repeat("A", 41)} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("%q: expected error", name)
+		}
+	}
+	if !Valid("__DEFVAL_0000") {
+		t.Error("Parse should accept system tags")
+	}
+}
@@ -0,0 +1,138 @@
+package ethernetip
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Scopes of TagFilter.Scope other than program names
+const (
+	SearchScopeController = "controller"
+	SearchScopeProgram    = "program"
+)
+
+// TagFilter narrows a tag search beyond the name pattern. The zero value
+// matches every tag except system tags.
+type TagFilter struct {
+	// Regexp makes the pattern a regular expression, matched anywhere in the
+	// tag name, instead of a glob
+	Regexp bool `json:"regexp,omitempty"`
+	// Scope limits the search to controller tags (SearchScopeController),
+	// program tags (SearchScopeProgram) or the tags of one program, named
+	// with or without "Program:"
+	Scope string `json:"scope,omitempty"`
+	// Type limits the search to tags of one data type, an atomic type such
+	// as "DINT" or a structure type, matched ignoring case. Arrays of the
+	// type are included.
+	Type string `json:"type,omitempty"`
+	// System includes the controller's system tags
+	System bool `json:"system,omitempty"`
+	// Limit caps the number of tags returned; 0 returns them all
+	Limit int `json:"limit,omitempty"`
+}
+
+// SearchTags returns the tags of the discovered tag database whose names
+// match pattern and that pass filter, in name order. The pattern is a glob
+// in which * matches any run of characters and ? matches one, compared
+// ignoring case with the whole name; for program tags, the name within the
+// program matches as well, so "Motor*" finds "Program:Line1.Motor3". An
+// empty pattern matches every tag. Structure types are named from their
+// templates, which are read once per type.
+func (c *EipClient) SearchTags(pattern string, filter TagFilter) ([]TagInfo, error) {
+	db := c.TagDatabase()
+	if db == nil {
+		return nil, NewEipError(ErrInvalidOperation, "no tag database; run DiscoverTagDatabase first")
+	}
+	return db.Search(pattern, filter, c)
+}
+
+// Search returns the tags of the database that match pattern and pass filter
+// (see EipClient.SearchTags). Structure types are named through templates;
+// with nil templates, a Type filter matches atomic types only.
+func (db *TagDatabase) Search(pattern string, filter TagFilter, templates TemplateSource) ([]TagInfo, error) {
+	match, err := tagNameMatcher(pattern, filter.Regexp)
+	if err != nil {
+		return nil, err
+	}
+	typeNames := map[uint16]string{}
+	typeName := func(tag TagInfo) string {
+		if !tag.IsStructure() {
+			dataType, ok := atomicDataType(tag.TypeCode())
+			if !ok {
+				return ""
+			}
+			return dataType.String()
+		}
+		code := tag.TypeCode()
+		name, ok := typeNames[code]
+		if !ok && templates != nil {
+			if template, err := templates.GetTemplate(code); err == nil {
+				name = template.Name
+			}
+			typeNames[code] = name
+		}
+		return name
+	}
+
+	var found []TagInfo
+	for _, tag := range db.Tags {
+		if filter.Limit > 0 && len(found) == filter.Limit {
+			break
+		}
+		switch {
+		case tag.IsSystem() && !filter.System:
+		case tag.Program == "" && strings.HasPrefix(tag.Name, "Program:"):
+			// A program, not a tag
+		case !inSearchScope(tag, filter.Scope):
+		case !match(tag):
+		case filter.Type != "" && !strings.EqualFold(typeName(tag), filter.Type):
+		default:
+			found = append(found, tag)
+		}
+	}
+	return found, nil
+}
+
+// tagNameMatcher compiles a search pattern
+func tagNameMatcher(pattern string, isRegexp bool) (func(TagInfo) bool, error) {
+	if isRegexp {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, NewEipErrorWithDetails(ErrInvalidTagName, fmt.Sprintf("invalid tag search pattern: %v", err),
+				map[string]interface{}{"pattern": pattern})
+		}
+		return func(tag TagInfo) bool { return re.MatchString(tag.Name) }, nil
+	}
+	if pattern == "" {
+		return func(TagInfo) bool { return true }, nil
+	}
+	pattern = strings.ToLower(pattern)
+	return func(tag TagInfo) bool {
+		name := strings.ToLower(tag.Name)
+		if globMatch(pattern, name) {
+			return true
+		}
+		if tag.Program != "" {
+			local := strings.TrimPrefix(name, strings.ToLower("Program:"+tag.Program+"."))
+			return local != name && globMatch(pattern, local)
+		}
+		return false
+	}, nil
+}
+
+// inSearchScope reports whether tag is in scope, a TagFilter.Scope
+func inSearchScope(tag TagInfo, scope string) bool {
+	switch scope {
+	case "":
+		return true
+	case SearchScopeController:
+		return tag.Program == ""
+	case SearchScopeProgram:
+		return tag.Program != ""
+	}
+	if len(scope) > 8 && strings.EqualFold(scope[:8], "Program:") {
+		scope = scope[8:]
+	}
+	return tag.Program != "" && strings.EqualFold(tag.Program, scope)
+}
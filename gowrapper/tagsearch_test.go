@@ -0,0 +1,64 @@
+package ethernetip
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestSearchTags tests name patterns and the scope, type and system filters
+func TestSearchTags(t *testing.T) {
+	if _, err := (&EipClient{}).SearchTags("*", TagFilter{}); err == nil {
+		t.Error("Expected an error without a tag database")
+	}
+
+	client := browseClient()
+	client.tagDB.Store(NewTagDatabase(append(client.TagDatabase().Tags,
+		TagInfo{Name: "Program:Line1", SymbolType: 0x68},
+		TagInfo{Name: "Program:Line1.Motor3", SymbolType: CIPTypeReal, Program: "Line1"},
+		TagInfo{Name: "Program:Line1.Recipes", SymbolType: symbolTypeStructBit | 1<<symbolTypeDimsShift | 0x100, Program: "Line1"},
+		TagInfo{Name: "Motor1", SymbolType: CIPTypeReal},
+	)))
+
+	names := func(pattern string, filter TagFilter) []string {
+		t.Helper()
+		tags, err := client.SearchTags(pattern, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+		return names
+	}
+	for _, tc := range []struct {
+		pattern string
+		filter  TagFilter
+		want    []string
+	}{
+		{"motor*", TagFilter{}, []string{"Motor1", "Program:Line1.Motor3"}},
+		{"Program:Line1.*", TagFilter{}, []string{"Program:Line1.Motor3", "Program:Line1.Recipes"}},
+		{"", TagFilter{Scope: "program:line1"}, []string{"Program:Line1.Motor3", "Program:Line1.Recipes"}},
+		{"", TagFilter{Scope: SearchScopeProgram}, []string{"Program:Line1.Motor3", "Program:Line1.Recipes", "Program:Main.Count"}},
+		{"M*", TagFilter{Scope: SearchScopeController}, []string{"Motor1"}},
+		{"", TagFilter{Type: "real"}, []string{"Motor1", "Program:Line1.Motor3"}},
+		{"", TagFilter{Type: "RECIPE"}, []string{"Program:Line1.Recipes", "Recipe"}},
+		{"", TagFilter{Type: "DINT", Limit: 2}, []string{"Grid", "Program:Main.Count"}},
+		{"_*", TagFilter{}, nil},
+		{"_*", TagFilter{System: true}, []string{"__Internal"}},
+		{`^Program:\w+\.Motor\d$`, TagFilter{Regexp: true}, []string{"Program:Line1.Motor3"}},
+	} {
+		if got := names(tc.pattern, tc.filter); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q %+v: got %v, want %v", tc.pattern, tc.filter, got, tc.want)
+		}
+	}
+
+	var eipErr *EipError
+	if _, err := client.SearchTags("Motor[", TagFilter{Regexp: true}); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagName {
+		t.Errorf("Expected ErrInvalidTagName for a bad expression, got %v", err)
+	}
+	if tags, _ := client.TagDatabase().Search("", TagFilter{Type: "RECIPE"}, nil); len(tags) != 0 {
+		t.Errorf("Expected structure types to be unknown without templates, got %v", tags)
+	}
+}
@@ -0,0 +1,132 @@
+package ethernetip
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// TagTypes maps tag names to data types so that callers, such as HTTP
+// clients, can read tags without naming the type on every request. The map is
+// filled from configuration and from tag discovery. The zero value is an
+// empty map ready for use.
+type TagTypes struct {
+	mu    sync.RWMutex
+	types map[string]PlcDataType // keyed by names.Key
+	names TagNameOptions
+}
+
+// NewTagTypes creates a type map from types
+func NewTagTypes(types map[string]PlcDataType) *TagTypes {
+	t := &TagTypes{}
+	for name, dataType := range types {
+		t.Set(name, dataType)
+	}
+	return t
+}
+
+// Set records the data type of a tag, replacing any previous entry
+func (t *TagTypes) Set(tagName string, dataType PlcDataType) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.types == nil {
+		t.types = make(map[string]PlcDataType)
+	}
+	t.types[t.names.Key(tagName)] = dataType
+}
+
+// Lookup returns the data type recorded for a tag
+func (t *TagTypes) Lookup(tagName string) (PlcDataType, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	dataType, ok := t.types[t.names.Key(tagName)]
+	return dataType, ok
+}
+
+// SetNameOptions sets how tag names are matched and re-keys the existing
+// entries. When several entries collapse onto one name, one of them is kept.
+func (t *TagTypes) SetNameOptions(opts TagNameOptions) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.names = opts
+	if t.types == nil {
+		return
+	}
+	types := make(map[string]PlcDataType, len(t.types))
+	for name, dataType := range t.types {
+		types[opts.Key(name)] = dataType
+	}
+	t.types = types
+}
+
+// Len returns the number of tags with a known type
+func (t *TagTypes) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.types)
+}
+
+// Load adds the entries of a JSON object mapping tag names to type names or
+// numbers, e.g. {"Speed": "REAL", "PartCount": "DINT"}. Any name understood by
+// ParsePlcDataType is accepted. Nothing is added if an entry is invalid.
+func (t *TagTypes) Load(r io.Reader) error {
+	var types map[string]PlcDataType
+	if err := json.NewDecoder(r).Decode(&types); err != nil {
+		return fmt.Errorf("failed to parse tag type map: %w", err)
+	}
+	for name, dataType := range types {
+		t.Set(name, dataType)
+	}
+	return nil
+}
+
+// AddDatabase records the type of every scalar atomic tag in a discovered tag
+// database. Structures and arrays are skipped; entries loaded from
+// configuration take precedence and are not overwritten.
+func (t *TagTypes) AddDatabase(db *TagDatabase) int {
+	if db == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.types == nil {
+		t.types = make(map[string]PlcDataType)
+	}
+	added := 0
+	for _, tag := range db.Tags {
+		if tag.IsStructure() || tag.Dimensions() != 0 {
+			continue
+		}
+		dataType, ok := atomicDataType(tag.TypeCode())
+		if !ok {
+			continue
+		}
+		key := t.names.Key(tag.Name)
+		if _, exists := t.types[key]; exists {
+			continue
+		}
+		t.types[key] = dataType
+		added++
+	}
+	return added
+}
+
+// TagTypes returns the client's default data type map. DiscoverTagDatabase
+// adds the discovered tags to it.
+func (c *EipClient) TagTypes() *TagTypes {
+	return &c.tagTypes
+}
+
+// ReadTag reads a tag using the data type recorded in the client's TagTypes
+func (c *EipClient) ReadTag(tagName string) (*PlcValue, error) {
+	// The type is recorded under the tag, so look the alias up first;
+	// ReadValue resolves it again when queuing the read
+	resolved := c.ResolveAlias(tagName)
+	dataType, ok := c.tagTypes.Lookup(resolved)
+	if !ok {
+		return nil, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("no data type known for tag '%s'", resolved),
+			map[string]interface{}{"tag_name": resolved})
+	}
+	return c.ReadValue(tagName, dataType)
+}
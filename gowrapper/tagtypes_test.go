@@ -0,0 +1,56 @@
+package ethernetip
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestTagTypesLoad tests loading a type map from JSON configuration
+func TestTagTypesLoad(t *testing.T) {
+	var types TagTypes
+	if err := types.Load(strings.NewReader(`{"Speed": "float", "PartCount": "DINT", "Mode": 2}`)); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]PlcDataType{"Speed": Real, "PartCount": Dint, "Mode": Int} {
+		if got, ok := types.Lookup(name); !ok || got != want {
+			t.Errorf("%s: got %v, %v; want %v", name, got, ok, want)
+		}
+	}
+	if err := types.Load(strings.NewReader(`{"Bad": "quux"}`)); err == nil {
+		t.Error("Expected error for unknown type")
+	}
+	if _, ok := types.Lookup("Bad"); ok {
+		t.Error("Expected invalid map to add nothing")
+	}
+}
+
+// TestTagTypesAddDatabase tests taking types from a discovered tag database
+func TestTagTypesAddDatabase(t *testing.T) {
+	types := NewTagTypes(map[string]PlcDataType{"Level": Lreal})
+	db := NewTagDatabase([]TagInfo{
+		{Name: "Speed", SymbolType: CIPTypeReal},
+		{Name: "Level", SymbolType: CIPTypeReal},
+		{Name: "History", SymbolType: CIPTypeDint | 1<<symbolTypeDimsShift},
+		{Name: "Motor", SymbolType: symbolTypeStructBit | 0x0123},
+	})
+	if added := types.AddDatabase(db); added != 1 {
+		t.Errorf("Expected 1 tag added, got %d", added)
+	}
+	if dt, _ := types.Lookup("Speed"); dt != Real {
+		t.Errorf("Expected Speed to be REAL, got %v", dt)
+	}
+	if dt, _ := types.Lookup("Level"); dt != Lreal {
+		t.Errorf("Expected configured type to win, got %v", dt)
+	}
+	if _, ok := types.Lookup("History"); ok {
+		t.Error("Expected arrays to be skipped")
+	}
+}
+
+// TestReadTagUnknownType tests ReadTag for a tag without a known type
+func TestReadTagUnknownType(t *testing.T) {
+	client := &EipClient{}
+	if _, err := client.ReadTag("Unknown"); err == nil {
+		t.Error("Expected error for tag without a known type")
+	}
+}
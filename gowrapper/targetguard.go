@@ -0,0 +1,142 @@
+package ethernetip
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TargetExpectation identifies the controller a client is meant to write
+// to. When set, the client reads the controller's identity before its first
+// write and after every reconnect, and refuses to write to any other
+// controller, so setpoints do not reach the wrong PLC when an IP address is
+// reused.
+type TargetExpectation struct {
+	// SerialNumber is the controller's serial number; 0 accepts any
+	SerialNumber uint32 `json:"serial_number,omitempty"`
+	// Catalog is the controller's catalog number, e.g. "1756-L83E", matched
+	// ignoring case against the product name without its series ("/B") and
+	// anything after a space; empty accepts any
+	Catalog string `json:"catalog,omitempty"`
+}
+
+// IsZero reports whether the expectation accepts any controller
+func (e TargetExpectation) IsZero() bool {
+	return e.SerialNumber == 0 && e.Catalog == ""
+}
+
+// check returns an error if identity is not the expected controller
+func (e TargetExpectation) check(identity *DeviceIdentity) error {
+	details := map[string]interface{}{
+		"expected_serial":  fmt.Sprintf("%08X", e.SerialNumber),
+		"expected_catalog": e.Catalog,
+		"actual_serial":    fmt.Sprintf("%08X", identity.SerialNumber),
+		"actual_product":   identity.ProductName,
+	}
+	if e.SerialNumber != 0 && identity.SerialNumber != e.SerialNumber {
+		return NewEipErrorWithDetails(ErrInvalidOperation,
+			fmt.Sprintf("controller serial %08X is not the expected %08X; writes refused", identity.SerialNumber, e.SerialNumber), details)
+	}
+	if e.Catalog != "" && !catalogMatch(e.Catalog, identity.ProductName) {
+		return NewEipErrorWithDetails(ErrInvalidOperation,
+			fmt.Sprintf("controller '%s' is not a %s; writes refused", identity.ProductName, e.Catalog), details)
+	}
+	return nil
+}
+
+// catalogMatch reports whether productName, e.g. "1756-L83E/B", is of
+// catalog
+func catalogMatch(catalog, productName string) bool {
+	base, _, _ := strings.Cut(productName, "/")
+	base, _, _ = strings.Cut(base, " ")
+	return strings.EqualFold(catalog, base) || strings.EqualFold(catalog, productName)
+}
+
+// targetGuard holds the expectation and whether the connected controller has
+// been verified against it
+type targetGuard struct {
+	mu       sync.Mutex
+	expect   TargetExpectation
+	verified *DeviceIdentity // nil until verified on the current session
+	// generation counts resets, so a verification that raced with a
+	// reconnect is not kept
+	generation uint64
+}
+
+// ExpectSerialNumber makes the client refuse writes unless the controller's
+// serial number is serial (see TargetExpectation). 0 removes the check.
+func (c *EipClient) ExpectSerialNumber(serial uint32) {
+	c.target.mu.Lock()
+	defer c.target.mu.Unlock()
+	c.target.expect.SerialNumber = serial
+	c.target.reset()
+}
+
+// ExpectCatalog makes the client refuse writes unless the controller is of
+// catalog, e.g. "1756-L83E" (see TargetExpectation). "" removes the check.
+func (c *EipClient) ExpectCatalog(catalog string) {
+	c.target.mu.Lock()
+	defer c.target.mu.Unlock()
+	c.target.expect.Catalog = catalog
+	c.target.reset()
+}
+
+// TargetExpectation returns the controller the client expects to write to
+func (c *EipClient) TargetExpectation() TargetExpectation {
+	c.target.mu.Lock()
+	defer c.target.mu.Unlock()
+	return c.target.expect
+}
+
+// VerifyTarget reads the controller's identity and checks it against the
+// expectation, as the first write does. A verified controller is not read
+// again until the session is re-opened. It returns the identity either way
+// so callers can report what was found.
+func (c *EipClient) VerifyTarget() (*DeviceIdentity, error) {
+	c.target.mu.Lock()
+	verified, expect, generation := c.target.verified, c.target.expect, c.target.generation
+	c.target.mu.Unlock()
+	if verified != nil {
+		return verified, nil
+	}
+
+	// Not under the lock: reading may re-open the session, which resets
+	// the verification
+	identity, err := c.ReadIdentity()
+	if err != nil {
+		return nil, err
+	}
+	if err := expect.check(identity); err != nil {
+		return identity, err
+	}
+	c.target.mu.Lock()
+	if c.target.generation == generation {
+		c.target.verified = identity
+	}
+	c.target.mu.Unlock()
+	return identity, nil
+}
+
+// checkTarget verifies the controller before a write when an expectation is
+// set
+func (c *EipClient) checkTarget() error {
+	if c.TargetExpectation().IsZero() {
+		return nil
+	}
+	_, err := c.VerifyTarget()
+	return err
+}
+
+// resetTargetVerification makes the next write verify the controller again,
+// after the session was re-opened, possibly to another device
+func (c *EipClient) resetTargetVerification() {
+	c.target.mu.Lock()
+	c.target.reset()
+	c.target.mu.Unlock()
+}
+
+// reset drops the verification. Must be called with mu held.
+func (g *targetGuard) reset() {
+	g.verified = nil
+	g.generation++
+}
@@ -0,0 +1,63 @@
+package ethernetip
+
+import "testing"
+
+// TestTargetExpectation tests matching serial numbers and catalog numbers
+func TestTargetExpectation(t *testing.T) {
+	identity := &DeviceIdentity{SerialNumber: 0xC0FFEE, ProductName: "1756-L83E/B"}
+	tests := []struct {
+		expect TargetExpectation
+		ok     bool
+	}{
+		{TargetExpectation{}, true},
+		{TargetExpectation{SerialNumber: 0xC0FFEE}, true},
+		{TargetExpectation{SerialNumber: 0xBADF00D}, false},
+		{TargetExpectation{Catalog: "1756-l83e"}, true},
+		{TargetExpectation{Catalog: "1756-L83E/B"}, true},
+		{TargetExpectation{Catalog: "1756-L8"}, false},
+		{TargetExpectation{SerialNumber: 0xC0FFEE, Catalog: "1769-L33ER"}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.expect.check(identity); (err == nil) != tt.ok {
+			t.Errorf("%+v: expected ok=%v, got %v", tt.expect, tt.ok, err)
+		}
+	}
+	if !catalogMatch("1756-L83E", "1756-L83E V1") {
+		t.Error("Expected a product name with a trailing version to match")
+	}
+}
+
+// TestTargetVerification tests that writes are refused until the controller
+// is verified, and that a reconnect requires verifying it again
+func TestTargetVerification(t *testing.T) {
+	c := &EipClient{}
+	if err := c.CheckWrite("Speed"); err != nil {
+		t.Fatalf("Expected writes without an expectation, got %v", err)
+	}
+
+	c.ExpectSerialNumber(0xC0FFEE)
+	if err := c.CheckWrite("Speed"); err == nil {
+		t.Error("Expected a write to be refused when the identity cannot be read")
+	}
+	if err := c.checkCIPRequest([]byte{CIPServiceWriteTag, 0x00}); err == nil {
+		t.Error("Expected a raw write to be refused when the identity cannot be read")
+	}
+	if err := c.checkCIPRequest([]byte{CIPServiceGetAttributesAll, 0x00}); err != nil {
+		t.Errorf("Expected raw reads to pass, got %v", err)
+	}
+
+	c.target.verified = &DeviceIdentity{SerialNumber: 0xC0FFEE}
+	if err := c.CheckWrite("Speed"); err != nil {
+		t.Errorf("Expected a verified controller to be written, got %v", err)
+	}
+	c.recordReconnect(ReconnectEvent{Reason: ReconnectKeepAliveFailure}, nil, nil)
+	if c.target.verified != nil {
+		t.Error("Expected a reconnect to require verifying again")
+	}
+
+	c.target.verified = &DeviceIdentity{SerialNumber: 0xC0FFEE}
+	c.ExpectCatalog("1756-L83E")
+	if c.target.verified != nil || c.TargetExpectation() != (TargetExpectation{SerialNumber: 0xC0FFEE, Catalog: "1756-L83E"}) {
+		t.Error("Expected a new expectation to require verifying again")
+	}
+}
@@ -0,0 +1,205 @@
+package ethernetip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// TemplateMember is a member of a structure template
+type TemplateMember struct {
+	Name string `json:"name"`
+	// Type is the CIP type word: an elementary type code, or a template
+	// instance with the structure bit set for nested structures
+	Type uint16 `json:"type"`
+	// Info is the array size for arrays and the bit number for BOOL members
+	Info   uint16 `json:"info"`
+	Offset uint32 `json:"offset"`
+}
+
+// TypeCode returns the elementary type code or nested template instance
+func (m TemplateMember) TypeCode() uint16 {
+	return m.Type & symbolTypeCodeMask
+}
+
+// IsStructure reports whether the member is a nested structure
+func (m TemplateMember) IsStructure() bool {
+	return m.Type&symbolTypeStructBit != 0
+}
+
+// IsArray reports whether the member is an array
+func (m TemplateMember) IsArray() bool {
+	return m.Type&(symbolTypeDimsMask<<symbolTypeDimsShift) != 0
+}
+
+// StructTemplate describes a structure type (UDT or predefined type) as
+// reported by the controller's Template Object
+type StructTemplate struct {
+	Instance uint16 `json:"instance"`
+	Name     string `json:"name"`
+	// Handle is the structure handle that accompanies the type in tag reads
+	// and writes
+	Handle uint16 `json:"handle"`
+	// Size is the structure size in bytes
+	Size    int              `json:"size"`
+	Members []TemplateMember `json:"members"`
+}
+
+// StringCapacity reports whether the template is a Logix string type (a DINT
+// LEN followed by a SINT DATA array, as in STRING or a user-defined STRING20)
+// and returns the DATA capacity
+func (t *StructTemplate) StringCapacity() (int, bool) {
+	if len(t.Members) != 2 {
+		return 0, false
+	}
+	length, data := t.Members[0], t.Members[1]
+	if !strings.EqualFold(length.Name, "LEN") || length.IsStructure() || length.TypeCode() != CIPTypeDint {
+		return 0, false
+	}
+	if !strings.EqualFold(data.Name, "DATA") || data.IsStructure() || data.TypeCode() != CIPTypeSint || !data.IsArray() {
+		return 0, false
+	}
+	return int(data.Info), true
+}
+
+// Template attribute IDs (Template Object, class 0x6C)
+const (
+	templateAttrHandle         = 1
+	templateAttrMemberCount    = 2
+	templateAttrDefinitionSize = 4 // Definition size in 32-bit words
+	templateAttrStructureSize  = 5 // Structure size in bytes
+)
+
+// GetTemplate reads the definition of the structure template with the given
+// instance ID, e.g. TagInfo.TypeCode() of a structure tag. Templates are
+// cached by the client, since they only change when the program is edited.
+func (c *EipClient) GetTemplate(instance uint16) (*StructTemplate, error) {
+	if cached, ok := c.templates.Load(instance); ok {
+		return cached.(*StructTemplate), nil
+	}
+
+	request := []byte{0x04, 0x00,
+		templateAttrHandle, 0x00, templateAttrMemberCount, 0x00,
+		templateAttrDefinitionSize, 0x00, templateAttrStructureSize, 0x00}
+	resp, err := c.SendCIPMessage(CIPServiceGetAttributeList, classInstancePath(CIPClassTemplate, uint32(instance)), request)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := parseTemplateAttributes(resp.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The definition is read with Read Template, which may span several
+	// replies. Its length is the definition size less the 23-byte header the
+	// controller includes in the word count.
+	length := int(attrs.definitionWords)*4 - 23
+	var definition []byte
+	for len(definition) < length {
+		req := make([]byte, 6)
+		binary.LittleEndian.PutUint32(req, uint32(len(definition)))
+		binary.LittleEndian.PutUint16(req[4:], uint16(length-len(definition)))
+		resp, err := c.SendCIPMessage(CIPServiceReadTag, classInstancePath(CIPClassTemplate, uint32(instance)), req)
+		if err != nil {
+			return nil, err
+		}
+		definition = append(definition, resp.Data...)
+		if resp.GeneralStatus != CIPStatusPartialTransfer {
+			break
+		}
+		if len(resp.Data) == 0 {
+			return nil, NewEipError(ErrInvalidValue, "Read Template made no progress")
+		}
+	}
+
+	template, err := parseTemplateDefinition(definition, attrs.memberCount)
+	if err != nil {
+		return nil, err
+	}
+	template.Instance = instance
+	template.Handle = attrs.handle
+	template.Size = int(attrs.structureSize)
+	c.templates.Store(instance, template)
+	return template, nil
+}
+
+// templateAttributes are the Template Object attributes GetTemplate reads
+type templateAttributes struct {
+	handle          uint16
+	memberCount     int
+	definitionWords uint32
+	structureSize   uint32
+}
+
+// parseTemplateAttributes decodes a Get Attribute List reply for the template
+// attributes: [count UINT] then [id UINT][status UINT][value] per attribute
+func parseTemplateAttributes(data []byte) (templateAttributes, error) {
+	var attrs templateAttributes
+	if len(data) < 2 {
+		return attrs, NewEipError(ErrInvalidValue, "template attribute reply too short")
+	}
+	count := int(binary.LittleEndian.Uint16(data))
+	offset := 2
+	for i := 0; i < count; i++ {
+		if offset+4 > len(data) {
+			return attrs, NewEipError(ErrInvalidValue, "template attribute reply truncated")
+		}
+		id := binary.LittleEndian.Uint16(data[offset:])
+		status := binary.LittleEndian.Uint16(data[offset+2:])
+		offset += 4
+		if status != 0 {
+			return attrs, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("template attribute %d not available", id),
+				map[string]interface{}{"attribute": id, "status": status})
+		}
+		size := 2
+		if id == templateAttrDefinitionSize || id == templateAttrStructureSize {
+			size = 4
+		}
+		if offset+size > len(data) {
+			return attrs, NewEipError(ErrInvalidValue, "template attribute reply truncated")
+		}
+		switch id {
+		case templateAttrHandle:
+			attrs.handle = binary.LittleEndian.Uint16(data[offset:])
+		case templateAttrMemberCount:
+			attrs.memberCount = int(binary.LittleEndian.Uint16(data[offset:]))
+		case templateAttrDefinitionSize:
+			attrs.definitionWords = binary.LittleEndian.Uint32(data[offset:])
+		case templateAttrStructureSize:
+			attrs.structureSize = binary.LittleEndian.Uint32(data[offset:])
+		}
+		offset += size
+	}
+	return attrs, nil
+}
+
+// parseTemplateDefinition decodes a Read Template definition: memberCount
+// 8-byte member entries ([info UINT][type UINT][offset UDINT]) followed by the
+// NUL-terminated template name ("NAME;n...") and member names
+func parseTemplateDefinition(data []byte, memberCount int) (*StructTemplate, error) {
+	if len(data) < memberCount*8 {
+		return nil, NewEipErrorWithDetails(ErrInvalidValue, "template definition truncated",
+			map[string]interface{}{"length": len(data), "members": memberCount})
+	}
+	template := &StructTemplate{Members: make([]TemplateMember, memberCount)}
+	for i := range template.Members {
+		entry := data[i*8:]
+		template.Members[i] = TemplateMember{
+			Info:   binary.LittleEndian.Uint16(entry),
+			Type:   binary.LittleEndian.Uint16(entry[2:]),
+			Offset: binary.LittleEndian.Uint32(entry[4:]),
+		}
+	}
+
+	names := bytes.Split(data[memberCount*8:], []byte{0})
+	if len(names) < memberCount+1 {
+		return nil, NewEipError(ErrInvalidValue, "template definition is missing member names")
+	}
+	name, _, _ := strings.Cut(string(names[0]), ";")
+	template.Name = name
+	for i := range template.Members {
+		template.Members[i].Name = string(names[i+1])
+	}
+	return template, nil
+}
@@ -0,0 +1,89 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// templateDefinition encodes a Read Template definition
+func templateDefinition(name string, members []TemplateMember) []byte {
+	var b []byte
+	for _, m := range members {
+		b = binary.LittleEndian.AppendUint16(b, m.Info)
+		b = binary.LittleEndian.AppendUint16(b, m.Type)
+		b = binary.LittleEndian.AppendUint32(b, m.Offset)
+	}
+	b = append(append(b, name...), 0)
+	for _, m := range members {
+		b = append(append(b, m.Name...), 0)
+	}
+	return b
+}
+
+// TestParseTemplateDefinition tests decoding of a string type template
+func TestParseTemplateDefinition(t *testing.T) {
+	data := templateDefinition("STRING20;n", []TemplateMember{
+		{Name: "LEN", Type: CIPTypeDint},
+		{Name: "DATA", Type: 0x2000 | CIPTypeSint, Info: 20, Offset: 4},
+	})
+	template, err := parseTemplateDefinition(data, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if template.Name != "STRING20" {
+		t.Errorf("Expected name STRING20, got %q", template.Name)
+	}
+	if template.Members[1].Name != "DATA" || template.Members[1].Offset != 4 || !template.Members[1].IsArray() {
+		t.Errorf("Unexpected DATA member: %+v", template.Members[1])
+	}
+	if capacity, ok := template.StringCapacity(); !ok || capacity != 20 {
+		t.Errorf("Expected a 20-byte string type, got %d, %v", capacity, ok)
+	}
+
+	if _, err := parseTemplateDefinition(data[:10], 2); err == nil {
+		t.Error("Expected error for truncated member entries")
+	}
+	if _, err := parseTemplateDefinition(data[:16], 2); err == nil {
+		t.Error("Expected error for missing member names")
+	}
+}
+
+// TestStringCapacity tests that only LEN/DATA structures are string types
+func TestStringCapacity(t *testing.T) {
+	udt := &StructTemplate{Members: []TemplateMember{
+		{Name: "LEN", Type: CIPTypeDint},
+		{Name: "DATA", Type: CIPTypeSint},
+	}}
+	if _, ok := udt.StringCapacity(); ok {
+		t.Error("Expected a scalar DATA member not to be a string type")
+	}
+	udt.Members = append(udt.Members, TemplateMember{Name: "Extra", Type: CIPTypeDint})
+	if _, ok := udt.StringCapacity(); ok {
+		t.Error("Expected a three-member structure not to be a string type")
+	}
+}
+
+// TestParseTemplateAttributes tests decoding of the template attribute reply
+func TestParseTemplateAttributes(t *testing.T) {
+	data := []byte{0x04, 0x00,
+		0x01, 0x00, 0x00, 0x00, 0x34, 0x12, // handle
+		0x02, 0x00, 0x00, 0x00, 0x02, 0x00, // member count
+		0x04, 0x00, 0x00, 0x00, 0x0E, 0x00, 0x00, 0x00, // definition words
+		0x05, 0x00, 0x00, 0x00, 0x18, 0x00, 0x00, 0x00, // structure size
+	}
+	attrs, err := parseTemplateAttributes(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if attrs.handle != 0x1234 || attrs.memberCount != 2 || attrs.definitionWords != 14 || attrs.structureSize != 24 {
+		t.Errorf("Unexpected attributes: %+v", attrs)
+	}
+
+	data[4] = 0x05 // attribute error
+	if _, err := parseTemplateAttributes(data); err == nil {
+		t.Error("Expected error for a failed attribute")
+	}
+	if _, err := parseTemplateAttributes(data[:10]); err == nil {
+		t.Error("Expected error for a truncated reply")
+	}
+}
@@ -0,0 +1,118 @@
+package ethernetip
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/codec"
+)
+
+// Logix time types (DT, LDT and TIME) are LINTs on the wire; see the codec
+// package for their units. ReadValue returns time.Time for DT and LDT and
+// time.Duration for TIME, and WriteValue accepts the same types.
+
+// ReadDateTime reads a DT tag (microseconds since the Unix epoch) as a UTC time
+func (c *EipClient) ReadDateTime(tagName string) (time.Time, error) {
+	us, err := c.ReadLint(tagName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return codec.DateTime(us), nil
+}
+
+// WriteDateTime writes a DT tag, truncating t to the microsecond
+func (c *EipClient) WriteDateTime(tagName string, t time.Time) error {
+	return c.WriteLint(tagName, codec.DateTimeValue(t))
+}
+
+// ReadLongDateTime reads an LDT tag (nanoseconds since the Unix epoch) as a UTC time
+func (c *EipClient) ReadLongDateTime(tagName string) (time.Time, error) {
+	ns, err := c.ReadLint(tagName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return codec.LongDateTime(ns), nil
+}
+
+// WriteLongDateTime writes an LDT tag
+func (c *EipClient) WriteLongDateTime(tagName string, t time.Time) error {
+	return c.WriteLint(tagName, codec.LongDateTimeValue(t))
+}
+
+// ReadDuration reads a TIME tag (microseconds) as a duration
+func (c *EipClient) ReadDuration(tagName string) (time.Duration, error) {
+	us, err := c.ReadLint(tagName)
+	if err != nil {
+		return 0, err
+	}
+	return codec.Duration(us), nil
+}
+
+// WriteDuration writes a TIME tag, truncating d to the microsecond
+func (c *EipClient) WriteDuration(tagName string, d time.Duration) error {
+	return c.WriteLint(tagName, codec.DurationValue(d))
+}
+
+// temporalLint converts a DT, LDT or TIME value to its LINT encoding. Raw
+// LINT values (any Go integer) are passed through.
+func temporalLint(dataType PlcDataType, v interface{}) (int64, error) {
+	switch value := v.(type) {
+	case time.Time:
+		switch dataType {
+		case Dt:
+			return codec.DateTimeValue(value), nil
+		case Ldt:
+			return codec.LongDateTimeValue(value), nil
+		}
+	case time.Duration:
+		if dataType == Time {
+			return codec.DurationValue(value), nil
+		}
+	case int64:
+		return value, nil
+	default:
+		if f, ok := numericValue(v); ok && f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return int64(f), nil
+		}
+	}
+	return 0, NewEipErrorWithDetails(ErrInvalidTagValue, fmt.Sprintf("invalid %s value of type %T", dataType, v),
+		map[string]interface{}{"type": dataType.String()})
+}
+
+// temporalValue converts the LINT encoding of a DT, LDT or TIME value to the
+// Go type ReadValue returns
+func temporalValue(dataType PlcDataType, v int64) interface{} {
+	switch dataType {
+	case Dt:
+		return codec.DateTime(v)
+	case Ldt:
+		return codec.LongDateTime(v)
+	default: // Time
+		return codec.Duration(v)
+	}
+}
+
+// coerceTemporal converts a JSON-decoded DT, LDT or TIME value: an RFC 3339
+// timestamp or duration string ("1m30s"), or the raw LINT as a number
+func coerceTemporal(dataType PlcDataType, v interface{}) (interface{}, error) {
+	if s, ok := v.(string); ok {
+		if dataType == Time {
+			return time.ParseDuration(s)
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	if _, ok := v.(json.Number); ok {
+		lint, err := signedInteger(v, 64)
+		if err != nil {
+			return nil, err
+		}
+		return temporalValue(dataType, lint), nil
+	}
+	f, ok := v.(float64)
+	if !ok || f != float64(int64(f)) {
+		return nil, fmt.Errorf("expected string or integer, got %v", v)
+	}
+	return temporalValue(dataType, int64(f)), nil
+}
@@ -0,0 +1,71 @@
+package ethernetip
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTemporalElements tests encoding and decoding of DT, LDT and TIME values
+func TestTemporalElements(t *testing.T) {
+	when := time.Date(2024, 6, 30, 23, 59, 59, 250000000, time.UTC)
+	cases := []struct {
+		dataType PlcDataType
+		value    interface{}
+	}{
+		{Dt, when},
+		{Ldt, when},
+		{Time, 90 * time.Second},
+	}
+	for _, tc := range cases {
+		data, err := encodeElement(tc.dataType, tc.value)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.dataType, err)
+		}
+		if len(data) != 8 {
+			t.Errorf("%s: expected an 8-byte LINT, got %d bytes", tc.dataType, len(data))
+		}
+		got := decodeElement(tc.dataType, data)
+		if got != tc.value {
+			t.Errorf("%s: expected %v, got %v", tc.dataType, tc.value, got)
+		}
+	}
+
+	// Raw LINT values are accepted; mismatched Go types are not
+	if data, err := encodeElement(Time, int64(1500000)); err != nil || decodeElement(Time, data) != 1500*time.Millisecond {
+		t.Errorf("Expected a raw TIME value of 1.5s, got %v", err)
+	}
+	if _, err := encodeElement(Time, when); err == nil {
+		t.Error("Expected error writing a time.Time to a TIME tag")
+	}
+	if _, err := encodeElement(Dt, "2024-06-30"); err == nil {
+		t.Error("Expected error writing a string to a DT tag")
+	}
+}
+
+// TestTemporalJSON tests the JSON forms accepted for time types
+func TestTemporalJSON(t *testing.T) {
+	value, err := NewPlcValue(Ldt, "2024-06-30T23:59:59.000000001Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value.Value.(time.Time).Nanosecond() != 1 {
+		t.Errorf("Expected nanoseconds to be kept, got %v", value.Value)
+	}
+	value, err = NewPlcValue(Time, "1m30s")
+	if err != nil || value.Value != 90*time.Second {
+		t.Errorf("Expected 1m30s, got %v, %v", value, err)
+	}
+	value, err = NewPlcValue(Dt, float64(1000000))
+	if err != nil || !value.Value.(time.Time).Equal(time.Unix(1, 0)) {
+		t.Errorf("Expected one second after the epoch, got %v, %v", value, err)
+	}
+	if _, err := NewPlcValue(Time, true); err == nil {
+		t.Error("Expected error for a bool TIME value")
+	}
+
+	for name, want := range map[string]PlcDataType{"DT": Dt, "date_and_time": Dt, "LDT": Ldt, "TIME": Time} {
+		if got, err := ParsePlcDataType(name); err != nil || got != want {
+			t.Errorf("ParsePlcDataType(%q) = %v, %v", name, got, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package ethernetip
+
+/*
+#include <stdlib.h>
+
+// Request tracing
+extern int eip_set_request_id(int client_id, unsigned long long request_id);
+extern unsigned long long eip_get_request_id(void);
+*/
+import "C"
+import (
+	"log/slog"
+	"runtime"
+)
+
+// LastRequestID returns the most recently assigned request ID, or zero if no
+// traced request has been issued
+func (c *EipClient) LastRequestID() uint64 {
+	return c.lastRequestID.Load()
+}
+
+// traced assigns the next request ID and runs fn with it set on the native
+// driver, which sends it as the encapsulation sender context. The ID is
+// recorded on op (if non-nil) and added to the details of a failed request.
+//
+// The driver keeps the ID per OS thread, so fn runs locked to its thread and
+// the ID is cleared afterwards: concurrent operations carry their own IDs and
+// later untraced requests from the thread carry none. Calls nested in a
+// traced operation, such as the CIP requests of a queued read issued through
+// ReadValueContext, find the ID already set on the thread and keep it, so
+// the packets, op and the error all carry the outer operation's ID.
+func (c *EipClient) traced(op *Operation, fn func() error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if id := uint64(C.eip_get_request_id()); id != 0 {
+		if op != nil {
+			op.RequestID = id
+		}
+		return withRequestID(fn(), id)
+	}
+
+	id := c.lastRequestID.Add(1)
+	err := func() error {
+		C.eip_set_request_id(C.int(c.id()), C.ulonglong(id))
+		defer C.eip_set_request_id(C.int(c.session.Load()), 0)
+		return fn()
+	}()
+
+	if op != nil {
+		op.RequestID = id
+	}
+	if err != nil {
+		if op != nil {
+			c.logf(slog.LevelDebug, "❌", "Request %d: %s of tag '%s' failed: %v", id, op.Kind, op.TagName, err)
+		} else {
+			c.logf(slog.LevelDebug, "❌", "Request %d failed: %v", id, err)
+		}
+	}
+	return withRequestID(err, id)
+}
+
+// withRequestID adds the request ID to the details of an EipError. Other
+// errors are returned unchanged.
+func withRequestID(err error, id uint64) error {
+	eipErr, ok := err.(*EipError)
+	if !ok {
+		return err
+	}
+	if eipErr.Details == nil {
+		eipErr.Details = make(map[string]interface{})
+	}
+	eipErr.Details["request_id"] = id
+	return eipErr
+}
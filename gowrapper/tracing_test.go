@@ -0,0 +1,110 @@
+package ethernetip
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestTracedAssignsIncreasingIDs tests that request IDs increase monotonically
+// and are recorded on the operation and in error details
+func TestTracedAssignsIncreasingIDs(t *testing.T) {
+	client := &EipClient{}
+	client.session.Store(-1)
+
+	first := &Operation{Kind: OperationRead, TagName: "A"}
+	if err := client.traced(first, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	second := &Operation{Kind: OperationWrite, TagName: "B"}
+	err := client.traced(second, func() error { return NewEipError(ErrInvalidTagValue, "write failed") })
+
+	if first.RequestID == 0 || second.RequestID != first.RequestID+1 {
+		t.Errorf("expected consecutive request IDs, got %d and %d", first.RequestID, second.RequestID)
+	}
+	if client.LastRequestID() != second.RequestID {
+		t.Errorf("expected last request ID %d, got %d", second.RequestID, client.LastRequestID())
+	}
+
+	eipErr, ok := err.(*EipError)
+	if !ok {
+		t.Fatalf("expected *EipError, got %T", err)
+	}
+	if eipErr.Details["request_id"] != second.RequestID {
+		t.Errorf("expected request_id %d in details, got %v", second.RequestID, eipErr.Details["request_id"])
+	}
+}
+
+// TestTracedNested tests that a traced call inside a traced operation keeps
+// the operation's request ID rather than assigning or clearing its own
+func TestTracedNested(t *testing.T) {
+	client := &EipClient{}
+	client.session.Store(-1)
+
+	outer := &Operation{Kind: OperationRead, TagName: "A"}
+	var inner, after error
+	err := client.traced(outer, func() error {
+		inner = client.traced(nil, func() error { return NewEipError(ErrInvalidOperation, "inner failed") })
+		after = client.traced(nil, func() error { return NewEipError(ErrInvalidOperation, "still inside") })
+		return after
+	})
+
+	if client.LastRequestID() != outer.RequestID {
+		t.Errorf("expected one request ID for the nested calls, last is %d and outer %d", client.LastRequestID(), outer.RequestID)
+	}
+	for _, got := range []error{inner, after, err} {
+		if eipErr, ok := got.(*EipError); !ok || eipErr.Details["request_id"] != outer.RequestID {
+			t.Errorf("expected request_id %d on every error, got %v", outer.RequestID, got)
+		}
+	}
+
+	// The ID was cleared when the outer operation ended
+	next := &Operation{Kind: OperationRead, TagName: "B"}
+	client.traced(next, func() error { return nil })
+	if next.RequestID != outer.RequestID+1 {
+		t.Errorf("expected a new request ID after the operation, got %d", next.RequestID)
+	}
+}
+
+// TestTracedConcurrent tests that traced operations on one client are not
+// serialized and each keeps its own request ID
+func TestTracedConcurrent(t *testing.T) {
+	client := &EipClient{}
+	client.session.Store(-1)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	first := &Operation{Kind: OperationRead, TagName: "A"}
+	done := make(chan error, 1)
+	go func() {
+		done <- client.traced(first, func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	// The second operation completes while the first is still running
+	second := &Operation{Kind: OperationRead, TagName: "B"}
+	if err := client.traced(second, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if first.RequestID == second.RequestID || first.RequestID == 0 || second.RequestID == 0 {
+		t.Errorf("expected distinct request IDs, got %d and %d", first.RequestID, second.RequestID)
+	}
+}
+
+// TestWithRequestIDPlainError tests that non-EipError errors are returned unchanged
+func TestWithRequestIDPlainError(t *testing.T) {
+	plain := errors.New("plain")
+	if err := withRequestID(plain, 7); err != plain {
+		t.Errorf("expected plain error unchanged, got %v", err)
+	}
+	if err := withRequestID(nil, 7); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
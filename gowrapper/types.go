@@ -0,0 +1,142 @@
+package ethernetip
+
+import "github.com/sergiogallegos/rust-ethernet-ip/gowrapper/types"
+
+// The value and error types are defined in the types package, which does not
+// need the native library. They are re-exported here so that code written
+// against this package keeps compiling unchanged.
+
+// PlcDataType represents different PLC data types
+type PlcDataType = types.PlcDataType
+
+const (
+	Bool   = types.Bool
+	Sint   = types.Sint
+	Int    = types.Int
+	Dint   = types.Dint
+	Lint   = types.Lint
+	Usint  = types.Usint
+	Uint   = types.Uint
+	Udint  = types.Udint
+	Ulint  = types.Ulint
+	Real   = types.Real
+	Lreal  = types.Lreal
+	String = types.String
+	Udt    = types.Udt
+	Dt     = types.Dt
+	Ldt    = types.Ldt
+	Time   = types.Time
+)
+
+// TagMetadata represents metadata for a PLC tag
+type TagMetadata = types.TagMetadata
+
+// Tag scopes reported in TagMetadata.Scope
+const (
+	ScopeController = types.ScopeController
+	ScopeProgram    = types.ScopeProgram
+	ScopeLocal      = types.ScopeLocal
+)
+
+// ExternalAccess is the External Access setting of a tag
+type ExternalAccess = types.ExternalAccess
+
+const (
+	AccessReadWrite = types.AccessReadWrite
+	AccessReadOnly  = types.AccessReadOnly
+	AccessNone      = types.AccessNone
+)
+
+// BatchOperation represents a single operation in a batch
+type BatchOperation = types.BatchOperation
+
+// BatchOperationResult represents the result of a batch operation
+type BatchOperationResult = types.BatchOperationResult
+
+// UdtValue represents a UDT (User Defined Type) value
+type UdtValue = types.UdtValue
+
+// PlcValue represents a value that can be read from or written to the PLC
+type PlcValue = types.PlcValue
+
+// PlcValueResult is used for async operations
+type PlcValueResult = types.PlcValueResult
+
+// Quality describes how trustworthy a polled tag value is
+type Quality = types.Quality
+
+const (
+	QualityUncertain = types.QualityUncertain
+	QualityGood      = types.QualityGood
+	QualityStale     = types.QualityStale
+)
+
+// EipError represents errors from the EtherNet/IP library
+type EipError = types.EipError
+
+// Error code constants
+const (
+	ErrConnectionFailed        = types.ErrConnectionFailed
+	ErrTagNotFound             = types.ErrTagNotFound
+	ErrInvalidDataType         = types.ErrInvalidDataType
+	ErrTimeout                 = types.ErrTimeout
+	ErrBatchOperationFailed    = types.ErrBatchOperationFailed
+	ErrInvalidOperation        = types.ErrInvalidOperation
+	ErrInvalidValue            = types.ErrInvalidValue
+	ErrInvalidTagName          = types.ErrInvalidTagName
+	ErrInvalidTagType          = types.ErrInvalidTagType
+	ErrInvalidTagValue         = types.ErrInvalidTagValue
+	ErrInvalidTagAddress       = types.ErrInvalidTagAddress
+	ErrInvalidTagLength        = types.ErrInvalidTagLength
+	ErrInvalidTagOffset        = types.ErrInvalidTagOffset
+	ErrInvalidTagDimension     = types.ErrInvalidTagDimension
+	ErrInvalidTagScope         = types.ErrInvalidTagScope
+	ErrInvalidTagAccess        = types.ErrInvalidTagAccess
+	ErrInvalidTagStatus        = types.ErrInvalidTagStatus
+	ErrInvalidTagQuality       = types.ErrInvalidTagQuality
+	ErrInvalidTagTimestamp     = types.ErrInvalidTagTimestamp
+	ErrInvalidTagMetadata      = types.ErrInvalidTagMetadata
+	ErrInvalidTagSubscription  = types.ErrInvalidTagSubscription
+	ErrInvalidTagBatch         = types.ErrInvalidTagBatch
+	ErrInvalidTagConfig        = types.ErrInvalidTagConfig
+	ErrInvalidTagHealth        = types.ErrInvalidTagHealth
+	ErrInvalidTagKeepAlive     = types.ErrInvalidTagKeepAlive
+	ErrInvalidTagRetry         = types.ErrInvalidTagRetry
+	ErrInvalidTagTimeout       = types.ErrInvalidTagTimeout
+	ErrInvalidTagInterval      = types.ErrInvalidTagInterval
+	ErrInvalidTagCondition     = types.ErrInvalidTagCondition
+	ErrInvalidTagPeriod        = types.ErrInvalidTagPeriod
+	ErrInvalidTagParallel      = types.ErrInvalidTagParallel
+	ErrConcurrentModification  = types.ErrConcurrentModification
+	ErrWriteVerificationFailed = types.ErrWriteVerificationFailed
+	ErrOverloaded              = types.ErrOverloaded
+	ErrIndexOutOfRange         = types.ErrIndexOutOfRange
+)
+
+// NewEipError creates a new EipError with the given code and message
+func NewEipError(code int, message string) *EipError {
+	return types.NewEipError(code, message)
+}
+
+// NewEipErrorWithDetails creates a new EipError with additional details
+func NewEipErrorWithDetails(code int, message string, details map[string]interface{}) *EipError {
+	return types.NewEipErrorWithDetails(code, message, details)
+}
+
+// ParsePlcDataType converts a type name to a PlcDataType. Names are matched
+// case-insensitively against the Logix names ("DINT"), common synonyms
+// ("int32", "float", "Boolean") and aliases added with RegisterDataTypeAlias.
+func ParsePlcDataType(name string) (PlcDataType, error) {
+	return types.ParsePlcDataType(name)
+}
+
+// RegisterDataTypeAlias adds a synonym accepted by ParsePlcDataType
+func RegisterDataTypeAlias(alias string, t PlcDataType) error {
+	return types.RegisterDataTypeAlias(alias, t)
+}
+
+// LoadDataTypeAliases registers aliases from configuration, mapping each alias
+// to the name of an existing type (e.g. {"analog": "REAL"})
+func LoadDataTypeAliases(aliases map[string]string) error {
+	return types.LoadDataTypeAliases(aliases)
+}
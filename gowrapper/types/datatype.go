@@ -0,0 +1,139 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// dataTypeNames are the canonical (Logix) names of the data types
+var dataTypeNames = map[PlcDataType]string{
+	Bool:   "BOOL",
+	Sint:   "SINT",
+	Int:    "INT",
+	Dint:   "DINT",
+	Lint:   "LINT",
+	Usint:  "USINT",
+	Uint:   "UINT",
+	Udint:  "UDINT",
+	Ulint:  "ULINT",
+	Real:   "REAL",
+	Lreal:  "LREAL",
+	String: "STRING",
+	Udt:    "UDT",
+	Dt:     "DT",
+	Ldt:    "LDT",
+	Time:   "TIME",
+}
+
+// dataTypeAliases maps normalized names (see normalizeTypeName) to data types
+var (
+	dataTypeAliasMu sync.RWMutex
+	dataTypeAliases = map[string]PlcDataType{
+		"bool": Bool, "boolean": Bool, "bit": Bool,
+		"sint": Sint, "int8": Sint,
+		"int": Int, "int16": Int, "short": Int,
+		"dint": Dint, "int32": Dint,
+		"lint": Lint, "int64": Lint,
+		"usint": Usint, "uint8": Usint, "byte": Usint,
+		"uint": Uint, "uint16": Uint, "word": Uint,
+		"udint": Udint, "uint32": Udint, "dword": Udint,
+		"ulint": Ulint, "uint64": Ulint, "lword": Ulint,
+		"real": Real, "float": Real, "float32": Real, "single": Real,
+		"lreal": Lreal, "double": Lreal, "float64": Lreal,
+		"string": String, "str": String, "text": String,
+		"udt": Udt, "struct": Udt, "structure": Udt,
+		"dt": Dt, "dateandtime": Dt, "datetime": Dt,
+		"ldt": Ldt, "longdateandtime": Ldt,
+		"time": Time, "duration": Time,
+	}
+)
+
+// String returns the canonical Logix name of the data type, e.g. "DINT"
+func (t PlcDataType) String() string {
+	if name, ok := dataTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("PlcDataType(%d)", int(t))
+}
+
+// UnmarshalJSON accepts either the numeric value or any recognised name
+func (t *PlcDataType) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		if _, ok := dataTypeNames[PlcDataType(n)]; !ok {
+			return NewEipError(ErrInvalidDataType, fmt.Sprintf("unknown data type %d", n))
+		}
+		*t = PlcDataType(n)
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return NewEipError(ErrInvalidDataType, "data type must be a number or a name")
+	}
+	parsed, err := ParsePlcDataType(name)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// normalizeTypeName lowercases a type name and drops spaces, underscores and
+// hyphens, so "Float 32", "FLOAT_32" and "float32" compare equal
+func normalizeTypeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if r == ' ' || r == '_' || r == '-' {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ParsePlcDataType converts a type name to a PlcDataType. Names are matched
+// case-insensitively against the Logix names ("DINT"), common synonyms
+// ("int32", "float", "Boolean") and aliases added with RegisterDataTypeAlias.
+func ParsePlcDataType(name string) (PlcDataType, error) {
+	dataTypeAliasMu.RLock()
+	t, ok := dataTypeAliases[normalizeTypeName(name)]
+	dataTypeAliasMu.RUnlock()
+	if !ok {
+		return 0, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("unsupported PLC data type: %s", name),
+			map[string]interface{}{"type": name})
+	}
+	return t, nil
+}
+
+// RegisterDataTypeAlias adds a synonym accepted by ParsePlcDataType
+func RegisterDataTypeAlias(alias string, t PlcDataType) error {
+	if _, ok := dataTypeNames[t]; !ok {
+		return NewEipError(ErrInvalidDataType, fmt.Sprintf("unknown data type %d", int(t)))
+	}
+	key := normalizeTypeName(alias)
+	if key == "" {
+		return NewEipError(ErrInvalidDataType, "alias cannot be empty")
+	}
+	dataTypeAliasMu.Lock()
+	dataTypeAliases[key] = t
+	dataTypeAliasMu.Unlock()
+	return nil
+}
+
+// LoadDataTypeAliases registers aliases from configuration, mapping each alias
+// to the name of an existing type (e.g. {"analog": "REAL"}). It stops at the
+// first alias whose target is not recognised.
+func LoadDataTypeAliases(aliases map[string]string) error {
+	for alias, target := range aliases {
+		t, err := ParsePlcDataType(target)
+		if err != nil {
+			return fmt.Errorf("alias %q: %w", alias, err)
+		}
+		if err := RegisterDataTypeAlias(alias, t); err != nil {
+			return fmt.Errorf("alias %q: %w", alias, err)
+		}
+	}
+	return nil
+}
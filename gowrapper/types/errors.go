@@ -0,0 +1,103 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EipError represents errors from the EtherNet/IP library
+type EipError struct {
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+	Time    time.Time              `json:"time"`
+}
+
+// Error code constants
+const (
+	ErrConnectionFailed = iota + 1
+	ErrTagNotFound
+	ErrInvalidDataType
+	ErrTimeout
+	ErrBatchOperationFailed
+	ErrInvalidOperation
+	ErrInvalidValue
+	ErrInvalidTagName
+	ErrInvalidTagType
+	ErrInvalidTagValue
+	ErrInvalidTagAddress
+	ErrInvalidTagLength
+	ErrInvalidTagOffset
+	ErrInvalidTagDimension
+	ErrInvalidTagScope
+	ErrInvalidTagAccess
+	ErrInvalidTagStatus
+	ErrInvalidTagQuality
+	ErrInvalidTagTimestamp
+	ErrInvalidTagMetadata
+	ErrInvalidTagSubscription
+	ErrInvalidTagBatch
+	ErrInvalidTagConfig
+	ErrInvalidTagHealth
+	ErrInvalidTagKeepAlive
+	ErrInvalidTagRetry
+	ErrInvalidTagTimeout
+	ErrInvalidTagInterval
+	ErrInvalidTagCondition
+	ErrInvalidTagPeriod
+	ErrInvalidTagParallel
+	ErrConcurrentModification
+	ErrWriteVerificationFailed
+	ErrOverloaded
+	ErrIndexOutOfRange
+)
+
+func (e *EipError) Error() string {
+	details, _ := json.Marshal(e.Details)
+	return fmt.Sprintf("EIP Error %d: %s (Details: %s) at %s", e.Code, e.Message, string(details), e.Time.Format(time.RFC3339))
+}
+
+// NewEipError creates a new EipError with the given code and message
+func NewEipError(code int, message string) *EipError {
+	return &EipError{
+		Code:    code,
+		Message: message,
+		Time:    time.Now(),
+	}
+}
+
+// NewEipErrorWithDetails creates a new EipError with additional details
+func NewEipErrorWithDetails(code int, message string, details map[string]interface{}) *EipError {
+	return &EipError{
+		Code:    code,
+		Message: message,
+		Details: details,
+		Time:    time.Now(),
+	}
+}
+
+// IsConnectionError returns true if the error is related to connection issues
+func (e *EipError) IsConnectionError() bool {
+	return e.Code == ErrConnectionFailed
+}
+
+// IsTagError returns true if the error is related to tag operations
+func (e *EipError) IsTagError() bool {
+	return e.Code >= ErrTagNotFound && e.Code <= ErrInvalidTagParallel
+}
+
+// IsTimeoutError returns true if the error is a timeout
+func (e *EipError) IsTimeoutError() bool {
+	return e.Code == ErrTimeout
+}
+
+// IsBatchError returns true if the error is related to batch operations
+func (e *EipError) IsBatchError() bool {
+	return e.Code == ErrBatchOperationFailed
+}
+
+// IsValidationError returns true if the error is related to validation
+func (e *EipError) IsValidationError() bool {
+	return e.Code >= ErrInvalidOperation && e.Code <= ErrInvalidTagParallel
+}
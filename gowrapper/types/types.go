@@ -0,0 +1,163 @@
+// Package types holds the value and error types shared by the EtherNet/IP
+// client and the packages built on it: PlcDataType, PlcValue, TagMetadata,
+// the batch operation records, Quality and EipError. It has no dependency on
+// the native library, so code that only handles tag values (a gateway
+// client, a historian, a test) can use it without cgo. The ethernetip
+// package re-exports every name here, so existing code is unaffected.
+package types
+
+import "encoding/json"
+
+// PlcDataType represents different PLC data types
+type PlcDataType int
+
+const (
+	Bool PlcDataType = iota
+	Sint
+	Int
+	Dint
+	Lint
+	Usint
+	Uint
+	Udint
+	Ulint
+	Real
+	Lreal
+	String
+	Udt
+	Dt   // Logix DT: time.Time, microsecond resolution
+	Ldt  // Logix LDT: time.Time, nanosecond resolution
+	Time // Logix TIME: time.Duration, microsecond resolution
+)
+
+// TagMetadata represents metadata for a PLC tag
+type TagMetadata struct {
+	DataType       int            `json:"data_type"`                 // CIP data type code
+	Scope          int            `json:"scope"`                     // Tag scope: ScopeController, ScopeProgram or ScopeLocal
+	ArrayDimension int            `json:"array_dimension"`           // Number of array dimensions
+	ArraySize      int            `json:"array_size"`                // Total array size
+	TypeName       string         `json:"type_name,omitempty"`       // Atomic type name ("DINT") or structure type name
+	Template       string         `json:"template,omitempty"`        // Structure (UDT) type name, empty for atomic tags
+	ElementSize    int            `json:"element_size,omitempty"`    // Size of one element in bytes
+	Dimensions     []int          `json:"dimensions,omitempty"`      // Size of each array dimension, empty for scalars
+	Program        string         `json:"program,omitempty"`         // Program of a program-scoped tag
+	ExternalAccess ExternalAccess `json:"external_access,omitempty"` // Access rights of clients such as this one
+}
+
+// Tag scopes reported in TagMetadata.Scope
+const (
+	ScopeController = iota
+	ScopeProgram
+	ScopeLocal
+)
+
+// ExternalAccess is the External Access setting of a tag, which controls what
+// clients outside the controller may do with it
+type ExternalAccess string
+
+const (
+	AccessReadWrite ExternalAccess = "Read/Write"
+	AccessReadOnly  ExternalAccess = "Read Only"
+	AccessNone      ExternalAccess = "None"
+)
+
+// CanRead reports whether the tag may be read. An unknown setting is assumed
+// to allow it.
+func (a ExternalAccess) CanRead() bool {
+	return a != AccessNone
+}
+
+// CanWrite reports whether the tag may be written. An unknown setting is
+// assumed to allow it.
+func (a ExternalAccess) CanWrite() bool {
+	return a != AccessNone && a != AccessReadOnly
+}
+
+// BatchOperation represents a single operation in a batch
+type BatchOperation struct {
+	TagName  string      `json:"tag_name"`
+	IsWrite  bool        `json:"is_write"`
+	DataType PlcDataType `json:"data_type"`
+	Value    interface{} `json:"value,omitempty"`
+}
+
+// BatchOperationResult represents the result of a batch operation
+type BatchOperationResult struct {
+	TagName         string      `json:"tag_name"`
+	IsWrite         bool        `json:"is_write"`
+	Success         bool        `json:"success"`
+	ExecutionTimeUs int64       `json:"execution_time_us"`
+	ErrorCode       int         `json:"error_code"`
+	ErrorMessage    string      `json:"error_message,omitempty"`
+	DataType        PlcDataType `json:"data_type,omitempty"`
+	Value           interface{} `json:"value,omitempty"`
+}
+
+// UdtValue represents a UDT (User Defined Type) value
+type UdtValue struct {
+	Members map[string]interface{} `json:"members"`
+}
+
+// PlcValue represents a value that can be read from or written to the PLC
+type PlcValue struct {
+	Type  PlcDataType
+	Value interface{}
+}
+
+// PlcValueResult is used for async operations
+// Value is the tag value, Err is any error encountered
+// Type is the PlcDataType
+// Tag is the tag name
+type PlcValueResult struct {
+	Tag   string
+	Type  PlcDataType
+	Value interface{}
+	Err   error
+}
+
+// Quality describes how trustworthy a polled tag value is
+type Quality int
+
+const (
+	// QualityUncertain means the tag has not been read successfully yet
+	QualityUncertain Quality = iota
+	// QualityGood means the value was refreshed within the expected interval
+	QualityGood
+	// QualityStale means the tag has not been refreshed for too many intervals
+	// and the value is the last one known
+	QualityStale
+)
+
+// String returns the name of the quality
+func (q Quality) String() string {
+	switch q {
+	case QualityGood:
+		return "good"
+	case QualityStale:
+		return "stale"
+	default:
+		return "uncertain"
+	}
+}
+
+// MarshalJSON encodes the quality as its name
+func (q Quality) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.String())
+}
+
+// UnmarshalJSON decodes a quality from its name
+func (q *Quality) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	switch name {
+	case "good":
+		*q = QualityGood
+	case "stale":
+		*q = QualityStale
+	default:
+		*q = QualityUncertain
+	}
+	return nil
+}
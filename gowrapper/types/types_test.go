@@ -0,0 +1,49 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestEipErrorClasses tests the error classification helpers
+func TestEipErrorClasses(t *testing.T) {
+	if !NewEipError(ErrConnectionFailed, "refused").IsConnectionError() {
+		t.Error("Expected a connection error")
+	}
+	if err := NewEipError(ErrTagNotFound, "missing"); !err.IsTagError() || err.IsValidationError() {
+		t.Error("Expected a tag error that is not a validation error")
+	}
+	if err := NewEipError(ErrInvalidTagName, "bad"); !err.IsValidationError() {
+		t.Error("Expected a validation error")
+	}
+
+	var wrapped error = NewEipErrorWithDetails(ErrTimeout, "slow", map[string]interface{}{"tag_name": "Speed"})
+	var eipErr *EipError
+	if !errors.As(wrapped, &eipErr) || !eipErr.IsTimeoutError() || eipErr.Details["tag_name"] != "Speed" {
+		t.Errorf("Unexpected error %v", wrapped)
+	}
+}
+
+// TestQualityJSON tests that qualities are encoded by name
+func TestQualityJSON(t *testing.T) {
+	data, err := json.Marshal(QualityStale)
+	if err != nil || string(data) != `"stale"` {
+		t.Fatalf("Unexpected encoding %s (%v)", data, err)
+	}
+	var q Quality
+	if err := json.Unmarshal([]byte(`"good"`), &q); err != nil || q != QualityGood {
+		t.Errorf("Expected good, got %v (%v)", q, err)
+	}
+}
+
+// TestPlcValueJSON tests that values decode with named data types
+func TestPlcValueJSON(t *testing.T) {
+	var op BatchOperation
+	if err := json.Unmarshal([]byte(`{"tag_name":"Speed","is_write":true,"data_type":"int32","value":5}`), &op); err != nil {
+		t.Fatal(err)
+	}
+	if op.DataType != Dint || !op.IsWrite {
+		t.Errorf("Unexpected operation %+v", op)
+	}
+}
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Member returns the value at path within the structure, following nested
+// structures and array elements, e.g. "Steps[2].Duration". Member names are
+// matched ignoring case, as Logix does. The second return value is false if
+// the path does not exist.
+func (v *UdtValue) Member(path string) (interface{}, bool) {
+	if v == nil {
+		return nil, false
+	}
+	var current interface{} = v
+	for _, segment := range strings.Split(path, ".") {
+		name, indexes, ok := splitIndexes(segment)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = member(current, name); !ok {
+			return nil, false
+		}
+		for _, i := range indexes {
+			elements, ok := current.([]interface{})
+			if !ok || i >= len(elements) {
+				return nil, false
+			}
+			current = elements[i]
+		}
+	}
+	return current, true
+}
+
+// member returns the named member of a structure value
+func member(v interface{}, name string) (interface{}, bool) {
+	var members map[string]interface{}
+	switch v := v.(type) {
+	case *UdtValue:
+		if v == nil {
+			return nil, false
+		}
+		members = v.Members
+	case UdtValue:
+		members = v.Members
+	case map[string]interface{}:
+		// A nested UdtValue decoded from JSON
+		members = v
+		if inner, ok := v["members"].(map[string]interface{}); ok && len(v) == 1 {
+			members = inner
+		}
+	default:
+		return nil, false
+	}
+	if value, ok := members[name]; ok {
+		return value, true
+	}
+	for key, value := range members {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// splitIndexes splits a path segment such as "Steps[2]" into the member name
+// and its array indexes
+func splitIndexes(segment string) (string, []int, bool) {
+	name, rest, found := strings.Cut(segment, "[")
+	if name == "" {
+		return "", nil, false
+	}
+	var indexes []int
+	for found {
+		var index string
+		if index, rest, found = strings.Cut(rest, "]"); !found {
+			return "", nil, false
+		}
+		i, err := strconv.Atoi(index)
+		if err != nil || i < 0 {
+			return "", nil, false
+		}
+		indexes = append(indexes, i)
+		if rest == "" {
+			break
+		}
+		if rest, found = strings.CutPrefix(rest, "["); !found {
+			return "", nil, false
+		}
+	}
+	return name, indexes, true
+}
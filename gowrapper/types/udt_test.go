@@ -0,0 +1,46 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestUdtValueMember tests following paths through nested structures and
+// arrays of structures
+func TestUdtValueMember(t *testing.T) {
+	recipe := &UdtValue{Members: map[string]interface{}{
+		"Setpoint": 42.5,
+		"Steps": []interface{}{
+			&UdtValue{Members: map[string]interface{}{"Duration": int32(10)}},
+			&UdtValue{Members: map[string]interface{}{"Duration": int32(20), "Temps": []interface{}{1.5, 2.5}}},
+		},
+	}}
+	for path, want := range map[string]interface{}{
+		"Setpoint":          42.5,
+		"setpoint":          42.5,
+		"Steps[1].Duration": int32(20),
+		"Steps[1].Temps[1]": 2.5,
+	} {
+		if got, ok := recipe.Member(path); !ok || got != want {
+			t.Errorf("Member(%q) = %v, %v; want %v", path, got, ok, want)
+		}
+	}
+	for _, path := range []string{"Missing", "Steps[2].Duration", "Setpoint.X", "Steps[x]", "Steps[0", "Steps[0]x", ""} {
+		if _, ok := recipe.Member(path); ok {
+			t.Errorf("Expected %q not to exist", path)
+		}
+	}
+
+	// Nested values keep their structure through JSON
+	data, err := json.Marshal(recipe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded UdtValue
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := decoded.Member("Steps[0].Duration"); !ok || got != 10.0 {
+		t.Errorf("Unexpected decoded value %v in %s", got, data)
+	}
+}
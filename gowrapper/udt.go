@@ -0,0 +1,492 @@
+package ethernetip
+
+/*
+#include <stdlib.h>
+
+// UDT operations
+extern int eip_read_udt(int client_id, const char* tag_name, char* result, int max_size);
+extern int eip_write_udt(int client_id, const char* tag_name, const char* value, int size);
+*/
+import "C"
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/codec"
+)
+
+// UDT buffer sizes for ReadUdt
+const (
+	// DefaultMaxUdtSize is the largest structure ReadUdt and WriteUdt accept
+	// unless changed with SetMaxUdtSize
+	DefaultMaxUdtSize = 64 * 1024
+	// initialUdtBuffer is the first buffer tried for the native JSON reply
+	initialUdtBuffer = 4096
+)
+
+// udtBufferTooSmall is returned by eip_read_udt when the value does not fit
+const udtBufferTooSmall = -2
+
+// SetMaxUdtSize sets the largest structure, in bytes, that ReadUdt and
+// WriteUdt transfer. For tags in the tag database this is the structure size
+// from the template; otherwise it bounds the JSON the native driver returns.
+// Larger structures fail with ErrInvalidTagLength. Zero or less restores
+// DefaultMaxUdtSize.
+func (c *EipClient) SetMaxUdtSize(size int) {
+	if size < 0 {
+		size = 0
+	}
+	c.maxUdtSize.Store(int64(size))
+}
+
+// MaxUdtSize returns the largest structure ReadUdt and WriteUdt transfer
+func (c *EipClient) MaxUdtSize() int {
+	if size := int(c.maxUdtSize.Load()); size > 0 {
+		return size
+	}
+	return DefaultMaxUdtSize
+}
+
+// udtTooLarge is the error for a structure beyond the maximum UDT size
+func udtTooLarge(tagName string, size, max int) error {
+	return NewEipErrorWithDetails(ErrInvalidTagLength,
+		fmt.Sprintf("UDT tag %s is larger than the maximum UDT size of %d bytes", tagName, max),
+		map[string]interface{}{"tag_name": tagName, "size": size, "max_udt_size": max})
+}
+
+// knownUdt returns the template of a tag in the tag database, or false if
+// the tag is not in it
+func (c *EipClient) knownUdt(tagName string) (*StructTemplate, bool, error) {
+	if _, known := c.TagDatabase().Lookup(tagName); !known {
+		return nil, false, nil
+	}
+	template, err := c.tagTemplate(tagName)
+	if err != nil {
+		return nil, true, err
+	}
+	if max := c.MaxUdtSize(); template.Size > max {
+		return nil, true, udtTooLarge(tagName, template.Size, max)
+	}
+	return template, true, nil
+}
+
+// readUdtTemplate reads a structure with Read Tag Fragmented, so structures
+// larger than one packet take several round trips, and decodes its members
+// with the template
+func (c *EipClient) readUdtTemplate(tagName string, template *StructTemplate) (*UdtValue, error) {
+	data, err := c.readStructData(tagName)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < template.Size {
+		return nil, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("UDT tag %s is shorter than its template", tagName),
+			map[string]interface{}{"tag_name": tagName, "length": len(data), "size": template.Size})
+	}
+	return c.decodeStruct(template, data)
+}
+
+// decodeStruct decodes the members of structure data with its template
+func (c *EipClient) decodeStruct(template *StructTemplate, data []byte) (*UdtValue, error) {
+	members := make(map[string]interface{}, len(template.Members))
+	for _, m := range template.Members {
+		if hiddenMember(m) {
+			continue
+		}
+		value, ok, err := c.decodeMember(m, data)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			members[m.Name] = value
+		}
+	}
+	return &UdtValue{Members: members}, nil
+}
+
+// writeUdtTemplate writes the members in value to a structure. The tag is
+// read first so members not in value, padding and hidden members keep their
+// values; the whole structure is then written back, with Write Tag
+// Fragmented if it is larger than one packet.
+func (c *EipClient) writeUdtTemplate(tagName string, template *StructTemplate, value *UdtValue) error {
+	byName := make(map[string]TemplateMember, len(template.Members))
+	for _, m := range template.Members {
+		if !hiddenMember(m) {
+			byName[strings.ToLower(m.Name)] = m
+		}
+	}
+	for name := range value.Members {
+		if _, ok := byName[strings.ToLower(name)]; !ok {
+			return NewEipErrorWithDetails(ErrInvalidTagName, fmt.Sprintf("%s has no member %s", template.Name, name),
+				map[string]interface{}{"tag_name": tagName, "member": name, "template": template.Name})
+		}
+	}
+
+	current, err := c.readStructData(tagName)
+	if err != nil {
+		return err
+	}
+	if len(current) < template.Size {
+		return NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("UDT tag %s is shorter than its template", tagName),
+			map[string]interface{}{"tag_name": tagName, "length": len(current), "size": template.Size})
+	}
+	data := make([]byte, 2+template.Size)
+	binary.LittleEndian.PutUint16(data, template.Handle)
+	copy(data[2:], current)
+	for name, v := range value.Members {
+		m := byName[strings.ToLower(name)]
+		if err := c.encodeMember(m, data[2:], v); err != nil {
+			return NewEipErrorWithDetails(ErrInvalidTagValue, fmt.Sprintf("member %s of %s: %v", m.Name, tagName, err),
+				map[string]interface{}{"tag_name": tagName, "member": m.Name})
+		}
+	}
+	return c.writeRaw(tagName, CIPTypeStruct, data)
+}
+
+// hiddenMember reports whether a template member is an implementation
+// detail, such as the SINT that holds a structure's BOOL members
+func hiddenMember(m TemplateMember) bool {
+	return strings.HasPrefix(m.Name, "ZZZZZZZZZZ") || strings.HasPrefix(m.Name, "__")
+}
+
+// memberLayout returns the element type and count of a member and its size
+// in bytes. Nested structures have no element type and are sized from their
+// template.
+func (c *EipClient) memberLayout(m TemplateMember) (dataType PlcDataType, count, size int, atomic bool, err error) {
+	count = 1
+	if m.IsArray() {
+		count = int(m.Info)
+	}
+	if m.IsStructure() {
+		nested, err := c.GetTemplate(m.TypeCode())
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		return 0, count, count * nested.Size, false, nil
+	}
+	dataType, ok := atomicDataType(m.TypeCode())
+	if !ok {
+		return 0, 0, 0, false, nil
+	}
+	_, elementSize, _ := cipTypeInfo(dataType)
+	return dataType, count, count * elementSize, true, nil
+}
+
+// decodeMember decodes one member from the structure data: atomic values as
+// their Go type, nested structures as *UdtValue, string types as string, and
+// arrays of any of them as []interface{}. Members of types the wrapper does
+// not know are skipped.
+func (c *EipClient) decodeMember(m TemplateMember, data []byte) (interface{}, bool, error) {
+	offset := int(m.Offset)
+	if m.TypeCode() == CIPTypeBool && !m.IsArray() && !m.IsStructure() {
+		if offset >= len(data) {
+			return nil, false, memberOutOfRange(m, len(data))
+		}
+		return codec.Bit(data[offset:], int(m.Info)), true, nil
+	}
+	dataType, count, size, atomic, err := c.memberLayout(m)
+	if err != nil || size == 0 {
+		return nil, false, err
+	}
+	if offset+size > len(data) {
+		return nil, false, memberOutOfRange(m, len(data))
+	}
+	if !atomic {
+		value, err := c.decodeNested(m, count, data[offset:offset+size])
+		return value, err == nil, err
+	}
+	if !m.IsArray() {
+		return decodeElement(dataType, data[offset:]), true, nil
+	}
+	elementSize := size / count
+	values := make([]interface{}, count)
+	for i := range values {
+		values[i] = decodeElement(dataType, data[offset+i*elementSize:])
+	}
+	return values, true, nil
+}
+
+// encodeMember encodes v into the member's bytes of the structure data
+func (c *EipClient) encodeMember(m TemplateMember, data []byte, v interface{}) error {
+	offset := int(m.Offset)
+	if m.TypeCode() == CIPTypeBool && !m.IsArray() && !m.IsStructure() {
+		b, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("expected bool, got %T", v)
+		}
+		if offset >= len(data) {
+			return memberOutOfRange(m, len(data))
+		}
+		codec.SetBit(data[offset:], int(m.Info), b)
+		return nil
+	}
+	dataType, count, size, atomic, err := c.memberLayout(m)
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		return fmt.Errorf("unsupported member type 0x%04X", m.Type)
+	}
+	if offset+size > len(data) {
+		return memberOutOfRange(m, len(data))
+	}
+	if !atomic {
+		return c.encodeNested(m, count, data[offset:offset+size], v)
+	}
+	if !m.IsArray() {
+		encoded, err := encodeElement(dataType, v)
+		if err != nil {
+			return err
+		}
+		copy(data[offset:], encoded)
+		return nil
+	}
+	values, ok := v.([]interface{})
+	if !ok || len(values) > count {
+		return fmt.Errorf("expected up to %d elements, got %T", count, v)
+	}
+	elementSize := size / count
+	for i, element := range values {
+		encoded, err := encodeElement(dataType, element)
+		if err != nil {
+			return fmt.Errorf("element %d: %v", i, err)
+		}
+		copy(data[offset+i*elementSize:], encoded)
+	}
+	return nil
+}
+
+// decodeNested decodes a nested structure member, or an array of them, from
+// the member's bytes
+func (c *EipClient) decodeNested(m TemplateMember, count int, data []byte) (interface{}, error) {
+	nested, err := c.GetTemplate(m.TypeCode())
+	if err != nil {
+		return nil, err
+	}
+	decode := func(element []byte) (interface{}, error) {
+		if _, isString := nested.StringCapacity(); isString {
+			return codec.DecodeString(element)
+		}
+		return c.decodeStruct(nested, element)
+	}
+	if !m.IsArray() {
+		return decode(data)
+	}
+	values := make([]interface{}, count)
+	for i := range values {
+		if values[i], err = decode(data[i*nested.Size : (i+1)*nested.Size]); err != nil {
+			return nil, fmt.Errorf("element %d of %s: %v", i, m.Name, err)
+		}
+	}
+	return values, nil
+}
+
+// encodeNested encodes v into the bytes of a nested structure member. A
+// structure is given as *UdtValue, UdtValue or a member map, as decoded from
+// JSON, and only the members given are changed; a string type is given as a
+// string. For arrays, v is a []interface{} of elements, where nil leaves an
+// element unchanged. The raw bytes of the whole member are accepted too.
+func (c *EipClient) encodeNested(m TemplateMember, count int, data []byte, v interface{}) error {
+	if raw, ok := v.([]byte); ok {
+		if len(raw) != len(data) {
+			return fmt.Errorf("expected %d bytes of structure data, got %d", len(data), len(raw))
+		}
+		copy(data, raw)
+		return nil
+	}
+	nested, err := c.GetTemplate(m.TypeCode())
+	if err != nil {
+		return err
+	}
+	encode := func(element []byte, v interface{}) error {
+		if capacity, isString := nested.StringCapacity(); isString {
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("expected string, got %T", v)
+			}
+			encoded, err := codec.EncodeString(s, capacity)
+			if err != nil {
+				return err
+			}
+			copy(element, encoded)
+			return nil
+		}
+		members, err := udtMembers(v)
+		if err != nil {
+			return err
+		}
+		return c.encodeStruct(nested, element, members)
+	}
+	if !m.IsArray() {
+		return encode(data, v)
+	}
+	values, ok := v.([]interface{})
+	if !ok || len(values) > count {
+		return fmt.Errorf("expected up to %d elements, got %T", count, v)
+	}
+	for i, element := range values {
+		if element == nil {
+			continue
+		}
+		if err := encode(data[i*nested.Size:(i+1)*nested.Size], element); err != nil {
+			return fmt.Errorf("element %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// encodeStruct encodes members into structure data with its template,
+// leaving the other members unchanged
+func (c *EipClient) encodeStruct(template *StructTemplate, data []byte, members map[string]interface{}) error {
+	byName := make(map[string]TemplateMember, len(template.Members))
+	for _, m := range template.Members {
+		if !hiddenMember(m) {
+			byName[strings.ToLower(m.Name)] = m
+		}
+	}
+	for name, v := range members {
+		m, ok := byName[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("%s has no member %s", template.Name, name)
+		}
+		if err := c.encodeMember(m, data, v); err != nil {
+			return fmt.Errorf("%s: %v", m.Name, err)
+		}
+	}
+	return nil
+}
+
+// udtMembers returns the members of a nested structure value
+func udtMembers(v interface{}) (map[string]interface{}, error) {
+	switch v := v.(type) {
+	case *UdtValue:
+		if v == nil {
+			return nil, nil
+		}
+		return v.Members, nil
+	case UdtValue:
+		return v.Members, nil
+	case map[string]interface{}:
+		// A UdtValue decoded from JSON as a plain map
+		if inner, ok := v["members"].(map[string]interface{}); ok && len(v) == 1 {
+			return inner, nil
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("expected a structure value, got %T", v)
+	}
+}
+
+// memberOutOfRange is the error for a member beyond the structure data
+func memberOutOfRange(m TemplateMember, length int) error {
+	return NewEipErrorWithDetails(ErrInvalidTagOffset, fmt.Sprintf("member %s lies beyond the %d-byte structure", m.Name, length),
+		map[string]interface{}{"member": m.Name, "offset": m.Offset})
+}
+
+// ReadUdt reads a UDT (User Defined Type) from the PLC. Tags in the tag
+// database are read with Read Tag Fragmented, so structures larger than one
+// packet are supported, and decoded with their template; other tags are
+// read by the native driver. Structures larger than MaxUdtSize fail with
+// ErrInvalidTagLength.
+func (c *EipClient) ReadUdt(tagName string) (*UdtValue, error) {
+	var value *UdtValue
+	err := c.submitTag(OperationRead, tagName, func(tagName string) (err error) {
+		value, err = c.readUdt(tagName)
+		return err
+	})
+	return value, err
+}
+
+// readUdt is ReadUdt without queuing
+func (c *EipClient) readUdt(tagName string) (*UdtValue, error) {
+	if template, known, err := c.knownUdt(tagName); known {
+		if err != nil {
+			return nil, err
+		}
+		return c.readUdtTemplate(tagName, template)
+	}
+
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	maxSize := c.MaxUdtSize()
+	size := initialUdtBuffer
+	if size > maxSize {
+		size = maxSize
+	}
+	for {
+		cResult := C.malloc(C.size_t(size))
+		retCode := int(C.eip_read_udt(C.int(c.id()), cTagName, (*C.char)(cResult), C.int(size)))
+		if retCode == 0 {
+			result := C.GoString((*C.char)(cResult))
+			C.free(cResult)
+
+			// Parse the JSON result into UdtValue
+			var udtValue UdtValue
+			if err := json.Unmarshal([]byte(result), &udtValue); err != nil {
+				return nil, fmt.Errorf("failed to parse UDT value: %v", err)
+			}
+			return &udtValue, nil
+		}
+		C.free(cResult)
+
+		if retCode != udtBufferTooSmall {
+			return nil, &EipError{
+				Code:    retCode,
+				Message: fmt.Sprintf("Failed to read UDT tag %s", tagName),
+			}
+		}
+		next, ok := growStringBuffer(size, maxSize)
+		if !ok {
+			return nil, udtTooLarge(tagName, size, maxSize)
+		}
+		size = next
+	}
+}
+
+// WriteUdt writes a UDT (User Defined Type) to the PLC. For tags in the tag
+// database only the members in value are changed: the structure is read,
+// updated and written back, with Write Tag Fragmented if it is larger than
+// one packet. Member names are matched ignoring case.
+func (c *EipClient) WriteUdt(tagName string, value *UdtValue) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.auditedAs(tagName, Udt.String(), value, func() error { return c.writeUdt(tagName, value) })
+	})
+}
+
+// writeUdt is WriteUdt without auditing
+func (c *EipClient) writeUdt(tagName string, value *UdtValue) error {
+	if template, known, err := c.knownUdt(tagName); known {
+		if err != nil {
+			return err
+		}
+		return c.writeUdtTemplate(tagName, template, value)
+	}
+
+	cTagName := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cTagName))
+
+	// Convert UdtValue to JSON
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal UDT value: %v", err)
+	}
+	if max := c.MaxUdtSize(); len(jsonData) > max {
+		return udtTooLarge(tagName, len(jsonData), max)
+	}
+
+	cValue := C.CString(string(jsonData))
+	defer C.free(unsafe.Pointer(cValue))
+
+	retCode := int(C.eip_write_udt(C.int(c.id()), cTagName, cValue, C.int(len(jsonData))))
+	if retCode != 0 {
+		return &EipError{
+			Code:    retCode,
+			Message: fmt.Sprintf("Failed to write UDT tag %s", tagName),
+		}
+	}
+
+	return nil
+}
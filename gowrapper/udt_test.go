@@ -0,0 +1,144 @@
+package ethernetip
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testUdtClient returns a client whose tag database holds Motor, a 68-byte
+// MOTOR structure with a nested 4-byte STATUS structure, an array of them and
+// a STRING20
+func testUdtClient() *EipClient {
+	client := &EipClient{}
+	client.tagDB.Store(NewTagDatabase([]TagInfo{{Name: "Motor", SymbolType: symbolTypeStructBit | 0x0A01}}))
+	client.templates.Store(uint16(0x0A01), &StructTemplate{
+		Instance: 0x0A01,
+		Name:     "MOTOR",
+		Handle:   0x5A5A,
+		Size:     68,
+		Members: []TemplateMember{
+			{Name: "ZZZZZZZZZZMOTOR0", Type: CIPTypeSint},
+			{Name: "Running", Type: CIPTypeBool, Info: 0},
+			{Name: "Fault", Type: CIPTypeBool, Info: 3},
+			{Name: "Speed", Type: CIPTypeReal, Offset: 4},
+			{Name: "Counts", Type: 0x2000 | CIPTypeInt, Info: 3, Offset: 8},
+			{Name: "Status", Type: symbolTypeStructBit | 0x0A02, Offset: 16},
+			{Name: "Vendor", Type: 0x00D3, Offset: 20},
+			{Name: "History", Type: symbolTypeStructBit | 0x2000 | 0x0A02, Info: 5, Offset: 24},
+			{Name: "Label", Type: symbolTypeStructBit | 0x0A03, Offset: 44},
+		},
+	})
+	client.templates.Store(uint16(0x0A02), &StructTemplate{Instance: 0x0A02, Name: "STATUS", Size: 4, Members: []TemplateMember{
+		{Name: "Code", Type: CIPTypeInt},
+		{Name: "Severity", Type: CIPTypeSint, Offset: 2},
+	}})
+	client.templates.Store(uint16(0x0A03), &StructTemplate{Instance: 0x0A03, Name: "STRING20", Size: 24, Members: []TemplateMember{
+		{Name: "LEN", Type: CIPTypeDint},
+		{Name: "DATA", Type: 0x2000 | CIPTypeSint, Info: 20, Offset: 4},
+	}})
+	return client
+}
+
+// TestUdtMembers tests encoding and decoding structure members with a
+// template
+func TestUdtMembers(t *testing.T) {
+	client := testUdtClient()
+	template, _ := client.GetTemplate(0x0A01)
+	data := make([]byte, template.Size)
+	values := map[string]interface{}{
+		"Fault":  true,
+		"Speed":  12.5,
+		"Counts": []interface{}{1, -2, 3},
+		"Status": []byte{9, 8, 7, 6},
+		// Nested values as decoded from JSON, changing one element
+		"History": []interface{}{nil, map[string]interface{}{"members": map[string]interface{}{"Code": 7}}},
+		"Label":   "Pump 3",
+	}
+	for _, m := range template.Members {
+		if v, ok := values[m.Name]; ok {
+			if err := client.encodeMember(m, data, v); err != nil {
+				t.Fatalf("Failed to encode %s: %v", m.Name, err)
+			}
+		}
+	}
+	if data[0] != 0x08 {
+		t.Errorf("Expected Fault in bit 3 of the hidden SINT, got %08b", data[0])
+	}
+
+	decoded := make(map[string]interface{})
+	for _, m := range template.Members {
+		if hiddenMember(m) {
+			continue
+		}
+		if v, ok, err := client.decodeMember(m, data); err != nil {
+			t.Fatalf("Failed to decode %s: %v", m.Name, err)
+		} else if ok {
+			decoded[m.Name] = v
+		}
+	}
+	want := map[string]interface{}{
+		"Running": false,
+		"Fault":   true,
+		"Speed":   12.5,
+		"Counts":  []interface{}{int16(1), int16(-2), int16(3)},
+		"Status":  &UdtValue{Members: map[string]interface{}{"Code": int16(0x0809), "Severity": int8(7)}},
+		"History": []interface{}{status(0, 0), status(7, 0), status(0, 0), status(0, 0), status(0, 0)},
+		"Label":   "Pump 3",
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("Expected %v, got %v", want, decoded)
+	}
+
+	counts := template.Members[4]
+	if err := client.encodeMember(counts, data, []interface{}{1, 2, 3, 4}); err == nil {
+		t.Error("Expected too many array elements to be rejected")
+	}
+	if err := client.encodeMember(template.Members[5], data, []byte{1}); err == nil {
+		t.Error("Expected nested structure data of the wrong size to be rejected")
+	}
+	if err := client.encodeMember(template.Members[5], data, &UdtValue{Members: map[string]interface{}{"Torque": 1}}); err == nil {
+		t.Error("Expected an unknown nested member to be rejected")
+	}
+	if !bytes.Equal(data[16:20], []byte{9, 8, 7, 6}) {
+		t.Error("Expected rejected writes to leave the data unchanged")
+	}
+	if err := client.encodeMember(template.Members[5], data, &UdtValue{Members: map[string]interface{}{"Severity": 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[16:20], []byte{9, 8, 2, 6}) {
+		t.Errorf("Expected only Severity to change, got % X", data[16:20])
+	}
+}
+
+// status returns a decoded STATUS value
+func status(code int16, severity int8) *UdtValue {
+	return &UdtValue{Members: map[string]interface{}{"Code": code, "Severity": severity}}
+}
+
+// TestMaxUdtSize tests that structures beyond the maximum size and unknown
+// members fail before anything is sent
+func TestMaxUdtSize(t *testing.T) {
+	client := testUdtClient()
+	if client.MaxUdtSize() != DefaultMaxUdtSize {
+		t.Errorf("Expected the default maximum, got %d", client.MaxUdtSize())
+	}
+
+	var eipErr *EipError
+	if err := client.WriteUdt("Motor", &UdtValue{Members: map[string]interface{}{"Torque": 1}}); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagName {
+		t.Errorf("Expected an unknown member to be rejected, got %v", err)
+	}
+
+	client.SetMaxUdtSize(16)
+	if _, err := client.ReadUdt("Motor"); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagLength || eipErr.Details["size"] != 68 {
+		t.Errorf("Expected ErrInvalidTagLength for a 68-byte structure, got %v", err)
+	}
+	if err := client.WriteUdt("Motor", &UdtValue{}); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagLength {
+		t.Errorf("Expected the write to be rejected too, got %v", err)
+	}
+	client.SetMaxUdtSize(0)
+	if client.MaxUdtSize() != DefaultMaxUdtSize {
+		t.Error("Expected zero to restore the default maximum")
+	}
+}
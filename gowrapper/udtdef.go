@@ -0,0 +1,181 @@
+package ethernetip
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UdtDefinition describes a structure type by name, with its members in the
+// form needed to interpret ReadUdt results and raw structure data
+type UdtDefinition struct {
+	Name string `json:"name"`
+	// Instance is the Template Object instance, as used by GetTemplate
+	Instance uint16 `json:"instance"`
+	// Handle is the structure handle that accompanies the type in tag reads
+	// and writes
+	Handle uint16 `json:"handle"`
+	// Size is the structure size in bytes
+	Size    int         `json:"size"`
+	Members []UdtMember `json:"members"`
+}
+
+// UdtMember is a member of a UdtDefinition
+type UdtMember struct {
+	Name string `json:"name"`
+	// Type is the atomic type name ("DINT") or the template name of a nested
+	// structure
+	Type string `json:"type"`
+	// CIPType is the elementary type code, or the template instance of a
+	// nested structure
+	CIPType   uint16 `json:"cip_type"`
+	Structure bool   `json:"structure,omitempty"`
+	// Offset is the byte offset of the member in the structure data
+	Offset int `json:"offset"`
+	// ArraySize is the number of elements of an array member, 0 for scalars
+	ArraySize int `json:"array_size,omitempty"`
+	// Bit is the bit number of a BOOL member within the byte at Offset
+	Bit int `json:"bit"`
+}
+
+// GetUdtDefinition returns the definition of the structure type named
+// udtName, matched ignoring case. Templates already read are searched first;
+// otherwise the templates of the structure tags in the tag database, and the
+// structures nested in them, are read until the type is found, so
+// DiscoverTagDatabase must have run. The hidden members that hold packed BOOLs
+// are left out.
+func (c *EipClient) GetUdtDefinition(udtName string) (*UdtDefinition, error) {
+	template, err := c.findTemplate(udtName)
+	if err != nil {
+		return nil, err
+	}
+	return udtDefinition(template, c.GetTemplate)
+}
+
+// UdtDefinition returns the definition of the structure type named udtName,
+// matched ignoring case, from the templates of the export, so structure types
+// can be looked up offline
+func (e *TagExport) UdtDefinition(udtName string) (*UdtDefinition, error) {
+	template, lookup, err := e.template(udtName)
+	if err != nil {
+		return nil, err
+	}
+	return udtDefinition(template, lookup)
+}
+
+// template returns the template of the export named udtName, matched
+// ignoring case, and a lookup of the export's templates by instance
+func (e *TagExport) template(udtName string) (*StructTemplate, func(instance uint16) (*StructTemplate, error), error) {
+	byInstance := make(map[uint16]*StructTemplate, len(e.Templates))
+	var found *StructTemplate
+	for _, template := range e.Templates {
+		byInstance[template.Instance] = template
+		if found == nil && strings.EqualFold(template.Name, udtName) {
+			found = template
+		}
+	}
+	if found == nil {
+		return nil, nil, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("no structure type named %s in the export", udtName),
+			map[string]interface{}{"udt_name": udtName})
+	}
+	return found, func(instance uint16) (*StructTemplate, error) {
+		if template, ok := byInstance[instance]; ok {
+			return template, nil
+		}
+		return nil, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("template %d is not in the export", instance),
+			map[string]interface{}{"instance": instance})
+	}, nil
+}
+
+// findTemplate returns the template named name
+func (c *EipClient) findTemplate(name string) (*StructTemplate, error) {
+	var found *StructTemplate
+	c.templates.Range(func(_, v interface{}) bool {
+		if template := v.(*StructTemplate); strings.EqualFold(template.Name, name) {
+			found = template
+			return false
+		}
+		return true
+	})
+	if found != nil {
+		return found, nil
+	}
+
+	db := c.TagDatabase()
+	if db == nil {
+		return nil, NewEipErrorWithDetails(ErrInvalidOperation,
+			fmt.Sprintf("structure type %s has not been read; run DiscoverTagDatabase first", name),
+			map[string]interface{}{"udt_name": name})
+	}
+	var pending []uint16
+	for _, tag := range db.Tags {
+		if tag.IsStructure() {
+			pending = append(pending, tag.TypeCode())
+		}
+	}
+	seen := map[uint16]bool{}
+	for len(pending) > 0 {
+		instance := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if seen[instance] {
+			continue
+		}
+		seen[instance] = true
+		template, err := c.GetTemplate(instance)
+		if err != nil {
+			// Some system types cannot be read; they are not UDTs
+			continue
+		}
+		if strings.EqualFold(template.Name, name) {
+			return template, nil
+		}
+		for _, m := range template.Members {
+			if m.IsStructure() {
+				pending = append(pending, m.TypeCode())
+			}
+		}
+	}
+	return nil, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("no structure type named %s in the tag database", name),
+		map[string]interface{}{"udt_name": name})
+}
+
+// udtDefinition describes template, looking up the templates of nested
+// structures for their names
+func udtDefinition(template *StructTemplate, lookup func(instance uint16) (*StructTemplate, error)) (*UdtDefinition, error) {
+	def := &UdtDefinition{
+		Name:     template.Name,
+		Instance: template.Instance,
+		Handle:   template.Handle,
+		Size:     template.Size,
+		Members:  make([]UdtMember, 0, len(template.Members)),
+	}
+	for _, m := range template.Members {
+		if hiddenMember(m) {
+			continue
+		}
+		member := UdtMember{
+			Name:      m.Name,
+			CIPType:   m.TypeCode(),
+			Structure: m.IsStructure(),
+			Offset:    int(m.Offset),
+		}
+		switch {
+		case m.IsArray():
+			member.ArraySize = int(m.Info)
+		case m.TypeCode() == CIPTypeBool && !m.IsStructure():
+			member.Bit = int(m.Info)
+		}
+		if m.IsStructure() {
+			nested, err := lookup(m.TypeCode())
+			if err != nil {
+				return nil, err
+			}
+			member.Type = nested.Name
+		} else if dataType, ok := atomicDataType(m.TypeCode()); ok {
+			member.Type = dataType.String()
+		} else {
+			member.Type = fmt.Sprintf("0x%02X", m.TypeCode())
+		}
+		def.Members = append(def.Members, member)
+	}
+	return def, nil
+}
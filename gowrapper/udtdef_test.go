@@ -0,0 +1,59 @@
+package ethernetip
+
+import "testing"
+
+// TestGetUdtDefinition tests looking up a structure type by name, including
+// one nested in another
+func TestGetUdtDefinition(t *testing.T) {
+	if _, err := (&EipClient{}).GetUdtDefinition("RECIPE"); err == nil {
+		t.Error("Expected an error without templates or a tag database")
+	}
+
+	client := browseClient()
+	def, err := client.GetUdtDefinition("recipe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Name != "RECIPE" || def.Instance != 0x100 || len(def.Members) != 3 {
+		t.Fatalf("Unexpected definition %+v", def)
+	}
+	running, steps, setpoint := def.Members[0], def.Members[1], def.Members[2]
+	if running.Type != "BOOL" || running.Bit != 0 || running.ArraySize != 0 {
+		t.Errorf("Unexpected BOOL member %+v", running)
+	}
+	if steps.Type != "STEP" || !steps.Structure || steps.ArraySize != 3 || steps.CIPType != 0x101 || steps.Offset != 4 {
+		t.Errorf("Unexpected array member %+v", steps)
+	}
+	if setpoint.Type != "REAL" || setpoint.Offset != 28 {
+		t.Errorf("Unexpected REAL member %+v", setpoint)
+	}
+
+	// Once flushed, the templates have to be read from the controller again
+	client.FlushCaches()
+	if _, err := client.GetUdtDefinition("STEP"); err == nil {
+		t.Error("Expected an error when the templates cannot be read")
+	}
+	if _, err := client.GetUdtDefinition("MISSING"); err == nil {
+		t.Error("Expected an error for an unknown type")
+	}
+}
+
+// TestTagExportUdtDefinition tests looking up a structure type offline
+func TestTagExportUdtDefinition(t *testing.T) {
+	client := browseClient()
+	recipe, _ := client.GetTemplate(0x100)
+	step, _ := client.GetTemplate(0x101)
+	export := &TagExport{Templates: []*StructTemplate{recipe, step}}
+
+	def, err := export.UdtDefinition("Recipe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(def.Members) != 3 || def.Members[1].Type != "STEP" {
+		t.Errorf("Unexpected definition %+v", def)
+	}
+	export.Templates = export.Templates[:1]
+	if _, err := export.UdtDefinition("RECIPE"); err == nil {
+		t.Error("Expected an error when a nested template is missing")
+	}
+}
@@ -0,0 +1,33 @@
+package ethernetip
+
+import "fmt"
+
+// FindTagsOfType returns the tags of the tag database whose type is the
+// structure type named udtName, matched ignoring case, in name order. Arrays
+// of the type are included; check TagInfo.Dimensions to address their
+// elements. Structures nested in other tags are not searched. The type is
+// looked up as by GetUdtDefinition, so DiscoverTagDatabase must have run:
+//
+//	motors, err := client.FindTagsOfType("MotorCtrl")
+//	for _, m := range motors {
+//		fault, err := client.ReadBool(m.Name + ".Fault")
+//	}
+func (c *EipClient) FindTagsOfType(udtName string) ([]TagInfo, error) {
+	db := c.TagDatabase()
+	if db == nil {
+		return nil, NewEipErrorWithDetails(ErrInvalidOperation,
+			fmt.Sprintf("cannot find tags of type %s; run DiscoverTagDatabase first", udtName),
+			map[string]interface{}{"udt_name": udtName})
+	}
+	template, err := c.findTemplate(udtName)
+	if err != nil {
+		return nil, err
+	}
+	var tags []TagInfo
+	for _, tag := range db.Tags {
+		if tag.IsStructure() && tag.TypeCode() == template.Instance {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
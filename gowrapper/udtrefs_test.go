@@ -0,0 +1,36 @@
+package ethernetip
+
+import "testing"
+
+// TestFindTagsOfType tests finding the tags, arrays and program tags of a
+// structure type
+func TestFindTagsOfType(t *testing.T) {
+	if _, err := (&EipClient{}).FindTagsOfType("RECIPE"); err == nil {
+		t.Error("Expected an error without a tag database")
+	}
+
+	client := browseClient()
+	client.tagDB.Store(NewTagDatabase(append(client.TagDatabase().Tags,
+		TagInfo{Name: "Program:Main.Recipes", SymbolType: symbolTypeStructBit | 1<<symbolTypeDimsShift | 0x100, Program: "Main"},
+		TagInfo{Name: "Step1", SymbolType: symbolTypeStructBit | 0x101},
+	)))
+
+	tags, err := client.FindTagsOfType("recipe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	if len(names) != 2 || names[0] != "Program:Main.Recipes" || names[1] != "Recipe" {
+		t.Errorf("Expected the RECIPE tags, got %v", names)
+	}
+
+	if tags, err := client.FindTagsOfType("STEP"); err != nil || len(tags) != 1 {
+		t.Errorf("Expected Step1 only, got %v, %v", tags, err)
+	}
+	if _, err := client.FindTagsOfType("MISSING"); err == nil {
+		t.Error("Expected an error for an unknown type")
+	}
+}
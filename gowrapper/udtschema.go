@@ -0,0 +1,180 @@
+package ethernetip
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+)
+
+// jsonSchemaDialect is the JSON Schema version UdtJSONSchema emits
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is a JSON Schema document or subschema, with the keywords
+// UdtJSONSchema uses
+type JSONSchema struct {
+	Schema string `json:"$schema,omitempty"`
+	Ref    string `json:"$ref,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Type   string `json:"type,omitempty"`
+
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+
+	Items    *JSONSchema `json:"items,omitempty"`
+	MinItems *int        `json:"minItems,omitempty"`
+	MaxItems *int        `json:"maxItems,omitempty"`
+
+	// Minimum and Maximum are numbers, kept as text so 64-bit limits are
+	// exact
+	Minimum   json.Number `json:"minimum,omitempty"`
+	Maximum   json.Number `json:"maximum,omitempty"`
+	MaxLength *int        `json:"maxLength,omitempty"`
+
+	Defs map[string]*JSONSchema `json:"$defs,omitempty"`
+}
+
+// UdtJSONSchema returns a JSON Schema for values of the structure type named
+// udtName, as ReadUdt returns them and WriteUdt accepts them in JSON: an
+// object whose "members" maps member names to values. Integers carry the
+// range of their type, STRING members their capacity and arrays their
+// length; nested structures refer to definitions in "$defs". Members are not
+// required, since writes may change only some of them. The type is looked up
+// as by GetUdtDefinition.
+func (c *EipClient) UdtJSONSchema(udtName string) (*JSONSchema, error) {
+	template, err := c.findTemplate(udtName)
+	if err != nil {
+		return nil, err
+	}
+	return udtJSONSchema(template, c.GetTemplate)
+}
+
+// UdtJSONSchema returns a JSON Schema for values of the structure type named
+// udtName (see EipClient.UdtJSONSchema), from the templates of the export
+func (e *TagExport) UdtJSONSchema(udtName string) (*JSONSchema, error) {
+	template, lookup, err := e.template(udtName)
+	if err != nil {
+		return nil, err
+	}
+	return udtJSONSchema(template, lookup)
+}
+
+// udtJSONSchema builds the schema of template, looking up nested templates
+func udtJSONSchema(template *StructTemplate, lookup func(instance uint16) (*StructTemplate, error)) (*JSONSchema, error) {
+	b := schemaBuilder{lookup: lookup, defs: map[string]*JSONSchema{}, building: map[uint16]bool{}}
+	root, err := b.structure(template)
+	if err != nil {
+		return nil, err
+	}
+	root.Schema = jsonSchemaDialect
+	if len(b.defs) > 0 {
+		root.Defs = b.defs
+	}
+	return root, nil
+}
+
+// schemaBuilder collects the definitions of nested structures
+type schemaBuilder struct {
+	lookup   func(instance uint16) (*StructTemplate, error)
+	defs     map[string]*JSONSchema
+	building map[uint16]bool
+}
+
+// structure returns the schema of a structure value, or of a string for
+// string types
+func (b *schemaBuilder) structure(template *StructTemplate) (*JSONSchema, error) {
+	if capacity, ok := template.StringCapacity(); ok {
+		return &JSONSchema{Title: template.Name, Type: "string", MaxLength: &capacity}, nil
+	}
+	closed := false
+	members := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}, AdditionalProperties: &closed}
+	for _, m := range template.Members {
+		if hiddenMember(m) {
+			continue
+		}
+		element, ok, err := b.element(m)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// Not decoded by ReadUdt either
+			continue
+		}
+		if m.IsArray() {
+			n := int(m.Info)
+			element = &JSONSchema{Type: "array", Items: element, MinItems: &n, MaxItems: &n}
+		}
+		members.Properties[m.Name] = element
+	}
+	return &JSONSchema{
+		Title:                template.Name,
+		Type:                 "object",
+		Properties:           map[string]*JSONSchema{"members": members},
+		Required:             []string{"members"},
+		AdditionalProperties: &closed,
+	}, nil
+}
+
+// element returns the schema of one element of a member, false for types
+// ReadUdt skips
+func (b *schemaBuilder) element(m TemplateMember) (*JSONSchema, bool, error) {
+	if !m.IsStructure() {
+		dataType, ok := atomicDataType(m.TypeCode())
+		if !ok {
+			return nil, false, nil
+		}
+		return atomicJSONSchema(dataType), true, nil
+	}
+
+	nested, err := b.lookup(m.TypeCode())
+	if err != nil {
+		return nil, false, err
+	}
+	if _, isString := nested.StringCapacity(); isString {
+		schema, err := b.structure(nested)
+		return schema, err == nil, err
+	}
+	if _, done := b.defs[nested.Name]; !done && !b.building[nested.Instance] {
+		b.building[nested.Instance] = true
+		def, err := b.structure(nested)
+		if err != nil {
+			return nil, false, err
+		}
+		b.defs[nested.Name] = def
+	}
+	return &JSONSchema{Ref: "#/$defs/" + nested.Name}, true, nil
+}
+
+// atomicJSONSchema returns the schema of an atomic value, with the range of
+// integer types
+func atomicJSONSchema(dataType PlcDataType) *JSONSchema {
+	schema := &JSONSchema{Title: dataType.String(), Type: "integer"}
+	if dataType == Bool {
+		schema.Type = "boolean"
+	}
+	limits := func(min int64, max uint64) {
+		schema.Minimum = json.Number(strconv.FormatInt(min, 10))
+		schema.Maximum = json.Number(strconv.FormatUint(max, 10))
+	}
+	switch dataType {
+	case Sint:
+		limits(math.MinInt8, math.MaxInt8)
+	case Int:
+		limits(math.MinInt16, math.MaxInt16)
+	case Dint:
+		limits(math.MinInt32, math.MaxInt32)
+	case Lint:
+		limits(math.MinInt64, math.MaxInt64)
+	case Usint:
+		limits(0, math.MaxUint8)
+	case Uint:
+		limits(0, math.MaxUint16)
+	case Udint:
+		limits(0, math.MaxUint32)
+	case Ulint:
+		limits(0, math.MaxUint64)
+	case Real, Lreal:
+		schema.Type = "number"
+	}
+	return schema
+}
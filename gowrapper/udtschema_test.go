@@ -0,0 +1,71 @@
+package ethernetip
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestUdtJSONSchema tests the schema of a structure with BOOL, REAL, STRING
+// and nested array members
+func TestUdtJSONSchema(t *testing.T) {
+	client := browseClient()
+	client.templates.Store(uint16(0x102), &StructTemplate{Instance: 0x102, Name: "STRING20", Members: []TemplateMember{
+		{Name: "LEN", Type: CIPTypeDint},
+		{Name: "DATA", Type: 1<<symbolTypeDimsShift | CIPTypeSint, Info: 20, Offset: 4},
+	}})
+	recipe, _ := client.GetTemplate(0x100)
+	recipe.Members = append(recipe.Members,
+		TemplateMember{Name: "Label", Type: symbolTypeStructBit | 0x102, Offset: 32},
+		TemplateMember{Name: "Count", Type: CIPTypeUlint, Offset: 56})
+
+	schema, err := client.UdtJSONSchema("recipe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Schema     string `json:"$schema"`
+		Title      string `json:"title"`
+		Properties struct {
+			Members struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"members"`
+		} `json:"properties"`
+		Defs map[string]json.RawMessage `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Schema != jsonSchemaDialect || doc.Title != "RECIPE" {
+		t.Errorf("Unexpected header in %s", data)
+	}
+	want := map[string]string{
+		"Running":  `{"title":"BOOL","type":"boolean"}`,
+		"Setpoint": `{"title":"REAL","type":"number"}`,
+		"Steps":    `{"type":"array","items":{"$ref":"#/$defs/STEP"},"minItems":3,"maxItems":3}`,
+		"Label":    `{"title":"STRING20","type":"string","maxLength":20}`,
+		"Count":    `{"title":"ULINT","type":"integer","minimum":0,"maximum":18446744073709551615}`,
+	}
+	members := doc.Properties.Members.Properties
+	if len(members) != len(want) {
+		t.Errorf("Expected members %v, got %s", want, data)
+	}
+	for name, schema := range want {
+		if string(members[name]) != schema {
+			t.Errorf("%s: got %s, want %s", name, members[name], schema)
+		}
+	}
+	if step := string(doc.Defs["STEP"]); !strings.Contains(step, `"Duration":{"title":"DINT","type":"integer","minimum":-2147483648,"maximum":2147483647}`) {
+		t.Errorf("Unexpected STEP definition %s", step)
+	}
+
+	export := &TagExport{Templates: []*StructTemplate{recipe}}
+	if _, err := export.UdtJSONSchema("RECIPE"); err == nil {
+		t.Error("Expected an error when a nested template is missing")
+	}
+}
@@ -0,0 +1,212 @@
+package ethernetip
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// WriteVerification configures reading a tag back after writing it, so a
+// write the controller accepted but did not keep (a clamped setpoint, a tag
+// the program overwrites) fails instead of passing silently
+type WriteVerification struct {
+	// FloatTolerance is the largest difference accepted between the written
+	// and read value of a REAL or LREAL. REAL values are rounded to single
+	// precision before comparing, so 0 accepts the rounding of the write.
+	FloatTolerance float64 `json:"float_tolerance"`
+	// Delay waits before reading back, for values the program checks or
+	// copies on its next scan
+	Delay time.Duration `json:"delay"`
+}
+
+// SetWriteVerification makes WriteValue and WriteValueContext read every tag
+// back after writing it; nil turns verification off. Packed writes such as
+// BatchWrite, WriteFrom and TagGroup.WriteAll are not verified.
+func (c *EipClient) SetWriteVerification(v *WriteVerification) {
+	if v != nil {
+		copied := *v
+		v = &copied
+	}
+	c.writeVerification.Store(v)
+}
+
+// WriteVerification returns the client's write verification, or nil if
+// writes are not read back
+func (c *EipClient) WriteVerification() *WriteVerification {
+	if v := c.writeVerification.Load(); v != nil {
+		copied := *v
+		return &copied
+	}
+	return nil
+}
+
+// WriteValueVerified writes a value and reads it back, whether or not the
+// client verifies every write. It fails with ErrWriteVerificationFailed if
+// the value read back differs from the one written.
+func (c *EipClient) WriteValueVerified(tagName string, value *PlcValue, v WriteVerification) error {
+	return c.submitTag(OperationWrite, tagName, func(tagName string) error {
+		return c.audited(CallerIdentity{}, tagName, value.Type, value.Value, func() error {
+			return c.writeVerified(tagName, value, v)
+		})
+	})
+}
+
+// writeVerified writes a value and reads it back without queuing the
+// operation; the read-back is part of the write and is never shed
+func (c *EipClient) writeVerified(tagName string, value *PlcValue, v WriteVerification) error {
+	if err := c.writeValue(tagName, value); err != nil {
+		return err
+	}
+	return c.verifyWrite(tagName, value, v)
+}
+
+// verifyWrite reads a written tag back and compares it with value
+func (c *EipClient) verifyWrite(tagName string, value *PlcValue, v WriteVerification) error {
+	if v.Delay > 0 {
+		sleep(c.Clock(), v.Delay)
+	}
+	read, err := c.readValue(tagName, value.Type)
+	if err != nil {
+		return NewEipErrorWithDetails(ErrWriteVerificationFailed,
+			fmt.Sprintf("could not read back '%s' after writing it: %v", tagName, err),
+			map[string]interface{}{"tag_name": tagName, "written": value.Value})
+	}
+	if !ValuesMatch(value.Type, value.Value, read.Value, v.FloatTolerance) {
+		return NewEipErrorWithDetails(ErrWriteVerificationFailed,
+			fmt.Sprintf("'%s' read back %v after writing %v", tagName, read.Value, value.Value),
+			map[string]interface{}{"tag_name": tagName, "written": value.Value, "read": read.Value, "float_tolerance": v.FloatTolerance})
+	}
+	return nil
+}
+
+// ValuesMatch reports whether a value read from a tag of dataType matches the
+// value written to it, as WriteValueVerified compares them. Numbers of
+// different Go types compare by value, REAL values at single precision within
+// tolerance and times at the precision of their type.
+func ValuesMatch(dataType PlcDataType, written, read interface{}, tolerance float64) bool {
+	switch dataType {
+	case Dt, Ldt, Time:
+		w, errW := temporalLint(dataType, written)
+		r, errR := temporalLint(dataType, read)
+		return errW == nil && errR == nil && w == r
+	}
+
+	w, wNumeric := numericValue(written)
+	r, rNumeric := numericValue(read)
+	if !wNumeric || !rNumeric {
+		return reflect.DeepEqual(written, read)
+	}
+	switch dataType {
+	case Real:
+		w, r = float64(float32(w)), float64(float32(r))
+	case Lreal:
+	default:
+		return w == r
+	}
+	if math.IsNaN(w) || math.IsNaN(r) {
+		return math.IsNaN(w) && math.IsNaN(r)
+	}
+	// Equal infinities differ by NaN
+	return w == r || math.Abs(w-r) <= tolerance
+}
+
+// TagWriteResult is the outcome of writing one tag of a recipe or snapshot
+type TagWriteResult struct {
+	TagName string      `json:"tag_name"`
+	Type    PlcDataType `json:"data_type"`
+	Value   interface{} `json:"value"`
+	// Read is the value read back after writing, if the write succeeded
+	Read     interface{} `json:"read,omitempty"`
+	Written  bool        `json:"written"`
+	Verified bool        `json:"verified"`
+	Err      error       `json:"-"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// setErr records the error of a tag, if any
+func (r *TagWriteResult) setErr(err error) {
+	if err != nil {
+		r.Err = err
+		r.Error = err.Error()
+	}
+}
+
+// writeTagsVerified writes the values of results and reads them back,
+// recording the outcome in each result. Tags are written in Multiple Service
+// Packets, except strings and BOOL members of integers, which are written one
+// by one, then read back in one batch and compared as by WriteValueVerified,
+// using the client's verification tolerance and delay if set. It returns the
+// number of tags written and verified and describes the tags that failed.
+func (c *EipClient) writeTagsVerified(results []TagWriteResult) (written, verified int, failed []string) {
+	var writes []packedWrite
+	var batched []int
+	for i := range results {
+		result := &results[i]
+		if !batchable(result.TagName, result.Type) {
+			result.setErr(c.WriteValue(result.TagName, &PlcValue{Type: result.Type, Value: result.Value}))
+			continue
+		}
+		tagName := c.ResolveAlias(result.TagName)
+		req, err := writeTagRequest(tagName, result.Type, result.Value)
+		if err != nil {
+			result.setErr(err)
+			continue
+		}
+		writes = append(writes, packedWrite{tagName: tagName, dataType: result.Type, value: result.Value, request: req})
+		batched = append(batched, i)
+	}
+	for i, err := range c.writePacked(writes) {
+		results[batched[i]].setErr(err)
+	}
+
+	verification := WriteVerification{}
+	if v := c.WriteVerification(); v != nil {
+		verification = *v
+	}
+	group := c.NewTagGroup()
+	for i := range results {
+		if results[i].Written = results[i].Err == nil; results[i].Written {
+			if err := group.Add(results[i].TagName, results[i].Type); err != nil {
+				results[i].setErr(err)
+			}
+		}
+	}
+	if group.Len() > 0 {
+		sleep(c.Clock(), verification.Delay)
+		read, err := group.ReadAll()
+		for i := range results {
+			result := &results[i]
+			if !result.Written || result.Err != nil {
+				continue
+			}
+			value, ok := read[result.TagName]
+			switch {
+			case !ok:
+				result.setErr(NewEipErrorWithDetails(ErrWriteVerificationFailed,
+					fmt.Sprintf("could not read back '%s' after writing it: %v", result.TagName, err),
+					map[string]interface{}{"tag_name": result.TagName, "written": result.Value}))
+			case !ValuesMatch(result.Type, result.Value, value.Value, verification.FloatTolerance):
+				result.Read = value.Value
+				result.setErr(NewEipErrorWithDetails(ErrWriteVerificationFailed,
+					fmt.Sprintf("'%s' read back %v after writing %v", result.TagName, value.Value, result.Value),
+					map[string]interface{}{"tag_name": result.TagName, "written": result.Value, "read": value.Value, "float_tolerance": verification.FloatTolerance}))
+			default:
+				result.Read = value.Value
+				result.Verified = true
+			}
+		}
+	}
+
+	for _, result := range results {
+		if result.Written {
+			written++
+		}
+		if result.Verified {
+			verified++
+		} else {
+			failed = append(failed, fmt.Sprintf("%s (%v)", result.TagName, result.Err))
+		}
+	}
+	return written, verified, failed
+}
@@ -0,0 +1,63 @@
+package ethernetip
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestValuesMatch tests comparing written and read-back values
+func TestValuesMatch(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		dataType      PlcDataType
+		written, read interface{}
+		tolerance     float64
+		want          bool
+	}{
+		{Dint, int32(5), int32(5), 0, true},
+		{Dint, 5, int32(5), 0, true},
+		{Dint, int32(5), int32(6), 10, false},
+		{Bool, true, true, 0, true},
+		{Bool, true, false, 0, false},
+		{String, "abc", "abc", 0, true},
+		{String, "abc", "abd", 0, false},
+		{Real, 0.1, float64(float32(0.1)), 0, true},
+		{Real, 10.0, 10.004, 0.01, true},
+		{Real, 10.0, 10.5, 0.01, false},
+		{Lreal, 0.1, 0.1000001, 0, false},
+		{Lreal, 0.1, 0.1000001, 1e-6, true},
+		{Lreal, math.NaN(), math.NaN(), 0, true},
+		{Real, math.Inf(-1), math.Inf(-1), 0, true},
+		{Lreal, math.Inf(1), math.Inf(-1), 0, false},
+		{Dt, now, now.In(time.FixedZone("X", 3600)), 0, true},
+		{Dt, now.Add(1500), now.Add(1000), 0, true},
+		{Dt, now, now.Add(time.Second), 0, false},
+		{Time, 1500 * time.Millisecond, 1500 * time.Millisecond, 0, true},
+		{Time, time.Second, 2 * time.Second, 0, false},
+	}
+	for _, test := range tests {
+		if got := ValuesMatch(test.dataType, test.written, test.read, test.tolerance); got != test.want {
+			t.Errorf("%s %v vs %v (tolerance %v): got %v, want %v",
+				test.dataType, test.written, test.read, test.tolerance, got, test.want)
+		}
+	}
+}
+
+// TestSetWriteVerification tests that the client keeps its own copy
+func TestSetWriteVerification(t *testing.T) {
+	client := &EipClient{}
+	if client.WriteVerification() != nil {
+		t.Fatal("Expected verification to be off by default")
+	}
+	v := &WriteVerification{FloatTolerance: 0.5}
+	client.SetWriteVerification(v)
+	v.FloatTolerance = 2
+	if got := client.WriteVerification(); got == nil || got.FloatTolerance != 0.5 {
+		t.Errorf("Unexpected verification %+v", got)
+	}
+	client.SetWriteVerification(nil)
+	if client.WriteVerification() != nil {
+		t.Error("Expected nil to turn verification off")
+	}
+}
@@ -0,0 +1,573 @@
+package ethernetip
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// VirtualTag describes a tag computed from an expression over controller
+// tags, e.g. "Flow_A + Flow_B" or "Temp > 80"
+type VirtualTag struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	// Type is Bool for comparisons and logical expressions, Lreal otherwise
+	Type PlcDataType `json:"data_type"`
+	// Inputs are the controller tags the expression reads, including those
+	// of the virtual tags it refers to
+	Inputs []string `json:"inputs"`
+}
+
+// virtualTag is a defined virtual tag
+type virtualTag struct {
+	VirtualTag
+	expr  *expression
+	group *TagGroup // Reads the inputs in one batch
+}
+
+// virtualTags is the client's registry of virtual tags, keyed by
+// TagNameOptions.Key
+type virtualTags struct {
+	mu   sync.RWMutex
+	tags map[string]*virtualTag
+}
+
+// virtualTag returns the virtual tag named tagName, if one is defined
+func (c *EipClient) virtualTag(tagName string) (*virtualTag, bool) {
+	c.virtual.mu.RLock()
+	empty := len(c.virtual.tags) == 0
+	c.virtual.mu.RUnlock()
+	if empty {
+		return nil, false
+	}
+	return c.virtual.lookup(c.TagNameOptions().Key(tagName))
+}
+
+// lookup returns the virtual tag stored under key
+func (v *virtualTags) lookup(key string) (*virtualTag, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	tag, ok := v.tags[key]
+	return tag, ok
+}
+
+// DefineVirtualTag defines a read-only tag computed from an expression over
+// controller tags. The virtual tag is read with ReadValue and ReadTag and
+// subscribed to like any other tag; every read fetches its inputs in one
+// batch and evaluates the expression.
+//
+// Expressions use Go syntax: numbers, true and false, the operators + - * /
+// % == != < <= > >= && || ! and parentheses, and the functions abs, min and
+// max. Tag references are names such as Flow_A, Motor.Speed or Temps[3];
+// they may also name other virtual tags. The type of every controller tag
+// must be known to the client's TagTypes. Redefining a virtual tag replaces
+// it unless other virtual tags refer to it.
+func (c *EipClient) DefineVirtualTag(name, expression string) error {
+	opts := c.TagNameOptions()
+	name = opts.Clean(name)
+	if name == "" {
+		return NewEipError(ErrInvalidTagName, "virtual tag name cannot be empty")
+	}
+	key := opts.Key(name)
+
+	c.virtual.mu.Lock()
+	defer c.virtual.mu.Unlock()
+	if users := c.virtualUsers(key); len(users) > 0 {
+		return NewEipErrorWithDetails(ErrInvalidOperation,
+			fmt.Sprintf("virtual tag '%s' is used by %s", name, strings.Join(users, ", ")),
+			map[string]interface{}{"tag_name": name, "used_by": users})
+	}
+
+	var inputs []string
+	seen := make(map[string]bool)
+	resolve := func(ref string) (exprKind, error) {
+		refKey := opts.Key(ref)
+		if refKey == key {
+			return 0, NewEipError(ErrInvalidTagName, fmt.Sprintf("virtual tag '%s' refers to itself", name))
+		}
+		if other, ok := c.virtual.tags[refKey]; ok {
+			for _, input := range other.Inputs {
+				if !seen[opts.Key(input)] {
+					seen[opts.Key(input)] = true
+					inputs = append(inputs, input)
+				}
+			}
+			return kindOf(other.Type), nil
+		}
+		dataType, ok := c.tagTypes.Lookup(ref)
+		if !ok {
+			return 0, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("no data type known for tag '%s'", ref),
+				map[string]interface{}{"tag_name": ref})
+		}
+		switch dataType {
+		case String, Udt, Dt, Ldt, Time:
+			return 0, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("tag '%s' of type %s cannot be used in an expression", ref, dataType),
+				map[string]interface{}{"tag_name": ref, "data_type": dataType.String()})
+		}
+		if !seen[refKey] {
+			seen[refKey] = true
+			inputs = append(inputs, ref)
+		}
+		return kindOf(dataType), nil
+	}
+	expr, err := parseExpression(expression, resolve)
+	if err != nil {
+		return err
+	}
+
+	group := c.NewTagGroup()
+	for _, input := range inputs {
+		dataType, _ := c.tagTypes.Lookup(input)
+		if err := group.Add(input, dataType); err != nil {
+			return err
+		}
+	}
+	resultType := Lreal
+	if expr.kind == kindBool {
+		resultType = Bool
+	}
+	if c.virtual.tags == nil {
+		c.virtual.tags = make(map[string]*virtualTag)
+	}
+	c.virtual.tags[key] = &virtualTag{
+		VirtualTag: VirtualTag{Name: name, Expression: expression, Type: resultType, Inputs: inputs},
+		expr:       expr,
+		group:      group,
+	}
+	c.tagTypes.Set(name, resultType)
+	return nil
+}
+
+// virtualUsers returns the names of the virtual tags whose expressions refer
+// to the virtual tag stored under key. Must be called with c.virtual.mu held.
+func (c *EipClient) virtualUsers(key string) []string {
+	opts := c.TagNameOptions()
+	var users []string
+	for _, tag := range c.virtual.tags {
+		for _, ref := range tag.expr.refs {
+			if opts.Key(ref) == key {
+				users = append(users, tag.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// RemoveVirtualTag removes a virtual tag and reports whether it was defined.
+// A virtual tag other virtual tags refer to cannot be removed.
+func (c *EipClient) RemoveVirtualTag(name string) (bool, error) {
+	key := c.TagNameOptions().Key(name)
+	c.virtual.mu.Lock()
+	defer c.virtual.mu.Unlock()
+	tag, ok := c.virtual.tags[key]
+	if !ok {
+		return false, nil
+	}
+	if users := c.virtualUsers(key); len(users) > 0 {
+		return false, NewEipErrorWithDetails(ErrInvalidOperation,
+			fmt.Sprintf("virtual tag '%s' is used by %s", tag.Name, strings.Join(users, ", ")),
+			map[string]interface{}{"tag_name": tag.Name, "used_by": users})
+	}
+	delete(c.virtual.tags, key)
+	return true, nil
+}
+
+// VirtualTags returns the defined virtual tags, sorted by name
+func (c *EipClient) VirtualTags() []VirtualTag {
+	c.virtual.mu.RLock()
+	defer c.virtual.mu.RUnlock()
+	tags := make([]VirtualTag, 0, len(c.virtual.tags))
+	for _, tag := range c.virtual.tags {
+		info := tag.VirtualTag
+		info.Inputs = append([]string(nil), tag.Inputs...)
+		tags = append(tags, info)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags
+}
+
+// readVirtual reads the inputs of a virtual tag and evaluates it. The value
+// is converted to dataType if it is not the virtual tag's own type.
+func (c *EipClient) readVirtual(tag *virtualTag, dataType PlcDataType) (*PlcValue, error) {
+	var values map[string]*PlcValue
+	if tag.group.Len() > 0 {
+		var err error
+		if values, err = tag.group.readAll(); err != nil {
+			return nil, err
+		}
+	}
+	opts := c.TagNameOptions()
+	env := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		env[opts.Key(name)] = value.Value
+	}
+
+	result, err := c.evalVirtual(tag, env, opts)
+	if err != nil {
+		return nil, err
+	}
+	if dataType == tag.Type {
+		return &PlcValue{Type: dataType, Value: result}, nil
+	}
+	if b, ok := result.(bool); ok {
+		result = 0.0
+		if b {
+			result = 1.0
+		}
+	}
+	return NewPlcValue(dataType, result)
+}
+
+// evalVirtual evaluates a virtual tag over the values of its inputs, keyed by
+// TagNameOptions.Key, evaluating the virtual tags it refers to on the way
+func (c *EipClient) evalVirtual(tag *virtualTag, env map[string]interface{}, opts TagNameOptions) (interface{}, error) {
+	return tag.expr.eval(func(ref string) (interface{}, error) {
+		key := opts.Key(ref)
+		if value, ok := env[key]; ok {
+			return value, nil
+		}
+		if other, ok := c.virtual.lookup(key); ok {
+			value, err := c.evalVirtual(other, env, opts)
+			if err == nil {
+				env[key] = value
+			}
+			return value, err
+		}
+		return nil, NewEipErrorWithDetails(ErrTagNotFound, fmt.Sprintf("no value read for '%s'", ref),
+			map[string]interface{}{"tag_name": ref, "virtual_tag": tag.Name})
+	})
+}
+
+// exprKind is the static type of an expression
+type exprKind int
+
+const (
+	kindNumber exprKind = iota
+	kindBool
+)
+
+func (k exprKind) String() string {
+	if k == kindBool {
+		return "boolean"
+	}
+	return "number"
+}
+
+// kindOf returns the expression type of a tag's values
+func kindOf(dataType PlcDataType) exprKind {
+	if dataType == Bool {
+		return kindBool
+	}
+	return kindNumber
+}
+
+// expression is a compiled virtual tag expression
+type expression struct {
+	kind exprKind
+	refs []string // Tag references, in order of first use
+	eval func(lookup func(ref string) (interface{}, error)) (interface{}, error)
+}
+
+// evalFunc evaluates a node of an expression
+type evalFunc = func(lookup func(ref string) (interface{}, error)) (interface{}, error)
+
+// parseExpression compiles src, calling resolve with every tag reference to
+// learn its type
+func parseExpression(src string, resolve func(ref string) (exprKind, error)) (*expression, error) {
+	node, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("invalid expression '%s': %v", src, err),
+			map[string]interface{}{"expression": src})
+	}
+	e := &expression{}
+	refs := make(map[string]bool)
+	kind, eval, err := compileNode(node, func(ref string) (exprKind, error) {
+		if !refs[ref] {
+			refs[ref] = true
+			e.refs = append(e.refs, ref)
+		}
+		return resolve(ref)
+	})
+	if err != nil {
+		if _, ok := err.(*EipError); ok {
+			return nil, err
+		}
+		return nil, NewEipErrorWithDetails(ErrInvalidValue, fmt.Sprintf("invalid expression '%s': %v", src, err),
+			map[string]interface{}{"expression": src})
+	}
+	e.kind, e.eval = kind, eval
+	return e, nil
+}
+
+// tagReference returns the tag name an identifier, member or element
+// expression refers to, e.g. "Motor.Speed" or "Temps[3]". Elements of
+// multi-dimensional arrays cannot be referenced.
+func tagReference(node ast.Expr) (string, bool) {
+	switch n := node.(type) {
+	case *ast.Ident:
+		return n.Name, n.Name != "true" && n.Name != "false"
+	case *ast.SelectorExpr:
+		base, ok := tagReference(n.X)
+		return base + "." + n.Sel.Name, ok
+	case *ast.IndexExpr:
+		base, ok := tagReference(n.X)
+		lit, isLit := n.Index.(*ast.BasicLit)
+		if !ok || !isLit || lit.Kind != token.INT {
+			return "", false
+		}
+		return base + "[" + lit.Value + "]", true
+	}
+	return "", false
+}
+
+// compileNode compiles an expression node and returns its type
+func compileNode(node ast.Expr, resolve func(ref string) (exprKind, error)) (exprKind, evalFunc, error) {
+	if ref, ok := tagReference(node); ok {
+		kind, err := resolve(ref)
+		if err != nil {
+			return 0, nil, err
+		}
+		return kind, func(lookup func(string) (interface{}, error)) (interface{}, error) {
+			value, err := lookup(ref)
+			if err != nil {
+				return nil, err
+			}
+			if kind == kindBool {
+				if b, ok := value.(bool); ok {
+					return b, nil
+				}
+			} else if f, ok := numericValue(value); ok {
+				return f, nil
+			}
+			return nil, NewEipErrorWithDetails(ErrInvalidDataType, fmt.Sprintf("'%s' read %v, expected a %s", ref, value, kind),
+				map[string]interface{}{"tag_name": ref})
+		}, nil
+	}
+
+	switch n := node.(type) {
+	case *ast.ParenExpr:
+		return compileNode(n.X, resolve)
+	case *ast.Ident:
+		b := n.Name == "true"
+		return kindBool, func(func(string) (interface{}, error)) (interface{}, error) { return b, nil }, nil
+	case *ast.BasicLit:
+		if n.Kind != token.INT && n.Kind != token.FLOAT {
+			return 0, nil, fmt.Errorf("unsupported literal %s", n.Value)
+		}
+		f, err := strconv.ParseFloat(n.Value, 64)
+		if err != nil {
+			i, intErr := strconv.ParseInt(n.Value, 0, 64)
+			if intErr != nil {
+				return 0, nil, fmt.Errorf("invalid number %s", n.Value)
+			}
+			f = float64(i)
+		}
+		return kindNumber, func(func(string) (interface{}, error)) (interface{}, error) { return f, nil }, nil
+	case *ast.UnaryExpr:
+		return compileUnary(n, resolve)
+	case *ast.BinaryExpr:
+		return compileBinary(n, resolve)
+	case *ast.CallExpr:
+		return compileCall(n, resolve)
+	}
+	return 0, nil, fmt.Errorf("unsupported expression at offset %d", node.Pos()-1)
+}
+
+// compileOperands compiles operands that must all be of kind want
+func compileOperands(op string, want exprKind, nodes []ast.Expr, resolve func(ref string) (exprKind, error)) ([]evalFunc, error) {
+	evals := make([]evalFunc, len(nodes))
+	for i, node := range nodes {
+		kind, eval, err := compileNode(node, resolve)
+		if err != nil {
+			return nil, err
+		}
+		if kind != want {
+			return nil, fmt.Errorf("%s needs %s operands, got a %s", op, want, kind)
+		}
+		evals[i] = eval
+	}
+	return evals, nil
+}
+
+// operands evaluates evals in order
+func operands(evals []evalFunc, lookup func(string) (interface{}, error)) ([]interface{}, error) {
+	values := make([]interface{}, len(evals))
+	for i, eval := range evals {
+		value, err := eval(lookup)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func compileUnary(n *ast.UnaryExpr, resolve func(ref string) (exprKind, error)) (exprKind, evalFunc, error) {
+	switch n.Op {
+	case token.SUB, token.ADD:
+		evals, err := compileOperands(n.Op.String(), kindNumber, []ast.Expr{n.X}, resolve)
+		if err != nil {
+			return 0, nil, err
+		}
+		negate := n.Op == token.SUB
+		return kindNumber, func(lookup func(string) (interface{}, error)) (interface{}, error) {
+			value, err := evals[0](lookup)
+			if err != nil || !negate {
+				return value, err
+			}
+			return -value.(float64), nil
+		}, nil
+	case token.NOT:
+		evals, err := compileOperands("!", kindBool, []ast.Expr{n.X}, resolve)
+		if err != nil {
+			return 0, nil, err
+		}
+		return kindBool, func(lookup func(string) (interface{}, error)) (interface{}, error) {
+			value, err := evals[0](lookup)
+			if err != nil {
+				return nil, err
+			}
+			return !value.(bool), nil
+		}, nil
+	}
+	return 0, nil, fmt.Errorf("unsupported operator %s", n.Op)
+}
+
+func compileBinary(n *ast.BinaryExpr, resolve func(ref string) (exprKind, error)) (exprKind, evalFunc, error) {
+	nodes := []ast.Expr{n.X, n.Y}
+	switch n.Op {
+	case token.LAND, token.LOR:
+		evals, err := compileOperands(n.Op.String(), kindBool, nodes, resolve)
+		if err != nil {
+			return 0, nil, err
+		}
+		and := n.Op == token.LAND
+		return kindBool, func(lookup func(string) (interface{}, error)) (interface{}, error) {
+			x, err := evals[0](lookup)
+			if err != nil {
+				return nil, err
+			}
+			if x.(bool) != and {
+				return x, nil
+			}
+			return evals[1](lookup)
+		}, nil
+
+	case token.EQL, token.NEQ:
+		xKind, _, err := compileNode(n.X, resolve)
+		if err != nil {
+			return 0, nil, err
+		}
+		evals, err := compileOperands(n.Op.String(), xKind, nodes, resolve)
+		if err != nil {
+			return 0, nil, err
+		}
+		equal := n.Op == token.EQL
+		return kindBool, func(lookup func(string) (interface{}, error)) (interface{}, error) {
+			values, err := operands(evals, lookup)
+			if err != nil {
+				return nil, err
+			}
+			return (values[0] == values[1]) == equal, nil
+		}, nil
+
+	case token.LSS, token.LEQ, token.GTR, token.GEQ:
+		evals, err := compileOperands(n.Op.String(), kindNumber, nodes, resolve)
+		if err != nil {
+			return 0, nil, err
+		}
+		op := n.Op
+		return kindBool, func(lookup func(string) (interface{}, error)) (interface{}, error) {
+			values, err := operands(evals, lookup)
+			if err != nil {
+				return nil, err
+			}
+			x, y := values[0].(float64), values[1].(float64)
+			switch op {
+			case token.LSS:
+				return x < y, nil
+			case token.LEQ:
+				return x <= y, nil
+			case token.GTR:
+				return x > y, nil
+			}
+			return x >= y, nil
+		}, nil
+
+	case token.ADD, token.SUB, token.MUL, token.QUO, token.REM:
+		evals, err := compileOperands(n.Op.String(), kindNumber, nodes, resolve)
+		if err != nil {
+			return 0, nil, err
+		}
+		op := n.Op
+		return kindNumber, func(lookup func(string) (interface{}, error)) (interface{}, error) {
+			values, err := operands(evals, lookup)
+			if err != nil {
+				return nil, err
+			}
+			x, y := values[0].(float64), values[1].(float64)
+			switch op {
+			case token.ADD:
+				return x + y, nil
+			case token.SUB:
+				return x - y, nil
+			case token.MUL:
+				return x * y, nil
+			case token.QUO:
+				return x / y, nil
+			}
+			return math.Mod(x, y), nil
+		}, nil
+	}
+	return 0, nil, fmt.Errorf("unsupported operator %s", n.Op)
+}
+
+func compileCall(n *ast.CallExpr, resolve func(ref string) (exprKind, error)) (exprKind, evalFunc, error) {
+	fn, ok := n.Fun.(*ast.Ident)
+	if !ok {
+		return 0, nil, fmt.Errorf("unsupported function call at offset %d", n.Pos()-1)
+	}
+	switch fn.Name {
+	case "abs":
+		if len(n.Args) != 1 {
+			return 0, nil, fmt.Errorf("abs takes one argument, got %d", len(n.Args))
+		}
+	case "min", "max":
+		if len(n.Args) < 1 {
+			return 0, nil, fmt.Errorf("%s takes at least one argument", fn.Name)
+		}
+	default:
+		return 0, nil, fmt.Errorf("unknown function %s", fn.Name)
+	}
+	evals, err := compileOperands(fn.Name, kindNumber, n.Args, resolve)
+	if err != nil {
+		return 0, nil, err
+	}
+	name := fn.Name
+	return kindNumber, func(lookup func(string) (interface{}, error)) (interface{}, error) {
+		values, err := operands(evals, lookup)
+		if err != nil {
+			return nil, err
+		}
+		result := values[0].(float64)
+		for _, value := range values[1:] {
+			if name == "min" {
+				result = math.Min(result, value.(float64))
+			} else {
+				result = math.Max(result, value.(float64))
+			}
+		}
+		if name == "abs" {
+			result = math.Abs(result)
+		}
+		return result, nil
+	}, nil
+}
@@ -0,0 +1,139 @@
+package ethernetip
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestParseExpression tests compiling and evaluating expressions
+func TestParseExpression(t *testing.T) {
+	kinds := map[string]exprKind{"Flow_A": kindNumber, "Flow_B": kindNumber, "Temp": kindNumber,
+		"Motor.Speed": kindNumber, "Temps[3]": kindNumber, "Grid[1]": kindNumber, "Running": kindBool}
+	env := map[string]interface{}{"Flow_A": float32(1.5), "Flow_B": int32(2), "Temp": 85.0,
+		"Motor.Speed": uint16(1200), "Temps[3]": int16(-4), "Grid[1]": int8(7), "Running": true}
+	resolve := func(ref string) (exprKind, error) {
+		kind, ok := kinds[ref]
+		if !ok {
+			return 0, NewEipError(ErrInvalidDataType, "unknown "+ref)
+		}
+		return kind, nil
+	}
+	lookup := func(ref string) (interface{}, error) { return env[ref], nil }
+
+	for _, tc := range []struct {
+		src  string
+		want interface{}
+		refs []string
+	}{
+		{"Flow_A + Flow_B", 3.5, []string{"Flow_A", "Flow_B"}},
+		{"Temp > 80", true, []string{"Temp"}},
+		{"Running && !(Temp >= 90) || false", true, []string{"Running", "Temp"}},
+		{"Motor.Speed / 60 - Temps[3] * 0.5", 22.0, []string{"Motor.Speed", "Temps[3]"}},
+		{"abs(Temps[3]) + max(Grid[1], 3, -1) + min(Flow_A, Flow_A)", 12.5, []string{"Temps[3]", "Grid[1]", "Flow_A"}},
+		{"-Flow_B % 3 == -2", true, []string{"Flow_B"}},
+		{"Running != true", false, []string{"Running"}},
+		{"0x10 + 1e1", 26.0, nil},
+	} {
+		expr, err := parseExpression(tc.src, resolve)
+		if err != nil {
+			t.Errorf("%s: %v", tc.src, err)
+			continue
+		}
+		got, err := expr.eval(lookup)
+		if err != nil || got != tc.want {
+			t.Errorf("%s = %v (%v), expected %v", tc.src, got, err, tc.want)
+		}
+		if !reflect.DeepEqual(expr.refs, tc.refs) {
+			t.Errorf("%s: references %v, expected %v", tc.src, expr.refs, tc.refs)
+		}
+	}
+
+	for _, src := range []string{"Flow_A +", "Flow_A + Running", "Running > 1", "!Temp", `"text"`, "sqrt(Temp)", "Temps[Flow_A]", "Missing * 2", "abs(1, 2)", "Grid[1,2]"} {
+		if _, err := parseExpression(src, resolve); err == nil {
+			t.Errorf("%s: expected an error", src)
+		}
+	}
+
+	expr, _ := parseExpression("Flow_A > 1", resolve)
+	if _, err := expr.eval(func(string) (interface{}, error) { return "text", nil }); err == nil {
+		t.Error("Expected a non-numeric value to fail")
+	}
+}
+
+// TestDefineVirtualTag tests defining, evaluating and removing virtual tags
+func TestDefineVirtualTag(t *testing.T) {
+	c := &EipClient{}
+	for name, dataType := range map[string]PlcDataType{"Flow_A": Real, "Flow_B": Real, "Temp": Dint, "Label": String} {
+		c.TagTypes().Set(name, dataType)
+	}
+	code := func(err error) int {
+		var eipErr *EipError
+		if errors.As(err, &eipErr) {
+			return eipErr.Code
+		}
+		return 0
+	}
+
+	if err := c.DefineVirtualTag("Flow_Total", "Flow_A + Flow_B"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DefineVirtualTag("Alarm", "Temp > 80"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DefineVirtualTag("Trip", "Alarm && Flow_Total > 10 && Flow_A > 1"); err != nil {
+		t.Fatal(err)
+	}
+	tags := c.VirtualTags()
+	if len(tags) != 3 || tags[2].Name != "Trip" || tags[2].Type != Bool || !reflect.DeepEqual(tags[2].Inputs, []string{"Temp", "Flow_A", "Flow_B"}) {
+		t.Errorf("Unexpected virtual tags %+v", tags)
+	}
+	if dataType, ok := c.TagTypes().Lookup("Flow_Total"); !ok || dataType != Lreal {
+		t.Errorf("Expected the virtual tag's type in TagTypes, got %v", dataType)
+	}
+
+	trip, _ := c.virtualTag("Trip")
+	env := map[string]interface{}{"Temp": int32(85), "Flow_A": float32(6), "Flow_B": float32(5)}
+	if value, err := c.evalVirtual(trip, env, c.TagNameOptions()); err != nil || value != true {
+		t.Errorf("Expected Trip to be true, got %v (%v)", value, err)
+	}
+
+	for _, tc := range []struct {
+		name, expression string
+		code             int
+	}{
+		{"Bad", "Unknown + 1", ErrInvalidDataType},
+		{"Bad", "Label + 1", ErrInvalidDataType},
+		{"Bad", "Bad * 2", ErrInvalidTagName},
+		{"Bad", "Flow_A +", ErrInvalidValue},
+		{"Alarm", "Temp > 90", ErrInvalidOperation}, // Trip refers to it
+		{"", "1", ErrInvalidTagName},
+	} {
+		if err := c.DefineVirtualTag(tc.name, tc.expression); code(err) != tc.code {
+			t.Errorf("%s = %s: expected error code %d, got %v", tc.name, tc.expression, tc.code, err)
+		}
+	}
+	if removed, err := c.RemoveVirtualTag("Alarm"); removed || code(err) != ErrInvalidOperation {
+		t.Errorf("Expected a virtual tag in use not to be removed, got %v %v", removed, err)
+	}
+	if removed, err := c.RemoveVirtualTag("Trip"); !removed || err != nil {
+		t.Errorf("Expected Trip to be removed, got %v %v", removed, err)
+	}
+	if err := c.DefineVirtualTag("Alarm", "Temp > 90"); err != nil {
+		t.Errorf("Expected Alarm to be redefined once unused, got %v", err)
+	}
+
+	if err := c.WriteValue("Flow_Total", &PlcValue{Type: Lreal, Value: 1.0}); code(err) != ErrInvalidTagAccess {
+		t.Errorf("Expected virtual tags to be read-only, got %v", err)
+	}
+
+	if err := c.DefineVirtualTag("Limit", "max(2, 3) * 10"); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := c.ReadTag("Limit"); err != nil || value.Type != Lreal || value.Value != 30.0 {
+		t.Errorf("Expected Limit 30, got %+v (%v)", value, err)
+	}
+	if value, err := c.ReadValue("Limit", Dint); err != nil || value.Value != int32(30) {
+		t.Errorf("Expected Limit read as DINT 30, got %+v (%v)", value, err)
+	}
+}
@@ -0,0 +1,86 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// Wall Clock Time object of Logix controllers and its attributes, both
+// microseconds since 1970-01-01 UTC as a ULINT
+const (
+	cipClassWallClockTime uint16 = 0x8B
+	// wallClockAttrCurrentValue is written to set the clock
+	wallClockAttrCurrentValue uint16 = 0x06
+	// wallClockAttrUTCValue is read for the current UTC time
+	wallClockAttrUTCValue uint16 = 0x0B
+)
+
+// GetControllerClock reads the controller's wall clock, in UTC
+func (c *EipClient) GetControllerClock() (time.Time, error) {
+	values, err := c.getAttributeList(cipClassWallClockTime, 1, []attributeSpec{{wallClockAttrUTCValue, 8}})
+	if err != nil {
+		return time.Time{}, err
+	}
+	v, ok := values[wallClockAttrUTCValue]
+	if !ok {
+		return time.Time{}, NewEipError(ErrInvalidOperation, "the controller did not report its wall clock")
+	}
+	return time.UnixMicro(int64(binary.LittleEndian.Uint64(v))).UTC(), nil
+}
+
+// SetControllerClock sets the controller's wall clock to t. The controller
+// keeps microseconds; finer precision is dropped. Read-only clients are
+// refused like any other write.
+func (c *EipClient) SetControllerClock(t time.Time) error {
+	if t.Before(time.Unix(0, 0)) {
+		return NewEipErrorWithDetails(ErrInvalidValue, "the controller clock cannot be set before 1970",
+			map[string]interface{}{"time": t})
+	}
+	_, err := c.SendCIPMessage(CIPServiceSetAttributeList, classInstancePath(cipClassWallClockTime, 1), wallClockRequest(t))
+	return err
+}
+
+// wallClockRequest encodes a Set Attribute List request for the current value
+// of the wall clock
+func wallClockRequest(t time.Time) []byte {
+	request := binary.LittleEndian.AppendUint16(nil, 1)
+	request = binary.LittleEndian.AppendUint16(request, wallClockAttrCurrentValue)
+	return binary.LittleEndian.AppendUint64(request, uint64(t.UnixMicro()))
+}
+
+// ControllerClockDrift returns how far the controller's clock is ahead of the
+// client's (negative when it is behind). The controller's time is compared
+// with the midpoint of the request, so the network round trip does not count
+// as drift.
+func (c *EipClient) ControllerClockDrift() (time.Duration, error) {
+	clock := c.Clock()
+	sent := clock.Now()
+	controller, err := c.GetControllerClock()
+	if err != nil {
+		return 0, err
+	}
+	received := clock.Now()
+	return clockDrift(controller, sent, received), nil
+}
+
+// clockDrift returns controller minus the midpoint of sent and received
+func clockDrift(controller, sent, received time.Time) time.Duration {
+	return controller.Sub(sent.Add(received.Sub(sent) / 2))
+}
+
+// SyncControllerClock sets the controller's clock to the client's when it has
+// drifted by more than tolerance, and returns the drift measured before
+// setting it. Gateways call it periodically to keep PLC timestamps in step
+// with the rest of the plant.
+func (c *EipClient) SyncControllerClock(tolerance time.Duration) (time.Duration, error) {
+	drift, err := c.ControllerClockDrift()
+	if err != nil {
+		return 0, err
+	}
+	if drift > tolerance || drift < -tolerance {
+		if err := c.SetControllerClock(c.Clock().Now()); err != nil {
+			return drift, err
+		}
+	}
+	return drift, nil
+}
@@ -0,0 +1,32 @@
+package ethernetip
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// TestWallClockRequest tests encoding the Set Attribute List request
+func TestWallClockRequest(t *testing.T) {
+	at := time.Date(2024, 6, 30, 12, 0, 0, 123456789, time.UTC)
+	want := []byte{0x01, 0x00, 0x06, 0x00, 0x40, 0x92, 0x55, 0x38, 0x1A, 0x1C, 0x06, 0x00}
+	if got := wallClockRequest(at); !bytes.Equal(got, want) {
+		t.Errorf("Expected % X, got % X", want, got)
+	}
+
+	if err := (&EipClient{}).SetControllerClock(time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("Expected an error for a time before 1970")
+	}
+}
+
+// TestClockDrift tests that drift is measured from the middle of the request
+func TestClockDrift(t *testing.T) {
+	sent := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	received := sent.Add(40 * time.Millisecond)
+	if drift := clockDrift(sent.Add(20*time.Millisecond), sent, received); drift != 0 {
+		t.Errorf("Expected no drift, got %v", drift)
+	}
+	if drift := clockDrift(sent.Add(-2*time.Second), sent, received); drift != -2020*time.Millisecond {
+		t.Errorf("Expected -2.02s, got %v", drift)
+	}
+}
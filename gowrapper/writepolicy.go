@@ -0,0 +1,238 @@
+package ethernetip
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/sergiogallegos/rust-ethernet-ip/gowrapper/tagpath"
+)
+
+// WritePolicy restricts which tags the client may write. Writes the policy
+// rejects fail with ErrInvalidTagAccess before anything is sent to the
+// controller. The zero value allows every write.
+//
+// Patterns are tag names in which * matches any run of characters and ?
+// matches one, e.g. "Program:Safety.*" or "Line?_Setpoint". A pattern that
+// matches a tag also matches its members, elements and bits, so "Recipe"
+// covers "Recipe.Speed" and "Recipe.Steps[3]". Names are compared with the
+// client's TagNameOptions.
+type WritePolicy struct {
+	// ReadOnly rejects every write, including raw CIP requests other than
+	// reads (see SendCIPRequest)
+	ReadOnly bool `json:"read_only"`
+	// Allow, if not empty, limits writes to tags matching one of its
+	// patterns
+	Allow []string `json:"allow,omitempty"`
+	// Deny rejects writes to tags matching one of its patterns, even if
+	// Allow matches them too
+	Deny []string `json:"deny,omitempty"`
+	// CheckExternalAccess rejects writes to tags whose External Access is
+	// Read Only or None, as reported by their metadata (see
+	// GetTagMetadataCached), instead of sending them for the controller to
+	// refuse. Tags whose metadata cannot be read are left to the controller.
+	CheckExternalAccess bool `json:"check_external_access,omitempty"`
+}
+
+// SetWritePolicy restricts the tags the client may write; the zero
+// WritePolicy lifts all restrictions. The policy applies to every write
+// method, packed and batch writes, recipes and snapshots, and a WriteQueue
+// built on the client. Rejected writes are recorded in the audit log.
+func (c *EipClient) SetWritePolicy(policy WritePolicy) {
+	policy.Allow = append([]string(nil), policy.Allow...)
+	policy.Deny = append([]string(nil), policy.Deny...)
+	c.writePolicy.Store(&policy)
+}
+
+// WritePolicy returns the client's write policy
+func (c *EipClient) WritePolicy() WritePolicy {
+	p := c.writePolicy.Load()
+	if p == nil {
+		return WritePolicy{}
+	}
+	return WritePolicy{
+		ReadOnly:            p.ReadOnly,
+		Allow:               append([]string(nil), p.Allow...),
+		Deny:                append([]string(nil), p.Deny...),
+		CheckExternalAccess: p.CheckExternalAccess,
+	}
+}
+
+// CheckWrite reports whether the write policy allows writing tagName,
+// returning the ErrInvalidTagAccess error a write would fail with if not.
+// With a target expectation set, it also verifies the controller (see
+// VerifyTarget).
+func (c *EipClient) CheckWrite(tagName string) error {
+	if err := c.checkTarget(); err != nil {
+		return err
+	}
+	p := c.writePolicy.Load()
+	if p == nil {
+		return nil
+	}
+	denied := func(rule string) error {
+		return NewEipErrorWithDetails(ErrInvalidTagAccess, fmt.Sprintf("write to '%s' denied by %s", tagName, rule),
+			map[string]interface{}{"tag_name": tagName, "rule": rule})
+	}
+	if p.ReadOnly {
+		return denied("read-only mode")
+	}
+	names := c.TagNameOptions()
+	for _, pattern := range p.Deny {
+		if tagPatternMatch(names, pattern, tagName) {
+			return denied(fmt.Sprintf("deny pattern '%s'", pattern))
+		}
+	}
+	if len(p.Allow) > 0 && !allowListed(names, p.Allow, tagName) {
+		return denied("allow list")
+	}
+	if p.CheckExternalAccess {
+		return c.checkExternalAccess(tagName)
+	}
+	return nil
+}
+
+// allowListed reports whether one of the allow patterns matches tagName
+func allowListed(names TagNameOptions, allow []string, tagName string) bool {
+	for _, pattern := range allow {
+		if tagPatternMatch(names, pattern, tagName) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkExternalAccess rejects writes to tagName if the External Access of
+// the tag it belongs to does not allow them
+func (c *EipClient) checkExternalAccess(tagName string) error {
+	root, ok := writtenTag(tagName)
+	if !ok {
+		return nil
+	}
+	meta, err := c.GetTagMetadataCached(root)
+	if err != nil || meta.ExternalAccess.CanWrite() {
+		return nil
+	}
+	return NewEipErrorWithDetails(ErrInvalidTagAccess,
+		fmt.Sprintf("write to '%s' denied: external access of '%s' is %s", tagName, root, meta.ExternalAccess),
+		map[string]interface{}{"tag_name": tagName, "rule": "external access", "external_access": string(meta.ExternalAccess)})
+}
+
+// writtenTag returns the tag a write to name changes: the tag itself for
+// its members, elements and bits, and for array slices labelled "Tag[2..5]"
+func writtenTag(name string) (string, bool) {
+	path, err := tagpath.Parse(name)
+	if err != nil {
+		i := strings.LastIndexByte(name, '[')
+		if i < 0 {
+			return "", false
+		}
+		if path, err = tagpath.Parse(name[:i]); err != nil {
+			return "", false
+		}
+	}
+	return path.Tag(), true
+}
+
+// permitted returns write guarded by the write policy for tagName
+func (c *EipClient) permitted(tagName string, write func() error) func() error {
+	return func() error {
+		if err := c.CheckWrite(tagName); err != nil {
+			return err
+		}
+		return write()
+	}
+}
+
+// tagPatternMatch reports whether pattern matches tagName or one of the
+// tags it is a member, element or bit of
+func tagPatternMatch(names TagNameOptions, pattern, tagName string) bool {
+	pattern, tagName = names.Key(pattern), names.Key(tagName)
+	if globMatch(pattern, tagName) {
+		return true
+	}
+	for i := 1; i < len(tagName); i++ {
+		if (tagName[i] == '.' || tagName[i] == '[') && globMatch(pattern, tagName[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch matches name against a pattern of literal characters, * and ?
+func globMatch(pattern, name string) bool {
+	// Backtrack to the most recent * on a mismatch
+	p, n, star, resume := 0, 0, -1, 0
+	for n < len(name) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == name[n]):
+			p++
+			n++
+		case p < len(pattern) && pattern[p] == '*':
+			star, resume = p, n
+			p++
+		case star >= 0:
+			resume++
+			p, n = star+1, resume
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// cipReadServices are the services a read-only client may send raw
+var cipReadServices = map[byte]bool{
+	CIPServiceGetAttributesAll:         true,
+	CIPServiceGetAttributeList:         true,
+	CIPServiceGetAttributeSingle:       true,
+	CIPServiceReadTag:                  true,
+	CIPServiceReadTagFragmented:        true,
+	CIPServiceGetInstanceAttributeList: true,
+}
+
+// checkCIPRequest rejects a raw CIP request that is not a read while the
+// client is read-only, or before the controller is verified against the
+// target expectation. Multiple Service Packets pass if every embedded
+// request is a read.
+func (c *EipClient) checkCIPRequest(request []byte) error {
+	if cipReadRequest(request) {
+		return nil
+	}
+	if err := c.checkTarget(); err != nil {
+		return err
+	}
+	if p := c.writePolicy.Load(); p == nil || !p.ReadOnly {
+		return nil
+	}
+	return NewEipErrorWithDetails(ErrInvalidTagAccess, fmt.Sprintf("CIP service 0x%02X denied by read-only mode", request[0]),
+		map[string]interface{}{"service": request[0], "rule": "read-only mode"})
+}
+
+// cipReadRequest reports whether request only reads
+func cipReadRequest(request []byte) bool {
+	if len(request) < 2 || 2+2*int(request[1]) > len(request) {
+		return false
+	}
+	if request[0] != CIPServiceMultipleServicePacket {
+		return cipReadServices[request[0]]
+	}
+	data := request[2+2*int(request[1]):]
+	if len(data) < 2 {
+		return false
+	}
+	count := int(binary.LittleEndian.Uint16(data))
+	if count == 0 || len(data) < 2+2*count {
+		return false
+	}
+	for i := 0; i < count; i++ {
+		offset := int(binary.LittleEndian.Uint16(data[2+2*i:]))
+		if offset >= len(data) || data[offset] == CIPServiceMultipleServicePacket || !cipReadRequest(data[offset:]) {
+			return false
+		}
+	}
+	return true
+}
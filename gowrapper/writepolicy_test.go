@@ -0,0 +1,158 @@
+package ethernetip
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestTagPatternMatch tests wildcards, member matching and name options
+func TestTagPatternMatch(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, tagName string
+		want             bool
+	}{
+		{"Program:Safety.*", "Program:Safety.EStop", true},
+		{"Program:Safety.*", "Program:SafetyOld.EStop", false},
+		{"Program:Safety.*", "Program:Main.EStop", false},
+		{"Recipe", "Recipe.Steps[3].Speed", true},
+		{"Recipe", "RecipeName", false},
+		{"Line?_Setpoint", "Line2_Setpoint", true},
+		{"Line?_Setpoint", "Line12_Setpoint", false},
+		{"*_SP", "Motor1_SP.5", true},
+		{"Arr[2]", "Arr[2].Value", true},
+		{"Arr[2]", "Arr[3]", false},
+		{"*", "Anything", true},
+	} {
+		if got := tagPatternMatch(TagNameOptions{}, tc.pattern, tc.tagName); got != tc.want {
+			t.Errorf("tagPatternMatch(%q, %q) = %v, want %v", tc.pattern, tc.tagName, got, tc.want)
+		}
+	}
+	if tagPatternMatch(TagNameOptions{}, "program:safety.*", "Program:Safety.EStop") {
+		t.Error("Expected exact names to be case-sensitive")
+	}
+	if !tagPatternMatch(LogixTagNames, "program:safety.*", "Program:Safety.EStop") {
+		t.Error("Expected Logix names to match regardless of case")
+	}
+}
+
+// TestWritePolicy tests that rejected writes fail before reaching the PLC
+// and are recorded in the audit log
+func TestWritePolicy(t *testing.T) {
+	var log bytes.Buffer
+	client := &EipClient{}
+	client.SetAuditSink(NewAuditWriter(&log), AuditOptions{})
+	client.SetWritePolicy(WritePolicy{Allow: []string{"Line1.*", "Speed"}, Deny: []string{"Line1.Safety*"}})
+
+	var eipErr *EipError
+	denied := map[string]func() error{
+		"not allowed":   func() error { return client.WriteDint("Count", 1) },
+		"deny pattern":  func() error { return client.WriteBool("Line1.SafetyOK", true) },
+		"bit of denied": func() error { return client.WriteBit("Line1.SafetyFlags", 3, true) },
+		"batch":         func() error { return client.BatchWrite(map[string]interface{}{"Speed": 1, "Count": 2}) },
+		"value":         func() error { return client.WriteValue("Count", &PlcValue{Type: Dint, Value: int32(1)}) },
+	}
+	for name, write := range denied {
+		if err := write(); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagAccess {
+			t.Errorf("%s: expected ErrInvalidTagAccess, got %v", name, err)
+		}
+	}
+	// Allowed writes reach the (missing) PLC and fail differently
+	if err := client.WriteDint("Speed.Max", 1); errors.As(err, &eipErr) && eipErr.Code == ErrInvalidTagAccess {
+		t.Errorf("Expected a member of an allowed tag to be written, got %v", err)
+	}
+	if n := strings.Count(log.String(), "denied by"); n != 6 {
+		t.Errorf("Expected the 6 rejected writes in the audit log, got %d:\n%s", n, log.String())
+	}
+
+	errs := client.writePacked([]packedWrite{{tagName: "Count"}, {tagName: "Line1.Speed"}})
+	if !errors.As(errs[0], &eipErr) || eipErr.Code != ErrInvalidTagAccess {
+		t.Errorf("Expected the packed write to Count to be rejected, got %v", errs[0])
+	}
+	if errors.As(errs[1], &eipErr) && eipErr.Code == ErrInvalidTagAccess {
+		t.Errorf("Expected the packed write to Line1.Speed to be sent, got %v", errs[1])
+	}
+
+	client.SetWritePolicy(WritePolicy{ReadOnly: true})
+	if err := client.WriteDint("Speed", 1); !errors.As(err, &eipErr) || eipErr.Details["rule"] != "read-only mode" {
+		t.Errorf("Expected read-only mode to reject the write, got %v", err)
+	}
+	client.SetWritePolicy(WritePolicy{})
+	if err := client.CheckWrite("Count"); err != nil {
+		t.Errorf("Expected the zero policy to allow writes, got %v", err)
+	}
+}
+
+// TestReadOnlyCIP tests that a read-only client sends only raw read requests
+func TestReadOnlyCIP(t *testing.T) {
+	client := &EipClient{}
+	client.SetWritePolicy(WritePolicy{ReadOnly: true})
+	path, _ := tagRequestPath("Speed")
+	read := append([]byte{CIPServiceReadTag, byte(len(path) / 2)}, append(path, 0x01, 0x00)...)
+	write := append([]byte{CIPServiceWriteTag, byte(len(path) / 2)}, append(path, 0xC4, 0x00, 0x01, 0x00, 1, 0, 0, 0)...)
+
+	packet := func(requests ...[]byte) []byte {
+		router := []byte{CIPServiceMultipleServicePacket, 0x02, 0x20, 0x02, 0x24, 0x01, byte(len(requests)), 0x00}
+		offset := 2 + 2*len(requests)
+		for _, r := range requests {
+			router = append(router, byte(offset), byte(offset>>8))
+			offset += len(r)
+		}
+		for _, r := range requests {
+			router = append(router, r...)
+		}
+		return router
+	}
+
+	for name, tc := range map[string]struct {
+		request []byte
+		read    bool
+	}{
+		"read":            {read, true},
+		"write":           {write, false},
+		"packed reads":    {packet(read, read), true},
+		"packed write":    {packet(read, write), false},
+		"truncated":       {packet(read)[:9], false},
+		"set attribute":   {[]byte{CIPServiceSetAttributeSingle, 0x03, 0x20, 0x01, 0x24, 0x01, 0x30, 0x01, 0x00}, false},
+		"attribute query": {[]byte{CIPServiceGetAttributeSingle, 0x03, 0x20, 0x01, 0x24, 0x01, 0x30, 0x01}, true},
+	} {
+		if got := cipReadRequest(tc.request); got != tc.read {
+			t.Errorf("%s: cipReadRequest = %v, want %v", name, got, tc.read)
+		}
+	}
+
+	var eipErr *EipError
+	if _, err := client.SendCIPRequest(write); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagAccess {
+		t.Errorf("Expected the raw write to be rejected, got %v", err)
+	}
+}
+
+// TestWritePolicyExternalAccess tests rejecting writes to tags the
+// controller exposes read-only
+func TestWritePolicyExternalAccess(t *testing.T) {
+	client := &EipClient{tagNames: LogixTagNames}
+	client.SeedTagMetadata(map[string]*TagMetadata{
+		"Program:Main.Limits": {ExternalAccess: AccessReadOnly},
+		"Hidden":              {ExternalAccess: AccessNone},
+		"Speed":               {ExternalAccess: AccessReadWrite},
+		"Values":              {ArrayDimension: 1, ArraySize: 10, ExternalAccess: AccessReadOnly},
+	})
+	client.SetWritePolicy(WritePolicy{CheckExternalAccess: true})
+
+	var eipErr *EipError
+	for _, tagName := range []string{"program:main.Limits.High", "Hidden", "Hidden.3", "Values[2..4]"} {
+		if err := client.CheckWrite(tagName); !errors.As(err, &eipErr) || eipErr.Details["rule"] != "external access" {
+			t.Errorf("%s: expected the external access to reject the write, got %v", tagName, err)
+		}
+	}
+	if err := client.CheckWrite("Speed"); err != nil {
+		t.Errorf("Expected a Read/Write tag to be allowed, got %v", err)
+	}
+	if err := client.WriteArraySlice("Values", 2, Dint, []interface{}{1}); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagAccess {
+		t.Errorf("Expected the slice write to be rejected, got %v", err)
+	}
+	if !client.WritePolicy().CheckExternalAccess {
+		t.Error("Expected the policy to keep CheckExternalAccess")
+	}
+}
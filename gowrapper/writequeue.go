@@ -0,0 +1,625 @@
+package ethernetip
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ConflictPolicy decides how queued writes to the same tag are replayed
+type ConflictPolicy int
+
+const (
+	// ConflictReplayAll replays every queued write in the order it was issued
+	ConflictReplayAll ConflictPolicy = iota
+	// ConflictLastWriteWins replays only the most recent write for each tag
+	ConflictLastWriteWins
+)
+
+// QueuedWrite is a write held by a WriteQueue until the PLC is reachable
+type QueuedWrite struct {
+	Seq      uint64      `json:"seq"`
+	TagName  string      `json:"tag_name"`
+	DataType PlcDataType `json:"data_type"`
+	Value    interface{} `json:"value"`
+	QueuedAt time.Time   `json:"queued_at"`
+}
+
+// MarshalJSON implements json.Marshaler. JSON has no NaN or infinities, so
+// non-finite REAL and LREAL values are encoded as the strings "NaN", "+Inf"
+// and "-Inf". TIME values are encoded as duration strings, since a bare
+// number would be read back as the LINT microseconds.
+func (w QueuedWrite) MarshalJSON() ([]byte, error) {
+	type plain QueuedWrite
+	if f, ok := nonFinite(w.Value); ok && (w.DataType == Real || w.DataType == Lreal) {
+		w.Value = strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	if d, ok := w.Value.(time.Duration); ok && w.DataType == Time {
+		w.Value = d.String()
+	}
+	return json.Marshal(plain(w))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The value gets back the Go type
+// of its data type, which JSON decoding loses, and integers are decoded
+// exactly.
+func (w *QueuedWrite) UnmarshalJSON(data []byte) error {
+	type plain QueuedWrite
+	var decoded plain
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return err
+	}
+	*w = QueuedWrite(decoded)
+	return restoreQueuedValue(w)
+}
+
+// WriteStore persists queued writes. Implementations must be safe for use by a
+// single WriteQueue; the queue serializes calls.
+type WriteStore interface {
+	// Save replaces the stored queue with writes
+	Save(writes []QueuedWrite) error
+	// Load returns the stored queue in order
+	Load() ([]QueuedWrite, error)
+}
+
+// AppendWriteStore is a WriteStore that can add a write to the stored queue
+// without rewriting it. A WriteQueue calls Append for every write it queues,
+// and Save only when writes leave the queue.
+type AppendWriteStore interface {
+	WriteStore
+	// Append adds write to the end of the stored queue
+	Append(write QueuedWrite) error
+}
+
+// MemoryWriteStore is a non-durable WriteStore
+type MemoryWriteStore struct {
+	writes []QueuedWrite
+}
+
+// Save implements WriteStore
+func (m *MemoryWriteStore) Save(writes []QueuedWrite) error {
+	m.writes = append([]QueuedWrite(nil), writes...)
+	return nil
+}
+
+// Load implements WriteStore
+func (m *MemoryWriteStore) Load() ([]QueuedWrite, error) {
+	return append([]QueuedWrite(nil), m.writes...), nil
+}
+
+// FileWriteStore persists the queue as a log of JSON lines, one per queued
+// write. Queuing a write appends and syncs one line, so it costs the same
+// however long the queue is. When writes leave the queue the log is
+// rewritten to a temporary file, synced and renamed over the previous one,
+// so the queue survives power loss and process restarts. A line cut short by
+// a crash is ignored. Writes are encoded as by QueuedWrite.MarshalJSON, so
+// non-finite REAL and LREAL values are kept.
+type FileWriteStore struct {
+	path string
+}
+
+// NewFileWriteStore creates a store backed by the file at path
+func NewFileWriteStore(path string) *FileWriteStore {
+	return &FileWriteStore{path: path}
+}
+
+// Append implements AppendWriteStore
+func (f *FileWriteStore) Append(write QueuedWrite) error {
+	line, err := marshalQueuedWrite(write)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open queue file: %v", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write queue file: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync queue file: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close queue file: %v", err)
+	}
+	return nil
+}
+
+// Save implements WriteStore
+func (f *FileWriteStore) Save(writes []QueuedWrite) error {
+	var data []byte
+	for _, w := range writes {
+		line, err := marshalQueuedWrite(w)
+		if err != nil {
+			return err
+		}
+		data = append(data, line...)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create write queue file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write queue file: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync queue file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close queue file: %v", err)
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// Load implements WriteStore. A queue saved as a single JSON array, as
+// earlier versions did, is read and rewritten as a log.
+func (f *FileWriteStore) Load() ([]QueuedWrite, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue file: %v", err)
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var writes []QueuedWrite
+		if err := json.Unmarshal(trimmed, &writes); err != nil {
+			return nil, fmt.Errorf("failed to parse queue file: %v", err)
+		}
+		return writes, f.Save(writes)
+	}
+
+	var writes []QueuedWrite
+	for len(data) > 0 {
+		line, rest, complete := bytes.Cut(data, []byte("\n"))
+		data = rest
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		w, err := unmarshalQueuedWrite(line)
+		if err != nil {
+			if !complete {
+				// The last append was cut short and never acknowledged
+				break
+			}
+			return nil, err
+		}
+		writes = append(writes, w)
+	}
+	return writes, nil
+}
+
+// marshalQueuedWrite encodes a write as a line of the queue file
+func marshalQueuedWrite(w QueuedWrite) ([]byte, error) {
+	line, err := json.Marshal(w)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal queued write to %s: %v", w.TagName, err)
+	}
+	return append(line, '\n'), nil
+}
+
+// unmarshalQueuedWrite decodes a line of the queue file
+func unmarshalQueuedWrite(line []byte) (QueuedWrite, error) {
+	var w QueuedWrite
+	if err := json.Unmarshal(line, &w); err != nil {
+		return QueuedWrite{}, fmt.Errorf("failed to parse queue file: %v", err)
+	}
+	return w, nil
+}
+
+// nonFinite returns v as a float64 if it is a NaN or an infinity
+func nonFinite(v interface{}) (float64, bool) {
+	var f float64
+	switch n := v.(type) {
+	case float32:
+		f = float64(n)
+	case float64:
+		f = n
+	default:
+		return 0, false
+	}
+	return f, math.IsNaN(f) || math.IsInf(f, 0)
+}
+
+// restoreQueuedValue gives a decoded write's value back its Go type, which
+// JSON decoding loses
+func restoreQueuedValue(w *QueuedWrite) error {
+	if s, ok := w.Value.(string); ok && (w.DataType == Real || w.DataType == Lreal) {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid queued value for tag %s: %v", w.TagName, err)
+		}
+		w.Value = f
+		return nil
+	}
+	value, err := coerceValue(w.DataType, w.Value)
+	if err != nil {
+		return fmt.Errorf("invalid queued value for tag %s: %v", w.TagName, err)
+	}
+	w.Value = value
+	return nil
+}
+
+// WriteQueueOptions configures a WriteQueue
+type WriteQueueOptions struct {
+	// Store persists the queue; defaults to a MemoryWriteStore
+	Store WriteStore
+	// Conflict selects how writes to the same tag are replayed
+	Conflict ConflictPolicy
+	// MaxAge drops queued writes older than this when flushing; 0 keeps them forever
+	MaxAge time.Duration
+	// IsOutage reports whether a write error means the PLC is unreachable, in
+	// which case the write is queued. Defaults to connection and timeout errors.
+	IsOutage func(err error) bool
+}
+
+// FlushResult summarizes a Flush
+type FlushResult struct {
+	Written   int           // Writes delivered to the PLC
+	Dropped   []QueuedWrite // Writes discarded as expired, superseded or rejected by the PLC
+	Remaining int           // Writes still queued
+}
+
+// WriteQueue is a store-and-forward queue for writes: writes issued while the
+// PLC is unreachable are persisted and replayed in order once it is back.
+type WriteQueue struct {
+	client Client
+	opts   WriteQueueOptions
+
+	mu      sync.Mutex
+	pending []QueuedWrite
+	nextSeq uint64
+}
+
+// NewWriteQueue creates a write queue for client, restoring any writes left in
+// the store by a previous run
+func NewWriteQueue(client Client, opts WriteQueueOptions) (*WriteQueue, error) {
+	if opts.Store == nil {
+		opts.Store = &MemoryWriteStore{}
+	}
+	if opts.IsOutage == nil {
+		opts.IsOutage = isOutageError
+	}
+
+	pending, err := opts.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	q := &WriteQueue{client: client, opts: opts, pending: pending}
+	for _, w := range pending {
+		if w.Seq >= q.nextSeq {
+			q.nextSeq = w.Seq + 1
+		}
+	}
+	return q, nil
+}
+
+// isOutageError is the default WriteQueueOptions.IsOutage
+func isOutageError(err error) bool {
+	if eipErr, ok := err.(*EipError); ok {
+		return eipErr.IsConnectionError() || eipErr.IsTimeoutError()
+	}
+	return false
+}
+
+// Write writes value to the PLC, or queues it if the PLC is unreachable or
+// earlier writes are still queued (so ordering is preserved). It reports
+// whether the write was queued. Errors that do not indicate an outage are
+// returned without queuing. Only values a store can replay exactly are
+// accepted: UDT, array and other values fail with ErrInvalidDataType or
+// ErrInvalidTagValue, whether or not the PLC is reachable.
+func (q *WriteQueue) Write(tagName string, value *PlcValue) (queued bool, err error) {
+	if err := checkQueueable(tagName, value); err != nil {
+		return false, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending) == 0 {
+		err := q.client.WriteValue(tagName, value)
+		if err == nil {
+			return false, nil
+		}
+		if !q.opts.IsOutage(err) {
+			return false, err
+		}
+	}
+
+	q.pending = append(q.pending, QueuedWrite{
+		Seq:      q.nextSeq,
+		TagName:  tagName,
+		DataType: value.Type,
+		Value:    value.Value,
+		QueuedAt: clockOf(q.client).Now(),
+	})
+	q.nextSeq++
+	if err := q.persistQueued(); err != nil {
+		q.pending = q.pending[:len(q.pending)-1]
+		return false, err
+	}
+	return true, nil
+}
+
+// checkQueueable reports an error if value is not a scalar, string or time
+// value that comes back from the queue file as written
+func checkQueueable(tagName string, value *PlcValue) error {
+	if _, _, ok := cipTypeInfo(value.Type); !ok {
+		return NewEipErrorWithDetails(ErrInvalidDataType,
+			fmt.Sprintf("cannot queue %s write to '%s': only scalar, string and time values can be queued", value.Type, tagName),
+			map[string]interface{}{"tag_name": tagName, "type": value.Type.String()})
+	}
+	line, err := marshalQueuedWrite(QueuedWrite{TagName: tagName, DataType: value.Type, Value: value.Value})
+	var restored QueuedWrite
+	if err == nil {
+		restored, err = unmarshalQueuedWrite(line)
+	}
+	if err == nil && !ValuesMatch(value.Type, value.Value, restored.Value, 0) {
+		err = fmt.Errorf("%v (%T) would be replayed as %v", value.Value, value.Value, restored.Value)
+	}
+	if err != nil {
+		return NewEipErrorWithDetails(ErrInvalidTagValue,
+			fmt.Sprintf("cannot queue %s write to '%s': %v", value.Type, tagName, err),
+			map[string]interface{}{"tag_name": tagName, "type": value.Type.String()})
+	}
+	return nil
+}
+
+// persistQueued stores the write just queued, appending it when the store
+// supports it. Must be called with q.mu held.
+func (q *WriteQueue) persistQueued() error {
+	if store, ok := q.opts.Store.(AppendWriteStore); ok {
+		return store.Append(q.pending[len(q.pending)-1])
+	}
+	return q.opts.Store.Save(q.pending)
+}
+
+// Pending returns a copy of the queued writes
+func (q *WriteQueue) Pending() []QueuedWrite {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]QueuedWrite(nil), q.pending...)
+}
+
+// Flush replays queued writes in order according to the conflict policy. It
+// stops at the first outage error, leaving the rest queued.
+func (q *WriteQueue) Flush() (FlushResult, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var result FlushResult
+	replay := q.pending
+	if q.opts.MaxAge > 0 {
+		cutoff := clockOf(q.client).Now().Add(-q.opts.MaxAge)
+		fresh := replay[:0:0]
+		for _, w := range replay {
+			if w.QueuedAt.Before(cutoff) {
+				result.Dropped = append(result.Dropped, w)
+			} else {
+				fresh = append(fresh, w)
+			}
+		}
+		replay = fresh
+	}
+	if q.opts.Conflict == ConflictLastWriteWins {
+		var superseded []QueuedWrite
+		replay, superseded = lastWritePerTag(replay)
+		result.Dropped = append(result.Dropped, superseded...)
+	}
+
+	var flushErr error
+	done := 0
+	for _, w := range replay {
+		err := q.client.WriteValue(w.TagName, &PlcValue{Type: w.DataType, Value: w.Value})
+		if err != nil && q.opts.IsOutage(err) {
+			flushErr = err
+			break
+		}
+		if err != nil {
+			result.Dropped = append(result.Dropped, w)
+		} else {
+			result.Written++
+		}
+		done++
+	}
+
+	queued := len(q.pending)
+	q.pending = append([]QueuedWrite(nil), replay[done:]...)
+	result.Remaining = len(q.pending)
+	// A flush cut short by the outage before it changed anything leaves the
+	// stored queue as it is
+	if len(q.pending) != queued {
+		if err := q.opts.Store.Save(q.pending); err != nil && flushErr == nil {
+			flushErr = err
+		}
+	}
+	return result, flushErr
+}
+
+// reconnectNotifier is implemented by clients that report restored
+// sessions, such as *EipClient
+type reconnectNotifier interface {
+	OnReconnect(fn func(event ReconnectEvent)) (remove func())
+}
+
+// Run flushes the queue every interval while writes are pending, until ctx
+// is cancelled. If the client reports reconnects (see
+// EipClient.OnReconnect), the queue is also flushed as soon as the session
+// is restored, without waiting for the next tick.
+func (q *WriteQueue) Run(ctx context.Context, interval time.Duration) {
+	ticker := clockOf(q.client).NewTicker(interval)
+	defer ticker.Stop()
+	restored := make(chan struct{}, 1)
+	if notifier, ok := q.client.(reconnectNotifier); ok {
+		remove := notifier.OnReconnect(func(ReconnectEvent) {
+			select {
+			case restored <- struct{}{}:
+			default:
+			}
+		})
+		defer remove()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		case <-restored:
+		}
+		q.mu.Lock()
+		pending := len(q.pending)
+		q.mu.Unlock()
+		if pending > 0 {
+			q.Flush()
+		}
+	}
+}
+
+// lastWritePerTag keeps only the last write for each tag, preserving the
+// relative order of the surviving writes
+func lastWritePerTag(writes []QueuedWrite) (kept, superseded []QueuedWrite) {
+	last := make(map[string]int, len(writes))
+	for i, w := range writes {
+		last[w.TagName] = i
+	}
+	for i, w := range writes {
+		if last[w.TagName] == i {
+			kept = append(kept, w)
+		} else {
+			superseded = append(superseded, w)
+		}
+	}
+	return kept, superseded
+}
+
+// NewPlcValue converts a JSON-decoded value (bool, float64, json.Number or
+// string) to the Go type WriteValue expects for dataType, e.g. float64(5) to
+// int32(5) for DINT. Non-integral or out-of-range numbers, and UDT values,
+// are rejected.
+// Decode with json.Decoder.UseNumber to keep LINT and ULINT values beyond
+// 2^53 exact.
+func NewPlcValue(dataType PlcDataType, v interface{}) (*PlcValue, error) {
+	value, err := coerceValue(dataType, v)
+	if err != nil {
+		return nil, NewEipErrorWithDetails(ErrInvalidTagValue, fmt.Sprintf("invalid %s value: %v", dataType, err),
+			map[string]interface{}{"type": dataType.String()})
+	}
+	return &PlcValue{Type: dataType, Value: value}, nil
+}
+
+// coerceValue converts a JSON-decoded value back to the Go type used for dataType
+func coerceValue(dataType PlcDataType, v interface{}) (interface{}, error) {
+	switch dataType {
+	case Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected bool, got %T", v)
+		}
+		return b, nil
+	case Sint:
+		n, err := signedInteger(v, 8)
+		return int8(n), err
+	case Int:
+		n, err := signedInteger(v, 16)
+		return int16(n), err
+	case Dint:
+		n, err := signedInteger(v, 32)
+		return int32(n), err
+	case Lint:
+		return signedInteger(v, 64)
+	case Usint:
+		n, err := unsignedInteger(v, 8)
+		return uint8(n), err
+	case Uint:
+		n, err := unsignedInteger(v, 16)
+		return uint16(n), err
+	case Udint:
+		n, err := unsignedInteger(v, 32)
+		return uint32(n), err
+	case Ulint:
+		return unsignedInteger(v, 64)
+	case Real, Lreal:
+		switch n := v.(type) {
+		case float64:
+			return n, nil
+		case json.Number:
+			return strconv.ParseFloat(string(n), 64)
+		}
+		return nil, fmt.Errorf("expected number, got %T", v)
+	case String:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", v)
+		}
+		return s, nil
+	case Dt, Ldt, Time:
+		return coerceTemporal(dataType, v)
+	default:
+		return nil, fmt.Errorf("unsupported data type %s", dataType)
+	}
+}
+
+// signedInteger converts a JSON number to an integer of the given bit size.
+// json.Number values are parsed as integers, so all 64 bits are kept.
+func signedInteger(v interface{}, bits int) (int64, error) {
+	if n, ok := v.(json.Number); ok {
+		if i, err := strconv.ParseInt(string(n), 10, bits); err == nil {
+			return i, nil
+		}
+		// Fall back for forms such as 1e3 or 5.0
+		f, err := strconv.ParseFloat(string(n), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %s", n)
+		}
+		v = f
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("expected number, got %T", v)
+	}
+	min, max := -math.Ldexp(1, bits-1), math.Ldexp(1, bits-1)
+	if f != math.Trunc(f) || f < min || f >= max {
+		return 0, fmt.Errorf("value %v out of range", f)
+	}
+	return int64(f), nil
+}
+
+// unsignedInteger converts a JSON number to an unsigned integer of the given
+// bit size; see signedInteger
+func unsignedInteger(v interface{}, bits int) (uint64, error) {
+	if n, ok := v.(json.Number); ok {
+		if u, err := strconv.ParseUint(string(n), 10, bits); err == nil {
+			return u, nil
+		}
+		f, err := strconv.ParseFloat(string(n), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %s", n)
+		}
+		v = f
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("expected number, got %T", v)
+	}
+	if f != math.Trunc(f) || f < 0 || f >= math.Ldexp(1, bits) {
+		return 0, fmt.Errorf("value %v out of range", f)
+	}
+	return uint64(f), nil
+}
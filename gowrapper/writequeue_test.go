@@ -0,0 +1,332 @@
+package ethernetip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestWriteQueueQueuesDuringOutage tests that writes are queued while the PLC
+// is unreachable and flushed in order once it is back
+func TestWriteQueueQueuesDuringOutage(t *testing.T) {
+	fake := newFakeClient()
+	queue, err := NewWriteQueue(fake, WriteQueueOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queued, err := queue.Write("Setpoint", &PlcValue{Type: Dint, Value: int32(1)})
+	if err != nil || queued {
+		t.Fatalf("expected direct write, got queued=%v err=%v", queued, err)
+	}
+
+	fake.setErr(NewEipError(ErrConnectionFailed, "connection lost"))
+	for i := int32(2); i <= 3; i++ {
+		queued, err := queue.Write("Setpoint", &PlcValue{Type: Dint, Value: i})
+		if err != nil || !queued {
+			t.Fatalf("expected queued write, got queued=%v err=%v", queued, err)
+		}
+	}
+
+	if _, err := queue.Flush(); err == nil {
+		t.Error("expected flush to fail during outage")
+	}
+	if n := len(queue.Pending()); n != 2 {
+		t.Fatalf("expected 2 pending writes, got %d", n)
+	}
+
+	fake.setErr(nil)
+	result, err := queue.Flush()
+	if err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if result.Written != 2 || result.Remaining != 0 {
+		t.Errorf("unexpected flush result: %+v", result)
+	}
+	if v := fake.values["Setpoint"]; v != int32(3) {
+		t.Errorf("expected final value 3, got %v", v)
+	}
+}
+
+// TestWriteQueueRejectsNonOutageErrors tests that tag errors are returned instead of queued
+func TestWriteQueueRejectsNonOutageErrors(t *testing.T) {
+	fake := newFakeClient()
+	fake.setErr(NewEipError(ErrTagNotFound, "tag not found"))
+	queue, _ := NewWriteQueue(fake, WriteQueueOptions{})
+
+	queued, err := queue.Write("Missing", &PlcValue{Type: Dint, Value: int32(1)})
+	if err == nil || queued {
+		t.Errorf("expected error without queuing, got queued=%v err=%v", queued, err)
+	}
+}
+
+// TestWriteQueueLastWriteWins tests that superseded writes are dropped
+func TestWriteQueueLastWriteWins(t *testing.T) {
+	fake := newFakeClient()
+	fake.setErr(NewEipError(ErrTimeout, "timeout"))
+	queue, _ := NewWriteQueue(fake, WriteQueueOptions{Conflict: ConflictLastWriteWins})
+
+	queue.Write("A", &PlcValue{Type: Dint, Value: int32(1)})
+	queue.Write("B", &PlcValue{Type: Dint, Value: int32(2)})
+	queue.Write("A", &PlcValue{Type: Dint, Value: int32(3)})
+
+	fake.setErr(nil)
+	result, err := queue.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Written != 2 || len(result.Dropped) != 1 || result.Dropped[0].Value != int32(1) {
+		t.Errorf("unexpected flush result: %+v", result)
+	}
+	if fake.values["A"] != int32(3) || fake.values["B"] != int32(2) {
+		t.Errorf("unexpected values: %v", fake.values)
+	}
+}
+
+// TestWriteQueueMaxAge tests that expired writes are dropped on flush
+func TestWriteQueueMaxAge(t *testing.T) {
+	fake := newFakeClient()
+	store := &MemoryWriteStore{}
+	store.Save([]QueuedWrite{
+		{Seq: 0, TagName: "Old", DataType: Dint, Value: int32(1), QueuedAt: time.Now().Add(-time.Hour)},
+		{Seq: 1, TagName: "New", DataType: Dint, Value: int32(2), QueuedAt: time.Now()},
+	})
+	queue, _ := NewWriteQueue(fake, WriteQueueOptions{Store: store, MaxAge: time.Minute})
+
+	result, err := queue.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Written != 1 || len(result.Dropped) != 1 || result.Dropped[0].TagName != "Old" {
+		t.Errorf("unexpected flush result: %+v", result)
+	}
+}
+
+// reconnectingClient is a fakeClient that reports reconnects like an EipClient
+type reconnectingClient struct {
+	*fakeClient
+	reconnects reconnectLog
+}
+
+func (c *reconnectingClient) OnReconnect(fn func(event ReconnectEvent)) (remove func()) {
+	return c.reconnects.listen(fn)
+}
+
+// TestWriteQueueFlushesOnReconnect tests that Run flushes as soon as the
+// client reports a restored session rather than on the next tick
+func TestWriteQueueFlushesOnReconnect(t *testing.T) {
+	client := &reconnectingClient{fakeClient: newFakeClient()}
+	client.setErr(NewEipError(ErrConnectionFailed, "connection lost"))
+	queue, err := NewWriteQueue(client, WriteQueueOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queued, err := queue.Write("Setpoint", &PlcValue{Type: Dint, Value: int32(5)}); !queued || err != nil {
+		t.Fatalf("expected queued write, got queued=%v err=%v", queued, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.Run(ctx, time.Hour)
+
+	client.setErr(nil)
+	// Until Run has registered its listener, a reconnect is not seen
+	waitFor(t, func() bool {
+		client.reconnects.notify(ReconnectEvent{Reason: ReconnectKeepAliveFailure, NewSession: 2})
+		return len(queue.Pending()) == 0
+	})
+	if v := client.values["Setpoint"]; v != int32(5) {
+		t.Errorf("expected Setpoint = 5 after the reconnect, got %v", v)
+	}
+}
+
+// TestFileWriteStoreRoundTrip tests that queued writes survive a restart with their Go types
+func TestFileWriteStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	fake := newFakeClient()
+	fake.setErr(NewEipError(ErrConnectionFailed, "connection lost"))
+
+	queue, err := NewWriteQueue(fake, WriteQueueOptions{Store: NewFileWriteStore(path)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue.Write("Count", &PlcValue{Type: Dint, Value: int32(42)})
+	queue.Write("Speed", &PlcValue{Type: Real, Value: 1.5})
+	queue.Write("Running", &PlcValue{Type: Bool, Value: true})
+	queue.Write("Total", &PlcValue{Type: Lint, Value: int64(9007199254740993)})
+
+	restored, err := NewWriteQueue(fake, WriteQueueOptions{Store: NewFileWriteStore(path)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := restored.Pending()
+	if len(pending) != 4 {
+		t.Fatalf("expected 4 restored writes, got %d", len(pending))
+	}
+	if pending[0].Value != int32(42) || pending[1].Value != 1.5 || pending[2].Value != true || pending[3].Value != int64(9007199254740993) {
+		t.Errorf("restored values lost their types: %#v", pending)
+	}
+
+	// New writes continue the sequence
+	restored.Write("Count", &PlcValue{Type: Dint, Value: int32(43)})
+	if seq := restored.Pending()[4].Seq; seq != 4 {
+		t.Errorf("expected seq 4, got %d", seq)
+	}
+}
+
+// TestFileWriteStoreNonFinite tests that NaN and infinite values survive a
+// restart, and that a line cut short by a crash is ignored
+func TestFileWriteStoreNonFinite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	fake := newFakeClient()
+	fake.setErr(NewEipError(ErrConnectionFailed, "connection lost"))
+
+	queue, err := NewWriteQueue(fake, WriteQueueOptions{Store: NewFileWriteStore(path)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []*PlcValue{{Type: Real, Value: math.NaN()}, {Type: Lreal, Value: math.Inf(-1)}} {
+		if queued, err := queue.Write("Level", value); !queued || err != nil {
+			t.Fatalf("expected %v to be queued, got %v, %v", value.Value, queued, err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"seq":2,"tag_name":"Le`)
+	file.Close()
+
+	restored, err := NewWriteQueue(fake, WriteQueueOptions{Store: NewFileWriteStore(path)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := restored.Pending()
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 restored writes, got %d", len(pending))
+	}
+	if f, ok := pending[0].Value.(float64); !ok || !math.IsNaN(f) {
+		t.Errorf("expected NaN, got %#v", pending[0].Value)
+	}
+	if pending[1].Value != math.Inf(-1) {
+		t.Errorf("expected -Inf, got %#v", pending[1].Value)
+	}
+}
+
+// TestWriteQueueRejectsUnstorableValues tests that values a store cannot
+// replay as written are rejected whether or not the PLC is reachable, and
+// that TIME values keep their duration
+func TestWriteQueueRejectsUnstorableValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	fake := newFakeClient()
+	queue, err := NewWriteQueue(fake, WriteQueueOptions{Store: NewFileWriteStore(path)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, outage := range []bool{false, true} {
+		if outage {
+			fake.setErr(NewEipError(ErrConnectionFailed, "connection lost"))
+		}
+		recipe := &PlcValue{Type: Udt, Value: map[string]interface{}{"Speed": 1.5}}
+		var eipErr *EipError
+		if _, err := queue.Write("Recipe", recipe); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidDataType {
+			t.Errorf("expected ErrInvalidDataType for a UDT write (outage %v), got %v", outage, err)
+		}
+		array := &PlcValue{Type: Dint, Value: []int32{1, 2}}
+		if _, err := queue.Write("Values", array); !errors.As(err, &eipErr) || eipErr.Code != ErrInvalidTagValue {
+			t.Errorf("expected ErrInvalidTagValue for an array write (outage %v), got %v", outage, err)
+		}
+	}
+	if queued, err := queue.Write("Delay", &PlcValue{Type: Time, Value: 1500 * time.Millisecond}); !queued || err != nil {
+		t.Fatalf("expected the TIME write to be queued, got %v, %v", queued, err)
+	}
+
+	restored, err := NewWriteQueue(fake, WriteQueueOptions{Store: NewFileWriteStore(path)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending := restored.Pending(); len(pending) != 1 || pending[0].Value != 1500*time.Millisecond {
+		t.Errorf("expected only the TIME write of 1.5s, got %#v", pending)
+	}
+}
+
+// TestFileWriteStoreLegacyArray tests that a queue saved as a JSON array is
+// still read
+func TestFileWriteStoreLegacyArray(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	legacy := `[{"seq":0,"tag_name":"Count","data_type":3,"value":42,"queued_at":"2024-01-01T00:00:00Z"}]`
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewFileWriteStore(path)
+	writes, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(writes) != 1 || writes[0].Value != int32(42) {
+		t.Fatalf("unexpected writes: %#v", writes)
+	}
+	if err := store.Append(QueuedWrite{Seq: 1, TagName: "Count", DataType: Dint, Value: int32(43)}); err != nil {
+		t.Fatal(err)
+	}
+	if writes, err = store.Load(); err != nil || len(writes) != 2 || writes[1].Value != int32(43) {
+		t.Errorf("expected the converted queue to take appends, got %#v, %v", writes, err)
+	}
+}
+
+// TestNewPlcValueNumbers tests converting JSON numbers to the Go type of each data type
+func TestNewPlcValueNumbers(t *testing.T) {
+	tests := []struct {
+		dataType PlcDataType
+		value    interface{}
+		want     interface{}
+	}{
+		{Sint, float64(-128), int8(-128)},
+		{Dint, json.Number("2147483647"), int32(2147483647)},
+		{Dint, json.Number("1e3"), int32(1000)},
+		{Lint, json.Number("9007199254740993"), int64(9007199254740993)},
+		{Lint, json.Number("-9223372036854775808"), int64(-9223372036854775808)},
+		{Ulint, json.Number("18446744073709551615"), uint64(18446744073709551615)},
+		{Usint, json.Number("255"), uint8(255)},
+		{Real, json.Number("0.1"), 0.1},
+		{Ldt, json.Number("1000"), time.Unix(0, 1000).UTC()},
+	}
+	for _, test := range tests {
+		value, err := NewPlcValue(test.dataType, test.value)
+		if err != nil {
+			t.Errorf("%s %v: %v", test.dataType, test.value, err)
+			continue
+		}
+		if got, ok := value.Value.(time.Time); ok {
+			if !got.Equal(test.want.(time.Time)) {
+				t.Errorf("%s %v: got %v, want %v", test.dataType, test.value, got, test.want)
+			}
+		} else if value.Value != test.want {
+			t.Errorf("%s %v: got %#v, want %#v", test.dataType, test.value, value.Value, test.want)
+		}
+	}
+
+	for _, test := range []struct {
+		dataType PlcDataType
+		value    interface{}
+	}{
+		{Sint, float64(128)},
+		{Dint, json.Number("2147483648")},
+		{Dint, json.Number("1.5")},
+		{Lint, float64(1 << 63)},
+		{Usint, json.Number("-1")},
+		{Ulint, json.Number("18446744073709551616")},
+		{Real, json.Number("x")},
+		{Dint, "5"},
+	} {
+		if _, err := NewPlcValue(test.dataType, test.value); err == nil {
+			t.Errorf("%s %v: expected error", test.dataType, test.value)
+		}
+	}
+}
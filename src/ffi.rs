@@ -3,10 +3,11 @@
 use crate::EipClient;
 use crate::PlcValue;
 use crate::RUNTIME;
+use crate::TagScope;
 use lazy_static::lazy_static;
 use std::collections::HashMap;
 use std::ffi::{CStr, CString};
-use std::os::raw::{c_char, c_int};
+use std::os::raw::{c_char, c_int, c_uint};
 use std::ptr;
 use std::sync::Mutex;
 
@@ -756,6 +757,10 @@ pub unsafe extern "C" fn eip_write_lreal(
 /// - The caller must ensure both pointers remain valid for the duration of the call
 /// - `client_id` must be a valid client ID returned from `eip_connect`
 /// - `max_length` must be positive and represent the actual buffer size
+///
+/// Returns 0 on success, -2 if the string (with its NUL terminator) does not
+/// fit in `max_length` bytes, so the caller can retry with a larger buffer,
+/// and -1 on any other error.
 #[no_mangle]
 pub unsafe extern "C" fn eip_read_string(
     client_id: c_int,
@@ -791,7 +796,7 @@ pub unsafe extern "C" fn eip_read_string(
 
     let bytes = c_string.as_bytes_with_nul();
     if bytes.len() > max_length as usize {
-        return -1; // String too long
+        return -2; // Buffer too small
     }
 
     unsafe {
@@ -874,13 +879,120 @@ pub unsafe extern "C" fn eip_get_tag_metadata(
     -1
 }
 
-// Configuration
+/// Get the metadata of a tag as a JSON object
+///
+/// The object written to `result` is NUL-terminated and has the fields
+/// `data_type` (CIP type code), `size` (bytes), `dimensions` (array
+/// dimension sizes, empty for scalars), `readable`, `writable`, `scope`
+/// (`"controller"`, `"program"` or `"local"`) and `program`. Exchanging JSON
+/// keeps the layout of the Rust metadata out of the C ABI, so fields can be
+/// added without breaking callers.
+///
+/// # Safety
+///
+/// This function is unsafe because:
+/// - `tag_name` must be a valid null-terminated C string pointer
+/// - `result` must point to a writable buffer of at least `capacity` bytes
+/// - `client_id` must be a valid client ID returned from `eip_connect`
+///
+/// Returns 0 on success, -2 if the JSON (with its NUL terminator) does not
+/// fit in `capacity` bytes, so the caller can retry with a larger buffer, and
+/// -1 if the tag is unknown or on any other error.
 #[no_mangle]
-pub unsafe extern "C" fn eip_set_max_packet_size(_client_id: c_int, _size: c_int) -> c_int {
-    // Return success for now - packet size configuration can be added later
+pub unsafe extern "C" fn eip_get_tag_metadata_json(
+    client_id: c_int,
+    tag_name: *const c_char,
+    result: *mut c_char,
+    capacity: c_int,
+) -> c_int {
+    if tag_name.is_null() || result.is_null() || capacity <= 0 {
+        return -1;
+    }
+
+    let tag_name_str = match unsafe { CStr::from_ptr(tag_name) }.to_str() {
+        Ok(s) => s,
+        Err(_) => return -1,
+    };
+
+    let clients = FFI_CLIENTS.lock().unwrap();
+    let client = match clients.get(&client_id) {
+        Some(client) => client,
+        None => return -1,
+    };
+
+    let metadata = match RUNTIME.block_on(client.get_tag_metadata(tag_name_str)) {
+        Some(metadata) => metadata,
+        None => return -1,
+    };
+
+    let (scope, program) = match &metadata.scope {
+        TagScope::Controller | TagScope::Global => ("controller", ""),
+        TagScope::Program(name) => ("program", name.as_str()),
+        TagScope::Local => ("local", ""),
+    };
+    let dimensions = metadata
+        .dimensions
+        .iter()
+        .map(|d| d.to_string())
+        .collect::<Vec<_>>()
+        .join(",");
+    let json = format!(
+        "{{\"data_type\":{},\"size\":{},\"dimensions\":[{}],\"readable\":{},\"writable\":{},\"scope\":\"{}\",\"program\":\"{}\"}}",
+        metadata.data_type,
+        metadata.size,
+        dimensions,
+        metadata.permissions.readable,
+        metadata.permissions.writable,
+        scope,
+        json_escape(program),
+    );
+
+    let c_string = match CString::new(json) {
+        Ok(s) => s,
+        Err(_) => return -1,
+    };
+    let bytes = c_string.as_bytes_with_nul();
+    if bytes.len() > capacity as usize {
+        return -2; // Buffer too small
+    }
+
+    unsafe {
+        ptr::copy_nonoverlapping(bytes.as_ptr(), result as *mut u8, bytes.len());
+    }
     0
 }
 
+/// Escape a string for inclusion in a JSON string literal
+fn json_escape(s: &str) -> String {
+    let mut escaped = String::with_capacity(s.len());
+    for c in s.chars() {
+        match c {
+            '"' => escaped.push_str("\\\""),
+            '\\' => escaped.push_str("\\\\"),
+            c if (c as u32) < 0x20 => escaped.push_str(&format!("\\u{:04x}", c as u32)),
+            c => escaped.push(c),
+        }
+    }
+    escaped
+}
+
+// Configuration
+#[no_mangle]
+pub unsafe extern "C" fn eip_set_max_packet_size(client_id: c_int, size: c_int) -> c_int {
+    if size <= 0 {
+        return -1;
+    }
+
+    let mut clients = FFI_CLIENTS.lock().unwrap();
+    match clients.get_mut(&client_id) {
+        Some(client) => {
+            client.set_max_packet_size(size as u32);
+            0
+        }
+        None => -1,
+    }
+}
+
 // Health checks
 #[no_mangle]
 pub unsafe extern "C" fn eip_check_health(client_id: c_int, is_healthy: *mut c_int) -> c_int {
@@ -1096,3 +1208,395 @@ pub unsafe extern "C" fn eip_configure_batch_operations(
 pub unsafe extern "C" fn eip_get_batch_config(_client_id: c_int, _config: *mut u8) -> c_int {
     -1 // Not implemented yet
 }
+
+/// Send a raw CIP Message Router request through the client's session
+///
+/// The reply is the CIP Message Router response (reply service, status and
+/// response data) with the encapsulation and CPF framing removed. When the
+/// reply does not fit in `response`, -2 is returned and `response_len` is set
+/// to the required size.
+///
+/// # Safety
+///
+/// This function is unsafe because:
+/// - `request` must point to at least `request_len` readable bytes
+/// - `response` must point to a buffer of at least `response_capacity` bytes
+/// - `response_len` must be a valid mutable pointer to a c_int
+/// - `client_id` must be a valid client ID returned from `eip_connect`
+#[no_mangle]
+pub unsafe extern "C" fn eip_send_cip_request(
+    client_id: c_int,
+    request: *const u8,
+    request_len: c_int,
+    response: *mut u8,
+    response_capacity: c_int,
+    response_len: *mut c_int,
+) -> c_int {
+    if request.is_null() || response.is_null() || response_len.is_null() || request_len <= 0 {
+        return -1;
+    }
+
+    let request_bytes = unsafe { std::slice::from_raw_parts(request, request_len as usize) };
+
+    let mut clients = FFI_CLIENTS.lock().unwrap();
+    let client = match clients.get_mut(&client_id) {
+        Some(client) => client,
+        None => return -1,
+    };
+
+    let reply = match RUNTIME.block_on(client.send_cip_request(request_bytes)) {
+        Ok(raw) => match client.extract_cip_from_response(&raw) {
+            Ok(cip) => cip,
+            Err(_) => return -1,
+        },
+        Err(_) => return -1,
+    };
+
+    unsafe {
+        *response_len = reply.len() as c_int;
+    }
+    if reply.len() > response_capacity as usize {
+        return -2; // Buffer too small
+    }
+
+    unsafe {
+        ptr::copy_nonoverlapping(reply.as_ptr(), response, reply.len());
+    }
+    0
+}
+
+/// Set the request ID carried in the sender context of requests issued from
+/// the calling thread
+///
+/// The Go wrapper assigns each operation a monotonically increasing request ID
+/// and sets it here, on a thread locked to the operation, before issuing the
+/// request, so a failed operation can be matched to its packets in a capture
+/// (the 8-byte sender context is the ID in little-endian order). The ID stays
+/// set on the thread until it is cleared with a request ID of 0, and requests
+/// from other threads are unaffected.
+///
+/// # Safety
+///
+/// This function is unsafe because:
+/// - `client_id` must be a valid client ID returned from `eip_connect`
+#[no_mangle]
+pub unsafe extern "C" fn eip_set_request_id(client_id: c_int, request_id: u64) -> c_int {
+    // Clear the ID even if the client has gone, so it cannot leak into the
+    // thread's later requests
+    crate::set_thread_sender_context(request_id);
+    let clients = FFI_CLIENTS.lock().unwrap();
+    if clients.contains_key(&client_id) {
+        0
+    } else {
+        -1
+    }
+}
+
+/// Get the request ID set on the calling thread with `eip_set_request_id`
+///
+/// Returns 0 when no ID is set. The Go wrapper checks it before assigning an
+/// ID, so a request issued while an operation is already traced on the thread
+/// carries the operation's ID instead of replacing it.
+#[no_mangle]
+pub extern "C" fn eip_get_request_id() -> u64 {
+    crate::thread_sender_context()
+}
+
+/// C layout of `BatchTiming` for `eip_get_last_batch_timing`
+#[repr(C)]
+pub struct CBatchTiming {
+    pub packets: u32,
+    pub operations: u32,
+    pub request_bytes: u64,
+    pub response_bytes: u64,
+    pub serialization_us: u64,
+    pub round_trip_us: u64,
+    pub controller_estimate_us: u64,
+    pub decode_us: u64,
+    pub total_us: u64,
+}
+
+/// Get the packet-level timing of the client's most recent batch execution
+///
+/// # Safety
+///
+/// This function is unsafe because:
+/// - `timing` must be a valid mutable pointer to a `CBatchTiming`
+/// - `client_id` must be a valid client ID returned from `eip_connect`
+#[no_mangle]
+pub unsafe extern "C" fn eip_get_last_batch_timing(
+    client_id: c_int,
+    timing: *mut CBatchTiming,
+) -> c_int {
+    if timing.is_null() {
+        return -1;
+    }
+
+    let clients = FFI_CLIENTS.lock().unwrap();
+    let client = match clients.get(&client_id) {
+        Some(client) => client,
+        None => return -1,
+    };
+
+    let t = client.last_batch_timing();
+    unsafe {
+        *timing = CBatchTiming {
+            packets: t.packets,
+            operations: t.operations,
+            request_bytes: t.request_bytes,
+            response_bytes: t.response_bytes,
+            serialization_us: t.serialization_us,
+            round_trip_us: t.round_trip_us,
+            controller_estimate_us: t.controller_estimate_us,
+            decode_us: t.decode_us,
+            total_us: t.total_us,
+        };
+    }
+    0
+}
+
+/// Set the route path used to reach the target processor
+///
+/// `path` holds encoded port segments (e.g. `[0x01, 0x02]` for backplane slot
+/// 2). Passing a null pointer or zero length clears the route so requests go to
+/// the device at the EtherNet/IP endpoint.
+///
+/// # Safety
+///
+/// This function is unsafe because:
+/// - `path` must be null or point to at least `path_len` readable bytes
+/// - `client_id` must be a valid client ID returned from `eip_connect`
+#[no_mangle]
+pub unsafe extern "C" fn eip_set_route_path(
+    client_id: c_int,
+    path: *const u8,
+    path_len: c_int,
+) -> c_int {
+    if path_len < 0 || path_len > 255 {
+        return -1;
+    }
+    let route_path = if path.is_null() || path_len == 0 {
+        None
+    } else {
+        Some(unsafe { std::slice::from_raw_parts(path, path_len as usize) }.to_vec())
+    };
+
+    let mut clients = FFI_CLIENTS.lock().unwrap();
+    match clients.get_mut(&client_id) {
+        Some(client) => {
+            client.set_route_path(route_path);
+            0
+        }
+        None => -1,
+    }
+}
+
+/// Select how the client's requests reach the Message Router
+///
+/// `mode` 0 sends unconnected messages through the target's UCMM (the
+/// default); 1 sends them over a Class 3 connection opened with Forward Open
+/// on the next request. Switching back to 0 closes the connection.
+///
+/// # Safety
+///
+/// This function is unsafe because:
+/// - `client_id` must be a valid client ID returned from `eip_connect`
+#[no_mangle]
+pub unsafe extern "C" fn eip_set_messaging_mode(client_id: c_int, mode: c_int) -> c_int {
+    let mode = match mode {
+        0 => crate::MessagingMode::Unconnected,
+        1 => crate::MessagingMode::Connected,
+        _ => return -1,
+    };
+
+    let mut clients = FFI_CLIENTS.lock().unwrap();
+    match clients.get_mut(&client_id) {
+        Some(client) => match RUNTIME.block_on(client.set_messaging_mode(mode)) {
+            Ok(()) => 0,
+            Err(_) => -1,
+        },
+        None => -1,
+    }
+}
+
+/// Set the Forward Open parameters of the messaging connection
+///
+/// `rpi_us` is the requested packet interval in microseconds,
+/// `connection_size` the size of the connection in bytes,
+/// `timeout_multiplier` (0-7) scales the RPI to the connection timeout
+/// (RPI × 4 × 2^multiplier) and `transport_trigger` is 0 (cyclic), 1 (change
+/// of state) or 2 (application object). Only connected messaging mode opens
+/// the connection; an open one is closed so the next request uses the new
+/// parameters.
+///
+/// # Safety
+///
+/// This function is unsafe because:
+/// - `client_id` must be a valid client ID returned from `eip_connect`
+#[no_mangle]
+pub unsafe extern "C" fn eip_set_forward_open_params(
+    client_id: c_int,
+    rpi_us: c_uint,
+    connection_size: c_int,
+    timeout_multiplier: c_int,
+    transport_trigger: c_int,
+) -> c_int {
+    if !(0..=0xFFFF).contains(&connection_size)
+        || !(0..=0xFF).contains(&timeout_multiplier)
+        || !(0..=0xFF).contains(&transport_trigger)
+    {
+        return -1;
+    }
+    let parameters = crate::ForwardOpenParameters {
+        rpi_us,
+        connection_size: connection_size as u16,
+        timeout_multiplier: timeout_multiplier as u8,
+        transport_trigger: transport_trigger as u8,
+    };
+
+    let mut clients = FFI_CLIENTS.lock().unwrap();
+    match clients.get_mut(&client_id) {
+        Some(client) => match RUNTIME.block_on(client.set_forward_open_parameters(parameters)) {
+            Ok(()) => 0,
+            Err(_) => -1,
+        },
+        None => -1,
+    }
+}
+
+/// State of the messaging connection, as returned by `eip_get_connection_info`
+#[repr(C)]
+pub struct CConnectionInfo {
+    /// 0 = unconnected (UCMM), 1 = connected
+    pub messaging_mode: c_int,
+    /// 1 if a messaging connection is open
+    pub connected: c_int,
+    /// 1 if the connection was opened with Large Forward Open
+    pub large_forward_open: c_int,
+    /// Negotiated connection size in bytes, 0 without a connection
+    pub connection_size: c_int,
+    pub rpi_us: u32,
+    pub connection_id: u32,
+    pub connection_serial: u32,
+    pub max_packet_size: u32,
+}
+
+/// Get the state of the client's messaging connection, including the
+/// connection size negotiated with the target
+///
+/// # Safety
+///
+/// This function is unsafe because:
+/// - `info` must be a valid mutable pointer to a `CConnectionInfo`
+/// - `client_id` must be a valid client ID returned from `eip_connect`
+#[no_mangle]
+pub unsafe extern "C" fn eip_get_connection_info(
+    client_id: c_int,
+    info: *mut CConnectionInfo,
+) -> c_int {
+    if info.is_null() {
+        return -1;
+    }
+
+    let clients = FFI_CLIENTS.lock().unwrap();
+    let client = match clients.get(&client_id) {
+        Some(client) => client,
+        None => return -1,
+    };
+
+    let i = client.connection_info();
+    unsafe {
+        *info = CConnectionInfo {
+            messaging_mode: match i.messaging_mode {
+                crate::MessagingMode::Unconnected => 0,
+                crate::MessagingMode::Connected => 1,
+            },
+            connected: i.connected as c_int,
+            large_forward_open: i.large_forward_open as c_int,
+            connection_size: i.connection_size as c_int,
+            rpi_us: i.rpi_us,
+            connection_id: i.connection_id,
+            connection_serial: i.connection_serial as u32,
+            max_packet_size: i.max_packet_size,
+        };
+    }
+    0
+}
+
+/// Callback receiving library log records: level (1 = error, 2 = warn,
+/// 3 = info, 4 = debug, 5 = trace), target module and message. Both strings
+/// are only valid for the duration of the call.
+pub type EipLogCallback = extern "C" fn(level: c_int, target: *const c_char, message: *const c_char);
+
+static LOG_CALLBACK: Mutex<Option<EipLogCallback>> = Mutex::new(None);
+static LOGGER_INIT: std::sync::Once = std::sync::Once::new();
+static LOGGER_INSTALLED: std::sync::atomic::AtomicBool = std::sync::atomic::AtomicBool::new(false);
+
+/// `log` implementation that forwards records to the registered callback
+struct FfiLogger;
+
+impl log::Log for FfiLogger {
+    fn enabled(&self, metadata: &log::Metadata) -> bool {
+        metadata.level() <= log::max_level()
+    }
+
+    fn log(&self, record: &log::Record) {
+        if !self.enabled(record.metadata()) {
+            return;
+        }
+        // Copy the callback out so the lock is not held while it runs
+        let callback = match *LOG_CALLBACK.lock().unwrap() {
+            Some(callback) => callback,
+            None => return,
+        };
+        let target = CString::new(record.target().replace('\0', "")).unwrap_or_default();
+        let message = CString::new(record.args().to_string().replace('\0', "")).unwrap_or_default();
+        callback(record.level() as usize as c_int, target.as_ptr(), message.as_ptr());
+    }
+
+    fn flush(&self) {}
+}
+
+static FFI_LOGGER: FfiLogger = FfiLogger;
+
+/// Forward the library's log records to `callback`
+///
+/// `max_level` selects the most verbose level delivered (0 = off, 1 = error
+/// through 5 = trace). Passing a null callback stops forwarding. Returns -1 if
+/// the host process already installed a different `log` logger.
+///
+/// # Safety
+///
+/// This function is unsafe because:
+/// - `callback` must be null or a function that stays valid until it is
+///   replaced, and that may be called from any thread
+#[no_mangle]
+pub unsafe extern "C" fn eip_set_log_callback(
+    callback: Option<EipLogCallback>,
+    max_level: c_int,
+) -> c_int {
+    LOGGER_INIT.call_once(|| {
+        if log::set_logger(&FFI_LOGGER).is_ok() {
+            LOGGER_INSTALLED.store(true, std::sync::atomic::Ordering::SeqCst);
+        }
+    });
+    if !LOGGER_INSTALLED.load(std::sync::atomic::Ordering::SeqCst) {
+        return -1;
+    }
+
+    let filter = match max_level {
+        i32::MIN..=0 => log::LevelFilter::Off,
+        1 => log::LevelFilter::Error,
+        2 => log::LevelFilter::Warn,
+        3 => log::LevelFilter::Info,
+        4 => log::LevelFilter::Debug,
+        _ => log::LevelFilter::Trace,
+    };
+    *LOG_CALLBACK.lock().unwrap() = callback;
+    log::set_max_level(if callback.is_some() {
+        filter
+    } else {
+        log::LevelFilter::Off
+    });
+    0
+}
@@ -257,6 +257,7 @@
 
 use crate::udt::UdtManager;
 use lazy_static::lazy_static;
+use std::cell::Cell;
 use std::collections::HashMap;
 use std::net::SocketAddr;
 use std::sync::atomic::AtomicBool;
@@ -312,6 +313,24 @@ lazy_static! {
     static ref NEXT_ID: Mutex<i32> = Mutex::new(1);
 }
 
+thread_local! {
+    /// Request ID set through the FFI for requests issued from this thread.
+    /// FFI calls run their futures on the calling thread with `block_on`, so
+    /// concurrent callers on other threads keep their own IDs.
+    static THREAD_SENDER_CONTEXT: Cell<u64> = Cell::new(0);
+}
+
+/// Sets the request ID sent in the sender context of requests issued from
+/// the calling thread, overriding the client's own; 0 clears it
+pub fn set_thread_sender_context(context: u64) {
+    THREAD_SENDER_CONTEXT.with(|c| c.set(context));
+}
+
+/// Returns the request ID set for the calling thread, or 0 if none is set
+pub fn thread_sender_context() -> u64 {
+    THREAD_SENDER_CONTEXT.with(|c| c.get())
+}
+
 // =========================================================================
 // BATCH OPERATIONS DATA STRUCTURES
 // =========================================================================
@@ -355,6 +374,34 @@ pub struct BatchResult {
     pub execution_time_us: u64,
 }
 
+/// Aggregate packet-level timing for a batch execution
+///
+/// Times are summed over every Multiple Service Packet sent by the batch.
+/// `controller_estimate_us` is an estimate: the network share of each round
+/// trip is taken to be the fastest round trip seen on the session so far, and
+/// the remainder is attributed to the controller.
+#[derive(Debug, Clone, Copy, Default)]
+pub struct BatchTiming {
+    /// Number of Multiple Service Packets sent
+    pub packets: u32,
+    /// Number of operations executed
+    pub operations: u32,
+    /// Total CIP request bytes sent
+    pub request_bytes: u64,
+    /// Total reply bytes received
+    pub response_bytes: u64,
+    /// Time spent building requests (in microseconds)
+    pub serialization_us: u64,
+    /// Time from sending a request until its reply was read (in microseconds)
+    pub round_trip_us: u64,
+    /// Estimated controller processing time (in microseconds)
+    pub controller_estimate_us: u64,
+    /// Time spent parsing replies (in microseconds)
+    pub decode_us: u64,
+    /// Wall-clock time of the whole batch (in microseconds)
+    pub total_us: u64,
+}
+
 /// Specific error types that can occur during batch operations
 ///
 /// This enum provides detailed error information for batch operations,
@@ -461,6 +508,132 @@ impl Default for BatchConfig {
     }
 }
 
+/// How the client's CIP requests reach the target's Message Router
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub enum MessagingMode {
+    /// Unconnected messages through the Unconnected Message Manager (UCMM),
+    /// which take no connection resources on the target
+    #[default]
+    Unconnected,
+    /// Class 3 explicit messages over a connection opened with Forward Open
+    /// on first use
+    Connected,
+}
+
+/// Largest connection requested with Large Forward Open
+const MAX_CONNECTION_SIZE: u16 = 4000;
+
+/// Largest connection of a standard Forward Open
+const MAX_STANDARD_CONNECTION_SIZE: u16 = 0x01FF;
+
+/// Connection size requested when a target rejects Large Forward Open
+const FALLBACK_CONNECTION_SIZE: u16 = 500;
+
+/// Connection Manager extended statuses of a connected request on a
+/// connection the target no longer holds: not found, or timed out
+const STALE_CONNECTION_STATUSES: [u16; 2] = [0x0107, 0x0203];
+
+/// Reports whether a SendUnitData reply says the target no longer holds the
+/// connection, as after it timed out while idle. The reply is the CPF payload
+/// of a connected data item: [interface handle, timeout, item count, address
+/// item] then [type, length, sequence count, CIP reply]. The request was not
+/// carried out, so it can be sent again on a new connection.
+fn is_stale_connection_reply(response: &[u8]) -> bool {
+    // CIP reply: service, reserved, general status, extended status size
+    const CIP_REPLY: usize = 22;
+    if response.len() < CIP_REPLY + 6 || response[CIP_REPLY + 2] != 0x01 {
+        return false;
+    }
+    let extended = u16::from_le_bytes([response[CIP_REPLY + 4], response[CIP_REPLY + 5]]);
+    response[CIP_REPLY + 3] >= 1 && STALE_CONNECTION_STATUSES.contains(&extended)
+}
+
+/// State of the client's messaging connection as negotiated with the target
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub struct ConnectionInfo {
+    /// How requests reach the Message Router
+    pub messaging_mode: MessagingMode,
+    /// Whether a messaging connection is open
+    pub connected: bool,
+    /// Whether the connection was opened with Large Forward Open
+    pub large_forward_open: bool,
+    /// Negotiated connection size in bytes, 0 without a connection
+    pub connection_size: u16,
+    /// Requested packet interval of the connection in microseconds
+    pub rpi_us: u32,
+    /// O->T connection ID assigned by the target
+    pub connection_id: u32,
+    /// Connection serial number
+    pub connection_serial: u16,
+    /// Largest packet size the client may negotiate
+    pub max_packet_size: u32,
+}
+
+/// Tunable parameters of the Forward Open that opens the messaging
+/// connection of connected messaging mode
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct ForwardOpenParameters {
+    /// Requested packet interval in microseconds
+    pub rpi_us: u32,
+    /// Connection size in bytes, in both directions. Sizes above 511 bytes
+    /// are requested with Large Forward Open, falling back to a standard
+    /// 500-byte connection when the target rejects it.
+    pub connection_size: u16,
+    /// The connection times out after RPI × 4 × 2^timeout_multiplier
+    /// without traffic (0-7)
+    pub timeout_multiplier: u8,
+    /// Production trigger: 0 = cyclic, 1 = change of state, 2 = application
+    /// object
+    pub transport_trigger: u8,
+}
+
+impl Default for ForwardOpenParameters {
+    fn default() -> Self {
+        Self {
+            rpi_us: 100000,        // 100ms RPI
+            connection_size: 4000, // Large Forward Open, or 500 bytes
+            timeout_multiplier: 5, // RPI × 128
+            transport_trigger: 2,  // Application object
+        }
+    }
+}
+
+impl ForwardOpenParameters {
+    /// Checks that the parameters can be sent in a Forward Open
+    pub fn validate(&self) -> crate::error::Result<()> {
+        if self.rpi_us == 0 {
+            return Err(EtherNetIpError::Protocol(
+                "RPI must be positive".to_string(),
+            ));
+        }
+        if self.connection_size == 0 || self.connection_size > MAX_CONNECTION_SIZE {
+            return Err(EtherNetIpError::Protocol(format!(
+                "Connection size must be 1-{} bytes, got {}",
+                MAX_CONNECTION_SIZE, self.connection_size
+            )));
+        }
+        if self.timeout_multiplier > 7 {
+            return Err(EtherNetIpError::Protocol(format!(
+                "Timeout multiplier must be 0-7, got {}",
+                self.timeout_multiplier
+            )));
+        }
+        if self.transport_trigger > 2 {
+            return Err(EtherNetIpError::Protocol(format!(
+                "Transport trigger must be 0-2, got {}",
+                self.transport_trigger
+            )));
+        }
+        Ok(())
+    }
+
+    /// Returns the transport class and trigger byte of an explicit messaging
+    /// connection: server direction, the trigger, class 3
+    fn transport_class_trigger(&self) -> u8 {
+        0x80 | (self.transport_trigger << 4) | 0x03
+    }
+}
+
 /// Connected session information for Class 3 explicit messaging
 ///
 /// Allen-Bradley PLCs often require connected sessions for certain operations
@@ -505,6 +678,14 @@ pub struct ConnectedSession {
 
     /// Sequence counter for connected messages (increments with each message)
     pub sequence_count: u16,
+
+    /// Transport class and trigger byte (0xA3 = server, application
+    /// triggered, class 3)
+    pub transport_class_trigger: u8,
+
+    /// Whether the connection is opened with Large Forward Open, which
+    /// allows connections of more than 511 bytes
+    pub large_forward_open: bool,
 }
 
 /// Connection parameters for EtherNet/IP connections
@@ -551,6 +732,8 @@ impl ConnectedSession {
             established_at: Instant::now(),
             is_active: false,
             sequence_count: 0,
+            transport_class_trigger: 0xA3,
+            large_forward_open: false,
         }
     }
 
@@ -567,7 +750,7 @@ impl ConnectedSession {
                 session.t_to_o_params.size = 504;
                 session.o_to_t_params.priority = 0x00; // Low priority
                 session.t_to_o_params.priority = 0x00;
-                println!("🔧 [CONFIG 1] Conservative: 504 bytes, 200ms RPI, low priority");
+                log::debug!("🔧 [CONFIG 1] Conservative: 504 bytes, 200ms RPI, low priority");
             }
             2 => {
                 // Config 2: Compact parameters
@@ -577,7 +760,7 @@ impl ConnectedSession {
                 session.t_to_o_params.size = 256;
                 session.o_to_t_params.priority = 0x02; // Scheduled priority
                 session.t_to_o_params.priority = 0x02;
-                println!("🔧 [CONFIG 2] Compact: 256 bytes, 50ms RPI, scheduled priority");
+                log::debug!("🔧 [CONFIG 2] Compact: 256 bytes, 50ms RPI, scheduled priority");
             }
             3 => {
                 // Config 3: Minimal parameters
@@ -587,7 +770,7 @@ impl ConnectedSession {
                 session.t_to_o_params.size = 128;
                 session.o_to_t_params.priority = 0x03; // Urgent priority
                 session.t_to_o_params.priority = 0x03;
-                println!("🔧 [CONFIG 3] Minimal: 128 bytes, 1000ms RPI, urgent priority");
+                log::debug!("🔧 [CONFIG 3] Minimal: 128 bytes, 1000ms RPI, urgent priority");
             }
             4 => {
                 // Config 4: Standard Rockwell parameters (from documentation)
@@ -598,7 +781,7 @@ impl ConnectedSession {
                 session.o_to_t_params.connection_type = 0x01; // Multicast
                 session.t_to_o_params.connection_type = 0x01;
                 session.originator_vendor_id = 0x001D; // Rockwell vendor ID
-                println!("🔧 [CONFIG 4] Rockwell standard: 500 bytes, 100ms RPI, multicast, Rockwell vendor");
+                log::debug!("🔧 [CONFIG 4] Rockwell standard: 500 bytes, 100ms RPI, multicast, Rockwell vendor");
             }
             5 => {
                 // Config 5: Large buffer parameters
@@ -608,11 +791,11 @@ impl ConnectedSession {
                 session.t_to_o_params.size = 1024;
                 session.o_to_t_params.variable_size = true; // Variable size
                 session.t_to_o_params.variable_size = true;
-                println!("🔧 [CONFIG 5] Large buffer: 1024 bytes, 500ms RPI, variable size");
+                log::debug!("🔧 [CONFIG 5] Large buffer: 1024 bytes, 500ms RPI, variable size");
             }
             _ => {
                 // Default config
-                println!("🔧 [CONFIG 0] Default parameters");
+                log::debug!("🔧 [CONFIG 0] Default parameters");
             }
         }
 
@@ -950,6 +1133,23 @@ pub struct EipClient {
     connection_sequence: Arc<Mutex<u32>>,
     /// Active tag subscriptions
     subscriptions: Arc<Mutex<Vec<TagSubscription>>>,
+    /// Request ID placed in the encapsulation sender context of outgoing
+    /// requests, so packets can be correlated with wrapper logs
+    sender_context: u64,
+    /// Route path to the target processor, if it is not the device at the
+    /// EtherNet/IP endpoint
+    route_path: Option<Vec<u8>>,
+    /// How requests reach the Message Router
+    messaging_mode: MessagingMode,
+    /// Class 3 connection carrying requests in connected messaging mode
+    messaging_connection: Arc<std::sync::Mutex<Option<ConnectedSession>>>,
+    /// Forward Open parameters of the messaging connection
+    forward_open_parameters: ForwardOpenParameters,
+    /// Timing of the most recent batch execution
+    last_batch_timing: BatchTiming,
+    /// Fastest batch round trip seen on this session, used as the network
+    /// latency baseline for controller processing estimates
+    min_round_trip_us: Option<u64>,
 }
 
 impl EipClient {
@@ -972,6 +1172,13 @@ impl EipClient {
             connected_sessions: Arc::new(Mutex::new(HashMap::new())),
             connection_sequence: Arc::new(Mutex::new(1)),
             subscriptions: Arc::new(Mutex::new(Vec::new())),
+            sender_context: 0,
+            route_path: None,
+            messaging_mode: MessagingMode::Unconnected,
+            messaging_connection: Arc::new(std::sync::Mutex::new(None)),
+            forward_open_parameters: ForwardOpenParameters::default(),
+            last_batch_timing: BatchTiming::default(),
+            min_round_trip_us: None,
         };
         client.register_session().await?;
         Ok(client)
@@ -1004,7 +1211,7 @@ impl EipClient {
     /// - Invalid response format
     /// - PLC rejection (status code non-zero)
     async fn register_session(&mut self) -> crate::error::Result<()> {
-        println!("🔌 [DEBUG] Starting session registration...");
+        log::debug!("🔌 [DEBUG] Starting session registration...");
         let packet: [u8; 28] = [
             0x65, 0x00, // Command: Register Session (0x0065)
             0x04, 0x00, // Length: 4 bytes
@@ -1016,7 +1223,7 @@ impl EipClient {
             0x00, 0x00, // Option Flags: 0
         ];
 
-        println!(
+        log::debug!(
             "📤 [DEBUG] Sending Register Session packet: {:02X?}",
             packet
         );
@@ -1026,12 +1233,12 @@ impl EipClient {
             .write_all(&packet)
             .await
             .map_err(|e| {
-                println!("❌ [DEBUG] Failed to send Register Session packet: {}", e);
+                log::warn!("❌ [DEBUG] Failed to send Register Session packet: {}", e);
                 EtherNetIpError::Io(e)
             })?;
 
         let mut buf = [0u8; 1024];
-        println!("⏳ [DEBUG] Waiting for Register Session response...");
+        log::debug!("⏳ [DEBUG] Waiting for Register Session response...");
         let n = match timeout(
             Duration::from_secs(5),
             self.stream.lock().await.read(&mut buf),
@@ -1039,34 +1246,34 @@ impl EipClient {
         .await
         {
             Ok(Ok(n)) => {
-                println!("📥 [DEBUG] Received {} bytes in response", n);
+                log::debug!("📥 [DEBUG] Received {} bytes in response", n);
                 n
             }
             Ok(Err(e)) => {
-                println!("❌ [DEBUG] Error reading response: {}", e);
+                log::warn!("❌ [DEBUG] Error reading response: {}", e);
                 return Err(EtherNetIpError::Io(e));
             }
             Err(_) => {
-                println!("⏰ [DEBUG] Timeout waiting for response");
+                log::debug!("⏰ [DEBUG] Timeout waiting for response");
                 return Err(EtherNetIpError::Timeout(Duration::from_secs(5)));
             }
         };
 
         if n < 28 {
-            println!("❌ [DEBUG] Response too short: {} bytes (expected 28)", n);
+            log::warn!("❌ [DEBUG] Response too short: {} bytes (expected 28)", n);
             return Err(EtherNetIpError::Protocol("Response too short".to_string()));
         }
 
         // Extract session handle from response
         self.session_handle = u32::from_le_bytes([buf[4], buf[5], buf[6], buf[7]]);
-        println!("🔑 [DEBUG] Session handle: 0x{:08X}", self.session_handle);
+        log::debug!("🔑 [DEBUG] Session handle: 0x{:08X}", self.session_handle);
 
         // Check status
         let status = u32::from_le_bytes([buf[8], buf[9], buf[10], buf[11]]);
-        println!("📊 [DEBUG] Status code: 0x{:08X}", status);
+        log::debug!("📊 [DEBUG] Status code: 0x{:08X}", status);
 
         if status != 0 {
-            println!(
+            log::warn!(
                 "❌ [DEBUG] Session registration failed with status: 0x{:08X}",
                 status
             );
@@ -1076,15 +1283,146 @@ impl EipClient {
             )));
         }
 
-        println!("✅ [DEBUG] Session registration successful");
+        log::debug!("✅ [DEBUG] Session registration successful");
         Ok(())
     }
 
     /// Sets the maximum packet size for communication
+    ///
+    /// The size caps the connection negotiated for connected messaging; it
+    /// applies to the next connection opened.
     pub fn set_max_packet_size(&mut self, size: u32) {
         self.max_packet_size = size.min(4000);
     }
 
+    /// Sets the route path used to reach the target processor
+    ///
+    /// The path is a sequence of encoded port segments, e.g. `[0x01, 0x02]` for
+    /// backplane slot 2. When set, unconnected requests are wrapped in an
+    /// Unconnected Send to the Connection Manager; `None` addresses the device
+    /// at the EtherNet/IP endpoint directly.
+    pub fn set_route_path(&mut self, route_path: Option<Vec<u8>>) {
+        self.route_path = route_path.filter(|path| !path.is_empty());
+        // The messaging connection runs along the old route; the target
+        // closes it once it times out
+        self.messaging_connection.lock().unwrap().take();
+    }
+
+    /// Selects how requests reach the Message Router
+    ///
+    /// Unconnected messaging (the default) sends every request through the
+    /// target's UCMM. Connected messaging opens a Class 3 connection with
+    /// Forward Open on the next request and sends requests over it, which
+    /// saves the target from routing each one; switching back closes the
+    /// connection.
+    pub async fn set_messaging_mode(&mut self, mode: MessagingMode) -> crate::error::Result<()> {
+        if mode == MessagingMode::Unconnected {
+            self.close_messaging_connection().await;
+        }
+        self.messaging_mode = mode;
+        Ok(())
+    }
+
+    /// Returns how requests reach the Message Router
+    pub fn messaging_mode(&self) -> MessagingMode {
+        self.messaging_mode
+    }
+
+    /// Sets the Forward Open parameters of the messaging connection of
+    /// connected messaging mode
+    ///
+    /// An open connection is closed so the next request opens one with the
+    /// new parameters.
+    pub async fn set_forward_open_parameters(
+        &mut self,
+        parameters: ForwardOpenParameters,
+    ) -> crate::error::Result<()> {
+        parameters.validate()?;
+        self.forward_open_parameters = parameters;
+        self.close_messaging_connection().await;
+        Ok(())
+    }
+
+    /// Returns the Forward Open parameters of the messaging connection
+    pub fn forward_open_parameters(&self) -> ForwardOpenParameters {
+        self.forward_open_parameters
+    }
+
+    /// Returns the state of the messaging connection as negotiated with the
+    /// target
+    pub fn connection_info(&self) -> ConnectionInfo {
+        let mut info = ConnectionInfo {
+            messaging_mode: self.messaging_mode,
+            max_packet_size: self.max_packet_size,
+            ..ConnectionInfo::default()
+        };
+        if let Some(session) = self.messaging_connection.lock().unwrap().as_ref() {
+            info.connected = true;
+            info.large_forward_open = session.large_forward_open;
+            info.connection_size = session.o_to_t_params.size;
+            info.rpi_us = session.rpi;
+            info.connection_id = session.o_to_t_connection_id;
+            info.connection_serial = session.connection_serial;
+        }
+        info
+    }
+
+    /// Returns the connection path of Forward Open and Forward Close: the
+    /// route to the target processor followed by its Message Router
+    fn connection_path(&self) -> Vec<u8> {
+        let mut path = self.route_path.clone().unwrap_or_default();
+        if path.len() % 2 != 0 {
+            path.push(0x00);
+        }
+        path.extend_from_slice(&[0x20, 0x02, 0x24, 0x01]); // Message Router instance 1
+        path
+    }
+
+    /// Returns true if the request is addressed to the Connection Manager
+    /// (class 0x06), which handles its own routing
+    fn targets_connection_manager(cip_request: &[u8]) -> bool {
+        cip_request.len() >= 4 && cip_request[2] == 0x20 && cip_request[3] == 0x06
+    }
+
+    /// Wraps a Message Router request in an Unconnected Send service
+    fn build_unconnected_send(cip_request: &[u8], route_path: &[u8]) -> Vec<u8> {
+        let mut request = Vec::with_capacity(cip_request.len() + route_path.len() + 14);
+        request.push(0x52); // Unconnected Send service
+        request.push(0x02); // Path size: 2 words
+        request.extend_from_slice(&[0x20, 0x06, 0x24, 0x01]); // Connection Manager, instance 1
+        request.push(0x0A); // Priority/time tick
+        request.push(0x05); // Timeout ticks
+        request.extend_from_slice(&(cip_request.len() as u16).to_le_bytes());
+        request.extend_from_slice(cip_request);
+        if cip_request.len() % 2 != 0 {
+            request.push(0x00); // Pad to an even length
+        }
+        request.push(((route_path.len() + 1) / 2) as u8); // Route path size in words
+        request.push(0x00); // Reserved
+        request.extend_from_slice(route_path);
+        if route_path.len() % 2 != 0 {
+            request.push(0x00);
+        }
+        request
+    }
+
+    /// Sets the request ID sent in the sender context of subsequent requests
+    ///
+    /// The target echoes the sender context in its reply, so the ID shows up in
+    /// both directions of a packet capture.
+    pub fn set_sender_context(&mut self, context: u64) {
+        self.sender_context = context;
+    }
+
+    /// Returns the sender context for a request: the calling thread's request
+    /// ID if one is set, otherwise the client's own
+    fn request_sender_context(&self) -> u64 {
+        match THREAD_SENDER_CONTEXT.with(|c| c.get()) {
+            0 => self.sender_context,
+            context => context,
+        }
+    }
+
     /// Discovers all tags in the PLC
     pub async fn discover_tags(&mut self) -> crate::error::Result<()> {
         let response = self
@@ -1203,7 +1541,7 @@ impl EipClient {
     /// # }
     /// ```
     pub async fn write_tag(&mut self, tag_name: &str, value: PlcValue) -> crate::error::Result<()> {
-        println!(
+        log::debug!(
             "📝 Writing '{}' to tag '{}'",
             match &value {
                 PlcValue::String(s) => format!("\"{}\"", s),
@@ -1233,14 +1571,14 @@ impl EipClient {
         let service_reply = cip_response[0]; // Should be 0xCD (0x4D + 0x80) for Write Tag reply
         let general_status = cip_response[2]; // CIP status code
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Write response - Service: 0x{:02X}, Status: 0x{:02X}",
             service_reply, general_status
         );
 
         if general_status != 0x00 {
             let error_msg = self.get_cip_error_message(general_status);
-            println!(
+            log::warn!(
                 "❌ [WRITE] CIP Error: {} (0x{:02X})",
                 error_msg, general_status
             );
@@ -1250,7 +1588,7 @@ impl EipClient {
             )));
         }
 
-        println!("✅ Write operation completed successfully");
+        log::debug!("✅ Write operation completed successfully");
         Ok(())
     }
 
@@ -1261,7 +1599,7 @@ impl EipClient {
         value: &PlcValue,
     ) -> crate::error::Result<Vec<u8>> {
         if let PlcValue::String(string_value) = value {
-            println!(
+            log::debug!(
                 "🔧 [DEBUG] Building correct Allen-Bradley string write request for tag: '{}'",
                 tag_name
             );
@@ -1314,9 +1652,9 @@ impl EipClient {
                 .copy_from_slice(&string_bytes[..current_len as usize]);
             cip_request.extend_from_slice(&data_array);
 
-            println!("🔧 [DEBUG] Built correct AB string write request ({} bytes): len={}, maxlen={}, data_len={}",
+            log::debug!("🔧 [DEBUG] Built correct AB string write request ({} bytes): len={}, maxlen={}, data_len={}",
                      cip_request.len(), current_len, max_len, string_bytes.len());
-            println!(
+            log::debug!(
                 "🔧 [DEBUG] First 32 bytes: {:02X?}",
                 &cip_request[..std::cmp::min(32, cip_request.len())]
             );
@@ -1338,7 +1676,7 @@ impl EipClient {
         tag_name: &str,
         value: &PlcValue,
     ) -> crate::error::Result<Vec<u8>> {
-        println!("🔧 [DEBUG] Building write request for tag: '{}'", tag_name);
+        log::debug!("🔧 [DEBUG] Building write request for tag: '{}'", tag_name);
 
         // Use Connected Explicit Messaging for consistency
         let mut cip_request = Vec::new();
@@ -1373,7 +1711,7 @@ impl EipClient {
         cip_request.extend_from_slice(&[0x01, 0x00]); // Element count: 1
         cip_request.extend_from_slice(&value_bytes); // Value data
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Built CIP write request ({} bytes): {:02X?}",
             cip_request.len(),
             cip_request
@@ -1480,7 +1818,7 @@ impl EipClient {
     }
 
     pub fn build_list_tags_request(&self) -> Vec<u8> {
-        println!("🔧 [DEBUG] Building list tags request");
+        log::debug!("🔧 [DEBUG] Building list tags request");
 
         // Build path array for Symbol Object Class (0x6B)
         let path_array = vec![
@@ -1513,7 +1851,7 @@ impl EipClient {
         // Request Data
         cip_request.extend_from_slice(&request_data);
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Built CIP list tags request ({} bytes): {:02X?}",
             cip_request.len(),
             cip_request
@@ -1657,14 +1995,14 @@ impl EipClient {
         let service_reply = cip_response[0]; // Should be 0xCD (0x4D + 0x80) for Write Tag reply
         let general_status = cip_response[2]; // CIP status code
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Write response - Service: 0x{:02X}, Status: 0x{:02X}",
             service_reply, general_status
         );
 
         if general_status != 0x00 {
             let error_msg = self.get_cip_error_message(general_status);
-            println!(
+            log::warn!(
                 "❌ [WRITE] CIP Error: {} (0x{:02X})",
                 error_msg, general_status
             );
@@ -1674,18 +2012,44 @@ impl EipClient {
             )));
         }
 
-        println!("✅ Write completed successfully");
+        log::debug!("✅ Write completed successfully");
         Ok(())
     }
 
-    /// Sends a CIP request wrapped in EtherNet/IP SendRRData command
+    /// Sends a CIP request and returns the Common Packet Format data of the
+    /// reply
+    ///
+    /// In connected messaging mode requests go over the client's Class 3
+    /// connection, except Connection Manager requests, which are always
+    /// unconnected.
     pub async fn send_cip_request(&self, cip_request: &[u8]) -> Result<Vec<u8>> {
-        println!(
+        if self.messaging_mode == MessagingMode::Connected
+            && !Self::targets_connection_manager(cip_request)
+        {
+            return self.send_connected_message(cip_request).await;
+        }
+        self.send_unconnected_cip_request(cip_request).await
+    }
+
+    /// Sends a CIP request wrapped in EtherNet/IP SendRRData command
+    async fn send_unconnected_cip_request(&self, cip_request: &[u8]) -> Result<Vec<u8>> {
+        log::debug!(
             "🔧 [DEBUG] Sending CIP request ({} bytes): {:02X?}",
             cip_request.len(),
             cip_request
         );
 
+        // Route through the Connection Manager when the target is behind a
+        // backplane or bridge
+        let routed;
+        let cip_request = match &self.route_path {
+            Some(route_path) if !Self::targets_connection_manager(cip_request) => {
+                routed = Self::build_unconnected_send(cip_request, route_path);
+                &routed[..]
+            }
+            _ => cip_request,
+        };
+
         // Calculate total packet size
         let cip_data_size = cip_request.len();
         let total_data_len = 4 + 2 + 2 + 8 + cip_data_size; // Interface + Timeout + Count + Items + CIP
@@ -1697,7 +2061,7 @@ impl EipClient {
         packet.extend_from_slice(&(total_data_len as u16).to_le_bytes()); // Length
         packet.extend_from_slice(&self.session_handle.to_le_bytes()); // Session handle
         packet.extend_from_slice(&[0x00, 0x00, 0x00, 0x00]); // Status
-        packet.extend_from_slice(&[0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08]); // Context
+        packet.extend_from_slice(&self.request_sender_context().to_le_bytes()); // Context
         packet.extend_from_slice(&[0x00, 0x00, 0x00, 0x00]); // Options
 
         // CPF (Common Packet Format) data
@@ -1716,7 +2080,7 @@ impl EipClient {
         // Add CIP request data
         packet.extend_from_slice(cip_request);
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Built packet ({} bytes): {:02X?}",
             packet.len(),
             &packet[..std::cmp::min(64, packet.len())]
@@ -1768,7 +2132,7 @@ impl EipClient {
         // Update last activity time
         *self.last_activity.lock().await = Instant::now();
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Received response ({} bytes): {:02X?}",
             response_data.len(),
             &response_data[..std::cmp::min(32, response_data.len())]
@@ -1779,7 +2143,7 @@ impl EipClient {
 
     /// Extracts CIP data from EtherNet/IP response packet
     fn extract_cip_from_response(&self, response: &[u8]) -> crate::error::Result<Vec<u8>> {
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Extracting CIP from response ({} bytes): {:02X?}",
             response.len(),
             &response[..std::cmp::min(32, response.len())]
@@ -1800,7 +2164,7 @@ impl EipClient {
         // Read item count
         let item_count = u16::from_le_bytes([response[pos], response[pos + 1]]);
         pos += 2;
-        println!("🔧 [DEBUG] CPF item count: {}", item_count);
+        log::debug!("🔧 [DEBUG] CPF item count: {}", item_count);
 
         // Process items
         for i in 0..item_count {
@@ -1814,23 +2178,29 @@ impl EipClient {
             let item_length = u16::from_le_bytes([response[pos + 2], response[pos + 3]]) as usize;
             pos += 4; // Skip item header
 
-            println!(
+            log::debug!(
                 "🔧 [DEBUG] Item {}: type=0x{:04X}, length={}",
                 i, item_type, item_length
             );
 
-            if item_type == 0x00B2 {
+            if item_type == 0x00B1 {
+                // Connected Data Item: sequence count followed by the CIP data
+                if item_length < 2 || pos + item_length > response.len() {
+                    return Err(EtherNetIpError::Protocol("Data item truncated".to_string()));
+                }
+                return Ok(response[pos + 2..pos + item_length].to_vec());
+            } else if item_type == 0x00B2 {
                 // Unconnected Data Item
                 if pos + item_length > response.len() {
                     return Err(EtherNetIpError::Protocol("Data item truncated".to_string()));
                 }
 
                 let cip_data = response[pos..pos + item_length].to_vec();
-                println!(
+                log::debug!(
                     "🔧 [DEBUG] Found Unconnected Data Item, extracted CIP data ({} bytes)",
                     cip_data.len()
                 );
-                println!(
+                log::debug!(
                     "🔧 [DEBUG] CIP data bytes: {:02X?}",
                     &cip_data[..std::cmp::min(16, cip_data.len())]
                 );
@@ -1842,13 +2212,13 @@ impl EipClient {
         }
 
         Err(EtherNetIpError::Protocol(
-            "No Unconnected Data Item (0x00B2) found in response".to_string(),
+            "No Connected or Unconnected Data Item (0x00B1/0x00B2) found in response".to_string(),
         ))
     }
 
     /// Parses CIP response and converts to PlcValue
     fn parse_cip_response(&self, cip_response: &[u8]) -> crate::error::Result<PlcValue> {
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Parsing CIP response ({} bytes): {:02X?}",
             cip_response.len(),
             cip_response
@@ -1863,7 +2233,7 @@ impl EipClient {
         let service_reply = cip_response[0]; // Should be 0xCC (0x4C + 0x80) for Read Tag reply
         let general_status = cip_response[2]; // CIP status code
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Service reply: 0x{:02X}, Status: 0x{:02X}",
             service_reply, general_status
         );
@@ -1871,7 +2241,7 @@ impl EipClient {
         // Check for CIP errors
         if general_status != 0x00 {
             let error_msg = self.get_cip_error_message(general_status);
-            println!(
+            log::debug!(
                 "🔧 [DEBUG] CIP Error - Status: 0x{:02X}, Message: {}",
                 general_status, error_msg
             );
@@ -1893,7 +2263,7 @@ impl EipClient {
             let data_type = u16::from_le_bytes([cip_response[4], cip_response[5]]);
             let value_data = &cip_response[6..];
 
-            println!(
+            log::debug!(
                 "🔧 [DEBUG] Data type: 0x{:04X}, Value data ({} bytes): {:02X?}",
                 data_type,
                 value_data.len(),
@@ -1910,7 +2280,7 @@ impl EipClient {
                         ));
                     }
                     let value = value_data[0] != 0;
-                    println!("🔧 [DEBUG] Parsed BOOL: {}", value);
+                    log::debug!("🔧 [DEBUG] Parsed BOOL: {}", value);
                     Ok(PlcValue::Bool(value))
                 }
                 0x00C2 => {
@@ -1921,7 +2291,7 @@ impl EipClient {
                         ));
                     }
                     let value = value_data[0] as i8;
-                    println!("🔧 [DEBUG] Parsed SINT: {}", value);
+                    log::debug!("🔧 [DEBUG] Parsed SINT: {}", value);
                     Ok(PlcValue::Sint(value))
                 }
                 0x00C3 => {
@@ -1932,7 +2302,7 @@ impl EipClient {
                         ));
                     }
                     let value = i16::from_le_bytes([value_data[0], value_data[1]]);
-                    println!("🔧 [DEBUG] Parsed INT: {}", value);
+                    log::debug!("🔧 [DEBUG] Parsed INT: {}", value);
                     Ok(PlcValue::Int(value))
                 }
                 0x00C4 => {
@@ -1948,7 +2318,7 @@ impl EipClient {
                         value_data[2],
                         value_data[3],
                     ]);
-                    println!("🔧 [DEBUG] Parsed DINT: {}", value);
+                    log::debug!("🔧 [DEBUG] Parsed DINT: {}", value);
                     Ok(PlcValue::Dint(value))
                 }
                 0x00CA => {
@@ -1964,7 +2334,7 @@ impl EipClient {
                         value_data[2],
                         value_data[3],
                     ]);
-                    println!("🔧 [DEBUG] Parsed REAL: {}", value);
+                    log::debug!("🔧 [DEBUG] Parsed REAL: {}", value);
                     Ok(PlcValue::Real(value))
                 }
                 0x00DA => {
@@ -1980,35 +2350,35 @@ impl EipClient {
                     }
                     let string_data = &value_data[1..1 + length];
                     let value = String::from_utf8_lossy(string_data).to_string();
-                    println!("🔧 [DEBUG] Parsed STRING: '{}'", value);
+                    log::debug!("🔧 [DEBUG] Parsed STRING: '{}'", value);
                     Ok(PlcValue::String(value))
                 }
                 0x02A0 => {
                     // Alternative STRING type (Allen-Bradley specific)
-                    if value_data.len() < 7 {
+                    if value_data.len() < 6 {
                         return Err(EtherNetIpError::Protocol(
                             "Insufficient data for alternative STRING value".to_string(),
                         ));
                     }
 
-                    // For this format, the string data starts directly at position 6
-                    // We need to find the null terminator or use the full remaining length
-                    let string_start = 6;
-                    let string_data = &value_data[string_start..];
-
-                    // Find null terminator or use full length
-                    let string_end = string_data
-                        .iter()
-                        .position(|&b| b == 0)
-                        .unwrap_or(string_data.len());
-                    let string_bytes = &string_data[..string_end];
+                    // Structure handle (2 bytes), LEN (DINT) and DATA. LEN is
+                    // authoritative: DATA may hold stale bytes past it, and
+                    // replies may omit unused trailing bytes
+                    let string_data = &value_data[6..];
+                    let length = u32::from_le_bytes([
+                        value_data[2],
+                        value_data[3],
+                        value_data[4],
+                        value_data[5],
+                    ]) as usize;
+                    let string_bytes = &string_data[..length.min(string_data.len())];
 
                     let value = String::from_utf8_lossy(string_bytes).to_string();
-                    println!("🔧 [DEBUG] Parsed alternative STRING (0x02A0): '{}'", value);
+                    log::debug!("🔧 [DEBUG] Parsed alternative STRING (0x02A0): '{}'", value);
                     Ok(PlcValue::String(value))
                 }
                 _ => {
-                    println!("🔧 [DEBUG] Unknown data type: 0x{:04X}", data_type);
+                    log::debug!("🔧 [DEBUG] Unknown data type: 0x{:04X}", data_type);
                     Err(EtherNetIpError::Protocol(format!(
                         "Unsupported data type: 0x{:04X}",
                         data_type
@@ -2017,7 +2387,7 @@ impl EipClient {
             }
         } else if service_reply == 0xCD {
             // Write Tag reply - no data to parse
-            println!("🔧 [DEBUG] Write operation successful");
+            log::debug!("🔧 [DEBUG] Write operation successful");
             Ok(PlcValue::Bool(true)) // Indicate success
         } else {
             Err(EtherNetIpError::Protocol(format!(
@@ -2029,7 +2399,7 @@ impl EipClient {
 
     /// Unregisters the EtherNet/IP session with the PLC
     pub async fn unregister_session(&mut self) -> crate::error::Result<()> {
-        println!("🔌 Unregistering session and cleaning up connections...");
+        log::debug!("🔌 Unregistering session and cleaning up connections...");
 
         // Close all connected sessions first
         let _ = self.close_all_connected_sessions().await;
@@ -2054,13 +2424,13 @@ impl EipClient {
             .await
             .map_err(EtherNetIpError::Io)?;
 
-        println!("✅ Session unregistered and all connections closed");
+        log::debug!("✅ Session unregistered and all connections closed");
         Ok(())
     }
 
     /// Builds a CIP Read Tag Service request
     fn build_read_request(&self, tag_name: &str) -> Vec<u8> {
-        println!("🔧 [DEBUG] Building read request for tag: '{}'", tag_name);
+        log::debug!("🔧 [DEBUG] Building read request for tag: '{}'", tag_name);
 
         let mut cip_request = Vec::new();
 
@@ -2079,7 +2449,7 @@ impl EipClient {
         // Element count (little-endian)
         cip_request.extend_from_slice(&[0x01, 0x00]); // Read 1 element
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Built CIP read request ({} bytes): {:02X?}",
             cip_request.len(),
             cip_request
@@ -2218,10 +2588,14 @@ impl EipClient {
         }
 
         let start_time = Instant::now();
-        println!(
+        log::debug!(
             "🚀 [BATCH] Starting batch execution with {} operations",
             operations.len()
         );
+        self.last_batch_timing = BatchTiming {
+            operations: operations.len() as u32,
+            ..BatchTiming::default()
+        };
 
         // Group operations based on configuration
         let operation_groups = if self.batch_config.optimize_packet_packing {
@@ -2234,7 +2608,7 @@ impl EipClient {
 
         // Execute each group
         for (group_index, group) in operation_groups.iter().enumerate() {
-            println!(
+            log::debug!(
                 "🔧 [BATCH] Processing group {} with {} operations",
                 group_index + 1,
                 group.len()
@@ -2263,7 +2637,8 @@ impl EipClient {
         }
 
         let total_time = start_time.elapsed();
-        println!(
+        self.last_batch_timing.total_us = total_time.as_micros() as u64;
+        log::debug!(
             "✅ [BATCH] Completed batch execution in {:?} - {} operations processed",
             total_time,
             all_results.len()
@@ -2449,7 +2824,7 @@ impl EipClient {
     /// ```
     pub fn configure_batch_operations(&mut self, config: BatchConfig) {
         self.batch_config = config;
-        println!(
+        log::debug!(
             "🔧 [BATCH] Updated batch configuration: max_ops={}, max_size={}, timeout={}ms",
             self.batch_config.max_operations_per_packet,
             self.batch_config.max_packet_size,
@@ -2514,14 +2889,23 @@ impl EipClient {
 
         // Build Multiple Service Packet request
         let cip_request = self.build_multiple_service_packet(operations)?;
+        let serialized_at = Instant::now();
 
         // Send request and get response
         let response = self.send_cip_request(&cip_request).await?;
+        let received_at = Instant::now();
 
         // Parse response and create results
         let parsed_results = self.parse_multiple_service_response(&response, operations)?;
 
         let execution_time = start_time.elapsed();
+        self.record_packet_timing(
+            cip_request.len(),
+            response.len(),
+            serialized_at - start_time,
+            received_at - serialized_at,
+            received_at.elapsed(),
+        );
 
         // Create BatchResult objects
         for (i, operation) in operations.iter().enumerate() {
@@ -2548,6 +2932,36 @@ impl EipClient {
         Ok(results)
     }
 
+    /// Adds the timing of one Multiple Service Packet to the current batch timing
+    fn record_packet_timing(
+        &mut self,
+        request_bytes: usize,
+        response_bytes: usize,
+        serialization: Duration,
+        round_trip: Duration,
+        decode: Duration,
+    ) {
+        let round_trip_us = round_trip.as_micros() as u64;
+        let baseline = self
+            .min_round_trip_us
+            .map_or(round_trip_us, |min| min.min(round_trip_us));
+        self.min_round_trip_us = Some(baseline);
+
+        let timing = &mut self.last_batch_timing;
+        timing.packets += 1;
+        timing.request_bytes += request_bytes as u64;
+        timing.response_bytes += response_bytes as u64;
+        timing.serialization_us += serialization.as_micros() as u64;
+        timing.round_trip_us += round_trip_us;
+        timing.controller_estimate_us += round_trip_us - baseline;
+        timing.decode_us += decode.as_micros() as u64;
+    }
+
+    /// Returns the packet-level timing of the most recent batch execution
+    pub fn last_batch_timing(&self) -> BatchTiming {
+        self.last_batch_timing
+    }
+
     /// Builds a CIP Multiple Service Packet request
     fn build_multiple_service_packet(
         &self,
@@ -2595,7 +3009,7 @@ impl EipClient {
             packet.extend_from_slice(&service_request);
         }
 
-        println!(
+        log::debug!(
             "🔧 [BATCH] Built Multiple Service Packet ({} bytes, {} services)",
             packet.len(),
             operations.len()
@@ -2618,7 +3032,7 @@ impl EipClient {
 
         let mut results = Vec::new();
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Raw Multiple Service Response ({} bytes): {:02X?}",
             response.len(),
             response
@@ -2628,12 +3042,12 @@ impl EipClient {
         let cip_data = match self.extract_cip_from_response(response) {
             Ok(data) => data,
             Err(e) => {
-                println!("🔧 [DEBUG] Failed to extract CIP data: {}", e);
+                log::debug!("🔧 [DEBUG] Failed to extract CIP data: {}", e);
                 return Err(e);
             }
         };
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Extracted CIP data ({} bytes): {:02X?}",
             cip_data.len(),
             cip_data
@@ -2656,7 +3070,7 @@ impl EipClient {
         let general_status = cip_data[2];
         let num_replies = u16::from_le_bytes([cip_data[4], cip_data[5]]) as usize;
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Multiple Service Response: service=0x{:02X}, status=0x{:02X}, replies={}",
             service_code, general_status, num_replies
         );
@@ -2692,12 +3106,12 @@ impl EipClient {
             offset += 2;
         }
 
-        println!("🔧 [DEBUG] Reply offsets: {:?}", reply_offsets);
+        log::debug!("🔧 [DEBUG] Reply offsets: {:?}", reply_offsets);
 
         // The reply data starts after all the offsets
         let reply_base_offset = 6 + (num_replies * 2);
 
-        println!("🔧 [DEBUG] Reply base offset: {}", reply_base_offset);
+        log::debug!("🔧 [DEBUG] Reply base offset: {}", reply_base_offset);
 
         // Parse each reply
         for (i, &reply_offset) in reply_offsets.iter().enumerate() {
@@ -2729,7 +3143,7 @@ impl EipClient {
 
             let reply_data = &cip_data[reply_start..reply_end];
 
-            println!(
+            log::debug!(
                 "🔧 [DEBUG] Reply {} at offset {}: start={}, end={}, len={}",
                 i,
                 reply_offset,
@@ -2737,7 +3151,7 @@ impl EipClient {
                 reply_end,
                 reply_data.len()
             );
-            println!("🔧 [DEBUG] Reply {} data: {:02X?}", i, reply_data);
+            log::debug!("🔧 [DEBUG] Reply {} data: {:02X?}", i, reply_data);
 
             let result = self.parse_individual_reply(reply_data, &operations[i]);
             results.push(result);
@@ -2758,7 +3172,7 @@ impl EipClient {
             ));
         }
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Parsing individual reply ({} bytes): {:02X?}",
             reply_data.len(),
             reply_data
@@ -2774,7 +3188,7 @@ impl EipClient {
         let service_code = reply_data[0];
         let general_status = reply_data[2];
 
-        println!(
+        log::debug!(
             "🔧 [DEBUG] Service code: 0x{:02X}, Status: 0x{:02X}",
             service_code, general_status
         );
@@ -2803,7 +3217,7 @@ impl EipClient {
                 // Parse the data directly (skip the 4-byte header)
                 // Data format: [type_low, type_high, value_bytes...]
                 let data = &reply_data[4..];
-                println!(
+                log::debug!(
                     "🔧 [DEBUG] Parsing data ({} bytes): {:02X?}",
                     data.len(),
                     data
@@ -2818,7 +3232,7 @@ impl EipClient {
                 let data_type = u16::from_le_bytes([data[0], data[1]]);
                 let value_data = &data[2..];
 
-                println!(
+                log::debug!(
                     "🔧 [DEBUG] Data type: 0x{:04X}, Value data ({} bytes): {:02X?}",
                     data_type,
                     value_data.len(),
@@ -2868,7 +3282,7 @@ impl EipClient {
                             value_data[2],
                             value_data[3],
                         ]);
-                        println!("🔧 [DEBUG] Parsed DINT: {}", value);
+                        log::debug!("🔧 [DEBUG] Parsed DINT: {}", value);
                         Ok(Some(PlcValue::Dint(value)))
                     }
                     0x00C5 => {
@@ -2952,7 +3366,7 @@ impl EipClient {
                         }
                         let bytes = [value_data[0], value_data[1], value_data[2], value_data[3]];
                         let value = f32::from_le_bytes(bytes);
-                        println!("🔧 [DEBUG] Parsed REAL: {}", value);
+                        log::debug!("🔧 [DEBUG] Parsed REAL: {}", value);
                         Ok(Some(PlcValue::Real(value)))
                     }
                     0x00CB => {
@@ -2988,31 +3402,30 @@ impl EipClient {
                         }
                         let string_data = &value_data[1..1 + length];
                         let value = String::from_utf8_lossy(string_data).to_string();
-                        println!("🔧 [DEBUG] Parsed STRING: '{}'", value);
+                        log::debug!("🔧 [DEBUG] Parsed STRING: '{}'", value);
                         Ok(Some(PlcValue::String(value)))
                     }
                     0x02A0 => {
                         // Alternative STRING type (Allen-Bradley specific) for batch operations
-                        if value_data.len() < 7 {
+                        if value_data.len() < 6 {
                             return Err(BatchError::SerializationError(
                                 "Insufficient data for alternative STRING value".to_string(),
                             ));
                         }
 
-                        // For this format, the string data starts directly at position 6
-                        // We need to find the null terminator or use the full remaining length
-                        let string_start = 6;
-                        let string_data = &value_data[string_start..];
-
-                        // Find null terminator or use full length
-                        let string_end = string_data
-                            .iter()
-                            .position(|&b| b == 0)
-                            .unwrap_or(string_data.len());
-                        let string_bytes = &string_data[..string_end];
+                        // Structure handle (2 bytes), LEN (DINT) and DATA; see
+                        // parse_cip_response
+                        let string_data = &value_data[6..];
+                        let length = u32::from_le_bytes([
+                            value_data[2],
+                            value_data[3],
+                            value_data[4],
+                            value_data[5],
+                        ]) as usize;
+                        let string_bytes = &string_data[..length.min(string_data.len())];
 
                         let value = String::from_utf8_lossy(string_bytes).to_string();
-                        println!("🔧 [DEBUG] Parsed alternative STRING (0x02A0): '{}'", value);
+                        log::debug!("🔧 [DEBUG] Parsed alternative STRING (0x02A0): '{}'", value);
                         Ok(Some(PlcValue::String(value)))
                     }
                     _ => Err(BatchError::SerializationError(format!(
@@ -3031,7 +3444,7 @@ impl EipClient {
         tag_name: &str,
         value: &str,
     ) -> crate::error::Result<()> {
-        println!(
+        log::debug!(
             "🔧 [AB STRING] Writing string '{}' to tag '{}' using component access",
             value, tag_name
         );
@@ -3041,18 +3454,18 @@ impl EipClient {
 
         // Step 1: Write the length to TestString.LEN
         let len_tag = format!("{}.LEN", tag_name);
-        println!("   📝 Step 1: Writing length {} to {}", string_len, len_tag);
+        log::debug!("   📝 Step 1: Writing length {} to {}", string_len, len_tag);
 
         match self.write_tag(&len_tag, PlcValue::Dint(string_len)).await {
-            Ok(_) => println!("   ✅ Length written successfully"),
+            Ok(_) => log::debug!("   ✅ Length written successfully"),
             Err(e) => {
-                println!("   ❌ Length write failed: {}", e);
+                log::warn!("   ❌ Length write failed: {}", e);
                 return Err(e);
             }
         }
 
         // Step 2: Write the string data to TestString.DATA using array access
-        println!("   📝 Step 2: Writing string data to {}.DATA", tag_name);
+        log::debug!("   📝 Step 2: Writing string data to {}.DATA", tag_name);
 
         // We need to write each character individually to the DATA array
         for (i, &byte) in string_bytes.iter().enumerate() {
@@ -3061,10 +3474,10 @@ impl EipClient {
                 .write_tag(&data_element, PlcValue::Sint(byte as i8))
                 .await
             {
-                Ok(_) => print!("."),
+                Ok(_) => log::trace!("   ✅ Wrote byte {} to {}", byte, data_element),
                 Err(e) => {
-                    println!(
-                        "\n   ❌ Failed to write byte {} to position {}: {}",
+                    log::warn!(
+                        "   ❌ Failed to write byte {} to position {}: {}",
                         byte, i, e
                     );
                     return Err(e);
@@ -3076,12 +3489,12 @@ impl EipClient {
         if string_bytes.len() < 82 {
             let null_element = format!("{}.DATA[{}]", tag_name, string_bytes.len());
             match self.write_tag(&null_element, PlcValue::Sint(0)).await {
-                Ok(_) => println!("\n   ✅ String null-terminated successfully"),
-                Err(e) => println!("\n   ⚠️ Could not null-terminate: {}", e),
+                Ok(_) => log::debug!("   ✅ String null-terminated successfully"),
+                Err(e) => log::warn!("   ⚠️ Could not null-terminate: {}", e),
             }
         }
 
-        println!("   🎉 AB STRING component write completed!");
+        log::debug!("   🎉 AB STRING component write completed!");
         Ok(())
     }
 
@@ -3091,7 +3504,7 @@ impl EipClient {
         tag_name: &str,
         value: &str,
     ) -> crate::error::Result<()> {
-        println!(
+        log::debug!(
             "🔧 [AB STRING UDT] Writing string '{}' to tag '{}' as UDT",
             value, tag_name
         );
@@ -3133,7 +3546,7 @@ impl EipClient {
         let padding_needed = 82 - string_bytes.len();
         cip_request.extend_from_slice(&vec![0u8; padding_needed]);
 
-        println!(
+        log::debug!(
             "   📦 Built UDT write request: {} bytes total",
             cip_request.len()
         );
@@ -3143,7 +3556,7 @@ impl EipClient {
         if response.len() >= 3 {
             let general_status = response[2];
             if general_status == 0x00 {
-                println!("   ✅ AB STRING UDT write successful!");
+                log::debug!("   ✅ AB STRING UDT write successful!");
                 Ok(())
             } else {
                 let error_msg = self.get_cip_error_message(general_status);
@@ -3168,11 +3581,11 @@ impl EipClient {
         &mut self,
         session_name: &str,
     ) -> crate::error::Result<ConnectedSession> {
-        println!(
+        log::debug!(
             "🔗 [CONNECTED] Establishing connected session: '{}'",
             session_name
         );
-        println!("🔗 [CONNECTED] Will try multiple parameter configurations...");
+        log::debug!("🔗 [CONNECTED] Will try multiple parameter configurations...");
 
         // Generate unique connection parameters
         *self.connection_sequence.lock().await += 1;
@@ -3180,7 +3593,7 @@ impl EipClient {
 
         // Try different configurations until one works
         for config_id in 0..=5 {
-            println!(
+            log::debug!(
                 "\n🔧 [ATTEMPT {}] Trying configuration {}:",
                 config_id + 1,
                 config_id
@@ -3201,24 +3614,28 @@ impl EipClient {
             // Build Forward Open request with this configuration
             let forward_open_request = self.build_forward_open_request(&session)?;
 
-            println!(
+            log::debug!(
                 "🔗 [ATTEMPT {}] Sending Forward Open request ({} bytes)",
                 config_id + 1,
                 forward_open_request.len()
             );
 
             // Send Forward Open request
-            match self.send_cip_request(&forward_open_request).await {
+            match self
+                .send_cip_request(&forward_open_request)
+                .await
+                .and_then(|response| self.extract_cip_from_response(&response))
+            {
                 Ok(response) => {
                     // Try to parse the response - DON'T clone, modify the session directly!
                     match self.parse_forward_open_response(&mut session, &response) {
                         Ok(()) => {
                             // Success! Store the session and return
-                            println!("✅ [SUCCESS] Configuration {} worked!", config_id);
-                            println!("   Connection ID: 0x{:08X}", session.connection_id);
-                            println!("   O->T ID: 0x{:08X}", session.o_to_t_connection_id);
-                            println!("   T->O ID: 0x{:08X}", session.t_to_o_connection_id);
-                            println!(
+                            log::debug!("✅ [SUCCESS] Configuration {} worked!", config_id);
+                            log::debug!("   Connection ID: 0x{:08X}", session.connection_id);
+                            log::debug!("   O->T ID: 0x{:08X}", session.o_to_t_connection_id);
+                            log::debug!("   T->O ID: 0x{:08X}", session.t_to_o_connection_id);
+                            log::debug!(
                                 "   Using Connection ID: 0x{:08X} for messaging",
                                 session.connection_id
                             );
@@ -3229,7 +3646,7 @@ impl EipClient {
                             return Ok(session);
                         }
                         Err(e) => {
-                            println!(
+                            log::warn!(
                                 "❌ [ATTEMPT {}] Configuration {} failed: {}",
                                 config_id + 1,
                                 config_id,
@@ -3238,13 +3655,13 @@ impl EipClient {
 
                             // If it's a specific status error, log it
                             if e.to_string().contains("status: 0x") {
-                                println!("   Status indicates: parameter incompatibility or resource conflict");
+                                log::debug!("   Status indicates: parameter incompatibility or resource conflict");
                             }
                         }
                     }
                 }
                 Err(e) => {
-                    println!(
+                    log::warn!(
                         "❌ [ATTEMPT {}] Network error with config {}: {}",
                         config_id + 1,
                         config_id,
@@ -3270,8 +3687,12 @@ impl EipClient {
     ) -> crate::error::Result<Vec<u8>> {
         let mut request = Vec::with_capacity(50);
 
-        // CIP Forward Open Service (0x54)
-        request.push(0x54);
+        // CIP Forward Open Service (0x54), or Large Forward Open (0x5B)
+        if session.large_forward_open {
+            request.push(0x5B);
+        } else {
+            request.push(0x54);
+        }
 
         // Request path length (Connection Manager object)
         request.push(0x02); // 2 words
@@ -3287,8 +3708,8 @@ impl EipClient {
         // Forward Open parameters
 
         // Connection Timeout Ticks (1 byte) + Timeout multiplier (1 byte)
-        request.push(0x0A); // Timeout ticks (10)
-        request.push(session.timeout_multiplier);
+        request.push(0x0A); // Priority/time tick: 1024 ms ticks
+        request.push(0x05); // Time-out ticks: about 5 seconds for the request itself
 
         // Originator -> Target Connection ID (4 bytes, little-endian)
         request.extend_from_slice(&session.o_to_t_connection_id.to_le_bytes());
@@ -3314,33 +3735,75 @@ impl EipClient {
         // Originator -> Target RPI (4 bytes, little-endian, microseconds)
         request.extend_from_slice(&session.rpi.to_le_bytes());
 
-        // Originator -> Target connection parameters (4 bytes)
-        let o_to_t_params = self.encode_connection_parameters(&session.o_to_t_params);
-        request.extend_from_slice(&o_to_t_params.to_le_bytes());
+        // Originator -> Target network connection parameters (2 bytes, 4 for
+        // Large Forward Open)
+        self.push_connection_parameters(&mut request, session, &session.o_to_t_params)?;
 
         // Target -> Originator RPI (4 bytes, little-endian, microseconds)
         request.extend_from_slice(&session.rpi.to_le_bytes());
 
-        // Target -> Originator connection parameters (4 bytes)
-        let t_to_o_params = self.encode_connection_parameters(&session.t_to_o_params);
-        request.extend_from_slice(&t_to_o_params.to_le_bytes());
-
-        // Transport type/trigger (1 byte) - Class 3, Application triggered
-        request.push(0xA3);
+        // Target -> Originator network connection parameters
+        self.push_connection_parameters(&mut request, session, &session.t_to_o_params)?;
 
-        // Connection Path Size (1 byte)
-        request.push(0x02); // 2 words for Message Router path
+        // Transport type/trigger (1 byte) - Class 3, application triggered by default
+        request.push(session.transport_class_trigger);
 
-        // Connection Path - Target the Message Router
-        request.push(0x20); // Logical Class segment
-        request.push(0x02); // Message Router class (0x02)
-        request.push(0x24); // Logical Instance segment
-        request.push(0x01); // Message Router instance (0x01)
+        // Connection Path: the route to the processor, then its Message Router
+        let connection_path = self.connection_path();
+        request.push((connection_path.len() / 2) as u8); // Path size in words
+        request.extend_from_slice(&connection_path);
 
         Ok(request)
     }
 
-    /// Encodes connection parameters into a 32-bit value
+    /// Appends network connection parameters in the form of the session's
+    /// Forward Open service
+    fn push_connection_parameters(
+        &self,
+        request: &mut Vec<u8>,
+        session: &ConnectedSession,
+        params: &ConnectionParameters,
+    ) -> crate::error::Result<()> {
+        if session.large_forward_open {
+            let encoded = self.encode_connection_parameters(params);
+            request.extend_from_slice(&encoded.to_le_bytes());
+        } else {
+            let encoded = self.encode_small_connection_parameters(params)?;
+            request.extend_from_slice(&encoded.to_le_bytes());
+        }
+        Ok(())
+    }
+
+    /// Encodes connection parameters in the 16-bit form of Forward Open,
+    /// which limits connections to 511 bytes
+    fn encode_small_connection_parameters(
+        &self,
+        params: &ConnectionParameters,
+    ) -> crate::error::Result<u16> {
+        if params.size > MAX_STANDARD_CONNECTION_SIZE {
+            return Err(EtherNetIpError::Protocol(format!(
+                "Connection size {} exceeds the 511 bytes of Forward Open",
+                params.size
+            )));
+        }
+        let mut encoded = params.size;
+
+        // Variable flag (bit 9)
+        if params.variable_size {
+            encoded |= 1 << 9;
+        }
+
+        // Priority (bits 10-11)
+        encoded |= (params.priority as u16 & 0x03) << 10;
+
+        // Connection type (bits 13-14)
+        encoded |= (params.connection_type as u16 & 0x03) << 13;
+
+        Ok(encoded)
+    }
+
+    /// Encodes connection parameters into the 32-bit value of Large Forward
+    /// Open
     fn encode_connection_parameters(&self, params: &ConnectionParameters) -> u32 {
         let mut encoded = 0u32;
 
@@ -3367,17 +3830,18 @@ impl EipClient {
         session: &mut ConnectedSession,
         response: &[u8],
     ) -> crate::error::Result<()> {
-        if response.len() < 2 {
+        if response.len() < 4 {
             return Err(EtherNetIpError::Protocol(
                 "Forward Open response too short".to_string(),
             ));
         }
 
         let service = response[0];
-        let status = response[1];
+        // Reply header: service, reserved, general status, additional status size
+        let status = response[2];
 
-        // Check if this is a Forward Open Reply (0xD4)
-        if service != 0xD4 {
+        // Check if this is a Forward Open (0xD4) or Large Forward Open (0xDB) reply
+        if service != 0xD4 && service != 0xDB {
             return Err(EtherNetIpError::Protocol(format!(
                 "Unexpected service in Forward Open response: 0x{:02X}",
                 service
@@ -3397,14 +3861,23 @@ impl EipClient {
                 0x26 => "Invalid parameter value - RPI or size out of range",
                 _ => &format!("Unknown status: 0x{:02X}", status),
             };
+            // The Connection Manager explains failures in its extended status
+            let extended = if response.len() >= 6 && response[3] > 0 {
+                format!(
+                    " (extended status 0x{:04X})",
+                    u16::from_le_bytes([response[4], response[5]])
+                )
+            } else {
+                String::new()
+            };
             return Err(EtherNetIpError::Protocol(format!(
-                "Forward Open failed with status 0x{:02X}: {}",
-                status, error_msg
+                "Forward Open failed with status 0x{:02X}: {}{}",
+                status, error_msg, extended
             )));
         }
 
         // Parse successful response
-        if response.len() < 16 {
+        if response.len() < 12 {
             return Err(EtherNetIpError::Protocol(
                 "Forward Open response data too short".to_string(),
             ));
@@ -3413,25 +3886,25 @@ impl EipClient {
         // CRITICAL FIX: The Forward Open response contains the actual connection IDs assigned by the PLC
         // Use the IDs returned by the PLC, not our requested ones
         let actual_o_to_t_id =
-            u32::from_le_bytes([response[2], response[3], response[4], response[5]]);
+            u32::from_le_bytes([response[4], response[5], response[6], response[7]]);
         let actual_t_to_o_id =
-            u32::from_le_bytes([response[6], response[7], response[8], response[9]]);
+            u32::from_le_bytes([response[8], response[9], response[10], response[11]]);
 
         // Update session with the actual assigned connection IDs
         session.o_to_t_connection_id = actual_o_to_t_id;
         session.t_to_o_connection_id = actual_t_to_o_id;
         session.connection_id = actual_o_to_t_id; // Use O->T as the primary connection ID
 
-        println!("✅ [FORWARD OPEN] Success!");
-        println!(
+        log::debug!("✅ [FORWARD OPEN] Success!");
+        log::debug!(
             "   O->T Connection ID: 0x{:08X} (PLC assigned)",
             session.o_to_t_connection_id
         );
-        println!(
+        log::debug!(
             "   T->O Connection ID: 0x{:08X} (PLC assigned)",
             session.t_to_o_connection_id
         );
-        println!(
+        log::debug!(
             "   Using Connection ID: 0x{:08X} for messaging",
             session.connection_id
         );
@@ -3533,9 +4006,9 @@ impl EipClient {
         data_array[..current_len as usize].copy_from_slice(&string_bytes[..current_len as usize]);
         request.extend_from_slice(&data_array);
 
-        println!("🔧 [DEBUG] Built connected string write request ({} bytes) for '{}' = '{}' (len={}, maxlen={})",
+        log::debug!("🔧 [DEBUG] Built connected string write request ({} bytes) for '{}' = '{}' (len={}, maxlen={})",
                  request.len(), tag_name, value, current_len, max_len);
-        println!("🔧 [DEBUG] Request: {:02X?}", request);
+        log::debug!("🔧 [DEBUG] Request: {:02X?}", request);
 
         Ok(request)
     }
@@ -3547,7 +4020,7 @@ impl EipClient {
         session: &ConnectedSession,
         session_name: &str,
     ) -> crate::error::Result<Vec<u8>> {
-        println!("🔗 [CONNECTED] Sending connected CIP request ({} bytes) using T->O connection ID 0x{:08X}",
+        log::debug!("🔗 [CONNECTED] Sending connected CIP request ({} bytes) using T->O connection ID 0x{:08X}",
                  cip_request.len(), session.t_to_o_connection_id);
 
         // Build EtherNet/IP header for connected data (Send RR Data)
@@ -3558,7 +4031,7 @@ impl EipClient {
         packet.extend_from_slice(&[0x00, 0x00]); // Length (fill in later)
         packet.extend_from_slice(&self.session_handle.to_le_bytes()); // Session handle
         packet.extend_from_slice(&[0x00, 0x00, 0x00, 0x00]); // Status
-        packet.extend_from_slice(&[0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00]); // Context
+        packet.extend_from_slice(&self.request_sender_context().to_le_bytes()); // Context
         packet.extend_from_slice(&[0x00, 0x00, 0x00, 0x00]); // Options
 
         // CPF (Common Packet Format) data starts here
@@ -3610,7 +4083,7 @@ impl EipClient {
         let cpf_length = packet.len() - cpf_start;
         packet[2..4].copy_from_slice(&(cpf_length as u16).to_le_bytes());
 
-        println!(
+        log::debug!(
             "🔗 [CONNECTED] Sending packet ({} bytes) with sequence {}",
             packet.len(),
             current_sequence
@@ -3650,7 +4123,7 @@ impl EipClient {
         let mut last_activity = self.last_activity.lock().await;
         *last_activity = Instant::now();
 
-        println!(
+        log::debug!(
             "🔗 [CONNECTED] Received response ({} bytes)",
             response_data.len()
         );
@@ -3664,7 +4137,7 @@ impl EipClient {
         &self,
         response: &[u8],
     ) -> crate::error::Result<Vec<u8>> {
-        println!(
+        log::debug!(
             "🔗 [CONNECTED] Extracting CIP from connected response ({} bytes): {:02X?}",
             response.len(),
             response
@@ -3681,7 +4154,7 @@ impl EipClient {
         // [4-5]: Timeout
         // [6-7]: Item count
         let item_count = u16::from_le_bytes([response[6], response[7]]) as usize;
-        println!("🔗 [CONNECTED] CPF item count: {}", item_count);
+        log::debug!("🔗 [CONNECTED] CPF item count: {}", item_count);
 
         let mut pos = 8; // Start after CPF header
 
@@ -3697,7 +4170,7 @@ impl EipClient {
             let item_length = u16::from_le_bytes([response[pos + 2], response[pos + 3]]) as usize;
             pos += 4; // Skip item header
 
-            println!(
+            log::debug!(
                 "🔗 [CONNECTED] Found item: type=0x{:04X}, length={}",
                 item_type, item_length
             );
@@ -3718,11 +4191,11 @@ impl EipClient {
                 }
 
                 let sequence_count = u16::from_le_bytes([response[pos], response[pos + 1]]);
-                println!("🔗 [CONNECTED] Sequence count: {}", sequence_count);
+                log::debug!("🔗 [CONNECTED] Sequence count: {}", sequence_count);
 
                 // Extract CIP data (skip 2-byte sequence count)
                 let cip_data = response[pos + 2..pos + item_length].to_vec();
-                println!(
+                log::debug!(
                     "🔗 [CONNECTED] Extracted CIP data ({} bytes): {:02X?}",
                     cip_data.len(),
                     cip_data
@@ -3751,7 +4224,7 @@ impl EipClient {
             // Send Forward Close request
             let _response = self.send_cip_request(&forward_close_request).await?;
 
-            println!(
+            log::debug!(
                 "🔗 [CONNECTED] Session '{}' closed successfully",
                 session_name
             );
@@ -3788,8 +4261,8 @@ impl EipClient {
         // Forward Close parameters
 
         // Connection Timeout Ticks (1 byte) + Timeout multiplier (1 byte)
-        request.push(0x0A); // Timeout ticks (10)
-        request.push(session.timeout_multiplier);
+        request.push(0x0A); // Priority/time tick: 1024 ms ticks
+        request.push(0x05); // Time-out ticks: about 5 seconds for the request itself
 
         // Connection Serial Number (2 bytes, little-endian)
         request.extend_from_slice(&session.connection_serial.to_le_bytes());
@@ -3800,14 +4273,13 @@ impl EipClient {
         // Originator Serial Number (4 bytes, little-endian)
         request.extend_from_slice(&session.originator_serial.to_le_bytes());
 
-        // Connection Path Size (1 byte)
-        request.push(0x02); // 2 words for Message Router path
+        // Connection Path Size (1 byte) and reserved byte
+        let connection_path = self.connection_path();
+        request.push((connection_path.len() / 2) as u8);
+        request.push(0x00);
 
-        // Connection Path - Target the Message Router
-        request.push(0x20); // Logical Class segment
-        request.push(0x02); // Message Router class (0x02)
-        request.push(0x24); // Logical Instance segment
-        request.push(0x01); // Message Router instance (0x01)
+        // Connection Path: the route to the processor, then its Message Router
+        request.extend_from_slice(&connection_path);
 
         Ok(request)
     }
@@ -3825,10 +4297,211 @@ impl EipClient {
         for session_name in session_names {
             let _ = self.close_connected_session(&session_name).await; // Ignore errors during cleanup
         }
+        self.close_messaging_connection().await;
 
         Ok(())
     }
 
+    /// Opens the Class 3 connection of connected messaging mode. Connections
+    /// above 511 bytes are requested with Large Forward Open first; a target
+    /// that rejects it gets a standard Forward Open for 500 bytes.
+    async fn open_messaging_connection(&self) -> crate::error::Result<ConnectedSession> {
+        let requested = self
+            .forward_open_parameters
+            .connection_size
+            .min(self.max_packet_size.min(MAX_CONNECTION_SIZE as u32) as u16)
+            .max(1);
+        if requested <= MAX_STANDARD_CONNECTION_SIZE {
+            return self.forward_open(false, requested).await;
+        }
+        match self.forward_open(true, requested).await {
+            // The target answered with a CIP error: older firmware without
+            // Large Forward Open, or a size it cannot serve
+            Err(EtherNetIpError::Protocol(reason)) => {
+                log::info!(
+                    "🔗 Large Forward Open rejected ({}), falling back to {} bytes",
+                    reason,
+                    FALLBACK_CONNECTION_SIZE
+                );
+                self.forward_open(false, FALLBACK_CONNECTION_SIZE).await
+            }
+            result => result,
+        }
+    }
+
+    /// Opens a messaging connection of the given size with Forward Open or
+    /// Large Forward Open
+    async fn forward_open(&self, large: bool, size: u16) -> crate::error::Result<ConnectedSession> {
+        let serial = {
+            let mut sequence = self.connection_sequence.lock().await;
+            *sequence += 1;
+            *sequence
+        };
+        let mut session = ConnectedSession::new((serial & 0xFFFF) as u16);
+        // The target assigns the O->T ID; we choose the ID of its replies
+        session.t_to_o_connection_id = 0x40000000 + serial;
+        let parameters = self.forward_open_parameters;
+        session.rpi = parameters.rpi_us;
+        session.timeout_multiplier = parameters.timeout_multiplier;
+        session.o_to_t_params.size = size;
+        session.t_to_o_params.size = size;
+        session.transport_class_trigger = parameters.transport_class_trigger();
+        session.large_forward_open = large;
+
+        let request = self.build_forward_open_request(&session)?;
+        let response = self.send_unconnected_cip_request(&request).await?;
+        let reply = self.extract_cip_from_response(&response)?;
+        self.parse_forward_open_response(&mut session, &reply)?;
+        session.is_active = true;
+        session.established_at = Instant::now();
+
+        log::info!(
+            "🔗 Opened messaging connection 0x{:08X} ({} bytes, {})",
+            session.o_to_t_connection_id,
+            session.o_to_t_params.size,
+            if large {
+                "Large Forward Open"
+            } else {
+                "Forward Open"
+            }
+        );
+        Ok(session)
+    }
+
+    /// Closes the Class 3 connection of connected messaging mode, if open
+    async fn close_messaging_connection(&self) {
+        let session = self.messaging_connection.lock().unwrap().take();
+        if let Some(session) = session {
+            if let Ok(request) = self.build_forward_close_request(&session) {
+                // Ignore errors; the target times the connection out anyway
+                let _ = self.send_unconnected_cip_request(&request).await;
+            }
+        }
+    }
+
+    /// Sends a CIP request over the messaging connection in a SendUnitData
+    /// command, opening the connection first if needed. A failed exchange
+    /// drops the connection so the next request opens a new one. A connection
+    /// the target no longer holds (it times out after RPI × 4 ×
+    /// 2^timeout_multiplier without traffic) is reopened with Forward Open
+    /// and the request sent once more.
+    async fn send_connected_message(&self, cip_request: &[u8]) -> Result<Vec<u8>> {
+        let result = self.send_on_messaging_connection(cip_request).await;
+        match result {
+            Ok(response) if is_stale_connection_reply(&response) => {
+                log::info!("🔗 Messaging connection no longer held by the target, reopening");
+                self.messaging_connection.lock().unwrap().take();
+                self.send_on_messaging_connection(cip_request).await
+            }
+            result => result,
+        }
+    }
+
+    /// Sends a CIP request on the messaging connection, opening it if needed
+    async fn send_on_messaging_connection(&self, cip_request: &[u8]) -> Result<Vec<u8>> {
+        let open = self.messaging_connection.lock().unwrap().clone();
+        let session = match open {
+            Some(session) => session,
+            None => {
+                let session = self.open_messaging_connection().await?;
+                *self.messaging_connection.lock().unwrap() = Some(session.clone());
+                session
+            }
+        };
+        let sequence = {
+            let mut connection = self.messaging_connection.lock().unwrap();
+            match connection.as_mut() {
+                Some(open) => {
+                    open.sequence_count = open.sequence_count.wrapping_add(1);
+                    open.sequence_count
+                }
+                None => 1,
+            }
+        };
+
+        let result = self
+            .exchange_connected_message(cip_request, &session, sequence)
+            .await;
+        if result.is_err() {
+            self.messaging_connection.lock().unwrap().take();
+        }
+        result
+    }
+
+    /// Performs one SendUnitData exchange on a connection
+    async fn exchange_connected_message(
+        &self,
+        cip_request: &[u8],
+        session: &ConnectedSession,
+        sequence: u16,
+    ) -> Result<Vec<u8>> {
+        let data_length = cip_request.len() + 2; // Sequence count + CIP request
+        let total_data_len = 4 + 2 + 2 + 8 + 4 + data_length;
+
+        let mut packet = Vec::with_capacity(24 + total_data_len);
+
+        // EtherNet/IP header (24 bytes)
+        packet.extend_from_slice(&[0x70, 0x00]); // Command: Send Unit Data (0x0070)
+        packet.extend_from_slice(&(total_data_len as u16).to_le_bytes()); // Length
+        packet.extend_from_slice(&self.session_handle.to_le_bytes()); // Session handle
+        packet.extend_from_slice(&[0x00, 0x00, 0x00, 0x00]); // Status
+        packet.extend_from_slice(&self.request_sender_context().to_le_bytes()); // Context
+        packet.extend_from_slice(&[0x00, 0x00, 0x00, 0x00]); // Options
+
+        // CPF (Common Packet Format) data
+        packet.extend_from_slice(&[0x00, 0x00, 0x00, 0x00]); // Interface handle
+        packet.extend_from_slice(&[0x00, 0x00]); // Timeout (unused for connected data)
+        packet.extend_from_slice(&[0x02, 0x00]); // Item count: 2
+
+        // Item 1: Connected Address Item (0x00A1) with the target's O->T ID
+        packet.extend_from_slice(&[0xA1, 0x00]);
+        packet.extend_from_slice(&[0x04, 0x00]);
+        packet.extend_from_slice(&session.o_to_t_connection_id.to_le_bytes());
+
+        // Item 2: Connected Data Item (0x00B1)
+        packet.extend_from_slice(&[0xB1, 0x00]);
+        packet.extend_from_slice(&(data_length as u16).to_le_bytes());
+        packet.extend_from_slice(&sequence.to_le_bytes());
+        packet.extend_from_slice(cip_request);
+
+        let mut stream = self.stream.lock().await;
+        stream
+            .write_all(&packet)
+            .await
+            .map_err(EtherNetIpError::Io)?;
+
+        let mut header = [0u8; 24];
+        match timeout(Duration::from_secs(10), stream.read_exact(&mut header)).await {
+            Ok(Ok(_)) => {}
+            Ok(Err(e)) => return Err(EtherNetIpError::Io(e)),
+            Err(_) => return Err(EtherNetIpError::Timeout(Duration::from_secs(10))),
+        }
+
+        let cmd_status = u32::from_le_bytes([header[8], header[9], header[10], header[11]]);
+        if cmd_status != 0 {
+            return Err(EtherNetIpError::Protocol(format!(
+                "Connected message failed with status: 0x{:08X}",
+                cmd_status
+            )));
+        }
+
+        let response_length = u16::from_le_bytes([header[2], header[3]]) as usize;
+        let mut response_data = vec![0u8; response_length];
+        match timeout(
+            Duration::from_secs(10),
+            stream.read_exact(&mut response_data),
+        )
+        .await
+        {
+            Ok(Ok(_)) => {}
+            Ok(Err(e)) => return Err(EtherNetIpError::Io(e)),
+            Err(_) => return Err(EtherNetIpError::Timeout(Duration::from_secs(10))),
+        }
+
+        *self.last_activity.lock().await = Instant::now();
+        Ok(response_data)
+    }
+
     /// Writes a string using unconnected explicit messaging with proper AB STRING format
     ///
     /// This method uses standard unconnected messaging instead of connected messaging
@@ -3839,7 +4512,7 @@ impl EipClient {
         tag_name: &str,
         value: &str,
     ) -> crate::error::Result<()> {
-        println!(
+        log::debug!(
             "📝 [UNCONNECTED] Writing string '{}' to tag '{}' using unconnected messaging",
             value, tag_name
         );
@@ -3912,9 +4585,9 @@ impl EipClient {
         // Add padding if the total structure needs to be a specific size
         // Based on reads, it looks like there might be additional padding after the data
 
-        println!("🔧 [DEBUG] Built Allen-Bradley STRING write request ({} bytes) for '{}' = '{}' (len={})",
+        log::debug!("🔧 [DEBUG] Built Allen-Bradley STRING write request ({} bytes) for '{}' = '{}' (len={})",
                  cip_request.len(), tag_name, value, current_len);
-        println!("🔧 [DEBUG] Request structure: Service=0x4D, Path={} bytes, Header=0xCE0F, Len={} (4 bytes), Data",
+        log::debug!("🔧 [DEBUG] Request structure: Service=0x4D, Path={} bytes, Header=0xCE0F, Len={} (4 bytes), Data",
                  path_len * 2, current_len);
 
         // Send the request using standard unconnected messaging
@@ -3929,17 +4602,17 @@ impl EipClient {
             let _additional_status_size = cip_response[1]; // Additional status size (usually 0)
             let status = cip_response[2]; // CIP status code at position 2
 
-            println!(
+            log::debug!(
                 "🔧 [DEBUG] Write response - Service: 0x{:02X}, Status: 0x{:02X}",
                 service_reply, status
             );
 
             if status == 0x00 {
-                println!("✅ [UNCONNECTED] String write completed successfully");
+                log::debug!("✅ [UNCONNECTED] String write completed successfully");
                 Ok(())
             } else {
                 let error_msg = self.get_cip_error_message(status);
-                println!(
+                log::warn!(
                     "❌ [UNCONNECTED] String write failed: {} (0x{:02X})",
                     error_msg, status
                 );
@@ -4062,12 +4735,12 @@ impl EipClient {
                 match client.read_tag(&tag_path).await {
                     Ok(value) => {
                         if let Err(e) = client.update_subscription(&tag_path, &value).await {
-                            eprintln!("Error updating subscription: {}", e);
+                            log::debug!("Error updating subscription: {}", e);
                             break;
                         }
                     }
                     Err(e) => {
-                        eprintln!("Error reading tag {}: {}", tag_path, e);
+                        log::debug!("Error reading tag {}: {}", tag_path, e);
                         break;
                     }
                 }
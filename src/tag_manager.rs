@@ -178,7 +178,7 @@ impl TagManager {
     }
 
     pub fn parse_tag_list(&self, response: &[u8]) -> Result<Vec<(String, TagMetadata)>> {
-        println!(
+        log::debug!(
             "[DEBUG] Raw tag list response ({} bytes): {:02X?}",
             response.len(),
             response
@@ -192,7 +192,7 @@ impl TagManager {
         while offset < response.len() {
             // Check if we have enough bytes for instance ID
             if offset + 4 > response.len() {
-                println!(
+                log::debug!(
                     "[WARN] Not enough bytes for instance ID at offset {}",
                     offset
                 );
@@ -209,7 +209,7 @@ impl TagManager {
 
             // Check if we have enough bytes for name length
             if offset + 2 > response.len() {
-                println!(
+                log::debug!(
                     "[WARN] Not enough bytes for name length at offset {}",
                     offset
                 );
@@ -221,7 +221,7 @@ impl TagManager {
 
             // Check if we have enough bytes for the tag name
             if offset + name_length > response.len() {
-                println!(
+                log::debug!(
                     "[WARN] Not enough bytes for tag name at offset {} (need {}, have {})",
                     offset,
                     name_length,
@@ -235,7 +235,7 @@ impl TagManager {
 
             // Check if we have enough bytes for tag type
             if offset + 2 > response.len() {
-                println!("[WARN] Not enough bytes for tag type at offset {}", offset);
+                log::warn!("[WARN] Not enough bytes for tag type at offset {}", offset);
                 break;
             }
 
@@ -276,14 +276,14 @@ impl TagManager {
                 last_updated: Instant::now(),
             };
 
-            println!(
+            log::debug!(
                 "[DEBUG] Parsed tag: {} (ID: {}, Type: 0x{:04X})",
                 name, instance_id, type_code
             );
             tags.push((name, metadata));
         }
 
-        println!("[DEBUG] Parsed {} tags from response", tags.len());
+        log::debug!("[DEBUG] Parsed {} tags from response", tags.len());
         Ok(tags)
     }
 